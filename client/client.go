@@ -0,0 +1,181 @@
+// Package client connects Go programs to a Jobby server. A Client is made
+// once and shared: every call made through it, unary or streaming, goes
+// over the same connection (or small pool of them) instead of dialing
+// the server again. Nothing is dialed until the first call
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	jobmanagerv2 "github.com/gopheryan/jobby/jobmanagerpb/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Client is a JobManager client backed by a pool of connections. It's
+// also a grpc.ClientConnInterface, so generated clients for the server's
+// other APIs (ex: v2) can share its connections
+type Client struct {
+	jobmanagerpb.JobManagerClient
+
+	conns   []*grpc.ClientConn
+	next    atomic.Uint64
+	hooks   []StateHook
+	onClose []func() error
+	// Goroutines telling the hooks about connection states. They return
+	// once their connection shuts down
+	watchers sync.WaitGroup
+	closed   atomic.Bool
+}
+
+// StateHook is told when one of a Client's connections changes state (ex:
+// from IDLE to CONNECTING to READY, or to TRANSIENT_FAILURE while the
+// server is unreachable). 'conn' is the connection's index in the pool.
+// Hooks are called from a goroutine per connection, so they shouldn't block
+type StateHook func(conn int, state connectivity.State)
+
+// Option configures a Client
+type Option func(c *config)
+
+type config struct {
+	poolSize    int
+	dialOptions []grpc.DialOption
+	hooks       []StateHook
+	onClose     []func() error
+}
+
+// WithPoolSize spreads calls round-robin over 'n' connections instead of
+// one. HTTP/2 caps the streams a connection carries at once (100 by
+// default in gRPC servers), so programs following the output of many
+// jobs at once want more than one
+func WithPoolSize(n int) Option {
+	return func(c *config) {
+		c.poolSize = n
+	}
+}
+
+// WithDialOptions adds to the options each connection is made with. They
+// must include transport credentials
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *config) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
+}
+
+// WithStateHook calls 'hook' as each connection changes state, starting
+// with its state when the Client is made
+func WithStateHook(hook StateHook) Option {
+	return func(c *config) {
+		c.hooks = append(c.hooks, hook)
+	}
+}
+
+// WithCloseHook calls 'hook' once the Client is closed, after its
+// connections are (ex: to release credentials they were using). Its error
+// is returned from Close
+func WithCloseHook(hook func() error) Option {
+	return func(c *config) {
+		c.onClose = append(c.onClose, hook)
+	}
+}
+
+// New makes a Client for the server at 'target' (see grpc.NewClient). It
+// doesn't wait for the connection: the first call dials the server, or
+// call Connect to do it sooner. Close the client once done
+func New(target string, opts ...Option) (*Client, error) {
+	cfg := config{poolSize: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.poolSize < 1 {
+		return nil, errors.New("pool size must be at least 1")
+	}
+
+	c := &Client{hooks: cfg.hooks, onClose: cfg.onClose}
+	for range cfg.poolSize {
+		conn, err := grpc.NewClient(target, cfg.dialOptions...)
+		if err != nil {
+			for _, conn := range c.conns {
+				_ = conn.Close()
+			}
+			return nil, err
+		}
+		c.conns = append(c.conns, conn)
+	}
+	c.JobManagerClient = jobmanagerpb.NewJobManagerClient(c)
+
+	if len(c.hooks) > 0 {
+		for i, conn := range c.conns {
+			c.watchers.Add(1)
+			go c.watch(i, conn, conn.GetState())
+		}
+	}
+	return c, nil
+}
+
+// V2 is a client for the v2 API over the same connections
+func (c *Client) V2() jobmanagerv2.JobManagerClient {
+	return jobmanagerv2.NewJobManagerClient(c)
+}
+
+// Connect starts dialing every connection in the pool without waiting
+// for a call to need them
+func (c *Client) Connect() {
+	for _, conn := range c.conns {
+		conn.Connect()
+	}
+}
+
+// Invoke makes a unary call on the next connection in the pool
+func (c *Client) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	return c.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream starts a streaming call on the next connection in the pool
+func (c *Client) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.pick().NewStream(ctx, desc, method, opts...)
+}
+
+func (c *Client) pick() *grpc.ClientConn {
+	if len(c.conns) == 1 {
+		return c.conns[0]
+	}
+	return c.conns[(c.next.Add(1)-1)%uint64(len(c.conns))]
+}
+
+// Close closes every connection, ending calls still in progress. Safe to
+// call more than once
+func (c *Client) Close() error {
+	if c.closed.Swap(true) {
+		return nil
+	}
+	var err error
+	for _, conn := range c.conns {
+		err = errors.Join(err, conn.Close())
+	}
+	// The state hooks hear about the shutdown before the close hooks run
+	c.watchers.Wait()
+	for _, hook := range c.onClose {
+		err = errors.Join(err, hook())
+	}
+	return err
+}
+
+// Tell the hooks about each state 'conn' moves to from 'state' until it
+// shuts down
+func (c *Client) watch(i int, conn *grpc.ClientConn, state connectivity.State) {
+	defer c.watchers.Done()
+	for {
+		for _, hook := range c.hooks {
+			hook(i, state)
+		}
+		if state == connectivity.Shutdown || !conn.WaitForStateChange(context.Background(), state) {
+			return
+		}
+		state = conn.GetState()
+	}
+}
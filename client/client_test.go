@@ -0,0 +1,86 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gopheryan/jobby/client"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type infoServer struct {
+	jobmanagerpb.UnimplementedJobManagerServer
+}
+
+func (infoServer) GetServerInfo(context.Context, *jobmanagerpb.GetServerInfoRequest) (*jobmanagerpb.GetServerInfoResponse, error) {
+	return &jobmanagerpb.GetServerInfoResponse{Hostname: "test"}, nil
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	jobmanagerpb.RegisterJobManagerServer(server, infoServer{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	var dials atomic.Int32
+	var lock sync.Mutex
+	states := map[int][]connectivity.State{}
+	closed := false
+	c, err := client.New("passthrough://bufnet",
+		client.WithPoolSize(2),
+		client.WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				dials.Add(1)
+				return listener.Dial()
+			}),
+		),
+		client.WithStateHook(func(conn int, state connectivity.State) {
+			lock.Lock()
+			defer lock.Unlock()
+			states[conn] = append(states[conn], state)
+		}),
+		client.WithCloseHook(func() error {
+			closed = true
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	// Nothing's dialed until it's needed
+	assert.Zero(t, dials.Load())
+
+	for range 6 {
+		info, err := c.GetServerInfo(ctx, &jobmanagerpb.GetServerInfoRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "test", info.Hostname)
+	}
+	// The v2 API isn't served here, but its calls share the connections
+	_, err = c.V2().GetServerInfo(ctx, nil)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	assert.EqualValues(t, 2, dials.Load())
+
+	require.NoError(t, c.Close())
+	require.NoError(t, c.Close())
+	assert.True(t, closed)
+	lock.Lock()
+	defer lock.Unlock()
+	for conn := range 2 {
+		assert.Equal(t, connectivity.Idle, states[conn][0])
+		assert.Contains(t, states[conn], connectivity.Ready)
+		assert.Equal(t, connectivity.Shutdown, states[conn][len(states[conn])-1])
+	}
+}
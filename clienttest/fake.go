@@ -0,0 +1,396 @@
+// Package clienttest has an in-process fake of the JobManager service, for
+// testing code that drives jobs through the Go client. Nothing is run: tests
+// script what each job does and decide when it exits, so there's nothing to
+// wait out and no timing to race
+package clienttest
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/internal/testutils"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Script is what a job started on the fake does
+type Script struct {
+	// Written to the job's output as soon as it starts
+	Stdout []byte
+	Stderr []byte
+	// What the job exits with right after starting, unless it's held
+	ExitCode int32
+	// Keep the job running until the test calls FakeJob.Exit, or a
+	// client stops it
+	Hold bool
+	// StartJob fails with this instead of starting the job. Make it with
+	// status.Error to pick the code clients see
+	StartErr error
+}
+
+// FakeJobManager serves the JobManager API from memory. StartJob, StopJob,
+// GetStatus, WaitJob, GetJobOutput and ListJobs are faked. Everything else
+// is Unimplemented. There's only the one user, who owns every job
+type FakeJobManager struct {
+	jobmanagerpb.UnimplementedJobManagerServer
+
+	server *grpc.Server
+	local  testutils.GrpcLocalServer
+
+	lock sync.Mutex
+	// By command. The one for "" is used for commands without their own
+	scripts map[string]Script
+	jobs    map[uuid.UUID]*FakeJob
+	// Jobs in the order they were started
+	started []*FakeJob
+}
+
+// NewFakeJobManager starts serving the fake. Close it once done
+func NewFakeJobManager() (*FakeJobManager, error) {
+	f := &FakeJobManager{
+		server:  grpc.NewServer(),
+		scripts: map[string]Script{},
+		jobs:    map[uuid.UUID]*FakeJob{},
+	}
+	jobmanagerpb.RegisterJobManagerServer(f.server, f)
+	if err := f.local.ListenAndServe(f.server); err != nil {
+		f.server.Stop()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Client connected to the fake
+func (f *FakeJobManager) Client() jobmanagerpb.JobManagerClient {
+	return jobmanagerpb.NewJobManagerClient(f.local.Conn())
+}
+
+// Close stops serving, ending any calls still in progress
+func (f *FakeJobManager) Close() error {
+	f.server.Stop()
+	err := f.local.Done()
+	if errors.Is(err, grpc.ErrServerStopped) {
+		err = nil
+	}
+	return errors.Join(err, f.local.Conn().Close())
+}
+
+// Script what jobs running 'command' do from now on. An empty command
+// scripts every command without a script of its own. Unscripted jobs
+// exit with 0 without any output
+func (f *FakeJobManager) Script(command string, script Script) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.scripts[command] = script
+}
+
+// Jobs started so far, oldest first
+func (f *FakeJobManager) Jobs() []*FakeJob {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]*FakeJob(nil), f.started...)
+}
+
+// Job with 'id' (in canonical text form), or nil if there's none
+func (f *FakeJobManager) Job(id string) *FakeJob {
+	parsed, err := jobid.Parse(id)
+	if err != nil {
+		return nil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.jobs[parsed]
+}
+
+func (f *FakeJobManager) StartJob(_ context.Context, req *jobmanagerpb.StartJobRequest) (*jobmanagerpb.StartJobResponse, error) {
+	command, args := req.GetSpec().GetCommand(), req.GetSpec().GetArgs()
+	if req.Spec == nil {
+		command, args = req.GetCommand(), req.GetArgs()
+	}
+	if command == "" {
+		return nil, status.Error(codes.InvalidArgument, "Must provide a command")
+	}
+
+	f.lock.Lock()
+	script, ok := f.scripts[command]
+	if !ok {
+		script = f.scripts[""]
+	}
+	if script.StartErr != nil {
+		f.lock.Unlock()
+		return nil, script.StartErr
+	}
+	j := &FakeJob{
+		ID:        uuid.New(),
+		Request:   req,
+		command:   command,
+		args:      args,
+		startTime: time.Now(),
+		status:    jobmanagerpb.Status_STATUS_RUNNING,
+		changed:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	f.jobs[j.ID] = j
+	f.started = append(f.started, j)
+	f.lock.Unlock()
+
+	j.WriteStdout(script.Stdout)
+	j.WriteStderr(script.Stderr)
+	if !script.Hold {
+		j.Exit(script.ExitCode)
+	}
+	return &jobmanagerpb.StartJobResponse{JobId: j.ID[:], Id: j.ID.String()}, nil
+}
+
+func (f *FakeJobManager) StopJob(_ context.Context, req *jobmanagerpb.StopJobRequest) (*jobmanagerpb.StopJobResponse, error) {
+	j, err := f.getJob(req)
+	if err != nil {
+		return nil, err
+	}
+	j.finish(jobmanagerpb.Status_STATUS_STOPPED, nil)
+	return &jobmanagerpb.StopJobResponse{}, nil
+}
+
+func (f *FakeJobManager) GetStatus(_ context.Context, req *jobmanagerpb.GetStatusRequest) (*jobmanagerpb.GetStatusResponse, error) {
+	j, err := f.getJob(req)
+	if err != nil {
+		return nil, err
+	}
+	return j.statusResponse(), nil
+}
+
+func (f *FakeJobManager) WaitJob(ctx context.Context, req *jobmanagerpb.WaitJobRequest) (*jobmanagerpb.GetStatusResponse, error) {
+	j, err := f.getJob(req)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-j.done:
+		return j.statusResponse(), nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// Output is sent as it's written, in the chunks it was written in, then
+// its length and checksum. The batching and line options are ignored
+func (f *FakeJobManager) GetJobOutput(req *jobmanagerpb.GetJobOutputRequest, srv jobmanagerpb.JobManager_GetJobOutputServer) error {
+	j, err := f.getJob(req)
+	if err != nil {
+		return err
+	}
+	stderr := req.Type == jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR
+	sent := 0
+	sum := sha256.New()
+	var total uint64
+	for {
+		chunk, exited, changed := j.chunk(stderr, sent)
+		if chunk != nil {
+			if err := srv.Send(&jobmanagerpb.GetJobOutputResponse{Data: chunk}); err != nil {
+				return err
+			}
+			sum.Write(chunk)
+			total += uint64(len(chunk))
+			sent++
+			continue
+		}
+		if exited || req.NoFollow {
+			return srv.Send(&jobmanagerpb.GetJobOutputResponse{
+				End: &jobmanagerpb.OutputEnd{TotalBytes: total, Sha256: sum.Sum(nil)},
+			})
+		}
+		select {
+		case <-changed:
+		case <-srv.Context().Done():
+			return status.FromContextError(srv.Context().Err()).Err()
+		}
+	}
+}
+
+func (f *FakeJobManager) ListJobs(_ context.Context, req *jobmanagerpb.ListJobsRequest) (*jobmanagerpb.ListJobsResponse, error) {
+	resp := &jobmanagerpb.ListJobsResponse{}
+	for _, j := range f.Jobs() {
+		record := j.record()
+		if !strings.Contains(record.Command, req.CommandContains) {
+			continue
+		}
+		if req.ExitCode != nil && (record.ExitCode == nil || *record.ExitCode != *req.ExitCode) {
+			continue
+		}
+		resp.Jobs = append(resp.Jobs, record)
+	}
+	return resp, nil
+}
+
+// Implemented by requests that identify a job
+type jobIDGetter interface {
+	GetJobId() []byte
+	GetId() string
+}
+
+func (f *FakeJobManager) getJob(getter jobIDGetter) (*FakeJob, error) {
+	id, err := jobid.Resolve(getter.GetJobId(), getter.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Must provide valid job id")
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	j, ok := f.jobs[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "No such job exists")
+	}
+	return j, nil
+}
+
+// FakeJob is a job started on a FakeJobManager. Tests move it along with
+// its methods
+type FakeJob struct {
+	ID uuid.UUID
+	// The request that started the job
+	Request *jobmanagerpb.StartJobRequest
+
+	command string
+	args    []string
+
+	// Guards everything below
+	lock      sync.Mutex
+	startTime time.Time
+	endTime   time.Time
+	status    jobmanagerpb.Status
+	exitCode  *int32
+	// Each write is a chunk of its own
+	stdout [][]byte
+	stderr [][]byte
+	// Closed (and replaced) whenever output is written or the job exits
+	changed chan struct{}
+	done    chan struct{}
+}
+
+// WriteStdout adds 'data' to the job's standard output. Followers are sent
+// it right away. Ignored once the job has exited
+func (j *FakeJob) WriteStdout(data []byte) {
+	j.write(false, data)
+}
+
+// WriteStderr is WriteStdout for standard error
+func (j *FakeJob) WriteStderr(data []byte) {
+	j.write(true, data)
+}
+
+// Exit completes the job with 'code'. Does nothing if it already exited
+func (j *FakeJob) Exit(code int32) {
+	j.finish(jobmanagerpb.Status_STATUS_COMPLETE, &code)
+}
+
+// Done is closed once the job has exited
+func (j *FakeJob) Done() <-chan struct{} {
+	return j.done
+}
+
+// Status of the job, as GetStatus reports it
+func (j *FakeJob) Status() jobmanagerpb.Status {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.status
+}
+
+func (j *FakeJob) write(stderr bool, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.status != jobmanagerpb.Status_STATUS_RUNNING {
+		return
+	}
+	chunk := append([]byte(nil), data...)
+	if stderr {
+		j.stderr = append(j.stderr, chunk)
+	} else {
+		j.stdout = append(j.stdout, chunk)
+	}
+	j.notifyLocked()
+}
+
+func (j *FakeJob) finish(st jobmanagerpb.Status, code *int32) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.status != jobmanagerpb.Status_STATUS_RUNNING {
+		return
+	}
+	j.status = st
+	j.exitCode = code
+	j.endTime = time.Now()
+	close(j.done)
+	j.notifyLocked()
+}
+
+func (j *FakeJob) notifyLocked() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// Output chunk 'n', or nil if it hasn't been written. Also returns whether
+// the job exited, and a channel closed once there's more to read
+func (j *FakeJob) chunk(stderr bool, n int) ([]byte, bool, <-chan struct{}) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	chunks := j.stdout
+	if stderr {
+		chunks = j.stderr
+	}
+	exited := j.status != jobmanagerpb.Status_STATUS_RUNNING
+	if n < len(chunks) {
+		return chunks[n], exited, j.changed
+	}
+	return nil, exited, j.changed
+}
+
+func (j *FakeJob) statusResponse() *jobmanagerpb.GetStatusResponse {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return &jobmanagerpb.GetStatusResponse{
+		CurrentStatus: j.status,
+		ExitCode:      j.exitCode,
+		Duration:      durationpb.New(j.durationLocked()),
+	}
+}
+
+func (j *FakeJob) record() *jobmanagerpb.JobRecord {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	record := &jobmanagerpb.JobRecord{
+		JobId:       j.ID[:],
+		Command:     j.command,
+		Args:        j.args,
+		Status:      j.status,
+		ExitCode:    j.exitCode,
+		StartTime:   timestamppb.New(j.startTime),
+		Attempts:    1,
+		MaxAttempts: 1,
+		Duration:    durationpb.New(j.durationLocked()),
+		Spec:        j.Request.Spec,
+	}
+	if !j.endTime.IsZero() {
+		record.EndTime = timestamppb.New(j.endTime)
+	}
+	return record
+}
+
+func (j *FakeJob) durationLocked() time.Duration {
+	if j.endTime.IsZero() {
+		return time.Since(j.startTime)
+	}
+	return j.endTime.Sub(j.startTime)
+}
@@ -0,0 +1,134 @@
+package clienttest_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/gopheryan/jobby/clienttest"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func startJob(t *testing.T, client jobmanagerpb.JobManagerClient, command string) string {
+	t.Helper()
+	resp, err := client.StartJob(context.Background(), &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{Command: command, Args: []string{command}},
+	})
+	require.NoError(t, err)
+	return resp.Id
+}
+
+func readOutput(t *testing.T, stream jobmanagerpb.JobManager_GetJobOutputClient) string {
+	t.Helper()
+	var data []byte
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return string(data)
+		}
+		require.NoError(t, err)
+		data = append(data, resp.Data...)
+	}
+}
+
+func TestFakeJobManager(t *testing.T) {
+	ctx := context.Background()
+	fake, err := clienttest.NewFakeJobManager()
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, fake.Close())
+	}()
+	client := fake.Client()
+
+	t.Run("scripted", func(tt *testing.T) {
+		fake.Script("build", clienttest.Script{Stdout: []byte("built\n"), Stderr: []byte("warning\n"), ExitCode: 2})
+		id := startJob(tt, client, "build")
+
+		resp, err := client.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{Id: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, resp.CurrentStatus)
+		require.NotNil(tt, resp.ExitCode)
+		assert.EqualValues(tt, 2, *resp.ExitCode)
+
+		stream, err := client.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{Id: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, "built\n", readOutput(tt, stream))
+		stream, err = client.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{Id: id, Type: jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR})
+		require.NoError(tt, err)
+		assert.Equal(tt, "warning\n", readOutput(tt, stream))
+	})
+
+	t.Run("held", func(tt *testing.T) {
+		fake.Script("serve", clienttest.Script{Stdout: []byte("one\n"), Hold: true})
+		id := startJob(tt, client, "serve")
+		job := fake.Job(id)
+		require.NotNil(tt, job)
+
+		stream, err := client.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{Id: id})
+		require.NoError(tt, err)
+		resp, err := stream.Recv()
+		require.NoError(tt, err)
+		assert.Equal(tt, "one\n", string(resp.Data))
+
+		// Followers get output as the test writes it
+		job.WriteStdout([]byte("two\n"))
+		resp, err = stream.Recv()
+		require.NoError(tt, err)
+		assert.Equal(tt, "two\n", string(resp.Data))
+
+		status, err := client.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{Id: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_RUNNING, status.CurrentStatus)
+
+		job.Exit(0)
+		resp, err = stream.Recv()
+		require.NoError(tt, err)
+		sum := sha256.Sum256([]byte("one\ntwo\n"))
+		assert.EqualValues(tt, 8, resp.End.GetTotalBytes())
+		assert.Equal(tt, sum[:], resp.End.GetSha256())
+		_, err = stream.Recv()
+		assert.ErrorIs(tt, err, io.EOF)
+		<-job.Done()
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, job.Status())
+	})
+
+	t.Run("stop", func(tt *testing.T) {
+		fake.Script("sleep", clienttest.Script{Hold: true})
+		id := startJob(tt, client, "sleep")
+		_, err := client.StopJob(ctx, &jobmanagerpb.StopJobRequest{Id: id})
+		require.NoError(tt, err)
+
+		resp, err := client.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{Id: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_STOPPED, resp.CurrentStatus)
+		assert.Nil(tt, resp.ExitCode)
+	})
+
+	t.Run("start error", func(tt *testing.T) {
+		fake.Script("broken", clienttest.Script{StartErr: status.Error(codes.ResourceExhausted, "Quota exceeded")})
+		_, err := client.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "broken"}})
+		assert.Equal(tt, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("unknown job", func(tt *testing.T) {
+		_, err := client.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{Id: "9b2f2c4e-7c1e-4c52-a1a4-3f0e8f1d6b27"})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("list", func(tt *testing.T) {
+		resp, err := client.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{CommandContains: "s"})
+		require.NoError(tt, err)
+		var commands []string
+		for _, record := range resp.Jobs {
+			commands = append(commands, record.Command)
+		}
+		assert.Equal(tt, []string{"serve", "sleep"}, commands)
+		assert.Len(tt, fake.Jobs(), 3)
+	})
+}
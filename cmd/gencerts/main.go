@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/certgen"
+)
+
+// Generates a CA, server certificate, and client certificates laid out the
+// way the server and jobcli expect to find them:
+//
+//	<out>/ca/ca.crt, <out>/ca/ca.key
+//	<out>/server/server.crt, <out>/server/server.key
+//	<out>/client/<user>/client.crt, <out>/client/<user>/client.key
+func main() {
+	out := flag.String("out", "testdata/certs", "directory to write certificates to")
+	hosts := flag.String("hosts", "localhost,127.0.0.1,::1", "comma separated list of server hostnames/IPs")
+	users := flag.String("users", "ryan", "comma separated list of users to generate client certificates for")
+	uriPrefix := flag.String("uri-prefix", certgen.DefaultClientURIPrefix, "client certificates carry a URI SAN of <prefix><user>")
+	emailDomain := flag.String("email-domain", certgen.DefaultClientEmailDomain, "client certificates carry an email SAN of <user>@<domain>, or <user> if it's an address")
+	days := flag.Int("days", 365, "number of days generated certificates are valid for")
+	newCA := flag.Bool("new-ca", false, "generate a new CA even if one already exists in the output directory")
+	skipServer := flag.Bool("skip-server", false, "do not (re)generate the server certificate")
+	flag.Parse()
+
+	err := certgen.GenerateTree(certgen.Layout{Dir: *out}, certgen.TreeOptions{
+		Hosts:             splitList(*hosts),
+		Users:             splitList(*users),
+		ClientURIPrefix:   *uriPrefix,
+		ClientEmailDomain: *emailDomain,
+		Validity:          time.Duration(*days) * 24 * time.Hour,
+		ReuseCA:           !*newCA,
+		SkipServer:        *skipServer,
+	})
+	if err != nil {
+		slog.Error("Failed to generate certificates", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Certificates written to %s\n", *out)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
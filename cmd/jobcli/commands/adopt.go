@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptStdout string
+	adoptStderr string
+	adoptOwner  string
+	adoptLabels map[string]string
+)
+
+func init() {
+	adoptCmd.Flags().StringVarP(&adoptStdout, "stdout", "", "", "file the process writes its standard output to")
+	adoptCmd.Flags().StringVarP(&adoptStderr, "stderr", "", "", "file the process writes its standard error to (--stdout's if unset)")
+	adoptCmd.Flags().StringVarP(&adoptOwner, "owner", "", "", "user the job belongs to (you if unset)")
+	adoptCmd.Flags().StringToStringVarP(&adoptLabels, "label", "l", nil, "KEY=VALUE labels to attach to the job")
+	_ = adoptCmd.MarkFlagRequired("stdout")
+
+	rootCmd.AddCommand(adoptCmd)
+}
+
+// For admins bringing processes the server didn't start (ex: daemons
+// being migrated) under its management
+var adoptCmd = &cobra.Command{
+	Use:  "adopt pid",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pid, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil || pid <= 0 {
+			return fmt.Errorf("invalid pid '%s'", args[0])
+		}
+		if adoptStderr == "" {
+			adoptStderr = adoptStdout
+		}
+		// The server resolves them, so relative ones would be relative to it
+		stdoutPath, err := filepath.Abs(adoptStdout)
+		if err != nil {
+			return err
+		}
+		stderrPath, err := filepath.Abs(adoptStderr)
+		if err != nil {
+			return err
+		}
+
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).AdoptProcess(cmd.Context(), &jobmanagerpb.AdoptProcessRequest{
+			Pid:        int32(pid),
+			StdoutPath: stdoutPath,
+			StderrPath: stderrPath,
+			Owner:      adoptOwner,
+			Labels:     adoptLabels,
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error adopting process: %w", err)
+		}
+		fmt.Printf("Adopted process %d as job: %s\n", pid, resp.Id)
+		return nil
+	},
+}
@@ -0,0 +1,282 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var scheduleFiles []string
+var applyPrune bool
+
+func init() {
+	for _, cmd := range []*cobra.Command{applyCmd, diffCmd} {
+		cmd.Flags().StringArrayVarP(&scheduleFiles, "file", "f", nil, "schedule file, or directory of them (*.yaml, *.yml, *.json). Repeat for more")
+		cmd.Flags().BoolVar(&applyPrune, "prune", false, "also remove your schedules that aren't in the files (ex: when -f is a directory of all of them)")
+		_ = cmd.MarkFlagRequired("file")
+		rootCmd.AddCommand(cmd)
+	}
+}
+
+const scheduleFilesHelp = `Each file holds one schedule, in YAML or JSON:
+
+  name: nightly-backup   # defaults to the file's name, without its extension
+  cron: "0 3 * * *"
+  missed_runs: MISSED_RUN_POLICY_SKIP   # or RUN_ONCE (the default) or RUN_ALL
+  jitter: 300s           # start each run up to this much late
+  spec:
+    command: /usr/local/bin/backup
+    args: [backup, --all]
+
+The spec has the same fields as 'start -f'`
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <dir>",
+	Short: "Make your schedules on the server match a set of files",
+	Long: `Creates and updates your schedules to match the files. With --prune, also
+removes the ones that aren't in them, so they're exactly the ones in the files.
+See 'diff' for what would change.
+
+` + scheduleFilesHelp,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reconcileSchedules(cmd, true)
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff -f <dir>",
+	Short: "Show what 'apply' would change",
+	Long: `Compares your schedules on the server with a set of files, without changing them.
+
+` + scheduleFilesHelp,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reconcileSchedules(cmd, false)
+	},
+}
+
+// How a schedule differs between the files and the server. 'desired' is
+// nil for schedules to remove, 'current' for ones to create
+type scheduleChange struct {
+	name     string
+	desired  *jobmanagerpb.Schedule
+	current  *jobmanagerpb.Schedule
+	modified []string
+}
+
+func reconcileSchedules(cmd *cobra.Command, apply bool) error {
+	desired, err := loadSchedules(scheduleFiles)
+	if err != nil {
+		return err
+	}
+
+	host, _ := cmd.Flags().GetString("host")
+	conn, err := newClientConnection(host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := jobmanagerpb.NewJobManagerClient(conn)
+
+	resp, err := client.ListSchedules(cmd.Context(), &jobmanagerpb.ListSchedulesRequest{})
+	if err != nil {
+		return fmt.Errorf("server returned error listing schedules: %w", err)
+	}
+	changes := planSchedules(desired, resp.Schedules, applyPrune)
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+	for _, change := range changes {
+		printScheduleChange(os.Stdout, change)
+		if apply {
+			if err := applyScheduleChange(cmd.Context(), client, change); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Schedules in 'paths', which may be files or directories of them
+func loadSchedules(paths []string) ([]*jobmanagerpb.Schedule, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+	}
+
+	var schedules []*jobmanagerpb.Schedule
+	seen := map[string]string{}
+	for _, file := range files {
+		schedule, err := loadSchedule(file)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[schedule.Name]; ok {
+			return nil, fmt.Errorf("schedule '%s' is in both %s and %s", schedule.Name, other, file)
+		}
+		seen[schedule.Name] = file
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func loadSchedule(path string) (*jobmanagerpb.Schedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading schedule: %w", err)
+	}
+	schedule := &jobmanagerpb.Schedule{}
+	if err := unmarshalSpec(data, schedule); err != nil {
+		return nil, fmt.Errorf("invalid schedule %s: %w", path, err)
+	}
+	if schedule.CreatedAt != nil || schedule.LastRun != nil || schedule.NextRun != nil {
+		return nil, fmt.Errorf("invalid schedule %s: created_at, last_run and next_run are set by the server", path)
+	}
+	if schedule.Name == "" {
+		schedule.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if schedule.Spec == nil {
+		return nil, fmt.Errorf("invalid schedule %s: spec is required", path)
+	}
+	return schedule, nil
+}
+
+// What to change for the server's schedules to be 'desired', by name.
+// Without 'prune', schedules that aren't desired are left alone
+func planSchedules(desired, current []*jobmanagerpb.Schedule, prune bool) []scheduleChange {
+	byName := map[string]*jobmanagerpb.Schedule{}
+	for _, schedule := range current {
+		byName[schedule.Name] = schedule
+	}
+	var changes []scheduleChange
+	for _, want := range desired {
+		have, ok := byName[want.Name]
+		delete(byName, want.Name)
+		if !ok {
+			changes = append(changes, scheduleChange{name: want.Name, desired: want})
+			continue
+		}
+		if modified := scheduleDifferences(have, want); len(modified) > 0 {
+			changes = append(changes, scheduleChange{name: want.Name, desired: want, current: have, modified: modified})
+		}
+	}
+	if prune {
+		for name, have := range byName {
+			changes = append(changes, scheduleChange{name: name, current: have})
+		}
+	}
+	slices.SortFunc(changes, func(a, b scheduleChange) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return changes
+}
+
+// Lines describing each field that differs, as "field: old -> new"
+func scheduleDifferences(have, want *jobmanagerpb.Schedule) []string {
+	var lines []string
+	if have.Cron != want.Cron {
+		lines = append(lines, fmt.Sprintf("cron: %q -> %q", have.Cron, want.Cron))
+	}
+	if have.MissedRuns != want.MissedRuns {
+		lines = append(lines, fmt.Sprintf("missed_runs: %s -> %s", have.MissedRuns, want.MissedRuns))
+	}
+	if have.Jitter.AsDuration() != want.Jitter.AsDuration() {
+		lines = append(lines, fmt.Sprintf("jitter: %s -> %s", have.Jitter.AsDuration(), want.Jitter.AsDuration()))
+	}
+	fields := (&jobmanagerpb.JobSpec{}).ProtoReflect().Descriptor().Fields()
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		was, now := specField(have.Spec, field), specField(want.Spec, field)
+		if was != now {
+			lines = append(lines, fmt.Sprintf("spec.%s: %s -> %s", field.TextName(), was, now))
+		}
+	}
+	return lines
+}
+
+// One field of 'spec' in JSON form, or "(unset)"
+func specField(spec *jobmanagerpb.JobSpec, field protoreflect.FieldDescriptor) string {
+	src := spec.ProtoReflect()
+	if !src.Has(field) {
+		return "(unset)"
+	}
+	single := &jobmanagerpb.JobSpec{}
+	single.ProtoReflect().Set(field, src.Get(field))
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(single)
+	if err != nil {
+		return "(invalid)"
+	}
+	// Just the value, without the braces and name around it
+	text := strings.TrimSpace(string(data))
+	text = strings.TrimPrefix(text, "{")
+	text = strings.TrimSuffix(text, "}")
+	_, value, _ := strings.Cut(text, ":")
+	return strings.TrimSpace(value)
+}
+
+func printScheduleChange(w io.Writer, change scheduleChange) {
+	switch {
+	case change.current == nil:
+		fmt.Fprintf(w, "+ %s\n", change.name)
+		fmt.Fprintf(w, "    cron: %q\n", change.desired.Cron)
+		if change.desired.MissedRuns != jobmanagerpb.MissedRunPolicy_MISSED_RUN_POLICY_UNSPECIFIED {
+			fmt.Fprintf(w, "    missed_runs: %s\n", change.desired.MissedRuns)
+		}
+		if change.desired.Jitter != nil {
+			fmt.Fprintf(w, "    jitter: %s\n", change.desired.Jitter.AsDuration())
+		}
+		spec, _ := protojson.MarshalOptions{UseProtoNames: true}.Marshal(change.desired.Spec)
+		fmt.Fprintf(w, "    spec: %s\n", spec)
+	case change.desired == nil:
+		fmt.Fprintf(w, "- %s\n", change.name)
+	default:
+		fmt.Fprintf(w, "~ %s\n", change.name)
+		for _, line := range change.modified {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+}
+
+func applyScheduleChange(ctx context.Context, client jobmanagerpb.JobManagerClient, change scheduleChange) error {
+	if change.desired == nil {
+		_, err := client.DeleteSchedule(ctx, &jobmanagerpb.DeleteScheduleRequest{Name: change.name})
+		if err != nil {
+			return fmt.Errorf("server returned error removing schedule '%s': %w", change.name, err)
+		}
+		return nil
+	}
+	if _, err := client.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: change.desired}); err != nil {
+		return fmt.Errorf("server returned error putting schedule '%s': %w", change.name, err)
+	}
+	return nil
+}
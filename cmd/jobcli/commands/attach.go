@@ -6,24 +6,76 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
 	"github.com/gopheryan/jobby/jobmanagerpb"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 var stdErr bool
+var bothStreams bool
+var attemptNumber uint32
+var batchBytes uint32
+var batchDelay time.Duration
+var collapseRepeats bool
+var lineMode bool
+var lineHold time.Duration
+var noFollow bool
+var rawOutput bool
+var stripTimestamps bool
+var coalesceBytes int
+var coalesceDelay time.Duration
+var downloadPath string
+var downloadStreams int
+
+const (
+	// Bytes of a GetJobOutputResponse besides its data. Generous, since
+	// the data field's tag and length only take a few
+	outputMessageOverhead = 64
+	// Most output the server puts in a message (see batch_max_bytes)
+	maxOutputMessageData = 1024 * 1024
+)
 
 func init() {
 
 	attachCmd.Flags().BoolVarP(&stdErr, "stderr", "", false, "attach to stderr output")
+	attachCmd.Flags().BoolVarP(&bothStreams, "both", "", false, "attach to stdout and stderr at once, writing each to ours")
+	attachCmd.Flags().Uint32VarP(&attemptNumber, "attempt", "", 0, "attempt to attach to (defaults to the latest)")
+	attachCmd.Flags().Uint32VarP(&batchBytes, "batch-bytes", "", 0, "ask the server to buffer up to this many bytes per message (server default if unset)")
+	attachCmd.Flags().BoolVarP(&collapseRepeats, "collapse-repeats", "", false, "collapse runs of identical lines into a 'last line repeated N times' line")
+	attachCmd.Flags().BoolVarP(&lineMode, "lines", "", false, "only receive complete lines")
+	attachCmd.Flags().DurationVarP(&lineHold, "line-hold", "", 0, "with --lines, send a partial line after this long anyway (server default if unset)")
+	attachCmd.Flags().DurationVarP(&batchDelay, "batch-delay", "", 0, "ask the server to buffer output for up to this long (server default if unset)")
+	attachCmd.Flags().BoolVarP(&noFollow, "no-follow", "", false, "exit after the output written so far instead of following the job")
+	attachCmd.Flags().BoolVarP(&rawOutput, "raw", "", false, "write stdout as is, even if the job declared a content type to render it by")
+	attachCmd.Flags().BoolVarP(&stripTimestamps, "strip-timestamps", "", false, "leave out the timestamps a job started with --timestamps has on each line")
+	attachCmd.Flags().IntVarP(&coalesceBytes, "coalesce-bytes", "", 64*1024, "gather small chunks of output into writes of up to this many bytes (0 writes each chunk as it arrives)")
+	attachCmd.Flags().DurationVarP(&coalesceDelay, "coalesce-delay", "", 20*time.Millisecond, "longest a gathered chunk of output waits to be written (0 writes each chunk as it arrives)")
+
+	attachCmd.Flags().StringVarP(&downloadPath, "download", "", "", "save the output of an attempt that's exited to this file, fetching pieces of it in parallel. Run again to resume a failed download")
+	attachCmd.Flags().IntVarP(&downloadStreams, "download-streams", "", 4, "with --download, how many pieces of the output to fetch at once")
+
+	attachCmd.MarkFlagsMutuallyExclusive("stderr", "both")
+	for _, flag := range []string{"both", "lines", "collapse-repeats", "strip-timestamps", "no-follow"} {
+		// Downloads are of the output exactly as it's stored
+		attachCmd.MarkFlagsMutuallyExclusive("download", flag)
+	}
 
 	rootCmd.AddCommand(attachCmd)
 }
 
 var attachCmd = &cobra.Command{
-	Use:  "attach job-id",
-	Args: cobra.ExactArgs(1),
+	Use:     "attach job-id",
+	Aliases: []string{"logs"},
+	Short:   "Follow a job's output, or save it with 'logs --download out.log job-id' once it's exited",
+	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		host, _ := cmd.Flags().GetString("host")
 		conn, err := newClientConnection(host)
@@ -33,7 +85,7 @@ var attachCmd = &cobra.Command{
 		defer conn.Close()
 
 		var id uuid.UUID
-		if id, err = uuid.Parse(args[0]); err != nil {
+		if id, err = jobid.Parse(args[0]); err != nil {
 			return fmt.Errorf("failed to parse job id: %w", err)
 		}
 
@@ -41,35 +93,135 @@ var attachCmd = &cobra.Command{
 		if stdErr {
 			outputType = jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR
 		}
+		if downloadPath != "" {
+			return downloadOutput(cmd.Context(), jobmanagerpb.NewJobManagerClient(conn), id, outputType, attemptNumber, downloadPath, downloadStreams)
+		}
 
-		return attachJob(cmd.Context(), id, outputType, os.Stdout, jobmanagerpb.NewJobManagerClient(conn))
+		batchMaxBytes, err := fitBatchBytes(batchBytes)
+		if err != nil {
+			return err
+		}
+		req := &jobmanagerpb.GetJobOutputRequest{
+			JobId:                 id[:],
+			Type:                  outputType,
+			Attempt:               attemptNumber,
+			BatchMaxBytes:         batchMaxBytes,
+			CollapseRepeatedLines: collapseRepeats,
+			NoFollow:              noFollow,
+			StripTimestamps:       stripTimestamps,
+		}
+		if cmd.Flags().Changed("batch-delay") {
+			req.BatchMaxDelay = durationpb.New(batchDelay)
+		}
+		if lineMode {
+			req.Mode = jobmanagerpb.StreamMode_STREAM_MODE_LINES
+		}
+		if cmd.Flags().Changed("line-hold") {
+			req.LineMaxHold = durationpb.New(lineHold)
+		}
+		client := jobmanagerpb.NewJobManagerClient(conn)
+		terminalOut := newCoalescingWriter(os.Stdout, coalesceBytes, coalesceDelay)
+		terminalErr := newCoalescingWriter(os.Stderr, coalesceBytes, coalesceDelay)
+		// The content type is declared for stdout only
+		var stdout io.WriteCloser = terminalOut
+		if !rawOutput && !stdErr {
+			jobStatus, err := getJobstatus(cmd.Context(), id, client)
+			if err != nil {
+				return err
+			}
+			stdout = newRenderer(jobStatus.OutputContentType, terminalOut)
+		}
+		if bothStreams {
+			err = attachBoth(cmd.Context(), req, stdout, terminalErr, client)
+		} else {
+			err = attachJob(cmd.Context(), req, stdout, client)
+		}
+		// Renders what arrived even if the stream failed. The renderer
+		// writes through the coalescer, so it's closed first
+		return errors.Join(err, stdout.Close(), terminalOut.Close(), terminalErr.Close())
 	},
 }
 
-func attachJob(ctx context.Context, jobId uuid.UUID, outputType jobmanagerpb.OutputType, dest io.Writer, jmClient jobmanagerpb.JobManagerClient) error {
+// Attach to stdout and stderr concurrently, so they keep their own file
+// descriptors for shell redirection. Interleaved as the server sends them.
+// Either stream failing ends the other
+func attachBoth(ctx context.Context, req *jobmanagerpb.GetJobOutputRequest, stdout io.Writer, stderr io.Writer, jmClient jobmanagerpb.JobManagerClient) error {
 	subCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	client, err := jmClient.GetJobOutput(subCtx, &jobmanagerpb.GetJobOutputRequest{
-		JobId: jobId[:],
-		Type:  outputType,
-	})
+	stderrReq := proto.Clone(req).(*jobmanagerpb.GetJobOutputRequest)
+	stderrReq.Type = jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR
+	req = proto.Clone(req).(*jobmanagerpb.GetJobOutputRequest)
+	req.Type = jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT
+
+	// Only the stream that failed first is reported, not the other's cancellation
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		if err != nil {
+			once.Do(func() {
+				firstErr = err
+				cancel()
+			})
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fail(attachJob(subCtx, stderrReq, stderr, jmClient))
+	}()
+	fail(attachJob(subCtx, req, stdout, jmClient))
+	wg.Wait()
+	return firstErr
+}
+
+func attachJob(ctx context.Context, req *jobmanagerpb.GetJobOutputRequest, dest io.Writer, jmClient jobmanagerpb.JobManagerClient) error {
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client, err := jmClient.GetJobOutput(subCtx, req)
 	if err != nil {
 		return fmt.Errorf("server returned error attaching to job output: %w", err)
 	}
 
+	verifier := newOutputVerifier()
 	var resp *jobmanagerpb.GetJobOutputResponse
 	for err == nil {
 		resp, err = client.Recv()
 		if err == nil {
+			if resp.End != nil {
+				if err := verifier.check(resp.End); err != nil {
+					return fmt.Errorf("output is incomplete or corrupted: %w", err)
+				}
+				continue
+			}
+			verifier.add(resp.Data)
 			if _, err = dest.Write(resp.Data); err != nil {
 				return fmt.Errorf("error writing output data to destination: %w", err)
 			}
 		}
 	}
 
+	if status.Code(err) == codes.ResourceExhausted {
+		return fmt.Errorf("error receiving output data (try a larger --max-message-size): %w", err)
+	}
 	if !errors.Is(err, io.EOF) {
 		return fmt.Errorf("error receiving output data: %w", err)
 	}
 	return nil
 }
+
+// The batch size to ask for, so the server's messages fit in the largest one
+// we accept. 0 (the server's default) when every batch size fits
+func fitBatchBytes(requested uint32) (uint32, error) {
+	limit := uint32(min(maxMessageSize-outputMessageOverhead, maxOutputMessageData))
+	if requested > limit {
+		return 0, fmt.Errorf("--batch-bytes must not exceed %d with a --max-message-size of %d", limit, maxMessageSize)
+	}
+	if requested == 0 && limit < maxOutputMessageData {
+		return limit, nil
+	}
+	return requested, nil
+}
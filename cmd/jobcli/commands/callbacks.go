@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+// Set by the server in jobs given a token (see --token-scope)
+const jobIDEnv = "JOBBY_JOB_ID"
+
+var callbackJob string
+
+func init() {
+	for _, cmd := range []*cobra.Command{reportProgressCmd, annotateCmd} {
+		cmd.Flags().StringVarP(&callbackJob, "job", "", os.Getenv(jobIDEnv), "job to act on. Defaults to $"+jobIDEnv+", so jobs act on themselves")
+		rootCmd.AddCommand(cmd)
+	}
+}
+
+var reportProgressCmd = &cobra.Command{
+	Use:   "report-progress percent [message]",
+	Short: "Report how far along a job started with --progress is",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "%"), 64)
+		if err != nil {
+			return fmt.Errorf("invalid percent '%s'", args[0])
+		}
+		req := &jobmanagerpb.ReportJobProgressRequest{Id: callbackJob, Percent: percent}
+		if len(args) > 1 {
+			req.Message = args[1]
+		}
+
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if _, err := jobmanagerpb.NewJobManagerClient(conn).ReportJobProgress(cmd.Context(), req); err != nil {
+			return fmt.Errorf("server returned error reporting progress: %w", err)
+		}
+		return nil
+	},
+}
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate KEY=VALUE...",
+	Short: "Attach notes to a job. An empty value removes the key",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		annotations := make(map[string]string, len(args))
+		for _, arg := range args {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return fmt.Errorf("invalid annotation '%s'. Expected KEY=VALUE", arg)
+			}
+			annotations[key] = value
+		}
+
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		req := &jobmanagerpb.AnnotateJobRequest{Id: callbackJob, Annotations: annotations}
+		if _, err := jobmanagerpb.NewJobManagerClient(conn).AnnotateJob(cmd.Context(), req); err != nil {
+			return fmt.Errorf("server returned error annotating job: %w", err)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Gathers small writes into fewer, bigger ones, so tailing a job that writes
+// a byte at a time doesn't cost a write to the terminal for every byte.
+// Buffered output is written once 'maxBytes' are waiting, or once the
+// oldest of it has waited 'maxDelay'
+type coalescingWriter struct {
+	lock     sync.Mutex
+	dest     io.Writer
+	maxBytes int
+	maxDelay time.Duration
+	pending  []byte
+	// Set while output is waiting
+	timer *time.Timer
+	// From writing to 'dest' after a delay. Returned by the next Write or Close
+	err error
+}
+
+// Writes straight to 'dest' unless both limits are positive
+func newCoalescingWriter(dest io.Writer, maxBytes int, maxDelay time.Duration) io.WriteCloser {
+	if maxBytes <= 0 || maxDelay <= 0 {
+		return nopCloser{dest}
+	}
+	return &coalescingWriter{dest: dest, maxBytes: maxBytes, maxDelay: maxDelay}
+}
+
+func (w *coalescingWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	if len(w.pending)+len(p) > w.maxBytes {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+		// Big writes don't need any help
+		if len(p) >= w.maxBytes {
+			return w.dest.Write(p)
+		}
+	}
+	w.pending = append(w.pending, p...)
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.maxDelay, w.flushLate)
+	}
+	return len(p), nil
+}
+
+// Write out what's pending. Caller must hold the lock
+func (w *coalescingWriter) flush() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := w.dest.Write(w.pending)
+	w.pending = w.pending[:0]
+	return err
+}
+
+func (w *coalescingWriter) flushLate() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if err := w.flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Close writes out what's pending. 'dest' is left open
+func (w *coalescingWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	return w.flush()
+}
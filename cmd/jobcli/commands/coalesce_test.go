@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Keeps each write separately
+type recordingWriter struct {
+	lock   sync.Mutex
+	writes []string
+	err    error
+}
+
+func (r *recordingWriter) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.writes = append(r.writes, string(p))
+	return len(p), nil
+}
+
+func (r *recordingWriter) written() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.writes
+}
+
+func TestCoalescingWriter(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		maxBytes int
+		maxDelay time.Duration
+		writes   []string
+		// Before Close, and after it
+		want      []string
+		wantClose []string
+	}{
+		{
+			name:     "small writes gathered",
+			maxBytes: 8, maxDelay: time.Hour,
+			writes:    []string{"a", "b", "c"},
+			want:      nil,
+			wantClose: []string{"abc"},
+		},
+		{
+			name:     "flushed when full",
+			maxBytes: 4, maxDelay: time.Hour,
+			writes:    []string{"ab", "cd", "ef"},
+			want:      []string{"abcd"},
+			wantClose: []string{"abcd", "ef"},
+		},
+		{
+			name:     "big writes go straight through",
+			maxBytes: 4, maxDelay: time.Hour,
+			writes:    []string{"ab", "cdefgh", "i"},
+			want:      []string{"ab", "cdefgh"},
+			wantClose: []string{"ab", "cdefgh", "i"},
+		},
+		{
+			name:     "exactly full",
+			maxBytes: 4, maxDelay: time.Hour,
+			writes:    []string{"abcd", "e"},
+			want:      []string{"abcd"},
+			wantClose: []string{"abcd", "e"},
+		},
+		{
+			name:     "no limit on bytes",
+			maxBytes: 0, maxDelay: time.Hour,
+			writes:    []string{"a", "b"},
+			want:      []string{"a", "b"},
+			wantClose: []string{"a", "b"},
+		},
+		{
+			name:     "no delay",
+			maxBytes: 8, maxDelay: 0,
+			writes:    []string{"a", "b"},
+			want:      []string{"a", "b"},
+			wantClose: []string{"a", "b"},
+		},
+		{
+			name:     "nothing written",
+			maxBytes: 8, maxDelay: time.Hour,
+		},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			dest := &recordingWriter{}
+			w := newCoalescingWriter(dest, tc.maxBytes, tc.maxDelay)
+			for _, write := range tc.writes {
+				n, err := w.Write([]byte(write))
+				require.NoError(tt, err)
+				assert.Equal(tt, len(write), n)
+			}
+			assert.Equal(tt, tc.want, dest.written())
+			require.NoError(tt, w.Close())
+			assert.Equal(tt, tc.wantClose, dest.written())
+		})
+	}
+
+	t.Run("flushed after delay", func(tt *testing.T) {
+		dest := &recordingWriter{}
+		w := newCoalescingWriter(dest, 1024, time.Millisecond)
+		_, err := w.Write([]byte("a"))
+		require.NoError(tt, err)
+		_, err = w.Write([]byte("b"))
+		require.NoError(tt, err)
+		assert.Eventually(tt, func() bool { return len(dest.written()) == 1 }, time.Second, time.Millisecond)
+		assert.Equal(tt, []string{"ab"}, dest.written())
+		require.NoError(tt, w.Close())
+		assert.Equal(tt, []string{"ab"}, dest.written())
+	})
+
+	t.Run("late errors", func(tt *testing.T) {
+		failure := errors.New("terminal on fire")
+		dest := &recordingWriter{err: failure}
+		w := newCoalescingWriter(dest, 1024, time.Millisecond)
+		_, err := w.Write([]byte("a"))
+		require.NoError(tt, err)
+		// The delayed write's error comes with the next call
+		assert.Eventually(tt, func() bool {
+			_, err := w.Write([]byte("b"))
+			return errors.Is(err, failure)
+		}, time.Second, time.Millisecond)
+		assert.ErrorIs(tt, w.Close(), failure)
+	})
+}
@@ -0,0 +1,296 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/gopheryan/jobby/client"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// How long to wait for the daemon's socket to answer before dialing the
+// server ourselves
+const daemonDialTimeout = 100 * time.Millisecond
+
+var daemonSocket string
+var noDaemon bool
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&daemonSocket, "daemon-socket", "", "Unix socket of a running 'jobcli daemon' (defaults to one per --host in $XDG_RUNTIME_DIR, or in a directory of yours in the temp directory)")
+	rootCmd.PersistentFlags().BoolVar(&noDaemon, "no-daemon", false, "dial the server directly even if a 'jobcli daemon' is running")
+	rootCmd.AddCommand(daemonCmd)
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep a connection to the server open for other jobcli commands to share",
+	Long: `Keeps an authenticated connection to --host open and serves it on a Unix socket.
+Other jobcli commands for the same --host find the socket and send their calls
+through it, skipping the TLS handshake. Runs until interrupted`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		path, err := daemonSocketPath(host)
+		if err != nil {
+			return err
+		}
+		upstream, err := dialServer(host)
+		if err != nil {
+			return err
+		}
+		defer upstream.Close()
+		// Handshake now, so the first command doesn't wait on it
+		upstream.Connect()
+
+		listener, err := listenDaemonSocket(path)
+		if err != nil {
+			return err
+		}
+		server := grpc.NewServer(
+			grpc.ForceServerCodec(rawCodec{}),
+			grpc.UnknownServiceHandler(forwardTo(upstream)),
+			// Commands enforce their own limits (see --max-message-size)
+			grpc.MaxRecvMsgSize(math.MaxInt32),
+			grpc.MaxSendMsgSize(math.MaxInt32),
+		)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, unix.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			server.Stop()
+		}()
+		fmt.Fprintf(os.Stderr, "Serving %s on %s\n", host, path)
+		err = server.Serve(listener)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	},
+}
+
+// Where the daemon for 'host' listens. Daemons for different hosts get
+// different sockets, so commands never reach the wrong server
+func daemonSocketPath(host string) (string, error) {
+	if daemonSocket != "" {
+		return filepath.Abs(daemonSocket)
+	}
+	sum := sha256.Sum256([]byte(host))
+	name := fmt.Sprintf("jobcli-%d-%s.sock", os.Getuid(), hex.EncodeToString(sum[:6]))
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, name), nil
+	}
+	return filepath.Join(daemonFallbackDir(), name), nil
+}
+
+// Where sockets go without $XDG_RUNTIME_DIR. Anyone may create names in
+// the temp directory, so they're kept in one of our own rather than at a
+// name someone else could take first
+func daemonFallbackDir() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("jobcli-%d", os.Getuid()))
+}
+
+// Create 'dir' if need be, and check that only we can use it
+func privateDir(dir string) error {
+	if err := os.Mkdir(dir, 0700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("error creating daemon socket directory: %w", err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("error checking daemon socket directory: %w", err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !info.IsDir() || !ok || int(stat.Uid) != os.Getuid() || info.Mode().Perm() != 0700 {
+		return fmt.Errorf("%s must be a directory only you can use (mode 0700)", dir)
+	}
+	return nil
+}
+
+// Whether the socket at 'path' was created by us. Anyone else's could be
+// listening to every call we send, and answering them as it likes
+func ownSocket(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode().Type() != fs.ModeSocket {
+		return false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
+
+// Listen on 'path', which only we may connect to. A socket left behind by
+// a daemon that's gone is replaced, but not one that's still answering
+func listenDaemonSocket(path string) (net.Listener, error) {
+	if filepath.Dir(path) == daemonFallbackDir() {
+		if err := privateDir(filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+	}
+	if conn, err := net.DialTimeout("unix", path, daemonDialTimeout); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and isn't a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("error removing stale daemon socket: %w", err)
+		}
+	}
+
+	// Connecting is as good as holding our credentials. Nobody else gets to
+	old := unix.Umask(0077)
+	listener, err := net.Listen("unix", path)
+	unix.Umask(old)
+	if err != nil {
+		return nil, fmt.Errorf("error listening on daemon socket: %w", err)
+	}
+	return listener, nil
+}
+
+// A client for the daemon serving 'host', if one is running and this
+// command may use it
+func dialDaemon(host string) (*client.Client, bool) {
+	if noDaemon || os.Getenv(jobTokenEnv) != "" {
+		// Calls from jobs are limited to what their token allows, which
+		// the daemon's credentials aren't
+		return nil, false
+	}
+	path, err := daemonSocketPath(host)
+	if err != nil {
+		return nil, false
+	}
+	if filepath.Dir(path) == daemonFallbackDir() && privateDir(filepath.Dir(path)) != nil {
+		// Someone else's, who could swap the socket for theirs once we've checked it
+		return nil, false
+	}
+	if _, err := os.Lstat(path); err == nil && !ownSocket(path) {
+		fmt.Fprintf(os.Stderr, "Warning: not using %s, which isn't a socket of yours\n", path)
+		return nil, false
+	}
+	conn, err := net.DialTimeout("unix", path, daemonDialTimeout)
+	if err != nil {
+		return nil, false
+	}
+	conn.Close()
+
+	c, err := client.New("unix://"+path,
+		client.WithPoolSize(connections),
+		client.WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize)),
+		),
+	)
+	if err != nil {
+		return nil, false
+	}
+	return c, true
+}
+
+// Passes every call the daemon gets on to 'upstream' as it is, streaming
+// or not. Messages aren't decoded (see rawCodec)
+func forwardTo(upstream *client.Client) grpc.StreamHandler {
+	return func(_ any, stream grpc.ServerStream) error {
+		method, ok := grpc.MethodFromServerStream(stream)
+		if !ok {
+			return errors.New("no method for stream")
+		}
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		md = md.Copy()
+		// Set by gRPC for the hop to the server
+		for _, key := range []string{"content-type", "user-agent", ":authority"} {
+			delete(md, key)
+		}
+		ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(stream.Context(), md))
+		defer cancel()
+
+		out, err := upstream.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, method,
+			grpc.ForceCodec(rawCodec{}), grpc.MaxCallRecvMsgSize(math.MaxInt32), grpc.MaxCallSendMsgSize(math.MaxInt32))
+		if err != nil {
+			return err
+		}
+
+		// Requests go up until the command is done sending
+		go func() {
+			for {
+				msg := &rawMessage{}
+				if err := stream.RecvMsg(msg); err != nil {
+					if errors.Is(err, io.EOF) {
+						_ = out.CloseSend()
+					} else {
+						cancel()
+					}
+					return
+				}
+				if err := out.SendMsg(msg); err != nil {
+					// The reason comes back on the receiving side
+					return
+				}
+			}
+		}()
+
+		// Responses come back until the server ends the call
+		header, err := out.Header()
+		if err == nil {
+			err = stream.SendHeader(header)
+		}
+		for err == nil {
+			msg := &rawMessage{}
+			if err = out.RecvMsg(msg); err == nil {
+				err = stream.SendMsg(msg)
+			}
+		}
+		stream.SetTrailer(out.Trailer())
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+}
+
+// An encoded message, passed along without being decoded
+type rawMessage struct {
+	data []byte
+}
+
+// Lets the daemon forward messages without knowing their types
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("can't forward %T", v)
+	}
+	return msg.data, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("can't forward %T", v)
+	}
+	// gRPC may reuse 'data' once we return
+	msg.data = append([]byte(nil), data...)
+	return nil
+}
+
+// Same content type as the messages being forwarded
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemonSocketPath(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	path, err := daemonSocketPath("jobby:8443")
+	require.NoError(t, err)
+	assert.Equal(t, "/run/user/1000", filepath.Dir(path))
+
+	// Never straight in the shared temp directory
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	path, err = daemonSocketPath("jobby:8443")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmp, fmt.Sprintf("jobcli-%d", os.Getuid())), filepath.Dir(path))
+	other, err := daemonSocketPath("other:8443")
+	require.NoError(t, err)
+	assert.NotEqual(t, path, other)
+
+	listener, err := listenDaemonSocket(path)
+	require.NoError(t, err)
+	defer listener.Close()
+	info, err := os.Stat(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	assert.True(t, ownSocket(path))
+}
+
+func TestPrivateDir(t *testing.T) {
+	base := t.TempDir()
+	for _, tc := range []struct {
+		name    string
+		setup   func(dir string) error
+		wantErr bool
+	}{
+		{name: "created", setup: func(string) error { return nil }},
+		{name: "already ours", setup: func(dir string) error { return os.Mkdir(dir, 0700) }},
+		{name: "others may use it", setup: func(dir string) error {
+			if err := os.Mkdir(dir, 0700); err != nil {
+				return err
+			}
+			return os.Chmod(dir, 0777)
+		}, wantErr: true},
+		{name: "symlink", setup: func(dir string) error {
+			target := dir + "-target"
+			if err := os.Mkdir(target, 0700); err != nil {
+				return err
+			}
+			return os.Symlink(target, dir)
+		}, wantErr: true},
+		{name: "file", setup: func(dir string) error { return os.WriteFile(dir, nil, 0600) }, wantErr: true},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			dir := filepath.Join(base, tc.name)
+			require.NoError(tt, tc.setup(dir))
+			err := privateDir(dir)
+			if tc.wantErr {
+				assert.ErrorContains(tt, err, "only you can use")
+				return
+			}
+			require.NoError(tt, err)
+		})
+	}
+}
+
+func TestOwnSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "daemon.sock")
+	assert.False(t, ownSocket(path))
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer listener.Close()
+	assert.True(t, ownSocket(path))
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+	assert.False(t, ownSocket(file))
+	if os.Getuid() == 0 {
+		// Only root can give it to someone else
+		require.NoError(t, os.Lchown(path, 12345, 12345))
+		assert.False(t, ownSocket(path))
+	}
+}
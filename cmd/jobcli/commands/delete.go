@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+var deleteForce bool
+
+func init() {
+	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "", false, "purge the job and its output right away, with no way to restore it")
+
+	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// Deleted jobs can be restored for a while, unless forced
+var deleteCmd = &cobra.Command{
+	Use:  "delete job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		var id uuid.UUID
+		if id, err = jobid.Parse(args[0]); err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).DeleteJob(cmd.Context(), &jobmanagerpb.DeleteJobRequest{
+			JobId: id[:],
+			Force: deleteForce,
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error deleting job: %w", err)
+		}
+		if resp.RestorableUntil == nil {
+			fmt.Printf("Purged job %s\n", args[0])
+			return nil
+		}
+		fmt.Printf("Deleted job %s. It can be restored until %s\n", args[0],
+			resp.RestorableUntil.AsTime().Local().Format(time.RFC3339))
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:  "restore job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		var id uuid.UUID
+		if id, err = jobid.Parse(args[0]); err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+
+		if _, err := jobmanagerpb.NewJobManagerClient(conn).RestoreJob(cmd.Context(), &jobmanagerpb.RestoreJobRequest{
+			JobId: id[:],
+		}); err != nil {
+			return fmt.Errorf("server returned error restoring job: %w", err)
+		}
+		fmt.Printf("Restored job %s\n", args[0])
+		return nil
+	},
+}
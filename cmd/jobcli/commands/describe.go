@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+}
+
+// Everything the server knows about a job, in one go
+var describeCmd = &cobra.Command{
+	Use:  "describe job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		id, err := jobid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).DescribeJob(cmd.Context(), &jobmanagerpb.DescribeJobRequest{JobId: id[:]})
+		if err != nil {
+			return fmt.Errorf("server returned error describing job: %w", err)
+		}
+
+		record, spec := resp.Record, resp.Record.Spec
+		fmt.Printf("Job: %s\n", record.Id)
+		if spec.Shell != "" {
+			fmt.Printf("Shell: %s\n", spec.Shell)
+		} else {
+			fmt.Printf("Command: %s\n", strings.Join(append([]string{spec.Command}, spec.Args...), " "))
+		}
+		if len(spec.Env) > 0 {
+			fmt.Printf("Env: %s\n", formatLabels(spec.Env))
+		}
+		if len(spec.Labels) > 0 {
+			fmt.Printf("Labels: %s\n", formatLabels(spec.Labels))
+		}
+		if len(record.Annotations) > 0 {
+			fmt.Printf("Annotations: %s\n", formatLabels(record.Annotations))
+		}
+		if record.ParentId != "" {
+			fmt.Printf("Parent: %s\n", record.ParentId)
+		}
+		if record.RuntimeClass != "" {
+			fmt.Printf("Runtime Class: %s\n", record.RuntimeClass)
+		}
+		if record.SessionId != "" {
+			fmt.Printf("Session: %s\n", record.SessionId)
+		}
+		if record.Adopted {
+			fmt.Println("Adopted")
+		}
+		fmt.Printf("Status: %s\n", resp.Status.CurrentStatus.String())
+		if resp.Status.ExitCode != nil {
+			fmt.Printf("Exit Code: %d\n", *resp.Status.ExitCode)
+		}
+		if reason := formatExitReason(resp.Status.ExitReason, resp.Status.Signal); reason != "" {
+			fmt.Printf("Exit Reason: %s\n", reason)
+		}
+		if reason := formatStateReason(resp.Status.StateReason, resp.Status.StateMessage); reason != "" {
+			fmt.Printf("Reason: %s\n", reason)
+		}
+		fmt.Printf("Started: %s\n", formatTimestamp(record.StartTime))
+		if record.EndTime != nil {
+			fmt.Printf("Ended: %s\n", formatTimestamp(record.EndTime))
+		}
+		fmt.Printf("Duration: %s\n", record.Duration.AsDuration())
+		fmt.Printf("Attempts: %d of %d\n", record.Attempts, record.MaxAttempts)
+		if usage := resp.Usage; usage != nil {
+			fmt.Printf("Usage: %s CPU, %s wall, %d bytes output\n", usage.CpuTime.AsDuration(), usage.WallTime.AsDuration(), usage.OutputBytes)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\nOUTPUT\tBYTES\tFILES\tSTORAGE")
+		for _, output := range resp.Outputs {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n",
+				strings.ToLower(strings.TrimPrefix(output.Type.String(), "OUTPUT_TYPE_")), output.Bytes, output.Files, formatStorage(output))
+		}
+		fmt.Fprintln(w, "\nATTEMPT\tSTATUS\tEXIT\tSTARTED\tDURATION")
+		for _, attempt := range resp.Attempts {
+			exit := "-"
+			if attempt.ExitCode != nil {
+				exit = fmt.Sprint(*attempt.ExitCode)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+				attempt.Number, strings.TrimPrefix(attempt.Status.String(), "STATUS_"), exit, formatTimestamp(attempt.StartTime), attempt.Duration.AsDuration())
+		}
+		fmt.Fprintln(w, "\nTIME\tEVENT\tACTOR\tATTEMPT\tDETAIL")
+		for _, event := range resp.Events {
+			attempt := "-"
+			if event.Attempt != 0 {
+				attempt = fmt.Sprint(event.Attempt)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				formatTimestamp(event.Time), strings.TrimPrefix(event.Type.String(), "JOB_EVENT_TYPE_"), event.Actor, attempt, event.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		snapshot := record.LaunchSnapshot
+		if snapshot == nil {
+			return nil
+		}
+		fmt.Printf("\nLaunch Snapshot (%s):\n", formatTimestamp(snapshot.CapturedAt))
+		fmt.Printf("  Host: %s (kernel %s, server %s)\n", snapshot.Hostname, snapshot.KernelRelease, snapshot.ServerVersion)
+		fmt.Printf("  Working Directory: %s\n", snapshot.WorkingDirectory)
+		fmt.Printf("  Binary: %s\n", snapshot.BinaryPath)
+		if snapshot.BinarySha256 != "" {
+			fmt.Printf("  SHA-256: %s\n", snapshot.BinarySha256)
+		}
+		fmt.Println("  Environment:")
+		for _, name := range slices.Sorted(maps.Keys(snapshot.Env)) {
+			fmt.Printf("    %s=%s\n", name, snapshot.Env[name])
+		}
+		return nil
+	},
+}
+
+func formatTimestamp(t *timestamppb.Timestamp) string {
+	return t.AsTime().Local().Format(time.RFC3339)
+}
+
+// Ex: "segmented, encrypted"
+func formatStorage(output *jobmanagerpb.OutputDescriptor) string {
+	if output.Sink {
+		return "sink"
+	}
+	var out []string
+	if output.Segmented {
+		out = append(out, "segmented")
+	}
+	if output.Encrypted {
+		out = append(out, "encrypted")
+	}
+	if len(out) == 0 {
+		return "file"
+	}
+	return strings.Join(out, ", ")
+}
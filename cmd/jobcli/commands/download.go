@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Outputs smaller than this per stream aren't worth splitting up
+const minDownloadPartBytes = 8 * 1024 * 1024
+
+// Download the output of an attempt that's exited to 'path', fetching up to
+// 'streams' ranges of it at once. Each range's checksum is checked against
+// the server's before the file is put in place. A download that fails can
+// be resumed by running it again: ranges already fetched aren't fetched again
+func downloadOutput(ctx context.Context, client jobmanagerpb.JobManagerClient, id uuid.UUID, outputType jobmanagerpb.OutputType,
+	attemptNumber uint32, path string, streams int) error {
+	attempts, err := getJobHistory(ctx, id, client)
+	if err != nil {
+		return err
+	}
+	var attempt *jobmanagerpb.Attempt
+	for _, a := range attempts {
+		if attemptNumber == 0 || a.Number == attemptNumber {
+			attempt = a
+		}
+	}
+	if attempt == nil {
+		return fmt.Errorf("job has no attempt %d", attemptNumber)
+	}
+	if attempt.EndTime == nil {
+		return fmt.Errorf("attempt %d is still running. Only the output of attempts that have exited can be downloaded", attempt.Number)
+	}
+	size := attempt.StdoutBytes
+	if outputType == jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR {
+		size = attempt.StderrBytes
+	}
+
+	// Written beside the destination, so a failed download doesn't leave
+	// a partial file where the output should be
+	partial, statePath := downloadPaths(path)
+	state := &downloadState{JobID: id.String(), Attempt: attempt.Number, Type: outputType, Size: size, Ranges: splitRanges(size, streams)}
+	f, resumed, err := openPartial(partial, statePath, state)
+	if err != nil {
+		return err
+	}
+	if resumed != nil {
+		state = resumed
+	} else if err := state.save(statePath); err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = fetchRanges(ctx, client, id, outputType, attempt.Number, f, state.Ranges, func() error {
+		// The data must be on disk before the state says it is
+		return errors.Join(f.Sync(), state.save(statePath))
+	})
+	err = errors.Join(err, f.Close())
+	if err != nil {
+		return fmt.Errorf("%w\nRun the same command again to resume the download", err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return err
+	}
+	_ = os.Remove(statePath)
+	return nil
+}
+
+// Where a download to 'path' is written until it's complete, and where its
+// progress is kept meanwhile
+func downloadPaths(path string) (partial string, state string) {
+	return path + ".part", path + ".part.json"
+}
+
+// Progress of a download, so one that fails can be resumed
+type downloadState struct {
+	JobID   string                  `json:"job_id"`
+	Attempt uint32                  `json:"attempt"`
+	Type    jobmanagerpb.OutputType `json:"type"`
+	Size    uint64                  `json:"size"`
+	Ranges  []*downloadRange        `json:"ranges"`
+}
+
+// Bytes [Start, End) of the output. Done once they're written and verified
+type downloadRange struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+	Done  bool   `json:"done"`
+}
+
+// Whether 's' is the progress of downloading the same output as 'other'
+func (s *downloadState) sameOutput(other *downloadState) bool {
+	return s.JobID == other.JobID && s.Attempt == other.Attempt && s.Type == other.Type && s.Size == other.Size
+}
+
+// Written to a temporary file first, so a crash mid-write can't leave it corrupted
+func (s *downloadState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", data, 0666); err != nil {
+		return fmt.Errorf("error saving download progress: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Open the partial file of a download. 'resumed' is the progress of an
+// earlier download of the same output, if one left its partial file
+// behind. Otherwise the partial file starts out empty
+func openPartial(partial string, statePath string, state *downloadState) (f *os.File, resumed *downloadState, err error) {
+	if data, err := os.ReadFile(statePath); err == nil {
+		var previous downloadState
+		if json.Unmarshal(data, &previous) == nil && previous.sameOutput(state) {
+			if f, err := os.OpenFile(partial, os.O_RDWR, 0); err == nil {
+				return f, &previous, nil
+			}
+		}
+	}
+	// Nothing to resume. Anything left behind is of some other output
+	_ = os.Remove(statePath)
+	f, err = os.Create(partial)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating download file: %w", err)
+	}
+	return f, nil, nil
+}
+
+// Split [0, size) into up to 'streams' ranges of at least minDownloadPartBytes
+// (the last may be shorter). There's always at least one range
+func splitRanges(size uint64, streams int) []*downloadRange {
+	parts := uint64(max(streams, 1))
+	if size/parts < minDownloadPartBytes {
+		parts = max(size/minDownloadPartBytes, 1)
+	}
+	partSize := (size + parts - 1) / parts
+
+	var ranges []*downloadRange
+	for start := uint64(0); ; start += partSize {
+		end := min(start+partSize, size)
+		ranges = append(ranges, &downloadRange{Start: start, End: end})
+		if end == size {
+			return ranges
+		}
+	}
+}
+
+// Fetch the ranges that aren't done yet at once, writing each to 'dest'
+// at its offset as it arrives. Each one is marked done and 'saveProgress'
+// called once it's complete. The first failure cancels the rest
+func fetchRanges(ctx context.Context, client jobmanagerpb.JobManagerClient, id uuid.UUID, outputType jobmanagerpb.OutputType,
+	attemptNumber uint32, dest io.WriterAt, ranges []*downloadRange, saveProgress func() error) error {
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var lock sync.Mutex
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	var wg sync.WaitGroup
+	for _, r := range ranges {
+		if r.Done {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := fetchRange(subCtx, client, id, outputType, attemptNumber, dest, r.Start, r.End)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				fail(err)
+				return
+			}
+			r.Done = true
+			if err := saveProgress(); err != nil {
+				fail(err)
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func fetchRange(ctx context.Context, client jobmanagerpb.JobManagerClient, id uuid.UUID, outputType jobmanagerpb.OutputType,
+	attemptNumber uint32, dest io.WriterAt, start, end uint64) error {
+	stream, err := client.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+		JobId:         id[:],
+		Type:          outputType,
+		Attempt:       attemptNumber,
+		BatchMaxBytes: uint32(min(maxMessageSize-outputMessageOverhead, maxOutputMessageData)),
+		Range:         &jobmanagerpb.ByteRange{Start: start, End: end},
+	})
+	if err != nil {
+		return fmt.Errorf("server returned error downloading output: %w", err)
+	}
+
+	verifier := newOutputVerifier()
+	offset := int64(start)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("bytes %d-%d of the output ended without a checksum", start, end)
+		} else if status.Code(err) == codes.OutOfRange {
+			return fmt.Errorf("output is shorter than the job's history says (was it deleted?): %w", err)
+		} else if err != nil {
+			return fmt.Errorf("error receiving bytes %d-%d of the output: %w", start, end, err)
+		}
+		if resp.End != nil {
+			if err := verifier.check(resp.End); err != nil {
+				return fmt.Errorf("bytes %d-%d of the output are incomplete or corrupted: %w", start, end, err)
+			}
+			if verifier.total != end-start {
+				return fmt.Errorf("received %d bytes of the output for bytes %d-%d", verifier.total, start, end)
+			}
+			return nil
+		}
+		verifier.add(resp.Data)
+		if _, err := dest.WriteAt(resp.Data, offset); err != nil {
+			return fmt.Errorf("error writing download file: %w", err)
+		}
+		offset += int64(len(resp.Data))
+	}
+}
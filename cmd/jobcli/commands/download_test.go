@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/testutils"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSplitRanges(t *testing.T) {
+	const min = minDownloadPartBytes
+	// A third of 10*min, rounded up
+	const third = (10*min + 2) / 3
+	for _, tc := range []struct {
+		name    string
+		size    uint64
+		streams int
+		want    [][2]uint64
+	}{
+		{"empty", 0, 4, [][2]uint64{{0, 0}}},
+		{"too small to split", min + 1, 4, [][2]uint64{{0, min + 1}}},
+		{"fewer parts than streams", 3 * min, 4, [][2]uint64{{0, min}, {min, 2 * min}, {2 * min, 3 * min}}},
+		{"one part per stream", 8*min + 2, 2, [][2]uint64{{0, 4*min + 1}, {4*min + 1, 8*min + 2}}},
+		{"last part shorter", 10 * min, 3, [][2]uint64{{0, third}, {third, 2 * third}, {2 * third, 10 * min}}},
+		{"no streams", 10 * min, 0, [][2]uint64{{0, 10 * min}}},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			var got [][2]uint64
+			for _, r := range splitRanges(tc.size, tc.streams) {
+				assert.False(tt, r.Done)
+				got = append(got, [2]uint64{r.Start, r.End})
+			}
+			assert.Equal(tt, tc.want, got)
+		})
+	}
+}
+
+func TestDownloadPaths(t *testing.T) {
+	partial, state := downloadPaths("/tmp/out.log")
+	assert.Equal(t, "/tmp/out.log.part", partial)
+	assert.Equal(t, "/tmp/out.log.part.json", state)
+}
+
+// Serves one exited attempt's stdout, honoring ranges
+type rangeServer struct {
+	jobmanagerpb.UnimplementedJobManagerServer
+	output []byte
+
+	lock sync.Mutex
+	// Ranges asked for, by start
+	requested []uint64
+	// Ranges starting here fail
+	failAt map[uint64]bool
+}
+
+func (s *rangeServer) GetJobHistory(context.Context, *jobmanagerpb.GetJobHistoryRequest) (*jobmanagerpb.GetJobHistoryResponse, error) {
+	return &jobmanagerpb.GetJobHistoryResponse{Attempts: []*jobmanagerpb.Attempt{
+		{Number: 1, EndTime: timestamppb.Now(), StdoutBytes: uint64(len(s.output))},
+	}}, nil
+}
+
+func (s *rangeServer) GetJobOutput(req *jobmanagerpb.GetJobOutputRequest, srv jobmanagerpb.JobManager_GetJobOutputServer) error {
+	start, end := req.Range.Start, req.Range.End
+	s.lock.Lock()
+	s.requested = append(s.requested, start)
+	fail := s.failAt[start]
+	s.lock.Unlock()
+	if fail {
+		return status.Error(codes.Unavailable, "Server is on fire")
+	}
+	data := s.output[start:end]
+	for chunk := range slices.Chunk(data, int(req.BatchMaxBytes)) {
+		if err := srv.Send(&jobmanagerpb.GetJobOutputResponse{Data: chunk}); err != nil {
+			return err
+		}
+	}
+	sum := sha256.Sum256(data)
+	return srv.Send(&jobmanagerpb.GetJobOutputResponse{End: &jobmanagerpb.OutputEnd{TotalBytes: uint64(len(data)), Sha256: sum[:]}})
+}
+
+func (s *rangeServer) takeRequested() []uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	requested := s.requested
+	s.requested = nil
+	slices.Sort(requested)
+	return requested
+}
+
+func TestDownloadOutput(t *testing.T) {
+	ctx := context.Background()
+	output := bytes.Repeat([]byte("0123456789abcdef"), 3*minDownloadPartBytes/16)
+	fake := &rangeServer{output: output, failAt: map[uint64]bool{minDownloadPartBytes: true}}
+	server := grpc.NewServer()
+	jobmanagerpb.RegisterJobManagerServer(server, fake)
+	var local testutils.GrpcLocalServer
+	require.NoError(t, local.ListenAndServe(server))
+	defer func() {
+		server.Stop()
+		_ = local.Done()
+	}()
+	client := jobmanagerpb.NewJobManagerClient(local.Conn())
+	id := uuid.New()
+	path := filepath.Join(t.TempDir(), "out.log")
+	partial, statePath := downloadPaths(path)
+
+	// A range fails. What was fetched is kept for next time
+	err := downloadOutput(ctx, client, id, jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT, 0, path, 4)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "again to resume")
+	assert.NoFileExists(t, path)
+	assert.FileExists(t, partial)
+	data, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	var state downloadState
+	require.NoError(t, json.Unmarshal(data, &state))
+	assert.Equal(t, id.String(), state.JobID)
+	require.Len(t, state.Ranges, 3)
+	assert.False(t, state.Ranges[1].Done)
+	var pending []uint64
+	for _, r := range state.Ranges {
+		if !r.Done {
+			pending = append(pending, r.Start)
+		}
+	}
+	// The others may have been cancelled before they were asked for
+	assert.Contains(t, fake.takeRequested(), uint64(minDownloadPartBytes))
+
+	// Resuming only fetches what's missing
+	fake.lock.Lock()
+	fake.failAt = nil
+	fake.lock.Unlock()
+	require.NoError(t, downloadOutput(ctx, client, id, jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT, 0, path, 4))
+	assert.Equal(t, pending, fake.takeRequested())
+	downloaded, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(output, downloaded))
+	assert.NoFileExists(t, partial)
+	assert.NoFileExists(t, statePath)
+
+	// Progress of some other download is ignored
+	require.NoError(t, os.WriteFile(partial, []byte("stale"), 0666))
+	state.JobID = uuid.NewString()
+	require.NoError(t, state.save(statePath))
+	require.NoError(t, downloadOutput(ctx, client, id, jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT, 0, path, 4))
+	assert.Equal(t, []uint64{0, minDownloadPartBytes, 2 * minDownloadPartBytes}, fake.takeRequested())
+	downloaded, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(output, downloaded))
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// Load balancing policies --lb-policy accepts. Named as gRPC names them
+const (
+	// Use the first endpoint that's up, in the order given
+	lbPickFirst = "pick_first"
+	// Spread calls over every endpoint that's up
+	lbRoundRobin = "round_robin"
+)
+
+// Scheme of the targets we resolve ourselves
+const endpointsScheme = "jobby-endpoints"
+
+var lbPolicy string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&lbPolicy, "lb-policy", lbPickFirst, "how calls are spread over several --host endpoints: pick_first or round_robin")
+}
+
+// Split --host into its endpoints. Several may be given separated by commas
+func parseEndpoints(host string) ([]string, error) {
+	var endpoints []string
+	for _, endpoint := range strings.Split(host, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			return nil, fmt.Errorf("invalid endpoint '%s': %w", endpoint, err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return nil, errors.New("--host must name at least one endpoint")
+	}
+	return endpoints, nil
+}
+
+// The target to dial for --host, along with the options that make it work.
+// A lone endpoint is dialed as it always was. Several are handed to gRPC
+// as the addresses of one target, so it can fail over between them or
+// balance calls across them without the commands knowing
+func endpointsTarget(host string) (string, []grpc.DialOption, error) {
+	endpoints, err := parseEndpoints(host)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(endpoints) == 1 {
+		return endpoints[0], nil, nil
+	}
+	if lbPolicy != lbPickFirst && lbPolicy != lbRoundRobin {
+		return "", nil, fmt.Errorf("--lb-policy must be %s or %s, not '%s'", lbPickFirst, lbRoundRobin, lbPolicy)
+	}
+
+	addresses := make([]resolver.Address, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		hostname, _, _ := net.SplitHostPort(endpoint)
+		// Each server is verified against its own name, not the target's
+		addresses = append(addresses, resolver.Address{Addr: endpoint, ServerName: hostname})
+	}
+	r := manual.NewBuilderWithScheme(endpointsScheme)
+	r.InitialState(resolver.State{Addresses: addresses})
+	return endpointsScheme + ":///" + strings.Join(endpoints, ","), []grpc.DialOption{
+		grpc.WithResolvers(r),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, lbPolicy)),
+	}, nil
+}
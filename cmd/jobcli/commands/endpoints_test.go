@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		host    string
+		want    []string
+		wantErr string
+	}{
+		{name: "one", host: "jobby:8443", want: []string{"jobby:8443"}},
+		{name: "several", host: "a:8443,b:8443,c:9000", want: []string{"a:8443", "b:8443", "c:9000"}},
+		{name: "spaces and empties", host: " a:8443, ,b:8443,", want: []string{"a:8443", "b:8443"}},
+		{name: "ipv6", host: "[::1]:8443,127.0.0.1:8443", want: []string{"[::1]:8443", "127.0.0.1:8443"}},
+		{name: "no port", host: "a:8443,b", wantErr: "invalid endpoint 'b'"},
+		{name: "empty", host: "", wantErr: "at least one endpoint"},
+		{name: "only commas", host: " , ", wantErr: "at least one endpoint"},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			got, err := parseEndpoints(tc.host)
+			if tc.wantErr != "" {
+				assert.ErrorContains(tt, err, tc.wantErr)
+				return
+			}
+			require.NoError(tt, err)
+			assert.Equal(tt, tc.want, got)
+		})
+	}
+}
+
+func TestEndpointsTarget(t *testing.T) {
+	defer func(policy string) { lbPolicy = policy }(lbPolicy)
+	for _, tc := range []struct {
+		name     string
+		host     string
+		policy   string
+		want     string
+		wantOpts int
+		wantErr  string
+	}{
+		{name: "one endpoint", host: "jobby:8443", policy: lbPickFirst, want: "jobby:8443"},
+		// Only matters when there's more than one
+		{name: "one endpoint, any policy", host: "jobby:8443", policy: "random", want: "jobby:8443"},
+		{name: "pick first", host: "a:8443,b:8443", policy: lbPickFirst, want: "jobby-endpoints:///a:8443,b:8443", wantOpts: 2},
+		{name: "round robin", host: "a:8443, b:8443", policy: lbRoundRobin, want: "jobby-endpoints:///a:8443,b:8443", wantOpts: 2},
+		{name: "unknown policy", host: "a:8443,b:8443", policy: "random", wantErr: "--lb-policy must be"},
+		{name: "bad endpoint", host: "a:8443,b", policy: lbPickFirst, wantErr: "invalid endpoint"},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			lbPolicy = tc.policy
+			target, opts, err := endpointsTarget(tc.host)
+			if tc.wantErr != "" {
+				assert.ErrorContains(tt, err, tc.wantErr)
+				return
+			}
+			require.NoError(tt, err)
+			assert.Equal(tt, tc.want, target)
+			assert.Len(tt, opts, tc.wantOpts)
+		})
+	}
+}
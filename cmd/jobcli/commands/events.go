@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+}
+
+var eventsCmd = &cobra.Command{
+	Use:  "events job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		id, err := jobid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).GetJobEvents(cmd.Context(), &jobmanagerpb.GetJobEventsRequest{
+			JobId: id[:],
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error getting job events: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tEVENT\tACTOR\tATTEMPT\tDETAIL")
+		for _, event := range resp.Events {
+			attempt := "-"
+			if event.Attempt != 0 {
+				attempt = fmt.Sprint(event.Attempt)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				event.Time.AsTime().Local().Format(time.RFC3339),
+				strings.TrimPrefix(event.Type.String(), "JOB_EVENT_TYPE_"),
+				event.Actor,
+				attempt,
+				event.Detail,
+			)
+		}
+		return w.Flush()
+	},
+}
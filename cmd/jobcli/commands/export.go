@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "output format. One of 'json' (JSON lines) or 'csv'")
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:  "export",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var write func(io.Writer, []exportRecord) error
+		switch exportFormat {
+		case "json":
+			write = writeJSONLines
+		case "csv":
+			write = writeCSV
+		default:
+			return fmt.Errorf("unknown export format %q", exportFormat)
+		}
+
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		records, err := exportJobs(cmd.Context(), jobmanagerpb.NewJobManagerClient(conn))
+		if err != nil {
+			return err
+		}
+		return write(os.Stdout, records)
+	},
+}
+
+// Flattened job record with friendlier types for serializing
+type exportRecord struct {
+	JobID       string     `json:"job_id"`
+	Command     string     `json:"command"`
+	Args        []string   `json:"args"`
+	Status      string     `json:"status"`
+	ExitCode    *int32     `json:"exit_code,omitempty"`
+	Attempts    uint32     `json:"attempts"`
+	MaxAttempts uint32     `json:"max_attempts"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	// Monotonic runtime reported by the server
+	DurationSeconds float64           `json:"duration_seconds"`
+	RuntimeClass    string            `json:"runtime_class,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+}
+
+func exportJobs(ctx context.Context, client jobmanagerpb.JobManagerClient) ([]exportRecord, error) {
+	stream, err := client.ExportJobs(ctx, &jobmanagerpb.ExportJobsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("server returned error exporting jobs: %w", err)
+	}
+
+	var records []exportRecord
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("server returned error exporting jobs: %w", err)
+		}
+
+		id, err := jobid.Resolve(msg.JobId, msg.Id)
+		if err != nil {
+			return nil, fmt.Errorf("server returned invalid job id: %w", err)
+		}
+		record := exportRecord{
+			JobID:       id.String(),
+			Command:     msg.Command,
+			Args:        msg.Args,
+			Status:      msg.Status.String(),
+			ExitCode:    msg.ExitCode,
+			Attempts:    msg.Attempts,
+			MaxAttempts: msg.MaxAttempts,
+			StartTime:   msg.StartTime.AsTime(),
+			// AsDuration is safe to call on nil
+			DurationSeconds: msg.Duration.AsDuration().Seconds(),
+			RuntimeClass:    msg.RuntimeClass,
+			// GetLabels is safe to call on a nil spec (older servers)
+			Labels: msg.Spec.GetLabels(),
+		}
+		if msg.EndTime != nil {
+			end := msg.EndTime.AsTime()
+			record.EndTime = &end
+		}
+		records = append(records, record)
+	}
+}
+
+func writeJSONLines(w io.Writer, records []exportRecord) error {
+	// Encode writes a newline after each value
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("error writing job record: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, records []exportRecord) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"job_id", "command", "args", "status", "exit_code", "attempts", "max_attempts", "start_time", "end_time", "duration_seconds", "runtime_class", "labels"})
+	for _, record := range records {
+		var exitCode, endTime string
+		if record.ExitCode != nil {
+			exitCode = strconv.Itoa(int(*record.ExitCode))
+		}
+		if record.EndTime != nil {
+			endTime = record.EndTime.Format(time.RFC3339Nano)
+		}
+		_ = cw.Write([]string{
+			record.JobID,
+			record.Command,
+			strings.Join(record.Args, " "),
+			record.Status,
+			exitCode,
+			strconv.FormatUint(uint64(record.Attempts), 10),
+			strconv.FormatUint(uint64(record.MaxAttempts), 10),
+			record.StartTime.Format(time.RFC3339Nano),
+			endTime,
+			strconv.FormatFloat(record.DurationSeconds, 'f', -1, 64),
+		})
+	}
+	// Write errors are sticky and reported here
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing job records: %w", err)
+	}
+	return nil
+}
+
+// KEY=VALUE pairs separated by commas, sorted by key
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+var removeOrphans bool
+
+func init() {
+	fsckCmd.Flags().BoolVarP(&removeOrphans, "remove-orphans", "", false, "remove orphaned files last modified over an hour ago")
+
+	adminCmd.AddCommand(fsckCmd)
+	rootCmd.AddCommand(adminCmd)
+}
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Commands for the server's admins",
+}
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Compare the server's output directory with its jobs",
+	Long: `Lists files in the output directory no job owns (orphans), output jobs are
+missing, and how much of the disk each user's jobs take up.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).CheckOutputDirectory(cmd.Context(), &jobmanagerpb.CheckOutputDirectoryRequest{
+			RemoveOrphans: removeOrphans,
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error checking output directory: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "USER\tJOBS\tFILES\tBYTES")
+		for _, usage := range resp.Usage {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", usage.User, usage.Jobs, usage.Files, usage.Bytes)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if len(resp.Orphans) > 0 {
+			fmt.Printf("\nOrphaned (%d bytes):\n", resp.OrphanedBytes)
+			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tBYTES\tMODIFIED\tREMOVED")
+			for _, orphan := range resp.Orphans {
+				fmt.Fprintf(w, "%s\t%d\t%s\t%t\n", orphan.Name, orphan.SizeBytes, orphan.Modified.AsTime().Format(time.RFC3339), orphan.Removed)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if len(resp.Missing) > 0 {
+			fmt.Println("\nMissing:")
+			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "JOB ID\tOWNER\tNAME")
+			for _, missing := range resp.Missing {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", missing.JobId, missing.Owner, missing.Name)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if len(resp.Unrecognized) > 0 {
+			fmt.Println("\nNot job output (left alone):")
+			for _, entry := range resp.Unrecognized {
+				fmt.Printf("  %s (%d bytes)\n", entry.Name, entry.SizeBytes)
+			}
+		}
+		fmt.Printf("\nTotal: %d bytes", resp.TotalBytes)
+		if resp.RemovedBytes > 0 {
+			fmt.Printf(", %d bytes of orphans removed", resp.RemovedBytes)
+		}
+		fmt.Println()
+		return nil
+	},
+}
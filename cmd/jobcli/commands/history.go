@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:  "history job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		var id uuid.UUID
+		if id, err = jobid.Parse(args[0]); err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+
+		attempts, err := getJobHistory(cmd.Context(), id, jobmanagerpb.NewJobManagerClient(conn))
+		if err != nil {
+			return err
+		}
+
+		for _, attempt := range attempts {
+			fmt.Printf("Attempt %d: %s\n", attempt.Number, attempt.Status.String())
+			fmt.Printf("  Started: %s\n", attempt.StartTime.AsTime().Local().Format(time.RFC3339))
+			if attempt.EndTime != nil {
+				fmt.Printf("  Ended: %s\n", attempt.EndTime.AsTime().Local().Format(time.RFC3339))
+			}
+			if attempt.Duration != nil {
+				fmt.Printf("  Duration: %s\n", attempt.Duration.AsDuration())
+			}
+			if attempt.ExitCode != nil {
+				fmt.Printf("  Exit Code: %d\n", *attempt.ExitCode)
+			}
+			if reason := formatExitReason(attempt.ExitReason, attempt.Signal); reason != "" {
+				fmt.Printf("  Exit Reason: %s\n", reason)
+			}
+			if attempt.Outcome != jobmanagerpb.Outcome_OUTCOME_UNSPECIFIED {
+				fmt.Printf("  Outcome: %s\n", formatOutcome(attempt.Outcome))
+			}
+			fmt.Printf("  Output: %d bytes stdout, %d bytes stderr\n", attempt.StdoutBytes, attempt.StderrBytes)
+			if attempt.TimedOut {
+				fmt.Println("  Timed out")
+			}
+			if attempt.QuotaExceeded {
+				fmt.Println("  Output quota exceeded")
+			}
+			if len(attempt.StderrTail) > 0 {
+				fmt.Printf("  Stderr (tail):\n%s\n", attempt.StderrTail)
+			}
+		}
+		return nil
+	},
+}
+
+func getJobHistory(ctx context.Context, jobId uuid.UUID, client jobmanagerpb.JobManagerClient) ([]*jobmanagerpb.Attempt, error) {
+	resp, err := client.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{
+		JobId: jobId[:],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("server returned error getting job history: %w", err)
+	}
+	return resp.Attempts, nil
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
+
+var infoCmd = &cobra.Command{
+	Use:  "info",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		info, err := jobmanagerpb.NewJobManagerClient(conn).GetServerInfo(cmd.Context(), &jobmanagerpb.GetServerInfoRequest{})
+		if err != nil {
+			return fmt.Errorf("server returned error getting info: %w", err)
+		}
+
+		fmt.Printf("Hostname: %s\n", info.Hostname)
+		if build := info.Build; build != nil {
+			fmt.Printf("Version: %s\n", build.Version)
+			if build.Commit != "" {
+				commit := build.Commit
+				if build.Modified {
+					commit += " (modified)"
+				}
+				fmt.Printf("Commit: %s\n", commit)
+			}
+			fmt.Printf("Go: %s\n", build.GoVersion)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if len(info.Features) > 0 {
+			fmt.Fprintln(w, "FEATURE\tENABLED\tDESCRIPTION")
+			for _, feature := range info.Features {
+				description := feature.Description
+				if feature.Experimental {
+					description += " (experimental)"
+				}
+				fmt.Fprintf(w, "%s\t%t\t%s\n", feature.Name, feature.Enabled, description)
+			}
+			fmt.Fprintln(w)
+		}
+		if len(info.Gpus) == 0 {
+			fmt.Fprintln(w, "No GPUs")
+			return w.Flush()
+		}
+		fmt.Fprintln(w, "GPU\tMODEL\tUUID\tBUS ID")
+		for _, gpu := range info.Gpus {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", gpu.Index, gpu.Model, gpu.Uuid, gpu.BusId)
+		}
+		return w.Flush()
+	},
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+var renewEvery time.Duration
+
+func init() {
+	rootCmd.AddCommand(renewLeaseCmd)
+	renewLeaseCmd.Flags().DurationVarP(&renewEvery, "every", "", 0, "keep renewing at this interval until the job ends, instead of once")
+}
+
+var renewLeaseCmd = &cobra.Command{
+	Use:   "renew-lease job-id",
+	Short: "Keep a job started with --lease from being stopped",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		var id uuid.UUID
+		if id, err = jobid.Parse(args[0]); err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+		client := jobmanagerpb.NewJobManagerClient(conn)
+		if renewEvery <= 0 {
+			expires, err := renewLease(cmd.Context(), id, client)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Lease renewed until %s\n", expires.Local().Format(time.RFC3339))
+			return nil
+		}
+
+		// Ends with an error once the job does, since its lease can't be renewed
+		ticker := time.NewTicker(renewEvery)
+		defer ticker.Stop()
+		for {
+			if _, err := renewLease(cmd.Context(), id, client); err != nil {
+				return err
+			}
+			<-ticker.C
+		}
+	},
+}
+
+func renewLease(ctx context.Context, jobId uuid.UUID, client jobmanagerpb.JobManagerClient) (time.Time, error) {
+	resp, err := client.RenewJobLease(ctx, &jobmanagerpb.RenewJobLeaseRequest{JobId: jobId[:]})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("server returned error renewing lease: %w", err)
+	}
+	return resp.ExpiresAt.AsTime(), nil
+}
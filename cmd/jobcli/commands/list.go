@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	listCommand  string
+	listAfter    string
+	listBefore   string
+	listExitCode int32
+	listSession  string
+	listParent   string
+	listTree     bool
+)
+
+func init() {
+	listCmd.Flags().StringVarP(&listCommand, "command", "c", "", "only jobs whose command contains this string")
+	listCmd.Flags().StringVarP(&listAfter, "after", "", "", "only jobs started after this time (RFC 3339)")
+	listCmd.Flags().StringVarP(&listBefore, "before", "", "", "only jobs started before this time (RFC 3339)")
+	listCmd.Flags().Int32VarP(&listExitCode, "exit-code", "", 0, "only jobs that exited with this code")
+	listCmd.Flags().StringVarP(&listSession, "session", "", "", "only jobs started in this session")
+	listCmd.Flags().StringVarP(&listParent, "parent", "", "", "only child jobs of this job")
+	listCmd.Flags().BoolVarP(&listTree, "tree", "", false, "show child jobs beneath the jobs that started them")
+
+	rootCmd.AddCommand(listCmd)
+}
+
+var listCmd = &cobra.Command{
+	Use:  "list",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := &jobmanagerpb.ListJobsRequest{CommandContains: listCommand, SessionId: listSession, ParentId: listParent}
+		var err error
+		if req.StartedAfter, err = parseListTime(listAfter); err != nil {
+			return fmt.Errorf("invalid --after: %w", err)
+		}
+		if req.StartedBefore, err = parseListTime(listBefore); err != nil {
+			return fmt.Errorf("invalid --before: %w", err)
+		}
+		if cmd.Flags().Changed("exit-code") {
+			req.ExitCode = &listExitCode
+		}
+
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		jobs, err := listJobs(cmd.Context(), req, jobmanagerpb.NewJobManagerClient(conn))
+		if err != nil {
+			return err
+		}
+
+		prefixes := make([]string, len(jobs))
+		if listTree {
+			jobs, prefixes = jobTree(jobs)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "JOB ID\tSTATUS\tEXIT CODE\tSTARTED\tCOMMAND")
+		for i, record := range jobs {
+			id, err := jobid.Resolve(record.JobId, record.Id)
+			if err != nil {
+				return fmt.Errorf("server returned invalid job id: %w", err)
+			}
+			var exitCode string
+			if record.ExitCode != nil {
+				exitCode = fmt.Sprint(*record.ExitCode)
+			}
+			fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\n",
+				prefixes[i],
+				id,
+				strings.TrimPrefix(record.Status.String(), "STATUS_"),
+				exitCode,
+				record.StartTime.AsTime().Local().Format(time.RFC3339),
+				recordCommand(record),
+			)
+		}
+		return w.Flush()
+	},
+}
+
+// Nil when unset
+func parseListTime(value string) (*timestamppb.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return timestamppb.New(t), nil
+}
+
+func listJobs(ctx context.Context, req *jobmanagerpb.ListJobsRequest, client jobmanagerpb.JobManagerClient) ([]*jobmanagerpb.JobRecord, error) {
+	resp, err := client.ListJobs(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("server returned error listing jobs: %w", err)
+	}
+	return resp.Jobs, nil
+}
+
+// Orders jobs so children follow their parent, along with the prefix
+// that draws each one's branch. Jobs whose parent wasn't listed are roots
+func jobTree(jobs []*jobmanagerpb.JobRecord) ([]*jobmanagerpb.JobRecord, []string) {
+	listed := make(map[string]bool, len(jobs))
+	for _, record := range jobs {
+		listed[record.Id] = true
+	}
+	children := make(map[string][]*jobmanagerpb.JobRecord)
+	var roots []*jobmanagerpb.JobRecord
+	for _, record := range jobs {
+		if record.ParentId != "" && listed[record.ParentId] {
+			children[record.ParentId] = append(children[record.ParentId], record)
+		} else {
+			roots = append(roots, record)
+		}
+	}
+
+	ordered := make([]*jobmanagerpb.JobRecord, 0, len(jobs))
+	prefixes := make([]string, 0, len(jobs))
+	var walk func(record *jobmanagerpb.JobRecord, indent, branch string)
+	walk = func(record *jobmanagerpb.JobRecord, indent, branch string) {
+		ordered = append(ordered, record)
+		prefixes = append(prefixes, indent+branch)
+		switch branch {
+		case "├─ ":
+			indent += "│  "
+		case "└─ ":
+			indent += "   "
+		}
+		kids := children[record.Id]
+		for i, child := range kids {
+			if i == len(kids)-1 {
+				walk(child, indent, "└─ ")
+			} else {
+				walk(child, indent, "├─ ")
+			}
+		}
+	}
+	for _, root := range roots {
+		walk(root, "", "")
+	}
+	return ordered, prefixes
+}
+
+// Shell jobs by their command line rather than the shell that ran it
+func recordCommand(record *jobmanagerpb.JobRecord) string {
+	if shell := record.Spec.GetShell(); shell != "" {
+		return shell
+	}
+	return record.Command
+}
@@ -0,0 +1,347 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/job"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+// Local jobs run on this machine with the job package, no server involved.
+// Each gets a directory under --dir with its output and a status file
+const (
+	localStdout = "stdout"
+	localStderr = "stderr"
+	localStatus = "status.json"
+)
+
+var (
+	localDir     string
+	localEnv     []string
+	localTimeout time.Duration
+	localStream  string
+	localStdin   string
+)
+
+func init() {
+	localCmd.PersistentFlags().StringVarP(&localDir, "dir", "", defaultLocalDir(), "directory local jobs are kept in")
+	localRunCmd.Flags().StringArrayVarP(&localEnv, "env", "e", nil, "KEY=VALUE environment variable to set for the job. Repeat for more")
+	localRunCmd.Flags().StringVarP(&localStdin, "stdin-file", "", "", "feed this file to the job's stdin ('-' for ours, to pipe data in)")
+	localRunCmd.Flags().DurationVarP(&localTimeout, "timeout", "", 0, "kill the job after it runs this long")
+	localOutputCmd.Flags().StringVarP(&localStream, "stream", "", localStdout, "output to print: stdout or stderr")
+
+	localCmd.AddCommand(localRunCmd, localStatusCmd, localListCmd, localOutputCmd)
+	rootCmd.AddCommand(localCmd)
+}
+
+// ~/.jobby/jobs, or a relative 'jobby-jobs' if there's no home directory
+func defaultLocalDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "jobby-jobs"
+	}
+	return filepath.Join(home, ".jobby", "jobs")
+}
+
+// What's kept in each local job's status file
+type localJob struct {
+	ID      string   `json:"id"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	// One of the job package's states (ex: RUNNING)
+	Status     string    `json:"status"`
+	PID        int       `json:"pid,omitempty"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time,omitzero"`
+	ExitCode   *int      `json:"exit_code,omitempty"`
+	ExitReason string    `json:"exit_reason,omitempty"`
+	// Ex: SIGKILL
+	Signal   string        `json:"signal,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+func (l *localJob) update(status job.Status) {
+	l.Status = string(status.CurrentState)
+	l.PID = status.PID
+	l.EndTime = status.EndTime
+	l.ExitCode = status.ReturnCode
+	l.ExitReason = string(status.ExitReason)
+	l.Duration = status.Duration
+	if status.Signal != 0 {
+		l.Signal = unix.SignalName(status.Signal)
+	}
+}
+
+// The exit code a shell would report for the job's process
+func (l *localJob) exitCode() int {
+	if l.ExitCode != nil {
+		return *l.ExitCode
+	}
+	if signal := unix.SignalNum(l.Signal); signal != 0 {
+		return 128 + int(signal)
+	}
+	return 1
+}
+
+func writeLocalJob(dir string, l *localJob) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Written aside and renamed, so readers never see half of it
+	tmp := filepath.Join(dir, localStatus+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing job status: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(dir, localStatus))
+}
+
+func readLocalJob(dir string) (*localJob, error) {
+	data, err := os.ReadFile(filepath.Join(dir, localStatus))
+	if err != nil {
+		return nil, fmt.Errorf("error reading job status: %w", err)
+	}
+	l := &localJob{}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("error parsing job status: %w", err)
+	}
+	return l, nil
+}
+
+// Directory of the local job with id 'arg'
+func localJobDir(arg string) (string, error) {
+	id, err := jobid.Parse(arg)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse job id: %w", err)
+	}
+	dir := filepath.Join(localDir, id.String())
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("no local job %s in %s", id, localDir)
+	}
+	return dir, nil
+}
+
+var localCmd = &cobra.Command{
+	Use:   "local",
+	Short: "Run jobs on this machine without a server, to try them out before submitting them",
+}
+
+// Runs in the foreground with the job's output on ours. The job is stopped
+// if we're interrupted, since it can't outlive us
+var localRunCmd = &cobra.Command{
+	Use:  "run command [arg] ...",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := uuid.New()
+		dir := filepath.Join(localDir, id.String())
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("error creating job directory: %w", err)
+		}
+
+		var stdinData io.ReadCloser
+		if localStdin != "" {
+			var err error
+			if stdinData, err = openStdinFile(localStdin); err != nil {
+				_ = os.RemoveAll(dir)
+				return err
+			}
+			defer stdinData.Close()
+		}
+		j, err := job.New(job.JobArgs{
+			Command:    args[0],
+			Args:       args[1:],
+			Env:        localEnv,
+			OutputDir:  dir,
+			StdoutPath: localStdout,
+			StderrPath: localStderr,
+			Limits:     job.Limits{Timeout: localTimeout},
+			Stdin:      stdinData != nil,
+		})
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return fmt.Errorf("error starting job: %w", err)
+		}
+		if stdinData != nil {
+			stdin, err := j.Stdin()
+			if err != nil {
+				_ = j.Stop()
+				return err
+			}
+			go func() {
+				// Stops early (with an error) if the job exits without reading it all
+				_, _ = io.Copy(stdin, stdinData)
+				_ = stdin.Close()
+			}()
+		}
+		record := &localJob{ID: id.String(), Command: args[0], Args: args[1:], StartTime: time.Now()}
+		record.update(j.Status())
+		if err := writeLocalJob(dir, record); err != nil {
+			_ = j.Stop()
+			return err
+		}
+		// Our stdout is the job's
+		fmt.Fprintf(os.Stderr, "Started Job: %s\n", id)
+
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt, unix.SIGTERM)
+		defer signal.Stop(interrupts)
+		go func() {
+			select {
+			case <-interrupts:
+				if err := j.Stop(); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to stop job: %v\n", err)
+				}
+			case <-j.Done():
+			}
+		}()
+
+		var wg sync.WaitGroup
+		var copyErrs [2]error
+		for i, stream := range []struct {
+			open func() (io.ReadCloser, error)
+			dest io.Writer
+		}{
+			{j.Stdout, os.Stdout},
+			{j.Stderr, os.Stderr},
+		} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				reader, err := stream.open()
+				if err != nil {
+					copyErrs[i] = err
+					return
+				}
+				defer reader.Close()
+				_, copyErrs[i] = io.Copy(stream.dest, reader)
+			}()
+		}
+		<-j.Done()
+		wg.Wait()
+
+		record.update(j.Status())
+		if err := errors.Join(writeLocalJob(dir, record), errors.Join(copyErrs[:]...)); err != nil {
+			return err
+		}
+		if code := record.exitCode(); code != 0 {
+			if reason := record.ExitReason; reason != string(job.ExitReasonExited) {
+				fmt.Fprintf(os.Stderr, "Job exited: %s\n", describeLocalExit(record))
+			}
+			cmd.SilenceErrors = true
+			return &exitCodeError{code: code}
+		}
+		return nil
+	},
+}
+
+// Ex: "TIMED_OUT (SIGKILL)"
+func describeLocalExit(l *localJob) string {
+	if l.Signal != "" {
+		return l.ExitReason + " (" + l.Signal + ")"
+	}
+	return l.ExitReason
+}
+
+var localStatusCmd = &cobra.Command{
+	Use:  "status job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := localJobDir(args[0])
+		if err != nil {
+			return err
+		}
+		l, err := readLocalJob(dir)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Status: %s\n", l.Status)
+		if l.ExitCode != nil {
+			fmt.Printf("Exit Code: %d\n", *l.ExitCode)
+		}
+		if l.ExitReason != "" {
+			fmt.Printf("Exit Reason: %s\n", describeLocalExit(l))
+		}
+		if l.Duration != 0 {
+			fmt.Printf("Duration: %s\n", l.Duration)
+		}
+		if l.PID != 0 {
+			fmt.Printf("PID: %d\n", l.PID)
+		}
+		fmt.Printf("Output: %s\n", dir)
+		return nil
+	},
+}
+
+var localListCmd = &cobra.Command{
+	Use:  "list",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := os.ReadDir(localDir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error listing local jobs: %w", err)
+		}
+		var jobs []*localJob
+		for _, entry := range entries {
+			if _, err := uuid.Parse(entry.Name()); err != nil || !entry.IsDir() {
+				continue
+			}
+			l, err := readLocalJob(filepath.Join(localDir, entry.Name()))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", entry.Name(), err)
+				continue
+			}
+			jobs = append(jobs, l)
+		}
+		// Oldest first, like the server's list
+		slices.SortFunc(jobs, func(a, b *localJob) int {
+			return a.StartTime.Compare(b.StartTime)
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "JOB ID\tSTATUS\tEXIT CODE\tSTARTED\tCOMMAND")
+		for _, l := range jobs {
+			exitCode := "-"
+			if l.ExitCode != nil {
+				exitCode = fmt.Sprint(*l.ExitCode)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.ID, l.Status, exitCode, l.StartTime.Format(time.DateTime), l.Command)
+		}
+		return w.Flush()
+	},
+}
+
+var localOutputCmd = &cobra.Command{
+	Use:  "output job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if localStream != localStdout && localStream != localStderr {
+			return fmt.Errorf("invalid --stream '%s'", localStream)
+		}
+		dir, err := localJobDir(args[0])
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(filepath.Join(dir, localStream))
+		if err != nil {
+			return fmt.Errorf("error opening job output: %w", err)
+		}
+		defer file.Close()
+		_, err = io.Copy(os.Stdout, file)
+		return err
+	},
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(progressCmd)
+}
+
+// Prints each progress report the job makes until it's finished
+var progressCmd = &cobra.Command{
+	Use:  "progress job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		id, err := jobid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+
+		stream, err := jobmanagerpb.NewJobManagerClient(conn).GetJobProgress(cmd.Context(), &jobmanagerpb.GetJobProgressRequest{
+			JobId: id[:],
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error getting job progress: %w", err)
+		}
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("error receiving job progress: %w", err)
+			}
+			if resp.Progress == nil {
+				fmt.Printf("Attempt %d: no progress reported yet\n", resp.Attempt)
+				continue
+			}
+			fmt.Printf("%s Attempt %d: %s\n",
+				resp.Progress.Time.AsTime().Local().Format(time.RFC3339),
+				resp.Attempt,
+				formatProgress(resp.Progress),
+			)
+		}
+	},
+}
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Renderers make output of a declared content type (see 'start --output-type')
+// easier to read. They're written to as output arrives, and Close flushes
+// whatever they held back
+
+// Output of plain or unknown types is written to 'dest' as is
+func newRenderer(contentType string, dest io.Writer) io.WriteCloser {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return newJSONRenderer(dest)
+	case "application/junit+xml":
+		return &junitRenderer{dest: dest}
+	}
+	return nopCloser{dest}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// Pretty-prints each JSON value as soon as it's complete, so streams of
+// them (ex: JSON lines) can be followed. If the output turns out not to
+// be JSON, the rest of it is passed through as is
+type jsonRenderer struct {
+	pipe *io.PipeWriter
+	done chan error
+}
+
+func newJSONRenderer(dest io.Writer) *jsonRenderer {
+	reader, writer := io.Pipe()
+	r := &jsonRenderer{pipe: writer, done: make(chan error, 1)}
+	go func() {
+		err := renderJSON(reader, dest)
+		// Fails writes from then on if 'dest' did
+		reader.CloseWithError(err)
+		r.done <- err
+	}()
+	return r
+}
+
+func (r *jsonRenderer) Write(p []byte) (int, error) {
+	return r.pipe.Write(p)
+}
+
+func (r *jsonRenderer) Close() error {
+	r.pipe.Close()
+	return <-r.done
+}
+
+func renderJSON(src io.Reader, dest io.Writer) error {
+	// Read by the decoder but not decoded yet. The decoder reads ahead,
+	// so this is what's passed through if the rest isn't JSON
+	var pending bytes.Buffer
+	decoder := json.NewDecoder(io.TeeReader(src, &pending))
+	var decoded int64
+	var out bytes.Buffer
+	for {
+		var value json.RawMessage
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			// Nothing but whitespace left
+			return nil
+		}
+		if err != nil {
+			if _, err := dest.Write(pending.Bytes()); err != nil {
+				return err
+			}
+			_, err = io.Copy(dest, src)
+			return err
+		}
+		offset := decoder.InputOffset()
+		pending.Next(int(offset - decoded))
+		decoded = offset
+
+		out.Reset()
+		// Can't fail, the decoder already checked it
+		_ = json.Indent(&out, value, "", "  ")
+		out.WriteByte('\n')
+		if _, err := dest.Write(out.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+// Summarizes JUnit XML results once the output ends, since they're only
+// valid XML then. Output that doesn't parse is passed through as is
+type junitRenderer struct {
+	dest   io.Writer
+	output bytes.Buffer
+}
+
+// Both <testsuites> and <testsuite>, which may nest
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	Skipped   *junitProblem `xml:"skipped"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitCounts struct {
+	tests, failed, errored, skipped int
+}
+
+// Ex: "12 tests, 1 failed, 2 skipped"
+func (c junitCounts) String() string {
+	out := fmt.Sprintf("%d tests", c.tests)
+	for _, count := range []struct {
+		n    int
+		what string
+	}{{c.failed, "failed"}, {c.errored, "errored"}, {c.skipped, "skipped"}} {
+		if count.n > 0 {
+			out += fmt.Sprintf(", %d %s", count.n, count.what)
+		}
+	}
+	return out
+}
+
+func (r *junitRenderer) Write(p []byte) (int, error) {
+	return r.output.Write(p)
+}
+
+func (r *junitRenderer) Close() error {
+	var root junitSuite
+	if err := xml.Unmarshal(r.output.Bytes(), &root); err != nil {
+		_, err := r.dest.Write(r.output.Bytes())
+		return err
+	}
+
+	var summary strings.Builder
+	var total junitCounts
+	var walk func(suite junitSuite)
+	walk = func(suite junitSuite) {
+		if len(suite.Cases) > 0 {
+			var counts junitCounts
+			var problems strings.Builder
+			for _, c := range suite.Cases {
+				counts.tests++
+				name := c.Name
+				if c.Classname != "" {
+					name = c.Classname + "." + c.Name
+				}
+				switch {
+				case c.Failure != nil:
+					counts.failed++
+					fmt.Fprintf(&problems, "    FAIL  %s: %s\n", name, c.Failure.Message)
+				case c.Error != nil:
+					counts.errored++
+					fmt.Fprintf(&problems, "    ERROR %s: %s\n", name, c.Error.Message)
+				case c.Skipped != nil:
+					counts.skipped++
+				}
+			}
+			result := "PASS"
+			if counts.failed > 0 || counts.errored > 0 {
+				result = "FAIL"
+			}
+			fmt.Fprintf(&summary, "%s %s: %s\n%s", result, suite.Name, counts, problems.String())
+			total.tests += counts.tests
+			total.failed += counts.failed
+			total.errored += counts.errored
+			total.skipped += counts.skipped
+		}
+		for _, nested := range suite.Suites {
+			walk(nested)
+		}
+	}
+	walk(root)
+	fmt.Fprintf(&summary, "Total: %s\n", total)
+	_, err := io.WriteString(r.dest, summary.String())
+	return err
+}
@@ -1,11 +1,17 @@
 package commands
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
+	"github.com/gopheryan/jobby/client"
+	"github.com/gopheryan/jobby/internal/spiffeauth"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -15,10 +21,39 @@ const (
 	clientKeyPath  = "client/ryan/client.key"
 	clientCertPath = "client/ryan/client.crt"
 	caPath         = "ca/ca.crt"
+
+	// How long to wait for an SVID from the Workload API
+	spiffeTimeout = 10 * time.Second
+
+	// gRPC's own default
+	defaultMaxMessageSize = 4 * 1024 * 1024
+
+	// Set by the server in jobs given a token (see --token-scope), and the request
+	// metadata the token is sent back in (see service.JobTokenEnv)
+	jobTokenEnv    = "JOBBY_JOB_TOKEN"
+	jobTokenHeader = "x-jobby-job-token"
+)
+
+// SPIFFE Workload API settings (see 'newClientConnection')
+var (
+	useSPIFFE    bool
+	spiffeSocket string
+	serverID     string
 )
 
+// Largest message we'll accept from the server, in bytes
+var maxMessageSize int
+
+// Connections to spread a command's calls over
+var connections int
+
 func init() {
-	rootCmd.PersistentFlags().String("host", "localhost:8443", "server hostname:port")
+	rootCmd.PersistentFlags().String("host", "localhost:8443", "server hostname:port. Separate several with commas to fail over between them (see --lb-policy)")
+	rootCmd.PersistentFlags().BoolVar(&useSPIFFE, "spiffe", false, "obtain client credentials from the SPIFFE Workload API instead of files")
+	rootCmd.PersistentFlags().StringVar(&spiffeSocket, "spiffe-socket", "", "SPIFFE Workload API address (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+	rootCmd.PersistentFlags().StringVar(&serverID, "server-id", "", "SPIFFE ID the server must present (any ID in the trust bundle when empty)")
+	rootCmd.PersistentFlags().IntVar(&maxMessageSize, "max-message-size", defaultMaxMessageSize, "largest message to accept from the server, in bytes. Output is requested in messages that fit")
+	rootCmd.PersistentFlags().IntVar(&connections, "connections", 1, "connections to the server to spread calls over (ex: for attach --download with many streams)")
 }
 
 var rootCmd = &cobra.Command{
@@ -30,12 +65,73 @@ var rootCmd = &cobra.Command{
 	},
 }
 
-func newClientConnection(host string) (*grpc.ClientConn, error) {
-	cfg, err := newTLSConfig()
+// One client per command, shared by every call it makes. Goes through
+// the daemon for 'host' if one is running (see 'jobcli daemon')
+func newClientConnection(host string) (*client.Client, error) {
+	if c, ok := dialDaemon(host); ok {
+		return c, nil
+	}
+	return dialServer(host)
+}
+
+func dialServer(host string) (*client.Client, error) {
+	target, opts, err := endpointsTarget(host)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *tls.Config
+	var source io.Closer
+	jobToken := os.Getenv(jobTokenEnv)
+	switch {
+	case useSPIFFE:
+		cfg, source, err = newSPIFFETLSConfig()
+	case jobToken != "":
+		// Running in a job, which calls back as its owner with its token
+		cfg, err = newTokenTLSConfig()
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials(jobToken)))
+	default:
+		cfg, err = newTLSConfig()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error creating TLS config: %w", err)
 	}
-	return grpc.NewClient(host, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+
+	if maxMessageSize <= outputMessageOverhead {
+		return nil, fmt.Errorf("--max-message-size must be more than %d bytes", outputMessageOverhead)
+	}
+	opts = append(opts,
+		grpc.WithTransportCredentials(credentials.NewTLS(cfg)),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize)),
+	)
+	clientOpts := []client.Option{client.WithDialOptions(opts...), client.WithPoolSize(connections)}
+	if source != nil {
+		// The credentials have to outlive the connections
+		clientOpts = append(clientOpts, client.WithCloseHook(source.Close))
+	}
+	c, err := client.New(target, clientOpts...)
+	if err != nil {
+		if source != nil {
+			_ = source.Close()
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func newSPIFFETLSConfig() (*tls.Config, io.Closer, error) {
+	authorizer, err := spiffeauth.Authorizer(serverID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spiffeTimeout)
+	defer cancel()
+	source, err := spiffeauth.NewSource(ctx, spiffeSocket)
+	if err != nil {
+		return nil, nil, err
+	}
+	return spiffeauth.ClientTLSConfig(source, authorizer), source, nil
 }
 
 func newTLSConfig() (*tls.Config, error) {
@@ -44,6 +140,16 @@ func newTLSConfig() (*tls.Config, error) {
 		return nil, fmt.Errorf("error loading client key/cert: %w", err)
 	}
 
+	cfg, err := newTokenTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Certificates = []tls.Certificate{clientCert}
+	return cfg, nil
+}
+
+// Verifies the server, without a client certificate
+func newTokenTLSConfig() (*tls.Config, error) {
 	caData, err := os.ReadFile(caPath)
 	if err != nil {
 		return nil, fmt.Errorf("error loading ca certificate %w", err)
@@ -53,14 +159,38 @@ func newTLSConfig() (*tls.Config, error) {
 	pool.AppendCertsFromPEM(caData)
 
 	return &tls.Config{
-		RootCAs:      pool,
-		Certificates: []tls.Certificate{clientCert},
-		MinVersion:   tls.VersionTLS13,
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS13,
 	}, nil
 }
 
+// Sends a job's token with every call
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{jobTokenHeader: string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// Makes the CLI exit with 'code' (ex: to pass on a job's exit code).
+// Commands returning one should set SilenceErrors, since it isn't much of an error
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var (
+	runClass   string
+	runEnv     map[string]string
+	runTimeout time.Duration
+)
+
+func init() {
+	runCmd.Flags().StringVarP(&runClass, "class", "", "", "runtime class (preset of limits) to run the job with (server default if unset)")
+	runCmd.Flags().StringToStringVarP(&runEnv, "env", "e", nil, "KEY=VALUE environment variables to set for the job")
+	runCmd.Flags().DurationVarP(&runTimeout, "timeout", "", 0, "kill the job after it runs this long (may only shorten the class's timeout)")
+
+	rootCmd.AddCommand(runCmd)
+}
+
+// Runs a job as if it were a local command: our stdin is streamed to it
+// (ex: 'cat data | jobcli run -- /usr/bin/wc wc -l'), its output written
+// to ours, and we exit with its exit code once it's done. Like start, the
+// command is the executable's path, then the job's full argv
+var runCmd = &cobra.Command{
+	Use:  "run [flags] -- command [arg] ...",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		spec := &jobmanagerpb.JobSpec{
+			Command:      args[0],
+			Args:         args[1:],
+			Env:          runEnv,
+			RuntimeClass: runClass,
+			MaxAttempts:  1,
+			Stdin:        true,
+		}
+		if runTimeout != 0 {
+			spec.Timeout = durationpb.New(runTimeout)
+		}
+		code, err := runJob(cmd.Context(), jobmanagerpb.NewJobManagerClient(conn), spec, os.Stdin, os.Stdout, os.Stderr)
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			cmd.SilenceErrors = true
+			return &exitCodeError{code: code}
+		}
+		return nil
+	},
+}
+
+// Start a job with 'spec', feed it 'stdin' and copy its output to 'stdout'
+// and 'stderr' until it exits. Returns its exit code
+func runJob(ctx context.Context, client jobmanagerpb.JobManagerClient, spec *jobmanagerpb.JobSpec, stdin io.Reader,
+	stdout io.Writer, stderr io.Writer) (int, error) {
+	batchMaxBytes, err := fitBatchBytes(0)
+	if err != nil {
+		return 0, err
+	}
+	id, err := startJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec}, client)
+	if err != nil {
+		return 0, err
+	}
+	fmt.Fprintf(stderr, "Started Job: %s\n", id.String())
+
+	// Jobs needn't read all of stdin, so we don't wait for the upload once
+	// the job has exited (ex: on a terminal, for input that never comes)
+	uploadCtx, cancelUpload := context.WithCancel(ctx)
+	defer cancelUpload()
+	uploaded := make(chan error, 1)
+	go func() {
+		_, err := uploadStdin(uploadCtx, client, id, stdin)
+		uploaded <- err
+	}()
+
+	req := &jobmanagerpb.GetJobOutputRequest{JobId: id[:], BatchMaxBytes: batchMaxBytes}
+	if err := attachBoth(ctx, req, stdout, stderr, client); err != nil {
+		return 0, err
+	}
+	resp, err := client.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: id[:]})
+	if err != nil {
+		return 0, fmt.Errorf("server returned error waiting for job: %w", err)
+	}
+	select {
+	case err := <-uploaded:
+		if err != nil {
+			fmt.Fprintf(stderr, "Warning: %s\n", err)
+		}
+	default:
+	}
+	return exitCode(id, resp, stderr), nil
+}
+
+// The job's exit code, noting why it exited if it failed
+func exitCode(id uuid.UUID, resp *jobmanagerpb.GetStatusResponse, stderr io.Writer) int {
+	code := jobExitCode(resp)
+	if code != 0 {
+		if reason := formatExitReason(resp.ExitReason, resp.Signal); reason != "" {
+			fmt.Fprintf(stderr, "Job %s exited: %s\n", id.String(), reason)
+		}
+	}
+	return code
+}
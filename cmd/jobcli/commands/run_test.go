@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/testutils"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// Runs 'tr a-z A-Z' on whatever it's sent, failing with exit code 3
+type upcaseServer struct {
+	jobmanagerpb.UnimplementedJobManagerServer
+	id uuid.UUID
+	// What was sent to stdin, once it's closed
+	stdin chan []byte
+	spec  *jobmanagerpb.JobSpec
+}
+
+func (s *upcaseServer) StartJob(_ context.Context, req *jobmanagerpb.StartJobRequest) (*jobmanagerpb.StartJobResponse, error) {
+	s.spec = req.Spec
+	return &jobmanagerpb.StartJobResponse{JobId: s.id[:]}, nil
+}
+
+func (s *upcaseServer) WriteJobStdin(srv jobmanagerpb.JobManager_WriteJobStdinServer) error {
+	var data []byte
+	for {
+		msg, err := srv.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data = append(data, msg.Data...)
+	}
+	s.stdin <- data
+	return srv.SendAndClose(&jobmanagerpb.WriteJobStdinResponse{BytesWritten: uint64(len(data))})
+}
+
+func (s *upcaseServer) GetJobOutput(req *jobmanagerpb.GetJobOutputRequest, srv jobmanagerpb.JobManager_GetJobOutputServer) error {
+	data := []byte("converted\n")
+	if req.Type == jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT {
+		select {
+		case stdin := <-s.stdin:
+			data = bytes.ToUpper(stdin)
+		case <-srv.Context().Done():
+			return srv.Context().Err()
+		}
+	}
+	if err := srv.Send(&jobmanagerpb.GetJobOutputResponse{Data: data}); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	return srv.Send(&jobmanagerpb.GetJobOutputResponse{End: &jobmanagerpb.OutputEnd{TotalBytes: uint64(len(data)), Sha256: sum[:]}})
+}
+
+func (s *upcaseServer) WaitJob(context.Context, *jobmanagerpb.WaitJobRequest) (*jobmanagerpb.GetStatusResponse, error) {
+	code := int32(3)
+	return &jobmanagerpb.GetStatusResponse{ExitCode: &code, ExitReason: jobmanagerpb.ExitReason_EXIT_REASON_EXITED}, nil
+}
+
+func TestRunJob(t *testing.T) {
+	fake := &upcaseServer{id: uuid.New(), stdin: make(chan []byte, 1)}
+	server := grpc.NewServer()
+	jobmanagerpb.RegisterJobManagerServer(server, fake)
+	var local testutils.GrpcLocalServer
+	require.NoError(t, local.ListenAndServe(server))
+	defer func() {
+		server.Stop()
+		_ = local.Done()
+	}()
+
+	// More than one chunk, to be sure they're all sent intact
+	input := strings.Repeat("some data\n", 2*stdinChunkBytes/10)
+	var stdout, stderr bytes.Buffer
+	spec := &jobmanagerpb.JobSpec{Command: "/usr/bin/tr", Args: []string{"tr", "a-z", "A-Z"}, Stdin: true}
+	code, err := runJob(context.Background(), jobmanagerpb.NewJobManagerClient(local.Conn()), spec,
+		strings.NewReader(input), &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+	assert.True(t, fake.spec.Stdin)
+	assert.Equal(t, strings.ToUpper(input), stdout.String())
+	assert.Contains(t, stderr.String(), "Started Job: "+fake.id.String())
+	assert.Contains(t, stderr.String(), "converted\n")
+	assert.Contains(t, stderr.String(), "exited: EXITED")
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+var upcomingRuns uint32
+
+func init() {
+	scheduleRunsCmd.Flags().Uint32VarP(&upcomingRuns, "upcoming", "n", 0, "how many upcoming runs to show (server default if unset)")
+	rootCmd.AddCommand(scheduleRunsCmd)
+}
+
+var scheduleRunsCmd = &cobra.Command{
+	Use:   "schedule-runs name",
+	Short: "Show a schedule's recent and upcoming runs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).ListScheduleRuns(cmd.Context(), &jobmanagerpb.ListScheduleRunsRequest{
+			Name:     args[0],
+			Upcoming: upcomingRuns,
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error listing schedule runs: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SCHEDULED FOR\tSTART AT\tJOB\tNOTE")
+		// Oldest first, so upcoming runs follow on
+		for _, run := range slices.Backward(resp.Past) {
+			job, note := run.JobId, run.Skipped
+			if job == "" {
+				job = "-"
+			}
+			if note == "" {
+				note = "started " + formatRunTime(run.StartedAt.AsTime())
+			} else {
+				note = "skipped: " + note
+			}
+			if run.Missed > 0 {
+				note += fmt.Sprintf(" (and %d missed before it)", run.Missed)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", formatRunTime(run.ScheduledFor.AsTime()), formatRunTime(run.StartAt.AsTime()), job, note)
+		}
+		for _, run := range resp.Upcoming {
+			fmt.Fprintf(w, "%s\t%s\t-\tupcoming\n", formatRunTime(run.ScheduledFor.AsTime()), formatRunTime(run.StartAt.AsTime()))
+		}
+		return w.Flush()
+	},
+}
+
+func formatRunTime(t time.Time) string {
+	return t.Local().Format(time.RFC3339)
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	segmentsStderr  bool
+	segmentsAttempt uint32
+	segmentsSince   string
+	segmentsUntil   string
+)
+
+func init() {
+	segmentsCmd.Flags().BoolVarP(&segmentsStderr, "stderr", "", false, "use stderr output")
+	segmentsCmd.Flags().Uint32VarP(&segmentsAttempt, "attempt", "", 0, "attempt to use (defaults to the latest)")
+	segmentsCmd.Flags().StringVarP(&segmentsSince, "since", "", "", "only list segments with output from this RFC 3339 time on")
+	segmentsCmd.Flags().StringVarP(&segmentsUntil, "until", "", "", "only list segments with output from before this RFC 3339 time")
+
+	rootCmd.AddCommand(segmentsCmd)
+}
+
+// Lists a job's output segments, or prints one of them
+var segmentsCmd = &cobra.Command{
+	Use:  "segments job-id [segment]",
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		id, err := jobid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+		outputType := jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT
+		if segmentsStderr {
+			outputType = jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR
+		}
+		client := jobmanagerpb.NewJobManagerClient(conn)
+
+		if len(args) == 2 {
+			number, err := strconv.ParseUint(args[1], 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid segment number '%s'", args[1])
+			}
+			stream, err := client.GetOutputSegment(cmd.Context(), &jobmanagerpb.GetOutputSegmentRequest{
+				JobId:   id[:],
+				Type:    outputType,
+				Attempt: segmentsAttempt,
+				Segment: uint32(number),
+			})
+			if err != nil {
+				return fmt.Errorf("server returned error getting output segment: %w", err)
+			}
+			verifier := newOutputVerifier()
+			for {
+				msg, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					return nil
+				} else if err != nil {
+					return fmt.Errorf("error receiving output segment: %w", err)
+				}
+				if msg.End != nil {
+					if err := verifier.check(msg.End); err != nil {
+						return fmt.Errorf("output segment is incomplete or corrupted: %w", err)
+					}
+					continue
+				}
+				verifier.add(msg.Data)
+				if _, err := os.Stdout.Write(msg.Data); err != nil {
+					return err
+				}
+			}
+		}
+
+		req := &jobmanagerpb.ListOutputSegmentsRequest{
+			JobId:   id[:],
+			Type:    outputType,
+			Attempt: segmentsAttempt,
+		}
+		if req.Since, err = parseTimeFlag("since", segmentsSince); err != nil {
+			return err
+		}
+		if req.Until, err = parseTimeFlag("until", segmentsUntil); err != nil {
+			return err
+		}
+		resp, err := client.ListOutputSegments(cmd.Context(), req)
+		if err != nil {
+			return fmt.Errorf("server returned error listing output segments: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SEGMENT\tSTART\tEND\tBYTES")
+		for _, segment := range resp.Segments {
+			end := "-"
+			if segment.EndTime != nil {
+				end = segment.EndTime.AsTime().Local().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\n",
+				segment.Number,
+				segment.StartTime.AsTime().Local().Format(time.RFC3339),
+				end,
+				segment.SizeBytes,
+			)
+		}
+		return w.Flush()
+	},
+}
+
+// Nil if the flag wasn't set
+func parseTimeFlag(name string, value string) (*timestamppb.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", name, err)
+	}
+	return timestamppb.New(t), nil
+}
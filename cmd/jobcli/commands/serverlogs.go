@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+var serverLogsLevel string
+
+func init() {
+	serverLogsCmd.Flags().StringVarP(&serverLogsLevel, "level", "", "info", "least severe entries to show: debug, info, warn or error")
+
+	rootCmd.AddCommand(serverLogsCmd)
+}
+
+// Follows the server's log until interrupted. Admins only
+var serverLogsCmd = &cobra.Command{
+	Use:  "server-logs",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		level, ok := jobmanagerpb.LogLevel_value["LOG_LEVEL_"+strings.ToUpper(serverLogsLevel)]
+		if !ok || level == int32(jobmanagerpb.LogLevel_LOG_LEVEL_UNSPECIFIED) {
+			return fmt.Errorf("invalid --level '%s'", serverLogsLevel)
+		}
+
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		stream, err := jobmanagerpb.NewJobManagerClient(conn).StreamServerLogs(cmd.Context(), &jobmanagerpb.StreamServerLogsRequest{
+			Level: jobmanagerpb.LogLevel(level),
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error streaming logs: %w", err)
+		}
+		for {
+			entry, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("error receiving server logs: %w", err)
+			}
+			fmt.Println(formatLogEntry(entry))
+		}
+	},
+}
+
+// Ex: "2025-06-01T12:00:00Z INFO Handling 'StopJob' request user=ryan"
+func formatLogEntry(entry *jobmanagerpb.ServerLogEntry) string {
+	var b strings.Builder
+	if entry.Dropped > 0 {
+		fmt.Fprintf(&b, "(%d entries dropped)\n", entry.Dropped)
+	}
+	fmt.Fprintf(&b, "%s %s %s",
+		entry.Time.AsTime().Local().Format(time.RFC3339),
+		strings.TrimPrefix(entry.Level.String(), "LOG_LEVEL_"),
+		entry.Message,
+	)
+	for _, key := range slices.Sorted(maps.Keys(entry.Attrs)) {
+		fmt.Fprintf(&b, " %s=%q", key, entry.Attrs[key])
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+// Environment variable 'start' takes its session from, so a CI runner
+// can set it once for every job it starts
+const sessionEnv = "JOBBY_SESSION"
+
+var endSessionStop bool
+
+func init() {
+	endSessionCmd.Flags().BoolVarP(&endSessionStop, "stop", "", false, "stop the session's jobs that are still running")
+
+	rootCmd.AddCommand(endSessionCmd)
+}
+
+// No more jobs may be started in an ended session
+var endSessionCmd = &cobra.Command{
+	Use:  "end-session session-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).EndSession(cmd.Context(), &jobmanagerpb.EndSessionRequest{
+			SessionId: args[0],
+			StopJobs:  endSessionStop,
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error ending session: %w", err)
+		}
+		for _, id := range resp.StoppedJobIds {
+			fmt.Printf("Stopped job %s\n", id)
+		}
+		fmt.Printf("Ended session %s\n", args[0])
+		return nil
+	},
+}
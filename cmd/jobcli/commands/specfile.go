@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// Load a JobSpec from a YAML or JSON file ('-' for stdin). Fields are named
+// as in the proto's JSON mapping (ex: max_attempts or maxAttempts, and
+// durations like "90s"). Unknown fields and values of the wrong type are
+// errors, so typos don't quietly start a different job
+func loadJobSpec(path string) (*jobmanagerpb.JobSpec, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading job spec: %w", err)
+	}
+	spec := &jobmanagerpb.JobSpec{}
+	if err := unmarshalSpec(data, spec); err != nil {
+		return nil, fmt.Errorf("invalid job spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Decode YAML (or JSON, which YAML parsers accept too) into 'msg'
+// following the proto's JSON mapping
+func unmarshalSpec(data []byte, msg proto.Message) error {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc == nil {
+		return errors.New("file is empty")
+	}
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		// YAML allows keys JSON doesn't (ex: a list as a key)
+		return err
+	}
+	return protojson.Unmarshal(encoded, msg)
+}
+
+// Replace the fields of 'dst' with those set in 'src'. Unlike proto.Merge,
+// lists and maps are replaced rather than added to
+func overrideFields(dst proto.Message, src proto.Message) {
+	target := dst.ProtoReflect()
+	src.ProtoReflect().Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		target.Set(field, value)
+		return true
+	})
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestUnmarshalSpec(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    string
+		want    *jobmanagerpb.JobSpec
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			data: "command: /bin/echo\nargs: [hello, world]\nmax_attempts: 3\ntimeout: 90s\nenv:\n  FOO: bar\n",
+			want: &jobmanagerpb.JobSpec{
+				Command:     "/bin/echo",
+				Args:        []string{"hello", "world"},
+				MaxAttempts: 3,
+				Timeout:     durationpb.New(90 * time.Second),
+				Env:         map[string]string{"FOO": "bar"},
+			},
+		},
+		{
+			name: "json",
+			data: `{"command": "/bin/echo", "maxAttempts": 2, "labels": {"team": "infra"}}`,
+			want: &jobmanagerpb.JobSpec{Command: "/bin/echo", MaxAttempts: 2, Labels: map[string]string{"team": "infra"}},
+		},
+		{
+			name: "template",
+			data: "template:\n  name: echo\n  params:\n    text: hi\n",
+			want: &jobmanagerpb.JobSpec{Template: &jobmanagerpb.TemplateRef{Name: "echo", Params: map[string]string{"text": "hi"}}},
+		},
+		{name: "empty", data: "", wantErr: "file is empty"},
+		{name: "only a comment", data: "# nothing here\n", wantErr: "file is empty"},
+		{name: "unknown field", data: "command: /bin/echo\nmax_attempt: 3\n", wantErr: "max_attempt"},
+		{name: "wrong type", data: "command: /bin/echo\nmax_attempts: lots\n", wantErr: "field maxAttempts"},
+		{name: "bad duration", data: "timeout: soon\n", wantErr: "Duration"},
+		{name: "not yaml", data: "command: [unclosed\n", wantErr: "yaml"},
+		{name: "list key", data: "? [a, b]\n: c\n", wantErr: "invalid map key"},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			spec := &jobmanagerpb.JobSpec{}
+			err := unmarshalSpec([]byte(tc.data), spec)
+			if tc.wantErr != "" {
+				assert.ErrorContains(tt, err, tc.wantErr)
+				return
+			}
+			require.NoError(tt, err)
+			assert.True(tt, proto.Equal(tc.want, spec), "got %v", spec)
+		})
+	}
+}
+
+func TestLoadJobSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("command: /bin/true\n"), 0666))
+	spec, err := loadJobSpec(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/bin/true", spec.Command)
+
+	require.NoError(t, os.WriteFile(path, []byte("comand: /bin/true\n"), 0666))
+	_, err = loadJobSpec(path)
+	assert.ErrorContains(t, err, "invalid job spec "+path)
+
+	_, err = loadJobSpec(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "error reading job spec")
+}
+
+func TestOverrideFields(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		dst  *jobmanagerpb.JobSpec
+		src  *jobmanagerpb.JobSpec
+		want *jobmanagerpb.JobSpec
+	}{
+		{
+			name: "unset fields kept",
+			dst:  &jobmanagerpb.JobSpec{Command: "/bin/echo", MaxAttempts: 3},
+			src:  &jobmanagerpb.JobSpec{RuntimeClass: "small"},
+			want: &jobmanagerpb.JobSpec{Command: "/bin/echo", MaxAttempts: 3, RuntimeClass: "small"},
+		},
+		{
+			name: "scalars replaced",
+			dst:  &jobmanagerpb.JobSpec{Command: "/bin/echo", MaxAttempts: 3},
+			src:  &jobmanagerpb.JobSpec{MaxAttempts: 5},
+			want: &jobmanagerpb.JobSpec{Command: "/bin/echo", MaxAttempts: 5},
+		},
+		{
+			name: "lists replaced, not appended",
+			dst:  &jobmanagerpb.JobSpec{Args: []string{"a", "b"}},
+			src:  &jobmanagerpb.JobSpec{Args: []string{"c"}},
+			want: &jobmanagerpb.JobSpec{Args: []string{"c"}},
+		},
+		{
+			name: "maps replaced, not merged",
+			dst:  &jobmanagerpb.JobSpec{Env: map[string]string{"A": "1", "B": "2"}},
+			src:  &jobmanagerpb.JobSpec{Env: map[string]string{"B": "3"}},
+			want: &jobmanagerpb.JobSpec{Env: map[string]string{"B": "3"}},
+		},
+		{
+			name: "messages replaced",
+			dst:  &jobmanagerpb.JobSpec{Timeout: durationpb.New(time.Minute)},
+			src:  &jobmanagerpb.JobSpec{Timeout: durationpb.New(time.Second)},
+			want: &jobmanagerpb.JobSpec{Timeout: durationpb.New(time.Second)},
+		},
+		{
+			name: "nothing set",
+			dst:  &jobmanagerpb.JobSpec{Command: "/bin/echo"},
+			src:  &jobmanagerpb.JobSpec{},
+			want: &jobmanagerpb.JobSpec{Command: "/bin/echo"},
+		},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			overrideFields(tc.dst, tc.src)
+			assert.True(tt, proto.Equal(tc.want, tc.dst), "got %v", tc.dst)
+		})
+	}
+}
@@ -2,20 +2,136 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
 	"github.com/gopheryan/jobby/jobmanagerpb"
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var (
+	maxAttempts  uint32
+	retention    time.Duration
+	keepForever  bool
+	runtimeClass string
+	jobEnv       map[string]string
+	jobLabels    map[string]string
+	jobTimeout   time.Duration
+	egressPolicy string
+	jobGPUs      []uint
+	jobNice      int32
+	ioClass      string
+	ioPriority   uint32
+	jobCPUs      []uint
+	jobPriority  int32
+	requeue      bool
+	force        bool
+	outputWindow uint64
+	segmentBytes uint64
+	segmentEvery time.Duration
+	progress     bool
+	public       bool
+	session      string
+	outputType   string
+	exitOutcomes map[string]string
+	shellLine    string
+	expectedRun  time.Duration
+	captureEnv   bool
+	timestamps   bool
+	cacheTTL     time.Duration
+	stdinFile    string
+	jobLease     time.Duration
+	childJobs    bool
+	tokenScopes  []string
+	scratch      bool
+	scratchTmpfs uint64
+	specFile     string
+	templateName string
+	templateArgs map[string]string
+	assumeYes    bool
+	jobMutex     string
+	waitMutex    bool
 )
 
 func init() {
+	startCmd.Flags().Uint32VarP(&maxAttempts, "max-attempts", "", 1, "number of times to run the command if it keeps failing")
+	startCmd.Flags().DurationVarP(&retention, "retention", "", 0, "how long the server keeps the job after it finishes (server default if unset)")
+	startCmd.Flags().BoolVarP(&keepForever, "keep-forever", "", false, "ask the server to never delete the job")
+	startCmd.Flags().StringVarP(&runtimeClass, "class", "", "", "runtime class (preset of limits) to run the job with (server default if unset)")
+	startCmd.Flags().StringToStringVarP(&jobEnv, "env", "e", nil, "KEY=VALUE environment variables to set for the job")
+	startCmd.Flags().StringToStringVarP(&jobLabels, "label", "l", nil, "KEY=VALUE labels to attach to the job")
+	startCmd.Flags().DurationVarP(&jobTimeout, "timeout", "", 0, "kill each attempt after it runs this long (may only shorten the class's timeout)")
+	startCmd.Flags().DurationVarP(&expectedRun, "expected-runtime", "", 0, "how long the job usually runs, so server shutdowns can wait for it (past runs' average if unset)")
+	startCmd.Flags().BoolVarP(&captureEnv, "capture-env", "", false, "record the job's environment, working directory, binary checksum and host when it launches")
+	startCmd.Flags().StringVarP(&stdinFile, "stdin-file", "", "", "send this file to the job's stdin as it reads it ('-' for ours, to pipe data in)")
+	startCmd.Flags().DurationVarP(&cacheTTL, "cache-ttl", "", 0, "reuse an identical job of yours that succeeded within this long instead of running the command again")
+	startCmd.Flags().DurationVarP(&jobLease, "lease", "", 0, "stop the job unless its lease is renewed (see 'renew-lease') at least this often")
+	startCmd.Flags().BoolVarP(&childJobs, "child-jobs", "", false, "let the job start child jobs (see 'list --tree') by running jobcli itself")
+	startCmd.Flags().StringSliceVarP(&tokenScopes, "token-scope", "", nil, "give the job a token it may use for this: 'progress' (see 'report-progress'), 'annotate' (see 'annotate') or 'child-jobs'")
+	startCmd.Flags().BoolVarP(&scratch, "scratch", "", false, "give the job a directory of its own to write to, in $JOBBY_SCRATCH, removed along with its output")
+	startCmd.Flags().Uint64VarP(&scratchTmpfs, "scratch-tmpfs", "", 0, "keep the scratch directory in memory, on a tmpfs of this many bytes (implies --scratch)")
+	startCmd.Flags().BoolVarP(&timestamps, "timestamps", "", false, "have the server prefix each line of output with when it was written")
+	startCmd.Flags().StringVarP(&egressPolicy, "egress", "", "", "network egress policy for the job (runtime class's policy if unset)")
+	startCmd.Flags().UintSliceVarP(&jobGPUs, "gpu", "", nil, "index of a GPU (see 'info') the job may use. Repeat for more GPUs")
+	startCmd.Flags().Int32VarP(&jobNice, "nice", "", 0, "nice value for the job, 0 (normal) to 19 (lowest priority)")
+	startCmd.Flags().StringVarP(&ioClass, "io-class", "", "", "I/O scheduling class: 'best-effort' or 'idle' (server's if unset)")
+	startCmd.Flags().Uint32VarP(&ioPriority, "io-priority", "", 4, "priority within the best-effort I/O class, 0 (highest) to 7")
+	startCmd.Flags().UintSliceVarP(&jobCPUs, "cpus", "", nil, "CPUs the job may run on (any if unset)")
+	startCmd.Flags().Int32VarP(&jobPriority, "priority", "p", 0, "jobs may preempt running jobs of lower priority when the server is at capacity")
+	startCmd.Flags().BoolVarP(&requeue, "requeue", "", false, "run the job again once there's room if it's preempted")
+	startCmd.Flags().StringVarP(&jobMutex, "mutex", "", "", "don't run the job alongside any other holding this mutex (ex: 'db-migration'), whoever owns it")
+	startCmd.Flags().BoolVarP(&waitMutex, "wait-for-mutex", "", false, "wait for the --mutex to be released instead of failing while another job holds it")
+	startCmd.Flags().BoolVarP(&force, "force", "", false, "start the job even if an identical one of yours is still running")
+	startCmd.Flags().Uint64VarP(&outputWindow, "output-window", "", 0, "keep only about the last this many bytes of each output stream (all of it if unset)")
+	startCmd.Flags().Uint64VarP(&segmentBytes, "segment-bytes", "", 0, "split output into segments (see 'segments') of about this many bytes")
+	startCmd.Flags().DurationVarP(&segmentEvery, "segment-interval", "", 0, "split output into segments (see 'segments') at every multiple of this on the clock")
+	startCmd.Flags().BoolVarP(&progress, "progress", "", false, "track the progress the job reports with 'JOBBY_PROGRESS: 42%' lines (see 'progress')")
+	startCmd.Flags().BoolVarP(&public, "public", "", false, "let anyone read the job's status and output, if the server allows it")
+	startCmd.Flags().StringVarP(&session, "session", "", os.Getenv(sessionEnv), "start the job in this session (see 'end-session'). Defaults to $"+sessionEnv)
+	startCmd.Flags().StringVarP(&outputType, "output-type", "", "", "content type of the job's stdout, which 'attach' renders: text/plain, application/json or application/junit+xml")
+	startCmd.Flags().StringToStringVarP(&exitOutcomes, "exit-outcome", "", nil, "CODE=OUTCOME classification of exit codes: success, warning, failure (never retried), retryable or infrastructure-failure")
+	startCmd.Flags().StringVarP(&shellLine, "shell", "", "", "command line to run with /bin/sh -c (ex: 'make | tee log'), instead of a command and args")
+	startCmd.Flags().StringVarP(&specFile, "file", "f", "", "YAML or JSON file with the job's spec ('-' for stdin). Flags given too replace its fields, and a command replaces its command")
+	startCmd.Flags().StringVarP(&templateName, "template", "t", "", "start one of the server's templates (see 'template preview') instead of a command")
+	startCmd.Flags().StringToStringVarP(&templateArgs, "param", "", nil, "NAME=VALUE parameter for --template")
+	startCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "start a --template job without asking to confirm its command")
+	startCmd.MarkFlagsMutuallyExclusive("retention", "keep-forever")
+	startCmd.MarkFlagsMutuallyExclusive("template", "shell")
+
 	rootCmd.AddCommand(startCmd)
 }
 
 var startCmd = &cobra.Command{
-	Use:  "start command [arg] ...",
-	Args: cobra.MinimumNArgs(1),
+	Use: "start command [arg] ... | start --shell command-line | start -f job.yaml | start --template name --param k=v",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if templateName != "" {
+			if len(args) != 0 {
+				return errors.New("--template jobs run the template's command. Pass --param instead of args")
+			}
+			return nil
+		}
+		if cmd.Flags().Changed("shell") {
+			if len(args) != 0 {
+				return errors.New("--shell takes the whole command line. Quote it instead of passing args")
+			}
+			return nil
+		}
+		if specFile != "" {
+			// The command may come from the file
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		host, _ := cmd.Flags().GetString("host")
 		conn, err := newClientConnection(host)
@@ -24,27 +140,216 @@ var startCmd = &cobra.Command{
 		}
 		defer conn.Close()
 
-		jobId, err := startJob(cmd.Context(), args[0], args[1:], jobmanagerpb.NewJobManagerClient(conn))
+		spec := &jobmanagerpb.JobSpec{
+			Env:                 jobEnv,
+			MaxAttempts:         maxAttempts,
+			Retention:           retentionPolicy(retention, keepForever),
+			RuntimeClass:        runtimeClass,
+			Labels:              jobLabels,
+			EgressPolicy:        egressPolicy,
+			Priority:            jobPriority,
+			RequeueOnPreemption: requeue,
+			OutputWindowBytes:   outputWindow,
+			TrackProgress:       progress,
+			Public:              public,
+			OutputContentType:   outputType,
+			CaptureEnvironment:  captureEnv,
+			TimestampOutput:     timestamps,
+			Stdin:               stdinFile != "",
+			ChildJobs:           childJobs,
+			Mutex:               jobMutex,
+			WaitForMutex:        waitMutex,
+		}
+		if cmd.Flags().Changed("shell") {
+			spec.Shell = shellLine
+		} else if len(args) > 0 {
+			spec.Command, spec.Args = args[0], args[1:]
+		} else if templateName != "" {
+			spec.Template = &jobmanagerpb.TemplateRef{Name: templateName, Params: templateArgs}
+		}
+		for _, gpu := range jobGPUs {
+			spec.Gpus = append(spec.Gpus, uint32(gpu))
+		}
+		if spec.Scheduling, err = scheduling(cmd); err != nil {
+			return err
+		}
+		if spec.ExitCodeRules, err = exitCodeRules(exitOutcomes); err != nil {
+			return err
+		}
+		for _, name := range tokenScopes {
+			scope, ok := tokenScopeNames[name]
+			if !ok {
+				return fmt.Errorf("invalid --token-scope '%s'", name)
+			}
+			spec.TokenScopes = append(spec.TokenScopes, scope)
+		}
+		if scratch || scratchTmpfs != 0 {
+			spec.Scratch = &jobmanagerpb.Scratch{TmpfsBytes: scratchTmpfs}
+		}
+		if segmentBytes != 0 || segmentEvery != 0 {
+			spec.OutputSegments = &jobmanagerpb.SegmentPolicy{MaxBytes: segmentBytes}
+			if segmentEvery != 0 {
+				spec.OutputSegments.Interval = durationpb.New(segmentEvery)
+			}
+		}
+		if jobTimeout != 0 {
+			spec.Timeout = durationpb.New(jobTimeout)
+		}
+		if expectedRun != 0 {
+			spec.ExpectedRuntime = durationpb.New(expectedRun)
+		}
+		if jobLease != 0 {
+			spec.Lease = durationpb.New(jobLease)
+		}
+		if specFile != "" {
+			if spec, err = specFromFile(cmd, spec); err != nil {
+				return err
+			}
+		}
+		req := &jobmanagerpb.StartJobRequest{Spec: spec, Force: force, SessionId: session}
+		if cacheTTL != 0 {
+			req.CacheTtl = durationpb.New(cacheTTL)
+		}
+		// Opened first, so a missing file doesn't leave a job waiting on it
+		var stdin io.ReadCloser
+		if stdinFile != "" {
+			if stdin, err = openStdinFile(stdinFile); err != nil {
+				return err
+			}
+			defer stdin.Close()
+		}
+		client := jobmanagerpb.NewJobManagerClient(conn)
+		if spec.Template != nil && !assumeYes {
+			if err := confirmTemplate(cmd.Context(), client, spec.Template); err != nil {
+				return err
+			}
+		}
+		jobId, err := startJob(cmd.Context(), req, client)
 		if err != nil {
 			return err
 		}
 		fmt.Printf("Started Job: %s\n", jobId.String())
+		if stdin == nil {
+			return nil
+		}
+		written, err := uploadStdin(cmd.Context(), client, jobId, stdin)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Sent %d bytes to the job's stdin\n", written)
 		return nil
 	},
 }
 
-func startJob(ctx context.Context, command string, args []string, client jobmanagerpb.JobManagerClient) (uuid.UUID, error) {
-	resp, err := client.StartJob(ctx, &jobmanagerpb.StartJobRequest{
-		Command: command,
-		Args:    args,
-	})
+// The spec in --file, with the fields of 'flags' (the spec the flags make)
+// in place of its own
+func specFromFile(cmd *cobra.Command, flags *jobmanagerpb.JobSpec) (*jobmanagerpb.JobSpec, error) {
+	if specFile == "-" && stdinFile == "-" {
+		return nil, errors.New("only one of --file and --stdin-file can be read from stdin")
+	}
+	spec, err := loadJobSpec(specFile)
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.Flags().Changed("max-attempts") {
+		// Only the flag's default
+		flags.MaxAttempts = 0
+	}
+	if flags.Command != "" || flags.Shell != "" || flags.Template != nil {
+		// A command on the command line replaces the file's, however it was given
+		spec.Command, spec.Args, spec.Shell, spec.Template = "", nil, "", nil
+	}
+	overrideFields(spec, flags)
+	if spec.Command == "" && spec.Shell == "" && spec.Template == nil {
+		return nil, fmt.Errorf("%s has no command, shell or template, and none was given", specFile)
+	}
+	return spec, nil
+}
+
+// What --token-scope takes
+var tokenScopeNames = map[string]jobmanagerpb.JobTokenScope{
+	"progress":   jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_REPORT_PROGRESS,
+	"annotate":   jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_ANNOTATE,
+	"child-jobs": jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_START_CHILD_JOBS,
+}
+
+// Nil when no scheduling flags were set
+func scheduling(cmd *cobra.Command) (*jobmanagerpb.Scheduling, error) {
+	sched := &jobmanagerpb.Scheduling{}
+	if cmd.Flags().Changed("nice") {
+		sched.Nice = &jobNice
+	}
+	switch ioClass {
+	case "":
+	case "best-effort":
+		sched.IoClass = jobmanagerpb.IOClass_IO_CLASS_BEST_EFFORT
+		sched.IoPriority = ioPriority
+	case "idle":
+		sched.IoClass = jobmanagerpb.IOClass_IO_CLASS_IDLE
+	default:
+		return nil, fmt.Errorf("invalid --io-class '%s'", ioClass)
+	}
+	for _, cpu := range jobCPUs {
+		sched.Cpus = append(sched.Cpus, uint32(cpu))
+	}
+	if sched.Nice == nil && sched.IoClass == jobmanagerpb.IOClass_IO_CLASS_UNSPECIFIED && len(sched.Cpus) == 0 {
+		return nil, nil
+	}
+	return sched, nil
+}
+
+// One rule per outcome, with codes in order so jobs are reproducible
+func exitCodeRules(outcomes map[string]string) ([]*jobmanagerpb.ExitCodeRule, error) {
+	byOutcome := map[jobmanagerpb.Outcome][]int32{}
+	for code, name := range outcomes {
+		parsed, err := strconv.ParseInt(code, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exit-outcome code '%s'", code)
+		}
+		value, ok := jobmanagerpb.Outcome_value["OUTCOME_"+strings.ToUpper(strings.ReplaceAll(name, "-", "_"))]
+		if !ok || value == 0 {
+			return nil, fmt.Errorf("invalid --exit-outcome outcome '%s'", name)
+		}
+		byOutcome[jobmanagerpb.Outcome(value)] = append(byOutcome[jobmanagerpb.Outcome(value)], int32(parsed))
+	}
+	var rules []*jobmanagerpb.ExitCodeRule
+	for _, outcome := range slices.Sorted(maps.Keys(byOutcome)) {
+		codes := byOutcome[outcome]
+		slices.Sort(codes)
+		rules = append(rules, &jobmanagerpb.ExitCodeRule{Codes: codes, Outcome: outcome})
+	}
+	return rules, nil
+}
+
+// Nil leaves the choice to the server
+func retentionPolicy(ttl time.Duration, keepForever bool) *jobmanagerpb.RetentionPolicy {
+	switch {
+	case keepForever:
+		return &jobmanagerpb.RetentionPolicy{Policy: &jobmanagerpb.RetentionPolicy_KeepForever{KeepForever: true}}
+	case ttl != 0:
+		return &jobmanagerpb.RetentionPolicy{Policy: &jobmanagerpb.RetentionPolicy_Ttl{Ttl: durationpb.New(ttl)}}
+	default:
+		return nil
+	}
+}
+
+func startJob(ctx context.Context, req *jobmanagerpb.StartJobRequest, client jobmanagerpb.JobManagerClient) (uuid.UUID, error) {
+	resp, err := client.StartJob(ctx, req)
 
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("server returned error starting job: %w", err)
 	}
+	for _, warning := range resp.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if resp.Cached {
+		// The id is printed like any other, so scripts needn't care
+		fmt.Fprintln(os.Stderr, "Reusing the results of an identical job that succeeded recently")
+	}
 
 	var id uuid.UUID
-	if id, err = uuid.FromBytes(resp.JobId); err != nil {
+	// Older servers only send the raw id
+	if id, err = jobid.Resolve(resp.JobId, resp.Id); err != nil {
 		return uuid.UUID{}, fmt.Errorf("server returned invalid job id: %w", err)
 	} else {
 		return id, nil
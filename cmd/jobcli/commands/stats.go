@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+)
+
+var statsCommand string
+
+func init() {
+	statsCmd.Flags().StringVarP(&statsCommand, "command", "c", "", "every run of this command (or shell command line) instead of a job's")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+// Runs of jobs identical to the given one, or of a command
+var statsCmd = &cobra.Command{
+	Use:  "stats [job-id]",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := &jobmanagerpb.GetJobStatsRequest{Command: statsCommand}
+		switch {
+		case len(args) == 1 && statsCommand != "":
+			return errors.New("give a job id or --command, not both")
+		case len(args) == 1:
+			id, err := jobid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to parse job id: %w", err)
+			}
+			req.JobId = id[:]
+		case statsCommand == "":
+			return errors.New("give a job id or --command")
+		}
+
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).GetJobStats(cmd.Context(), req)
+		if err != nil {
+			return fmt.Errorf("server returned error getting job stats: %w", err)
+		}
+
+		fmt.Printf("Runs: %d\n", resp.Runs)
+		if resp.Runs == 0 {
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tMIN\tMEDIAN\tP90\tMAX\tMEAN")
+		d := resp.Duration
+		fmt.Fprintf(w, "Duration\t%s\t%s\t%s\t%s\t%s\n",
+			d.Min.AsDuration(), d.Median.AsDuration(), d.P90.AsDuration(), d.Max.AsDuration(), d.Mean.AsDuration())
+		o := resp.OutputBytes
+		fmt.Fprintf(w, "Output Bytes\t%d\t%d\t%d\t%d\t%d\n", o.Min, o.Median, o.P90, o.Max, o.Mean)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Println("Exits:")
+		for _, code := range slices.Sorted(maps.Keys(resp.ExitCodes)) {
+			fmt.Printf("  code %d: %d\n", code, resp.ExitCodes[code])
+		}
+		if resp.Signaled > 0 {
+			fmt.Printf("  signaled: %d\n", resp.Signaled)
+		}
+		return nil
+	},
+}
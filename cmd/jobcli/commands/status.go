@@ -3,8 +3,14 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
 	"github.com/gopheryan/jobby/jobmanagerpb"
 	"github.com/spf13/cobra"
 )
@@ -25,30 +31,122 @@ var statusCmd = &cobra.Command{
 		defer conn.Close()
 
 		var id uuid.UUID
-		if id, err = uuid.Parse(args[0]); err != nil {
+		if id, err = jobid.Parse(args[0]); err != nil {
 			return fmt.Errorf("failed to parse job id: %w", err)
 		}
 
-		status, exitCode, err := getJobstatus(cmd.Context(), id, jobmanagerpb.NewJobManagerClient(conn))
+		resp, err := getJobstatus(cmd.Context(), id, jobmanagerpb.NewJobManagerClient(conn))
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Status: %s\n", status.String())
-		if exitCode != nil {
-			fmt.Printf("Exit Code: %d\n", *exitCode)
+		fmt.Printf("Status: %s\n", resp.CurrentStatus.String())
+		if resp.ExitCode != nil {
+			fmt.Printf("Exit Code: %d\n", *resp.ExitCode)
 		}
-		return nil
+		if reason := formatExitReason(resp.ExitReason, resp.Signal); reason != "" {
+			fmt.Printf("Exit Reason: %s\n", reason)
+		}
+		if reason := formatStateReason(resp.StateReason, resp.StateMessage); reason != "" {
+			fmt.Printf("Reason: %s\n", reason)
+		}
+		if resp.Outcome != jobmanagerpb.Outcome_OUTCOME_UNSPECIFIED {
+			fmt.Printf("Outcome: %s\n", formatOutcome(resp.Outcome))
+		}
+		if resp.TimedOut {
+			fmt.Println("Timed out")
+		}
+		if resp.QuotaExceeded {
+			fmt.Println("Output quota exceeded")
+		}
+		if resp.Queued {
+			fmt.Println("Queued to run again once there's room")
+		}
+		if resp.Progress != nil {
+			fmt.Printf("Progress: %s\n", formatProgress(resp.Progress))
+		}
+		if resp.Duration != nil {
+			fmt.Printf("Duration: %s\n", resp.Duration.AsDuration())
+		}
+		if resp.CurrentStatus == jobmanagerpb.Status_STATUS_RUNNING && resp.Duration != nil {
+			// Only a guess, so failing to make one isn't worth an error
+			stats, err := jobmanagerpb.NewJobManagerClient(conn).GetJobStats(cmd.Context(), &jobmanagerpb.GetJobStatsRequest{JobId: id[:]})
+			if err == nil && stats.Runs > 0 {
+				fmt.Printf("Estimated Remaining: %s\n", formatRemaining(stats.Duration.Median.AsDuration()-resp.Duration.AsDuration()))
+			}
+		}
+		if resp.OutputContentType != "" {
+			fmt.Printf("Output Type: %s\n", resp.OutputContentType)
+		}
+		if resp.Pid != 0 {
+			fmt.Printf("PID: %d\n", resp.Pid)
+		}
+		if len(resp.Processes) == 0 {
+			return nil
+		}
+		fmt.Println("Processes:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  PID\tPPID\tCOMMAND")
+		for _, process := range resp.Processes {
+			fmt.Fprintf(w, "  %d\t%d\t%s\n", process.Pid, process.Ppid, process.Command)
+		}
+		return w.Flush()
 	},
 }
 
-func getJobstatus(ctx context.Context, jobId uuid.UUID, client jobmanagerpb.JobManagerClient) (jobmanagerpb.Status, *int32, error) {
+func getJobstatus(ctx context.Context, jobId uuid.UUID, client jobmanagerpb.JobManagerClient) (*jobmanagerpb.GetStatusResponse, error) {
 	resp, err := client.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{
 		JobId: jobId[:],
 	})
 
 	if err != nil {
-		return 0, nil, fmt.Errorf("server returned error getting job status: %w", err)
+		return nil, fmt.Errorf("server returned error getting job status: %w", err)
+	}
+	return resp, nil
+}
+
+// Ex: "OOM_KILLED (SIGKILL)". Empty while the job is running
+func formatExitReason(reason jobmanagerpb.ExitReason, signal string) string {
+	if reason == jobmanagerpb.ExitReason_EXIT_REASON_UNSPECIFIED {
+		return ""
+	}
+	out := strings.TrimPrefix(reason.String(), "EXIT_REASON_")
+	if signal != "" {
+		out += " (" + signal + ")"
+	}
+	return out
+}
+
+// Ex: "TIMEOUT (killed after running for 1m0s)". Empty until the job finishes
+func formatStateReason(reason jobmanagerpb.StateReason, message string) string {
+	if reason == jobmanagerpb.StateReason_STATE_REASON_UNSPECIFIED {
+		return ""
+	}
+	out := strings.TrimPrefix(reason.String(), "STATE_REASON_")
+	if message != "" {
+		out += " (" + message + ")"
+	}
+	return out
+}
+
+// Ex: "INFRASTRUCTURE_FAILURE"
+func formatOutcome(outcome jobmanagerpb.Outcome) string {
+	return strings.TrimPrefix(outcome.String(), "OUTCOME_")
+}
+
+// Ex: "1m30s", from the median of earlier runs (see GetJobStats)
+func formatRemaining(remaining time.Duration) string {
+	if remaining <= 0 {
+		return "overdue, runs usually finish by now"
+	}
+	return remaining.Round(time.Second).String()
+}
+
+// Ex: "42% copying files"
+func formatProgress(progress *jobmanagerpb.Progress) string {
+	out := strconv.FormatFloat(progress.Percent, 'f', -1, 64) + "%"
+	if progress.Message != "" {
+		out += " " + progress.Message
 	}
-	return resp.CurrentStatus, resp.ExitCode, nil
+	return out
 }
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+)
+
+// Size of the chunks stdin is uploaded in. Sends block while the server
+// (and so the job) hasn't caught up, so only a few are in flight
+const stdinChunkBytes = 64 * 1024
+
+// Open what --stdin-file names. "-" is our own stdin, for pipes
+func openStdinFile(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening stdin file: %w", err)
+	}
+	return f, nil
+}
+
+// Stream 'data' to the job's stdin, closing it once 'data' runs out.
+// Returns how much of it the job was handed
+func uploadStdin(ctx context.Context, client jobmanagerpb.JobManagerClient, id uuid.UUID, data io.Reader) (uint64, error) {
+	stream, err := client.WriteJobStdin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("server returned error opening job stdin: %w", err)
+	}
+	if err := stream.Send(&jobmanagerpb.WriteJobStdinRequest{JobId: id[:]}); err != nil {
+		return 0, closeStdinStream(stream, err)
+	}
+	for {
+		// Not reused, as gRPC doesn't allow changing a message once it's sent
+		buf := make([]byte, stdinChunkBytes)
+		n, readErr := data.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&jobmanagerpb.WriteJobStdinRequest{Data: buf[:n]}); err != nil {
+				return 0, closeStdinStream(stream, err)
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			// Don't leave the job with half the data and no EOF in sight
+			stream.CloseSend()
+			return 0, fmt.Errorf("error reading stdin data: %w", readErr)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, fmt.Errorf("server returned error writing job stdin: %w", err)
+	}
+	return resp.BytesWritten, nil
+}
+
+// A failed Send only says the stream is broken. The real error comes with the response
+func closeStdinStream(stream jobmanagerpb.JobManager_WriteJobStdinClient, sendErr error) error {
+	if _, err := stream.CloseAndRecv(); err != nil {
+		return fmt.Errorf("server returned error writing job stdin: %w", err)
+	}
+	return fmt.Errorf("error sending job stdin: %w", sendErr)
+}
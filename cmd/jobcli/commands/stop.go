@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
 	"github.com/gopheryan/jobby/jobmanagerpb"
 	"github.com/spf13/cobra"
 )
@@ -25,7 +26,7 @@ var stopCmd = &cobra.Command{
 		defer conn.Close()
 
 		var id uuid.UUID
-		if id, err = uuid.Parse(args[0]); err != nil {
+		if id, err = jobid.Parse(args[0]); err != nil {
 			return fmt.Errorf("failed to parse job id: %w", err)
 		}
 
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+var previewParams map[string]string
+
+func init() {
+	templatePreviewCmd.Flags().StringToStringVarP(&previewParams, "param", "", nil, "NAME=VALUE parameter for the template")
+	templateCmd.AddCommand(templatePreviewCmd)
+	rootCmd.AddCommand(templateCmd)
+}
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Work with the job templates the server defines",
+}
+
+var templatePreviewCmd = &cobra.Command{
+	Use:   "preview name [--param k=v] ...",
+	Short: "Show the command a template runs with a set of parameters, without starting it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		client := jobmanagerpb.NewJobManagerClient(conn)
+		resp, err := client.PreviewTemplate(cmd.Context(), &jobmanagerpb.PreviewTemplateRequest{
+			Template: &jobmanagerpb.TemplateRef{Name: args[0], Params: previewParams},
+		})
+		if err != nil {
+			return fmt.Errorf("server returned error previewing template: %w", err)
+		}
+		printPreview(resp)
+		return nil
+	},
+}
+
+func printPreview(resp *jobmanagerpb.PreviewTemplateResponse) {
+	if resp.Description != "" {
+		fmt.Println(resp.Description)
+	}
+	for _, name := range slices.Sorted(maps.Keys(resp.Params)) {
+		fmt.Printf("  %s=%s\n", name, resp.Params[name])
+	}
+	fmt.Printf("Command: %s\n", quoteCommand(resp.Command, resp.Args))
+}
+
+// The command line, with arguments quoted where a shell would need them
+func quoteCommand(command string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	for _, part := range append([]string{command}, args...) {
+		if part == "" || strings.ContainsAny(part, " \t\n\"'\\$`;&|<>()*?[]{}~#") {
+			part = strconv.Quote(part)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Shows what the template runs and asks whether to go on, when there's
+// someone at a terminal to ask. Otherwise the server's checks are enough
+func confirmTemplate(ctx context.Context, client jobmanagerpb.JobManagerClient, ref *jobmanagerpb.TemplateRef) error {
+	if _, err := unix.IoctlGetTermios(int(os.Stdin.Fd()), unix.TCGETS); err != nil || stdinFile == "-" {
+		return nil
+	}
+	resp, err := client.PreviewTemplate(ctx, &jobmanagerpb.PreviewTemplateRequest{Template: ref})
+	if err != nil {
+		return fmt.Errorf("server returned error previewing template: %w", err)
+	}
+	printPreview(resp)
+	fmt.Print("Start it? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errors.New("not started")
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var usageWindow time.Duration
+
+func init() {
+	usageCmd.Flags().DurationVarP(&usageWindow, "window", "w", 0, "only this accounting window (every window the server keeps if unset)")
+
+	rootCmd.AddCommand(usageCmd)
+}
+
+var usageCmd = &cobra.Command{
+	Use:  "usage",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		req := &jobmanagerpb.GetUsageSummaryRequest{}
+		if usageWindow != 0 {
+			req.Window = durationpb.New(usageWindow)
+		}
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).GetUsageSummary(cmd.Context(), req)
+		if err != nil {
+			return fmt.Errorf("server returned error getting usage: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "WINDOW\tOWNER\tJOBS\tATTEMPTS\tCPU SECONDS\tWALL SECONDS")
+		for _, window := range resp.Windows {
+			for _, usage := range window.Owners {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.1f\t%.1f\n",
+					window.Window.AsDuration(),
+					usage.Owner,
+					usage.JobsStarted,
+					usage.AttemptsFinished,
+					usage.CpuSeconds,
+					usage.WallSeconds,
+				)
+			}
+		}
+		return w.Flush()
+	},
+}
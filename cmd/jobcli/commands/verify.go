@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+)
+
+// Keeps a running length and checksum of the output received on a stream,
+// to compare with what the server says it sent once the stream ends
+type outputVerifier struct {
+	sum   hash.Hash
+	total uint64
+}
+
+func newOutputVerifier() *outputVerifier {
+	return &outputVerifier{sum: sha256.New()}
+}
+
+func (v *outputVerifier) add(data []byte) {
+	v.sum.Write(data)
+	v.total += uint64(len(data))
+}
+
+// Error if the output received doesn't match 'end'
+func (v *outputVerifier) check(end *jobmanagerpb.OutputEnd) error {
+	if end.TotalBytes != v.total {
+		return fmt.Errorf("received %d bytes of output but the server sent %d", v.total, end.TotalBytes)
+	}
+	if !bytes.Equal(end.Sha256, v.sum.Sum(nil)) {
+		return errors.New("checksum of the output received doesn't match the one the server sent")
+	}
+	return nil
+}
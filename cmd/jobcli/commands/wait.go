@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Exit code when --timeout runs out, like timeout(1)
+const waitTimeoutExitCode = 124
+
+var waitTimeout time.Duration
+
+func init() {
+	waitCmd.Flags().DurationVarP(&waitTimeout, "timeout", "", 0, "give up waiting after this long (wait forever if unset)")
+
+	rootCmd.AddCommand(waitCmd)
+}
+
+// Blocks until the job is finished and exits with its exit code, or 128 plus
+// the signal number if it was killed, so it can be chained in shell pipelines
+var waitCmd = &cobra.Command{
+	Use:  "wait job-id",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		conn, err := newClientConnection(host)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		id, err := jobid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse job id: %w", err)
+		}
+
+		ctx := cmd.Context()
+		if waitTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, waitTimeout)
+			defer cancel()
+		}
+		resp, err := jobmanagerpb.NewJobManagerClient(conn).WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: id[:]})
+		if status.Code(err) == codes.DeadlineExceeded && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			fmt.Fprintf(os.Stderr, "Job still running after %s\n", waitTimeout)
+			cmd.SilenceErrors = true
+			return &exitCodeError{code: waitTimeoutExitCode}
+		}
+		if err != nil {
+			return fmt.Errorf("server returned error waiting for job: %w", err)
+		}
+
+		code := jobExitCode(resp)
+		if code != 0 {
+			if reason := formatExitReason(resp.ExitReason, resp.Signal); reason != "" {
+				fmt.Fprintf(os.Stderr, "Job exited: %s\n", reason)
+			}
+			cmd.SilenceErrors = true
+			return &exitCodeError{code: code}
+		}
+		return nil
+	},
+}
+
+// The exit code a shell would report for the job's process
+func jobExitCode(resp *jobmanagerpb.GetStatusResponse) int {
+	if resp.ExitCode != nil {
+		return int(*resp.ExitCode)
+	}
+	if signal := unix.SignalNum(resp.Signal); signal != 0 {
+		return 128 + int(signal)
+	}
+	// Killed without a signal we know of
+	return 1
+}
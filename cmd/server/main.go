@@ -4,23 +4,65 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"errors"
+	"expvar"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
 	"net"
+	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/gopheryan/jobby/internal/acmetls"
+	"github.com/gopheryan/jobby/internal/audit"
 	"github.com/gopheryan/jobby/internal/authinterceptors"
+	"github.com/gopheryan/jobby/internal/buildinfo"
+	"github.com/gopheryan/jobby/internal/config"
+	"github.com/gopheryan/jobby/internal/encryption"
+	"github.com/gopheryan/jobby/internal/features"
+	"github.com/gopheryan/jobby/internal/leader"
+	"github.com/gopheryan/jobby/internal/listeners"
+	"github.com/gopheryan/jobby/internal/policy"
 	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/internal/spiffeauth"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/job"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"golang.org/x/crypto/acme/autocert"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	grpc_reflection "google.golang.org/grpc/reflection"
 )
 
-const address = "localhost:8443"
+// How long to wait for the SPIFFE agent to hand us our first SVID
+const spiffeStartupTimeout = 30 * time.Second
+
+// Extra time drained jobs get to be reaped after being killed
+const drainSlack = 5 * time.Second
+
+// Held by whichever server collects garbage in a shared store
+const storeGCLease = "store-gc"
+
+// Held by whichever server runs the schedules in a shared store
+const schedulerLease = "scheduler"
+
+// How often schedules are checked for runs that are due. Cron expressions
+// go by the minute, so runs start at most this late
+const scheduleInterval = 10 * time.Second
+
+// How long events still queued for a SIEM get to be delivered on the way out
+const exporterDrainTimeout = 5 * time.Second
 
 type UserGetterFunc func(context.Context) string
 
@@ -28,6 +70,12 @@ func (u UserGetterFunc) GetUserContext(ctx context.Context) string {
 	return u(ctx)
 }
 
+type CredentialExpiryGetterFunc func(context.Context) time.Time
+
+func (e CredentialExpiryGetterFunc) GetCredentialExpiry(ctx context.Context) time.Time {
+	return e(ctx)
+}
+
 // we have log.Fatal, but let's be consistent with slog
 func slogFatal(msg string, args ...any) {
 	slog.Error(msg, args...)
@@ -35,48 +83,491 @@ func slogFatal(msg string, args ...any) {
 }
 
 func main() {
+	configPath := flag.String("config", "", "path to YAML config file (defaults are used when omitted)")
+	flag.Parse()
+
+	// Admins can follow the log remotely (see StreamServerLogs)
+	serverLogs := service.NewLogBroadcaster(slog.NewTextHandler(os.Stderr, nil))
+	slog.SetDefault(slog.New(serverLogs))
 
-	tlsConfig, err := NewTLSConfig()
+	cfg := config.Default()
+	if *configPath != "" {
+		var err error
+		if cfg, err = config.Load(*configPath); err != nil {
+			slogFatal("Failed to load config", "error", err)
+		}
+	}
+	// Already validated along with the rest of the config
+	featureSet, err := cfg.FeatureSet()
 	if err != nil {
-		slogFatal("Failed to create TLS config", "error", err)
+		slogFatal("Invalid feature flags", "error", err)
+	}
+	build := buildinfo.Get()
+	slog.Info("Starting jobby server", "version", build.Version, "commit", build.Commit, "modified", build.Modified)
+	// Served along with the metrics, under /debug/vars
+	expvar.Publish("build", expvar.Func(func() any { return build }))
+	expvar.Publish("features", expvar.Func(func() any {
+		enabled := map[string]bool{}
+		for _, feature := range featureSet.All() {
+			enabled[string(feature.Flag)] = feature.Enabled
+		}
+		return enabled
+	}))
+
+	// Config validation only looks at the path. Make sure symlinks don't
+	// point it somewhere it shouldn't be either
+	if err := job.ValidateOutputDir(cfg.OutputDir); err != nil {
+		slogFatal("Invalid output directory", "error", err)
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLS.SPIFFE.Enabled {
+		var source *workloadapi.X509Source
+		tlsConfig, source, err = NewSPIFFETLSConfig(cfg.TLS.SPIFFE)
+		if err != nil {
+			slogFatal("Failed to create SPIFFE TLS config", "error", err)
+		}
+		defer source.Close()
+
+		if cfg.Auth.Identity == string(authinterceptors.IdentityCommonName) {
+			slog.Warn("SPIFFE is enabled but users are identified by common name. Consider 'uri' identity")
+		}
+	} else if cfg.TLS.ACME.Enabled {
+		var manager *autocert.Manager
+		tlsConfig, manager, err = NewACMETLSConfig(cfg.TLS)
+		if err != nil {
+			slogFatal("Failed to create ACME TLS config", "error", err)
+		}
+
+		if cfg.TLS.ACME.HTTPAddress != "" {
+			go func() {
+				slog.Info("Answering ACME HTTP-01 challenges", "address", cfg.TLS.ACME.HTTPAddress)
+				err := http.ListenAndServe(cfg.TLS.ACME.HTTPAddress, manager.HTTPHandler(nil))
+				slog.Error("ACME HTTP-01 listener exited", "error", err)
+			}()
+		}
+	} else {
+		tlsConfig, err = NewTLSConfig(cfg.TLS)
+		if err != nil {
+			slogFatal("Failed to create TLS config", "error", err)
+		}
+	}
+
+	var anonymousMethods []string
+	if cfg.Auth.Anonymous {
+		// Certificates are still verified when they're given
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		anonymousMethods = service.PublicMethods
+	}
+	var jobTokens *service.JobTokens
+	if cfg.Auth.JobTokens {
+		// Jobs call back with their token instead of a certificate
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		jobTokens = service.NewJobTokens()
+	}
+	authenticator, err := authinterceptors.NewAuthenticator(authinterceptors.IdentityConfig{
+		Mode:         authinterceptors.IdentityMode(cfg.Auth.Identity),
+		URIPrefix:    cfg.Auth.URIPrefix,
+		FallbackToCN: cfg.Auth.FallbackToCN,
+	}, anonymousMethods...)
+	if err != nil {
+		slogFatal("Failed to create authenticator", "error", err)
+	}
+	if jobTokens != nil {
+		authenticator.AcceptTokens(jobTokens)
+	}
+	allowed := cfg.Auth.AllowedClients
+	err = authenticator.AllowOnly(authinterceptors.ClientAllowlist{
+		CommonNames: allowed.CommonNames,
+		URIs:        allowed.URIs,
+		SPKIPins:    allowed.SPKIPins,
+	})
+	if err != nil {
+		slogFatal("Invalid client allowlist", "error", err)
+	}
+	// Filled in along with the event log's. Bans only start once we serve
+	var banExporters []*audit.Exporter
+	var failureTracker *authinterceptors.FailureTracker
+	if bans := cfg.Auth.FailureBans; bans.Enabled {
+		failureTracker = authinterceptors.NewFailureTracker(authinterceptors.BanPolicy{
+			MaxFailures: bans.MaxFailures,
+			Window:      bans.Window,
+			Ban:         bans.Ban,
+			MaxBan:      bans.MaxBan,
+			OnBan: func(ban authinterceptors.Ban) {
+				slog.Warn("Banned caller for failing to authenticate", "kind", ban.Kind, "caller", ban.Key, "until", ban.Until, "count", ban.Count)
+				for _, exporter := range banExporters {
+					exporter.Export(audit.Event{
+						Time:   time.Now(),
+						Type:   "AUTH_BANNED",
+						Actor:  ban.Key,
+						Detail: fmt.Sprintf("banned by %s until %s (ban %d)", ban.Kind, ban.Until.Format(time.RFC3339), ban.Count),
+					})
+				}
+			},
+		})
+		authenticator.TrackFailures(failureTracker)
+	}
+	if proxies := cfg.Auth.ForwardedClientCert.TrustedProxies; len(proxies) > 0 {
+		// Proxies verify certificates too, but we go by our own CA
+		roots, err := loadCAPool(cfg.TLS.CACert)
+		if err != nil {
+			slogFatal("Failed to load CA for forwarded client certificates", "error", err)
+		}
+		authenticator.AcceptForwardedCerts(proxies, roots)
+		slog.Info("Accepting forwarded client certificates", "proxies", proxies)
+	}
+
+	topLevelTLS := config.ListenerTLS{CACert: cfg.TLS.CACert, Cert: cfg.TLS.Cert, Key: cfg.TLS.Key}
+	var grpcListeners []net.Listener
+	for _, listenerCfg := range cfg.GRPCListeners() {
+		listener, err := listeners.Listen(listenerCfg.Network, listenerCfg.Address)
+		if err != nil {
+			slogFatal("Failed to create TLS listener", "address", listenerCfg.Address, "error", err)
+		}
+		defer listener.Close()
+		if listenerCfg.ProxyProtocol.Enabled {
+			// Already validated along with the rest of the config
+			trusted, err := listenerCfg.ProxyProtocol.Prefixes()
+			if err != nil {
+				slogFatal("Invalid trusted proxies", "address", listenerCfg.Address, "error", err)
+			}
+			listener = listeners.WithProxyProtocol(listener, trusted)
+		}
+		if listenerCfg.TLS != topLevelTLS {
+			listenerTLS, err := NewTLSConfig(config.TLS{CACert: listenerCfg.TLS.CACert, Cert: listenerCfg.TLS.Cert, Key: listenerCfg.TLS.Key})
+			if err != nil {
+				slogFatal("Failed to create TLS config", "address", listenerCfg.Address, "error", err)
+			}
+			// Clients are authenticated the same way on every listener
+			listenerTLS.ClientAuth = tlsConfig.ClientAuth
+			listener = listeners.WithTLS(listener, listenerTLS)
+		}
+		grpcListeners = append(grpcListeners, listener)
 	}
-	// Harcoded!
-	listener, err := net.Listen("tcp", address)
+
+	// Already validated along with the rest of the config
+	rules, err := cfg.PolicyRules()
 	if err != nil {
-		slogFatal("Failed to create TLS listener", "error", err)
+		slogFatal("Invalid policy", "error", err)
+	}
+	requestPolicy := policy.New(rules...)
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		grpc_recovery.UnaryServerInterceptor(),
+		authenticator.UnaryInterceptor,
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		grpc_recovery.StreamServerInterceptor(),
+		authenticator.StreamInterceptor,
+	}
+	var credentialLeases *service.CredentialLeases
+	if cfg.Auth.CredentialLeases.Enabled {
+		// Every authenticated call renews its caller's lease, even ones
+		// refused further down
+		credentialLeases = service.NewCredentialLeases(service.CredentialLeasePolicy{
+			Lease: cfg.Auth.CredentialLeases.Lease,
+			Grace: cfg.Auth.CredentialLeases.Grace,
+		}, UserGetterFunc(authinterceptors.GetUserContext), CredentialExpiryGetterFunc(authinterceptors.GetCredentialExpiry), nil)
+		unaryInterceptors = append(unaryInterceptors, credentialLeases.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, credentialLeases.StreamInterceptor)
+	}
+	var adminServices *service.AdminServices
+	if featureSet.Enabled(features.GRPCAdmin) {
+		adminServices = service.NewAdminServices(UserGetterFunc(authinterceptors.GetUserContext), cfg.Admins)
+		unaryInterceptors = append(unaryInterceptors, adminServices.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, adminServices.StreamInterceptor)
 	}
-	defer listener.Close()
+	unaryInterceptors = append(unaryInterceptors, featureSet.UnaryInterceptor, requestPolicy.UnaryInterceptor)
+	streamInterceptors = append(streamInterceptors, featureSet.StreamInterceptor, requestPolicy.StreamInterceptor)
 
 	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			grpc_recovery.UnaryServerInterceptor(),
-			authinterceptors.AuthHandlerUnaryInterceptor,
-		),
-		grpc.ChainStreamInterceptor(
-			grpc_recovery.StreamServerInterceptor(),
-			authinterceptors.AuthHandlerStreamInterceptor,
-		),
-		grpc.Creds(credentials.NewTLS(&tlsConfig)),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		grpc.Creds(listeners.Credentials(credentials.NewTLS(tlsConfig))),
 	)
 
-	jobbyService := service.NewJobService(UserGetterFunc(authinterceptors.GetUserContext), os.TempDir())
+	if cfg.Reaper.Subreaper {
+		// Before any job starts
+		err := job.EnableSubreaper(job.ReaperOptions{
+			SweepInterval: cfg.Reaper.SweepInterval,
+			KillOnExit:    cfg.Reaper.KillOnExit,
+		})
+		if err != nil {
+			slogFatal("Failed to become a child subreaper", "error", err)
+		}
+	}
+
+	// Already validated along with the rest of the config
+	egressPolicies, err := cfg.EgressPolicies()
+	if err != nil {
+		slogFatal("Invalid egress policies", "error", err)
+	}
+	serviceOpts := []service.Option{
+		service.WithRetention(service.RetentionLimits{
+			DefaultTTL:        cfg.Retention.DefaultTTL,
+			MaxTTL:            cfg.Retention.MaxTTL,
+			AllowKeepForever:  cfg.Retention.AllowKeepForever,
+			DeleteGracePeriod: cfg.Retention.DeleteGracePeriod,
+		}),
+		service.WithOutputBatching(service.OutputBatching{
+			MaxBytes:    cfg.Output.BatchMaxBytes,
+			MaxDelay:    cfg.Output.BatchMaxDelay,
+			MaxLineHold: cfg.Output.MaxLineHold,
+		}),
+		service.WithOutputRateLimits(service.OutputRateLimits{
+			PerStream: cfg.Output.RateLimit.PerStream,
+			PerUser:   cfg.Output.RateLimit.PerUser,
+			Global:    cfg.Output.RateLimit.Global,
+		}),
+		service.WithQuotaLimits(service.QuotaLimits{
+			PerUserBytes: cfg.Quota.PerUserBytes,
+			Action:       quotaAction(cfg.Quota.Action),
+		}),
+		service.WithRuntimeClasses(runtimeClasses(cfg, egressPolicies), cfg.DefaultRuntimeClass),
+		service.WithEgressPolicies(egressPolicies),
+		service.WithCapacity(service.Capacity{
+			MaxRunningJobs:  cfg.Capacity.MaxRunningJobs,
+			PreemptionGrace: cfg.Capacity.PreemptionGrace,
+			Headroom: service.Headroom{
+				MinFreeMemory: cfg.Capacity.Headroom.MinFreeMemory,
+				MinFreeDisk:   cfg.Capacity.Headroom.MinFreeDisk,
+				MaxLoadPerCPU: cfg.Capacity.Headroom.MaxLoadPerCPU,
+			},
+		}),
+		service.WithUsageAccounting(service.UsageAccounting{
+			Windows: cfg.Usage.Windows,
+			Viewers: cfg.Usage.Viewers,
+		}),
+	}
+	// Already validated along with the rest of the config
+	outputSync, err := cfg.Output.Sync.SyncPolicy()
+	if err != nil {
+		slogFatal("Invalid output sync settings", "error", err)
+	}
+	serviceOpts = append(serviceOpts, service.WithOutputSync(outputSync))
+	if len(cfg.Redactions) > 0 {
+		// Already validated along with the rest of the config
+		redactions, err := cfg.JobRedactions()
+		if err != nil {
+			slogFatal("Invalid redactions", "error", err)
+		}
+		serviceOpts = append(serviceOpts, service.WithRedactions(redactions))
+	}
+	if len(cfg.Templates) > 0 {
+		// Already validated along with the rest of the config
+		jobTemplates, err := cfg.JobTemplates()
+		if err != nil {
+			slogFatal("Invalid templates", "error", err)
+		}
+		serviceOpts = append(serviceOpts, service.WithTemplates(jobTemplates))
+	}
+	// The policy's interceptor sees template jobs before they're rendered,
+	// and never sees scheduled runs
+	serviceOpts = append(serviceOpts, service.WithPolicy(requestPolicy))
+	gpus, err := job.GPUs()
+	if err != nil {
+		// Jobs can still run, just not on GPUs
+		slog.Error("Failed to list GPUs", "error", err)
+	} else if len(gpus) > 0 {
+		slog.Info("Found GPUs", "count", len(gpus))
+		serviceOpts = append(serviceOpts, service.WithGPUs(gpus))
+	}
+	if cfg.Encryption.MasterKeyFile != "" {
+		keys, err := loadMasterKey(cfg.Encryption.MasterKeyFile)
+		if err != nil {
+			slogFatal("Failed to load output encryption key", "error", err)
+		}
+		serviceOpts = append(serviceOpts, service.WithOutputEncryption(keys))
+	}
+	var registry *prometheus.Registry
+	if cfg.Metrics.Address != "" {
+		registry = prometheus.NewRegistry()
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		serviceOpts = append(serviceOpts, service.WithMetrics(registry))
+		if failureTracker != nil {
+			registry.MustRegister(failureTracker.Collectors()...)
+		}
+	}
+	events, err := service.OpenEventLog(cfg.EventsFile(), cfg.Events.Retention)
+	if err != nil {
+		slogFatal("Failed to open job event log", "error", err)
+	}
+	defer events.Close()
+	for _, exporterCfg := range cfg.Events.Exporters {
+		exporter, err := newEventExporter(exporterCfg)
+		if err != nil {
+			slogFatal("Failed to set up event exporter", "exporter", exporterCfg.Name, "error", err)
+		}
+		events.ExportTo(exporter)
+		banExporters = append(banExporters, exporter)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), exporterDrainTimeout)
+			defer cancel()
+			if err := exporter.Close(ctx); err != nil {
+				slog.Error("Failed to deliver queued events", "exporter", exporterCfg.Name, "error", err)
+			}
+		}()
+		slog.Info("Exporting job events", "exporter", exporterCfg.Name, "address", exporterCfg.Address)
+	}
+	serviceOpts = append(serviceOpts, service.WithEventLog(events))
+	var metadata store.Store
+	if cfg.Store.Backend != "none" {
+		metadata, err = store.Open(context.Background(), cfg.Store.Backend, cfg.StoreLocation())
+		if err != nil {
+			slogFatal("Failed to open metadata store", "backend", cfg.Store.Backend, "error", err)
+		}
+		defer metadata.Close()
+		serviceOpts = append(serviceOpts, service.WithStore(metadata))
+		slog.Info("Writing job metadata to store", "backend", cfg.Store.Backend)
+	}
+	node := cfg.Store.HA.Node
+	if cfg.Store.HA.Enabled && node == "" {
+		if node, err = os.Hostname(); err != nil {
+			slogFatal("Failed to get hostname for ha node name", "error", err)
+		}
+	}
+	if node != "" {
+		serviceOpts = append(serviceOpts, service.WithNode(node))
+	}
+	serviceOpts = append(serviceOpts, service.WithAdmins(cfg.Admins), service.WithServerLogs(serverLogs))
+	serviceOpts = append(serviceOpts, service.WithFeatures(featureSet))
+	if cfg.Auth.Anonymous {
+		serviceOpts = append(serviceOpts, service.WithPublicJobs())
+	}
+	if credentialLeases != nil {
+		serviceOpts = append(serviceOpts, service.WithCredentialLeases(credentialLeases))
+	}
+	if jobTokens != nil {
+		serviceOpts = append(serviceOpts, service.WithJobTokens(jobTokens))
+	}
+	if cfg.Scratch.MaxTmpfsBytes > 0 {
+		serviceOpts = append(serviceOpts, service.WithScratchTmpfs(cfg.Scratch.MaxTmpfsBytes))
+	}
+	if len(cfg.Hooks.PreStart) > 0 {
+		serviceOpts = append(serviceOpts, service.WithPreStartHooks(serviceHooks(cfg.Hooks.PreStart)))
+	}
+	if len(cfg.Hooks.PostJob) > 0 {
+		serviceOpts = append(serviceOpts, service.WithPostJobHooks(serviceHooks(cfg.Hooks.PostJob)))
+	}
+	jobbyService := service.NewJobService(UserGetterFunc(authinterceptors.GetUserContext), cfg.OutputDir, serviceOpts...)
 	jobbyService.Register(grpcServer)
 
+	var metricsTLS *tls.Config
+	if cfg.Metrics.TLS {
+		metricsTLS = serverOnlyTLSConfig(tlsConfig)
+	}
+	for _, listener := range cfg.Metrics.Listeners() {
+		mux := http.NewServeMux()
+		if listener.Metrics {
+			mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+			mux.Handle("/debug/vars", expvar.Handler())
+		}
+		if listener.Health {
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, "ok")
+			})
+			mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+				if jobbyService.Draining() {
+					http.Error(w, "draining", http.StatusServiceUnavailable)
+					return
+				}
+				fmt.Fprintln(w, "ok")
+			})
+		}
+		if listener.Pprof {
+			mux.HandleFunc("/debug/pprof/", pprof.Index)
+			mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+			mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+			mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+			mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		}
+		server := &http.Server{Addr: listener.Address, Handler: mux, TLSConfig: metricsTLS}
+		go func() {
+			slog.Info("Serving monitoring endpoints", "address", listener.Address, "metrics", listener.Metrics,
+				"health", listener.Health, "pprof", listener.Pprof, "tls", metricsTLS != nil)
+			var err error
+			if metricsTLS != nil {
+				// Certificates come from the TLS config
+				err = server.ListenAndServeTLS("", "")
+			} else {
+				err = server.ListenAndServe()
+			}
+			slog.Error("Monitoring listener exited", "address", listener.Address, "error", err)
+		}()
+	}
+
+	gcCtx, stopGC := context.WithCancel(context.Background())
+	defer stopGC()
+	go jobbyService.RunGarbageCollector(gcCtx, cfg.Retention.GCInterval)
+	if credentialLeases != nil {
+		go jobbyService.RunCredentialLeases(gcCtx, cfg.Auth.CredentialLeases.CheckInterval)
+	}
+	if cfg.Store.HA.Enabled {
+		// Only the leader cleans up after servers that are gone
+		elector := leader.New(metadata, storeGCLease, node, cfg.Store.HA.LeaseTTL, nil)
+		go elector.Run(gcCtx, func(ctx context.Context) {
+			jobbyService.RunStoreCollector(ctx, cfg.Retention.GCInterval)
+		})
+		// Or runs schedules, which would otherwise start every job once per server
+		scheduler := leader.New(metadata, schedulerLease, node, cfg.Store.HA.LeaseTTL, nil)
+		go scheduler.Run(gcCtx, func(ctx context.Context) {
+			jobbyService.RunScheduler(ctx, scheduleInterval)
+		})
+		slog.Info("Sharing store with other servers", "node", node)
+	} else if metadata != nil {
+		go jobbyService.RunScheduler(gcCtx, scheduleInterval)
+	}
+
 	// So I can poke at this thing with grpcurl
-	grpc_reflection.Register(grpcServer)
+	if featureSet.Enabled(features.GRPCReflection) {
+		grpc_reflection.Register(grpcServer)
+	}
+	if adminServices != nil {
+		cleanup, err := adminServices.Register(grpcServer)
+		if err != nil {
+			slogFatal("Failed to register gRPC admin services", "error", err)
+		}
+		defer cleanup()
+	}
 
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, os.Interrupt)
+	signalChan := make(chan os.Signal, 2)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
 
-	// Catch sigterm and exit
+	// Catch sigterm and exit once jobs are drained. A second signal stops
+	// waiting for them
 	go func() {
 		<-signalChan
-		slog.Info("Caught signal. Stopping Server")
+		slog.Info("Caught signal. Draining jobs")
+		// Stopped jobs may take their grace period to exit, and a little
+		// longer to be reaped once they're killed
+		drainCtx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.DrainTimeout+cfg.Shutdown.StopGrace+drainSlack)
+		go func() {
+			<-signalChan
+			cancel()
+		}()
+		err := jobbyService.Drain(drainCtx, service.DrainPolicy{
+			Timeout:   cfg.Shutdown.DrainTimeout,
+			StopGrace: cfg.Shutdown.StopGrace,
+		})
+		cancel()
+		if err != nil {
+			slog.Warn("Stopping server before every job exited", "error", err)
+		}
+		slog.Info("Stopping Server")
 		grpcServer.Stop()
 	}()
 
-	slog.Info("Listening for gRPC requests!", "address", address)
-	err = grpcServer.Serve(listener)
+	for _, listener := range grpcListeners[1:] {
+		go func() {
+			slog.Info("Listening for gRPC requests!", "address", listener.Addr().String())
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("gRPC server returned with error: %s", err)
+			}
+		}()
+	}
+	slog.Info("Listening for gRPC requests!", "address", grpcListeners[0].Addr().String())
+	err = grpcServer.Serve(grpcListeners[0])
 	if err != nil {
 		log.Fatalf("gRPC server returned with error: %s", err)
 	}
@@ -84,29 +575,160 @@ func main() {
 	slog.Info("nighty night!")
 }
 
-// Hardcoded!
-func NewTLSConfig() (tls.Config, error) {
+func loadCAPool(path string) (*x509.CertPool, error) {
 	localPool := x509.NewCertPool()
 
-	caCertData, err := os.ReadFile("ca/ca.crt")
+	caCertData, err := os.ReadFile(path)
 	if err != nil {
-		return tls.Config{}, fmt.Errorf("error loading ca crt: %w", err)
+		return nil, fmt.Errorf("error loading ca crt: %w", err)
 	}
 
 	ok := localPool.AppendCertsFromPEM(caCertData)
 	if !ok {
-		return tls.Config{}, errors.New("error parsing ca cert")
+		return nil, errors.New("error parsing ca cert")
+	}
+	return localPool, nil
+}
+
+func loadMasterKey(path string) (encryption.KeyWrapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading master key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding master key: %w", err)
+	}
+	return encryption.NewLocalWrapper(key)
+}
+
+func newEventExporter(cfg config.EventExporter) (*audit.Exporter, error) {
+	var tlsConfig *tls.Config
+	if cfg.Network == audit.NetworkTLS {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.CACert != "" {
+			pool, err := loadCAPool(cfg.CACert)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if cfg.Cert != "" {
+			cert, err := tls.LoadX509KeyPair(cfg.Cert, cfg.Key)
+			if err != nil {
+				return nil, fmt.Errorf("error loading client cert/key: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+	return audit.New(audit.Config{
+		Name:      cfg.Name,
+		Format:    cfg.Format,
+		Network:   cfg.Network,
+		Address:   cfg.Address,
+		TLS:       tlsConfig,
+		Facility:  cfg.Facility,
+		Version:   buildinfo.Get().Version,
+		QueueSize: cfg.QueueSize,
+	})
+}
+
+func NewTLSConfig(cfg config.TLS) (*tls.Config, error) {
+	localPool, err := loadCAPool(cfg.CACert)
+	if err != nil {
+		return nil, err
 	}
 
-	serverCertificate, err := tls.LoadX509KeyPair("server/server.crt", "server/server.key")
+	serverCertificate, err := tls.LoadX509KeyPair(cfg.Cert, cfg.Key)
 	if err != nil {
-		return tls.Config{}, fmt.Errorf("error loading server cert/key: %w", err)
+		return nil, fmt.Errorf("error loading server cert/key: %w", err)
 	}
 
-	return tls.Config{
+	return &tls.Config{
 		MinVersion:   tls.VersionTLS13,
 		Certificates: []tls.Certificate{serverCertificate},
 		ClientCAs:    localPool,
 		ClientAuth:   tls.RequireAndVerifyClientCert,
 	}, nil
 }
+
+// For listeners that don't authenticate clients: the gRPC listener's
+// certificate, without asking for the client's
+func serverOnlyTLSConfig(base *tls.Config) *tls.Config {
+	cfg := base.Clone()
+	cfg.ClientAuth = tls.NoClientCert
+	cfg.ClientCAs = nil
+	cfg.VerifyPeerCertificate = nil
+	cfg.VerifyConnection = nil
+	return cfg
+}
+
+// Fetches the server's SVID and trust bundle from the Workload API.
+// The returned source keeps them rotated and must be closed on shutdown
+func NewSPIFFETLSConfig(cfg config.SPIFFE) (*tls.Config, *workloadapi.X509Source, error) {
+	authorizer, err := spiffeauth.Authorizer("", cfg.TrustDomain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spiffeStartupTimeout)
+	defer cancel()
+	source, err := spiffeauth.NewSource(ctx, cfg.Socket)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return spiffeauth.ServerTLSConfig(source, authorizer), source, nil
+}
+
+// The server certificate comes from the ACME CA while client
+// certificates are still verified against our own CA
+func NewACMETLSConfig(cfg config.TLS) (*tls.Config, *autocert.Manager, error) {
+	clientCAs, err := loadCAPool(cfg.CACert)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manager, err := acmetls.NewManager(acmetls.Options{
+		Domains:      cfg.ACME.Domains,
+		Email:        cfg.ACME.Email,
+		CacheDir:     cfg.ACME.CacheDir,
+		DirectoryURL: cfg.ACME.DirectoryURL,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return acmetls.ServerTLSConfig(manager, clientCAs), manager, nil
+}
+
+// Config is validated, so anything but "truncate" is "stop"
+func quotaAction(action string) job.QuotaAction {
+	if action == "truncate" {
+		return job.QuotaActionTruncate
+	}
+	return job.QuotaActionStop
+}
+
+func runtimeClasses(cfg config.Server, egressPolicies map[string]job.Egress) map[string]job.Limits {
+	classes := make(map[string]job.Limits, len(cfg.RuntimeClasses))
+	for name, class := range cfg.RuntimeClasses {
+		limits := class.Limits(cfg.CgroupParent)
+		limits.Isolation.Egress = egressPolicies[class.Isolation.EgressPolicy]
+		classes[name] = limits
+	}
+	return classes
+}
+
+func serviceHooks(hooks []config.Hook) []service.Hook {
+	out := make([]service.Hook, 0, len(hooks))
+	for _, hook := range hooks {
+		out = append(out, service.Hook{
+			Name:    hook.Name,
+			Command: hook.Command,
+			URL:     hook.URL,
+			Timeout: hook.Timeout,
+		})
+	}
+	return out
+}
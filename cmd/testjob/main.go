@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Test program for jobs to run. With just a count it writes "stdout N" and
+// "stderr N" lines that many times, 500 milliseconds apart:
+//
+//	testjob 5
+//
+// Flags make it do more, in this order: echo stdin, write a burst of
+// output, write binary output, write the counted lines, sleep, then exit
+// with -exit or be killed by -signal
+func main() {
+	exitCode := flag.Int("exit", 0, "exit code to exit with once done")
+	sig := flag.String("signal", "", "signal to send itself once done instead of exiting (ex: TERM, KILL)")
+	burst := flag.Int("burst", 0, "bytes of text output to write to stdout in a single write")
+	binary := flag.Int("binary", 0, "bytes of binary output (every byte value, repeating) to write to stdout")
+	stdin := flag.Bool("stdin", false, "copy stdin to stdout until it's closed")
+	interval := flag.String("interval", "500ms", "time between counted lines. A comma separated list is cycled through (ex: 100ms,1s)")
+	sleep := flag.Duration("sleep", 0, "time to sleep before exiting")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: testjob [flags] [count]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	count := 0
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		var err error
+		if count, err = strconv.Atoi(flag.Arg(0)); err != nil || count < 0 {
+			fail("invalid count %q", flag.Arg(0))
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	intervals, err := parseIntervals(*interval)
+	if err != nil {
+		fail("invalid interval: %v", err)
+	}
+	var signal syscall.Signal
+	if *sig != "" {
+		if signal = unix.SignalNum("SIG" + strings.TrimPrefix(strings.ToUpper(*sig), "SIG")); signal == 0 {
+			fail("unknown signal %q", *sig)
+		}
+	}
+
+	if *stdin {
+		if _, err := io.Copy(os.Stdout, os.Stdin); err != nil {
+			fail("failed to copy stdin: %v", err)
+		}
+	}
+	if *burst > 0 {
+		data := make([]byte, *burst)
+		for i := range data {
+			// Lines of the alphabet, so truncation shows where it happened
+			if i%27 == 26 {
+				data[i] = '\n'
+			} else {
+				data[i] = 'a' + byte(i%27)
+			}
+		}
+		writeAll(data)
+	}
+	if *binary > 0 {
+		data := make([]byte, *binary)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		writeAll(data)
+	}
+
+	for idx := range count {
+		fmt.Printf("stdout %d\n", idx+1)
+		fmt.Fprintf(os.Stderr, "stderr %d\n", idx+1)
+		time.Sleep(intervals[idx%len(intervals)])
+	}
+	time.Sleep(*sleep)
+
+	if signal != 0 {
+		if err := unix.Kill(os.Getpid(), signal); err != nil {
+			fail("failed to signal self: %v", err)
+		}
+		// Only reached if the signal didn't kill us (ex: SIGQUIT, which Go
+		// handles itself, or a stop signal until we're continued)
+		time.Sleep(time.Second)
+		fail("still running after %s", signal)
+	}
+	os.Exit(*exitCode)
+}
+
+func parseIntervals(list string) ([]time.Duration, error) {
+	var intervals []time.Duration
+	for field := range strings.SplitSeq(list, ",") {
+		interval, err := time.ParseDuration(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		if interval < 0 {
+			return nil, fmt.Errorf("negative interval %s", interval)
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, nil
+}
+
+// One write, so the job sees the whole thing at once
+func writeAll(data []byte) {
+	if _, err := os.Stdout.Write(data); err != nil {
+		fail("failed to write output: %v", err)
+	}
+}
+
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "testjob: "+format+"\n", args...)
+	os.Exit(2)
+}
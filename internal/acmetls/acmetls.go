@@ -0,0 +1,74 @@
+package acmetls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"slices"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type Options struct {
+	// Names the server is reachable at. Certificates are only
+	// requested for these names
+	Domains []string
+	// Contact address registered with the ACME account (optional)
+	Email string
+	// Where certificates and the account key are stored between restarts
+	CacheDir string
+	// ACME directory. Defaults to Let's Encrypt production
+	DirectoryURL string
+}
+
+// NewManager creates an autocert manager that acquires and renews
+// the server certificate on demand
+func NewManager(opts Options) (*autocert.Manager, error) {
+	if len(opts.Domains) == 0 {
+		return nil, errors.New("at least one domain is required for ACME")
+	}
+	if opts.CacheDir == "" {
+		// Without a cache we would request a new certificate on every
+		// restart and quickly run into the CA's rate limits
+		return nil, errors.New("a cache directory is required for ACME")
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(opts.CacheDir),
+		HostPolicy: autocert.HostWhitelist(opts.Domains...),
+		Email:      opts.Email,
+	}
+	if opts.DirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: opts.DirectoryURL}
+	}
+	return manager, nil
+}
+
+// ServerTLSConfig serves the ACME issued certificate while still requiring
+// clients to present a certificate signed by our own CA.
+// TLS-ALPN-01 challenge connections from the ACME CA can't present a
+// client certificate, so those handshakes skip client verification. They
+// never make it to the gRPC server since they don't negotiate HTTP/2
+func ServerTLSConfig(manager *autocert.Manager, clientCAs *x509.CertPool) *tls.Config {
+	challengeConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: manager.GetCertificate,
+		NextProtos:     []string{acme.ALPNProto},
+	}
+
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS13,
+		GetCertificate: manager.GetCertificate,
+		ClientCAs:      clientCAs,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			if slices.Contains(hello.SupportedProtos, acme.ALPNProto) {
+				return challengeConfig, nil
+			}
+			// Use the outer config
+			return nil, nil
+		},
+	}
+}
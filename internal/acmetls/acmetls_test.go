@@ -0,0 +1,101 @@
+package acmetls_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/acmetls"
+	"github.com/gopheryan/jobby/internal/certgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+)
+
+func TestNewManagerValidation(t *testing.T) {
+	_, err := acmetls.NewManager(acmetls.Options{CacheDir: t.TempDir()})
+	assert.Error(t, err)
+
+	_, err = acmetls.NewManager(acmetls.Options{Domains: []string{"jobby.test"}})
+	assert.Error(t, err)
+}
+
+// A certificate already in the cache is served without talking to an
+// ACME CA, which lets us exercise the full handshake offline
+func TestServerTLSConfig(t *testing.T) {
+	cacheDir := t.TempDir()
+	manager, err := acmetls.NewManager(acmetls.Options{
+		Domains:  []string{"jobby.test"},
+		CacheDir: cacheDir,
+	})
+	require.NoError(t, err)
+
+	// Stands in for the public CA
+	publicCA, err := certgen.NewCA("PublicCA", time.Hour)
+	require.NoError(t, err)
+	serverCert, err := publicCA.IssueServer([]string{"jobby.test"}, time.Hour)
+	require.NoError(t, err)
+
+	// autocert's cache format: private key followed by the certificate chain
+	keyPEM, err := serverCert.KeyPEM()
+	require.NoError(t, err)
+	cached := bytes.Join([][]byte{keyPEM, serverCert.CertPEM()}, nil)
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "jobby.test"), cached, 0600))
+
+	// Clients are still authenticated with our private CA
+	privateCA, err := certgen.NewCA("PrivateCA", time.Hour)
+	require.NoError(t, err)
+	clientCert, err := privateCA.IssueClient("someuser", time.Hour)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(privateCA.Cert)
+
+	serverCfg := acmetls.ServerTLSConfig(manager, clientCAs)
+
+	t.Run("challenge-config", func(tt *testing.T) {
+		cfg, err := serverCfg.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{acme.ALPNProto}})
+		require.NoError(tt, err)
+		require.NotNil(tt, cfg)
+		assert.Equal(tt, tls.NoClientCert, cfg.ClientAuth)
+
+		cfg, err = serverCfg.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{"h2"}})
+		require.NoError(tt, err)
+		assert.Nil(tt, cfg)
+	})
+
+	t.Run("handshake", func(tt *testing.T) {
+		roots := x509.NewCertPool()
+		roots.AddCert(publicCA.Cert)
+
+		serverConn, clientConn := net.Pipe()
+		defer serverConn.Close()
+		defer clientConn.Close()
+
+		srv := tls.Server(serverConn, serverCfg)
+		cli := tls.Client(clientConn, &tls.Config{
+			// autocert only serves ECDSA certs to clients advertising an
+			// ECDSA cipher suite, which TLS 1.3-only clients omit.
+			// Those clients get an RSA cert, which we haven't cached
+			MinVersion:   tls.VersionTLS12,
+			ServerName:   "jobby.test",
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{clientCert.TLSCertificate()},
+		})
+
+		srvErr := make(chan error)
+		go func() {
+			srvErr <- srv.Handshake()
+		}()
+		require.NoError(tt, cli.Handshake())
+		require.NoError(tt, <-srvErr)
+
+		peerCerts := srv.ConnectionState().PeerCertificates
+		require.Len(tt, peerCerts, 1)
+		assert.Equal(tt, "someuser", peerCerts[0].Subject.CommonName)
+	})
+}
@@ -0,0 +1,300 @@
+// Package audit sends the server's job events to SIEMs as they happen, as
+// RFC 5424 syslog or ArcSight CEF, over UDP, TCP or TLS. Each exporter
+// queues events and delivers them in order from its own goroutine, so a
+// slow or unreachable collector never holds up jobs. Events that can't be
+// delivered are retried, with backoff, until the queue fills up. Then the
+// oldest are dropped. Over TCP, an event written just as the collector
+// drops the connection may still be lost, as there are no acknowledgements
+package audit
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Formats
+const (
+	FormatSyslog = "syslog"
+	FormatCEF    = "cef"
+)
+
+// Networks
+const (
+	NetworkUDP = "udp"
+	NetworkTCP = "tcp"
+	NetworkTLS = "tls"
+)
+
+const (
+	defaultQueueSize = 10000
+	defaultFacility  = 16 // local0
+	dialTimeout      = 10 * time.Second
+	writeTimeout     = 10 * time.Second
+	// Retries back off from the first to the second, doubling each time
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// Event is one job event, as the exporters send it
+type Event struct {
+	Time  time.Time
+	JobID string
+	// Owner of the job
+	Owner string
+	// Short for the job event type (ex: STARTED, HOOK_FAILED), or
+	// AUTH_BANNED for callers banned for failing to authenticate
+	Type string
+	// Who caused it. "server" for events the server caused on its own
+	Actor   string
+	Attempt uint32
+	Detail  string
+}
+
+// Config describes where an exporter sends events, and how
+type Config struct {
+	// Named in the server's logs
+	Name string
+	// FormatSyslog or FormatCEF
+	Format string
+	// NetworkUDP, NetworkTCP or NetworkTLS
+	Network string
+	// host:port of the collector
+	Address string
+	// Used for NetworkTLS. Nil verifies the collector against the system's roots
+	TLS *tls.Config
+	// syslog facility, 0-23. Zero uses local0 (16)
+	Facility int
+	// Sent as the syslog HOSTNAME and CEF dvchost. Defaults to os.Hostname
+	Hostname string
+	// Version of the server, sent in CEF headers
+	Version string
+	// Events waiting to be delivered. Zero uses 10000
+	QueueSize int
+}
+
+// Exporter delivers events to one collector
+type Exporter struct {
+	cfg Config
+	// Retries back off from minRetry to maxRetry
+	minRetry, maxRetry time.Duration
+
+	lock    sync.Mutex
+	queue   []Event
+	dropped int
+	closed  bool
+	// Signaled when events are queued or the exporter closes
+	wake chan struct{}
+	done chan struct{}
+	// Stops delivery when closing gives up on what's left
+	cancel context.CancelFunc
+}
+
+// New checks 'cfg' and starts delivering the events passed to Export
+func New(cfg Config) (*Exporter, error) {
+	switch cfg.Format {
+	case FormatSyslog, FormatCEF:
+	default:
+		return nil, fmt.Errorf("unknown format '%s'. Must be one of syslog or cef", cfg.Format)
+	}
+	switch cfg.Network {
+	case NetworkUDP, NetworkTCP, NetworkTLS:
+	default:
+		return nil, fmt.Errorf("unknown network '%s'. Must be one of udp, tcp or tls", cfg.Network)
+	}
+	if _, _, err := net.SplitHostPort(cfg.Address); err != nil {
+		return nil, fmt.Errorf("invalid address '%s': %w", cfg.Address, err)
+	}
+	if cfg.Facility < 0 || cfg.Facility > 23 {
+		return nil, fmt.Errorf("facility must be between 0 and 23, got %d", cfg.Facility)
+	}
+	if cfg.Facility == 0 {
+		cfg.Facility = defaultFacility
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	return start(cfg, minRetryDelay, maxRetryDelay), nil
+}
+
+func start(cfg Config, minRetry, maxRetry time.Duration) *Exporter {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Exporter{
+		cfg:      cfg,
+		minRetry: minRetry,
+		maxRetry: maxRetry,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		cancel:   cancel,
+	}
+	go e.run(ctx)
+	return e
+}
+
+// Export queues an event to be delivered. It never blocks
+func (e *Exporter) Export(event Event) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.closed {
+		return
+	}
+	if len(e.queue) >= e.cfg.QueueSize {
+		e.queue = e.queue[1:]
+		e.dropped++
+	}
+	e.queue = append(e.queue, event)
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops taking events and gives those still queued until the
+// context is done to be delivered
+func (e *Exporter) Close(ctx context.Context) error {
+	e.lock.Lock()
+	e.closed = true
+	e.lock.Unlock()
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		e.cancel()
+		<-e.done
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		return fmt.Errorf("audit exporter %s: %d events not delivered: %w", e.cfg.Name, len(e.queue), ctx.Err())
+	}
+}
+
+// The oldest event, or false once there are none and the exporter is closed.
+// Waits for one otherwise
+func (e *Exporter) next(ctx context.Context) (Event, bool) {
+	for {
+		e.lock.Lock()
+		if dropped := e.dropped; dropped > 0 {
+			e.dropped = 0
+			slog.Warn("Dropped audit events the collector couldn't take in time", "exporter", e.cfg.Name, "count", dropped)
+		}
+		if len(e.queue) > 0 {
+			event := e.queue[0]
+			e.lock.Unlock()
+			return event, true
+		}
+		closed := e.closed
+		e.lock.Unlock()
+		if closed {
+			return Event{}, false
+		}
+		select {
+		case <-e.wake:
+		case <-ctx.Done():
+			return Event{}, false
+		}
+	}
+}
+
+// Remove 'event' from the front of the queue, unless it was dropped meanwhile
+func (e *Exporter) delivered(event Event) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if len(e.queue) > 0 && e.queue[0] == event {
+		e.queue = e.queue[1:]
+	}
+}
+
+func (e *Exporter) run(ctx context.Context) {
+	defer close(e.done)
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+	delay := e.minRetry
+	for {
+		event, ok := e.next(ctx)
+		if !ok {
+			return
+		}
+		err := func() error {
+			if conn == nil {
+				var err error
+				if conn, err = e.dial(ctx); err != nil {
+					return err
+				}
+			}
+			return e.write(conn, event)
+		}()
+		if err == nil {
+			e.delivered(event)
+			delay = e.minRetry
+			continue
+		}
+		if conn != nil {
+			_ = conn.Close()
+			conn = nil
+		}
+		slog.Warn("Failed to deliver audit event. Retrying", "exporter", e.cfg.Name, "retry-in", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, e.maxRetry)
+	}
+}
+
+func (e *Exporter) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if e.cfg.Network == NetworkTLS {
+		tlsConfig := e.cfg.TLS
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		return (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", e.cfg.Address)
+	}
+	return dialer.DialContext(ctx, e.cfg.Network, e.cfg.Address)
+}
+
+func (e *Exporter) write(conn net.Conn, event Event) error {
+	var msg string
+	if e.cfg.Format == FormatCEF {
+		msg = formatCEF(event, e.cfg.Hostname, e.cfg.Version)
+	} else {
+		msg = formatSyslog(event, e.cfg.Facility, e.cfg.Hostname)
+	}
+	var frame string
+	switch {
+	case e.cfg.Network == NetworkUDP:
+		// One message per datagram
+		frame = msg
+	case e.cfg.Format == FormatSyslog:
+		// Octet counting (RFC 6587), which lets messages hold newlines
+		frame = fmt.Sprintf("%d %s", len(msg), msg)
+	default:
+		// CEF collectors read a line per event. formatCEF escapes newlines
+		frame = msg + "\n"
+	}
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	n, err := conn.Write([]byte(frame))
+	if err == nil && n < len(frame) {
+		err = errors.New("short write")
+	}
+	return err
+}
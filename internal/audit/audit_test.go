@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var event = Event{
+	Time:    time.Date(2025, time.March, 4, 5, 6, 7, 890000000, time.UTC),
+	JobID:   "5c7e6f0e-3b8f-4d7a-9a55-0d6f8e4b1f2a",
+	Owner:   "alice",
+	Type:    "HOOK_FAILED",
+	Actor:   "server",
+	Attempt: 2,
+	Detail:  `notify: POST "x]" = 500`,
+}
+
+func TestFormatSyslog(t *testing.T) {
+	assert.Equal(t,
+		fmt.Sprintf(`<132>1 2025-03-04T05:06:07.890000Z build_host jobby %d HOOK_FAILED `, os.Getpid())+
+			`[jobby@32473 job_id="5c7e6f0e-3b8f-4d7a-9a55-0d6f8e4b1f2a" owner="alice" actor="server" attempt="2"] notify: POST "x]" = 500`,
+		formatSyslog(event, 16, "build host"))
+
+	// Values are escaped within structured data
+	msg := formatSyslog(Event{Type: "CREATED", Actor: `a"b\c]`}, 16, "")
+	assert.Contains(t, msg, ` - jobby `)
+	assert.Contains(t, msg, `actor="a\"b\\c\]"]`)
+	assert.True(t, strings.HasPrefix(msg, "<134>1 "))
+}
+
+func TestFormatCEF(t *testing.T) {
+	assert.Equal(t,
+		"CEF:0|gopheryan|jobby|v1.2.0|HOOK_FAILED|Job hook failed|5|rt=1741064767890 dvchost=host suser=server duser=alice "+
+			`cs1Label=jobId cs1=5c7e6f0e-3b8f-4d7a-9a55-0d6f8e4b1f2a cn1Label=attempt cn1=2 msg=notify: POST "x]" \= 500`,
+		formatCEF(event, "host", "v1.2.0"))
+
+	line := formatCEF(Event{Type: "CREATED", Actor: "a|b", Detail: "one\ntwo\\"}, "host", "v|1")
+	assert.True(t, strings.HasPrefix(line, `CEF:0|gopheryan|jobby|v\|1|CREATED|Job created|1|`))
+	assert.Contains(t, line, "suser=a|b")
+	assert.Contains(t, line, `msg=one\ntwo\\`)
+	assert.NotContains(t, line, "\n")
+
+	line = formatCEF(Event{Type: "AUTH_BANNED", Actor: "192.0.2.1"}, "host", "v1")
+	assert.True(t, strings.HasPrefix(line, "CEF:0|gopheryan|jobby|v1|AUTH_BANNED|Caller banned for failed authentication|5|"))
+}
+
+func TestNewErrors(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"format":   {Format: "json", Network: NetworkTCP, Address: "localhost:514"},
+		"network":  {Format: FormatSyslog, Network: "unix", Address: "localhost:514"},
+		"address":  {Format: FormatSyslog, Network: NetworkTCP, Address: "localhost"},
+		"facility": {Format: FormatSyslog, Network: NetworkTCP, Address: "localhost:514", Facility: 24},
+	} {
+		_, err := New(cfg)
+		assert.Error(t, err, name)
+	}
+}
+
+// Reads octet counted syslog messages from each connection
+func collect(t *testing.T, listener net.Listener, messages chan<- string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for {
+				length, err := reader.ReadString(' ')
+				if err != nil {
+					return
+				}
+				n, err := strconv.Atoi(strings.TrimSpace(length))
+				if !assert.NoError(t, err) {
+					return
+				}
+				msg := make([]byte, n)
+				if _, err := io.ReadFull(reader, msg); err != nil {
+					return
+				}
+				messages <- string(msg)
+			}
+		}()
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	// Nothing listens at first, so delivery has to be retried
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	exporter := start(Config{Name: "test", Format: FormatSyslog, Network: NetworkTCP, Address: address, Facility: 16, Hostname: "host", QueueSize: 10},
+		10*time.Millisecond, 50*time.Millisecond)
+	for i := range 3 {
+		e := event
+		e.Attempt = uint32(i + 1)
+		exporter.Export(e)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	listener, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer listener.Close()
+	messages := make(chan string, 10)
+	go collect(t, listener, messages)
+
+	for i := range 3 {
+		select {
+		case msg := <-messages:
+			assert.Contains(t, msg, fmt.Sprintf(`attempt="%d"`, i+1))
+		case <-time.After(10 * time.Second):
+			t.Fatalf("event %d wasn't delivered", i+1)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, exporter.Close(ctx))
+	// Closed exporters take no more
+	exporter.Export(event)
+	assert.Empty(t, exporter.queue)
+}
+
+func TestQueueLimit(t *testing.T) {
+	exporter, err := New(Config{Format: FormatCEF, Network: NetworkTCP, Address: "127.0.0.1:1", QueueSize: 2})
+	require.NoError(t, err)
+	for i := range 5 {
+		e := event
+		e.Attempt = uint32(i + 1)
+		exporter.Export(e)
+	}
+	exporter.lock.Lock()
+	require.Len(t, exporter.queue, 2)
+	assert.Equal(t, uint32(5), exporter.queue[1].Attempt)
+	exporter.lock.Unlock()
+
+	// Nowhere to deliver them
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, exporter.Close(ctx), context.DeadlineExceeded)
+}
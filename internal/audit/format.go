@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Syslog severities
+const (
+	severityWarning = 4
+	severityNotice  = 5
+	severityInfo    = 6
+)
+
+const appName = "jobby"
+
+// SD-ID of the structured data in syslog messages. 32473 is the enterprise
+// number set aside for examples (RFC 5612), as jobby doesn't have its own
+const sdID = "jobby@32473"
+
+func severity(eventType string) int {
+	switch eventType {
+	case "ATTEMPT_FAILED", "HOOK_FAILED", "AUTH_BANNED":
+		return severityWarning
+	case "SIGNALED", "DELETED", "RESTORED":
+		return severityNotice
+	default:
+		return severityInfo
+	}
+}
+
+// RFC 5424 message for 'event'
+func formatSyslog(event Event, facility int, hostname string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s ",
+		facility*8+severity(event.Type),
+		event.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(hostname, 255),
+		appName,
+		os.Getpid(),
+		headerField(event.Type, 32),
+	)
+	fmt.Fprintf(&b, `[%s job_id="%s" owner="%s" actor="%s"`, sdID, sdValue(event.JobID), sdValue(event.Owner), sdValue(event.Actor))
+	if event.Attempt != 0 {
+		fmt.Fprintf(&b, ` attempt="%d"`, event.Attempt)
+	}
+	b.WriteByte(']')
+	if event.Detail != "" {
+		b.WriteByte(' ')
+		b.WriteString(event.Detail)
+	}
+	return b.String()
+}
+
+// Header fields are printable ASCII without spaces, "-" when empty
+func headerField(value string, maxLen int) string {
+	if value == "" {
+		return "-"
+	}
+	field := []byte(value)
+	for i, c := range field {
+		if c <= ' ' || c > '~' {
+			field[i] = '_'
+		}
+	}
+	if len(field) > maxLen {
+		field = field[:maxLen]
+	}
+	return string(field)
+}
+
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func sdValue(value string) string {
+	return sdEscaper.Replace(value)
+}
+
+// CEF severities, 0-10
+func cefSeverity(eventType string) int {
+	switch severity(eventType) {
+	case severityWarning:
+		return 5
+	case severityNotice:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// Names of events that aren't about jobs, for CEF
+var eventNames = map[string]string{
+	"AUTH_BANNED": "Caller banned for failed authentication",
+}
+
+// CEF line for 'event'
+func formatCEF(event Event, hostname, version string) string {
+	name, ok := eventNames[event.Type]
+	if !ok {
+		name = "Job " + strings.ToLower(strings.ReplaceAll(event.Type, "_", " "))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|gopheryan|jobby|%s|%s|%s|%d|",
+		cefHeader(version), cefHeader(event.Type), cefHeader(name), cefSeverity(event.Type))
+	fields := []string{
+		"rt", strconv.FormatInt(event.Time.UnixMilli(), 10),
+		"dvchost", hostname,
+		"suser", event.Actor,
+		"duser", event.Owner,
+		"cs1Label", "jobId",
+		"cs1", event.JobID,
+	}
+	if event.Attempt != 0 {
+		fields = append(fields, "cn1Label", "attempt", "cn1", strconv.FormatUint(uint64(event.Attempt), 10))
+	}
+	if event.Detail != "" {
+		fields = append(fields, "msg", event.Detail)
+	}
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(fields[i])
+		b.WriteByte('=')
+		b.WriteString(cefExtensionEscaper.Replace(fields[i+1]))
+	}
+	return b.String()
+}
+
+var cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+
+func cefHeader(value string) string {
+	return cefHeaderEscaper.Replace(value)
+}
+
+var cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
@@ -0,0 +1,67 @@
+package authinterceptors
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"slices"
+)
+
+// ClientAllowlist narrows the client certificates accepted down from
+// every one the CA signed. A certificate is allowed if it matches any entry
+type ClientAllowlist struct {
+	CommonNames []string
+	// Matched against each URI SAN in full (ex: 'spiffe://example.org/user/ryan')
+	URIs []string
+	// Base64 encoded SHA-256 digests of certificates' public keys (see SPKIPin)
+	SPKIPins []string
+}
+
+func (l ClientAllowlist) Validate() error {
+	for _, pin := range l.SPKIPins {
+		digest, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(digest) != sha256.Size {
+			return fmt.Errorf("SPKI pin '%s' isn't a base64 encoded SHA-256 digest", pin)
+		}
+	}
+	return nil
+}
+
+func (l ClientAllowlist) empty() bool {
+	return len(l.CommonNames) == 0 && len(l.URIs) == 0 && len(l.SPKIPins) == 0
+}
+
+func (l ClientAllowlist) allows(cert *x509.Certificate) bool {
+	if slices.Contains(l.CommonNames, cert.Subject.CommonName) {
+		return true
+	}
+	for _, uri := range cert.URIs {
+		if slices.Contains(l.URIs, uri.String()) {
+			return true
+		}
+	}
+	return slices.Contains(l.SPKIPins, SPKIPin(cert))
+}
+
+// SPKIPin is the base64 encoded SHA-256 digest of the certificate's
+// SubjectPublicKeyInfo, as in HPKP's pin-sha256. Unlike the certificate's
+// own fingerprint it stays the same when it's reissued with the same key
+func SPKIPin(cert *x509.Certificate) string {
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// AllowOnly turns away callers whose certificate isn't on 'list', even when
+// the CA signed it (ex: so a certificate stolen from another service can't
+// be used). That includes proxies and the certificates they forward.
+// Callers without a certificate are unaffected
+func (a *Authenticator) AllowOnly(list ClientAllowlist) error {
+	if err := list.Validate(); err != nil {
+		return err
+	}
+	if !list.empty() {
+		a.allowlist = &list
+	}
+	return nil
+}
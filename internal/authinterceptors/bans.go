@@ -0,0 +1,253 @@
+package authinterceptors
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/listeners"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// What a failure or ban was counted against
+type BanKind string
+
+const (
+	// The caller's IP address
+	BanAddress BanKind = "address"
+	// The public key of a rejected certificate (see SPKIPin). Not the user
+	// it names, as any certificate the CA signed could name someone else
+	BanCertificate BanKind = "certificate"
+)
+
+type BanPolicy struct {
+	// Failures within Window that get a caller banned
+	MaxFailures int
+	Window      time.Duration
+	// How long the first ban lasts. Each ban after it lasts twice as long
+	// as the one before, up to MaxBan. Callers that stay out of trouble
+	// for MaxBan start over
+	Ban    time.Duration
+	MaxBan time.Duration
+	// Called with each new ban (ex: to report it). Must not block
+	OnBan func(Ban)
+	// Nil uses the real one
+	Clock clock.Clock
+}
+
+// Ban is a caller being turned away for failing to authenticate too often
+type Ban struct {
+	Kind BanKind
+	// An IP address or SPKI pin
+	Key   string
+	Until time.Time
+	// How many times the caller has been banned, this one included
+	Count int
+}
+
+// FailureTracker bans callers that keep failing to authenticate, by
+// address and by the certificate they presented (see Authenticator.TrackFailures)
+type FailureTracker struct {
+	policy BanPolicy
+	clock  clock.Clock
+
+	lock      sync.Mutex
+	callers   map[trackedKey]*trackedCaller
+	lastSweep time.Time
+
+	failures *prometheus.CounterVec
+	bans     *prometheus.CounterVec
+	rejected prometheus.Counter
+}
+
+type trackedKey struct {
+	kind BanKind
+	key  string
+}
+
+type trackedCaller struct {
+	// Within the policy's window, oldest first
+	failures    []time.Time
+	bannedUntil time.Time
+	bans        int
+}
+
+func NewFailureTracker(policy BanPolicy) *FailureTracker {
+	t := &FailureTracker{
+		policy:  policy,
+		clock:   clock.Or(policy.Clock),
+		callers: map[trackedKey]*trackedCaller{},
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobby_auth_failures_total",
+			Help: "Requests that failed to authenticate, by gRPC status code",
+		}, []string{"code"}),
+		bans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobby_auth_bans_total",
+			Help: "Callers banned for failing to authenticate, by what they were banned by",
+		}, []string{"kind"}),
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "jobby_auth_banned_requests_total",
+			Help: "Requests turned away because their caller was banned",
+		}),
+	}
+	t.lastSweep = t.clock.Now()
+	return t
+}
+
+// Collectors are the tracker's metrics, for registering with Prometheus
+func (t *FailureTracker) Collectors() []prometheus.Collector {
+	active := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "jobby_auth_active_bans",
+		Help: "Callers currently banned for failing to authenticate",
+	}, func() float64 {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		now := t.clock.Now()
+		var count int
+		for _, caller := range t.callers {
+			if now.Before(caller.bannedUntil) {
+				count++
+			}
+		}
+		return float64(count)
+	})
+	return []prometheus.Collector{t.failures, t.bans, t.rejected, active}
+}
+
+// Banned reports whether any of the keys is banned
+func (t *FailureTracker) banned(keys []trackedKey) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.clock.Now()
+	for _, key := range keys {
+		if caller, ok := t.callers[key]; ok && now.Before(caller.bannedUntil) {
+			t.rejected.Inc()
+			return true
+		}
+	}
+	return false
+}
+
+func (t *FailureTracker) fail(code codes.Code, keys []trackedKey) {
+	t.failures.WithLabelValues(code.String()).Inc()
+	var bans []Ban
+	t.lock.Lock()
+	now := t.clock.Now()
+	t.sweep(now)
+	for _, key := range keys {
+		caller, ok := t.callers[key]
+		if !ok {
+			caller = &trackedCaller{}
+			t.callers[key] = caller
+		}
+		caller.failures = append(t.recent(caller.failures, now), now)
+		if len(caller.failures) < t.policy.MaxFailures || now.Before(caller.bannedUntil) {
+			continue
+		}
+		caller.bans++
+		ban := t.policy.Ban << min(caller.bans-1, 30)
+		if ban <= 0 || ban > t.policy.MaxBan {
+			ban = t.policy.MaxBan
+		}
+		caller.bannedUntil = now.Add(ban)
+		caller.failures = nil
+		t.bans.WithLabelValues(string(key.kind)).Inc()
+		bans = append(bans, Ban{Kind: key.kind, Key: key.key, Until: caller.bannedUntil, Count: caller.bans})
+	}
+	t.lock.Unlock()
+	if t.policy.OnBan != nil {
+		for _, ban := range bans {
+			t.policy.OnBan(ban)
+		}
+	}
+}
+
+// Failures still within the window
+func (t *FailureTracker) recent(failures []time.Time, now time.Time) []time.Time {
+	for len(failures) > 0 && now.Sub(failures[0]) >= t.policy.Window {
+		failures = failures[1:]
+	}
+	return failures
+}
+
+// Forgets callers that have stayed out of trouble for a while, so probing
+// from many addresses doesn't pile up. Called with the lock held
+func (t *FailureTracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.policy.Window {
+		return
+	}
+	t.lastSweep = now
+	for key, caller := range t.callers {
+		caller.failures = t.recent(caller.failures, now)
+		if len(caller.failures) == 0 && now.Sub(caller.bannedUntil) >= t.policy.MaxBan {
+			delete(t.callers, key)
+		}
+	}
+}
+
+// TrackFailures bans callers that fail to authenticate too often, as
+// 'tracker' decides. Banned callers are turned away before anything else
+// is checked
+func (a *Authenticator) TrackFailures(tracker *FailureTracker) {
+	a.failures = tracker
+}
+
+// Keys of whatever the caller can be banned by. Trusted proxies aren't
+// banned, as that would lock out everyone behind them. Callers they relay
+// are banned by the certificate forwarded for them, and by their address
+// when a load balancer reported it (see listeners.ProxiedAddr)
+func (a *Authenticator) callerKeys(ctx context.Context) []trackedKey {
+	peerInfo, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	var address, proxiedAddress []trackedKey
+	// Unix socket callers all look the same, so aren't banned by address
+	switch addr := peerInfo.Addr.(type) {
+	case *net.TCPAddr:
+		address = []trackedKey{addressKey(addr)}
+	case listeners.ProxiedAddr:
+		address = []trackedKey{addressKey(addr.TCPAddr)}
+		proxiedAddress = address
+	}
+	tls, ok := peerInfo.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tls.State.PeerCertificates) != 1 {
+		return address
+	}
+	cert := tls.State.PeerCertificates[0]
+	if user, err := a.identity.userFromCert(cert); err != nil || !a.proxies[user] {
+		return append(address, trackedKey{BanCertificate, SPKIPin(cert)})
+	}
+	// Relaying someone else's request. Malformed headers are caught later
+	if forwarded, _ := unverifiedForwardedCert(ctx); forwarded != nil {
+		return append(proxiedAddress, trackedKey{BanCertificate, SPKIPin(forwarded)})
+	}
+	return proxiedAddress
+}
+
+func addressKey(addr *net.TCPAddr) trackedKey {
+	return trackedKey{BanAddress, addr.AddrPort().Addr().Unmap().String()}
+}
+
+// Wraps getUser with the failure tracker, if there is one
+func (a *Authenticator) authenticate(ctx context.Context, method string) (string, *x509.Certificate, error) {
+	if a.failures == nil {
+		return a.getUser(ctx, method)
+	}
+	keys := a.callerKeys(ctx)
+	if a.failures.banned(keys) {
+		return "", nil, status.Error(codes.ResourceExhausted, "Too many failed authentication attempts. Try again later")
+	}
+	user, forwarded, err := a.getUser(ctx, method)
+	if code := status.Code(err); code == codes.Unauthenticated || code == codes.PermissionDenied {
+		a.failures.fail(code, keys)
+	}
+	return user, forwarded, err
+}
@@ -0,0 +1,105 @@
+package authinterceptors
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Header proxies that terminate the client's TLS pass its certificate on
+// in (ex: Envoy's forward_client_cert_details with the certificate set)
+const ForwardedClientCertHeader = "x-forwarded-client-cert"
+
+type forwardedCertKey struct{}
+
+// AcceptForwardedCerts identifies callers by the certificate in the
+// x-forwarded-client-cert header on connections from 'proxies', users
+// identified by their own certificate as usual. The proxies have already
+// verified it. When 'roots' isn't nil it must also chain up to one of them
+func (a *Authenticator) AcceptForwardedCerts(proxies []string, roots *x509.CertPool) {
+	a.proxies = make(map[string]bool, len(proxies))
+	for _, proxy := range proxies {
+		a.proxies[proxy] = true
+	}
+	a.forwardedRoots = roots
+}
+
+// The certificate a trusted proxy forwarded. Nil when the header is
+// missing, as it is for clients that didn't present one
+func (a *Authenticator) forwardedCert(ctx context.Context) (*x509.Certificate, error) {
+	cert, err := unverifiedForwardedCert(ctx)
+	if err != nil || cert == nil {
+		return nil, err
+	}
+	if a.forwardedRoots != nil {
+		_, err := cert.Verify(x509.VerifyOptions{
+			Roots:     a.forwardedRoots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cert, nil
+}
+
+// The forwarded certificate as the proxy sent it, without checking it
+// against the roots
+func unverifiedForwardedCert(ctx context.Context) (*x509.Certificate, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(ForwardedClientCertHeader)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return parseForwardedCert(strings.Join(values, ","))
+}
+
+// Each proxy along the way adds an element (ex: 'By=...;Cert="...";URI=...'),
+// so the last one is from the proxy that connected to us
+func parseForwardedCert(header string) (*x509.Certificate, error) {
+	elements := splitQuoted(header, ',')
+	for _, pair := range splitQuoted(elements[len(elements)-1], ';') {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if !strings.EqualFold(key, "Cert") {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		// URL encoded PEM. PathUnescape leaves base64's '+' alone
+		decoded, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("decoding forwarded certificate: %w", err)
+		}
+		block, _ := pem.Decode([]byte(decoded))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return nil, errors.New("forwarded certificate isn't PEM encoded")
+		}
+		return x509.ParseCertificate(block.Bytes)
+	}
+	return nil, errors.New("no certificate in " + ForwardedClientCertHeader)
+}
+
+// Splits 's' at each 'sep' outside of double quotes
+func splitQuoted(s string, sep rune) []string {
+	var parts []string
+	quoted, escaped, start := false, false, 0
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,103 @@
+package authinterceptors
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Determines which part of the client certificate identifies the user
+type IdentityMode string
+
+const (
+	// Subject common name. The original (and default) behavior
+	IdentityCommonName IdentityMode = "cn"
+	// URI SAN, e.g. a SPIFFE ID like 'spiffe://example.org/user/ryan'
+	IdentityURI IdentityMode = "uri"
+	// Email address SAN. Certificates with more than one are rejected
+	IdentityEmail IdentityMode = "email"
+)
+
+type IdentityConfig struct {
+	Mode IdentityMode
+	// Only used in URI mode. When set, exactly one URI SAN must start
+	// with this prefix, and the user is whatever follows it.
+	// When empty, the full URI is used as the user
+	URIPrefix string
+	// Use the common name when the certificate does not carry
+	// the SAN the mode is looking for. Eases migration of older certs
+	FallbackToCN bool
+}
+
+var errNoIdentity = errors.New("certificate does not contain a usable identity")
+
+func (c IdentityConfig) Validate() error {
+	switch c.Mode {
+	case "", IdentityCommonName, IdentityURI, IdentityEmail:
+		return nil
+	default:
+		return fmt.Errorf("unknown identity mode '%s'", c.Mode)
+	}
+}
+
+// Extract the user identity from a client certificate
+func (c IdentityConfig) userFromCert(cert *x509.Certificate) (string, error) {
+	var user string
+	var err error
+	switch c.Mode {
+	case IdentityURI:
+		user, err = c.userFromURI(cert)
+	case IdentityEmail:
+		user, err = userFromEmail(cert)
+	default:
+		return userFromCN(cert)
+	}
+
+	if errors.Is(err, errNoIdentity) && c.FallbackToCN {
+		return userFromCN(cert)
+	}
+	return user, err
+}
+
+func userFromCN(cert *x509.Certificate) (string, error) {
+	if cert.Subject.CommonName == "" {
+		return "", errNoIdentity
+	}
+	return cert.Subject.CommonName, nil
+}
+
+func (c IdentityConfig) userFromURI(cert *x509.Certificate) (string, error) {
+	var matches []string
+	for _, uri := range cert.URIs {
+		s := uri.String()
+		if strings.HasPrefix(s, c.URIPrefix) {
+			matches = append(matches, strings.TrimPrefix(s, c.URIPrefix))
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", errNoIdentity
+	case 1:
+		if matches[0] == "" {
+			return "", errNoIdentity
+		}
+		return matches[0], nil
+	default:
+		// Same rule as SPIFFE: a certificate identifies exactly one workload
+		return "", errors.New("certificate contains more than one matching URI SAN")
+	}
+}
+
+func userFromEmail(cert *x509.Certificate) (string, error) {
+	switch len(cert.EmailAddresses) {
+	case 0:
+		return "", errNoIdentity
+	case 1:
+		return cert.EmailAddresses[0], nil
+	default:
+		// As with URIs, picking one would let the order of SANs decide who the caller is
+		return "", errors.New("certificate contains more than one email SAN")
+	}
+}
@@ -2,6 +2,8 @@ package authinterceptors
 
 import (
 	"context"
+	"crypto/x509"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -22,36 +24,150 @@ func WithUser(ctx context.Context, user string) context.Context {
 	return context.WithValue(ctx, userValue, user)
 }
 
+// The user of callers without a client certificate, on methods that allow
+// them (see NewAuthenticator). Certificates never identify a user this way
+const AnonymousUser = ""
+
+// TokenVerifier identifies callers by a token they present instead of a
+// certificate (see AcceptTokens)
+type TokenVerifier interface {
+	// The user the caller's token acts for, if it may call 'method'.
+	// Empty with no error if the caller didn't present a token
+	VerifyToken(ctx context.Context, method string) (string, error)
+}
+
+// Authenticator determines the calling user from the client certificate
+// and stores it in the request context
+type Authenticator struct {
+	identity IdentityConfig
+	// Full names of the methods (ex: "/jobby.JobManager/GetStatus")
+	// callers without a certificate may call as AnonymousUser
+	anonymous map[string]bool
+	// Nil unless tokens are accepted
+	tokens TokenVerifier
+	// Users that are proxies forwarding client certificates (see AcceptForwardedCerts)
+	proxies        map[string]bool
+	forwardedRoots *x509.CertPool
+	// Nil when every certificate the CA signed is accepted
+	allowlist *ClientAllowlist
+	// Nil unless callers are banned for failing to authenticate
+	failures *FailureTracker
+}
+
+// The package level interceptors use the common name
+var defaultAuthenticator = &Authenticator{}
+
+// NewAuthenticator identifies users as 'identity' says. Callers without a
+// certificate are turned away, except from 'anonymousMethods'. The TLS
+// config must still let them connect (ex: tls.VerifyClientCertIfGiven)
+func NewAuthenticator(identity IdentityConfig, anonymousMethods ...string) (*Authenticator, error) {
+	if err := identity.Validate(); err != nil {
+		return nil, err
+	}
+	a := &Authenticator{identity: identity, anonymous: make(map[string]bool)}
+	for _, method := range anonymousMethods {
+		a.anonymous[method] = true
+	}
+	return a, nil
+}
+
+// AcceptTokens lets callers without a certificate identify with a token
+// 'verifier' accepts. The TLS config must still let them connect
+func (a *Authenticator) AcceptTokens(verifier TokenVerifier) {
+	a.tokens = verifier
+}
+
 // Dig into the context until we find the certificate
 // presented by the client. This function assumes that clients
-// will present exactly one certificate to the server
-func getUser(ctx context.Context) (string, error) {
+// will present exactly one certificate to the server. Returns the
+// certificate a trusted proxy forwarded, if it identified the user
+func (a *Authenticator) getUser(ctx context.Context, method string) (string, *x509.Certificate, error) {
 	peerInfo, ok := peer.FromContext(ctx)
 	if !ok {
-		return "", status.Error(codes.Unknown, "Could not determine peer info")
+		return "", nil, status.Error(codes.Unknown, "Could not determine peer info")
 	}
 
 	tls, ok := peerInfo.AuthInfo.(credentials.TLSInfo)
 	if !ok {
-		return "", status.Error(codes.Unauthenticated, "No TLS info")
+		return "", nil, status.Error(codes.Unauthenticated, "No TLS info")
 	}
 
+	if len(tls.State.PeerCertificates) == 0 {
+		user, err := a.getUserWithoutCert(ctx, method)
+		return user, nil, err
+	}
 	if len(tls.State.PeerCertificates) == 1 {
 		// huzzah!
-		user := tls.State.PeerCertificates[0].Subject.CommonName
-		return user, nil
+		if !a.isAllowed(tls.State.PeerCertificates[0]) {
+			return "", nil, status.Error(codes.PermissionDenied, "Client certificate is not allowed")
+		}
+		user, err := a.identity.userFromCert(tls.State.PeerCertificates[0])
+		if err != nil {
+			return "", nil, status.Error(codes.Unauthenticated, "Could not determine identity from client certificate")
+		}
+		if !a.proxies[user] {
+			return user, nil, nil
+		}
+		// Relaying someone else's request
+		forwarded, err := a.forwardedCert(ctx)
+		if err != nil {
+			return "", nil, status.Error(codes.Unauthenticated, "Invalid forwarded client certificate")
+		}
+		if forwarded == nil {
+			user, err := a.getUserWithoutCert(ctx, method)
+			return user, nil, err
+		}
+		if !a.isAllowed(forwarded) {
+			return "", nil, status.Error(codes.PermissionDenied, "Forwarded client certificate is not allowed")
+		}
+		if user, err = a.identity.userFromCert(forwarded); err != nil {
+			return "", nil, status.Error(codes.Unauthenticated, "Could not determine identity from forwarded client certificate")
+		}
+		return user, forwarded, nil
 	} else {
-		return "", status.Error(codes.Unauthenticated, "Client must present exactly one certificate")
+		return "", nil, status.Error(codes.Unauthenticated, "Client must present exactly one certificate")
 	}
 }
 
-func AuthHandlerUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-	user, err := getUser(ctx)
+func (a *Authenticator) isAllowed(cert *x509.Certificate) bool {
+	return a.allowlist == nil || a.allowlist.allows(cert)
+}
+
+// For callers with a token, or calling a method anyone may
+func (a *Authenticator) getUserWithoutCert(ctx context.Context, method string) (string, error) {
+	if a.tokens != nil {
+		user, err := a.tokens.VerifyToken(ctx, method)
+		if err != nil {
+			return "", status.Error(codes.Unauthenticated, "Token is invalid, expired or not allowed to call this method")
+		}
+		if user != "" {
+			return user, nil
+		}
+	}
+	if a.anonymous[method] {
+		return AnonymousUser, nil
+	}
+	return "", status.Error(codes.Unauthenticated, "Client must present exactly one certificate")
+}
+
+// The request's context, with the user and any forwarded certificate
+func withAuth(ctx context.Context, user string, forwarded *x509.Certificate) context.Context {
+	if forwarded != nil {
+		ctx = context.WithValue(ctx, forwardedCertKey{}, forwarded)
+	}
+	return WithUser(ctx, user)
+}
+
+func (a *Authenticator) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	var method string
+	if info != nil {
+		method = info.FullMethod
+	}
+	user, forwarded, err := a.authenticate(ctx, method)
 	if err != nil {
 		return nil, err
 	}
-	ctx = WithUser(ctx, user)
-	return handler(ctx, req)
+	return handler(withAuth(ctx, user, forwarded), req)
 }
 
 type replacementStream struct {
@@ -63,8 +179,12 @@ func (r *replacementStream) Context() context.Context {
 	return r.ctx
 }
 
-func AuthHandlerStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
-	user, err := getUser(stream.Context())
+func (a *Authenticator) StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	var method string
+	if info != nil {
+		method = info.FullMethod
+	}
+	user, forwarded, err := a.authenticate(stream.Context(), method)
 	if err != nil {
 		return err
 	}
@@ -72,6 +192,33 @@ func AuthHandlerStreamInterceptor(srv interface{}, stream grpc.ServerStream, inf
 	// but we can replace the server stream entirely with our own thin wrapper
 	return handler(srv, &replacementStream{
 		ServerStream: stream,
-		ctx:          WithUser(stream.Context(), user),
+		ctx:          withAuth(stream.Context(), user, forwarded),
 	})
 }
+
+// Identifies users by the common name of their certificate
+func AuthHandlerUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	return defaultAuthenticator.UnaryInterceptor(ctx, req, info, handler)
+}
+
+// Identifies users by the common name of their certificate
+func AuthHandlerStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	return defaultAuthenticator.StreamInterceptor(srv, stream, info, handler)
+}
+
+// GetCredentialExpiry returns when the caller's client certificate expires,
+// including one forwarded by a proxy. Zero for callers without one
+func GetCredentialExpiry(ctx context.Context) time.Time {
+	if forwarded, ok := ctx.Value(forwardedCertKey{}).(*x509.Certificate); ok {
+		return forwarded.NotAfter
+	}
+	peerInfo, ok := peer.FromContext(ctx)
+	if !ok {
+		return time.Time{}
+	}
+	tls, ok := peerInfo.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tls.State.PeerCertificates) == 0 {
+		return time.Time{}
+	}
+	return tls.State.PeerCertificates[0].NotAfter
+}
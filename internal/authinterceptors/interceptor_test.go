@@ -5,12 +5,23 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/gopheryan/jobby/internal/certgen"
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/listeners"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
@@ -80,3 +91,436 @@ func TestInterceptors(t *testing.T) {
 	})
 
 }
+
+func TestIdentityModes(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://jobby.local/user/alice")
+	otherURI, _ := url.Parse("https://example.com/whatever")
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "Ryan"},
+		URIs:           []*url.URL{otherURI, spiffeID},
+		EmailAddresses: []string{"bob@example.com"},
+	}
+	cnOnly := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "Ryan"},
+	}
+	twoEmails := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "Ryan"},
+		EmailAddresses: []string{"bob@example.com", "admin@example.com"},
+	}
+
+	tests := []struct {
+		name     string
+		identity IdentityConfig
+		cert     *x509.Certificate
+		user     string
+		fail     bool
+	}{
+		{name: "cn", identity: IdentityConfig{Mode: IdentityCommonName}, cert: cert, user: "Ryan"},
+		{name: "default-cn", identity: IdentityConfig{}, cert: cert, user: "Ryan"},
+		{name: "uri-prefix", identity: IdentityConfig{Mode: IdentityURI, URIPrefix: "spiffe://jobby.local/user/"}, cert: cert, user: "alice"},
+		{name: "uri-ambiguous", identity: IdentityConfig{Mode: IdentityURI}, cert: cert, fail: true},
+		{name: "uri-missing", identity: IdentityConfig{Mode: IdentityURI}, cert: cnOnly, fail: true},
+		{name: "uri-fallback", identity: IdentityConfig{Mode: IdentityURI, FallbackToCN: true}, cert: cnOnly, user: "Ryan"},
+		{name: "email", identity: IdentityConfig{Mode: IdentityEmail}, cert: cert, user: "bob@example.com"},
+		{name: "email-ambiguous", identity: IdentityConfig{Mode: IdentityEmail}, cert: twoEmails, fail: true},
+		{name: "email-ambiguous-no-fallback", identity: IdentityConfig{Mode: IdentityEmail, FallbackToCN: true}, cert: twoEmails, fail: true},
+		{name: "email-missing", identity: IdentityConfig{Mode: IdentityEmail}, cert: cnOnly, fail: true},
+		{name: "email-fallback", identity: IdentityConfig{Mode: IdentityEmail, FallbackToCN: true}, cert: cnOnly, user: "Ryan"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(tt *testing.T) {
+			auth, err := NewAuthenticator(tc.identity)
+			require.NoError(tt, err)
+
+			p := peer.Peer{
+				AuthInfo: credentials.TLSInfo{
+					State: tls.ConnectionState{
+						PeerCertificates: []*x509.Certificate{tc.cert},
+					},
+				},
+			}
+			ctx := peer.NewContext(context.Background(), &p)
+			_, err = auth.UnaryInterceptor(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+				assert.Equal(tt, tc.user, GetUserContext(ctx))
+				return nil, nil
+			})
+			if tc.fail {
+				s, ok := status.FromError(err)
+				require.True(tt, ok)
+				assert.Equal(tt, codes.Unauthenticated, s.Code())
+			} else {
+				assert.NoError(tt, err)
+			}
+		})
+	}
+
+	_, err := NewAuthenticator(IdentityConfig{Mode: "dn"})
+	assert.Error(t, err)
+}
+
+func TestAnonymousMethods(t *testing.T) {
+	auth, err := NewAuthenticator(IdentityConfig{}, "/jobby.JobManager/GetStatus")
+	require.NoError(t, err)
+	p := peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{},
+		},
+	}
+	ctx := peer.NewContext(context.Background(), &p)
+	handler := func(ctx context.Context, _ any) (any, error) {
+		assert.Equal(t, AnonymousUser, GetUserContext(ctx))
+		return nil, nil
+	}
+
+	t.Run("allowed", func(tt *testing.T) {
+		_, err := auth.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/GetStatus"}, handler)
+		assert.NoError(tt, err)
+
+		rs := &replacementStream{ctx: ctx}
+		err = auth.StreamInterceptor(nil, rs, &grpc.StreamServerInfo{FullMethod: "/jobby.JobManager/GetStatus"}, func(srv any, stream grpc.ServerStream) error {
+			assert.Equal(tt, AnonymousUser, GetUserContext(stream.Context()))
+			return nil
+		})
+		assert.NoError(tt, err)
+	})
+
+	t.Run("not allowed", func(tt *testing.T) {
+		_, err := auth.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/StopJob"}, handler)
+		assert.Equal(tt, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("certificate", func(tt *testing.T) {
+		// Callers with a certificate are who it says they are, even on anonymous methods
+		p := peer.Peer{
+			AuthInfo: credentials.TLSInfo{
+				State: tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "Ryan"}}},
+				},
+			},
+		}
+		ctx := peer.NewContext(context.Background(), &p)
+		_, err := auth.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/GetStatus"}, func(ctx context.Context, _ any) (any, error) {
+			assert.Equal(tt, "Ryan", GetUserContext(ctx))
+			return nil, nil
+		})
+		assert.NoError(tt, err)
+	})
+}
+
+type tokenVerifierFunc func(context.Context, string) (string, error)
+
+func (f tokenVerifierFunc) VerifyToken(ctx context.Context, method string) (string, error) {
+	return f(ctx, method)
+}
+
+func TestAcceptTokens(t *testing.T) {
+	auth, err := NewAuthenticator(IdentityConfig{})
+	require.NoError(t, err)
+	auth.AcceptTokens(tokenVerifierFunc(func(ctx context.Context, method string) (string, error) {
+		switch ctx.Value(tokenKey) {
+		case nil:
+			return "", nil
+		case "good":
+			if method == "/jobby.JobManager/StartJob" {
+				return "Ryan", nil
+			}
+		}
+		return "", errors.New("bad token")
+	}))
+	p := peer.Peer{AuthInfo: credentials.TLSInfo{}}
+	ctx := peer.NewContext(context.Background(), &p)
+	call := func(ctx context.Context, method string) (string, error) {
+		var user string
+		_, err := auth.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, _ any) (any, error) {
+			user = GetUserContext(ctx)
+			return nil, nil
+		})
+		return user, err
+	}
+
+	user, err := call(context.WithValue(ctx, tokenKey, "good"), "/jobby.JobManager/StartJob")
+	require.NoError(t, err)
+	assert.Equal(t, "Ryan", user)
+
+	_, err = call(context.WithValue(ctx, tokenKey, "good"), "/jobby.JobManager/StopJob")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = call(context.WithValue(ctx, tokenKey, "bad"), "/jobby.JobManager/StartJob")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Neither a certificate nor a token
+	_, err = call(ctx, "/jobby.JobManager/StartJob")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+type testTokenKey struct{}
+
+var tokenKey testTokenKey
+
+func TestGetCredentialExpiry(t *testing.T) {
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	p := peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "Ryan"}, NotAfter: expiry}},
+			},
+		},
+	}
+	assert.Equal(t, expiry, GetCredentialExpiry(peer.NewContext(context.Background(), &p)))
+
+	anonymous := peer.Peer{AuthInfo: credentials.TLSInfo{}}
+	assert.True(t, GetCredentialExpiry(peer.NewContext(context.Background(), &anonymous)).IsZero())
+	assert.True(t, GetCredentialExpiry(context.Background()).IsZero())
+}
+
+func TestAcceptForwardedCerts(t *testing.T) {
+	ca, err := certgen.NewCA("TestCA", time.Hour)
+	require.NoError(t, err)
+	alice, err := ca.IssueClient("alice", time.Hour)
+	require.NoError(t, err)
+	otherCA, err := certgen.NewCA("OtherCA", time.Hour)
+	require.NoError(t, err)
+	mallory, err := otherCA.IssueClient("mallory", time.Hour)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+
+	auth, err := NewAuthenticator(IdentityConfig{}, "/jobby.JobManager/GetServerInfo")
+	require.NoError(t, err)
+	auth.AcceptForwardedCerts([]string{"envoy"}, roots)
+	call := func(peerName string, method string, xfcc ...string) (string, time.Time, error) {
+		p := peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: peerName}}},
+		}}}
+		ctx := peer.NewContext(context.Background(), &p)
+		for _, header := range xfcc {
+			ctx = metadata.AppendToOutgoingContext(ctx, ForwardedClientCertHeader, header)
+		}
+		md, _ := metadata.FromOutgoingContext(ctx)
+		ctx = metadata.NewIncomingContext(ctx, md)
+		var user string
+		var expiry time.Time
+		_, err := auth.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, _ any) (any, error) {
+			user, expiry = GetUserContext(ctx), GetCredentialExpiry(ctx)
+			return nil, nil
+		})
+		return user, expiry, err
+	}
+	xfcc := func(cert *certgen.CertKey) string {
+		return `By=spiffe://jobby.local/envoy;Hash=abc;Cert="` + url.PathEscape(string(cert.CertPEM())) + `";Subject="CN=x,O=\"a;b\""`
+	}
+
+	user, expiry, err := call("envoy", "/jobby.JobManager/StartJob", xfcc(alice))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user)
+	assert.Equal(t, alice.Cert.NotAfter, expiry)
+
+	// The proxy nearest us adds the last element
+	user, _, err = call("envoy", "/jobby.JobManager/StartJob", xfcc(mallory)+","+xfcc(alice))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user)
+
+	// Not from our CA
+	_, _, err = call("envoy", "/jobby.JobManager/StartJob", xfcc(mallory))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, _, err = call("envoy", "/jobby.JobManager/StartJob", "By=spiffe://jobby.local/envoy;Hash=abc")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// No client certificate to forward
+	_, _, err = call("envoy", "/jobby.JobManager/StartJob")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	user, _, err = call("envoy", "/jobby.JobManager/GetServerInfo")
+	require.NoError(t, err)
+	assert.Equal(t, AnonymousUser, user)
+
+	// Only trusted proxies may forward certificates
+	user, _, err = call("bob", "/jobby.JobManager/StartJob", xfcc(alice))
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user)
+}
+
+func TestAllowOnly(t *testing.T) {
+	ca, err := certgen.NewCA("TestCA", time.Hour)
+	require.NoError(t, err)
+	issue := func(user string, sans ...string) *x509.Certificate {
+		cert, err := ca.IssueClient(user, time.Hour, sans...)
+		require.NoError(t, err)
+		return cert.Cert
+	}
+	alice := issue("alice")
+	bob := issue("bob", "spiffe://jobby.local/user/bob")
+	carol := issue("carol")
+	mallory := issue("mallory")
+
+	auth, err := NewAuthenticator(IdentityConfig{}, "/jobby.JobManager/GetServerInfo")
+	require.NoError(t, err)
+	assert.Error(t, auth.AllowOnly(ClientAllowlist{SPKIPins: []string{"not a pin"}}))
+	require.NoError(t, auth.AllowOnly(ClientAllowlist{
+		CommonNames: []string{"alice"},
+		URIs:        []string{"spiffe://jobby.local/user/bob"},
+		SPKIPins:    []string{SPKIPin(carol)},
+	}))
+	call := func(certs ...*x509.Certificate) (string, error) {
+		p := peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: certs}}}
+		var user string
+		_, err := auth.UnaryInterceptor(peer.NewContext(context.Background(), &p), nil, &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/GetServerInfo"}, func(ctx context.Context, _ any) (any, error) {
+			user = GetUserContext(ctx)
+			return nil, nil
+		})
+		return user, err
+	}
+
+	for _, cert := range []*x509.Certificate{alice, bob, carol} {
+		user, err := call(cert)
+		require.NoError(t, err)
+		assert.Equal(t, cert.Subject.CommonName, user)
+	}
+	_, err = call(mallory)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	// Callers without a certificate are up to the method
+	user, err := call()
+	require.NoError(t, err)
+	assert.Equal(t, AnonymousUser, user)
+}
+
+func TestTrackFailures(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	var bans []Ban
+	tracker := NewFailureTracker(BanPolicy{
+		MaxFailures: 3,
+		Window:      time.Minute,
+		Ban:         time.Minute,
+		MaxBan:      3 * time.Minute,
+		OnBan:       func(ban Ban) { bans = append(bans, ban) },
+		Clock:       fake,
+	})
+	ca, err := certgen.NewCA("TestCA", time.Hour)
+	require.NoError(t, err)
+	issue := func(user string) *x509.Certificate {
+		cert, err := ca.IssueClient(user, time.Hour)
+		require.NoError(t, err)
+		return cert.Cert
+	}
+	alice := issue("alice")
+	// Off the allowlist, but naming someone on it
+	mallory := issue("alice")
+	auth, err := NewAuthenticator(IdentityConfig{})
+	require.NoError(t, err)
+	require.NoError(t, auth.AllowOnly(ClientAllowlist{SPKIPins: []string{SPKIPin(alice)}}))
+	auth.TrackFailures(tracker)
+	call := func(address string, cert *x509.Certificate) error {
+		p := peer.Peer{
+			Addr: net.TCPAddrFromAddrPort(netip.MustParseAddrPort(address)),
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+			}},
+		}
+		_, err := auth.UnaryInterceptor(peer.NewContext(context.Background(), &p), nil, nil, func(ctx context.Context, _ any) (any, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	for range 2 {
+		assert.Equal(t, codes.PermissionDenied, status.Code(call("192.0.2.1:1000", mallory)))
+	}
+	// Failures only count within the window
+	fake.Advance(time.Minute)
+	for range 2 {
+		assert.Equal(t, codes.PermissionDenied, status.Code(call("192.0.2.1:1000", mallory)))
+	}
+	assert.Empty(t, bans)
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("192.0.2.1:1001", mallory)))
+	until := fake.Now().Add(time.Minute)
+	assert.Equal(t, []Ban{
+		{Kind: BanAddress, Key: "192.0.2.1", Until: until, Count: 1},
+		{Kind: BanCertificate, Key: SPKIPin(mallory), Until: until, Count: 1},
+	}, bans)
+
+	// Even with a good certificate, from the banned address
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("192.0.2.1:1002", alice)))
+	// Or with the banned certificate, from elsewhere
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("198.51.100.1:1000", mallory)))
+	// The user the rejected certificate named isn't banned
+	assert.NoError(t, call("198.51.100.1:1000", alice))
+
+	fake.Advance(time.Minute)
+	assert.NoError(t, call("192.0.2.1:1002", alice))
+	// The next ban is twice as long
+	for range 3 {
+		assert.Equal(t, codes.PermissionDenied, status.Code(call("192.0.2.1:1000", mallory)))
+	}
+	require.Len(t, bans, 4)
+	assert.Equal(t, Ban{Kind: BanAddress, Key: "192.0.2.1", Until: fake.Now().Add(2 * time.Minute), Count: 2}, bans[2])
+
+	collectors := tracker.Collectors()
+	assert.Equal(t, float64(8), testutil.ToFloat64(collectors[0]))
+	assert.Equal(t, float64(2), testutil.ToFloat64(tracker.bans.WithLabelValues(string(BanAddress))))
+	assert.Equal(t, float64(2), testutil.ToFloat64(collectors[2]))
+	assert.Equal(t, float64(2), testutil.ToFloat64(collectors[3]))
+}
+
+func TestTrackProxiedFailures(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	var bans []Ban
+	tracker := NewFailureTracker(BanPolicy{
+		MaxFailures: 2,
+		Window:      time.Minute,
+		Ban:         time.Minute,
+		MaxBan:      time.Minute,
+		OnBan:       func(ban Ban) { bans = append(bans, ban) },
+		Clock:       fake,
+	})
+	ca, err := certgen.NewCA("TestCA", time.Hour)
+	require.NoError(t, err)
+	alice, err := ca.IssueClient("alice", time.Hour)
+	require.NoError(t, err)
+	mallory, err := ca.IssueClient("mallory", time.Hour)
+	require.NoError(t, err)
+	auth, err := NewAuthenticator(IdentityConfig{})
+	require.NoError(t, err)
+	auth.AcceptForwardedCerts([]string{"envoy"}, nil)
+	require.NoError(t, auth.AllowOnly(ClientAllowlist{CommonNames: []string{"envoy", "alice"}}))
+	auth.TrackFailures(tracker)
+	call := func(addr net.Addr, forwarded *certgen.CertKey) error {
+		p := peer.Peer{
+			Addr: addr,
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "envoy"}}},
+			}},
+		}
+		md := metadata.MD{}
+		if forwarded != nil {
+			md.Set(ForwardedClientCertHeader, `Cert="`+url.PathEscape(string(forwarded.CertPEM()))+`"`)
+		}
+		ctx := metadata.NewIncomingContext(peer.NewContext(context.Background(), &p), md)
+		_, err := auth.UnaryInterceptor(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+			return nil, nil
+		})
+		return err
+	}
+	proxy := net.TCPAddrFromAddrPort(netip.MustParseAddrPort("192.0.2.1:1000"))
+	reported := func(address string) net.Addr {
+		return listeners.ProxiedAddr{TCPAddr: net.TCPAddrFromAddrPort(netip.MustParseAddrPort(address))}
+	}
+
+	// Banned by the certificate forwarded, never the proxy's own address
+	for range 2 {
+		assert.Equal(t, codes.PermissionDenied, status.Code(call(proxy, mallory)))
+	}
+	assert.Equal(t, []Ban{{Kind: BanCertificate, Key: SPKIPin(mallory.Cert), Until: fake.Now().Add(time.Minute), Count: 1}}, bans)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call(proxy, mallory)))
+	assert.NoError(t, call(proxy, alice))
+
+	// And by the address a load balancer reported for them
+	for range 2 {
+		assert.Equal(t, codes.Unauthenticated, status.Code(call(reported("198.51.100.1:1000"), nil)))
+	}
+	require.Len(t, bans, 2)
+	assert.Equal(t, Ban{Kind: BanAddress, Key: "198.51.100.1", Until: fake.Now().Add(time.Minute), Count: 1}, bans[1])
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call(reported("198.51.100.1:1001"), alice)))
+	assert.NoError(t, call(reported("198.51.100.2:1000"), alice))
+	assert.NoError(t, call(proxy, alice))
+}
@@ -0,0 +1,71 @@
+// Package buildinfo describes what the running binary was built from. Release
+// builds stamp their version in with ldflags (see the Makefile):
+//
+//	go build -ldflags "-X github.com/gopheryan/jobby/internal/buildinfo.version=v1.2.0" ./cmd/server
+//
+// Everything else comes from the VCS details Go embeds in binaries built
+// inside a checkout, unless it's stamped in the same way
+package buildinfo
+
+import (
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Stamped in with -X. Empty unless they were
+var (
+	version    string
+	commit     string
+	commitTime string
+)
+
+// Version of builds that weren't stamped with one, same as 'go version -m'
+const develVersion = "(devel)"
+
+type Info struct {
+	Version string
+	// Empty if unknown
+	Commit string
+	// Zero if unknown
+	CommitTime time.Time
+	// Built from a tree with uncommitted changes
+	Modified  bool
+	GoVersion string
+}
+
+// Get describes the running binary
+var Get = sync.OnceValue(func() Info {
+	info := Info{Version: develVersion}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = build.GoVersion
+		// Binaries installed with 'go install module@version' know their version
+		if build.Main.Version != "" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				info.CommitTime, _ = time.Parse(time.RFC3339, setting.Value)
+			case "vcs.modified":
+				info.Modified, _ = strconv.ParseBool(setting.Value)
+			}
+		}
+	}
+	// Stamped values win over what Go recorded
+	if version != "" {
+		info.Version = version
+	}
+	if commit != "" {
+		info.Commit = commit
+	}
+	if commitTime != "" {
+		if t, err := time.Parse(time.RFC3339, commitTime); err == nil {
+			info.CommitTime = t
+		}
+	}
+	return info
+})
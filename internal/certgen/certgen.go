@@ -0,0 +1,212 @@
+package certgen
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Matches the expiry used by the old openssl recipes in the Makefile
+const DefaultValidity = 365 * 24 * time.Hour
+
+// Certificates are backdated slightly so that a freshly generated
+// cert is still valid on a host whose clock lags a little behind ours
+const clockSkewAllowance = 5 * time.Minute
+
+// CertKey is a certificate along with the private key it was issued for
+type CertKey struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+}
+
+// NewCA creates a self-signed certificate authority that can be used
+// to sign server and client certificates
+func NewCA(commonName string, validity time.Duration) (*CertKey, error) {
+	template, err := newTemplate(commonName, validity)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.MaxPathLenZero = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	return issue(template, nil)
+}
+
+// IssueServer creates a server certificate signed by the CA.
+// Each host is added as either an IP or DNS SAN depending on its form.
+// The first host doubles as the certificate's common name
+func (ca *CertKey) IssueServer(hosts []string, validity time.Duration) (*CertKey, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("server certificate requires at least one host")
+	}
+
+	template, err := newTemplate(hosts[0], validity)
+	if err != nil {
+		return nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	return issue(template, ca)
+}
+
+// IssueClient creates a client certificate for the given user signed by the CA.
+// The user name is stored in the certificate's common name. Optional SANs
+// are added as URIs (e.g. 'spiffe://example.org/user/ryan') or email
+// addresses depending on their form
+func (ca *CertKey) IssueClient(user string, validity time.Duration, sans ...string) (*CertKey, error) {
+	if user == "" {
+		return nil, errors.New("client certificate requires a user name")
+	}
+
+	template, err := newTemplate(user, validity)
+	if err != nil {
+		return nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	for _, san := range sans {
+		if strings.Contains(san, "://") {
+			uri, err := url.Parse(san)
+			if err != nil {
+				return nil, fmt.Errorf("invalid URI SAN '%s': %w", san, err)
+			}
+			template.URIs = append(template.URIs, uri)
+		} else if strings.Contains(san, "@") {
+			template.EmailAddresses = append(template.EmailAddresses, san)
+		} else {
+			return nil, fmt.Errorf("unsupported client SAN '%s'", san)
+		}
+	}
+
+	return issue(template, ca)
+}
+
+// PEM encoded certificate
+func (c *CertKey) CertPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Cert.Raw})
+}
+
+// PEM encoded private key. Uses the same SEC1 format as 'openssl ecparam -genkey'
+func (c *CertKey) KeyPEM() ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// TLSCertificate converts the pair into a form usable by crypto/tls
+func (c *CertKey) TLSCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{c.Cert.Raw},
+		PrivateKey:  c.Key,
+		Leaf:        c.Cert,
+	}
+}
+
+// Write the certificate and key to disk, creating parent directories as needed.
+// Keys are only readable by the current user
+func (c *CertKey) WriteFiles(certPath, keyPath string) error {
+	keyData, err := c.KeyPEM()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{certPath, keyPath} {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return fmt.Errorf("error creating directory for '%s': %w", path, err)
+		}
+	}
+
+	if err := os.WriteFile(certPath, c.CertPEM(), 0644); err != nil {
+		return fmt.Errorf("error writing certificate: %w", err)
+	}
+	if err := os.WriteFile(keyPath, keyData, 0600); err != nil {
+		return fmt.Errorf("error writing private key: %w", err)
+	}
+	return nil
+}
+
+// Load a PEM encoded certificate and EC private key from disk
+func Load(certPath, keyPath string) (*CertKey, error) {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading cert/key: %w", err)
+	}
+
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", pair.PrivateKey)
+	}
+
+	return &CertKey{Cert: pair.Leaf, Key: key}, nil
+}
+
+func newTemplate(commonName string, validity time.Duration) (*x509.Certificate, error) {
+	if validity <= 0 {
+		validity = DefaultValidity
+	}
+
+	// 20 bytes is the maximum serial length allowed by RFC 5280
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 159))
+	if err != nil {
+		return nil, fmt.Errorf("error generating serial number: %w", err)
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-clockSkewAllowance),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+// Generate a fresh key and sign the template with the issuer.
+// A nil issuer produces a self-signed certificate
+func issue(template *x509.Certificate, issuer *CertKey) (*CertKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generating private key: %w", err)
+	}
+
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.Cert, issuer.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, fmt.Errorf("error creating certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing generated certificate: %w", err)
+	}
+
+	return &CertKey{Cert: cert, Key: key}, nil
+}
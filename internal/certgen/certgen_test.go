@@ -0,0 +1,130 @@
+package certgen_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/certgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Generated certs should chain back to the CA and be usable
+// for an actual mutual TLS handshake
+func TestMutualTLS(t *testing.T) {
+	ca, err := certgen.NewCA("TestCA", time.Hour)
+	require.NoError(t, err)
+
+	server, err := ca.IssueServer([]string{"localhost", "127.0.0.1"}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"localhost"}, server.Cert.DNSNames)
+	require.Len(t, server.Cert.IPAddresses, 1)
+	assert.True(t, server.Cert.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")))
+
+	client, err := ca.IssueClient("someuser", time.Hour, "spiffe://jobby.local/user/someuser", "someuser@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "someuser", client.Cert.Subject.CommonName)
+	require.Len(t, client.Cert.URIs, 1)
+	assert.Equal(t, "spiffe://jobby.local/user/someuser", client.Cert.URIs[0].String())
+	assert.Equal(t, []string{"someuser@example.com"}, client.Cert.EmailAddresses)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	srv := tls.Server(serverConn, &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{server.TLSCertificate()},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	cli := tls.Client(clientConn, &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{client.TLSCertificate()},
+		RootCAs:      pool,
+		ServerName:   "localhost",
+	})
+
+	srvErr := make(chan error)
+	go func() {
+		srvErr <- srv.Handshake()
+	}()
+	require.NoError(t, cli.Handshake())
+	require.NoError(t, <-srvErr)
+
+	peerCerts := srv.ConnectionState().PeerCertificates
+	require.Len(t, peerCerts, 1)
+	assert.Equal(t, "someuser", peerCerts[0].Subject.CommonName)
+}
+
+func TestGenerateTree(t *testing.T) {
+	layout := certgen.Layout{Dir: t.TempDir()}
+	require.NoError(t, certgen.GenerateTree(layout, certgen.TreeOptions{
+		Hosts: []string{"localhost"},
+		Users: []string{"alice", "carol@example.com"},
+	}))
+
+	for _, path := range []string{
+		layout.CACert(), layout.CAKey(),
+		layout.ServerCert(), layout.ServerKey(),
+		layout.ClientCert("alice"), layout.ClientKey("alice"),
+	} {
+		_, err := os.Stat(path)
+		assert.NoError(t, err, path)
+	}
+
+	ca, err := certgen.Load(layout.CACert(), layout.CAKey())
+	require.NoError(t, err)
+
+	// Adding a user should reuse the existing CA so alice's
+	// certificate remains valid
+	require.NoError(t, certgen.GenerateTree(layout, certgen.TreeOptions{
+		Users:      []string{"bob"},
+		ReuseCA:    true,
+		SkipServer: true,
+	}))
+
+	reloaded, err := certgen.Load(layout.CACert(), layout.CAKey())
+	require.NoError(t, err)
+	assert.True(t, ca.Cert.Equal(reloaded.Cert))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	for _, user := range []string{"alice", "bob"} {
+		client, err := certgen.Load(layout.ClientCert(user), layout.ClientKey(user))
+		require.NoError(t, err)
+		_, err = client.Cert.Verify(x509.VerifyOptions{
+			Roots:     pool,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		assert.NoError(t, err, user)
+	}
+
+	// Whichever identity mode the server uses finds the user
+	for user, want := range map[string][2]string{
+		"alice":             {"spiffe://jobby.local/user/alice", "alice@jobby.local"},
+		"bob":               {"spiffe://jobby.local/user/bob", "bob@jobby.local"},
+		"carol@example.com": {"spiffe://jobby.local/user/carol@example.com", "carol@example.com"},
+	} {
+		client, err := certgen.Load(layout.ClientCert(user), layout.ClientKey(user))
+		require.NoError(t, err)
+		assert.Equal(t, user, client.Cert.Subject.CommonName)
+		require.Len(t, client.Cert.URIs, 1, user)
+		assert.Equal(t, want[0], client.Cert.URIs[0].String())
+		assert.Equal(t, []string{want[1]}, client.Cert.EmailAddresses)
+	}
+}
+
+func TestServerRequiresHost(t *testing.T) {
+	ca, err := certgen.NewCA("TestCA", time.Hour)
+	require.NoError(t, err)
+	_, err = ca.IssueServer(nil, time.Hour)
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package certgen
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const caCommonName = "JobbyRootCA"
+
+// Where client certificates' SANs point unless TreeOptions say otherwise.
+// Each certificate carries one of each, so the user is found whichever
+// identity mode the server uses
+const (
+	DefaultClientURIPrefix   = "spiffe://jobby.local/user/"
+	DefaultClientEmailDomain = "jobby.local"
+)
+
+// Layout describes where certificates live relative to a base directory.
+// This matches the structure the server and jobcli expect in their
+// working directory (see testdata/certs)
+type Layout struct {
+	Dir string
+}
+
+func (l Layout) CACert() string {
+	return filepath.Join(l.Dir, "ca", "ca.crt")
+}
+
+func (l Layout) CAKey() string {
+	return filepath.Join(l.Dir, "ca", "ca.key")
+}
+
+func (l Layout) ServerCert() string {
+	return filepath.Join(l.Dir, "server", "server.crt")
+}
+
+func (l Layout) ServerKey() string {
+	return filepath.Join(l.Dir, "server", "server.key")
+}
+
+func (l Layout) ClientCert(user string) string {
+	return filepath.Join(l.Dir, "client", user, "client.crt")
+}
+
+func (l Layout) ClientKey(user string) string {
+	return filepath.Join(l.Dir, "client", user, "client.key")
+}
+
+type TreeOptions struct {
+	// Hosts added to the server certificate's SANs
+	Hosts []string
+	// A client certificate is generated for each user
+	Users []string
+	// Client certificates carry a URI SAN of this prefix followed by the
+	// user name. DefaultClientURIPrefix if empty
+	ClientURIPrefix string
+	// Client certificates carry an email SAN of the user at this domain,
+	// or the user name itself if it's already an address.
+	// DefaultClientEmailDomain if empty
+	ClientEmailDomain string
+	// Expiry applied to every generated certificate
+	Validity time.Duration
+	// When set, an existing CA found in the layout is used to sign
+	// new certificates rather than generating a new one. Useful for
+	// adding users without invalidating everybody else's certs
+	ReuseCA bool
+	// Skip (re)generating the server certificate
+	SkipServer bool
+}
+
+// GenerateTree writes a CA, server cert, and client certs to the layout.
+// Existing files are overwritten
+func GenerateTree(layout Layout, opts TreeOptions) error {
+	ca, err := loadOrCreateCA(layout, opts)
+	if err != nil {
+		return err
+	}
+
+	if !opts.SkipServer {
+		server, err := ca.IssueServer(opts.Hosts, opts.Validity)
+		if err != nil {
+			return fmt.Errorf("error issuing server certificate: %w", err)
+		}
+		if err := server.WriteFiles(layout.ServerCert(), layout.ServerKey()); err != nil {
+			return err
+		}
+	}
+
+	for _, user := range opts.Users {
+		client, err := ca.IssueClient(user, opts.Validity, clientSANs(user, opts)...)
+		if err != nil {
+			return fmt.Errorf("error issuing client certificate for '%s': %w", user, err)
+		}
+		if err := client.WriteFiles(layout.ClientCert(user), layout.ClientKey(user)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func clientSANs(user string, opts TreeOptions) []string {
+	uriPrefix := cmp.Or(opts.ClientURIPrefix, DefaultClientURIPrefix)
+	email := user
+	if !strings.Contains(user, "@") {
+		email = user + "@" + cmp.Or(opts.ClientEmailDomain, DefaultClientEmailDomain)
+	}
+	return []string{uriPrefix + url.PathEscape(user), email}
+}
+
+func loadOrCreateCA(layout Layout, opts TreeOptions) (*CertKey, error) {
+	if opts.ReuseCA {
+		ca, err := Load(layout.CACert(), layout.CAKey())
+		if err == nil {
+			return ca, nil
+		}
+		// Only fall through to creating a new CA if there wasn't one
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("error loading existing CA: %w", err)
+		}
+	}
+
+	ca, err := NewCA(caCommonName, opts.Validity)
+	if err != nil {
+		return nil, fmt.Errorf("error creating CA: %w", err)
+	}
+	if err := ca.WriteFiles(layout.CACert(), layout.CAKey()); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
@@ -0,0 +1,74 @@
+// Package clock lets time be swapped out. Jobs and the service read the
+// time and wait on timers through a Clock, so tests can use a Fake and move
+// time along themselves instead of sleeping through it
+package clock
+
+import (
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	// Time elapsed since 't', which should come from Now
+	Since(t time.Time) time.Duration
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the part of time.Timer we use
+type Timer interface {
+	C() <-chan time.Time
+	// Whether the timer was stopped before it fired
+	Stop() bool
+}
+
+// Ticker is the part of time.Ticker we use
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+// Or returns 'c', or Real if it's nil
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,148 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to (see Advance). Timers and
+// tickers fire from Advance, as if that much time had passed at once
+type Fake struct {
+	lock sync.Mutex
+	now  time.Time
+	// Timers and tickers that haven't been stopped or fired for good
+	waiting []*fakeTimer
+	// Closed and replaced whenever a timer or ticker is made
+	added chan struct{}
+}
+
+// NewFake starts a fake clock at 'now'
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, added: make(chan struct{})}
+}
+
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.newTimer(d, 0)
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.newTimer(d, d)}
+}
+
+func (f *Fake) newTimer(d time.Duration, period time.Duration) *fakeTimer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	// Buffered like the real ones, so firing never blocks on the receiver
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1), when: f.now.Add(d), period: period}
+	if d <= 0 {
+		// Only timers get here. Tickers need a positive interval
+		t.c <- f.now
+		return t
+	}
+	f.waiting = append(f.waiting, t)
+	close(f.added)
+	f.added = make(chan struct{})
+	return t
+}
+
+// Advance moves the clock forward by 'd', firing the timers and tickers
+// due by then. Tickers that fell behind fire once, with the time of the
+// latest tick, as real ones drop ticks for slow receivers
+func (f *Fake) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.now = f.now.Add(d)
+	waiting := f.waiting[:0]
+	for _, t := range f.waiting {
+		if t.when.After(f.now) {
+			waiting = append(waiting, t)
+			continue
+		}
+		due := t.when
+		for t.period > 0 && !t.when.After(f.now) {
+			due = t.when
+			t.when = t.when.Add(t.period)
+		}
+		select {
+		case t.c <- due:
+		default:
+		}
+		if t.period > 0 {
+			waiting = append(waiting, t)
+		}
+	}
+	f.waiting = waiting
+}
+
+// Waiting is the number of timers and tickers that have yet to fire
+// (or for tickers, be stopped)
+func (f *Fake) Waiting() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.waiting)
+}
+
+// WaitFor returns once at least 'n' timers and tickers are waiting, so
+// time can be advanced knowing the code under test is ready for it
+func (f *Fake) WaitFor(ctx context.Context, n int) error {
+	for {
+		f.lock.Lock()
+		count, added := len(f.waiting), f.added
+		f.lock.Unlock()
+		if count >= n {
+			return nil
+		}
+		select {
+		case <-added:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+type fakeTimer struct {
+	clock *Fake
+	c     chan time.Time
+	when  time.Time
+	// Zero for timers
+	period time.Duration
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	f := t.clock
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i, waiting := range f.waiting {
+		if waiting == t {
+			f.waiting = append(f.waiting[:i], f.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeTimer.Stop()
+}
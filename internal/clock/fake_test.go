@@ -0,0 +1,86 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Receives from 'c' if something's ready
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFake(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("timer", func(tt *testing.T) {
+		fake := clock.NewFake(start)
+		timer := fake.NewTimer(time.Minute)
+		assert.Equal(tt, 1, fake.Waiting())
+
+		fake.Advance(59 * time.Second)
+		_, ok := fired(timer.C())
+		assert.False(tt, ok)
+		fake.Advance(time.Second)
+		at, ok := fired(timer.C())
+		require.True(tt, ok)
+		assert.Equal(tt, start.Add(time.Minute), at)
+		assert.Equal(tt, start.Add(time.Minute), fake.Now())
+		assert.Equal(tt, time.Minute, fake.Since(start))
+
+		assert.Zero(tt, fake.Waiting())
+		assert.False(tt, timer.Stop())
+	})
+
+	t.Run("stop", func(tt *testing.T) {
+		fake := clock.NewFake(start)
+		timer := fake.NewTimer(time.Minute)
+		assert.True(tt, timer.Stop())
+		fake.Advance(time.Hour)
+		_, ok := fired(timer.C())
+		assert.False(tt, ok)
+	})
+
+	t.Run("ticker", func(tt *testing.T) {
+		fake := clock.NewFake(start)
+		ticker := fake.NewTicker(time.Minute)
+		fake.Advance(time.Minute)
+		at, ok := fired(ticker.C())
+		require.True(tt, ok)
+		assert.Equal(tt, start.Add(time.Minute), at)
+
+		// Missed ticks are dropped
+		fake.Advance(10*time.Minute + time.Second)
+		at, ok = fired(ticker.C())
+		require.True(tt, ok)
+		assert.Equal(tt, start.Add(11*time.Minute), at)
+		_, ok = fired(ticker.C())
+		assert.False(tt, ok)
+
+		ticker.Stop()
+		fake.Advance(time.Hour)
+		_, ok = fired(ticker.C())
+		assert.False(tt, ok)
+	})
+
+	t.Run("wait", func(tt *testing.T) {
+		fake := clock.NewFake(start)
+		go fake.NewTimer(time.Minute)
+		require.NoError(tt, fake.WaitFor(context.Background(), 1))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(tt, fake.WaitFor(ctx, 2), context.Canceled)
+	})
+}
@@ -0,0 +1,1221 @@
+package config
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/audit"
+	"github.com/gopheryan/jobby/internal/features"
+	"github.com/gopheryan/jobby/internal/policy"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/internal/templates"
+	"github.com/gopheryan/jobby/job"
+	"golang.org/x/sys/unix"
+	"gopkg.in/yaml.v3"
+)
+
+// Server holds deployment specific settings for the jobby server.
+// Any field omitted from the config file keeps its default value
+type Server struct {
+	// host:port to listen for gRPC requests on. May be empty when
+	// 'listeners' are given
+	Address string `yaml:"address"`
+	// More addresses to listen for gRPC requests on (ex: an IPv6 address,
+	// a management network's interface, or a unix socket)
+	Listeners []Listener `yaml:"listeners"`
+	// Base directory in which to store job output files
+	OutputDir string    `yaml:"output_dir"`
+	TLS       TLS       `yaml:"tls"`
+	Auth      Auth      `yaml:"auth"`
+	Retention Retention `yaml:"retention"`
+	Output    Output    `yaml:"output"`
+	Quota     Quota     `yaml:"quota"`
+	// Encryption of job output at rest. Disabled unless a master key is set
+	Encryption Encryption `yaml:"encryption"`
+	// Masks secrets (ex: API keys, bearer tokens) in all job output before it's stored
+	Redactions []Redaction `yaml:"redactions"`
+	// What jobs with network isolation may reach
+	Egress Egress `yaml:"egress"`
+	// Admin defined presets of limits and isolation that jobs select by name
+	RuntimeClasses map[string]RuntimeClass `yaml:"runtime_classes"`
+	// Class applied to jobs that don't select one. Empty means no limits
+	DefaultRuntimeClass string `yaml:"default_runtime_class"`
+	// Existing cgroup v2 directory that job cgroups are created in.
+	// Required by classes with cgroup limits
+	CgroupParent string `yaml:"cgroup_parent"`
+	// How many jobs may run at once, and how jobs are preempted beyond that
+	Capacity Capacity `yaml:"capacity"`
+	// Per-owner resource usage served by GetUsageSummary
+	Usage Usage `yaml:"usage"`
+	// Prometheus metrics. Not served unless an address is set
+	Metrics Metrics `yaml:"metrics"`
+	// Lifecycle events served by GetJobEvents
+	Events Events `yaml:"events"`
+	// Where job records and events are written through to, besides memory
+	Store Store `yaml:"store"`
+	// Rules every request must pass, beyond owning the jobs it's about
+	Policy []PolicyRule `yaml:"policy"`
+	// Users allowed to call admin RPCs (ex: StreamServerLogs)
+	Admins []string `yaml:"admins"`
+	// Adopting the processes jobs leave behind
+	Reaper Reaper `yaml:"reaper"`
+	// What happens to running jobs when the server is asked to exit
+	Shutdown Shutdown `yaml:"shutdown"`
+	// Commands or webhooks the server runs around jobs
+	Hooks Hooks `yaml:"hooks"`
+	// Directories jobs may ask for to write to
+	Scratch Scratch `yaml:"scratch"`
+	// Commands users may start by name with parameters, by name
+	Templates map[string]Template `yaml:"templates"`
+	// Switches feature flags (ex: v2_api) on or off. Flags left out keep
+	// their defaults. GetServerInfo lists them all
+	Features map[string]bool `yaml:"features"`
+}
+
+type TLS struct {
+	// CA used to verify client certificates
+	CACert string `yaml:"ca_cert"`
+	// Server certificate and key
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+	// When enabled, the server's certificate and the bundle used to verify
+	// clients come from the SPIFFE Workload API rather than the files above
+	SPIFFE SPIFFE `yaml:"spiffe"`
+	// When enabled, the server's certificate is obtained (and renewed) from an
+	// ACME CA such as Let's Encrypt. Clients are still verified with 'ca_cert'
+	ACME ACME `yaml:"acme"`
+}
+
+type Listener struct {
+	// "tcp" (default) or "unix"
+	Network string `yaml:"network"`
+	// host:port (ex: [::1]:8443), or the socket's path for "unix"
+	Address string `yaml:"address"`
+	// Files to use instead of the top level tls section's. Fields left
+	// empty keep the top level ones
+	TLS ListenerTLS `yaml:"tls"`
+	// For listeners behind L4 load balancers
+	ProxyProtocol ProxyProtocol `yaml:"proxy_protocol"`
+}
+
+// Has connections start with a PROXY protocol header (v1 or v2), which
+// gives the client's address in place of the load balancer's
+type ProxyProtocol struct {
+	Enabled bool `yaml:"enabled"`
+	// Addresses or prefixes (ex: 10.0.0.0/8) of the load balancers.
+	// Connections from anywhere else are refused. Not needed for unix
+	// sockets, which have no address to check
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+func (p ProxyProtocol) Prefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(p.TrustedProxies))
+	for _, proxy := range p.TrustedProxies {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy '%s': %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+func (l Listener) validate(field string) []error {
+	var errs []error
+	switch l.Network {
+	case NetworkTCP:
+		if _, _, err := net.SplitHostPort(l.Address); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid address '%s': %w", field, l.Address, err))
+		}
+	case NetworkUnix:
+		if !filepath.IsAbs(l.Address) {
+			errs = append(errs, fmt.Errorf("%s: socket path '%s' must be absolute", field, l.Address))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: unknown network '%s'", field, l.Network))
+	}
+	if _, err := l.ProxyProtocol.Prefixes(); err != nil {
+		errs = append(errs, fmt.Errorf("%s.proxy_protocol: %w", field, err))
+	} else if l.ProxyProtocol.Enabled && l.Network == NetworkTCP && len(l.ProxyProtocol.TrustedProxies) == 0 {
+		errs = append(errs, fmt.Errorf("%s.proxy_protocol.trusted_proxies must not be empty", field))
+	}
+	return errs
+}
+
+type ListenerTLS struct {
+	CACert string `yaml:"ca_cert"`
+	Cert   string `yaml:"cert"`
+	Key    string `yaml:"key"`
+}
+
+const (
+	NetworkTCP  = "tcp"
+	NetworkUnix = "unix"
+)
+
+// GRPCListeners returns every address to serve gRPC on, starting with
+// 'address', with their TLS files filled in from the tls section
+func (s Server) GRPCListeners() []Listener {
+	configured := s.Listeners
+	if s.Address != "" {
+		configured = append([]Listener{{Address: s.Address}}, configured...)
+	}
+	listeners := make([]Listener, 0, len(configured))
+	for _, listener := range configured {
+		listener.Network = cmp.Or(listener.Network, NetworkTCP)
+		listener.TLS = ListenerTLS{
+			CACert: cmp.Or(listener.TLS.CACert, s.TLS.CACert),
+			Cert:   cmp.Or(listener.TLS.Cert, s.TLS.Cert),
+			Key:    cmp.Or(listener.TLS.Key, s.TLS.Key),
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners
+}
+
+type SPIFFE struct {
+	Enabled bool `yaml:"enabled"`
+	// Workload API address (ex: unix:///run/spire/agent.sock).
+	// Defaults to the SPIFFE_ENDPOINT_SOCKET environment variable
+	Socket string `yaml:"socket"`
+	// Only accept clients from this trust domain. Any trust
+	// domain in the bundle is accepted when empty
+	TrustDomain string `yaml:"trust_domain"`
+}
+
+type ACME struct {
+	Enabled bool `yaml:"enabled"`
+	// Public DNS names of the server
+	Domains []string `yaml:"domains"`
+	// Contact email for the ACME account
+	Email string `yaml:"email"`
+	// Certificates and account keys are stored here
+	CacheDir string `yaml:"cache_dir"`
+	// Defaults to Let's Encrypt production
+	DirectoryURL string `yaml:"directory_url"`
+	// Optional address (ex: ':80') to answer HTTP-01 challenges on.
+	// TLS-ALPN-01 challenges are always answered on the gRPC listener
+	HTTPAddress string `yaml:"http_address"`
+}
+
+type Auth struct {
+	// Which part of the client certificate identifies the user.
+	// One of: cn (default), uri, email
+	Identity string `yaml:"identity"`
+	// Only used with 'uri' identity. Strips this prefix from the
+	// matching URI SAN to get the user name
+	URIPrefix string `yaml:"uri_prefix"`
+	// Use the certificate common name when the
+	// configured SAN is not present
+	FallbackToCN bool `yaml:"fallback_to_cn"`
+	// Let callers without a client certificate read the status and
+	// output of public jobs. Nothing else is open to them
+	Anonymous bool `yaml:"anonymous"`
+	// Let jobs ask for a token to call back into the server with, as
+	// their owner, without a client certificate
+	JobTokens bool `yaml:"job_tokens"`
+	// Stop the jobs of owners whose credentials lapse
+	CredentialLeases CredentialLeases `yaml:"credential_leases"`
+	// Identify callers by the client certificate a proxy that terminated
+	// their TLS forwards in the x-forwarded-client-cert header
+	ForwardedClientCert ForwardedClientCert `yaml:"forwarded_client_cert"`
+	// Only accept these client certificates, out of all the CA signed.
+	// Every certificate is accepted when empty
+	AllowedClients AllowedClients `yaml:"allowed_clients"`
+	// Turn away callers that keep failing to authenticate
+	FailureBans FailureBans `yaml:"failure_bans"`
+}
+
+// Callers are banned by IP address and by their certificate's public
+// key. Trusted proxies aren't banned, but the callers they relay are, by
+// the forwarded certificate and by the address in any PROXY protocol
+// header. Bans are logged, counted in the metrics and sent to event exporters
+type FailureBans struct {
+	Enabled bool `yaml:"enabled"`
+	// Failures within 'window' that get a caller banned
+	MaxFailures int           `yaml:"max_failures"`
+	Window      time.Duration `yaml:"window"`
+	// How long the first ban lasts. Each one after lasts twice as long,
+	// up to 'max_ban'
+	Ban    time.Duration `yaml:"ban"`
+	MaxBan time.Duration `yaml:"max_ban"`
+}
+
+// A certificate is allowed if it matches any of these. Proxies forwarding
+// certificates must be allowed as well as the certificates they forward
+type AllowedClients struct {
+	CommonNames []string `yaml:"common_names"`
+	// URI SANs, in full (ex: spiffe://jobby.local/user/alice)
+	URIs []string `yaml:"uris"`
+	// Base64 encoded SHA-256 digests of certificates' SubjectPublicKeyInfo
+	// (ex: from 'openssl x509 -pubkey -noout | openssl pkey -pubin
+	// -outform der | openssl dgst -sha256 -binary | base64')
+	SPKIPins []string `yaml:"spki_pins"`
+}
+
+type ForwardedClientCert struct {
+	// Users (as 'identity' determines them from their own client
+	// certificates) that are proxies. Headers from anyone else are ignored.
+	// Forwarded certificates must still be signed by tls.ca_cert
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// Controls stopping jobs whose owner's credentials have lapsed. Each
+// call an owner makes renews their credentials until their client
+// certificate expires
+type CredentialLeases struct {
+	Enabled bool `yaml:"enabled"`
+	// Credentials also lapse this long after the owner's last call.
+	// 0 goes by certificate expiry alone
+	Lease time.Duration `yaml:"lease"`
+	// How long after their credentials lapse an owner's jobs are stopped
+	Grace time.Duration `yaml:"grace"`
+	// How often to look for jobs of owners with lapsed credentials
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// Controls garbage collection of finished jobs and their output
+type Retention struct {
+	// Retention of jobs that don't ask for one. 0 keeps them forever
+	DefaultTTL time.Duration `yaml:"default_ttl"`
+	// Longest retention a job may ask for. 0 means no limit
+	MaxTTL time.Duration `yaml:"max_ttl"`
+	// Whether jobs may ask to be kept forever
+	AllowKeepForever bool `yaml:"allow_keep_forever"`
+	// How often to look for expired jobs
+	GCInterval time.Duration `yaml:"gc_interval"`
+	// How long deleted jobs may be restored. 0 deletes them right away
+	DeleteGracePeriod time.Duration `yaml:"delete_grace_period"`
+}
+
+// Default batching of GetJobOutput messages. Clients may override both per request
+type Output struct {
+	// Send once this many bytes are buffered. Also the largest message size
+	BatchMaxBytes int `yaml:"batch_max_bytes"`
+	// Longest output may wait before it's sent. 0 sends output as soon as it's read
+	BatchMaxDelay time.Duration `yaml:"batch_max_delay"`
+	// Line mode streams send a partial line after holding it this long. 0 holds it until complete
+	MaxLineHold time.Duration `yaml:"max_line_hold"`
+	RateLimit   RateLimit     `yaml:"rate_limit"`
+	// When output files are synced to disk
+	Sync OutputSync `yaml:"sync"`
+}
+
+// Trades throughput for output that survives host crashes
+type OutputSync struct {
+	// One of: none (default), exit (once the job exits), periodic (every
+	// interval while it runs, and once it exits)
+	Mode string `yaml:"mode"`
+	// Only used in periodic mode
+	Interval time.Duration `yaml:"interval"`
+}
+
+var syncModes = map[string]job.SyncMode{
+	"none":     job.SyncNone,
+	"exit":     job.SyncOnExit,
+	"periodic": job.SyncPeriodic,
+}
+
+// SyncPolicy converts the sync settings into the job package's representation
+func (o OutputSync) SyncPolicy() (job.SyncPolicy, error) {
+	mode, ok := syncModes[o.Mode]
+	if !ok {
+		return job.SyncPolicy{}, fmt.Errorf("unknown output.sync.mode '%s'", o.Mode)
+	}
+	policy := job.SyncPolicy{Mode: mode, Interval: o.Interval}
+	if err := policy.Validate(); err != nil {
+		return job.SyncPolicy{}, fmt.Errorf("output.sync: %w", err)
+	}
+	return policy, nil
+}
+
+// Output bandwidth caps in bytes per second. 0 means unlimited
+type RateLimit struct {
+	PerStream int `yaml:"per_stream"`
+	PerUser   int `yaml:"per_user"`
+	Global    int `yaml:"global"`
+}
+
+// Limits on job output kept on disk
+type Quota struct {
+	// Bytes of output each user may keep on disk. 0 means unlimited
+	PerUserBytes int64 `yaml:"per_user_bytes"`
+	// What to do with a job that writes past the quota.
+	// One of: stop (default), truncate
+	Action string `yaml:"action"`
+}
+
+type Capacity struct {
+	// Jobs running at once. 0 means no limit
+	MaxRunningJobs int `yaml:"max_running_jobs"`
+	// How long preempted jobs have to exit after SIGTERM before they're killed
+	PreemptionGrace time.Duration `yaml:"preemption_grace"`
+	// Jobs are refused if starting them would leave less than this
+	Headroom Headroom `yaml:"headroom"`
+}
+
+// See service.Headroom. 0 doesn't check
+type Headroom struct {
+	MinFreeMemory uint64  `yaml:"min_free_memory_bytes"`
+	MinFreeDisk   uint64  `yaml:"min_free_disk_bytes"`
+	MaxLoadPerCPU float64 `yaml:"max_load_per_cpu"`
+}
+
+// See service.DrainPolicy
+type Shutdown struct {
+	// How long to wait for jobs expected to finish soon. 0 stops every job right away
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+	// How long stopped jobs have to exit (ex: checkpoint) after SIGTERM before they're killed
+	StopGrace time.Duration `yaml:"stop_grace"`
+}
+
+// See job.EnableSubreaper
+type Reaper struct {
+	// Become a child subreaper, so daemons jobs start are reaped and
+	// stopped along with their job instead of outliving it
+	Subreaper bool `yaml:"subreaper"`
+	// How often to look for new orphans between SIGCHLDs
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+	// Kill a job's orphans as soon as its process exits
+	KillOnExit bool `yaml:"kill_on_exit"`
+}
+
+type Usage struct {
+	// Trailing windows (ex: 24h) usage is summarized over
+	Windows []time.Duration `yaml:"windows"`
+	// Users who may see the usage of every owner
+	Viewers []string `yaml:"viewers"`
+}
+
+type Events struct {
+	// File events are appended to. Defaults to events.jsonl in output_dir
+	File string `yaml:"file"`
+	// How long events are kept after their job is garbage collected
+	Retention time.Duration `yaml:"retention"`
+	// SIEMs events are sent to as they happen
+	Exporters []EventExporter `yaml:"exporters"`
+}
+
+// See package audit
+type EventExporter struct {
+	// Named in the server's logs
+	Name string `yaml:"name"`
+	// syslog (RFC 5424) or cef
+	Format string `yaml:"format"`
+	// udp, tcp or tls
+	Network string `yaml:"network"`
+	// host:port of the collector
+	Address string `yaml:"address"`
+	// tls only. Verifies the collector. The system's roots when empty
+	CACert string `yaml:"ca_cert"`
+	// tls only. Client certificate, for collectors that ask for one
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+	// syslog facility, 0-23. Defaults to local0 (16)
+	Facility int `yaml:"facility"`
+	// Events waiting to be delivered, beyond which the oldest are dropped.
+	// Defaults to 10000
+	QueueSize int `yaml:"queue_size"`
+}
+
+func (e EventExporter) validate(field string) []error {
+	var errs []error
+	if e.Name == "" {
+		errs = append(errs, fmt.Errorf("%s: name must not be empty", field))
+	}
+	switch e.Format {
+	case audit.FormatSyslog, audit.FormatCEF:
+	default:
+		errs = append(errs, fmt.Errorf("%s: unknown format '%s'", field, e.Format))
+	}
+	switch e.Network {
+	case audit.NetworkUDP, audit.NetworkTCP:
+		if e.CACert != "" || e.Cert != "" || e.Key != "" {
+			errs = append(errs, fmt.Errorf("%s: ca_cert, cert and key are only for the tls network", field))
+		}
+	case audit.NetworkTLS:
+		if (e.Cert == "") != (e.Key == "") {
+			errs = append(errs, fmt.Errorf("%s: cert and key must be set together", field))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: unknown network '%s'", field, e.Network))
+	}
+	if _, _, err := net.SplitHostPort(e.Address); err != nil {
+		errs = append(errs, fmt.Errorf("%s: address must be host:port", field))
+	}
+	if e.Facility < 0 || e.Facility > 23 {
+		errs = append(errs, fmt.Errorf("%s: facility must be between 0 and 23", field))
+	}
+	if e.QueueSize < 0 {
+		errs = append(errs, fmt.Errorf("%s: queue_size must not be negative", field))
+	}
+	return errs
+}
+
+// See store.Open
+type Store struct {
+	// One of: none (default), bolt, postgres
+	Backend string `yaml:"backend"`
+	// bolt's database file. Defaults to jobby.db in output_dir
+	Path string `yaml:"path"`
+	// postgres's connection string (ex: postgres://jobby@db.internal/jobby)
+	DSN string `yaml:"dsn"`
+	// Servers sharing a postgres store
+	HA HA `yaml:"ha"`
+}
+
+// Two or more servers can share a postgres store so the control plane
+// survives losing one of them. Jobs still run on, and are managed by,
+// the server that started them. One server at a time is elected to
+// collect the store's garbage
+type HA struct {
+	Enabled bool `yaml:"enabled"`
+	// This server's name, unique among those sharing the store.
+	// Defaults to the hostname
+	Node string `yaml:"node"`
+	// How long a leader that stops renewing its lease stays leader
+	LeaseTTL time.Duration `yaml:"lease_ttl"`
+}
+
+type Hooks struct {
+	// Run in order before each attempt of a job starts. The first to
+	// fail keeps the attempt from starting
+	PreStart []Hook `yaml:"pre_start"`
+	// Run in order once each job finishes
+	PostJob []Hook `yaml:"post_job"`
+}
+
+// Hook is either a command or a webhook, not both
+type Hook struct {
+	Name string `yaml:"name"`
+	// Program and arguments. The program's path must be absolute
+	Command []string `yaml:"command"`
+	// http(s) URL to POST to
+	URL string `yaml:"url"`
+	// Defaults to a minute
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type Scratch struct {
+	// Largest tmpfs a job may keep its scratch directory in. Mounting them
+	// takes CAP_SYS_ADMIN. Zero only allows scratch directories on disk
+	MaxTmpfsBytes uint64 `yaml:"max_tmpfs_bytes"`
+}
+
+// Listeners for monitoring, apart from the gRPC API so scrapers and
+// probes don't need client certificates
+type Metrics struct {
+	// host:port to serve /metrics and /debug/vars on
+	Address string `yaml:"address"`
+	// host:port to serve /healthz and /readyz on. Defaults to Address
+	HealthAddress string `yaml:"health_address"`
+	// host:port to serve /debug/pprof on. Off unless set, as profiles
+	// show what the server is up to. May be the same as the others
+	PprofAddress string `yaml:"pprof_address"`
+	// Serve over TLS with the server's certificate (without asking for
+	// the client's). Plain HTTP otherwise
+	TLS bool `yaml:"tls"`
+}
+
+// What's served on one of the monitoring addresses
+type MetricsListener struct {
+	Address string
+	Metrics bool
+	Health  bool
+	Pprof   bool
+}
+
+// Listeners combines the monitoring endpoints by address, in order of address
+func (m Metrics) Listeners() []MetricsListener {
+	var listeners []MetricsListener
+	listener := func(address string) *MetricsListener {
+		for i := range listeners {
+			if listeners[i].Address == address {
+				return &listeners[i]
+			}
+		}
+		listeners = append(listeners, MetricsListener{Address: address})
+		return &listeners[len(listeners)-1]
+	}
+	if m.Address != "" {
+		listener(m.Address).Metrics = true
+	}
+	if address := cmp.Or(m.HealthAddress, m.Address); address != "" {
+		listener(address).Health = true
+	}
+	if m.PprofAddress != "" {
+		listener(m.PprofAddress).Pprof = true
+	}
+	slices.SortFunc(listeners, func(a, b MetricsListener) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return listeners
+}
+
+type Encryption struct {
+	// File holding the base64 encoded 32 byte master key that wraps each job's data key.
+	// Output is written in plaintext when empty
+	MasterKeyFile string `yaml:"master_key_file"`
+}
+
+type Redaction struct {
+	// RE2 regular expression matched against each line of output
+	Pattern string `yaml:"pattern"`
+	// Replaces each match, and may refer to submatches ($1).
+	// Defaults to job.DefaultRedactionReplacement
+	Replacement string `yaml:"replacement"`
+}
+
+// JobRedactions converts the configured redactions into the job package's representation
+func (s Server) JobRedactions() ([]job.Redaction, error) {
+	redactions := make([]job.Redaction, 0, len(s.Redactions))
+	for idx, r := range s.Redactions {
+		redaction, err := job.NewRedaction(r.Pattern, r.Replacement)
+		if err != nil {
+			return nil, fmt.Errorf("redactions[%d]: %w", idx, err)
+		}
+		redactions = append(redactions, redaction)
+	}
+	return redactions, nil
+}
+
+type Template struct {
+	// Shown to users previewing the template
+	Description string `yaml:"description"`
+	// May hold placeholders ('{{name}}') for parameters. Each is filled in
+	// within the argument it's part of
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	// By name
+	Params map[string]TemplateParam `yaml:"params"`
+}
+
+type TemplateParam struct {
+	// string (the default), int, bool or enum
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+	// Used when the parameter isn't given. Parameters without one are required
+	Default *string `yaml:"default"`
+	// int only. Both inclusive
+	Min *int64 `yaml:"min"`
+	Max *int64 `yaml:"max"`
+	// string only. A regular expression the whole value must match
+	Pattern string `yaml:"pattern"`
+	// string only. In bytes
+	MaxLength int `yaml:"max_length"`
+	// enum only
+	Values []string `yaml:"values"`
+}
+
+// JobTemplates checks the configured templates, by name
+func (s Server) JobTemplates() (map[string]*templates.Template, error) {
+	out := make(map[string]*templates.Template, len(s.Templates))
+	for name, t := range s.Templates {
+		tmpl := templates.Template{Name: name, Description: t.Description, Command: t.Command, Args: t.Args}
+		for paramName, p := range t.Params {
+			tmpl.Params = append(tmpl.Params, templates.Param{
+				Name:        paramName,
+				Type:        p.Type,
+				Description: p.Description,
+				Default:     p.Default,
+				Min:         p.Min,
+				Max:         p.Max,
+				Pattern:     p.Pattern,
+				MaxLength:   p.MaxLength,
+				Values:      p.Values,
+			})
+		}
+		compiled, err := templates.New(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("templates.%s: %w", name, err)
+		}
+		out[name] = compiled
+	}
+	return out, nil
+}
+
+// FeatureSet applies the configured feature flags
+func (s Server) FeatureSet() (features.Set, error) {
+	set, err := features.New(s.Features)
+	if err != nil {
+		return features.Set{}, fmt.Errorf("features: %w", err)
+	}
+	return set, nil
+}
+
+// Requests the rule applies to are denied unless they satisfy it.
+// Expressions are written in CEL (see the policy package for variables)
+type PolicyRule struct {
+	// Named in the error of requests the rule denies
+	Name string `yaml:"name"`
+	// Selects the requests the rule applies to (ex: 'user.startsWith("intern-")').
+	// All of them when empty
+	When string `yaml:"when"`
+	// What they must satisfy (ex: 'rpc != "StartJob" || command == "/usr/bin/python3"')
+	Require string `yaml:"require"`
+}
+
+// PolicyRules compiles the configured policy rules
+func (s Server) PolicyRules() ([]policy.Rule, error) {
+	rules := make([]policy.Rule, 0, len(s.Policy))
+	for idx, r := range s.Policy {
+		rule, err := policy.NewRule(r.Name, r.When, r.Require)
+		if err != nil {
+			return nil, fmt.Errorf("policy[%d]: %w", idx, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+type RuntimeClass struct {
+	// Jobs are killed after running this long. 0 means no limit
+	Timeout time.Duration `yaml:"timeout"`
+	// Resource name (ex: nofile, nproc, as) to limit. Sets both the soft and hard limit
+	Rlimits map[string]uint64 `yaml:"rlimits"`
+	// Run each job in its own cgroup with these limits
+	Cgroup *Cgroup `yaml:"cgroup"`
+	// Namespaces to run jobs in. Requires the server to run as root
+	Isolation Isolation `yaml:"isolation"`
+}
+
+type Cgroup struct {
+	// Bytes
+	MemoryMax int64 `yaml:"memory_max"`
+	// Share of a CPU (ex: 0.5)
+	CPUs    float64 `yaml:"cpus"`
+	PidsMax int64   `yaml:"pids_max"`
+}
+
+type Isolation struct {
+	PID     bool `yaml:"pid"`
+	Network bool `yaml:"network"`
+	Mount   bool `yaml:"mount"`
+	IPC     bool `yaml:"ipc"`
+	UTS     bool `yaml:"uts"`
+	// Egress policy of jobs that don't select one. Requires network.
+	// Empty leaves jobs without any network at all
+	EgressPolicy string `yaml:"egress_policy"`
+}
+
+type Egress struct {
+	// IPv4 prefix (ex: 10.200.0.0/16) split into a /30 for each allowlist job. The host
+	// must forward and NAT it for jobs to reach anything besides the host itself
+	UplinkSubnet string `yaml:"uplink_subnet"`
+	// Policies jobs and runtime classes select by name
+	Policies map[string]EgressPolicy `yaml:"policies"`
+}
+
+type EgressPolicy struct {
+	// One of: none (default), loopback, allowlist
+	Mode string `yaml:"mode"`
+	// Destinations reachable in allowlist mode
+	Allow []EgressAllow `yaml:"allow"`
+}
+
+type EgressAllow struct {
+	// Address or prefix (ex: 10.0.0.0/8)
+	Destination string `yaml:"destination"`
+	// TCP or UDP port. 0 allows any port and protocol
+	Port uint16 `yaml:"port"`
+}
+
+var egressModes = map[string]job.EgressMode{
+	"":          job.EgressNone,
+	"none":      job.EgressNone,
+	"loopback":  job.EgressLoopback,
+	"allowlist": job.EgressAllowlist,
+}
+
+// EgressPolicies converts the egress policies into the job package's representation.
+// Allowlist policies share one pool of uplink subnets
+func (s Server) EgressPolicies() (map[string]job.Egress, error) {
+	var uplinks *job.UplinkPool
+	if s.Egress.UplinkSubnet != "" {
+		prefix, err := netip.ParsePrefix(s.Egress.UplinkSubnet)
+		if err != nil {
+			return nil, fmt.Errorf("egress.uplink_subnet: %w", err)
+		}
+		if uplinks, err = job.NewUplinkPool(prefix); err != nil {
+			return nil, fmt.Errorf("egress.uplink_subnet: %w", err)
+		}
+	}
+
+	var errs []error
+	policies := make(map[string]job.Egress, len(s.Egress.Policies))
+	for name, policy := range s.Egress.Policies {
+		mode, ok := egressModes[policy.Mode]
+		if !ok {
+			errs = append(errs, fmt.Errorf("egress.policies.%s: unknown mode '%s'", name, policy.Mode))
+			continue
+		}
+		egress := job.Egress{Mode: mode}
+		if mode == job.EgressAllowlist {
+			if uplinks == nil {
+				errs = append(errs, fmt.Errorf("egress.policies.%s: allowlist requires egress.uplink_subnet", name))
+			}
+			egress.Uplinks = uplinks
+		}
+		for _, allow := range policy.Allow {
+			prefix, err := parsePrefix(allow.Destination)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("egress.policies.%s: invalid destination '%s'", name, allow.Destination))
+				continue
+			}
+			egress.Allow = append(egress.Allow, job.EgressRule{Prefix: prefix, Port: allow.Port})
+		}
+		policies[name] = egress
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Single addresses are taken as a prefix of their full length
+func parsePrefix(address string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(address); err == nil {
+		return prefix, nil
+	}
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Hooks of a kind must have unique names
+func validateHooks(field string, hooks []Hook) []error {
+	var errs []error
+	names := make(map[string]bool, len(hooks))
+	for i, hook := range hooks {
+		if names[hook.Name] {
+			errs = append(errs, fmt.Errorf("%s[%d]: duplicate name '%s'", field, i, hook.Name))
+		}
+		names[hook.Name] = true
+		errs = append(errs, hook.validate(fmt.Sprintf("%s[%d]", field, i))...)
+	}
+	return errs
+}
+
+func (h Hook) validate(field string) []error {
+	var errs []error
+	if h.Name == "" {
+		errs = append(errs, fmt.Errorf("%s: name must not be empty", field))
+	}
+	switch {
+	case len(h.Command) > 0 && h.URL != "":
+		errs = append(errs, fmt.Errorf("%s: command and url are mutually exclusive", field))
+	case len(h.Command) > 0:
+		if !filepath.IsAbs(h.Command[0]) {
+			errs = append(errs, fmt.Errorf("%s: command must be an absolute path", field))
+		}
+	case h.URL != "":
+		if u, err := url.Parse(h.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s: url must be an http(s) URL", field))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: one of command or url is required", field))
+	}
+	if h.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("%s: timeout must not be negative", field))
+	}
+	return errs
+}
+
+var rlimitResources = map[string]int{
+	"as":      unix.RLIMIT_AS,
+	"core":    unix.RLIMIT_CORE,
+	"cpu":     unix.RLIMIT_CPU,
+	"data":    unix.RLIMIT_DATA,
+	"fsize":   unix.RLIMIT_FSIZE,
+	"memlock": unix.RLIMIT_MEMLOCK,
+	"nofile":  unix.RLIMIT_NOFILE,
+	"nproc":   unix.RLIMIT_NPROC,
+	"stack":   unix.RLIMIT_STACK,
+}
+
+// Limits converts the class into the job package's representation
+func (r RuntimeClass) Limits(cgroupParent string) job.Limits {
+	limits := job.Limits{
+		Timeout: r.Timeout,
+		Isolation: job.Isolation{
+			PID:     r.Isolation.PID,
+			Network: r.Isolation.Network,
+			Mount:   r.Isolation.Mount,
+			IPC:     r.Isolation.IPC,
+			UTS:     r.Isolation.UTS,
+		},
+	}
+	for name, value := range r.Rlimits {
+		limits.Rlimits = append(limits.Rlimits, job.Rlimit{Resource: rlimitResources[name], Soft: value, Hard: value})
+	}
+	if r.Cgroup != nil {
+		limits.Cgroup = &job.CgroupLimits{
+			Parent:    cgroupParent,
+			MemoryMax: r.Cgroup.MemoryMax,
+			CPUs:      r.Cgroup.CPUs,
+			PidsMax:   r.Cgroup.PidsMax,
+		}
+	}
+	return limits
+}
+
+func (r RuntimeClass) validate(name string, cgroupParent string, egress Egress) []error {
+	var errs []error
+	if policy := r.Isolation.EgressPolicy; policy != "" {
+		if _, ok := egress.Policies[policy]; !ok {
+			errs = append(errs, fmt.Errorf("runtime_classes.%s: egress policy '%s' is not defined", name, policy))
+		}
+		if !r.Isolation.Network {
+			errs = append(errs, fmt.Errorf("runtime_classes.%s: egress_policy requires network isolation", name))
+		}
+	}
+	if r.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("runtime_classes.%s.timeout must not be negative", name))
+	}
+	for resource := range r.Rlimits {
+		if _, ok := rlimitResources[resource]; !ok {
+			errs = append(errs, fmt.Errorf("runtime_classes.%s: unknown rlimit '%s'", name, resource))
+		}
+	}
+	if r.Cgroup != nil {
+		if cgroupParent == "" {
+			errs = append(errs, fmt.Errorf("runtime_classes.%s: cgroup limits require cgroup_parent", name))
+		}
+		if r.Cgroup.MemoryMax < 0 || r.Cgroup.CPUs < 0 || r.Cgroup.PidsMax < 0 {
+			errs = append(errs, fmt.Errorf("runtime_classes.%s: cgroup limits must not be negative", name))
+		}
+	}
+	return errs
+}
+
+// Keep messages comfortably below gRPC's default 4MiB limit
+const maxBatchBytes = 1024 * 1024
+
+// Where job events are kept
+func (s Server) EventsFile() string {
+	if s.Events.File != "" {
+		return s.Events.File
+	}
+	return filepath.Join(s.OutputDir, "events.jsonl")
+}
+
+// Where the store is: a file for bolt, a connection string for postgres
+func (s Server) StoreLocation() string {
+	if s.Store.Backend == store.BackendPostgres {
+		return s.Store.DSN
+	}
+	if s.Store.Path != "" {
+		return s.Store.Path
+	}
+	return filepath.Join(s.OutputDir, "jobby.db")
+}
+
+// Default reproduces the server's original hardcoded behavior:
+// listen on localhost and expect certs relative to the working directory
+func Default() Server {
+	return Server{
+		Address:   "localhost:8443",
+		OutputDir: os.TempDir(),
+		TLS: TLS{
+			CACert: "ca/ca.crt",
+			Cert:   "server/server.crt",
+			Key:    "server/server.key",
+		},
+		Auth: Auth{
+			Identity: "cn",
+			CredentialLeases: CredentialLeases{
+				CheckInterval: time.Minute,
+			},
+			FailureBans: FailureBans{
+				MaxFailures: 10,
+				Window:      time.Minute,
+				Ban:         time.Minute,
+				MaxBan:      time.Hour,
+			},
+		},
+		Retention: Retention{
+			AllowKeepForever:  true,
+			GCInterval:        time.Minute,
+			DeleteGracePeriod: 24 * time.Hour,
+		},
+		Output: Output{
+			BatchMaxBytes: 4096,
+			MaxLineHold:   time.Second,
+			Sync: OutputSync{
+				Mode:     "none",
+				Interval: 5 * time.Second,
+			},
+		},
+		Quota: Quota{
+			Action: "stop",
+		},
+		Capacity: Capacity{
+			PreemptionGrace: 10 * time.Second,
+		},
+		Reaper: Reaper{
+			SweepInterval: time.Second,
+		},
+		Shutdown: Shutdown{
+			DrainTimeout: 30 * time.Second,
+			StopGrace:    10 * time.Second,
+		},
+		Usage: Usage{
+			Windows: []time.Duration{time.Hour, 24 * time.Hour, 7 * 24 * time.Hour},
+		},
+		Events: Events{
+			Retention: 7 * 24 * time.Hour,
+		},
+		Store: Store{
+			Backend: "none",
+			HA: HA{
+				LeaseTTL: 15 * time.Second,
+			},
+		},
+	}
+}
+
+// Load reads a YAML config file on top of the defaults
+func Load(path string) (Server, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Server{}, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Server{}, fmt.Errorf("error parsing config file: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Server{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+func (s Server) Validate() error {
+	var errs []error
+	if s.Address == "" && len(s.Listeners) == 0 {
+		errs = append(errs, errors.New("address must not be empty without listeners"))
+	}
+	grpcAddresses := map[string]bool{}
+	listeners := s.GRPCListeners()
+	// 1 when 'address' is set
+	offset := len(listeners) - len(s.Listeners)
+	for i, listener := range listeners {
+		field := "address"
+		if i >= offset {
+			field = fmt.Sprintf("listeners[%d]", i-offset)
+		}
+		errs = append(errs, listener.validate(field)...)
+		if grpcAddresses[listener.Network+" "+listener.Address] {
+			errs = append(errs, fmt.Errorf("%s: '%s' is already listened on", field, listener.Address))
+		}
+		grpcAddresses[listener.Network+" "+listener.Address] = true
+	}
+	for i, listener := range s.Listeners {
+		if listener.TLS != (ListenerTLS{}) && (s.TLS.SPIFFE.Enabled || s.TLS.ACME.Enabled) {
+			errs = append(errs, fmt.Errorf("listeners[%d].tls can't be used with tls.spiffe or tls.acme", i))
+		}
+		if (listener.TLS.Cert == "") != (listener.TLS.Key == "") {
+			errs = append(errs, fmt.Errorf("listeners[%d]: tls.cert and tls.key must be set together", i))
+		}
+	}
+	if s.OutputDir == "" {
+		errs = append(errs, errors.New("output_dir must not be empty"))
+	} else if !filepath.IsAbs(s.OutputDir) {
+		errs = append(errs, errors.New("output_dir must be an absolute path"))
+	} else if privileged, ok := job.PrivilegedPath(s.OutputDir); ok {
+		errs = append(errs, fmt.Errorf("output_dir must not be inside privileged path '%s'", privileged))
+	}
+	if s.TLS.SPIFFE.Enabled && s.TLS.ACME.Enabled {
+		errs = append(errs, errors.New("tls.spiffe and tls.acme are mutually exclusive"))
+	}
+	if s.TLS.SPIFFE.Enabled && s.Auth.Anonymous {
+		errs = append(errs, errors.New("auth.anonymous can't be used with tls.spiffe"))
+	}
+	if s.TLS.SPIFFE.Enabled && s.Auth.JobTokens {
+		errs = append(errs, errors.New("auth.job_tokens can't be used with tls.spiffe"))
+	}
+	for _, pin := range s.Auth.AllowedClients.SPKIPins {
+		if digest, err := base64.StdEncoding.DecodeString(pin); err != nil || len(digest) != sha256.Size {
+			errs = append(errs, fmt.Errorf("auth.allowed_clients: '%s' isn't a base64 encoded SHA-256 digest", pin))
+		}
+	}
+	if len(s.Auth.ForwardedClientCert.TrustedProxies) > 0 && s.TLS.SPIFFE.Enabled {
+		errs = append(errs, errors.New("auth.forwarded_client_cert can't be used with tls.spiffe"))
+	}
+	if b := s.Auth.FailureBans; b.Enabled {
+		if b.MaxFailures <= 0 || b.Window <= 0 || b.Ban <= 0 {
+			errs = append(errs, errors.New("auth.failure_bans max_failures, window and ban must be positive"))
+		}
+		if b.MaxBan < b.Ban {
+			errs = append(errs, errors.New("auth.failure_bans.max_ban must not be less than ban"))
+		}
+	}
+	if l := s.Auth.CredentialLeases; l.Enabled {
+		if l.Lease < 0 || l.Grace < 0 {
+			errs = append(errs, errors.New("auth.credential_leases durations must not be negative"))
+		}
+		if l.CheckInterval <= 0 {
+			errs = append(errs, errors.New("auth.credential_leases.check_interval must be positive"))
+		}
+	}
+	if s.TLS.ACME.Enabled {
+		if len(s.TLS.ACME.Domains) == 0 {
+			errs = append(errs, errors.New("tls.acme.domains must not be empty"))
+		}
+		if s.TLS.ACME.CacheDir == "" {
+			errs = append(errs, errors.New("tls.acme.cache_dir must not be empty"))
+		}
+	}
+	if r := s.Retention; r.DefaultTTL < 0 || r.MaxTTL < 0 {
+		errs = append(errs, errors.New("retention ttls must not be negative"))
+	} else if r.MaxTTL > 0 && r.DefaultTTL > r.MaxTTL {
+		errs = append(errs, errors.New("retention.default_ttl must not exceed retention.max_ttl"))
+	} else if r.DefaultTTL == 0 && !r.AllowKeepForever {
+		errs = append(errs, errors.New("retention.default_ttl must be set when keeping jobs forever is not allowed"))
+	}
+	if s.Retention.GCInterval <= 0 {
+		errs = append(errs, errors.New("retention.gc_interval must be positive"))
+	}
+	if s.Retention.DeleteGracePeriod < 0 {
+		errs = append(errs, errors.New("retention.delete_grace_period must not be negative"))
+	}
+	if s.Output.BatchMaxBytes <= 0 || s.Output.BatchMaxBytes > maxBatchBytes {
+		errs = append(errs, fmt.Errorf("output.batch_max_bytes must be between 1 and %d", maxBatchBytes))
+	}
+	if s.Output.BatchMaxDelay < 0 || s.Output.MaxLineHold < 0 {
+		errs = append(errs, errors.New("output.batch_max_delay and output.max_line_hold must not be negative"))
+	}
+	if r := s.Output.RateLimit; r.PerStream < 0 || r.PerUser < 0 || r.Global < 0 {
+		errs = append(errs, errors.New("output.rate_limit values must not be negative"))
+	}
+	if _, err := s.Output.Sync.SyncPolicy(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, listener := range s.Metrics.Listeners() {
+		if _, _, err := net.SplitHostPort(listener.Address); err != nil {
+			errs = append(errs, fmt.Errorf("invalid metrics address '%s': %w", listener.Address, err))
+		} else if grpcAddresses[NetworkTCP+" "+listener.Address] {
+			errs = append(errs, fmt.Errorf("metrics address '%s' is a gRPC address", listener.Address))
+		}
+	}
+	if s.Metrics.TLS && len(s.Metrics.Listeners()) == 0 {
+		errs = append(errs, errors.New("metrics.tls needs an address to serve on"))
+	}
+	if s.Quota.PerUserBytes < 0 {
+		errs = append(errs, errors.New("quota.per_user_bytes must not be negative"))
+	}
+	switch s.Quota.Action {
+	case "stop", "truncate":
+	default:
+		errs = append(errs, fmt.Errorf("unknown quota action '%s'", s.Quota.Action))
+	}
+	if s.Capacity.MaxRunningJobs < 0 {
+		errs = append(errs, errors.New("capacity.max_running_jobs must not be negative"))
+	}
+	if s.Capacity.Headroom.MaxLoadPerCPU < 0 {
+		errs = append(errs, errors.New("capacity.headroom.max_load_per_cpu must not be negative"))
+	}
+	if s.Capacity.PreemptionGrace <= 0 {
+		errs = append(errs, errors.New("capacity.preemption_grace must be positive"))
+	}
+	if s.Shutdown.DrainTimeout < 0 {
+		errs = append(errs, errors.New("shutdown.drain_timeout must not be negative"))
+	}
+	if s.Shutdown.StopGrace <= 0 {
+		errs = append(errs, errors.New("shutdown.stop_grace must be positive"))
+	}
+	if s.Reaper.Subreaper && s.Reaper.SweepInterval <= 0 {
+		errs = append(errs, errors.New("reaper.sweep_interval must be positive"))
+	}
+	if len(s.Usage.Windows) == 0 {
+		errs = append(errs, errors.New("usage.windows must not be empty"))
+	}
+	for _, window := range s.Usage.Windows {
+		if window <= 0 {
+			errs = append(errs, errors.New("usage.windows must be positive"))
+			break
+		}
+	}
+	if s.Events.File != "" && !filepath.IsAbs(s.Events.File) {
+		errs = append(errs, errors.New("events.file must be an absolute path"))
+	}
+	if s.Events.Retention <= 0 {
+		errs = append(errs, errors.New("events.retention must be positive"))
+	}
+	exporterNames := make(map[string]bool, len(s.Events.Exporters))
+	for i, exporter := range s.Events.Exporters {
+		field := fmt.Sprintf("events.exporters[%d]", i)
+		if exporterNames[exporter.Name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate name '%s'", field, exporter.Name))
+		}
+		exporterNames[exporter.Name] = true
+		errs = append(errs, exporter.validate(field)...)
+	}
+	switch s.Store.Backend {
+	case "none":
+	case store.BackendBolt:
+		if s.Store.Path != "" && !filepath.IsAbs(s.Store.Path) {
+			errs = append(errs, errors.New("store.path must be an absolute path"))
+		}
+	case store.BackendPostgres:
+		if s.Store.DSN == "" {
+			errs = append(errs, errors.New("store.dsn is required by the postgres backend"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("store.backend must be none, bolt or postgres, not '%s'", s.Store.Backend))
+	}
+	if s.Store.HA.Enabled && s.Store.Backend != store.BackendPostgres {
+		errs = append(errs, errors.New("store.ha requires the postgres backend"))
+	}
+	if s.Store.HA.LeaseTTL <= 0 {
+		errs = append(errs, errors.New("store.ha.lease_ttl must be positive"))
+	}
+	for name, class := range s.RuntimeClasses {
+		errs = append(errs, class.validate(name, s.CgroupParent, s.Egress)...)
+	}
+	if slices.Contains(s.Admins, "") {
+		errs = append(errs, errors.New("admins must not be empty names"))
+	}
+	if _, err := s.PolicyRules(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := s.JobRedactions(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := s.EgressPolicies(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := s.FeatureSet(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := s.JobTemplates(); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, validateHooks("hooks.pre_start", s.Hooks.PreStart)...)
+	errs = append(errs, validateHooks("hooks.post_job", s.Hooks.PostJob)...)
+	if _, ok := s.RuntimeClasses[s.DefaultRuntimeClass]; s.DefaultRuntimeClass != "" && !ok {
+		errs = append(errs, fmt.Errorf("default_runtime_class '%s' is not defined", s.DefaultRuntimeClass))
+	}
+	switch s.Auth.Identity {
+	case "cn", "uri", "email":
+	default:
+		errs = append(errs, fmt.Errorf("unknown auth identity '%s'", s.Auth.Identity))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,525 @@
+package config_test
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/config"
+	"github.com/gopheryan/jobby/internal/features"
+	"github.com/gopheryan/jobby/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+address: 0.0.0.0:9443
+listeners:
+  - address: "[::1]:9443"
+    proxy_protocol:
+      enabled: true
+      trusted_proxies: [10.0.0.0/8, "fd00::1"]
+  - network: unix
+    address: /run/jobby/jobby.sock
+    tls:
+      ca_cert: /etc/jobby/mgmt-ca.crt
+auth:
+  identity: uri
+  uri_prefix: spiffe://jobby.local/user/
+  fallback_to_cn: true
+  job_tokens: true
+  credential_leases:
+    enabled: true
+    lease: 12h
+    grace: 30m
+  forwarded_client_cert:
+    trusted_proxies: [envoy]
+  failure_bans:
+    enabled: true
+    max_failures: 5
+  allowed_clients:
+    common_names: [envoy, alice]
+    uris: [spiffe://jobby.local/user/bob]
+    spki_pins: [47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=]
+retention:
+  default_ttl: 24h
+  max_ttl: 168h
+  allow_keep_forever: false
+output:
+  batch_max_delay: 50ms
+  rate_limit:
+    per_user: 1048576
+  sync:
+    mode: periodic
+    interval: 2s
+quota:
+  per_user_bytes: 1073741824
+encryption:
+  master_key_file: /etc/jobby/master.key
+redactions:
+  - pattern: 'AKIA[0-9A-Z]{16}'
+  - pattern: '(?i)(bearer\s+)\S+'
+    replacement: '${1}[TOKEN]'
+templates:
+  backup:
+    description: Back up one database
+    command: /usr/local/bin/backup
+    args: ["--db={{database}}", "--keep={{keep}}"]
+    params:
+      database:
+        pattern: '[a-z_]+'
+      keep:
+        type: int
+        min: 1
+        max: 30
+        default: "7"
+cgroup_parent: /sys/fs/cgroup/jobby
+default_runtime_class: small
+runtime_classes:
+  small:
+    timeout: 10m
+    rlimits:
+      nofile: 1024
+    cgroup:
+      memory_max: 268435456
+      cpus: 0.5
+    isolation:
+      network: true
+      egress_policy: internal
+egress:
+  uplink_subnet: 10.200.0.0/16
+  policies:
+    offline:
+      mode: loopback
+    internal:
+      mode: allowlist
+      allow:
+        - destination: 10.0.0.0/8
+        - destination: 192.168.1.10
+          port: 443
+capacity:
+  max_running_jobs: 16
+  headroom:
+    min_free_memory_bytes: 536870912
+    max_load_per_cpu: 1.5
+reaper:
+  subreaper: true
+  kill_on_exit: true
+shutdown:
+  drain_timeout: 2m
+hooks:
+  pre_start:
+    - name: fetch-dataset
+      url: http://datasets.internal/prepare
+  post_job:
+    - name: compress
+      command: [/usr/bin/gzip-output, --best]
+    - name: ticket
+      url: https://tickets.internal/jobby
+      timeout: 10s
+usage:
+  windows: [24h, 720h]
+  viewers: [finance]
+metrics:
+  address: localhost:9090
+  pprof_address: localhost:6060
+  tls: true
+scratch:
+  max_tmpfs_bytes: 1073741824
+events:
+  retention: 720h
+  exporters:
+    - name: siem
+      format: cef
+      network: tls
+      address: siem.internal:6514
+      ca_cert: /etc/jobby/siem-ca.crt
+store:
+  backend: postgres
+  dsn: postgres://jobby@db.internal/jobby
+  ha:
+    enabled: true
+    node: jobby-1
+policy:
+  - name: interns-run-python
+    when: user.startsWith("intern-")
+    require: rpc != "StartJob" || command == "/usr/bin/python3"
+admins: [ryan]
+features:
+  grpc_reflection: false
+`)
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.0.0.0:9443", cfg.Address)
+	tlsFiles := config.ListenerTLS{CACert: "ca/ca.crt", Cert: "server/server.crt", Key: "server/server.key"}
+	mgmtFiles := tlsFiles
+	mgmtFiles.CACert = "/etc/jobby/mgmt-ca.crt"
+	assert.Equal(t, []config.Listener{
+		{Network: "tcp", Address: "0.0.0.0:9443", TLS: tlsFiles},
+		{Network: "tcp", Address: "[::1]:9443", TLS: tlsFiles, ProxyProtocol: config.ProxyProtocol{
+			Enabled:        true,
+			TrustedProxies: []string{"10.0.0.0/8", "fd00::1"},
+		}},
+		{Network: "unix", Address: "/run/jobby/jobby.sock", TLS: mgmtFiles},
+	}, cfg.GRPCListeners())
+	prefixes, err := cfg.Listeners[0].ProxyProtocol.Prefixes()
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::1/128")}, prefixes)
+	assert.Equal(t, "uri", cfg.Auth.Identity)
+	assert.Equal(t, "spiffe://jobby.local/user/", cfg.Auth.URIPrefix)
+	assert.True(t, cfg.Auth.FallbackToCN)
+	assert.True(t, cfg.Auth.JobTokens)
+	assert.Equal(t, config.CredentialLeases{
+		Enabled:       true,
+		Lease:         12 * time.Hour,
+		Grace:         30 * time.Minute,
+		CheckInterval: time.Minute,
+	}, cfg.Auth.CredentialLeases)
+	assert.Equal(t, []string{"envoy"}, cfg.Auth.ForwardedClientCert.TrustedProxies)
+	assert.Equal(t, config.AllowedClients{
+		CommonNames: []string{"envoy", "alice"},
+		URIs:        []string{"spiffe://jobby.local/user/bob"},
+		SPKIPins:    []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+	}, cfg.Auth.AllowedClients)
+	assert.Equal(t, config.FailureBans{
+		Enabled:     true,
+		MaxFailures: 5,
+		Window:      time.Minute,
+		Ban:         time.Minute,
+		MaxBan:      time.Hour,
+	}, cfg.Auth.FailureBans)
+	assert.Equal(t, 24*time.Hour, cfg.Retention.DefaultTTL)
+	assert.Equal(t, 168*time.Hour, cfg.Retention.MaxTTL)
+	assert.False(t, cfg.Retention.AllowKeepForever)
+	assert.Equal(t, time.Minute, cfg.Retention.GCInterval)
+	assert.Equal(t, 50*time.Millisecond, cfg.Output.BatchMaxDelay)
+	assert.Equal(t, time.Second, cfg.Output.MaxLineHold)
+	assert.Equal(t, config.Default().Output.BatchMaxBytes, cfg.Output.BatchMaxBytes)
+	assert.Equal(t, config.RateLimit{PerUser: 1048576}, cfg.Output.RateLimit)
+	outputSync, err := cfg.Output.Sync.SyncPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, job.SyncPolicy{Mode: job.SyncPeriodic, Interval: 2 * time.Second}, outputSync)
+	assert.Equal(t, config.Quota{PerUserBytes: 1 << 30, Action: "stop"}, cfg.Quota)
+	assert.Equal(t, "/etc/jobby/master.key", cfg.Encryption.MasterKeyFile)
+	assert.Equal(t, []config.Redaction{
+		{Pattern: `AKIA[0-9A-Z]{16}`},
+		{Pattern: `(?i)(bearer\s+)\S+`, Replacement: "${1}[TOKEN]"},
+	}, cfg.Redactions)
+	redactions, err := cfg.JobRedactions()
+	require.NoError(t, err)
+	assert.Len(t, redactions, 2)
+	jobTemplates, err := cfg.JobTemplates()
+	require.NoError(t, err)
+	require.Contains(t, jobTemplates, "backup")
+	_, args, _, err := jobTemplates["backup"].Render(map[string]string{"database": "orders"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--db=orders", "--keep=7"}, args)
+	assert.Equal(t, "small", cfg.DefaultRuntimeClass)
+	assert.Equal(t, job.Limits{
+		Timeout: 10 * time.Minute,
+		Rlimits: []job.Rlimit{{Resource: unix.RLIMIT_NOFILE, Soft: 1024, Hard: 1024}},
+		Cgroup: &job.CgroupLimits{
+			Parent:    "/sys/fs/cgroup/jobby",
+			MemoryMax: 256 << 20,
+			CPUs:      0.5,
+		},
+		Isolation: job.Isolation{Network: true},
+	}, cfg.RuntimeClasses["small"].Limits(cfg.CgroupParent))
+	assert.Equal(t, "internal", cfg.RuntimeClasses["small"].Isolation.EgressPolicy)
+
+	policies, err := cfg.EgressPolicies()
+	require.NoError(t, err)
+	assert.Equal(t, job.Egress{Mode: job.EgressLoopback}, policies["offline"])
+	internal := policies["internal"]
+	assert.Equal(t, job.EgressAllowlist, internal.Mode)
+	assert.NotNil(t, internal.Uplinks)
+	assert.Equal(t, []job.EgressRule{
+		{Prefix: netip.MustParsePrefix("10.0.0.0/8")},
+		{Prefix: netip.MustParsePrefix("192.168.1.10/32"), Port: 443},
+	}, internal.Allow)
+
+	assert.Equal(t, config.Usage{
+		Windows: []time.Duration{24 * time.Hour, 720 * time.Hour},
+		Viewers: []string{"finance"},
+	}, cfg.Usage)
+	assert.Equal(t, "localhost:9090", cfg.Metrics.Address)
+	assert.True(t, cfg.Metrics.TLS)
+	assert.Equal(t, []config.MetricsListener{
+		{Address: "localhost:6060", Pprof: true},
+		{Address: "localhost:9090", Metrics: true, Health: true},
+	}, cfg.Metrics.Listeners())
+	assert.Equal(t, config.Events{
+		Retention: 720 * time.Hour,
+		Exporters: []config.EventExporter{{
+			Name:    "siem",
+			Format:  "cef",
+			Network: "tls",
+			Address: "siem.internal:6514",
+			CACert:  "/etc/jobby/siem-ca.crt",
+		}},
+	}, cfg.Events)
+	assert.Equal(t, filepath.Join(cfg.OutputDir, "events.jsonl"), cfg.EventsFile())
+	assert.Equal(t, "postgres", cfg.Store.Backend)
+	assert.Equal(t, "postgres://jobby@db.internal/jobby", cfg.StoreLocation())
+	assert.Equal(t, config.HA{Enabled: true, Node: "jobby-1", LeaseTTL: 15 * time.Second}, cfg.Store.HA)
+	assert.Equal(t, config.Capacity{
+		MaxRunningJobs:  16,
+		PreemptionGrace: 10 * time.Second,
+		Headroom:        config.Headroom{MinFreeMemory: 512 << 20, MaxLoadPerCPU: 1.5},
+	}, cfg.Capacity)
+	assert.Equal(t, config.Reaper{Subreaper: true, SweepInterval: time.Second, KillOnExit: true}, cfg.Reaper)
+	assert.Equal(t, config.Shutdown{DrainTimeout: 2 * time.Minute, StopGrace: 10 * time.Second}, cfg.Shutdown)
+	assert.Equal(t, []config.Hook{
+		{Name: "compress", Command: []string{"/usr/bin/gzip-output", "--best"}},
+		{Name: "ticket", URL: "https://tickets.internal/jobby", Timeout: 10 * time.Second},
+	}, cfg.Hooks.PostJob)
+	assert.Equal(t, uint64(1<<30), cfg.Scratch.MaxTmpfsBytes)
+	assert.Equal(t, []config.Hook{{Name: "fetch-dataset", URL: "http://datasets.internal/prepare"}}, cfg.Hooks.PreStart)
+	assert.Equal(t, []config.PolicyRule{{
+		Name:    "interns-run-python",
+		When:    `user.startsWith("intern-")`,
+		Require: `rpc != "StartJob" || command == "/usr/bin/python3"`,
+	}}, cfg.Policy)
+	rules, err := cfg.PolicyRules()
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, []string{"ryan"}, cfg.Admins)
+	featureSet, err := cfg.FeatureSet()
+	require.NoError(t, err)
+	assert.False(t, featureSet.Enabled(features.GRPCReflection))
+	assert.True(t, featureSet.Enabled(features.V2API))
+
+	// Unspecified values keep their defaults
+	assert.Equal(t, config.Default().TLS, cfg.TLS)
+	assert.Equal(t, config.Default().OutputDir, cfg.OutputDir)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := config.Load(writeConfig(t, "auth:\n  identity: dn\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "address: [not, a, string"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "tls:\n  acme:\n    enabled: true\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, `
+tls:
+  spiffe:
+    enabled: true
+  acme:
+    enabled: true
+    domains: [jobby.example.com]
+    cache_dir: /var/lib/jobby/acme
+`))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "auth:\n  anonymous: true\ntls:\n  spiffe:\n    enabled: true\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "auth:\n  job_tokens: true\ntls:\n  spiffe:\n    enabled: true\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "auth:\n  credential_leases:\n    enabled: true\n    grace: -1m\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "auth:\n  credential_leases:\n    enabled: true\n    check_interval: 0s\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "hooks:\n  post_job:\n    - name: both\n      command: [/bin/true]\n      url: https://hooks.example.com\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "hooks:\n  post_job:\n    - name: relative\n      command: [gzip]\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "hooks:\n  post_job:\n    - name: a\n      url: https://hooks.example.com\n    - name: a\n      url: https://hooks.example.com\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "capacity:\n  headroom:\n    max_load_per_cpu: -1\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "hooks:\n  pre_start:\n    - name: nothing\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "hooks:\n  post_job:\n    - name: ftp\n      url: ftp://hooks.example.com\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "retention:\n  default_ttl: 2h\n  max_ttl: 1h\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "retention:\n  allow_keep_forever: false\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "retention:\n  delete_grace_period: -1h\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "output:\n  batch_max_bytes: 0\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "output:\n  rate_limit:\n    global: -1\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "output:\n  sync:\n    mode: always\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "output:\n  sync:\n    mode: periodic\n    interval: 0s\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "reaper:\n  subreaper: true\n  sweep_interval: 0s\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "quota:\n  action: delete\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "redactions:\n  - pattern: '(unclosed'\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "redactions:\n  - replacement: x\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "templates:\n  echo:\n    command: /bin/echo\n    args: ['{{text}}']\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "admins: ['']\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "features:\n  teleportation: true\n"))
+	assert.Error(t, err)
+
+	for _, rule := range []string{
+		"policy:\n  - require: 'true'\n",
+		"policy:\n  - name: missing-require\n",
+		"policy:\n  - name: syntax\n    require: 'user =='\n",
+		"policy:\n  - name: not-bool\n    require: 'user'\n",
+		"policy:\n  - name: unknown-variable\n    when: 'job == \"x\"'\n    require: 'true'\n",
+	} {
+		_, err = config.Load(writeConfig(t, rule))
+		assert.Error(t, err, rule)
+	}
+
+	for _, egress := range []string{
+		"egress:\n  policies:\n    open:\n      mode: everything\n",
+		// Allowlists need somewhere to connect jobs to
+		"egress:\n  policies:\n    open:\n      mode: allowlist\n",
+		"egress:\n  uplink_subnet: 10.0.0.0/31\n",
+		"egress:\n  uplink_subnet: 10.200.0.0/16\n  policies:\n    open:\n      mode: allowlist\n      allow:\n        - destination: example.com\n",
+		"runtime_classes:\n  small:\n    isolation:\n      network: true\n      egress_policy: missing\n",
+		"egress:\n  policies:\n    offline:\n      mode: loopback\nruntime_classes:\n  small:\n    isolation:\n      egress_policy: offline\n",
+	} {
+		_, err = config.Load(writeConfig(t, egress))
+		assert.Error(t, err, egress)
+	}
+
+	_, err = config.Load(writeConfig(t, "capacity:\n  max_running_jobs: -1\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "capacity:\n  preemption_grace: 0s\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "shutdown:\n  drain_timeout: -1s\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "shutdown:\n  stop_grace: 0s\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "usage:\n  windows: []\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "usage:\n  windows: [1h, -1h]\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "events:\n  file: events.jsonl\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "events:\n  retention: 0s\n"))
+	assert.Error(t, err)
+
+	for _, exporter := range []string{
+		"{name: siem, format: leef, network: tcp, address: 'siem:514'}",
+		"{name: siem, format: syslog, network: tcp, address: siem}",
+		"{name: siem, format: syslog, network: udp, address: 'siem:514', ca_cert: /ca.crt}",
+		"{name: siem, format: syslog, network: tls, address: 'siem:6514', cert: /client.crt}",
+		"{name: siem, format: syslog, network: udp, address: 'siem:514', facility: 24}",
+		"{format: syslog, network: udp, address: 'siem:514'}",
+	} {
+		_, err = config.Load(writeConfig(t, "events:\n  exporters:\n    - "+exporter+"\n"))
+		assert.Error(t, err, exporter)
+	}
+
+	for _, listeners := range []string{
+		"address: ''\n",
+		"listeners: [{address: localhost}]\n",
+		"listeners: [{network: udp, address: 'localhost:9443'}]\n",
+		"listeners: [{network: unix, address: jobby.sock}]\n",
+		"listeners: [{address: 'localhost:8443'}]\n",
+		"listeners: [{address: 'localhost:9443', tls: {cert: /server.crt}}]\n",
+		"listeners: [{address: 'localhost:9443', tls: {ca_cert: /ca.crt}}]\ntls:\n  spiffe:\n    enabled: true\n",
+		"listeners: [{address: 'localhost:9443'}]\nmetrics:\n  address: localhost:9443\n",
+		"listeners: [{address: 'localhost:9443', proxy_protocol: {enabled: true}}]\n",
+		"listeners: [{address: 'localhost:9443', proxy_protocol: {enabled: true, trusted_proxies: [lb.internal]}}]\n",
+		"auth:\n  forwarded_client_cert:\n    trusted_proxies: [envoy]\ntls:\n  spiffe:\n    enabled: true\n",
+		"auth:\n  allowed_clients:\n    spki_pins: [abc]\n",
+		"auth:\n  failure_bans:\n    enabled: true\n    window: 0s\n",
+		"auth:\n  failure_bans:\n    enabled: true\n    max_ban: 30s\n",
+		"auth:\n  allowed_clients:\n    spki_pins: [YWJj]\n",
+	} {
+		_, err = config.Load(writeConfig(t, listeners))
+		assert.Error(t, err, listeners)
+	}
+	_, err = config.Load(writeConfig(t, "address: ''\nlisteners: [{network: unix, address: /run/jobby.sock, proxy_protocol: {enabled: true}}]\n"))
+	assert.NoError(t, err)
+
+	for _, metrics := range []string{
+		"{address: localhost}",
+		"{pprof_address: 'localhost:8443'}",
+		"{tls: true}",
+	} {
+		_, err = config.Load(writeConfig(t, "address: localhost:8443\nmetrics: "+metrics+"\n"))
+		assert.Error(t, err, metrics)
+	}
+
+	for _, storeConfig := range []string{
+		"store:\n  backend: sqlite\n",
+		"store:\n  backend: bolt\n  path: jobby.db\n",
+		"store:\n  backend: postgres\n",
+		"store:\n  backend: bolt\n  ha:\n    enabled: true\n",
+		"store:\n  backend: postgres\n  dsn: postgres:///jobby\n  ha:\n    lease_ttl: 0s\n",
+	} {
+		_, err = config.Load(writeConfig(t, storeConfig))
+		assert.Error(t, err, storeConfig)
+	}
+
+	_, err = config.Load(writeConfig(t, "default_runtime_class: missing\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "runtime_classes:\n  small:\n    rlimits:\n      bogus: 1\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "output_dir: jobby/output\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "output_dir: /etc/jobby\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(writeConfig(t, "output_dir: /var/../proc\n"))
+	assert.Error(t, err)
+
+	// Cgroup limits without cgroup_parent
+	_, err = config.Load(writeConfig(t, "runtime_classes:\n  small:\n    cgroup:\n      pids_max: 10\n"))
+	assert.Error(t, err)
+
+	_, err = config.Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
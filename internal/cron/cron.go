@@ -0,0 +1,188 @@
+// Package cron parses standard five field cron expressions (minute, hour,
+// day of month, month, day of week) and finds the times they match
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression is a parsed cron expression. Each field is a set of the
+// values it matches, one bit per value
+type Expression struct {
+	minute, hour, dom, month, dow uint64
+	// Whether the day fields were restricted. Like cron, a day matches if
+	// either restricted field does
+	domRestricted, dowRestricted bool
+}
+
+// How far Next looks before deciding an expression never matches (ex: "0 0 30 2 *")
+const searchYears = 5
+
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// 7 is Sunday too
+	dowField = field{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse an expression like "*/15 9-17 * * mon-fri". Fields may be '*',
+// numbers, ranges and lists of them, each with an optional step ("/n").
+// Months and days of the week may be named by their first three letters.
+// "@daily" and the other usual shortcuts stand for their expressions
+func Parse(spec string) (Expression, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := shortcuts[strings.ToLower(spec)]; ok {
+		spec = expanded
+	} else if strings.HasPrefix(spec, "@") {
+		return Expression{}, fmt.Errorf("unknown shortcut '%s'", spec)
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Expression{}, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	var e Expression
+	var err error
+	if e.minute, err = minuteField.parse(fields[0]); err != nil {
+		return Expression{}, err
+	}
+	if e.hour, err = hourField.parse(fields[1]); err != nil {
+		return Expression{}, err
+	}
+	if e.dom, err = domField.parse(fields[2]); err != nil {
+		return Expression{}, err
+	}
+	if e.month, err = monthField.parse(fields[3]); err != nil {
+		return Expression{}, err
+	}
+	if e.dow, err = dowField.parse(fields[4]); err != nil {
+		return Expression{}, err
+	}
+	if e.dow&(1<<7) != 0 {
+		e.dow |= 1
+	}
+	e.domRestricted = !strings.HasPrefix(fields[2], "*")
+	e.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return e, nil
+}
+
+// The set of values 'text' matches
+func (f field) parse(text string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(text, ",") {
+		bounds, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepText)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step '%s' in %s field", stepText, f.name)
+			}
+		}
+
+		var low, high int
+		if bounds == "*" {
+			low, high = f.min, f.max
+		} else {
+			lowText, highText, isRange := strings.Cut(bounds, "-")
+			var err error
+			if low, err = f.value(lowText); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(highText); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/15" means every 15 starting at 5
+				high = f.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("range %s in %s field ends before it starts", bounds, f.name)
+			}
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (f field) value(text string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s'", f.name, text)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s %d is out of range (%d-%d)", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next is the first time after 't' the expression matches, in t's
+// location. Zero if it doesn't match in the next few years
+func (e Expression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+	for t.Before(limit) {
+		if e.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !e.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if e.hour&(1<<t.Hour()) == 0 {
+			// Not time.Date, which can land on the same hour again when
+			// clocks go back
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+		if e.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (e Expression) dayMatches(t time.Time) bool {
+	dom := e.dom&(1<<t.Day()) != 0
+	dow := e.dow&(1<<int(t.Weekday())) != 0
+	if e.domRestricted && e.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
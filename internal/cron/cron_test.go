@@ -0,0 +1,67 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/cron"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	// A Wednesday
+	start := time.Date(2025, time.January, 15, 10, 30, 20, 0, time.UTC)
+	for _, tc := range []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.January, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, time.January, 16, 3, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 0 * * sat,7", time.Date(2025, time.January, 18, 0, 0, 0, 0, time.UTC)},
+		{"5/20 10 * * *", time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 0 1 mar *", time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Either day field matching is enough when both are restricted
+		{"0 0 20 * fri", time.Date(2025, time.January, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		t.Run(tc.spec, func(tt *testing.T) {
+			e, err := cron.Parse(tc.spec)
+			require.NoError(tt, err)
+			assert.Equal(tt, tc.next, e.Next(start))
+		})
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+	e, err := cron.Parse("0 * * * *")
+	require.NoError(t, err)
+	start := time.Date(2025, time.January, 15, 10, 30, 0, 0, kolkata)
+	assert.Equal(t, time.Date(2025, time.January, 15, 11, 0, 0, 0, kolkata), e.Next(start))
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@sometimes",
+	} {
+		_, err := cron.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
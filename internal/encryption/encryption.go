@@ -0,0 +1,305 @@
+// Package encryption encrypts job output files at rest.
+//
+// Each job gets a random data key, which is stored wrapped (encrypted) by a
+// KeyWrapper holding the server's master key. Each output file is encrypted
+// with its own key derived from the data key and the file's name, so nonces
+// only need to be unique within a file.
+//
+// Files are a sequence of records, one per write:
+//
+//	[4 byte big endian ciphertext length][AES-GCM ciphertext and tag]
+//
+// The nonce of a record is its index in the file, which also keeps records
+// from being reordered or dropped from the middle without detection
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Size of data and master keys. Selects AES-256
+const KeySize = 32
+
+// Bytes of overhead in each record
+const (
+	headerSize = 4
+	tagSize    = 16
+)
+
+// Largest plaintext in a single record. Bigger writes are split up
+const maxRecordSize = 64 * 1024
+
+// KeyWrapper encrypts data keys for storage. Implemented by the local master key
+// and could be implemented by a KMS client
+type KeyWrapper interface {
+	Wrap(dataKey []byte) ([]byte, error)
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// NewDataKey generates a random data key for a job
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error generating data key: %w", err)
+	}
+	return key, nil
+}
+
+// Wraps data keys with a master key held in memory
+type localWrapper struct {
+	aead cipher.AEAD
+}
+
+// NewLocalWrapper wraps data keys with AES-GCM under 'masterKey'
+func NewLocalWrapper(masterKey []byte) (KeyWrapper, error) {
+	if len(masterKey) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes", KeySize)
+	}
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &localWrapper{aead: aead}, nil
+}
+
+// Wrapped keys are the random nonce followed by the ciphertext
+func (l *localWrapper) Wrap(dataKey []byte) ([]byte, error) {
+	nonce := make([]byte, l.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+	return l.aead.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+func (l *localWrapper) Unwrap(wrapped []byte) ([]byte, error) {
+	if len(wrapped) < l.aead.NonceSize() {
+		return nil, errors.New("wrapped key is too short")
+	}
+	nonce, ciphertext := wrapped[:l.aead.NonceSize()], wrapped[l.aead.NonceSize():]
+	dataKey, err := l.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// The key of a single file
+func fileAEAD(dataKey []byte, name string) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, dataKey, nil, "jobby output "+name, KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving file key: %w", err)
+	}
+	return newAEAD(key)
+}
+
+func recordNonce(aead cipher.AEAD, index uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], index)
+	return nonce
+}
+
+// Writer encrypts each write as one or more records
+type Writer struct {
+	dst   io.Writer
+	aead  cipher.AEAD
+	index uint64
+}
+
+// NewWriter encrypts output written to 'dst', which must be a new (empty) file named 'name'
+func NewWriter(dst io.Writer, dataKey []byte, name string) (*Writer, error) {
+	aead, err := fileAEAD(dataKey, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{dst: dst, aead: aead}, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), maxRecordSize)]
+		// Header and ciphertext go out in a single write so
+		// readers never see a header without its record following
+		record := make([]byte, headerSize, headerSize+len(chunk)+tagSize)
+		record = w.aead.Seal(record, recordNonce(w.aead, w.index), chunk, nil)
+		binary.BigEndian.PutUint32(record, uint32(len(record)-headerSize))
+		if _, err := w.dst.Write(record); err != nil {
+			return written, err
+		}
+		w.index++
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// Reader decrypts a file written by Writer
+type Reader struct {
+	src   io.Reader
+	aead  cipher.AEAD
+	index uint64
+	// Decrypted data not yet returned to the caller
+	pending []byte
+}
+
+// NewReader decrypts the file 'name' read from 'src'. A record split across
+// reads is waited for, so 'src' may be a file that's still being written
+func NewReader(src io.Reader, dataKey []byte, name string) (*Reader, error) {
+	aead, err := fileAEAD(dataKey, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{src: src, aead: aead}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Decrypt the next record into 'pending'
+func (r *Reader) next() error {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r.src, header[:]); err != nil {
+		// A clean EOF between records is the end of the file
+		return err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length < tagSize || length > maxRecordSize+tagSize {
+		return fmt.Errorf("invalid record length %d", length)
+	}
+	ciphertext := make([]byte, length)
+	if _, err := io.ReadFull(r.src, ciphertext); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("error reading record: %w", err)
+	}
+	plaintext, err := r.aead.Open(ciphertext[:0], recordNonce(r.aead, r.index), ciphertext, nil)
+	if err != nil {
+		return fmt.Errorf("error decrypting record %d: %w", r.index, err)
+	}
+	r.index++
+	r.pending = plaintext
+	return nil
+}
+
+// Pause passes through to the source so flow control still works
+// when reading a live file (see streamer.LiveFileStreamer)
+func (r *Reader) Pause() error {
+	if p, ok := r.src.(interface{ Pause() error }); ok {
+		return p.Pause()
+	}
+	return nil
+}
+
+// Close closes the source if it's closeable
+func (r *Reader) Close() error {
+	if c, ok := r.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Location of a complete record in a file
+type record struct {
+	index uint64
+	// Offset of the ciphertext (after the header)
+	offset int64
+	length int64
+}
+
+// Find the complete records in a file by walking their headers.
+// A partially written record at the end is ignored
+func scanRecords(f io.ReaderAt, fileSize int64) ([]record, error) {
+	var records []record
+	var offset int64
+	var header [headerSize]byte
+	for index := uint64(0); offset+headerSize <= fileSize; index++ {
+		if _, err := f.ReadAt(header[:], offset); err != nil {
+			return nil, fmt.Errorf("error reading record header: %w", err)
+		}
+		length := int64(binary.BigEndian.Uint32(header[:]))
+		if length < tagSize || length > maxRecordSize+tagSize {
+			return nil, fmt.Errorf("invalid record length %d", length)
+		}
+		if offset+headerSize+length > fileSize {
+			break
+		}
+		records = append(records, record{index: index, offset: offset + headerSize, length: length})
+		offset += headerSize + length
+	}
+	return records, nil
+}
+
+// PlaintextSize reports how many bytes of output an encrypted file holds
+func PlaintextSize(f io.ReaderAt, fileSize int64) (int64, error) {
+	records, err := scanRecords(f, fileSize)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, rec := range records {
+		size += rec.length - tagSize
+	}
+	return size, nil
+}
+
+// ReadTail decrypts up to the last 'n' bytes of an encrypted file
+func ReadTail(f io.ReaderAt, fileSize int64, dataKey []byte, name string, n int64) ([]byte, error) {
+	aead, err := fileAEAD(dataKey, name)
+	if err != nil {
+		return nil, err
+	}
+	records, err := scanRecords(f, fileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only decrypt the records that cover the tail
+	var covered int64
+	first := len(records)
+	for first > 0 && covered < n {
+		first--
+		covered += records[first].length - tagSize
+	}
+
+	var tail []byte
+	for _, rec := range records[first:] {
+		ciphertext := make([]byte, rec.length)
+		if _, err := f.ReadAt(ciphertext, rec.offset); err != nil {
+			return nil, fmt.Errorf("error reading record: %w", err)
+		}
+		plaintext, err := aead.Open(ciphertext[:0], recordNonce(aead, rec.index), ciphertext, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting record %d: %w", rec.index, err)
+		}
+		tail = append(tail, plaintext...)
+	}
+	if int64(len(tail)) > n {
+		tail = tail[int64(len(tail))-n:]
+	}
+	return tail, nil
+}
@@ -0,0 +1,157 @@
+package encryption_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomBytes(t *testing.T, n int) []byte {
+	data := make([]byte, n)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	return data
+}
+
+// Encrypts each of 'writes' as a separate write
+func encrypt(t *testing.T, key []byte, name string, writes ...[]byte) []byte {
+	var buf bytes.Buffer
+	w, err := encryption.NewWriter(&buf, key, name)
+	require.NoError(t, err)
+	for _, p := range writes {
+		n, err := w.Write(p)
+		require.NoError(t, err)
+		require.Equal(t, len(p), n)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	key, err := encryption.NewDataKey()
+	require.NoError(t, err)
+
+	// The last write is bigger than a single record
+	writes := [][]byte{[]byte("stdout 1\n"), []byte("stdout 2\n"), randomBytes(t, 200*1024)}
+	var plaintext []byte
+	for _, p := range writes {
+		plaintext = append(plaintext, p...)
+	}
+	ciphertext := encrypt(t, key, "stdout", writes...)
+	assert.NotContains(t, string(ciphertext), "stdout 1")
+
+	t.Run("read", func(tt *testing.T) {
+		r, err := encryption.NewReader(bytes.NewReader(ciphertext), key, "stdout")
+		require.NoError(tt, err)
+		data, err := io.ReadAll(r)
+		require.NoError(tt, err)
+		assert.Equal(tt, plaintext, data)
+	})
+
+	t.Run("size", func(tt *testing.T) {
+		size, err := encryption.PlaintextSize(bytes.NewReader(ciphertext), int64(len(ciphertext)))
+		require.NoError(tt, err)
+		assert.Equal(tt, int64(len(plaintext)), size)
+	})
+
+	t.Run("tail", func(tt *testing.T) {
+		for _, n := range []int64{0, 5, 100 * 1024, int64(len(plaintext)), int64(len(plaintext)) + 10} {
+			tail, err := encryption.ReadTail(bytes.NewReader(ciphertext), int64(len(ciphertext)), key, "stdout", n)
+			require.NoError(tt, err)
+			want := plaintext[len(plaintext)-int(min(n, int64(len(plaintext)))):]
+			assert.Equal(tt, string(want), string(tail), n)
+		}
+	})
+
+	t.Run("partial-record", func(tt *testing.T) {
+		// A file caught mid-write only reports its complete records.
+		// The last record holds what's left of the big write after 64KiB records
+		lastRecord := (200 * 1024) % (64 * 1024)
+		cut := len(ciphertext) - 10
+		size, err := encryption.PlaintextSize(bytes.NewReader(ciphertext), int64(cut))
+		require.NoError(tt, err)
+		assert.Equal(tt, int64(len(plaintext)-lastRecord), size)
+
+		r, err := encryption.NewReader(bytes.NewReader(ciphertext[:cut]), key, "stdout")
+		require.NoError(tt, err)
+		_, err = io.ReadAll(r)
+		assert.ErrorIs(tt, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("wrong-name", func(tt *testing.T) {
+		// Each file has its own key
+		r, err := encryption.NewReader(bytes.NewReader(ciphertext), key, "stderr")
+		require.NoError(tt, err)
+		_, err = io.ReadAll(r)
+		assert.Error(tt, err)
+	})
+}
+
+func TestTamper(t *testing.T) {
+	key, err := encryption.NewDataKey()
+	require.NoError(t, err)
+	ciphertext := encrypt(t, key, "stdout", []byte("first\n"), []byte("other\n"))
+	recordSize := len(ciphertext) / 2
+
+	readAll := func(data []byte) error {
+		r, err := encryption.NewReader(bytes.NewReader(data), key, "stdout")
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		return err
+	}
+	require.NoError(t, readAll(ciphertext))
+
+	t.Run("flipped-bit", func(tt *testing.T) {
+		tampered := bytes.Clone(ciphertext)
+		tampered[recordSize-1] ^= 1
+		assert.Error(tt, readAll(tampered))
+	})
+
+	t.Run("reordered", func(tt *testing.T) {
+		reordered := append(bytes.Clone(ciphertext[recordSize:]), ciphertext[:recordSize]...)
+		assert.Error(tt, readAll(reordered))
+	})
+
+	t.Run("dropped", func(tt *testing.T) {
+		assert.Error(tt, readAll(ciphertext[recordSize:]))
+	})
+
+	t.Run("bad-length", func(tt *testing.T) {
+		tampered := bytes.Clone(ciphertext)
+		binary.BigEndian.PutUint32(tampered, 1<<30)
+		assert.Error(tt, readAll(tampered))
+		_, err := encryption.PlaintextSize(bytes.NewReader(tampered), int64(len(tampered)))
+		assert.Error(tt, err)
+	})
+}
+
+func TestLocalWrapper(t *testing.T) {
+	_, err := encryption.NewLocalWrapper(randomBytes(t, 16))
+	assert.Error(t, err)
+
+	wrapper, err := encryption.NewLocalWrapper(randomBytes(t, encryption.KeySize))
+	require.NoError(t, err)
+	dataKey, err := encryption.NewDataKey()
+	require.NoError(t, err)
+
+	wrapped, err := wrapper.Wrap(dataKey)
+	require.NoError(t, err)
+	assert.NotContains(t, string(wrapped), string(dataKey))
+
+	unwrapped, err := wrapper.Unwrap(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	other, err := encryption.NewLocalWrapper(randomBytes(t, encryption.KeySize))
+	require.NoError(t, err)
+	_, err = other.Unwrap(wrapped)
+	assert.Error(t, err)
+
+	_, err = wrapper.Unwrap(wrapped[:4])
+	assert.Error(t, err)
+}
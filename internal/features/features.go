@@ -0,0 +1,147 @@
+// Package features is the registry of feature flags: capabilities the server
+// can run with or without, switched on or off in the config's 'features'.
+//
+// Flags may gate whole RPCs, which the interceptors answer with Unimplemented
+// while the flag is off. Experimental RPCs ship dark that way: their flag is
+// off unless a deployment turns it on
+package features
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Flag string
+
+// Described in the registry below
+const (
+	V2API              Flag = "v2_api"
+	GRPCReflection     Flag = "grpc_reflection"
+	ServerLogStreaming Flag = "server_log_streaming"
+	ProcessAdoption    Flag = "process_adoption"
+	GRPCAdmin          Flag = "grpc_admin"
+)
+
+type definition struct {
+	description string
+	// Whether the flag is on when the config doesn't say
+	enabled bool
+	// Not ready for general use. Off by default
+	experimental bool
+	// RPCs (ex: "StreamServerLogs") that are only served while the flag is
+	// on, in every API version
+	rpcs []string
+}
+
+var registry = map[Flag]definition{
+	V2API:          {description: "Serve the jobmanager.v2 API alongside the original one", enabled: true},
+	GRPCReflection: {description: "Let clients list the server's services (ex: for grpcurl)", enabled: true},
+	ServerLogStreaming: {
+		description: "Let admins follow the server's log",
+		enabled:     true,
+		rpcs:        []string{"StreamServerLogs"},
+	},
+	ProcessAdoption: {
+		description:  "Let admins register processes the server didn't start as jobs",
+		experimental: true,
+		rpcs:         []string{"AdoptProcess"},
+	},
+	GRPCAdmin: {description: "Let admins inspect the server's connections and streams with gRPC's admin services (ex: channelz)"},
+}
+
+// Flags gating each RPC
+var gates = func() map[string][]Flag {
+	gates := map[string][]Flag{}
+	for flag, definition := range registry {
+		for _, rpc := range definition.rpcs {
+			gates[rpc] = append(gates[rpc], flag)
+		}
+	}
+	return gates
+}()
+
+// Set is whether each flag is on. The zero value leaves every flag at its default
+type Set struct {
+	overrides map[Flag]bool
+}
+
+// New switches the named flags on or off. Fails on flags that don't exist
+func New(overrides map[string]bool) (Set, error) {
+	set := Set{overrides: make(map[Flag]bool, len(overrides))}
+	for name, enabled := range overrides {
+		if _, ok := registry[Flag(name)]; !ok {
+			return Set{}, fmt.Errorf("unknown feature '%s'", name)
+		}
+		set.overrides[Flag(name)] = enabled
+	}
+	return set, nil
+}
+
+// Enabled reports whether 'flag' is on. Flags that don't exist never are
+func (s Set) Enabled(flag Flag) bool {
+	if enabled, ok := s.overrides[flag]; ok {
+		return enabled
+	}
+	return registry[flag].enabled
+}
+
+type State struct {
+	Flag         Flag
+	Description  string
+	Enabled      bool
+	Experimental bool
+	// RPCs the flag gates
+	RPCs []string
+}
+
+// All is the state of every flag, ordered by name
+func (s Set) All() []State {
+	states := make([]State, 0, len(registry))
+	for _, flag := range slices.Sorted(maps.Keys(registry)) {
+		definition := registry[flag]
+		states = append(states, State{
+			Flag:         flag,
+			Description:  definition.description,
+			Enabled:      s.Enabled(flag),
+			Experimental: definition.experimental,
+			RPCs:         slices.Clone(definition.rpcs),
+		})
+	}
+	return states
+}
+
+// Check fails with Unimplemented if 'fullMethod' (ex: "/jobby.JobManager/StreamServerLogs")
+// is gated by a flag that's off. Callers see the same as they would if the
+// server didn't have the RPC at all
+func (s Set) Check(fullMethod string) error {
+	rpc := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for _, flag := range gates[rpc] {
+		if !s.Enabled(flag) {
+			return status.Errorf(codes.Unimplemented, "%s is not enabled on this server (feature '%s')", rpc, flag)
+		}
+	}
+	return nil
+}
+
+// UnaryInterceptor refuses RPCs gated by flags that are off
+func (s Set) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	if err := s.Check(info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor is UnaryInterceptor for streaming RPCs
+func (s Set) StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	if err := s.Check(info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
@@ -0,0 +1,80 @@
+package features_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/features"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSet(t *testing.T) {
+	// Everything's at its default
+	var defaults features.Set
+	assert.True(t, defaults.Enabled(features.V2API))
+	assert.False(t, defaults.Enabled("teleportation"))
+
+	set, err := features.New(map[string]bool{"v2_api": false})
+	require.NoError(t, err)
+	assert.False(t, set.Enabled(features.V2API))
+	assert.True(t, set.Enabled(features.GRPCReflection))
+
+	all := set.All()
+	require.Len(t, all, 5)
+	assert.Equal(t, features.GRPCAdmin, all[0].Flag)
+	assert.False(t, all[0].Enabled)
+	assert.False(t, all[0].Experimental)
+	assert.Equal(t, features.GRPCReflection, all[1].Flag)
+	assert.True(t, all[1].Enabled)
+	assert.Equal(t, features.ProcessAdoption, all[2].Flag)
+	assert.True(t, all[2].Experimental)
+	assert.Equal(t, features.ServerLogStreaming, all[3].Flag)
+	assert.Equal(t, []string{"StreamServerLogs"}, all[3].RPCs)
+	assert.Equal(t, features.V2API, all[4].Flag)
+	assert.False(t, all[4].Enabled)
+	assert.NotEmpty(t, all[4].Description)
+
+	_, err = features.New(map[string]bool{"teleportation": true})
+	assert.Error(t, err)
+
+	// Experimental flags ship dark
+	for _, state := range defaults.All() {
+		if state.Experimental {
+			assert.False(t, state.Enabled, state.Flag)
+		}
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	ctx := context.Background()
+	off, err := features.New(map[string]bool{"server_log_streaming": false})
+	require.NoError(t, err)
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+
+	_, err = off.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/StreamServerLogs"}, handler)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	// Same RPC in the v2 API
+	err = off.StreamInterceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/jobmanager.v2.JobManager/StreamServerLogs"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			called = true
+			return nil
+		})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	assert.False(t, called)
+
+	// Ungated RPCs go through
+	_, err = off.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/GetStatus"}, handler)
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	var defaults features.Set
+	assert.NoError(t, defaults.Check("/jobby.JobManager/StreamServerLogs"))
+}
@@ -0,0 +1,62 @@
+// Package jobid validates job IDs, which are UUIDs sent either as their
+// raw 16 bytes or in canonical text form (ex: 9b2f2c4e-7c1e-4c52-a1a4-3f0e8f1d6b27)
+package jobid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Length of the canonical text form
+const textLength = 36
+
+// Parse a job ID in canonical text form. The other forms uuid.Parse
+// accepts (braces, urn prefix, no hyphens) are rejected so every
+// client spells a job ID the same way. Hex digits may be either case
+func Parse(text string) (uuid.UUID, error) {
+	if len(text) != textLength {
+		return uuid.UUID{}, fmt.Errorf("job id must be a %d character UUID", textLength)
+	}
+	id, err := uuid.Parse(text)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid job id: %w", err)
+	}
+	return id, nil
+}
+
+// FromBytes parses a job ID sent as raw bytes
+func FromBytes(raw []byte) (uuid.UUID, error) {
+	id, err := uuid.FromBytes(raw)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid job id: %w", err)
+	}
+	return id, nil
+}
+
+// Resolve a job ID that may be sent in either form. At least one
+// must be set, and if both are they must be the same ID
+func Resolve(raw []byte, text string) (uuid.UUID, error) {
+	switch {
+	case len(raw) == 0 && text == "":
+		return uuid.UUID{}, errors.New("job id must not be empty")
+	case len(raw) == 0:
+		return Parse(text)
+	case text == "":
+		return FromBytes(raw)
+	}
+
+	fromRaw, err := FromBytes(raw)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	fromText, err := Parse(text)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if fromRaw != fromText {
+		return uuid.UUID{}, errors.New("job id and its text form don't match")
+	}
+	return fromRaw, nil
+}
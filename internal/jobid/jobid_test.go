@@ -0,0 +1,65 @@
+package jobid_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	id := uuid.New()
+
+	parsed, err := jobid.Parse(id.String())
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+
+	parsed, err = jobid.Parse(strings.ToUpper(id.String()))
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+
+	for _, text := range []string{
+		"",
+		"not-a-job",
+		strings.ReplaceAll(id.String(), "-", ""),
+		"{" + id.String() + "}",
+		"urn:uuid:" + id.String(),
+		strings.Replace(id.String(), "-", "x", 1),
+	} {
+		_, err := jobid.Parse(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	id := uuid.New()
+	other := uuid.New()
+
+	for _, tc := range []struct {
+		name string
+		raw  []byte
+		text string
+		ok   bool
+	}{
+		{name: "bytes", raw: id[:], ok: true},
+		{name: "text", text: id.String(), ok: true},
+		{name: "both", raw: id[:], text: id.String(), ok: true},
+		{name: "empty"},
+		{name: "short-bytes", raw: id[:8]},
+		{name: "bad-text", text: "not-a-job"},
+		{name: "mismatch", raw: id[:], text: other.String()},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			resolved, err := jobid.Resolve(tc.raw, tc.text)
+			if !tc.ok {
+				assert.Error(tt, err)
+				return
+			}
+			require.NoError(tt, err)
+			assert.Equal(tt, id, resolved)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package leader picks one of several servers sharing a store to do work
+// that only one of them should be doing at a time (ex: collecting the
+// store's garbage). Whoever holds the lease leads. If it stops renewing
+// the lease, because it crashed or lost the store, another server takes
+// over once the lease expires
+package leader
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+)
+
+// Leases is the part of store.Store an Elector needs
+type Leases interface {
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// How long releasing the lease on the way out may take
+const releaseTimeout = 5 * time.Second
+
+// Elector campaigns for a single named lease on behalf of one server
+type Elector struct {
+	leases Leases
+	name   string
+	holder string
+	ttl    time.Duration
+	clock  clock.Clock
+	leader atomic.Bool
+}
+
+// New returns an Elector that campaigns for lease 'name' as 'holder', which
+// must be unique among the servers sharing the store. A leader that can't
+// renew the lease for 'ttl' is replaced
+func New(leases Leases, name, holder string, ttl time.Duration, c clock.Clock) *Elector {
+	return &Elector{
+		leases: leases,
+		name:   name,
+		holder: holder,
+		ttl:    ttl,
+		clock:  clock.Or(c),
+	}
+}
+
+// IsLeader reports whether this server held the lease when it last checked
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns until the context is cancelled. 'lead' is called each time
+// this server becomes leader, and its context is cancelled as soon as it
+// stops being leader. Run waits for 'lead' to return before campaigning
+// again, and releases the lease on the way out so a successor needn't
+// wait for it to expire
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	// Renew well before the lease expires, so one slow renewal isn't fatal
+	ticker := e.clock.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	var stepDown context.CancelFunc
+	var done chan struct{}
+	resign := func() {
+		if stepDown == nil {
+			return
+		}
+		stepDown()
+		<-done
+		stepDown, done = nil, nil
+		e.leader.Store(false)
+		slog.Info("No longer leader", "lease", e.name)
+	}
+	defer func() {
+		wasLeader := stepDown != nil
+		resign()
+		if !wasLeader {
+			return
+		}
+		releaseCtx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+		defer cancel()
+		if err := e.leases.ReleaseLease(releaseCtx, e.name, e.holder); err != nil {
+			slog.Error("Failed to release lease", "lease", e.name, "error", err)
+		}
+	}()
+
+	for {
+		acquired, err := e.leases.AcquireLease(ctx, e.name, e.holder, e.ttl)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			slog.Error("Failed to acquire lease", "lease", e.name, "error", err)
+			resign()
+		case !acquired || err != nil:
+			resign()
+		case stepDown == nil:
+			slog.Info("Became leader", "lease", e.name)
+			e.leader.Store(true)
+			stepDown, done = start(ctx, lead)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Call 'lead' in the background until the returned function is called.
+// The channel is closed once it returns
+func start(ctx context.Context, lead func(ctx context.Context)) (context.CancelFunc, chan struct{}) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(ctx)
+	}()
+	return cancel, done
+}
@@ -0,0 +1,63 @@
+package leader_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/leader"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElector(t *testing.T) {
+	leases, err := store.OpenBolt(filepath.Join(t.TempDir(), "jobby.db"))
+	require.NoError(t, err)
+	defer leases.Close()
+	const ttl = 300 * time.Millisecond
+
+	// Runs an elector until the returned function is called, which
+	// waits for it to finish
+	campaign := func(e *leader.Elector, leading chan<- string, name string) func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			e.Run(ctx, func(ctx context.Context) {
+				leading <- name
+				<-ctx.Done()
+			})
+		}()
+		return func() {
+			cancel()
+			<-done
+		}
+	}
+
+	leading := make(chan string, 2)
+	first := leader.New(leases, "gc", "first", ttl, nil)
+	second := leader.New(leases, "gc", "second", ttl, nil)
+	stopFirst := campaign(first, leading, "first")
+	require.Equal(t, "first", <-leading)
+	assert.True(t, first.IsLeader())
+
+	stopSecond := campaign(second, leading, "second")
+	defer stopSecond()
+	// Renewals keep the first in charge
+	time.Sleep(2 * ttl)
+	assert.Empty(t, leading)
+	assert.False(t, second.IsLeader())
+
+	// Takes over once the first lets go
+	stopFirst()
+	assert.False(t, first.IsLeader())
+	select {
+	case name := <-leading:
+		assert.Equal(t, "second", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("second elector never became leader")
+	}
+	assert.True(t, second.IsLeader())
+}
@@ -0,0 +1,91 @@
+// Package listeners lets one gRPC server accept connections on several
+// listeners, each with its own TLS config
+package listeners
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"syscall"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// Listen opens a "tcp" or "unix" listener. A socket left behind by a server
+// that's gone is replaced, but not one that's still being listened on
+func Listen(network, address string) (net.Listener, error) {
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+	info, err := os.Lstat(address)
+	if err == nil && info.Mode().Type() == fs.ModeSocket {
+		conn, err := net.Dial(network, address)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket '%s' is in use", address)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, err
+		}
+		if err := os.Remove(address); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen(network, address)
+}
+
+// WithTLS has connections accepted from 'listener' handshake with 'config'
+// when served with Credentials
+func WithTLS(listener net.Listener, config *tls.Config) net.Listener {
+	return &tlsListener{Listener: listener, creds: credentials.NewTLS(config)}
+}
+
+type tlsListener struct {
+	net.Listener
+	creds credentials.TransportCredentials
+}
+
+func (l *tlsListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &tlsConn{Conn: conn, creds: l.creds}, nil
+}
+
+// Remembers which listener it came from
+type tlsConn struct {
+	net.Conn
+	creds credentials.TransportCredentials
+}
+
+// Credentials handshakes connections with the TLS config of the listener
+// they came from (see WithTLS), or 'fallback' for other listeners. Ex:
+//
+//	grpc.NewServer(grpc.Creds(listeners.Credentials(credentials.NewTLS(config))))
+func Credentials(fallback credentials.TransportCredentials) credentials.TransportCredentials {
+	return &perListener{TransportCredentials: fallback}
+}
+
+type perListener struct {
+	credentials.TransportCredentials
+}
+
+func (p *perListener) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if conn, ok := conn.(*tlsConn); ok {
+		return conn.creds.ServerHandshake(conn.Conn)
+	}
+	return p.TransportCredentials.ServerHandshake(conn)
+}
+
+func (p *perListener) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return p.TransportCredentials.ClientHandshake(ctx, authority, conn)
+}
+
+func (p *perListener) Clone() credentials.TransportCredentials {
+	return &perListener{TransportCredentials: p.TransportCredentials.Clone()}
+}
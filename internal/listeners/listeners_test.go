@@ -0,0 +1,188 @@
+package listeners_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/certgen"
+	"github.com/gopheryan/jobby/internal/listeners"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Server and client configs, each trusting the same CA
+func tlsConfigs(t *testing.T, name string) (*tls.Config, *tls.Config) {
+	ca, err := certgen.NewCA(name, time.Hour)
+	require.NoError(t, err)
+	server, err := ca.IssueServer([]string{"localhost"}, time.Hour)
+	require.NoError(t, err)
+	client, err := ca.IssueClient("alice", time.Hour)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{server.TLSCertificate()},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{client.TLSCertificate()},
+		RootCAs:      pool,
+		ServerName:   "localhost",
+	}
+}
+
+func check(target string, config *tls.Config) error {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(false))
+	return err
+}
+
+func TestCredentials(t *testing.T) {
+	publicServer, publicClient := tlsConfigs(t, "PublicCA")
+	mgmtServer, mgmtClient := tlsConfigs(t, "ManagementCA")
+
+	server := grpc.NewServer(grpc.Creds(listeners.Credentials(credentials.NewTLS(publicServer))))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	defer server.Stop()
+
+	public, err := listeners.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	socket := filepath.Join(t.TempDir(), "jobby.sock")
+	mgmt, err := listeners.Listen("unix", socket)
+	require.NoError(t, err)
+	go server.Serve(public)
+	go server.Serve(listeners.WithTLS(mgmt, mgmtServer))
+
+	assert.NoError(t, check(public.Addr().String(), publicClient))
+	assert.Error(t, check(public.Addr().String(), mgmtClient))
+	assert.NoError(t, check("unix://"+socket, mgmtClient))
+	assert.Error(t, check("unix://"+socket, publicClient))
+}
+
+func TestListenUnix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "jobby.sock")
+	listener, err := listeners.Listen("unix", socket)
+	require.NoError(t, err)
+
+	// Still in use
+	_, err = listeners.Listen("unix", socket)
+	assert.ErrorContains(t, err, "in use")
+
+	// Left behind, as if the server had crashed
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, listener.Close())
+	assert.FileExists(t, socket)
+	listener, err = listeners.Listen("unix", socket)
+	require.NoError(t, err)
+	assert.NoError(t, listener.Close())
+	assert.NoFileExists(t, socket)
+}
+
+// Accepts one connection and reports its address and what was read from it
+func acceptOne(t *testing.T, listener net.Listener) (<-chan net.Addr, <-chan string) {
+	addrs, data := make(chan net.Addr, 1), make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		addrs <- conn.RemoteAddr()
+		read, _ := io.ReadAll(conn)
+		data <- string(read)
+	}()
+	return addrs, data
+}
+
+func TestProxyProtocol(t *testing.T) {
+	v2Header := func(command byte, family byte, addrs ...byte) []byte {
+		header := append([]byte("\r\n\r\n\x00\r\nQUIT\n"), 0x20|command, family, 0, byte(len(addrs)))
+		return append(header, addrs...)
+	}
+	for name, test := range map[string]struct {
+		header string
+		// Empty when the load balancer's address is kept
+		remote string
+	}{
+		"v1-tcp4":    {header: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n", remote: "192.0.2.1:56324"},
+		"v1-tcp6":    {header: "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n", remote: "[2001:db8::1]:56324"},
+		"v1-unknown": {header: "PROXY UNKNOWN\r\n"},
+		"v2-tcp4": {
+			header: string(v2Header(1, 0x11, 192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb)),
+			remote: "192.0.2.1:56324",
+		},
+		"v2-local": {header: string(v2Header(0, 0x00))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			inner, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer inner.Close()
+			listener := listeners.WithProxyProtocol(inner, []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})
+			addrs, data := acceptOne(t, listener)
+
+			conn, err := net.Dial("tcp", inner.Addr().String())
+			require.NoError(t, err)
+			_, err = conn.Write([]byte(test.header + "hello"))
+			require.NoError(t, err)
+			require.NoError(t, conn.Close())
+
+			remote := <-addrs
+			if test.remote == "" {
+				assert.Equal(t, conn.LocalAddr().String(), remote.String())
+			} else {
+				assert.Equal(t, test.remote, remote.String())
+			}
+			assert.Equal(t, "hello", <-data)
+		})
+	}
+
+	t.Run("missing-header", func(t *testing.T) {
+		inner, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer inner.Close()
+		listener := listeners.WithProxyProtocol(inner, []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")})
+		_, data := acceptOne(t, listener)
+
+		conn, err := net.Dial("tcp", inner.Addr().String())
+		require.NoError(t, err)
+		_, err = conn.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+		assert.Empty(t, <-data)
+	})
+
+	t.Run("untrusted", func(t *testing.T) {
+		inner, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer inner.Close()
+		listener := listeners.WithProxyProtocol(inner, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+		go listener.Accept()
+
+		conn, err := net.Dial("tcp", inner.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		// Closed without a word
+		_, err = conn.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.EOF)
+	})
+}
@@ -0,0 +1,198 @@
+package listeners
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Starts every v2 header
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// v1 headers are at most 107 bytes, including the CRLF
+const proxyV1MaxLength = 107
+
+// WithProxyProtocol expects a PROXY protocol header (v1 or v2) on every
+// connection accepted from 'listener', as load balancers send them, and
+// reports the client address in it as the connection's RemoteAddr.
+// Connections from outside 'trusted' are closed. Unix socket connections
+// don't have an address to check, so they're let through
+func WithProxyProtocol(listener net.Listener, trusted []netip.Prefix) net.Listener {
+	return &proxyListener{Listener: listener, trusted: trusted}
+}
+
+type proxyListener struct {
+	net.Listener
+	trusted []netip.Prefix
+}
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.isTrusted(conn.RemoteAddr()) {
+			// The header is read on first use, so a slow load balancer
+			// doesn't hold up the rest
+			return &proxyConn{Conn: conn, reader: bufio.NewReaderSize(conn, 512)}, nil
+		}
+		slog.Warn("Refusing connection from untrusted proxy", "address", conn.RemoteAddr().String())
+		conn.Close()
+	}
+}
+
+func (l *proxyListener) isTrusted(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return true
+	}
+	ip := tcpAddr.AddrPort().Addr().Unmap()
+	for _, prefix := range l.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProxiedAddr is the RemoteAddr of connections whose client address came
+// from a PROXY protocol header, rather than being the load balancer's own
+type ProxiedAddr struct {
+	*net.TCPAddr
+}
+
+type proxyConn struct {
+	net.Conn
+	reader *bufio.Reader
+
+	once sync.Once
+	// From the header. Nil when it had none to give (ex: health checks)
+	remote net.Addr
+	err    error
+}
+
+// Reading is bounded by the deadline of whoever reads first (ex: gRPC's
+// connection timeout covers the TLS handshake)
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		remote, err := readProxyHeader(c.reader)
+		if err != nil {
+			c.err = fmt.Errorf("bad PROXY protocol header from %s: %w", c.Conn.RemoteAddr(), err)
+		} else if remote != nil {
+			c.remote = ProxiedAddr{remote}
+		}
+	})
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func readProxyHeader(reader *bufio.Reader) (*net.TCPAddr, error) {
+	start, err := reader.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.Equal(start, proxyV2Signature):
+		return readProxyV2(reader)
+	case bytes.HasPrefix(start, []byte("PROXY ")):
+		return readProxyV1(reader)
+	default:
+		return nil, errors.New("missing header")
+	}
+}
+
+// Ex: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"
+func readProxyV1(reader *bufio.Reader) (*net.TCPAddr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if line = append(line, b); len(line) > proxyV1MaxLength {
+			return nil, errors.New("v1 header too long")
+		}
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("malformed v1 header %q", strings.TrimSpace(string(line)))
+	}
+	addr, err := netip.ParseAddr(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, err
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(port))), nil
+}
+
+func readProxyV2(reader *bufio.Reader) (*net.TCPAddr, error) {
+	header := make([]byte, len(proxyV2Signature)+4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	versionCommand, family := header[12], header[13]
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, err
+	}
+	if versionCommand>>4 != 2 {
+		return nil, fmt.Errorf("unknown version %d", versionCommand>>4)
+	}
+	switch command := versionCommand & 0xf; command {
+	case 0:
+		// LOCAL: the load balancer's own connection (ex: a health check)
+		return nil, nil
+	case 1:
+		// PROXY: a client's connection, relayed
+	default:
+		return nil, fmt.Errorf("unknown command %d", command)
+	}
+
+	var addrLength int
+	switch family >> 4 {
+	case 1:
+		addrLength = 4
+	case 2:
+		addrLength = 16
+	default:
+		// Unix sockets or unspecified. Nothing useful to report
+		return nil, nil
+	}
+	// Source and destination addresses, then their ports
+	if len(payload) < 2*addrLength+4 {
+		return nil, errors.New("v2 address block too short")
+	}
+	addr, _ := netip.AddrFromSlice(payload[:addrLength])
+	port := binary.BigEndian.Uint16(payload[2*addrLength:])
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, port)), nil
+}
@@ -0,0 +1,296 @@
+// Package policy checks requests against rules written in CEL
+// (https://cel.dev), so operators can restrict what users may do
+// (ex: "interns may only run /usr/bin/python3") without code changes.
+//
+// Rules see these variables:
+//
+//	user           string               the caller (see authinterceptors)
+//	rpc            string               method name (ex: "StartJob"), the same in every API version
+//	command        string               StartJob only. Empty for other RPCs. /bin/sh for shell jobs
+//	args           list(string)         StartJob only. ["sh", "-c", shell] for shell jobs
+//	shell          string               StartJob only. The command line of shell jobs, empty for others
+//	labels         map(string, string)  StartJob only
+//	runtime_class  string               StartJob only. Empty when the server default applies
+//	egress_policy  string               StartJob only
+//	template       string               StartJob and PreviewTemplate only. The template the job is
+//	                                    started from. Empty for others
+//	params         map(string, string)  StartJob and PreviewTemplate only. The template's parameters
+//
+// PutSchedule requests set the same variables from the schedule's spec.
+// They're also checked as the StartJob of the job the schedule starts,
+// since its runs aren't requests the rules would see. The service checks
+// each run again before it starts (see CheckStart), in case the rules
+// changed since.
+//
+// Jobs started from a template are checked twice: on the request, before
+// the template is rendered, with command and args empty, and by the
+// service once it's rendered (see CheckRendered), with both set
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/gopheryan/jobby/internal/authinterceptors"
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	jobmanagerv2 "github.com/gopheryan/jobby/jobmanagerpb/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Request holds what rules know about a request
+type Request struct {
+	User         string
+	RPC          string
+	Command      string
+	Args         []string
+	Shell        string
+	Labels       map[string]string
+	RuntimeClass string
+	EgressPolicy string
+	Template     string
+	Params       map[string]string
+}
+
+func (r Request) activation() map[string]any {
+	args, labels, params := r.Args, r.Labels, r.Params
+	if args == nil {
+		args = []string{}
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	return map[string]any{
+		"user":          r.User,
+		"rpc":           r.RPC,
+		"command":       r.Command,
+		"args":          args,
+		"shell":         r.Shell,
+		"labels":        labels,
+		"runtime_class": r.RuntimeClass,
+		"egress_policy": r.EgressPolicy,
+		"template":      r.Template,
+		"params":        params,
+	}
+}
+
+var env = func() *cel.Env {
+	env, err := cel.NewEnv(
+		cel.Variable("user", cel.StringType),
+		cel.Variable("rpc", cel.StringType),
+		cel.Variable("command", cel.StringType),
+		cel.Variable("args", cel.ListType(cel.StringType)),
+		cel.Variable("shell", cel.StringType),
+		cel.Variable("labels", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("runtime_class", cel.StringType),
+		cel.Variable("egress_policy", cel.StringType),
+		cel.Variable("template", cel.StringType),
+		cel.Variable("params", cel.MapType(cel.StringType, cel.StringType)),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return env
+}()
+
+// Rule is a condition the requests it applies to must meet
+type Rule struct {
+	name string
+	// Nil applies to every request
+	when    cel.Program
+	require cel.Program
+}
+
+// NewRule compiles a rule. 'when' selects the requests it applies to
+// (all of them when empty) and 'require' is what they must satisfy.
+// Both must be boolean CEL expressions
+func NewRule(name string, when string, require string) (Rule, error) {
+	if name == "" {
+		return Rule{}, errors.New("name must not be empty")
+	}
+	rule := Rule{name: name}
+	var err error
+	if when != "" {
+		if rule.when, err = compile(when); err != nil {
+			return Rule{}, fmt.Errorf("error compiling 'when': %w", err)
+		}
+	}
+	if require == "" {
+		return Rule{}, errors.New("require must not be empty")
+	}
+	if rule.require, err = compile(require); err != nil {
+		return Rule{}, fmt.Errorf("error compiling 'require': %w", err)
+	}
+	return rule, nil
+}
+
+func compile(expression string) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression must be a bool, not %s", ast.OutputType())
+	}
+	return env.Program(ast)
+}
+
+// Evaluation errors (ex: a missing label) count as false
+func eval(program cel.Program, activation map[string]any) (bool, error) {
+	out, _, err := program.Eval(activation)
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.Value().(bool)
+	return ok && result, nil
+}
+
+// Policy turns away requests that fail any of its rules
+type Policy struct {
+	rules []Rule
+}
+
+func New(rules ...Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+// Check returns a PermissionDenied error naming the first rule 'req' fails
+func (p *Policy) Check(req Request) error {
+	if len(p.rules) == 0 {
+		return nil
+	}
+	activation := req.activation()
+	for _, rule := range p.rules {
+		if rule.when != nil {
+			applies, err := eval(rule.when, activation)
+			if err != nil {
+				slog.Warn("Failed to evaluate policy rule", "rule", rule.name, "error", err)
+			}
+			if !applies {
+				continue
+			}
+		}
+		allowed, err := eval(rule.require, activation)
+		if err != nil {
+			// Fail closed. The rule can't tell whether the request is allowed
+			slog.Warn("Failed to evaluate policy rule", "rule", rule.name, "error", err)
+		}
+		if !allowed {
+			return status.Errorf(codes.PermissionDenied, "Denied by policy rule '%s'", rule.name)
+		}
+	}
+	return nil
+}
+
+// Fill in what 'msg' says about the job it starts, if anything
+func describe(req *Request, msg any) {
+	type spec interface {
+		GetCommand() string
+		GetArgs() []string
+		GetShell() string
+		GetLabels() map[string]string
+		GetRuntimeClass() string
+		GetEgressPolicy() string
+	}
+	type templateRef interface {
+		GetName() string
+		GetParams() map[string]string
+	}
+	var s spec
+	var ref templateRef
+	switch msg := msg.(type) {
+	case *jobmanagerpb.StartJobRequest:
+		if msg.Spec == nil {
+			// Older clients set these instead of a spec
+			req.Command, req.Args, req.RuntimeClass = msg.Command, msg.Args, msg.RuntimeClass
+			return
+		}
+		s, ref = msg.Spec, msg.Spec.GetTemplate()
+	case *jobmanagerv2.StartJobRequest:
+		s, ref = msg.GetSpec(), msg.GetSpec().GetTemplate()
+	case *jobmanagerpb.PutScheduleRequest:
+		s, ref = msg.GetSchedule().GetSpec(), msg.GetSchedule().GetSpec().GetTemplate()
+	case *jobmanagerv2.PutScheduleRequest:
+		s, ref = msg.GetSchedule().GetSpec(), msg.GetSchedule().GetSpec().GetTemplate()
+	case *jobmanagerpb.PreviewTemplateRequest:
+		ref = msg.GetTemplate()
+	case *jobmanagerv2.PreviewTemplateRequest:
+		ref = msg.GetTemplate()
+	default:
+		return
+	}
+	if ref != nil {
+		req.Template, req.Params = ref.GetName(), ref.GetParams()
+	}
+	if s == nil {
+		return
+	}
+	req.Command, req.Args, req.Labels = s.GetCommand(), s.GetArgs(), s.GetLabels()
+	// Rules about commands see the shell that runs the command line
+	if req.Shell = s.GetShell(); req.Shell != "" {
+		req.Command, req.Args = job.ShellCommand(req.Shell)
+	}
+	req.RuntimeClass, req.EgressPolicy = s.GetRuntimeClass(), s.GetEgressPolicy()
+}
+
+// CheckRendered checks the StartJob of 'spec', a job spec 'ref' was
+// rendered into, for 'user'. Rules see both the template and the command
+// it renders to, which the request alone didn't have
+func (p *Policy) CheckRendered(user string, spec *jobmanagerpb.JobSpec, ref *jobmanagerpb.TemplateRef) error {
+	req := Request{User: user, RPC: "StartJob"}
+	describe(&req, &jobmanagerpb.StartJobRequest{Spec: spec})
+	req.Template, req.Params = ref.GetName(), ref.GetParams()
+	return p.Check(req)
+}
+
+// CheckStart checks 'req' as a StartJob by 'user'. For jobs the server
+// starts on someone's behalf (ex: scheduled runs), which no interceptor sees
+func (p *Policy) CheckStart(user string, req *jobmanagerpb.StartJobRequest) error {
+	request := Request{User: user, RPC: "StartJob"}
+	describe(&request, req)
+	return p.Check(request)
+}
+
+func newRequest(ctx context.Context, fullMethod string, msg any) Request {
+	req := Request{
+		User: authinterceptors.GetUserContext(ctx),
+		RPC:  fullMethod[strings.LastIndex(fullMethod, "/")+1:],
+	}
+	describe(&req, msg)
+	return req
+}
+
+// UnaryInterceptor checks requests against the policy. It must run after
+// the authenticator's, which puts the user in the context
+func (p *Policy) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	request := newRequest(ctx, info.FullMethod, req)
+	if err := p.Check(request); err != nil {
+		return nil, err
+	}
+	if request.RPC == "PutSchedule" {
+		// A schedule may only start jobs its owner could start
+		request.RPC = "StartJob"
+		if err := p.Check(request); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor checks streams against the policy before their request
+// arrives, so only 'user' and 'rpc' are set. None of them start jobs
+func (p *Policy) StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	if err := p.Check(newRequest(stream.Context(), info.FullMethod, nil)); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
@@ -0,0 +1,183 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/authinterceptors"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	jobmanagerv2 "github.com/gopheryan/jobby/jobmanagerpb/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheck(t *testing.T) {
+	interns, err := NewRule("interns-run-python", `user.startsWith("intern-")`, `rpc != "StartJob" || command == "/usr/bin/python3"`)
+	require.NoError(t, err)
+	labeled, err := NewRule("labeled", `rpc == "StartJob"`, `labels["team"] != ""`)
+	require.NoError(t, err)
+	policy := New(interns, labeled)
+
+	for _, tc := range []struct {
+		name    string
+		req     Request
+		allowed bool
+	}{
+		{"intern runs python", Request{User: "intern-bob", RPC: "StartJob", Command: "/usr/bin/python3", Labels: map[string]string{"team": "infra"}}, true},
+		{"intern runs bash", Request{User: "intern-bob", RPC: "StartJob", Command: "/bin/bash", Labels: map[string]string{"team": "infra"}}, false},
+		{"intern reads output", Request{User: "intern-bob", RPC: "GetJobOutput"}, true},
+		{"others run anything", Request{User: "ryan", RPC: "StartJob", Command: "/bin/bash", Labels: map[string]string{"team": "infra"}}, true},
+		// The label lookup fails. Failing rules deny
+		{"unlabeled", Request{User: "ryan", RPC: "StartJob", Command: "/bin/bash"}, false},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			err := policy.Check(tc.req)
+			if tc.allowed {
+				assert.NoError(tt, err)
+			} else {
+				assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+			}
+		})
+	}
+
+	assert.NoError(t, New().Check(Request{}))
+}
+
+func TestNewRule(t *testing.T) {
+	_, err := NewRule("", "", "true")
+	assert.Error(t, err)
+	_, err = NewRule("no-require", "true", "")
+	assert.Error(t, err)
+	_, err = NewRule("not-bool", "", `command`)
+	assert.Error(t, err)
+	_, err = NewRule("unknown-variable", `job_id == "x"`, "true")
+	assert.Error(t, err)
+}
+
+func TestInterceptors(t *testing.T) {
+	rule, err := NewRule("no-bash", "", `command != "/bin/bash" && args.all(arg, arg != "--danger")`)
+	require.NoError(t, err)
+	policy := New(rule)
+	ctx := authinterceptors.WithUser(context.Background(), "ryan")
+	handler := func(ctx context.Context, _ any) (any, error) {
+		return nil, nil
+	}
+
+	t.Run("v1", func(tt *testing.T) {
+		info := &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/StartJob"}
+		_, err := policy.UnaryInterceptor(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/bash"},
+		}, info, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+
+		// Without a spec
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerpb.StartJobRequest{Command: "/bin/echo", Args: []string{"--danger"}}, info, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerpb.StartJobRequest{Command: "/bin/echo"}, info, handler)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("v2", func(tt *testing.T) {
+		info := &grpc.UnaryServerInfo{FullMethod: "/jobmanager.v2.JobManager/StartJob"}
+		_, err := policy.UnaryInterceptor(ctx, &jobmanagerv2.StartJobRequest{
+			Spec: &jobmanagerv2.JobSpec{Command: "/bin/bash"},
+		}, info, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("schedules", func(tt *testing.T) {
+		info := &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/PutSchedule"}
+		_, err := policy.UnaryInterceptor(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: &jobmanagerpb.Schedule{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/bash"},
+		}}, info, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+
+		// Rules about StartJob apply to what the schedule starts
+		startOnly, err := NewRule("start-echo", `rpc == "StartJob"`, `command == "/bin/echo"`)
+		require.NoError(tt, err)
+		_, err = New(startOnly).UnaryInterceptor(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: &jobmanagerpb.Schedule{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/date"},
+		}}, info, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+		_, err = New(startOnly).UnaryInterceptor(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: &jobmanagerpb.Schedule{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/echo"},
+		}}, info, handler)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("templates", func(tt *testing.T) {
+		rule, err := NewRule("only-backups", `rpc == "StartJob" || rpc == "PreviewTemplate"`, `template == "backup" && params["db"] != "prod"`)
+		require.NoError(tt, err)
+		policy := New(rule)
+		start := &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/StartJob"}
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{
+			Template: &jobmanagerpb.TemplateRef{Name: "backup", Params: map[string]string{"db": "orders"}},
+		}}, start, handler)
+		assert.NoError(tt, err)
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/echo"}}, start, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+
+		preview := &grpc.UnaryServerInfo{FullMethod: "/jobmanager.v2.JobManager/PreviewTemplate"}
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerv2.PreviewTemplateRequest{
+			Template: &jobmanagerv2.TemplateRef{Name: "backup", Params: map[string]string{"db": "prod"}},
+		}, preview, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("shell", func(tt *testing.T) {
+		info := &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/StartJob"}
+		_, err := policy.UnaryInterceptor(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Shell: "echo --danger | wc -l"},
+		}, info, handler)
+		assert.NoError(tt, err, "args are the shell's, not the command line's")
+
+		rule, err := NewRule("no-pipes", `shell != ""`, `!shell.contains("|") && command == "/bin/sh"`)
+		require.NoError(tt, err)
+		policy := New(rule)
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Shell: "echo hi | wc -l"},
+		}, info, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Shell: "echo hi > /dev/null"},
+		}, info, handler)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("rpc", func(tt *testing.T) {
+		rule, err := NewRule("read-only", `user == "auditor"`, `rpc in ["GetStatus", "GetJobOutput"]`)
+		require.NoError(tt, err)
+		policy := New(rule)
+		ctx := authinterceptors.WithUser(context.Background(), "auditor")
+
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerpb.StopJobRequest{}, &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/StopJob"}, handler)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+		_, err = policy.UnaryInterceptor(ctx, &jobmanagerpb.GetStatusRequest{}, &grpc.UnaryServerInfo{FullMethod: "/jobby.JobManager/GetStatus"}, handler)
+		assert.NoError(tt, err)
+
+		stream := &contextStream{ctx: ctx}
+		called := false
+		err = policy.StreamInterceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/jobmanager.v2.JobManager/GetJobOutput"}, func(any, grpc.ServerStream) error {
+			called = true
+			return nil
+		})
+		assert.NoError(tt, err)
+		assert.True(tt, called)
+		err = policy.StreamInterceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/jobby.JobManager/ExportJobs"}, func(any, grpc.ServerStream) error {
+			return nil
+		})
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/admin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Services admin.Register may add. CSDS only comes with xDS, which we don't
+// use, but it's refused all the same should it ever be linked in
+var adminServicePrefixes = []string{
+	"/grpc.channelz.v1.Channelz/",
+	"/envoy.service.status.v3.ClientStatusDiscoveryService/",
+}
+
+// AdminServices serves gRPC's admin services (ex: channelz, for a look at
+// live streams, flow control windows and connections) to admins only
+type AdminServices struct {
+	users  UserGetter
+	admins []string
+}
+
+func NewAdminServices(users UserGetter, admins []string) *AdminServices {
+	return &AdminServices{users: users, admins: admins}
+}
+
+// Register adds the admin services to 's'. Call 'cleanup' once it's stopped
+func (a *AdminServices) Register(s grpc.ServiceRegistrar) (cleanup func(), err error) {
+	return admin.Register(s)
+}
+
+// Fails with PermissionDenied if 'fullMethod' is an admin service's and the
+// caller isn't an admin
+func (a *AdminServices) check(ctx context.Context, fullMethod string) error {
+	isAdminService := slices.ContainsFunc(adminServicePrefixes, func(prefix string) bool {
+		return strings.HasPrefix(fullMethod, prefix)
+	})
+	if isAdminService && !slices.Contains(a.admins, a.users.GetUserContext(ctx)) {
+		return status.Error(codes.PermissionDenied, "Only admins may call the gRPC admin services")
+	}
+	return nil
+}
+
+// UnaryInterceptor refuses calls to the admin services from anyone but admins.
+// It must run after authentication
+func (a *AdminServices) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor is UnaryInterceptor for streaming RPCs
+func (a *AdminServices) StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.check(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
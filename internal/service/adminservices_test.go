@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/internal/testutils"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminServices(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "someuser"}
+	adminServices := service.NewAdminServices(users, []string{"admin"})
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(adminServices.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(adminServices.StreamInterceptor),
+	)
+	service.NewJobService(users, t.TempDir()).Register(server)
+	cleanup, err := adminServices.Register(server)
+	require.NoError(t, err)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+		cleanup()
+	})
+	channelz := channelzpb.NewChannelzClient(srv.Conn())
+
+	_, err = channelz.GetServers(ctx, &channelzpb.GetServersRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	// Everything else is left to the rest of the server's checks
+	_, err = jobmanagerpb.NewJobManagerClient(srv.Conn()).ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+	assert.NoError(t, err)
+
+	users.user = "admin"
+	servers, err := channelz.GetServers(ctx, &channelzpb.GetServersRequest{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, servers.Server)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Registers a process the server didn't start as a job, for bringing
+// existing daemons under the server's management. The job has a single
+// attempt, isn't counted against any capacity or quota, and its output
+// files are left alone when it's deleted
+func (j *Jobby) AdoptProcess(ctx context.Context, req *jobmanagerpb.AdoptProcessRequest) (*jobmanagerpb.AdoptProcessResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	subLogger := slog.With("user", user, "request", req)
+	subLogger.Info("Handling 'AdoptProcess' request")
+	if !slices.Contains(j.admins, user) {
+		return nil, status.Error(codes.PermissionDenied, "Only admins may adopt processes")
+	}
+	if j.draining.Load() {
+		return nil, errShuttingDown
+	}
+	if req.Pid <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Must provide a pid")
+	}
+	if !filepath.IsAbs(req.StdoutPath) || !filepath.IsAbs(req.StderrPath) {
+		return nil, status.Error(codes.InvalidArgument, "Output paths must be absolute")
+	}
+	pid := int(req.Pid)
+	if managed := j.jobWithPID(pid); managed != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "Process %d is already job %s", pid, managed.id)
+	}
+
+	command, args, err := processCommand(pid)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Error reading process %d: %v", pid, err)
+	}
+	spec := &jobmanagerpb.JobSpec{Command: command, Args: args, Labels: req.Labels}
+	if err := validateSpec(spec); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	owner := req.Owner
+	if owner == "" {
+		owner = user
+	}
+
+	jobId := uuid.New()
+	adopted := &jobData{
+		Owner:       owner,
+		id:          jobId,
+		spec:        spec,
+		specHash:    specHash(command, args, nil),
+		maxAttempts: 1,
+		directory:   j.directory,
+		retention:   j.retention.DefaultTTL,
+		usage:       j.usage,
+		stats:       j.stats,
+		store:       j.store,
+		node:        j.node,
+		scheduler:   j.scheduler,
+		events:      j.events,
+		hooks:       j.hooks,
+		clock:       j.clock,
+		startedAt:   j.clock.Now(),
+		finished:    make(chan struct{}),
+		adopted:     true,
+	}
+	first := &attempt{number: 1}
+	first.job, err = job.Adopt(job.AdoptArgs{
+		PID:        pid,
+		StdoutPath: req.StdoutPath,
+		StderrPath: req.StderrPath,
+		OnSignal:   adopted.onSignal(first.number),
+		Clock:      j.clock,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Can't adopt process %d: %v", pid, err)
+	}
+	adopted.attempts = []*attempt{first}
+	adopted.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED, user, 0, fmt.Sprintf("adopted process %d", pid))
+
+	j.jobDirectory.Store(jobId, adopted)
+	j.usage.jobStarted(owner)
+	adopted.persist()
+	go adopted.supervise(first)
+	subLogger.Info("Adopted process", "job-id", jobId, "pid", pid, "owner", owner)
+
+	return &jobmanagerpb.AdoptProcessResponse{JobId: jobId[:], Id: jobId.String()}, nil
+}
+
+// The unfinished job whose process is 'pid'. Nil if there's none
+func (j *Jobby) jobWithPID(pid int) *jobData {
+	var found *jobData
+	j.jobDirectory.Range(func(_, value any) bool {
+		d, ok := value.(*jobData)
+		if ok && !d.isFinished() && d.latest().job.Status().PID == pid {
+			found = d
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// What 'pid' runs: its executable and argv, like a JobSpec's command and args.
+// The executable may be unreadable (ex: a process of another user), in
+// which case argv[0] stands in for it
+func processCommand(pid int) (string, []string, error) {
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return "", nil, err
+	}
+	args := strings.Split(string(bytes.TrimSuffix(cmdline, []byte{0})), "\x00")
+	if len(cmdline) == 0 {
+		return "", nil, fmt.Errorf("process %d has no command line (ex: a kernel thread)", pid)
+	}
+	command, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		command = args[0]
+	}
+	return command, args, nil
+}
@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdoptProcess(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "admin"}
+	events, err := service.OpenEventLog(filepath.Join(t.TempDir(), "events.jsonl"), time.Hour)
+	require.NoError(t, err)
+	defer events.Close()
+	jobService := service.NewJobService(users, t.TempDir(),
+		service.WithAdmins([]string{"admin"}),
+		service.WithEventLog(events),
+	)
+
+	// Started as if by someone else, writing to a file of its own
+	startProcess := func(tt *testing.T, script string) (*exec.Cmd, string) {
+		output := filepath.Join(tt.TempDir(), "output")
+		file, err := os.Create(output)
+		require.NoError(tt, err)
+		defer file.Close()
+		cmd := exec.Command("/bin/sh", "-c", script)
+		cmd.Stdout, cmd.Stderr = file, file
+		require.NoError(tt, cmd.Start())
+		tt.Cleanup(func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		})
+		if command, ok := strings.CutPrefix(script, "exec "); ok {
+			// Adopting reads the command line, which is the shell's until it execs
+			cmdline := filepath.Join("/proc", strconv.Itoa(cmd.Process.Pid), "cmdline")
+			require.Eventually(tt, func() bool {
+				data, err := os.ReadFile(cmdline)
+				return err == nil && strings.Join(strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00"), " ") == command
+			}, 5*time.Second, time.Millisecond)
+		}
+		return cmd, output
+	}
+
+	t.Run("adopted", func(tt *testing.T) {
+		cmd, output := startProcess(tt, "exec sleep 30")
+		req := &jobmanagerpb.AdoptProcessRequest{
+			Pid:        int32(cmd.Process.Pid),
+			StdoutPath: output,
+			StderrPath: output,
+			Owner:      "bob",
+			Labels:     map[string]string{"migrated-from": "systemd"},
+		}
+
+		users.user = "bob"
+		_, err := jobService.AdoptProcess(ctx, req)
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+
+		users.user = "admin"
+		resp, err := jobService.AdoptProcess(ctx, req)
+		require.NoError(tt, err)
+		_, err = jobService.AdoptProcess(ctx, req)
+		assert.Equal(tt, codes.AlreadyExists, status.Code(err))
+		// It's bob's now
+		_, err = jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+
+		users.user = "bob"
+		statusResp, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_RUNNING, statusResp.CurrentStatus)
+		assert.Equal(tt, int32(cmd.Process.Pid), statusResp.Pid)
+		list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+		require.NoError(tt, err)
+		require.Len(tt, list.Jobs, 1)
+		assert.True(tt, list.Jobs[0].Adopted)
+		assert.Equal(tt, []string{"sleep", "30"}, list.Jobs[0].Spec.Args)
+		assert.Equal(tt, "systemd", list.Jobs[0].Spec.Labels["migrated-from"])
+
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		statusResp, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_STOPPED, statusResp.CurrentStatus)
+		assert.Equal(tt, jobmanagerpb.ExitReason_EXIT_REASON_STOPPED, statusResp.ExitReason)
+
+		// Deleting the job leaves the process's output alone
+		_, err = jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{JobId: resp.JobId, Force: true})
+		require.NoError(tt, err)
+		assert.FileExists(tt, output)
+	})
+
+	t.Run("exited", func(tt *testing.T) {
+		users.user = "admin"
+		cmd, output := startProcess(tt, "sleep 0.2")
+		resp, err := jobService.AdoptProcess(ctx, &jobmanagerpb.AdoptProcessRequest{
+			Pid:        int32(cmd.Process.Pid),
+			StdoutPath: output,
+			StderrPath: output,
+		})
+		require.NoError(tt, err)
+		statusResp, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, statusResp.CurrentStatus)
+		assert.Equal(tt, jobmanagerpb.ExitReason_EXIT_REASON_UNKNOWN, statusResp.ExitReason)
+		assert.Nil(tt, statusResp.ExitCode)
+
+		eventsResp, err := jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		require.Len(tt, eventsResp.Events, 2)
+		assert.Equal(tt, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED, eventsResp.Events[0].Type)
+		assert.Contains(tt, eventsResp.Events[0].Detail, "adopted process")
+		assert.Equal(tt, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_EXITED, eventsResp.Events[1].Type)
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		users.user = "admin"
+		cmd, output := startProcess(tt, "exec sleep 30")
+		for _, req := range []*jobmanagerpb.AdoptProcessRequest{
+			{StdoutPath: output, StderrPath: output},
+			{Pid: int32(cmd.Process.Pid), StdoutPath: "output", StderrPath: output},
+			{Pid: 1 << 30, StdoutPath: output, StderrPath: output},
+		} {
+			_, err := jobService.AdoptProcess(ctx, req)
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err), req.String())
+		}
+		_, err := jobService.AdoptProcess(ctx, &jobmanagerpb.AdoptProcessRequest{
+			Pid: int32(cmd.Process.Pid), StdoutPath: output + ".missing", StderrPath: output,
+		})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+}
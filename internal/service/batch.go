@@ -0,0 +1,198 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+)
+
+// Upper bounds on per-request batching overrides. Keeps messages well under
+// gRPC's default 4MiB limit and output from going stale for too long
+const (
+	maxBatchBytes = 1024 * 1024
+	maxBatchDelay = 5 * time.Second
+	maxLineHold   = 30 * time.Second
+)
+
+// OutputBatching controls how job output is grouped into GetJobOutput messages.
+// Chatty jobs that write many small lines can be sent in fewer, larger messages
+type OutputBatching struct {
+	// Send as soon as this many bytes are buffered. Also the largest message sent
+	MaxBytes int
+	// Send buffered output at least this often.
+	// Zero sends output as soon as it is read
+	MaxDelay time.Duration
+	// In line mode, how long a partial line is held waiting for its newline
+	// before it's sent anyway. Zero holds it until the line is complete
+	MaxLineHold time.Duration
+}
+
+// Sends output as soon as it's available, up to one buffer at a time
+var defaultOutputBatching = OutputBatching{
+	MaxBytes:    defaultOutputBufferSize,
+	MaxLineHold: time.Second,
+}
+
+// Apply the request's overrides to the server's policy
+func (b OutputBatching) override(req *jobmanagerpb.GetJobOutputRequest) (OutputBatching, error) {
+	out := b
+	if req.BatchMaxBytes != 0 {
+		if req.BatchMaxBytes > maxBatchBytes {
+			return OutputBatching{}, fmt.Errorf("batch_max_bytes must not exceed %d", maxBatchBytes)
+		}
+		out.MaxBytes = int(req.BatchMaxBytes)
+	}
+	if req.BatchMaxDelay != nil {
+		delay := req.BatchMaxDelay.AsDuration()
+		if delay < 0 || delay > maxBatchDelay {
+			return OutputBatching{}, fmt.Errorf("batch_max_delay must be between 0 and %s", maxBatchDelay)
+		}
+		out.MaxDelay = delay
+	}
+	if req.LineMaxHold != nil {
+		hold := req.LineMaxHold.AsDuration()
+		if hold < 0 || hold > maxLineHold {
+			return OutputBatching{}, fmt.Errorf("line_max_hold must be between 0 and %s", maxLineHold)
+		}
+		out.MaxLineHold = hold
+	}
+	return out, nil
+}
+
+type readResult struct {
+	data []byte
+	err  error
+}
+
+// How many reads may be buffered ahead of the sender. Once the pump
+// is full, reading stops until the client catches up
+const pumpDepth = 4
+
+// Implemented by readers that can release resources used to wait for
+// more data (ex: inotify watches) while we aren't reading
+type pauser interface {
+	Pause() error
+}
+
+// Reads from 'reader' and passes output to 'send' according to the batching policy.
+// Reads happen on a separate goroutine so a quiet job doesn't hold buffered output
+// past the deadline. When sends block (the client isn't keeping up with gRPC flow control)
+// reading stops as well, so a stalled client doesn't cost us anything beyond a few buffers.
+// Output passes through 'transform' (if not nil) before it's batched.
+// Returns once the reader fails (io.EOF included) or a send fails.
+// The caller must close the reader afterward to release the reading goroutine
+func batchOutput(reader io.Reader, policy OutputBatching, transform outputTransform, send func([]byte) error) (readErr error, sendErr error) {
+	results := make(chan readResult, pumpDepth)
+	quit := make(chan struct{})
+	defer close(quit)
+
+	// Hand a result to the sender, pausing the reader if we
+	// have to wait. False means the sender has gone away
+	deliver := func(result readResult) bool {
+		select {
+		case results <- result:
+			return true
+		default:
+		}
+
+		if p, ok := reader.(pauser); ok {
+			if err := p.Pause(); err != nil {
+				slog.Warn("Failed to pause output reader", "error", err)
+			}
+		}
+		select {
+		case results <- result:
+			return true
+		case <-quit:
+			return false
+		}
+	}
+
+	go func() {
+		for {
+			buf := make([]byte, policy.MaxBytes)
+			count, err := reader.Read(buf)
+			if count > 0 && !deliver(readResult{data: buf[:count]}) {
+				return
+			}
+			if err != nil {
+				deliver(readResult{err: err})
+				return
+			}
+		}
+	}()
+
+	var pending []byte
+	var deadline <-chan time.Time
+	flush := func() error {
+		deadline = nil
+		for len(pending) > 0 {
+			n := min(len(pending), policy.MaxBytes)
+			// Send a copy so we're free to reuse 'pending'
+			chunk := make([]byte, n)
+			copy(chunk, pending[:n])
+			pending = pending[n:]
+			if err := send(chunk); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+
+	// Queue output for sending according to the batching policy
+	queue := func(data []byte) error {
+		if len(data) == 0 {
+			return nil
+		}
+		pending = append(pending, data...)
+		if policy.MaxDelay == 0 || len(pending) >= policy.MaxBytes {
+			return flush()
+		}
+		if deadline == nil {
+			// The deadline starts with the oldest unsent byte
+			deadline = time.After(policy.MaxDelay)
+		}
+		return nil
+	}
+
+	// Fires when the transform has held output back for too long
+	var holdDeadline <-chan time.Time
+	for {
+		select {
+		case result := <-results:
+			if result.err != nil {
+				// Whatever we have left goes out before the stream ends
+				if transform != nil {
+					pending = append(pending, transform.Flush()...)
+				}
+				return result.err, flush()
+			}
+
+			data := result.data
+			if transform != nil {
+				data = transform.Transform(data)
+				if !transform.Holding() {
+					holdDeadline = nil
+				} else if holdDeadline == nil && policy.MaxLineHold > 0 {
+					holdDeadline = time.After(policy.MaxLineHold)
+				}
+			}
+			if err := queue(data); err != nil {
+				return nil, err
+			}
+		case <-holdDeadline:
+			holdDeadline = nil
+			if err := queue(transform.Flush()); err != nil {
+				return nil, err
+			}
+		case <-deadline:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
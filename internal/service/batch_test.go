@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Runs batchOutput over a pipe. Sent messages show up on the returned channel
+func startBatching(t *testing.T, policy OutputBatching, transform outputTransform) (*io.PipeWriter, <-chan string, <-chan error) {
+	reader, writer := io.Pipe()
+	t.Cleanup(func() { _ = reader.Close() })
+
+	sent := make(chan string, 16)
+	done := make(chan error, 1)
+	go func() {
+		readErr, sendErr := batchOutput(reader, policy, transform, func(data []byte) error {
+			sent <- string(data)
+			return nil
+		})
+		assert.NoError(t, sendErr)
+		done <- readErr
+		close(sent)
+	}()
+	return writer, sent, done
+}
+
+func TestBatchOutput(t *testing.T) {
+	t.Run("no-delay", func(tt *testing.T) {
+		writer, sent, done := startBatching(tt, OutputBatching{MaxBytes: 4096}, nil)
+
+		// Pipe writes return once read, so each write is its own read
+		_, _ = writer.Write([]byte("one"))
+		assert.Equal(tt, "one", <-sent)
+		_, _ = writer.Write([]byte("two"))
+		assert.Equal(tt, "two", <-sent)
+
+		require.NoError(tt, writer.Close())
+		assert.ErrorIs(tt, <-done, io.EOF)
+	})
+
+	t.Run("max-bytes", func(tt *testing.T) {
+		writer, sent, done := startBatching(tt, OutputBatching{MaxBytes: 4, MaxDelay: time.Hour}, nil)
+
+		_, _ = writer.Write([]byte("ab"))
+		_, _ = writer.Write([]byte("cdef"))
+		// Full batches go out right away and never exceed MaxBytes
+		assert.Equal(tt, "abcd", <-sent)
+
+		// Leftovers are flushed at the end of the stream
+		require.NoError(tt, writer.Close())
+		assert.Equal(tt, "ef", <-sent)
+		assert.ErrorIs(tt, <-done, io.EOF)
+	})
+
+	t.Run("max-delay", func(tt *testing.T) {
+		writer, sent, done := startBatching(tt, OutputBatching{MaxBytes: 4096, MaxDelay: 50 * time.Millisecond}, nil)
+
+		start := time.Now()
+		_, _ = writer.Write([]byte("a"))
+		_, _ = writer.Write([]byte("b"))
+		assert.Equal(tt, "ab", <-sent)
+		assert.GreaterOrEqual(tt, time.Since(start), 50*time.Millisecond)
+
+		require.NoError(tt, writer.Close())
+		assert.ErrorIs(tt, <-done, io.EOF)
+		// Nothing left to flush
+		_, ok := <-sent
+		assert.False(tt, ok)
+	})
+
+	t.Run("transform", func(tt *testing.T) {
+		writer, sent, done := startBatching(tt, OutputBatching{MaxBytes: 4096}, &lineCollapser{})
+
+		_, _ = writer.Write([]byte("a\na\na\n"))
+		assert.Equal(tt, "a\n", <-sent)
+
+		// Held back output is flushed when the stream ends
+		require.NoError(tt, writer.Close())
+		assert.Equal(tt, "last line repeated 2 times\n", <-sent)
+		assert.ErrorIs(tt, <-done, io.EOF)
+	})
+
+	t.Run("line-hold", func(tt *testing.T) {
+		writer, sent, done := startBatching(tt, OutputBatching{MaxBytes: 4096, MaxLineHold: 50 * time.Millisecond}, &lineBuffer{})
+
+		start := time.Now()
+		_, _ = writer.Write([]byte("a\npartial"))
+		assert.Equal(tt, "a\n", <-sent)
+		// The partial line goes out once it's been held long enough
+		assert.Equal(tt, "partial", <-sent)
+		assert.GreaterOrEqual(tt, time.Since(start), 50*time.Millisecond)
+
+		_, _ = writer.Write([]byte(" line\n"))
+		assert.Equal(tt, " line\n", <-sent)
+
+		require.NoError(tt, writer.Close())
+		assert.ErrorIs(tt, <-done, io.EOF)
+	})
+}
+
+// Produces endless output and records how it was used
+type pausingReader struct {
+	reads  atomic.Int32
+	pauses atomic.Int32
+}
+
+func (p *pausingReader) Read(b []byte) (int, error) {
+	p.reads.Add(1)
+	return copy(b, "output"), nil
+}
+
+func (p *pausingReader) Pause() error {
+	p.pauses.Add(1)
+	return nil
+}
+
+// A client that stops receiving should stop the reads too
+func TestBatchOutputStalledClient(t *testing.T) {
+	reader := &pausingReader{}
+	unblock := make(chan struct{})
+	sendErr := errors.New("client went away")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := batchOutput(reader, OutputBatching{MaxBytes: 4096}, nil, func([]byte) error {
+			// Stand in for a send blocked on flow control
+			<-unblock
+			return sendErr
+		})
+		assert.ErrorIs(t, err, sendErr)
+	}()
+
+	require.Eventually(t, func() bool {
+		return reader.pauses.Load() > 0
+	}, time.Second, time.Millisecond)
+	// One read being sent, a full pump and one read waiting to get in
+	reads := reader.reads.Load()
+	assert.LessOrEqual(t, reads, int32(pumpDepth+2))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, reads, reader.reads.Load())
+
+	close(unblock)
+	<-done
+}
+
+func TestBatchingOverride(t *testing.T) {
+	server := OutputBatching{MaxBytes: 4096, MaxDelay: 10 * time.Millisecond}
+
+	out, err := server.override(&jobmanagerpb.GetJobOutputRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, server, out)
+
+	out, err = server.override(&jobmanagerpb.GetJobOutputRequest{
+		BatchMaxBytes: 128,
+		BatchMaxDelay: durationpb.New(0),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, OutputBatching{MaxBytes: 128, MaxLineHold: server.MaxLineHold}, out)
+
+	_, err = server.override(&jobmanagerpb.GetJobOutputRequest{BatchMaxBytes: maxBatchBytes + 1})
+	assert.Error(t, err)
+
+	_, err = server.override(&jobmanagerpb.GetJobOutputRequest{BatchMaxDelay: durationpb.New(time.Minute)})
+	assert.Error(t, err)
+
+	_, err = server.override(&jobmanagerpb.GetJobOutputRequest{LineMaxHold: durationpb.New(time.Hour)})
+	assert.Error(t, err)
+}
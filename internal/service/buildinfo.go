@@ -0,0 +1,33 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/gopheryan/jobby/internal/buildinfo"
+	"github.com/gopheryan/jobby/internal/features"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics describing the binary and its feature flags. Their labels carry
+// the information, so dashboards can tell which build each server runs
+func buildCollectors(set features.Set) []prometheus.Collector {
+	build := buildinfo.Get()
+	info := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jobby_build_info",
+		Help: "Always 1. Labeled with what the server was built from",
+	}, []string{"version", "commit", "modified", "go_version"})
+	info.WithLabelValues(build.Version, build.Commit, strconv.FormatBool(build.Modified), build.GoVersion).Set(1)
+
+	enabled := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jobby_feature_enabled",
+		Help: "1 if the feature flag is on, 0 if it's off",
+	}, []string{"feature"})
+	for _, feature := range set.All() {
+		value := 0.0
+		if feature.Enabled {
+			value = 1
+		}
+		enabled.WithLabelValues(string(feature.Flag)).Set(value)
+	}
+	return []prometheus.Collector{info, enabled}
+}
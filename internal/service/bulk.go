@@ -0,0 +1,55 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Largest message no_follow streams send by default
+const bulkMessageBytes = maxBatchBytes
+
+// Read buffers for bulk output, recycled across streams
+var bulkBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, bulkMessageBytes)
+		return &buf
+	},
+}
+
+// Sends everything 'reader' has in messages of up to 'maxBytes', for output
+// that isn't followed (see GetJobOutputRequest.no_follow). There's nothing
+// to wait for, so this skips batchOutput's read-ahead goroutine and
+// deadlines, reading straight into a pooled buffer instead.
+// Each message gets its own copy of the data, as gRPC doesn't allow
+// changing a message once it's sent (stats handlers and interceptors may
+// hold onto it).
+// Splicing the file into the connection would save the last copy, but
+// gRPC frames (and TLS encrypts) everything it writes.
+// Returns once the reader fails (io.EOF included) or a send fails
+func bulkOutput(reader io.Reader, maxBytes int, send func([]byte) error) (readErr error, sendErr error) {
+	bufPtr := bulkBuffers.Get().(*[]byte)
+	defer bulkBuffers.Put(bufPtr)
+	buf := *bufPtr
+
+	for {
+		// Fill the buffer, so sends are as large as they can be
+		count := 0
+		var err error
+		for count < len(buf) && err == nil {
+			var n int
+			n, err = reader.Read(buf[count:])
+			count += n
+		}
+		for data := buf[:count]; len(data) > 0; {
+			n := min(len(data), maxBytes)
+			if err := send(bytes.Clone(data[:n])); err != nil {
+				return nil, err
+			}
+			data = data[n:]
+		}
+		if err != nil {
+			return err, nil
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkOutput(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), (2*bulkMessageBytes+5)/10+1)
+
+	t.Run("default size", func(tt *testing.T) {
+		var sizes []int
+		var sent bytes.Buffer
+		// Small reads still make full messages
+		readErr, sendErr := bulkOutput(iotest.HalfReader(bytes.NewReader(data)), bulkMessageBytes, func(chunk []byte) error {
+			sizes = append(sizes, len(chunk))
+			sent.Write(chunk)
+			return nil
+		})
+		assert.ErrorIs(tt, readErr, io.EOF)
+		assert.NoError(tt, sendErr)
+		assert.Equal(tt, []int{bulkMessageBytes, bulkMessageBytes, len(data) - 2*bulkMessageBytes}, sizes)
+		assert.Equal(tt, data, sent.Bytes())
+	})
+
+	t.Run("messages kept", func(tt *testing.T) {
+		// As stats handlers and interceptors may
+		var chunks [][]byte
+		_, sendErr := bulkOutput(bytes.NewReader(data), 4096, func(chunk []byte) error {
+			chunks = append(chunks, chunk)
+			return nil
+		})
+		assert.NoError(tt, sendErr)
+		assert.Equal(tt, data, bytes.Join(chunks, nil))
+	})
+
+	t.Run("requested size", func(tt *testing.T) {
+		var sent bytes.Buffer
+		_, sendErr := bulkOutput(bytes.NewReader(data), 4096, func(chunk []byte) error {
+			assert.LessOrEqual(tt, len(chunk), 4096)
+			sent.Write(chunk)
+			return nil
+		})
+		assert.NoError(tt, sendErr)
+		assert.Equal(tt, data, sent.Bytes())
+	})
+
+	t.Run("errors", func(tt *testing.T) {
+		failure := errors.New("disk on fire")
+		readErr, _ := bulkOutput(iotest.ErrReader(failure), 4096, func([]byte) error { return nil })
+		assert.ErrorIs(tt, readErr, failure)
+
+		gone := errors.New("client went away")
+		_, sendErr := bulkOutput(bytes.NewReader(data), 4096, func([]byte) error { return gone })
+		assert.ErrorIs(tt, sendErr, gone)
+	})
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+)
+
+// Find the owner's most recently finished job that ran 'spec' (see
+// specHash) and succeeded no longer than 'ttl' ago, so its results can be
+// reused instead of running it again (see StartJobRequest.cache_ttl).
+// Nil if there's none
+func (j *Jobby) cachedResult(owner string, spec *jobmanagerpb.JobSpec, hash string, ttl time.Duration) *jobData {
+	if spec.Stdin {
+		// What it does depends on data we haven't seen
+		return nil
+	}
+	now := j.clock.Now()
+	var found *jobData
+	var foundAt time.Time
+	j.jobDirectory.Range(func(_, value any) bool {
+		d, ok := value.(*jobData)
+		// Output with timestamps isn't what the caller asked for, nor the other way round
+		if !ok || d.Owner != owner || d.specHash != hash || d.adopted || d.spec.Stdin || d.spec.TimestampOutput != spec.TimestampOutput {
+			return true
+		}
+		d.lock.Lock()
+		finishedAt := d.finishedAt
+		reason, _ := d.stateReasonLocked()
+		d.lock.Unlock()
+		if reason != jobmanagerpb.StateReason_STATE_REASON_COMPLETED || now.Sub(finishedAt) > ttl || finishedAt.Before(foundAt) {
+			return true
+		}
+		found, foundAt = d, finishedAt
+		return true
+	})
+	return found
+}
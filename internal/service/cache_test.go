@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestResultCache(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "someuser"}
+	fake := clock.NewFake(time.Now())
+	jobService := service.NewJobService(users, t.TempDir(), service.WithClock(fake))
+	shell := func(script string) *jobmanagerpb.JobSpec {
+		return &jobmanagerpb.JobSpec{Command: "/bin/sh", Args: []string{"sh", "-c", script}}
+	}
+	start := func(tt *testing.T, spec *jobmanagerpb.JobSpec, ttl time.Duration) *jobmanagerpb.StartJobResponse {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec, CacheTtl: durationpb.New(ttl)})
+		require.NoError(tt, err)
+		if !resp.Cached {
+			_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+			require.NoError(tt, err)
+		}
+		return resp
+	}
+
+	first := start(t, shell("echo built"), time.Hour)
+	assert.False(t, first.Cached)
+
+	t.Run("hit", func(tt *testing.T) {
+		resp := start(tt, shell("echo built"), time.Hour)
+		assert.True(tt, resp.Cached)
+		assert.Equal(tt, first.Id, resp.Id)
+
+		events, err := jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: first.JobId})
+		require.NoError(tt, err)
+		last := events.Events[len(events.Events)-1]
+		assert.Equal(tt, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CACHE_HIT, last.Type)
+		assert.Equal(tt, "someuser", last.Actor)
+	})
+
+	t.Run("not opted in", func(tt *testing.T) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: shell("echo built")})
+		require.NoError(tt, err)
+		assert.False(tt, resp.Cached)
+		assert.NotEqual(tt, first.Id, resp.Id)
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+	})
+
+	t.Run("expired", func(tt *testing.T) {
+		fake.Advance(2 * time.Minute)
+		resp := start(tt, shell("echo built"), time.Minute)
+		assert.False(tt, resp.Cached)
+		// The newest success is the one reused
+		assert.Equal(tt, resp.Id, start(tt, shell("echo built"), time.Minute).Id)
+	})
+
+	t.Run("failures aren't cached", func(tt *testing.T) {
+		failed := start(tt, shell("exit 1"), time.Hour)
+		resp := start(tt, shell("exit 1"), time.Hour)
+		assert.False(tt, resp.Cached)
+		assert.NotEqual(tt, failed.Id, resp.Id)
+	})
+
+	t.Run("other owners", func(tt *testing.T) {
+		users.user = "otheruser"
+		defer func() { users.user = "someuser" }()
+		assert.False(tt, start(tt, shell("echo built"), time.Hour).Cached)
+	})
+
+	t.Run("negative ttl", func(tt *testing.T) {
+		_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: shell("true"), CacheTtl: durationpb.New(-time.Second)})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// As long as a whole progress report line in the output may be
+const maxProgressMessageLength = 1024
+
+func (j *Jobby) ReportJobProgress(ctx context.Context, req *jobmanagerpb.ReportJobProgressRequest) (*jobmanagerpb.ReportJobProgressResponse, error) {
+	sublogger := slog.With("user", j.userGetter.GetUserContext(ctx), "request", req)
+	sublogger.Info("Handling 'ReportJobProgress' request")
+	jobData, st := j.getCallbackJob(ctx, req)
+	if st != nil {
+		return nil, st.Err()
+	}
+	if math.IsNaN(req.Percent) || req.Percent < 0 || req.Percent > 100 {
+		return nil, status.Error(codes.InvalidArgument, "percent must be between 0 and 100")
+	}
+	if len(req.Message) > maxProgressMessageLength {
+		return nil, status.Errorf(codes.InvalidArgument, "message must not be longer than %d bytes", maxProgressMessageLength)
+	}
+	if jobData.isFinished() {
+		return nil, status.Error(codes.FailedPrecondition, "Job has finished")
+	}
+
+	err := jobData.latest().job.ReportProgress(job.Progress{Percent: req.Percent, Message: req.Message})
+	switch {
+	case errors.Is(err, job.ErrProgressNotTracked):
+		return nil, status.Error(codes.FailedPrecondition, "Job wasn't started with track_progress")
+	case err != nil:
+		sublogger.Error("Error reporting job progress", "error", err)
+		return nil, status.Error(codes.Internal, "Error reporting job progress")
+	}
+	return &jobmanagerpb.ReportJobProgressResponse{}, nil
+}
+
+func (j *Jobby) AnnotateJob(ctx context.Context, req *jobmanagerpb.AnnotateJobRequest) (*jobmanagerpb.AnnotateJobResponse, error) {
+	slog.Info("Handling 'AnnotateJob' request", "user", j.userGetter.GetUserContext(ctx), "request", req)
+	jobData, st := j.getCallbackJob(ctx, req)
+	if st != nil {
+		return nil, st.Err()
+	}
+	for key, value := range req.Annotations {
+		if key == "" || len(key) > maxLabelKeyLength || len(value) > maxLabelValueLength {
+			return nil, status.Errorf(codes.InvalidArgument, "annotation keys must be 1-%d bytes and values at most %d bytes", maxLabelKeyLength, maxLabelValueLength)
+		}
+	}
+	if err := jobData.annotate(req.Annotations); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	jobData.persist()
+	return &jobmanagerpb.AnnotateJobResponse{}, nil
+}
+
+// Like getJob, but callers with a job's token may only name that job
+func (j *Jobby) getCallbackJob(ctx context.Context, getter JobIDGetter) (*jobData, *status.Status) {
+	jobData, st := j.getJob(ctx, getter)
+	if st != nil {
+		return nil, st
+	}
+	if j.jobTokens != nil {
+		if token, ok := j.jobTokens.fromContext(ctx); ok && token.job != jobData.id {
+			return nil, status.New(codes.NotFound, "No such job exists")
+		}
+	}
+	return jobData, nil
+}
+
+// Merge 'annotations' into the job's. Empty values remove keys. Nothing
+// changes if the job would end up with too many
+func (d *jobData) annotate(annotations map[string]string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	merged := maps.Clone(d.annotations)
+	if merged == nil {
+		merged = make(map[string]string, len(annotations))
+	}
+	for key, value := range annotations {
+		if value == "" {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	if len(merged) > maxLabels {
+		return fmt.Errorf("no more than %d annotations are allowed", maxLabels)
+	}
+	d.annotations = merged
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Capacity bounds how many jobs run at once
+type Capacity struct {
+	// Jobs running at once. New jobs beyond it preempt running jobs of
+	// lower priority, or are refused. Zero means no limit
+	MaxRunningJobs int
+	// How long a preempted job has to exit after SIGTERM before it's killed
+	PreemptionGrace time.Duration
+	// Of the host's resources, left over once a job starts
+	Headroom Headroom
+}
+
+const defaultPreemptionGrace = 10 * time.Second
+
+// Hands out slots for running jobs, and holds preempted jobs
+// waiting to run again until a slot frees up
+type scheduler struct {
+	capacity Capacity
+
+	lock sync.Mutex
+	// Jobs holding a slot
+	running map[*jobData]struct{}
+	// Preempted jobs waiting for a slot. Highest priority first
+	queue []*jobData
+}
+
+func newScheduler(capacity Capacity) *scheduler {
+	if capacity.PreemptionGrace <= 0 {
+		capacity.PreemptionGrace = defaultPreemptionGrace
+	}
+	return &scheduler{capacity: capacity, running: map[*jobData]struct{}{}}
+}
+
+// Caller must hold the lock
+func (s *scheduler) full() bool {
+	return s.capacity.MaxRunningJobs > 0 && len(s.running) >= s.capacity.MaxRunningJobs
+}
+
+// Take a slot for 'd', preempting the lowest priority running job if there
+// isn't one free. False if every running job is at least as important
+func (s *scheduler) admit(d *jobData) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.full() {
+		s.running[d] = struct{}{}
+		return true
+	}
+
+	var victim *jobData
+	for running := range s.running {
+		if running.spec.Priority >= d.spec.Priority {
+			continue
+		}
+		// Of equally unimportant jobs, the newest has the least work to lose
+		if victim == nil || running.spec.Priority < victim.spec.Priority ||
+			(running.spec.Priority == victim.spec.Priority && running.startedAt.After(victim.startedAt)) {
+			victim = running
+		}
+	}
+	if victim == nil {
+		return false
+	}
+	// The slot changes hands right away. The victim may take up to the
+	// grace period to exit
+	delete(s.running, victim)
+	s.running[d] = struct{}{}
+	slog.Info("Preempting job", "job-id", victim.id, "for", d.id)
+	if err := victim.preempt(s.capacity.PreemptionGrace); err != nil {
+		slog.Error("Failed to preempt job", "job-id", victim.id, "error", err)
+	}
+	return true
+}
+
+// Wait for a slot to run 'd' again
+func (s *scheduler) requeue(d *jobData) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	i := slices.IndexFunc(s.queue, func(queued *jobData) bool {
+		return queued.spec.Priority < d.spec.Priority
+	})
+	if i < 0 {
+		i = len(s.queue)
+	}
+	s.queue = slices.Insert(s.queue, i, d)
+}
+
+// Give up the slot of a job that's done running (if it had one) and
+// resume queued jobs with the free slots
+func (s *scheduler) release(d *jobData) {
+	s.lock.Lock()
+	delete(s.running, d)
+	var next []*jobData
+	for len(s.queue) > 0 && !s.full() {
+		queued := s.queue[0]
+		s.queue = s.queue[1:]
+		s.running[queued] = struct{}{}
+		next = append(next, queued)
+	}
+	s.lock.Unlock()
+
+	for _, queued := range next {
+		go queued.resume()
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"google.golang.org/grpc"
+)
+
+// CredentialExpiryGetter tells when the caller's credentials (ex: its
+// client certificate) expire. Zero if they don't
+type CredentialExpiryGetter interface {
+	GetCredentialExpiry(context.Context) time.Time
+}
+
+// CredentialLeasePolicy decides how long an owner's jobs may outlive
+// the credentials they last called with
+type CredentialLeasePolicy struct {
+	// Credentials also lapse this long after the owner's last call, if
+	// that's before their certificate expires. 0 goes by certificates alone
+	Lease time.Duration
+	// How long after their credentials lapse an owner's jobs are
+	// stopped, leaving time to renew them
+	Grace time.Duration
+}
+
+// CredentialLeases keeps track of when each owner's credentials lapse, so
+// jobs of departed users don't run forever (see WithCredentialLeases).
+// Every call an owner makes renews their lease, so its interceptors must
+// see every call and run after the authenticator's. Owners who haven't
+// called since the server started have no lease, and their jobs are left be
+type CredentialLeases struct {
+	policy CredentialLeasePolicy
+	users  UserGetter
+	expiry CredentialExpiryGetter
+	clock  clock.Clock
+
+	lock sync.Mutex
+	// When each owner's credentials lapse
+	leases map[string]time.Time
+}
+
+// NewCredentialLeases renews the leases of the users 'users' finds with
+// the credentials 'expiry' finds. A nil clock uses the real one
+func NewCredentialLeases(policy CredentialLeasePolicy, users UserGetter, expiry CredentialExpiryGetter, c clock.Clock) *CredentialLeases {
+	return &CredentialLeases{
+		policy: policy,
+		users:  users,
+		expiry: expiry,
+		clock:  clock.Or(c),
+		leases: make(map[string]time.Time),
+	}
+}
+
+// Renew the caller's lease. A renewal never shortens it, so calling with
+// an older certificate doesn't cut short the lease of a newer one
+func (l *CredentialLeases) renew(ctx context.Context) {
+	user := l.users.GetUserContext(ctx)
+	if user == "" {
+		// Anonymous callers don't own jobs
+		return
+	}
+	lapses := l.expiry.GetCredentialExpiry(ctx)
+	if l.policy.Lease > 0 {
+		if sooner := l.clock.Now().Add(l.policy.Lease); lapses.IsZero() || sooner.Before(lapses) {
+			lapses = sooner
+		}
+	}
+	if lapses.IsZero() {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if lapses.After(l.leases[user]) {
+		l.leases[user] = lapses
+	}
+}
+
+// Whether 'owner's jobs should be stopped at 'now'
+func (l *CredentialLeases) lapsed(owner string, now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	lapses, ok := l.leases[owner]
+	return ok && now.After(lapses.Add(l.policy.Grace))
+}
+
+func (l *CredentialLeases) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	l.renew(ctx)
+	return handler(ctx, req)
+}
+
+func (l *CredentialLeases) StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	l.renew(stream.Context())
+	return handler(srv, stream)
+}
+
+// WithCredentialLeases stops jobs once their owner's credentials lapse
+// (see StopLapsedJobs). The same leases' interceptors must be installed
+func WithCredentialLeases(leases *CredentialLeases) Option {
+	return func(j *Jobby) {
+		j.credentialLeases = leases
+	}
+}
+
+// StopLapsedJobs stops the running jobs of owners whose credentials had
+// lapsed by 'now'. Returns the number of jobs stopped
+func (j *Jobby) StopLapsedJobs(now time.Time) int {
+	if j.credentialLeases == nil {
+		return 0
+	}
+	stopped := 0
+	j.jobDirectory.Range(func(_, value any) bool {
+		data, ok := value.(*jobData)
+		// Adopted processes weren't started with anyone's credentials
+		if !ok || data.adopted || data.isFinished() || !j.credentialLeases.lapsed(data.Owner, now) {
+			return true
+		}
+		ok, err := data.stopFor(stopCredentialsLapsed)
+		if err != nil {
+			slog.Error("Failed to stop job of owner with lapsed credentials", "job-id", data.id, "error", err)
+		}
+		if !ok || err != nil {
+			return true
+		}
+		data.persist()
+		slog.Info("Stopped job of owner with lapsed credentials", "job-id", data.id, "owner", data.Owner)
+		stopped++
+		return true
+	})
+	return stopped
+}
+
+// RunCredentialLeases stops jobs with lapsed owners every interval
+// until the context is cancelled
+func (j *Jobby) RunCredentialLeases(ctx context.Context, interval time.Duration) {
+	ticker := j.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			j.StopLapsedJobs(now)
+		}
+	}
+}
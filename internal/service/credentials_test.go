@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type mockExpiryGetter struct {
+	expiry time.Time
+}
+
+func (m *mockExpiryGetter) GetCredentialExpiry(context.Context) time.Time {
+	return m.expiry
+}
+
+func TestCredentialLeases(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	fakeClock := clock.NewFake(now)
+	users := &mockUserGetter{user: "someuser"}
+	expiry := &mockExpiryGetter{expiry: now.Add(time.Hour)}
+	leases := service.NewCredentialLeases(service.CredentialLeasePolicy{
+		Lease: 2 * time.Hour,
+		Grace: 10 * time.Minute,
+	}, users, expiry, fakeClock)
+	jobService := service.NewJobService(users, t.TempDir(), service.WithCredentialLeases(leases))
+	call := func() {
+		_, err := leases.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(context.Context, interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+	}
+	start := func(tt *testing.T) []byte {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Force: true, Spec: &jobmanagerpb.JobSpec{
+			Command: "/bin/sh",
+			Args:    []string{"sh", "-c", "sleep 10"},
+		}})
+		require.NoError(tt, err)
+		tt.Cleanup(func() {
+			jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		})
+		return resp.JobId
+	}
+
+	t.Run("no lease", func(tt *testing.T) {
+		// Owners who haven't called through the interceptor are left be
+		start(tt)
+		assert.Zero(tt, jobService.StopLapsedJobs(now.Add(24*time.Hour)))
+	})
+
+	call()
+
+	t.Run("within grace", func(tt *testing.T) {
+		start(tt)
+		assert.Zero(tt, jobService.StopLapsedJobs(now.Add(time.Hour+5*time.Minute)))
+	})
+
+	t.Run("renewed", func(tt *testing.T) {
+		start(tt)
+		// A newer certificate, but the lease caps it
+		expiry.expiry = now.Add(24 * time.Hour)
+		call()
+		assert.Zero(tt, jobService.StopLapsedJobs(now.Add(90*time.Minute)))
+
+		// An older certificate doesn't shorten the lease
+		expiry.expiry = now.Add(time.Minute)
+		call()
+		assert.Zero(tt, jobService.StopLapsedJobs(now.Add(90*time.Minute)))
+	})
+
+	t.Run("lapsed", func(tt *testing.T) {
+		id := start(tt)
+		assert.Equal(tt, 1, jobService.StopLapsedJobs(now.Add(3*time.Hour)))
+		resp, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_CREDENTIALS_EXPIRED, resp.StateReason)
+		assert.Equal(tt, "stopped since someuser's credentials lapsed", resp.StateMessage)
+
+		// Already stopped
+		assert.Zero(tt, jobService.StopLapsedJobs(now.Add(3*time.Hour)))
+	})
+
+	t.Run("anonymous", func(tt *testing.T) {
+		start(tt)
+		// Renews nobody's lease
+		users.user = ""
+		expiry.expiry = now.Add(48 * time.Hour)
+		call()
+		users.user = "someuser"
+		assert.Equal(tt, 1, jobService.StopLapsedJobs(now.Add(3*time.Hour)))
+	})
+}
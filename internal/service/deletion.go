@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// A job deleted without force. It's out of the job directory, so nothing
+// but RestoreJob (and GetJobEvents) can find it, but its output is kept
+type deletedJob struct {
+	data *jobData
+	// Last moment the job can be restored. Purged by the garbage collector after
+	until time.Time
+}
+
+func (j *Jobby) DeleteJob(ctx context.Context, req *jobmanagerpb.DeleteJobRequest) (*jobmanagerpb.DeleteJobResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	subLogger := slog.With("user", user, "request", req)
+	subLogger.Info("Handling 'DeleteJob' request")
+
+	data, st := j.getJob(ctx, req)
+	if st != nil {
+		deleted, ok := j.lookupDeletedJob(ctx, req)
+		if !ok || !req.Force {
+			return nil, st.Err()
+		}
+		// Purge a job that was already deleted
+		if !j.deletedJobs.CompareAndDelete(deleted.data.id, deleted) {
+			// Restored or purged since we looked
+			return nil, st.Err()
+		}
+		j.purge(deleted.data, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_DELETED, user, "purged")
+		return &jobmanagerpb.DeleteJobResponse{}, nil
+	}
+	if !data.isFinished() {
+		return nil, status.Error(codes.FailedPrecondition, "Job must be finished to be deleted, stop it first")
+	}
+
+	grace := j.retention.DeleteGracePeriod
+	if !j.jobDirectory.CompareAndDelete(data.id, data) {
+		// Deleted by a concurrent request
+		return nil, status.Error(codes.NotFound, "No such job exists")
+	}
+	if req.Force || grace <= 0 {
+		j.purge(data, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_DELETED, user, "purged")
+		return &jobmanagerpb.DeleteJobResponse{}, nil
+	}
+
+	until := j.clock.Now().Add(grace)
+	j.deletedJobs.Store(data.id, &deletedJob{data: data, until: until})
+	data.detachReaders(errJobDeleted)
+	data.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_DELETED, user, 0,
+		fmt.Sprintf("restorable until %s", until.UTC().Format(time.RFC3339)))
+	return &jobmanagerpb.DeleteJobResponse{RestorableUntil: timestamppb.New(until)}, nil
+}
+
+func (j *Jobby) RestoreJob(ctx context.Context, req *jobmanagerpb.RestoreJobRequest) (*jobmanagerpb.RestoreJobResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	slog.Info("Handling 'RestoreJob' request", "user", user, "request", req)
+
+	deleted, ok := j.lookupDeletedJob(ctx, req)
+	// Past its grace period, it's as good as purged
+	if !ok || !j.clock.Now().Before(deleted.until) || !j.deletedJobs.CompareAndDelete(deleted.data.id, deleted) {
+		return nil, status.Error(codes.NotFound, "No such deleted job exists")
+	}
+	j.jobDirectory.Store(deleted.data.id, deleted.data)
+	deleted.data.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_RESTORED, user, 0, "")
+	return &jobmanagerpb.RestoreJobResponse{}, nil
+}
+
+// Like getJob, for jobs that were deleted without force
+func (j *Jobby) lookupDeletedJob(ctx context.Context, getter JobIDGetter) (*deletedJob, bool) {
+	id, err := jobid.Resolve(getter.GetJobId(), getter.GetId())
+	if err != nil {
+		return nil, false
+	}
+	value, ok := j.deletedJobs.Load(id)
+	if !ok {
+		return nil, false
+	}
+	deleted, ok := value.(*deletedJob)
+	if !ok || deleted.data.Owner != j.userGetter.GetUserContext(ctx) {
+		return nil, false
+	}
+	return deleted, true
+}
+
+// Whether the job is in the directory, or was deleted but may still be restored
+func (j *Jobby) jobExists(id uuid.UUID) bool {
+	if _, live := loadJob(&j.jobDirectory, id); live {
+		return true
+	}
+	_, deleted := j.deletedJobs.Load(id)
+	return deleted
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDescribeJob(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "alice"}
+	jobService := service.NewJobService(users, t.TempDir())
+
+	resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{
+			Command: testJobPath,
+			Args:    []string{"testjob", "2"},
+			Labels:  map[string]string{"team": "infra"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+
+	described, err := jobService.DescribeJob(ctx, &jobmanagerpb.DescribeJobRequest{Id: resp.Id})
+	require.NoError(t, err)
+	assert.Equal(t, resp.Id, described.Record.Id)
+	assert.Equal(t, map[string]string{"team": "infra"}, described.Record.Spec.Labels)
+	assert.Equal(t, jobmanagerpb.Status_STATUS_COMPLETE, described.Status.CurrentStatus)
+	require.Len(t, described.Attempts, 1)
+	stdoutBytes := uint64(len("stdout 1\nstdout 2\n"))
+	assert.Equal(t, stdoutBytes, described.Attempts[0].StdoutBytes)
+
+	require.NotEmpty(t, described.Events)
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED, described.Events[0].Type)
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_EXITED, described.Events[len(described.Events)-1].Type)
+
+	require.Len(t, described.Outputs, 2)
+	assert.Equal(t, jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT, described.Outputs[0].Type)
+	assert.Equal(t, stdoutBytes, described.Outputs[0].Bytes)
+	assert.Equal(t, uint32(1), described.Outputs[0].Files)
+	assert.False(t, described.Outputs[0].Segmented)
+	assert.False(t, described.Outputs[0].Encrypted)
+	assert.Equal(t, jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR, described.Outputs[1].Type)
+
+	assert.Equal(t, described.Attempts[0].StdoutBytes+described.Attempts[0].StderrBytes, described.Usage.OutputBytes)
+	assert.Positive(t, described.Usage.WallTime.AsDuration())
+
+	// Only for the job's owner
+	users.user = "bob"
+	_, err = jobService.DescribeJob(ctx, &jobmanagerpb.DescribeJobRequest{Id: resp.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
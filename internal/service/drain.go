@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DrainPolicy decides what happens to running jobs when the server shuts down
+type DrainPolicy struct {
+	// How long to wait for jobs expected to finish within it. Those still
+	// running afterwards are stopped like the rest. Zero stops every job
+	Timeout time.Duration
+	// How long stopped jobs have to exit after SIGTERM (ex: to checkpoint)
+	// before they're killed
+	StopGrace time.Duration
+}
+
+// Draining reports whether Drain has been called, so the server is on its
+// way out
+func (j *Jobby) Draining() bool {
+	return j.draining.Load()
+}
+
+// Drain gets the server ready to exit, wasting as little work as it can.
+// New jobs are refused, jobs expected to finish within the policy's timeout
+// (see JobSpec.expected_runtime) are waited for, and the rest are preempted
+// right away so they can checkpoint. Nothing is retried or requeued.
+// Returns once every job has exited and its post-job hooks have run, or
+// with ctx's error if it's done first
+func (j *Jobby) Drain(ctx context.Context, policy DrainPolicy) error {
+	j.draining.Store(true)
+
+	var waiting []*jobData
+	for _, d := range j.unfinishedJobs() {
+		if !d.drain() {
+			// Queued, so nothing to stop
+			continue
+		}
+		remaining, known := j.expectedRemaining(d)
+		if known && remaining <= policy.Timeout {
+			slog.Info("Waiting for job to finish before shutting down", "job-id", d.id, "expected-remaining", remaining)
+			waiting = append(waiting, d)
+			continue
+		}
+		slog.Info("Stopping long running job for shutdown", "job-id", d.id)
+		if err := d.preempt(policy.StopGrace); err != nil {
+			slog.Error("Failed to stop job for shutdown", "job-id", d.id, "error", err)
+		}
+	}
+
+	timer := j.clock.NewTimer(policy.Timeout)
+	defer timer.Stop()
+	if err := waitFinished(ctx, waiting, timer.C()); err != nil {
+		return err
+	}
+
+	// Including jobs that outlived their estimate, and any that
+	// slipped in while draining started
+	remaining := j.unfinishedJobs()
+	for _, d := range remaining {
+		if d.drain() {
+			slog.Info("Stopping job that didn't finish in time for shutdown", "job-id", d.id)
+			if err := d.preempt(policy.StopGrace); err != nil {
+				slog.Error("Failed to stop job for shutdown", "job-id", d.id, "error", err)
+			}
+		}
+	}
+	if err := waitFinished(ctx, remaining, nil); err != nil {
+		return err
+	}
+	// The jobs' hooks get to finish too
+	return j.hooks.wait(ctx)
+}
+
+// Waits for every job in 'jobs' to finish. Returns nil early once 'timeout'
+// fires, or ctx's error once it's done
+func waitFinished(ctx context.Context, jobs []*jobData, timeout <-chan time.Time) error {
+	for _, d := range jobs {
+		select {
+		case <-d.finished:
+		case <-timeout:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Jobs that are running or waiting to run again
+func (j *Jobby) unfinishedJobs() []*jobData {
+	var out []*jobData
+	j.jobDirectory.Range(func(_, value any) bool {
+		if d, ok := value.(*jobData); ok && !d.isFinished() {
+			out = append(out, d)
+		}
+		return true
+	})
+	return out
+}
+
+// How much longer the running attempt of 'd' is expected to take. Its spec's
+// expected runtime wins, otherwise it's the median of the owner's recent runs
+// of identical jobs. False if there's no telling. May be negative if the
+// attempt has already run longer than expected
+func (j *Jobby) expectedRemaining(d *jobData) (time.Duration, bool) {
+	elapsed := d.latest().job.Status().Duration
+	if d.spec.ExpectedRuntime != nil {
+		return d.spec.ExpectedRuntime.AsDuration() - elapsed, true
+	}
+	expected, ok := j.stats.expectedRuntime(d.Owner, d.specHash)
+	return expected - elapsed, ok
+}
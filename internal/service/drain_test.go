@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestDrain(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	start := func(tt *testing.T, spec *jobmanagerpb.JobSpec) []byte {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+	shell := func(script string) *jobmanagerpb.JobSpec {
+		return &jobmanagerpb.JobSpec{Command: "/bin/sh", Args: []string{"sh", "-c", script}}
+	}
+
+	// Earlier runs tell how long this one takes
+	const historyScript = "sleep 0.3"
+	first := start(t, shell(historyScript))
+	_, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: first})
+	require.NoError(t, err)
+
+	short := shell("sleep 0.5")
+	short.ExpectedRuntime = durationpb.New(500 * time.Millisecond)
+	shortJob := start(t, short)
+	historyJob := start(t, shell(historyScript))
+	// Checkpoints and exits on SIGTERM. Failing makes sure it isn't retried
+	long := shell("trap 'exit 1' TERM; while true; do sleep 0.05; done")
+	long.ExpectedRuntime = durationpb.New(time.Hour)
+	long.MaxAttempts = 3
+	longJob := start(t, long)
+	// Never ran before, so there's no telling how long it takes
+	unknownJob := start(t, shell("trap 'exit 0' TERM; while true; do sleep 0.05; done"))
+
+	drained := time.Now()
+	require.NoError(t, jobService.Drain(ctx, service.DrainPolicy{Timeout: 5 * time.Second, StopGrace: time.Second}))
+	assert.Less(t, time.Since(drained), 5*time.Second)
+
+	_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: shell("true")})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	getStatus := func(id []byte) *jobmanagerpb.GetStatusResponse {
+		resp, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+		require.NoError(t, err)
+		return resp
+	}
+	for _, id := range [][]byte{shortJob, historyJob} {
+		resp := getStatus(id)
+		assert.Equal(t, jobmanagerpb.ExitReason_EXIT_REASON_EXITED, resp.ExitReason)
+		require.NotNil(t, resp.ExitCode)
+		assert.Zero(t, *resp.ExitCode)
+	}
+	for _, id := range [][]byte{longJob, unknownJob} {
+		assert.Equal(t, jobmanagerpb.ExitReason_EXIT_REASON_PREEMPTED, getStatus(id).ExitReason)
+	}
+	history, err := jobService.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: longJob})
+	require.NoError(t, err)
+	assert.Len(t, history.Attempts, 1)
+}
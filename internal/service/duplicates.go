@@ -0,0 +1,89 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Fingerprint of the work a job does: its command, arguments and
+// environment. Everything else (ex: labels, retention) is bookkeeping
+func specHash(command string, args []string, env map[string]string) string {
+	h := sha256.New()
+	writeHashString(h, command)
+	writeHashLength(h, len(args))
+	for _, arg := range args {
+		writeHashString(h, arg)
+	}
+	keys := slices.Sorted(maps.Keys(env))
+	writeHashLength(h, len(keys))
+	for _, key := range keys {
+		writeHashString(h, key)
+		writeHashString(h, env[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Everything is length prefixed, so ["a b"] and ["a", "b"] hash differently
+func writeHashLength(h hash.Hash, n int) {
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(n)))
+}
+
+func writeHashString(h hash.Hash, s string) {
+	writeHashLength(h, len(s))
+	h.Write([]byte(s))
+}
+
+// Catches jobs submitted while an identical job of the same owner is
+// still running. Jobs in the job directory are found by scanning it.
+// Jobs still being started aren't in it yet, so they're kept here
+type duplicateDetector struct {
+	lock     sync.Mutex
+	starting map[*jobData]struct{}
+}
+
+func newDuplicateDetector() *duplicateDetector {
+	return &duplicateDetector{starting: map[*jobData]struct{}{}}
+}
+
+// Find an unfinished job identical to 'd' among 'jobs' and the jobs being
+// started. Unless one is found and 'force' is unset, 'd' is marked as
+// starting until 'done' is called, which must be after it's in 'jobs'
+// (or failed to start)
+func (dd *duplicateDetector) check(d *jobData, jobs *sync.Map, force bool) (duplicate *jobData, done func()) {
+	dd.lock.Lock()
+	defer dd.lock.Unlock()
+	isDuplicate := func(other *jobData) bool {
+		return other.Owner == d.Owner && other.specHash == d.specHash && !other.isFinished()
+	}
+	for other := range dd.starting {
+		if isDuplicate(other) {
+			duplicate = other
+			break
+		}
+	}
+	if duplicate == nil {
+		jobs.Range(func(_, value any) bool {
+			other, ok := value.(*jobData)
+			if ok && isDuplicate(other) {
+				duplicate = other
+				return false
+			}
+			return true
+		})
+	}
+	if duplicate != nil && !force {
+		return duplicate, nil
+	}
+
+	dd.starting[d] = struct{}{}
+	return duplicate, func() {
+		dd.lock.Lock()
+		defer dd.lock.Unlock()
+		delete(dd.starting, d)
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecHash(t *testing.T) {
+	hash := specHash("/bin/echo", []string{"echo", "a b"}, map[string]string{"A": "1", "B": "2"})
+	assert.Len(t, hash, 64)
+	// Environment order doesn't matter
+	assert.Equal(t, hash, specHash("/bin/echo", []string{"echo", "a b"}, map[string]string{"B": "2", "A": "1"}))
+
+	for _, other := range []string{
+		specHash("/bin/echo", []string{"echo", "a", "b"}, map[string]string{"A": "1", "B": "2"}),
+		specHash("/bin/echo", []string{"echo", "a b"}, map[string]string{"A": "1"}),
+		specHash("/bin/echo", []string{"echo", "a b"}, map[string]string{"A": "12"}),
+		specHash("/bin/echo", []string{"echo", "a b", "A", "1", "B", "2"}, nil),
+		specHash("/usr/bin/echo", []string{"echo", "a b"}, map[string]string{"A": "1", "B": "2"}),
+	} {
+		assert.NotEqual(t, hash, other)
+	}
+}
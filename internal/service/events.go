@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/audit"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Actor of events the server caused on its own
+const serverActor = "server"
+
+// Events of a job are kept this long after its last one, unless the job is still around
+const defaultEventRetention = 7 * 24 * time.Hour
+
+// A single line of the event log. Has store.Event's fields, so it converts to one
+type jobEvent struct {
+	JobID uuid.UUID `json:"job_id"`
+	// Owner of the job, so events can be authorized after the job is gone
+	Owner   string    `json:"owner"`
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`
+	Attempt uint32    `json:"attempt,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+func (e jobEvent) toProto() *jobmanagerpb.JobEvent {
+	return &jobmanagerpb.JobEvent{
+		Type:    jobmanagerpb.JobEventType(jobmanagerpb.JobEventType_value[e.Type]),
+		Time:    timestamppb.New(e.Time),
+		Actor:   e.Actor,
+		Attempt: e.Attempt,
+		Detail:  e.Detail,
+	}
+}
+
+// EventLog records the lifecycle events of every job. Events are appended to a
+// file as JSON lines, so they survive both garbage collection and restarts
+type EventLog struct {
+	// Events of jobs that are gone are dropped this long after their last event
+	retention time.Duration
+
+	lock sync.Mutex
+	// Empty for a log that's only kept in memory
+	path string
+	file *os.File
+	// Events of each job, oldest first
+	jobs map[uuid.UUID][]jobEvent
+	// Events are also written through to it. Nil if there's none
+	store store.Store
+	// Events are also sent to each as they're recorded
+	exporters []*audit.Exporter
+}
+
+func newMemoryEventLog() *EventLog {
+	return &EventLog{retention: defaultEventRetention, jobs: map[uuid.UUID][]jobEvent{}}
+}
+
+// OpenEventLog loads the events already in the file at 'path' (if any) and
+// appends new ones to it. Zero retention uses a week
+func OpenEventLog(path string, retention time.Duration) (*EventLog, error) {
+	l := newMemoryEventLog()
+	if retention > 0 {
+		l.retention = retention
+	}
+	l.path = path
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	var err error
+	if l.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600); err != nil {
+		return nil, fmt.Errorf("error opening event log: %w", err)
+	}
+	return l, nil
+}
+
+func (l *EventLog) load() error {
+	f, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening event log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event jobEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// Most likely a line cut short by a crash
+			slog.Warn("Skipping malformed event", "error", err)
+			continue
+		}
+		l.jobs[event.JobID] = append(l.jobs[event.JobID], event)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading event log: %w", err)
+	}
+	return nil
+}
+
+// Write events through to 's' from now on. Nil stops writing them
+func (l *EventLog) setStore(s store.Store) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.store = s
+}
+
+// ExportTo sends events recorded from now on to 'exporters' too (ex: a
+// SIEM's syslog collector). The caller closes them once the server's done
+func (l *EventLog) ExportTo(exporters ...*audit.Exporter) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.exporters = append(l.exporters, exporters...)
+}
+
+func (e jobEvent) audit() audit.Event {
+	return audit.Event{
+		Time:    e.Time,
+		JobID:   e.JobID.String(),
+		Owner:   e.Owner,
+		Type:    strings.TrimPrefix(e.Type, "JOB_EVENT_TYPE_"),
+		Actor:   e.Actor,
+		Attempt: e.Attempt,
+		Detail:  e.Detail,
+	}
+}
+
+// Close stops writing events to the file
+func (l *EventLog) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *EventLog) record(event jobEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	// Drops the monotonic reading and zone, so events compare the same after a reload
+	event.Time = event.Time.UTC()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.jobs[event.JobID] = append(l.jobs[event.JobID], event)
+	for _, exporter := range l.exporters {
+		exporter.Export(event.audit())
+	}
+	if l.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		err := l.store.AppendEvent(ctx, store.Event(event))
+		cancel()
+		if err != nil {
+			slog.Error("Failed to store job event", "job-id", event.JobID, "error", err)
+		}
+	}
+	if l.file == nil {
+		return
+	}
+	line, err := json.Marshal(event)
+	if err == nil {
+		_, err = l.file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		// The event is still served from memory
+		slog.Error("Failed to write job event", "job-id", event.JobID, "error", err)
+	}
+}
+
+// Events of a job, oldest first. Nil if there aren't any
+func (l *EventLog) forJob(id uuid.UUID) []jobEvent {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return append([]jobEvent(nil), l.jobs[id]...)
+}
+
+// Forget the events of jobs that are gone and whose last event is older than
+// the retention. The file is rewritten without them
+func (l *EventLog) prune(now time.Time, live func(uuid.UUID) bool) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	pruned := false
+	for id, events := range l.jobs {
+		if now.Sub(events[len(events)-1].Time) >= l.retention && !live(id) {
+			delete(l.jobs, id)
+			pruned = true
+			l.pruneStore(id)
+		}
+	}
+	if !pruned || l.file == nil {
+		return nil
+	}
+	return l.rewrite()
+}
+
+// Caller must hold the lock
+func (l *EventLog) pruneStore(id uuid.UUID) {
+	if l.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := l.store.DeleteEvents(ctx, id); err != nil {
+		slog.Error("Failed to remove job events from store", "job-id", id, "error", err)
+	}
+}
+
+// Replace the file with the events still in memory. Caller must hold the lock
+func (l *EventLog) rewrite() error {
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating event log: %w", err)
+	}
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, events := range l.jobs {
+		for _, event := range events {
+			if err = encoder.Encode(event); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err = errors.Join(err, tmp.Close()); err == nil {
+		err = os.Rename(tmp.Name(), l.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("error rewriting event log: %w", err)
+	}
+
+	// Later events go to the new file
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("error opening event log: %w", err)
+	}
+	_ = l.file.Close()
+	l.file = file
+	return nil
+}
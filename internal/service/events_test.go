@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	log, err := OpenEventLog(path, time.Hour)
+	require.NoError(t, err)
+	gone, live := uuid.New(), uuid.New()
+	log.record(jobEvent{JobID: gone, Owner: "someuser", Type: "JOB_EVENT_TYPE_CREATED", Actor: "someuser"})
+	log.record(jobEvent{JobID: live, Owner: "someuser", Type: "JOB_EVENT_TYPE_CREATED", Actor: "someuser"})
+	log.record(jobEvent{JobID: gone, Owner: "someuser", Type: "JOB_EVENT_TYPE_EXITED", Actor: serverActor, Attempt: 1, Detail: "EXITED (exit code 0)"})
+	require.NoError(t, log.Close())
+
+	// A line cut short by a crash doesn't lose the rest
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"job_id":`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	log, err = OpenEventLog(path, time.Hour)
+	require.NoError(t, err)
+	defer log.Close()
+	events := log.forJob(gone)
+	require.Len(t, events, 2)
+	assert.Equal(t, "JOB_EVENT_TYPE_EXITED", events[1].Type)
+	assert.Equal(t, uint32(1), events[1].Attempt)
+	assert.Equal(t, "EXITED (exit code 0)", events[1].Detail)
+	assert.Len(t, log.forJob(live), 1)
+	assert.Nil(t, log.forJob(uuid.New()))
+
+	t.Run("prune", func(tt *testing.T) {
+		isLive := func(id uuid.UUID) bool { return id == live }
+		require.NoError(tt, log.prune(time.Now(), isLive))
+		assert.Len(tt, log.forJob(gone), 2)
+
+		// Events of jobs that are still around are kept regardless
+		require.NoError(tt, log.prune(time.Now().Add(2*time.Hour), isLive))
+		assert.Nil(tt, log.forJob(gone))
+		assert.Len(tt, log.forJob(live), 1)
+
+		// The file is rewritten, and still appended to afterwards
+		log.record(jobEvent{JobID: live, Owner: "someuser", Type: "JOB_EVENT_TYPE_STARTED", Actor: serverActor, Attempt: 1})
+		reloaded, err := OpenEventLog(path, time.Hour)
+		require.NoError(tt, err)
+		defer reloaded.Close()
+		assert.Nil(tt, reloaded.forJob(gone))
+		assert.Equal(tt, log.forJob(live), reloaded.forJob(live))
+	})
+}
+
+func TestEventExport(t *testing.T) {
+	collector, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer collector.Close()
+	exporter, err := audit.New(audit.Config{Name: "siem", Format: audit.FormatCEF, Network: audit.NetworkUDP, Address: collector.LocalAddr().String()})
+	require.NoError(t, err)
+	log := newMemoryEventLog()
+	log.ExportTo(exporter)
+
+	id := uuid.New()
+	log.record(jobEvent{JobID: id, Owner: "someuser", Type: "JOB_EVENT_TYPE_STARTED", Actor: serverActor, Attempt: 1})
+	require.NoError(t, collector.SetReadDeadline(time.Now().Add(10*time.Second)))
+	buf := make([]byte, 2048)
+	n, _, err := collector.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), "|STARTED|Job started|")
+	assert.Contains(t, string(buf[:n]), "cs1="+id.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, exporter.Close(ctx))
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+)
+
+// Limits on how long jobs stick around after they finish
+type RetentionLimits struct {
+	// Applied to jobs that don't specify a retention policy.
+	// Zero keeps them forever
+	DefaultTTL time.Duration
+	// Longest TTL a job may ask for. Zero means no limit
+	MaxTTL time.Duration
+	// Whether jobs may ask to be kept forever
+	AllowKeepForever bool
+	// How long jobs deleted without force may be restored (see DeleteJob).
+	// Zero purges every deleted job right away
+	DeleteGracePeriod time.Duration
+}
+
+// The original behavior: nothing is ever deleted unless its owner asks
+var defaultRetentionLimits = RetentionLimits{
+	AllowKeepForever:  true,
+	DeleteGracePeriod: 24 * time.Hour,
+}
+
+// Resolve the requested policy against the server's limits.
+// A TTL of zero means keep forever
+func (r RetentionLimits) resolve(policy *jobmanagerpb.RetentionPolicy) (time.Duration, error) {
+	switch p := policy.GetPolicy().(type) {
+	case nil:
+		return r.DefaultTTL, nil
+	case *jobmanagerpb.RetentionPolicy_KeepForever:
+		if !p.KeepForever {
+			return r.DefaultTTL, nil
+		}
+		if !r.AllowKeepForever {
+			return 0, errors.New("keeping jobs forever is not allowed by this server")
+		}
+		return 0, nil
+	case *jobmanagerpb.RetentionPolicy_Ttl:
+		ttl := p.Ttl.AsDuration()
+		if ttl <= 0 {
+			return 0, errors.New("retention ttl must be positive")
+		}
+		if r.MaxTTL > 0 && ttl > r.MaxTTL {
+			return 0, fmt.Errorf("retention ttl must not exceed %s", r.MaxTTL)
+		}
+		return ttl, nil
+	default:
+		return 0, fmt.Errorf("unknown retention policy %T", p)
+	}
+}
+
+// Whether the job has finished and outlived its retention
+func (d *jobData) expired(now time.Time) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.retention == 0 || d.finishedAt.IsZero() {
+		return false
+	}
+	return now.Sub(d.finishedAt) >= d.retention
+}
+
+// Delete output files for every attempt, returning the space to the owner's quota
+func (d *jobData) removeOutputs() error {
+	if d.adopted {
+		// The files belong to whoever started the process
+		return nil
+	}
+	var errs []error
+	for _, a := range d.history() {
+		outputs, err := a.job.Outputs()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, output := range outputs {
+			// More than one file if the job has an output window
+			for _, file := range output.Files {
+				// Quotas count plaintext, which is what the files' sizes are in
+				if err := os.Remove(file.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					errs = append(errs, err)
+				} else if err == nil && d.quota != nil {
+					d.quota.Release(file.Size)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Delete the job's output and record why. The job must already be out of
+// the job directory (and deletedJobs), so nobody can attach to output
+// we're about to delete
+func (j *Jobby) purge(data *jobData, reason jobmanagerpb.JobEventType, actor string, detail string) {
+	data.detachReaders(errJobDeleted)
+	if err := data.removeOutputs(); err != nil {
+		slog.Error("Failed to remove job output", "job-id", data.id, "error", err)
+	}
+	if err := data.removeScratch(); err != nil {
+		slog.Error("Failed to remove job scratch directory", "job-id", data.id, "error", err)
+	}
+	data.recordEvent(reason, actor, 0, detail)
+	if j.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		if err := j.store.DeleteJob(ctx, data.id); err != nil {
+			slog.Error("Failed to remove job record from store", "job-id", data.id, "error", err)
+		}
+	}
+}
+
+// CollectGarbage deletes jobs (and their output) whose retention has expired,
+// and purges deleted jobs whose grace period is up. Returns the number of jobs removed
+func (j *Jobby) CollectGarbage(now time.Time) int {
+	removed := 0
+	j.jobDirectory.Range(func(key, value any) bool {
+		data, ok := value.(*jobData)
+		if !ok || !data.expired(now) {
+			return true
+		}
+		if j.jobDirectory.CompareAndDelete(key, data) {
+			j.purge(data, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_GARBAGE_COLLECTED, serverActor, "")
+			removed++
+		}
+		return true
+	})
+	j.deletedJobs.Range(func(key, value any) bool {
+		deleted, ok := value.(*deletedJob)
+		if !ok || now.Before(deleted.until) {
+			return true
+		}
+		// Unless it was restored or purged since
+		if j.deletedJobs.CompareAndDelete(key, deleted) {
+			j.purge(deleted.data, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_GARBAGE_COLLECTED, serverActor, "")
+			removed++
+		}
+		return true
+	})
+
+	err := j.events.prune(now, j.jobExists)
+	if err != nil {
+		slog.Error("Failed to prune job events", "error", err)
+	}
+	return removed
+}
+
+// RunGarbageCollector collects garbage every interval until the context is cancelled
+func (j *Jobby) RunGarbageCollector(ctx context.Context, interval time.Duration) {
+	ticker := j.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			if removed := j.CollectGarbage(now); removed > 0 {
+				slog.Info("Garbage collected expired jobs", "count", removed)
+			}
+		}
+	}
+}
+
+// CollectStore deletes records (and events) of other servers' jobs whose
+// retention has expired. Servers collect their own jobs' records along with
+// the jobs, so this only matters for servers that are gone for good. It
+// should be run by one of the servers sharing the store (see package leader).
+// Returns the number of records removed
+func (j *Jobby) CollectStore(ctx context.Context, now time.Time) (int, error) {
+	if j.store == nil {
+		return 0, nil
+	}
+	jobs, err := j.store.ListJobs(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	var errs []error
+	for _, stored := range jobs {
+		if stored.Node == j.node || j.jobExists(stored.ID) {
+			continue
+		}
+		end := stored.Record.GetEndTime()
+		if end == nil {
+			// Still running, or its server died before it could say otherwise
+			continue
+		}
+		ttl, err := j.retention.resolve(stored.Record.GetSpec().GetRetention())
+		if err != nil || ttl == 0 || now.Sub(end.AsTime()) < ttl {
+			continue
+		}
+		if err := j.store.DeleteJob(ctx, stored.ID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := j.store.DeleteEvents(ctx, stored.ID); err != nil {
+			errs = append(errs, err)
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// RunStoreCollector collects the store's garbage every interval until the
+// context is cancelled
+func (j *Jobby) RunStoreCollector(ctx context.Context, interval time.Duration) {
+	ticker := j.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			removed, err := j.CollectStore(ctx, now)
+			if err != nil && ctx.Err() == nil {
+				slog.Error("Failed to collect store garbage", "error", err)
+			}
+			if removed > 0 {
+				slog.Info("Garbage collected records of other servers' jobs", "count", removed)
+			}
+		}
+	}
+}
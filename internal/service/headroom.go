@@ -0,0 +1,106 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/gopheryan/jobby/job"
+	"golang.org/x/sys/unix"
+)
+
+// Headroom is how much of the host has to be left once a job starts, so jobs
+// aren't piled onto a machine that's already saturated. Jobs that would cut
+// into it are refused. Zero fields aren't checked
+type Headroom struct {
+	// Bytes of memory that must stay available, after setting aside the
+	// job's memory limit
+	MinFreeMemory uint64
+	// Free bytes on the filesystem job output is written to
+	MinFreeDisk uint64
+	// Highest the 1 minute load average per CPU may get, counting the
+	// CPUs the job is limited to (or one, if it isn't)
+	MaxLoadPerCPU float64
+}
+
+func (h Headroom) enabled() bool {
+	return h.MinFreeMemory > 0 || h.MinFreeDisk > 0 || h.MaxLoadPerCPU > 0
+}
+
+// What the host is short of to take a job with 'limits' while keeping the
+// headroom. Empty if it has room
+func (h Headroom) check(directory string, limits job.Limits) (string, error) {
+	var memory int64
+	cpus := 1.0
+	if limits.Cgroup != nil {
+		memory = limits.Cgroup.MemoryMax
+		if limits.Cgroup.CPUs > 0 {
+			cpus = limits.Cgroup.CPUs
+		}
+	}
+	if h.MinFreeMemory > 0 {
+		available, err := availableMemory()
+		if err != nil {
+			return "", fmt.Errorf("error reading available memory: %w", err)
+		}
+		if available < uint64(memory) || available-uint64(memory) < h.MinFreeMemory {
+			return fmt.Sprintf("%d bytes of memory available, the job may use %d and %d must stay free", available, memory, h.MinFreeMemory), nil
+		}
+	}
+	if h.MinFreeDisk > 0 {
+		var fs unix.Statfs_t
+		if err := unix.Statfs(directory, &fs); err != nil {
+			return "", fmt.Errorf("error reading free disk space: %w", err)
+		}
+		if free := fs.Bavail * uint64(fs.Bsize); free < h.MinFreeDisk {
+			return fmt.Sprintf("%d bytes of disk free for output, and %d must stay free", free, h.MinFreeDisk), nil
+		}
+	}
+	if h.MaxLoadPerCPU > 0 {
+		load, err := loadAverage()
+		if err != nil {
+			return "", fmt.Errorf("error reading load average: %w", err)
+		}
+		if perCPU := (load + cpus) / float64(runtime.NumCPU()); perCPU > h.MaxLoadPerCPU {
+			return fmt.Sprintf("load would be %.2f per CPU with the job, over the limit of %.2f", perCPU, h.MaxLoadPerCPU), nil
+		}
+	}
+	return "", nil
+}
+
+// MemAvailable from /proc/meminfo, in bytes
+func availableMemory() (uint64, error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// Ex: "MemAvailable:   12345678 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "MemAvailable:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			return kb * 1024, err
+		}
+	}
+	return 0, errors.New("MemAvailable not found in /proc/meminfo")
+}
+
+// The 1 minute load average
+func loadAverage() (float64, error) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, err
+	}
+	// Ex: "0.52 0.58 0.59 1/467 12345"
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected /proc/loadavg: %q", data)
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
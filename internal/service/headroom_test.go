@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHeadroom(t *testing.T) {
+	ctx := context.Background()
+	start := func(tt *testing.T, headroom service.Headroom, class string) error {
+		jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, tt.TempDir(),
+			service.WithCapacity(service.Capacity{Headroom: headroom}),
+			service.WithRuntimeClasses(map[string]job.Limits{
+				// Never started, so the parent doesn't matter
+				"huge": {Cgroup: &job.CgroupLimits{Parent: "/sys/fs/cgroup/jobby", MemoryMax: math.MaxInt64}},
+			}, ""),
+		)
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/true", RuntimeClass: class}})
+		if err == nil {
+			_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+			require.NoError(tt, err)
+		}
+		return err
+	}
+
+	t.Run("room", func(tt *testing.T) {
+		assert.NoError(tt, start(tt, service.Headroom{MinFreeMemory: 1, MinFreeDisk: 1, MaxLoadPerCPU: math.MaxFloat64}, ""))
+	})
+
+	t.Run("memory", func(tt *testing.T) {
+		err := start(tt, service.Headroom{MinFreeMemory: 1}, "huge")
+		assert.Equal(tt, codes.ResourceExhausted, status.Code(err))
+		assert.Contains(tt, status.Convert(err).Message(), "bytes of memory available")
+	})
+
+	t.Run("disk", func(tt *testing.T) {
+		err := start(tt, service.Headroom{MinFreeDisk: math.MaxUint64}, "")
+		assert.Equal(tt, codes.ResourceExhausted, status.Code(err))
+		assert.Contains(tt, status.Convert(err).Message(), "bytes of disk free")
+	})
+
+	t.Run("load", func(tt *testing.T) {
+		// The job alone puts a load of more than nothing on the host
+		err := start(tt, service.Headroom{MaxLoadPerCPU: math.SmallestNonzeroFloat64}, "")
+		assert.Equal(tt, codes.ResourceExhausted, status.Code(err))
+		assert.Contains(tt, status.Convert(err).Message(), "per CPU")
+	})
+}
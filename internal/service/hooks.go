@@ -0,0 +1,236 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Hooks without a timeout get this long
+const defaultHookTimeout = time.Minute
+
+// How much of a failed command hook's output makes it into the job's events
+const maxHookOutput = 256
+
+// Hook is a command or webhook the server runs on a job's behalf (see
+// WithPreStartHooks and WithPostJobHooks). Commands are handed JSON about
+// the job on stdin, webhooks as the body of a POST. Whether it succeeds
+// is recorded as one of the job's events
+type Hook struct {
+	// Identifies the hook in events and logs
+	Name string
+	// Program and arguments to run. Along with the server's environment it
+	// gets JOBBY_JOB_ID, JOBBY_JOB_OWNER and the job's JOBBY_SCRATCH if it
+	// has one, plus what the kind of hook adds
+	Command []string
+	// Webhook to POST to instead of running a command. Any 2xx is a
+	// success. The job's ID and owner are in the X-Jobby-Job-Id and
+	// X-Jobby-Job-Owner headers
+	URL string
+	// How long the hook may take. Zero uses a minute
+	Timeout time.Duration
+}
+
+// WithPreStartHooks runs 'hooks' in order before each attempt of a job
+// starts (ex: to fetch a dataset or create a scratch directory). They're
+// handed the job's spec, and commands get the number of the attempt about
+// to start in JOBBY_ATTEMPT. The first to fail stops the rest, and the
+// attempt isn't started. Before the first attempt that fails StartJob,
+// which waits for the hooks to finish
+func WithPreStartHooks(hooks []Hook) Option {
+	return func(j *Jobby) {
+		j.hookRunner().preStart = hooks
+	}
+}
+
+// WithPostJobHooks runs 'hooks' in order once each job finishes (ex: to
+// compress its output, upload artifacts or update a ticket). They're
+// handed the job's record, and commands get JOBBY_STATE_REASON and the
+// paths of the last attempt's output in JOBBY_STDOUT and JOBBY_STDERR.
+// Output may be encrypted or segmented, as the job asked
+func WithPostJobHooks(hooks []Hook) Option {
+	return func(j *Jobby) {
+		j.hookRunner().postJob = hooks
+	}
+}
+
+func (j *Jobby) hookRunner() *hookRunner {
+	if j.hooks == nil {
+		j.hooks = &hookRunner{client: &http.Client{}}
+	}
+	return j.hooks
+}
+
+// Runs pre-start and post-job hooks
+type hookRunner struct {
+	preStart []Hook
+	postJob  []Hook
+	client   *http.Client
+	// Jobs whose post-job hooks are still running
+	running sync.WaitGroup
+}
+
+// Run the pre-start hooks of attempt 'number' of 'd', stopping at the first
+// failure. Its error names the hook. Safe to call on a nil runner
+func (r *hookRunner) beforeAttempt(ctx context.Context, d *jobData, number uint32) error {
+	if r == nil || len(r.preStart) == 0 {
+		return nil
+	}
+	body, err := protojson.Marshal(d.spec)
+	if err != nil {
+		return fmt.Errorf("error encoding job spec: %w", err)
+	}
+	env := []string{"JOBBY_ATTEMPT=" + strconv.FormatUint(uint64(number), 10)}
+	for _, hook := range r.preStart {
+		if err := r.run(ctx, hook, d, number, body, env); err != nil {
+			return fmt.Errorf("%s: %w", hook.Name, err)
+		}
+	}
+	return nil
+}
+
+// Run the post-job hooks of a job that just finished, without waiting
+// for them. Safe to call on a nil runner
+func (r *hookRunner) start(d *jobData) {
+	if r == nil || len(r.postJob) == 0 {
+		return
+	}
+	r.running.Add(1)
+	go func() {
+		defer r.running.Done()
+		r.afterJob(d)
+	}()
+}
+
+// Wait for running post-job hooks, or until ctx is done. Safe to call on
+// a nil runner
+func (r *hookRunner) wait(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		r.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *hookRunner) afterJob(d *jobData) {
+	record := d.record()
+	body, err := protojson.Marshal(record)
+	if err != nil {
+		slog.Error("Failed to encode job record for hooks", "job-id", d.id, "error", err)
+		return
+	}
+	attempt := record.Attempts
+	env := []string{
+		"JOBBY_STATE_REASON=" + strings.TrimPrefix(record.StateReason.String(), "STATE_REASON_"),
+		"JOBBY_STDOUT=" + filepath.Join(d.directory, outFileName(d.id, attempt, job.OutputStdout)),
+		"JOBBY_STDERR=" + filepath.Join(d.directory, outFileName(d.id, attempt, job.OutputStderr)),
+	}
+	for _, hook := range r.postJob {
+		// Failures are recorded, and don't keep the rest from running
+		_ = r.run(context.Background(), hook, d, 0, body, env)
+	}
+}
+
+// Run 'hook' with 'body' as its input and record how it went as an event
+// about 'attempt'. 'env' is added to commands' environments
+func (r *hookRunner) run(ctx context.Context, hook Hook, d *jobData, attempt uint32, body []byte, env []string) error {
+	timeout := hook.Timeout
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var err error
+	if hook.URL != "" {
+		err = r.post(ctx, hook, d, body)
+	} else {
+		err = runHookCommand(ctx, hook, d, body, env)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		slog.Error("Job hook failed", "job-id", d.id, "hook", hook.Name, "error", err)
+		d.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_FAILED, serverActor, attempt, hook.Name+": "+err.Error())
+		return err
+	}
+	d.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_SUCCEEDED, serverActor, attempt, hook.Name)
+	return nil
+}
+
+func (r *hookRunner) post(ctx context.Context, hook Hook, d *jobData, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Jobby-Hook", hook.Name)
+	req.Header.Set("X-Jobby-Job-Id", d.id.String())
+	req.Header.Set("X-Jobby-Job-Owner", d.Owner)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func runHookCommand(ctx context.Context, hook Hook, d *jobData, body []byte, env []string) error {
+	cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), JobIDEnv+"="+d.id.String(), "JOBBY_JOB_OWNER="+d.Owner)
+	if scratch := d.scratchPath(); scratch != "" {
+		cmd.Env = append(cmd.Env, ScratchEnv+"="+scratch)
+	}
+	cmd.Env = append(cmd.Env, env...)
+	output := &headWriter{max: maxHookOutput}
+	cmd.Stdout, cmd.Stderr = output, output
+	// Don't wait on pipes held open by whatever the hook left behind
+	cmd.WaitDelay = time.Second
+	if err := job.RunCommand(cmd); err != nil {
+		if out := strings.TrimSpace(string(output.buf)); out != "" {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+// Keeps the first 'max' bytes written to it and discards the rest
+type headWriter struct {
+	buf []byte
+	max int
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if room := w.max - len(w.buf); room > 0 {
+		w.buf = append(w.buf, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
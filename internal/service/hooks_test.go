@@ -0,0 +1,196 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostJobHooks(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	received := make(chan map[string]any, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record map[string]any
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &record)
+		assert.Equal(t, "notify", r.Header.Get("X-Jobby-Hook"))
+		received <- record
+	}))
+	defer webhook.Close()
+
+	out := filepath.Join(dir, "hook.out")
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, dir, service.WithPostJobHooks([]service.Hook{
+		{Name: "record", Command: []string{"/bin/sh", "-c", `{ cat; echo; echo "$JOBBY_JOB_OWNER $JOBBY_STATE_REASON"; } > ` + out}},
+		{Name: "notify", URL: webhook.URL},
+		{Name: "broken", Command: []string{"/bin/sh", "-c", "echo oops; exit 1"}},
+		{Name: "slow", Command: []string{"/bin/sleep", "10"}, Timeout: 100 * time.Millisecond},
+	}))
+
+	resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/true"}})
+	require.NoError(t, err)
+	_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+
+	var hookEvents []*jobmanagerpb.JobEvent
+	require.Eventually(t, func() bool {
+		events, err := jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: resp.JobId})
+		require.NoError(t, err)
+		hookEvents = nil
+		for _, event := range events.Events {
+			switch event.Type {
+			case jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_SUCCEEDED, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_FAILED:
+				hookEvents = append(hookEvents, event)
+			}
+		}
+		return len(hookEvents) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Hooks run in order
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_SUCCEEDED, hookEvents[0].Type)
+	assert.Equal(t, "record", hookEvents[0].Detail)
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_SUCCEEDED, hookEvents[1].Type)
+	assert.Equal(t, "notify", hookEvents[1].Detail)
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_FAILED, hookEvents[2].Type)
+	assert.Equal(t, "broken: exit status 1: oops", hookEvents[2].Detail)
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_FAILED, hookEvents[3].Type)
+	assert.Equal(t, "slow: timed out after 100ms", hookEvents[3].Detail)
+	for _, event := range hookEvents {
+		assert.Equal(t, "server", event.Actor)
+	}
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "STATE_REASON_COMPLETED", record["stateReason"])
+	assert.Equal(t, "someuser COMPLETED", lines[1])
+
+	record = <-received
+	assert.Equal(t, "/bin/true", record["command"])
+}
+
+// Subreaping is for the whole process, so it's tested in a process of its own
+func TestHooksSubreaper(t *testing.T) {
+	if os.Getenv("JOBBY_TEST_SUBREAPER") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHooksSubreaper$", "-test.v")
+		cmd.Env = append(os.Environ(), "JOBBY_TEST_SUBREAPER=1")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return
+	}
+
+	// Sweeping all the time, so a hook taken for an orphan would be adopted,
+	// and sometimes reaped out from under its hook runner
+	var logs syncBuffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	require.NoError(t, job.EnableSubreaper(job.ReaperOptions{SweepInterval: time.Millisecond}))
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(), service.WithPreStartHooks([]service.Hook{
+		{Name: "ready", Command: []string{"/bin/sleep", "0.05"}},
+	}))
+	for range 20 {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/true"}})
+		require.NoError(t, err)
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(t, err)
+	}
+	assert.False(t, strings.Contains(logs.String(), "Adopted orphaned job process"), "hook taken for an orphan")
+}
+
+// For logs written from several goroutines
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestPreStartHooks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("prepares attempts", func(tt *testing.T) {
+		dir := tt.TempDir()
+		scratch := filepath.Join(dir, "scratch")
+		jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, dir, service.WithPreStartHooks([]service.Hook{
+			{Name: "scratch", Command: []string{"/bin/sh", "-c", `mkdir -p ` + scratch + ` && echo "$JOBBY_JOB_OWNER $JOBBY_ATTEMPT" > ` + scratch + `/ready`}},
+		}))
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{
+			Command: "/bin/grep", Args: []string{"grep", "-q", "someuser 1", filepath.Join(scratch, "ready")},
+		}})
+		require.NoError(tt, err)
+		st, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_COMPLETED, st.StateReason)
+
+		events, err := jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		var types []jobmanagerpb.JobEventType
+		for _, event := range events.Events {
+			types = append(types, event.Type)
+		}
+		// Before the attempt it prepared
+		assert.Equal(tt, []jobmanagerpb.JobEventType{
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_HOOK_SUCCEEDED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_STARTED,
+		}, types[:3])
+		assert.Equal(tt, uint32(1), events.Events[1].Attempt)
+	})
+
+	t.Run("fails start", func(tt *testing.T) {
+		jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, tt.TempDir(), service.WithPreStartHooks([]service.Hook{
+			{Name: "fetch-dataset", Command: []string{"/bin/sh", "-c", "echo no such dataset; exit 3"}},
+		}))
+		_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/true"}})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(tt, "Pre-start hook failed: fetch-dataset: exit status 3: no such dataset", status.Convert(err).Message())
+	})
+
+	t.Run("fails retry", func(tt *testing.T) {
+		jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, tt.TempDir(), service.WithPreStartHooks([]service.Hook{
+			{Name: "first-only", Command: []string{"/bin/sh", "-c", `[ "$JOBBY_ATTEMPT" = 1 ]`}},
+		}))
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/false", MaxAttempts: 3}})
+		require.NoError(tt, err)
+		st, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_PRE_HOOK_FAILED, st.StateReason)
+		assert.Equal(tt, "pre-start hook failed before attempt 2: first-only: exit status 1", st.StateMessage)
+
+		history, err := jobService.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Len(tt, history.Attempts, 1)
+	})
+}
@@ -0,0 +1,740 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/encryption"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Upper bound on StartJobRequest.max_attempts so a crash looping
+// command can't run forever
+const maxAttemptsLimit = 10
+
+// How long writes to the store may take before they're given up on
+const storeTimeout = 5 * time.Second
+
+// Final status of GetJobOutput streams ended because their job went away
+var (
+	errJobStopped = status.Error(codes.Aborted, "Job was stopped")
+	errJobDeleted = status.Error(codes.NotFound, "Job was deleted")
+)
+
+// Returned by RPCs that would start jobs once Drain has been called
+var errShuttingDown = status.Error(codes.Unavailable, "Server is shutting down")
+
+// Why a job was stopped
+type stopCause int32
+
+const (
+	stopByOwner stopCause = iota
+	// The owner's credentials lapsed (see CredentialLeases)
+	stopCredentialsLapsed
+	// The job's lease wasn't renewed in time (see RenewJobLease)
+	stopLeaseExpired
+)
+
+// A single execution of the job's command
+type attempt struct {
+	// Starts at 1
+	number uint32
+	job    *job.Job
+}
+
+type jobData struct {
+	// User who owns the job
+	Owner string
+
+	id          uuid.UUID
+	maxAttempts uint32
+	// The job as submitted. Not modified once the job starts
+	spec *jobmanagerpb.JobSpec
+	// See specHash. Identical jobs have the same hash
+	specHash string
+	// Base directory for output files
+	directory string
+	// How long to keep the job after it finishes. Zero keeps it forever
+	retention time.Duration
+	// Name of the runtime class the limits came from
+	runtimeClass string
+	limits       job.Limits
+	// Owner's output quota. Nil when unlimited
+	quota       *userQuota
+	quotaAction job.QuotaAction
+	// Wraps the data key of the job's output. Nil if output is plaintext
+	keys       encryption.KeyWrapper
+	wrappedKey []byte
+	redactions []job.Redaction
+	outputSync job.SyncPolicy
+	// Finished attempts are counted toward the owner's usage here
+	usage *usageTracker
+	// And recorded for GetJobStats here
+	stats *statsTracker
+	// Nil if the job's record is only kept in memory
+	store store.Store
+	// This server's name in the store (see WithNode)
+	node string
+	// What the job was launched with. Nil unless the spec asked for it
+	snapshot *jobmanagerpb.LaunchSnapshot
+	// Hands out the slot the job runs in
+	scheduler *scheduler
+	// Where the job's lifecycle events are recorded
+	events *EventLog
+	// Runs before each attempt and once the job finishes. Nil if there
+	// are no hooks
+	hooks  *hookRunner
+	clock  clock.Clock
+	faults *job.FaultInjector
+	// Session the job was started in (see EndSession). Empty if none
+	session string
+	// Registered with AdoptProcess. Its output files aren't ours to delete
+	adopted bool
+	// Job whose token started this one. Zero if none
+	parent uuid.UUID
+	// Given to the job to call back with, and revoked from 'tokens' once
+	// it finishes. Empty if it has none
+	token  string
+	tokens *JobTokens
+	// When the job was submitted. With the real clock it keeps its monotonic
+	// reading, so the job's duration survives wall clock adjustments
+	startedAt time.Time
+	// Set before the server stops the job on its own (see stopFor).
+	// Atomic, since it's read while the job signals under the lock
+	stopCause atomic.Int32
+
+	// Guards everything below
+	lock     sync.Mutex
+	attempts []*attempt
+	// Set once the user stops the job. No further attempts are made
+	stopped bool
+	// Attempts cut short by preemption. They don't count toward maxAttempts
+	preemptions uint32
+	// Preempted and waiting in the scheduler's queue to run again
+	queued bool
+	// Set once the server starts shutting down (see Drain). No further
+	// attempts are made
+	draining bool
+	// Number of the attempt that couldn't be started, ending the job.
+	// Zero if they all started
+	startFailure uint32
+	// Why a pre-start hook kept attempt 'startFailure' from starting.
+	// Empty if it was the attempt itself that failed
+	preHookFailure string
+	// When the job's lease runs out. Zero if it has none (see watchLease)
+	leaseExpires time.Time
+	// Lets the next job waiting for the job's mutex start once it finishes.
+	// Nil if it has none
+	releaseMutex func()
+	// When the last attempt finished. Zero until then (see finish)
+	finishedAt time.Time
+	// Closed once finishedAt is set
+	finished chan struct{}
+	// Set with AnnotateJob. Replaced rather than modified, so records
+	// may share it
+	annotations map[string]string
+	// GetJobOutput streams attached to the job. Each is ended with
+	// its cancel func's cause when the job is stopped or deleted
+	readers map[*outputReader]struct{}
+}
+
+type outputReader struct {
+	cancel context.CancelCauseFunc
+}
+
+// Start the next attempt. Caller must hold the lock
+func (d *jobData) startAttempt() (*attempt, error) {
+	number := uint32(len(d.attempts) + 1)
+	stdoutName := outFileName(d.id, number, job.OutputStdout)
+	stderrName := outFileName(d.id, number, job.OutputStderr)
+	a := &attempt{number: number}
+
+	// Tenths of the way the attempt has reported getting. Only these are
+	// recorded as events, so chatty jobs don't flood the event log
+	var progressStep atomic.Int32
+	// The job creates the files beneath our directory and
+	// refuses names that would land anywhere else
+	command, commandArgs := specCommand(d.spec)
+	args := job.JobArgs{
+		Command:      command,
+		Args:         commandArgs,
+		Env:          specEnv(d.spec),
+		OutputDir:    d.directory,
+		StdoutPath:   stdoutName,
+		StderrPath:   stderrName,
+		QuotaAction:  d.quotaAction,
+		OutputWindow: int64(d.spec.OutputWindowBytes),
+		Segments:     specSegments(d.spec),
+		Limits:       d.limits,
+		Redactions:   d.redactions,
+		Timestamps:   d.spec.TimestampOutput,
+		Stdin:        d.spec.Stdin,
+		Sync:         d.outputSync,
+		Clock:        d.clock,
+		Faults:       d.faults,
+		Scheduling:   specScheduling(d.spec),
+		OnSignal:     d.onSignal(number),
+	}
+	if d.token != "" {
+		// Not in the spec, so it's never recorded anywhere
+		args.Env = append(args.Env, JobTokenEnv+"="+d.token, JobIDEnv+"="+d.id.String())
+	}
+	if scratch := d.scratchPath(); scratch != "" {
+		args.Env = append(args.Env, ScratchEnv+"="+scratch)
+	}
+	if d.spec.TrackProgress {
+		args.OnProgress = func(progress job.Progress) {
+			step := int32(progress.Percent / 10)
+			if last := progressStep.Load(); step > last && progressStep.CompareAndSwap(last, step) {
+				// Reported by the job, on the owner's behalf
+				d.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_PROGRESS, d.Owner, number, formatProgress(progress))
+			}
+		}
+	}
+	if d.quota != nil {
+		// Avoid a non-nil interface holding a nil pointer
+		args.Quota = d.quota
+	}
+
+	var err error
+	if args.OutputKey, err = d.outputKey(); err != nil {
+		return nil, err
+	}
+	a.job, err = job.New(args)
+	if err != nil {
+		d.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_ATTEMPT_FAILED, serverActor, number, "failed to start")
+		return nil, err
+	}
+
+	d.attempts = append(d.attempts, a)
+	d.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_STARTED, serverActor, number, "")
+	return a, nil
+}
+
+// Records the signals the job sends attempt 'number' (see JobArgs.OnSignal)
+func (d *jobData) onSignal(number uint32) func(syscall.Signal, job.ExitReason) {
+	return func(signal syscall.Signal, reason job.ExitReason) {
+		// Only owners can stop their jobs. Everything else is on us
+		actor := serverActor
+		if reason == job.ExitReasonStopped && stopCause(d.stopCause.Load()) == stopByOwner {
+			actor = d.Owner
+		}
+		d.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_SIGNALED, actor, number,
+			fmt.Sprintf("%s (%s)", signalName(signal), reason))
+	}
+}
+
+// Doesn't touch the job's lock, so it's safe to call with or without it
+func (d *jobData) recordEvent(eventType jobmanagerpb.JobEventType, actor string, attempt uint32, detail string) {
+	d.events.record(jobEvent{
+		JobID:   d.id,
+		Owner:   d.Owner,
+		Type:    eventType.String(),
+		Time:    d.clock.Now(),
+		Actor:   actor,
+		Attempt: attempt,
+		Detail:  detail,
+	})
+}
+
+// Ex: "TIMED_OUT (SIGKILL)" or "EXITED (exit code 0)"
+func describeExit(status job.Status) string {
+	switch {
+	case status.ReturnCode != nil:
+		return fmt.Sprintf("%s (exit code %d)", status.ExitReason, *status.ReturnCode)
+	case status.Signal != 0:
+		return fmt.Sprintf("%s (%s)", status.ExitReason, signalName(status.Signal))
+	default:
+		return string(status.ExitReason)
+	}
+}
+
+// Data key for the job's output files. Nil if they aren't encrypted.
+// Only the wrapped key is kept around
+func (d *jobData) outputKey() ([]byte, error) {
+	if d.keys == nil {
+		return nil, nil
+	}
+	return d.keys.Unwrap(d.wrappedKey)
+}
+
+// The most recent attempt. There is always at least one
+func (d *jobData) latest() *attempt {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.attempts[len(d.attempts)-1]
+}
+
+// Look up an attempt by number. 0 selects the latest attempt
+func (d *jobData) attempt(number uint32) (*attempt, bool) {
+	if number == 0 {
+		return d.latest(), true
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if int(number) > len(d.attempts) {
+		return nil, false
+	}
+	return d.attempts[number-1], true
+}
+
+// Snapshot of all attempts made so far
+func (d *jobData) history() []*attempt {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	out := make([]*attempt, len(d.attempts))
+	copy(out, d.attempts)
+	return out
+}
+
+// Stop the running attempt and prevent any more from starting
+func (d *jobData) stop() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.stopped = true
+	// Under the same lock as the stop itself, so readers are either
+	// ended here or attach to a job that's already stopped
+	d.detachReadersLocked(errJobStopped)
+	if d.queued {
+		// Nothing is running. The scheduler skips it once it comes up
+		d.queued = false
+		d.finish()
+	}
+	return d.attempts[len(d.attempts)-1].job.Stop()
+}
+
+// Stop the job on the server's behalf rather than its owner's.
+// False if it was stopped already
+func (d *jobData) stopFor(cause stopCause) (bool, error) {
+	d.lock.Lock()
+	stopped := d.stopped
+	d.lock.Unlock()
+	if stopped {
+		return false, nil
+	}
+	d.stopCause.Store(int32(cause))
+	return true, d.stop()
+}
+
+// Gracefully stop the running attempt to free its slot for another job
+func (d *jobData) preempt(grace time.Duration) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(d.attempts) == 0 {
+		// Admitted, but not started yet. It runs without a slot
+		return nil
+	}
+	return d.attempts[len(d.attempts)-1].job.Preempt(grace)
+}
+
+// Keep the job from being retried or requeued while the server shuts
+// down. A queued job is finished on the spot. False if nothing is running
+func (d *jobData) drain() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.draining = true
+	if d.queued {
+		d.queued = false
+		d.finish()
+		return false
+	}
+	return d.finishedAt.IsZero()
+}
+
+// Register a GetJobOutput stream reading from the job. The returned context
+// is cancelled when the job is stopped or deleted, with a status error
+// for the stream to end with as its cause. Call 'detach' once the stream is done
+func (d *jobData) attachReader(ctx context.Context) (readerCtx context.Context, detach func()) {
+	readerCtx, cancel := context.WithCancelCause(ctx)
+	reader := &outputReader{cancel: cancel}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.readers == nil {
+		d.readers = map[*outputReader]struct{}{}
+	}
+	d.readers[reader] = struct{}{}
+	return readerCtx, func() {
+		d.lock.Lock()
+		delete(d.readers, reader)
+		d.lock.Unlock()
+		cancel(nil)
+	}
+}
+
+// End every GetJobOutput stream attached to the job with 'reason'
+func (d *jobData) detachReaders(reason error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.detachReadersLocked(reason)
+}
+
+// Caller must hold the lock
+func (d *jobData) detachReadersLocked(reason error) {
+	for reader := range d.readers {
+		reader.cancel(reason)
+	}
+	clear(d.readers)
+}
+
+// Whether the last attempt has finished and no more will be made
+func (d *jobData) isFinished() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return !d.finishedAt.IsZero()
+}
+
+// Mark the job finished, waking anyone in WaitJob. Caller must hold the lock
+func (d *jobData) finish() {
+	if !d.finishedAt.IsZero() {
+		return
+	}
+	d.finishedAt = d.clock.Now()
+	close(d.finished)
+	if d.releaseMutex != nil {
+		d.releaseMutex()
+	}
+	if d.token != "" {
+		d.tokens.revoke(d.token)
+	}
+	// They read the job, so they wait for the lock
+	d.hooks.start(d)
+}
+
+// Whether the job is waiting to run again after being preempted
+func (d *jobData) isQueued() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.queued
+}
+
+// Queue a preempted job to run again, if it asked to be. False if the job is over
+func (d *jobData) requeue() bool {
+	d.lock.Lock()
+	d.preemptions++
+	if d.stopped || d.draining || !d.spec.RequeueOnPreemption {
+		d.lock.Unlock()
+		return false
+	}
+	d.queued = true
+	d.lock.Unlock()
+
+	slog.Info("Requeueing preempted job", "job-id", d.id)
+	d.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_REQUEUED, serverActor, 0, "")
+	d.scheduler.requeue(d)
+	return true
+}
+
+// Start a new attempt of a queued job, now that the scheduler has given it a slot
+func (d *jobData) resume() {
+	d.lock.Lock()
+	if !d.queued {
+		// Stopped while it was waiting
+		d.lock.Unlock()
+		d.scheduler.release(d)
+		return
+	}
+	number := uint32(len(d.attempts) + 1)
+	d.lock.Unlock()
+
+	// Still queued while they run, so stopping the job doesn't wait for them
+	hookErr := d.hooks.beforeAttempt(context.Background(), d, number)
+	d.lock.Lock()
+	if !d.queued {
+		// Stopped while they ran
+		d.lock.Unlock()
+		d.scheduler.release(d)
+		return
+	}
+	d.queued = false
+	if hookErr != nil {
+		d.startFailure = number
+		d.preHookFailure = hookErr.Error()
+		d.finish()
+		d.lock.Unlock()
+		d.scheduler.release(d)
+		return
+	}
+	next, err := d.startAttempt()
+	if err != nil {
+		d.startFailure = number
+		d.finish()
+	}
+	d.lock.Unlock()
+
+	if err != nil {
+		slog.Error("Failed to resume preempted job", "job-id", d.id, "error", err)
+		d.scheduler.release(d)
+		return
+	}
+	slog.Info("Resumed preempted job", "job-id", d.id, "attempt", next.number)
+	d.supervise(next)
+}
+
+// Why the job ended up in its final state (see StateReason).
+// Unspecified until it's finished. Caller must hold the lock
+func (d *jobData) stateReasonLocked() (jobmanagerpb.StateReason, string) {
+	if d.finishedAt.IsZero() {
+		return jobmanagerpb.StateReason_STATE_REASON_UNSPECIFIED, ""
+	}
+	if d.preHookFailure != "" {
+		return jobmanagerpb.StateReason_STATE_REASON_PRE_HOOK_FAILED, fmt.Sprintf("pre-start hook failed before attempt %d: %s", d.startFailure, d.preHookFailure)
+	}
+	if d.startFailure != 0 {
+		return jobmanagerpb.StateReason_STATE_REASON_EXEC_FAILED, fmt.Sprintf("attempt %d failed to start", d.startFailure)
+	}
+	status := d.attempts[len(d.attempts)-1].job.Status()
+	switch status.ExitReason {
+	case job.ExitReasonStopped:
+		return d.stoppedReason("")
+	case job.ExitReasonPreempted:
+		// Jobs waiting to run again after a preemption end here too
+		switch {
+		case d.stopped:
+			return d.stoppedReason(" while queued")
+		case d.draining:
+			return jobmanagerpb.StateReason_STATE_REASON_SERVER_SHUTDOWN, "stopped while the server shut down"
+		default:
+			return jobmanagerpb.StateReason_STATE_REASON_PREEMPTED, "preempted by a higher priority job"
+		}
+	case job.ExitReasonTimedOut:
+		return jobmanagerpb.StateReason_STATE_REASON_TIMEOUT, fmt.Sprintf("killed after running for %s", status.Duration.Round(time.Millisecond))
+	case job.ExitReasonOOMKilled:
+		return jobmanagerpb.StateReason_STATE_REASON_OOM, "killed for exceeding its memory limit"
+	case job.ExitReasonQuotaExceeded:
+		return jobmanagerpb.StateReason_STATE_REASON_QUOTA_EXCEEDED, "killed for exceeding its owner's output quota"
+	case job.ExitReasonSignaled:
+		return jobmanagerpb.StateReason_STATE_REASON_SIGNALED, "killed by " + signalName(status.Signal)
+	case job.ExitReasonUnknown:
+		return jobmanagerpb.StateReason_STATE_REASON_UNKNOWN, "adopted process exited"
+	case job.ExitReasonExited:
+		message := fmt.Sprintf("exited with code %d", *status.ReturnCode)
+		if attemptFailed(status, specOutcome(d.spec, status)) {
+			return jobmanagerpb.StateReason_STATE_REASON_FAILED, message
+		}
+		return jobmanagerpb.StateReason_STATE_REASON_COMPLETED, message
+	}
+	if d.draining {
+		// Never started: admitted or queued when the server shut down
+		return jobmanagerpb.StateReason_STATE_REASON_SERVER_SHUTDOWN, "never ran, since the server shut down"
+	}
+	return d.stoppedReason(" before it ran")
+}
+
+// Reason for a job that was stopped, with 'when' added to the message
+func (d *jobData) stoppedReason(when string) (jobmanagerpb.StateReason, string) {
+	switch stopCause(d.stopCause.Load()) {
+	case stopCredentialsLapsed:
+		return jobmanagerpb.StateReason_STATE_REASON_CREDENTIALS_EXPIRED, "stopped" + when + " since " + d.Owner + "'s credentials lapsed"
+	case stopLeaseExpired:
+		return jobmanagerpb.StateReason_STATE_REASON_LEASE_EXPIRED, "stopped" + when + " since its lease wasn't renewed"
+	}
+	return jobmanagerpb.StateReason_STATE_REASON_USER_STOPPED, "stopped by " + d.Owner + when
+}
+
+func (d *jobData) stateReason() (jobmanagerpb.StateReason, string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.stateReasonLocked()
+}
+
+// Summary of the job for exports
+func (d *jobData) record() *jobmanagerpb.JobRecord {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	first := d.attempts[0].job.Status()
+	latest := d.attempts[len(d.attempts)-1].job.Status()
+	// Shell jobs show older clients the shell that ran. Their
+	// command line is in the spec
+	command, args := specCommand(d.spec)
+	out := &jobmanagerpb.JobRecord{
+		JobId:          d.id[:],
+		Id:             d.id.String(),
+		Command:        command,
+		Args:           args,
+		Status:         *jobStateToStatus(latest.CurrentState),
+		ExitCode:       convertExitCode(latest.ReturnCode),
+		StartTime:      timestamppb.New(first.StartTime),
+		Attempts:       uint32(len(d.attempts)),
+		MaxAttempts:    d.maxAttempts,
+		RuntimeClass:   d.runtimeClass,
+		Spec:           d.spec,
+		SessionId:      d.session,
+		Adopted:        d.adopted,
+		LaunchSnapshot: d.snapshot,
+		Annotations:    d.annotations,
+	}
+	if d.parent != uuid.Nil {
+		out.ParentId = d.parent.String()
+	}
+	out.StateReason, out.StateMessage = d.stateReasonLocked()
+	if !d.finishedAt.IsZero() {
+		out.EndTime = timestamppb.New(d.finishedAt)
+		out.Duration = durationpb.New(d.finishedAt.Sub(d.startedAt))
+	} else {
+		out.Duration = durationpb.New(d.clock.Since(d.startedAt))
+	}
+	return out
+}
+
+// Write the job's record through to the store, if there is one. Must be
+// called without the lock
+func (d *jobData) persist() {
+	if d.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := d.store.PutJob(ctx, store.Job{ID: d.id, Owner: d.Owner, Node: d.node, Record: d.record()}); err != nil {
+		slog.Error("Failed to store job record", "job-id", d.id, "error", err)
+	}
+}
+
+// Ex: "42% copying files"
+func formatProgress(progress job.Progress) string {
+	out := strconv.FormatFloat(progress.Percent, 'f', -1, 64) + "%"
+	if progress.Message != "" {
+		out += " " + progress.Message
+	}
+	return out
+}
+
+func progressToProto(progress *job.Progress) *jobmanagerpb.Progress {
+	if progress == nil {
+		return nil
+	}
+	return &jobmanagerpb.Progress{
+		Percent: progress.Percent,
+		Message: progress.Message,
+		Time:    timestamppb.New(progress.Time),
+	}
+}
+
+func processesToProto(processes []job.Process) []*jobmanagerpb.JobProcess {
+	out := make([]*jobmanagerpb.JobProcess, 0, len(processes))
+	for _, process := range processes {
+		out = append(out, &jobmanagerpb.JobProcess{
+			Pid:     int32(process.PID),
+			Ppid:    int32(process.PPID),
+			Command: process.Command,
+		})
+	}
+	return out
+}
+
+func attemptFailed(status job.Status, outcome jobmanagerpb.Outcome) bool {
+	if status.CurrentState != job.JobstatusComplete {
+		// Still running, or the user stopped it
+		return false
+	}
+	if status.ExitReason == job.ExitReasonUnknown {
+		// An adopted process. Whether it failed is anyone's guess
+		return false
+	}
+	switch outcome {
+	case jobmanagerpb.Outcome_OUTCOME_SUCCESS, jobmanagerpb.Outcome_OUTCOME_WARNING:
+		return false
+	}
+	// Including no outcome at all: the process was killed by a signal
+	return true
+}
+
+// Waits for each attempt to exit and starts another one if it
+// failed and the job has attempts to spare
+func (d *jobData) supervise(current *attempt) {
+	requeued := false
+	defer func() {
+		if !requeued {
+			d.lock.Lock()
+			d.finish()
+			d.lock.Unlock()
+		}
+		d.persist()
+		d.scheduler.release(d)
+	}()
+
+	for {
+		<-current.job.Done()
+		status := current.job.Status()
+		d.usage.attemptFinished(d.Owner, status)
+		d.stats.attemptFinished(d.Owner, d.specHash, statsCommand(d.spec), status, attemptOutputBytes(current))
+		outcome := specOutcome(d.spec, status)
+		exitEvent := jobmanagerpb.JobEventType_JOB_EVENT_TYPE_EXITED
+		if attemptFailed(status, outcome) {
+			exitEvent = jobmanagerpb.JobEventType_JOB_EVENT_TYPE_ATTEMPT_FAILED
+		}
+		d.recordEvent(exitEvent, serverActor, current.number, describeExit(status))
+		d.persist()
+		if status.ExitReason == job.ExitReasonPreempted {
+			requeued = d.requeue()
+			return
+		}
+		if !attemptFailed(status, outcome) {
+			return
+		}
+		if outcome == jobmanagerpb.Outcome_OUTCOME_FAILURE {
+			// The job said another attempt won't help
+			slog.Info("Not retrying job that failed for good", "job-id", d.id, "exit-code", *status.ReturnCode)
+			return
+		}
+		if status.QuotaExceeded {
+			// Another attempt would just run into the quota again
+			slog.Warn("Not retrying job that exceeded its output quota", "job-id", d.id)
+			return
+		}
+
+		d.lock.Lock()
+		number := len(d.attempts) + 1
+		if d.stopped || d.draining || number-int(d.preemptions) > int(d.maxAttempts) {
+			d.lock.Unlock()
+			return
+		}
+		d.lock.Unlock()
+
+		// Without the lock, since they may take a while
+		if err := d.hooks.beforeAttempt(context.Background(), d, uint32(number)); err != nil {
+			d.lock.Lock()
+			d.startFailure = uint32(number)
+			d.preHookFailure = err.Error()
+			d.lock.Unlock()
+			return
+		}
+		d.lock.Lock()
+		if d.stopped || d.draining {
+			d.lock.Unlock()
+			return
+		}
+		next, err := d.startAttempt()
+		if err != nil {
+			d.startFailure = uint32(number)
+		}
+		d.lock.Unlock()
+
+		if err != nil {
+			slog.Error("Failed to start job attempt", "job-id", d.id, "attempt", number, "error", err)
+			return
+		}
+		slog.Info("Retrying failed job", "job-id", d.id, "attempt", next.number)
+		current = next
+	}
+}
+
+// Name of an attempt's output file, relative to the output directory
+func outFileName(u uuid.UUID, attempt uint32, kind job.OutputKind) string {
+	return fmt.Sprintf("%s-%d-%s", u.String(), attempt, kind)
+}
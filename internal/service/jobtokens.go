@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// JobTokenEnv holds the token of jobs given one (see specTokenScopes)
+	JobTokenEnv = "JOBBY_JOB_TOKEN"
+	// JobIDEnv holds the id of jobs given a token
+	JobIDEnv = "JOBBY_JOB_ID"
+	// JobTokenHeader is the request metadata a job's token is sent in
+	JobTokenHeader = "x-jobby-job-token"
+)
+
+// Methods a job's token may call, by the scope that allows them
+var jobTokenMethods = map[jobmanagerpb.JobTokenScope][]string{
+	jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_START_CHILD_JOBS: {
+		"/jobby.JobManager/StartJob",
+		"/jobmanager.v2.JobManager/StartJob",
+	},
+	jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_REPORT_PROGRESS: {
+		"/jobby.JobManager/ReportJobProgress",
+		"/jobmanager.v2.JobManager/ReportJobProgress",
+	},
+	jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_ANNOTATE: {
+		"/jobby.JobManager/AnnotateJob",
+		"/jobmanager.v2.JobManager/AnnotateJob",
+	},
+}
+
+var errInvalidJobToken = errors.New("invalid job token")
+
+// JobTokens are handed to running jobs so they can call back into the
+// server as their owner, without a certificate of their own (see
+// WithJobTokens). A token stops working once its job finishes
+type JobTokens struct {
+	lock   sync.Mutex
+	tokens map[string]jobToken
+}
+
+type jobToken struct {
+	job    uuid.UUID
+	owner  string
+	scopes []jobmanagerpb.JobTokenScope
+}
+
+// Whether the token may call 'method'
+func (t jobToken) allows(method string) bool {
+	for _, scope := range t.scopes {
+		if slices.Contains(jobTokenMethods[scope], method) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the token has 'scope'
+func (t jobToken) has(scope jobmanagerpb.JobTokenScope) bool {
+	return slices.Contains(t.scopes, scope)
+}
+
+// What the spec's token may do, without duplicates. Empty if the job
+// doesn't get one
+func specTokenScopes(spec *jobmanagerpb.JobSpec) []jobmanagerpb.JobTokenScope {
+	var scopes []jobmanagerpb.JobTokenScope
+	if spec.ChildJobs {
+		scopes = append(scopes, jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_START_CHILD_JOBS)
+	}
+	for _, scope := range spec.TokenScopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func NewJobTokens() *JobTokens {
+	return &JobTokens{tokens: make(map[string]jobToken)}
+}
+
+// WithJobTokens lets jobs ask for a token to call back with (see
+// JobSpec.token_scopes). The
+// authenticator must accept the same tokens (see VerifyToken)
+func WithJobTokens(tokens *JobTokens) Option {
+	return func(j *Jobby) {
+		j.jobTokens = tokens
+	}
+}
+
+// Hand out a token for 'job' with 'scopes'
+func (t *JobTokens) issue(job uuid.UUID, owner string, scopes []jobmanagerpb.JobTokenScope) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.tokens[token] = jobToken{job: job, owner: owner, scopes: scopes}
+	return token, nil
+}
+
+func (t *JobTokens) revoke(token string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.tokens, token)
+}
+
+// The token the caller sent, if any
+func tokenFromContext(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, JobTokenHeader)
+	if len(values) != 1 {
+		return ""
+	}
+	return values[0]
+}
+
+// The job whose token the caller sent, if it sent a valid one
+func (t *JobTokens) fromContext(ctx context.Context) (jobToken, bool) {
+	token := tokenFromContext(ctx)
+	if token == "" {
+		return jobToken{}, false
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	found, ok := t.tokens[token]
+	return found, ok
+}
+
+// VerifyToken identifies callers by the job token they sent as that
+// job's owner, on the methods its scopes allow
+func (t *JobTokens) VerifyToken(ctx context.Context, method string) (string, error) {
+	if tokenFromContext(ctx) == "" {
+		return "", nil
+	}
+	found, ok := t.fromContext(ctx)
+	if !ok || !found.allows(method) {
+		return "", errInvalidJobToken
+	}
+	return found.owner, nil
+}
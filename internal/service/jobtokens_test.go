@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Start a job that sleeps, and a context that calls back with its token
+func startWithToken(t *testing.T, jobService *service.Jobby, spec *jobmanagerpb.JobSpec) (*jobmanagerpb.StartJobResponse, context.Context) {
+	ctx := context.Background()
+	// The job leaves its token where the test can find it
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	spec.Command = "/bin/sh"
+	spec.Args = []string{"sh", "-c", `printf %s "$JOBBY_JOB_TOKEN" > ` + tokenFile + `.tmp; mv ` + tokenFile + `.tmp ` + tokenFile + `; sleep 10`}
+	resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec, Force: true})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+	})
+	var token []byte
+	require.Eventually(t, func() bool {
+		token, err = os.ReadFile(tokenFile)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NotEmpty(t, token)
+	return resp, metadata.NewIncomingContext(ctx, metadata.Pairs(service.JobTokenHeader, string(token)))
+}
+
+func TestChildJobs(t *testing.T) {
+	ctx := context.Background()
+	tokens := service.NewJobTokens()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(), service.WithJobTokens(tokens))
+	sleep := &jobmanagerpb.JobSpec{Command: "/bin/sleep", Args: []string{"sleep", "10"}}
+	parent, tokenCtx := startWithToken(t, jobService, &jobmanagerpb.JobSpec{ChildJobs: true})
+
+	t.Run("verify", func(tt *testing.T) {
+		user, err := tokens.VerifyToken(tokenCtx, "/jobby.JobManager/StartJob")
+		require.NoError(tt, err)
+		assert.Equal(tt, "someuser", user)
+
+		_, err = tokens.VerifyToken(tokenCtx, "/jobby.JobManager/StopJob")
+		assert.Error(tt, err)
+
+		user, err = tokens.VerifyToken(ctx, "/jobby.JobManager/StartJob")
+		require.NoError(tt, err)
+		assert.Empty(tt, user)
+	})
+
+	t.Run("child", func(tt *testing.T) {
+		child, err := jobService.StartJob(tokenCtx, &jobmanagerpb.StartJobRequest{Spec: sleep})
+		require.NoError(tt, err)
+		defer jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: child.JobId})
+
+		list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{ParentId: parent.Id})
+		require.NoError(tt, err)
+		require.Len(tt, list.Jobs, 1)
+		assert.Equal(tt, child.Id, list.Jobs[0].Id)
+		assert.Equal(tt, parent.Id, list.Jobs[0].ParentId)
+	})
+
+	t.Run("not offered", func(tt *testing.T) {
+		other := service.NewJobService(&mockUserGetter{user: "someuser"}, tt.TempDir())
+		_, err := other.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/true", ChildJobs: true}})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("revoked", func(tt *testing.T) {
+		_, err := jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: parent.JobId})
+		require.NoError(tt, err)
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: parent.JobId})
+		require.NoError(tt, err)
+
+		_, err = tokens.VerifyToken(tokenCtx, "/jobby.JobManager/StartJob")
+		assert.Error(tt, err)
+	})
+}
+
+func TestJobTokenScopes(t *testing.T) {
+	ctx := context.Background()
+	tokens := service.NewJobTokens()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(), service.WithJobTokens(tokens))
+	self, tokenCtx := startWithToken(t, jobService, &jobmanagerpb.JobSpec{
+		TrackProgress: true,
+		TokenScopes: []jobmanagerpb.JobTokenScope{
+			jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_REPORT_PROGRESS,
+			jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_ANNOTATE,
+		},
+	})
+
+	t.Run("verify", func(tt *testing.T) {
+		for _, method := range []string{"/jobby.JobManager/ReportJobProgress", "/jobmanager.v2.JobManager/AnnotateJob"} {
+			user, err := tokens.VerifyToken(tokenCtx, method)
+			require.NoError(tt, err)
+			assert.Equal(tt, "someuser", user)
+		}
+		_, err := tokens.VerifyToken(tokenCtx, "/jobby.JobManager/StartJob")
+		assert.Error(tt, err)
+	})
+
+	t.Run("progress", func(tt *testing.T) {
+		_, err := jobService.ReportJobProgress(tokenCtx, &jobmanagerpb.ReportJobProgressRequest{Id: self.Id, Percent: 40, Message: "halfway-ish"})
+		require.NoError(tt, err)
+		st, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: self.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, 40.0, st.Progress.GetPercent())
+		assert.Equal(tt, "halfway-ish", st.Progress.GetMessage())
+
+		_, err = jobService.ReportJobProgress(tokenCtx, &jobmanagerpb.ReportJobProgressRequest{Id: self.Id, Percent: 101})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("annotate", func(tt *testing.T) {
+		_, err := jobService.AnnotateJob(tokenCtx, &jobmanagerpb.AnnotateJobRequest{Id: self.Id, Annotations: map[string]string{"report": "https://ci/1", "stage": "build"}})
+		require.NoError(tt, err)
+		_, err = jobService.AnnotateJob(tokenCtx, &jobmanagerpb.AnnotateJobRequest{Id: self.Id, Annotations: map[string]string{"stage": ""}})
+		require.NoError(tt, err)
+		described, err := jobService.DescribeJob(ctx, &jobmanagerpb.DescribeJobRequest{JobId: self.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, map[string]string{"report": "https://ci/1"}, described.Record.Annotations)
+	})
+
+	t.Run("only itself", func(tt *testing.T) {
+		other, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/sleep", Args: []string{"sleep", "10"}, TrackProgress: true}})
+		require.NoError(tt, err)
+		defer jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: other.JobId})
+		_, err = jobService.AnnotateJob(tokenCtx, &jobmanagerpb.AnnotateJobRequest{Id: other.Id, Annotations: map[string]string{"a": "b"}})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		_, err = jobService.ReportJobProgress(tokenCtx, &jobmanagerpb.ReportJobProgressRequest{Id: other.Id, Percent: 10})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+
+		// The owner may annotate any of their jobs
+		_, err = jobService.AnnotateJob(ctx, &jobmanagerpb.AnnotateJobRequest{Id: other.Id, Annotations: map[string]string{"a": "b"}})
+		assert.NoError(tt, err)
+	})
+
+	t.Run("no children", func(tt *testing.T) {
+		// The token can't start children, so this is just the owner's job
+		child, err := jobService.StartJob(tokenCtx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/true"}})
+		require.NoError(tt, err)
+		list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{ParentId: self.Id})
+		require.NoError(tt, err)
+		assert.Empty(tt, list.Jobs)
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: child.JobId})
+		require.NoError(tt, err)
+	})
+
+	t.Run("invalid scope", func(tt *testing.T) {
+		_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{
+			Command:     "/bin/true",
+			TokenScopes: []jobmanagerpb.JobTokenScope{jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_UNSPECIFIED},
+		}})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (j *Jobby) RenewJobLease(ctx context.Context, req *jobmanagerpb.RenewJobLeaseRequest) (*jobmanagerpb.RenewJobLeaseResponse, error) {
+	slog.Info("Handling 'RenewJobLease' request", "user", j.userGetter.GetUserContext(ctx), "request", req)
+	jobData, st := j.getJob(ctx, req)
+	if st != nil {
+		return nil, st.Err()
+	}
+	if jobData.spec.Lease == nil {
+		return nil, status.Error(codes.FailedPrecondition, "Job wasn't started with a lease")
+	}
+	expires, ok := jobData.renewLease()
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "Job has finished, or its lease already ran out")
+	}
+	return &jobmanagerpb.RenewJobLeaseResponse{ExpiresAt: timestamppb.New(expires)}, nil
+}
+
+// Extend the job's lease to a full lease period from now. False if it
+// ran out or the job is over, since there's nothing left to keep alive
+func (d *jobData) renewLease() (time.Time, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	now := d.clock.Now()
+	if d.stopped || !d.finishedAt.IsZero() || now.After(d.leaseExpires) {
+		return time.Time{}, false
+	}
+	d.leaseExpires = now.Add(d.spec.Lease.AsDuration())
+	return d.leaseExpires, true
+}
+
+// Stop the job once its lease runs out, unless it finishes first
+func (d *jobData) watchLease() {
+	for {
+		d.lock.Lock()
+		remaining := d.leaseExpires.Sub(d.clock.Now())
+		d.lock.Unlock()
+		if remaining <= 0 {
+			break
+		}
+		timer := d.clock.NewTimer(remaining)
+		select {
+		case <-d.finished:
+			timer.Stop()
+			return
+		case <-timer.C():
+			// Renewed in the meantime, maybe
+		}
+	}
+
+	stopped, err := d.stopFor(stopLeaseExpired)
+	if err != nil {
+		slog.Error("Failed to stop job whose lease ran out", "job-id", d.id, "error", err)
+		return
+	}
+	if stopped {
+		d.persist()
+		slog.Info("Stopped job whose lease ran out", "job-id", d.id)
+	}
+}
@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestJobLease(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	fake := clock.NewFake(now)
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(), service.WithClock(fake))
+	sleep := &jobmanagerpb.JobSpec{Command: "/bin/sleep", Args: []string{"sleep", "10"}}
+
+	t.Run("no lease", func(tt *testing.T) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: sleep})
+		require.NoError(tt, err)
+		defer jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		_, err = jobService.RenewJobLease(ctx, &jobmanagerpb.RenewJobLeaseRequest{JobId: resp.JobId})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{
+			Command: "/bin/true",
+			Lease:   durationpb.New(-time.Second),
+		}})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("expires", func(tt *testing.T) {
+		spec := &jobmanagerpb.JobSpec{Command: sleep.Command, Args: sleep.Args, Lease: durationpb.New(time.Minute)}
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec, Force: true})
+		require.NoError(tt, err)
+		require.NoError(tt, fake.WaitFor(ctx, 1))
+
+		fake.Advance(30 * time.Second)
+		renewed, err := jobService.RenewJobLease(ctx, &jobmanagerpb.RenewJobLeaseRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.True(tt, now.Add(90*time.Second).Equal(renewed.ExpiresAt.AsTime()))
+
+		// Past the original lease, but not the renewed one
+		fake.Advance(45 * time.Second)
+		require.NoError(tt, fake.WaitFor(ctx, 1))
+		st, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_RUNNING, st.CurrentStatus)
+
+		fake.Advance(20 * time.Second)
+		st, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_LEASE_EXPIRED, st.StateReason)
+		assert.Equal(tt, "stopped since its lease wasn't renewed", st.StateMessage)
+
+		_, err = jobService.RenewJobLease(ctx, &jobmanagerpb.RenewJobLeaseRequest{JobId: resp.JobId})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+}
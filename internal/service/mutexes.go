@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Named locks jobs hold while they run (see JobSpec.mutex), so commands
+// that mustn't overlap (ex: database migrations) never do
+type mutexTable struct {
+	lock sync.Mutex
+	held map[string]*heldMutex
+}
+
+type heldMutex struct {
+	holder uuid.UUID
+	// First come, first served
+	waiters []*mutexWaiter
+}
+
+type mutexWaiter struct {
+	job uuid.UUID
+	// Closed once the mutex is handed to the waiter
+	turn chan struct{}
+}
+
+func newMutexTable() *mutexTable {
+	return &mutexTable{held: map[string]*heldMutex{}}
+}
+
+// Take mutex 'name' for job 'id'. If another job holds it, 'release' is nil
+// and 'holder' is that job, unless 'wait' is set, in which case it waits
+// for its turn until 'ctx' is done. Otherwise 'release' must be called once
+// the job is done with the mutex
+func (t *mutexTable) acquire(ctx context.Context, name string, id uuid.UUID, wait bool) (release func(), holder uuid.UUID, err error) {
+	t.lock.Lock()
+	m, ok := t.held[name]
+	if !ok {
+		t.held[name] = &heldMutex{holder: id}
+		t.lock.Unlock()
+		return t.releaser(name), uuid.Nil, nil
+	}
+	if !wait {
+		t.lock.Unlock()
+		return nil, m.holder, nil
+	}
+	waiter := &mutexWaiter{job: id, turn: make(chan struct{})}
+	m.waiters = append(m.waiters, waiter)
+	t.lock.Unlock()
+
+	select {
+	case <-waiter.turn:
+		return t.releaser(name), uuid.Nil, nil
+	case <-ctx.Done():
+	}
+	t.lock.Lock()
+	select {
+	case <-waiter.turn:
+		// Handed over just as the caller gave up, so pass it on
+		t.lock.Unlock()
+		t.release(name)
+	default:
+		m.waiters = slices.DeleteFunc(m.waiters, func(other *mutexWaiter) bool { return other == waiter })
+		t.lock.Unlock()
+	}
+	return nil, uuid.Nil, ctx.Err()
+}
+
+// Safe to call more than once
+func (t *mutexTable) releaser(name string) func() {
+	return sync.OnceFunc(func() { t.release(name) })
+}
+
+// Hand the mutex to the next job waiting for it, if any
+func (t *mutexTable) release(name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	m, ok := t.held[name]
+	if !ok {
+		return
+	}
+	if len(m.waiters) == 0 {
+		delete(t.held, name)
+		return
+	}
+	next := m.waiters[0]
+	m.waiters = m.waiters[1:]
+	m.holder = next.job
+	close(next.turn)
+}
+
+// Take the mutex 'spec' names, if any, for job 'id'. 'release' does
+// nothing for jobs without one
+func (j *Jobby) takeMutex(ctx context.Context, subLogger *slog.Logger, spec *jobmanagerpb.JobSpec, id uuid.UUID) (release func(), err error) {
+	if spec.Mutex == "" {
+		return func() {}, nil
+	}
+	release, holder, err := j.mutexes.acquire(ctx, spec.Mutex, id, spec.WaitForMutex)
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	if release == nil {
+		return nil, status.Errorf(codes.Aborted, "Job %s holds mutex '%s'. Try again once it finishes or set wait_for_mutex", holder, spec.Mutex)
+	}
+	// The server may have started shutting down while the job waited
+	if j.draining.Load() {
+		release()
+		return nil, errShuttingDown
+	}
+	subLogger.Info("Took job mutex", "mutex", spec.Mutex)
+	return release, nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Orphans modified more recently may belong to jobs that are starting, so
+// they aren't removed
+const orphanMinAge = time.Hour
+
+// Names of job output (see outFileName), their segments, and scratch directories
+var outputNamePattern = regexp.MustCompile(`^([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})-(?:\d+-(?:stdout|stderr)(?:\.\d+)?|scratch)$`)
+
+// What a job keeps in the output directory
+type jobOutputs struct {
+	owner string
+	// Names within the output directory
+	names map[string]bool
+	// Ones that should be there but aren't, as far as the job knows
+	missing []string
+}
+
+// The jobs the server knows of, including deleted ones whose output is
+// kept, by id. Adopted jobs are left out, as their output is elsewhere
+func (j *Jobby) knownOutputs() map[uuid.UUID]*jobOutputs {
+	known := map[uuid.UUID]*jobOutputs{}
+	add := func(data *jobData) {
+		if data.adopted {
+			return
+		}
+		outputs := &jobOutputs{owner: data.Owner, names: map[string]bool{}}
+		if path := data.scratchPath(); path != "" {
+			outputs.names[filepath.Base(path)] = true
+		}
+		for _, a := range data.history() {
+			descriptors, err := a.job.Outputs()
+			if err != nil {
+				slog.Warn("Failed to list job output", "job-id", data.id, "error", err)
+				continue
+			}
+			for _, output := range descriptors {
+				if output.Sink {
+					continue
+				}
+				if len(output.Files) == 0 && output.Segments == nil {
+					outputs.missing = append(outputs.missing, filepath.Base(output.Path))
+				}
+				for _, file := range output.Files {
+					if filepath.Dir(file.Path) == filepath.Clean(j.directory) {
+						outputs.names[filepath.Base(file.Path)] = true
+					}
+				}
+			}
+		}
+		known[data.id] = outputs
+	}
+	j.jobDirectory.Range(func(key, value any) bool {
+		if data, ok := value.(*jobData); ok {
+			add(data)
+		}
+		return true
+	})
+	j.deletedJobs.Range(func(key, value any) bool {
+		if deleted, ok := value.(*deletedJob); ok {
+			add(deleted.data)
+		}
+		return true
+	})
+	return known
+}
+
+// Bytes on disk, counting everything beneath directories
+func diskUsage(path string, entry fs.DirEntry) (uint64, error) {
+	if !entry.IsDir() {
+		info, err := entry.Info()
+		if err != nil {
+			return 0, err
+		}
+		return uint64(info.Size()), nil
+	}
+	var total uint64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += uint64(info.Size())
+		}
+		return nil
+	})
+	return total, err
+}
+
+func (j *Jobby) CheckOutputDirectory(ctx context.Context, req *jobmanagerpb.CheckOutputDirectoryRequest) (*jobmanagerpb.CheckOutputDirectoryResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	subLogger := slog.With("user", user, "request", req)
+	subLogger.Info("Handling 'CheckOutputDirectory' request")
+	if !slices.Contains(j.admins, user) {
+		return nil, status.Error(codes.PermissionDenied, "Only admins may check the output directory")
+	}
+
+	// Listed before reading the directory, so files of jobs started
+	// meanwhile are young enough to be left alone
+	known := j.knownOutputs()
+	// Jobs from before the server restarted are only in the store. Their
+	// files are left to them, whatever they're named
+	stored := map[uuid.UUID]string{}
+	if j.store != nil {
+		jobs, err := j.store.ListJobs(ctx, "")
+		if err != nil {
+			subLogger.Error("Error listing stored jobs", "error", err)
+			return nil, status.Error(codes.Internal, "Error listing stored jobs")
+		}
+		for _, job := range jobs {
+			stored[job.ID] = job.Owner
+		}
+	}
+	entries, err := os.ReadDir(j.directory)
+	if err != nil {
+		subLogger.Error("Error reading output directory", "error", err)
+		return nil, status.Error(codes.Internal, "Error reading output directory")
+	}
+
+	resp := &jobmanagerpb.CheckOutputDirectoryResponse{}
+	usage := map[string]*jobmanagerpb.UserDiskUsage{}
+	usedBy := map[string]map[uuid.UUID]bool{}
+	onDisk := map[string]bool{}
+	now := j.clock.Now()
+	for _, entry := range entries {
+		path := filepath.Join(j.directory, entry.Name())
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// Removed since we listed it (ex: an output window's old segment)
+			continue
+		}
+		if err != nil {
+			subLogger.Warn("Error checking output file", "file", entry.Name(), "error", err)
+			continue
+		}
+		size, err := diskUsage(path, entry)
+		if err != nil {
+			subLogger.Warn("Error measuring output file", "file", entry.Name(), "error", err)
+		}
+		onDisk[entry.Name()] = true
+		resp.TotalBytes += size
+		out := &jobmanagerpb.OutputDirectoryEntry{
+			Name:      entry.Name(),
+			SizeBytes: size,
+			Modified:  timestamppb.New(info.ModTime()),
+		}
+
+		match := outputNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			resp.Unrecognized = append(resp.Unrecognized, out)
+			continue
+		}
+		out.JobId = match[1]
+		id := uuid.MustParse(match[1])
+		ownerName, owned := "", false
+		if outputs, ok := known[id]; ok {
+			ownerName, owned = outputs.owner, outputs.names[entry.Name()]
+		} else {
+			ownerName, owned = stored[id]
+		}
+		if owned {
+			owner := usage[ownerName]
+			if owner == nil {
+				owner = &jobmanagerpb.UserDiskUsage{User: ownerName}
+				usage[ownerName] = owner
+				usedBy[ownerName] = map[uuid.UUID]bool{}
+			}
+			owner.Bytes += size
+			owner.Files++
+			usedBy[ownerName][id] = true
+			continue
+		}
+
+		resp.Orphans = append(resp.Orphans, out)
+		resp.OrphanedBytes += size
+		if !req.RemoveOrphans || now.Sub(info.ModTime()) < orphanMinAge {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			subLogger.Error("Error removing orphaned output", "file", entry.Name(), "error", err)
+			continue
+		}
+		subLogger.Info("Removed orphaned output", "file", entry.Name(), "bytes", size)
+		out.Removed = true
+		resp.RemovedBytes += size
+	}
+
+	for id, outputs := range known {
+		for _, name := range outputs.missing {
+			if !onDisk[name] {
+				resp.Missing = append(resp.Missing, &jobmanagerpb.MissingOutput{JobId: id.String(), Owner: outputs.owner, Name: name})
+			}
+		}
+	}
+	slices.SortFunc(resp.Missing, func(a, b *jobmanagerpb.MissingOutput) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for name, owner := range usage {
+		owner.Jobs = uint32(len(usedBy[name]))
+		resp.Usage = append(resp.Usage, owner)
+	}
+	slices.SortFunc(resp.Usage, func(a, b *jobmanagerpb.UserDiskUsage) int {
+		return strings.Compare(a.User, b.User)
+	})
+	return resp, nil
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckOutputDirectory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	now := time.Now()
+	// Files written now are old enough to remove as of then
+	fake := clock.NewFake(now.Add(2 * time.Hour))
+	metadata, err := store.OpenBolt(filepath.Join(t.TempDir(), "jobby.db"))
+	require.NoError(t, err)
+	defer metadata.Close()
+	users := &mockUserGetter{user: "alice"}
+	jobService := service.NewJobService(users, dir, service.WithAdmins([]string{"admin"}), service.WithClock(fake), service.WithStore(metadata))
+
+	resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Command: "/bin/echo", Args: []string{"echo", "hello"}}})
+	require.NoError(t, err)
+	_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	// Lost its stderr somehow
+	require.NoError(t, os.Remove(filepath.Join(dir, resp.Id+"-1-stderr")))
+
+	orphan := uuid.NewString() + "-2-stdout.3"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, orphan), []byte("old output"), 0600))
+	scratch := uuid.NewString() + "-scratch"
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, scratch, "nested"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, scratch, "nested", "data"), []byte("12345"), 0600))
+	young := uuid.NewString() + "-1-stderr"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, young), []byte("x"), 0600))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, young), fake.Now(), fake.Now()))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "events.jsonl"), []byte("{}\n"), 0600))
+	// From before a restart
+	restarted := uuid.New()
+	require.NoError(t, metadata.PutJob(ctx, store.Job{ID: restarted, Owner: "bob", Record: &jobmanagerpb.JobRecord{}}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, restarted.String()+"-1-stdout"), []byte("1234"), 0600))
+
+	_, err = jobService.CheckOutputDirectory(ctx, &jobmanagerpb.CheckOutputDirectoryRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	users.user = "admin"
+	check, err := jobService.CheckOutputDirectory(ctx, &jobmanagerpb.CheckOutputDirectoryRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []*jobmanagerpb.UserDiskUsage{
+		{User: "alice", Bytes: uint64(len("hello\n")), Files: 1, Jobs: 1},
+		{User: "bob", Bytes: 4, Files: 1, Jobs: 1},
+	}, check.Usage)
+	orphans := map[string]uint64{}
+	for _, entry := range check.Orphans {
+		orphans[entry.Name] = entry.SizeBytes
+		assert.False(t, entry.Removed)
+	}
+	assert.Equal(t, map[string]uint64{orphan: 10, scratch: 5, young: 1}, orphans)
+	assert.Equal(t, uint64(16), check.OrphanedBytes)
+	require.Len(t, check.Unrecognized, 1)
+	assert.Equal(t, "events.jsonl", check.Unrecognized[0].Name)
+	require.Len(t, check.Missing, 1)
+	assert.Equal(t, &jobmanagerpb.MissingOutput{JobId: resp.Id, Owner: "alice", Name: resp.Id + "-1-stderr"}, check.Missing[0])
+	assert.Equal(t, uint64(6+4+16+3), check.TotalBytes)
+
+	check, err = jobService.CheckOutputDirectory(ctx, &jobmanagerpb.CheckOutputDirectoryRequest{RemoveOrphans: true})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(15), check.RemovedBytes)
+	assert.NoFileExists(t, filepath.Join(dir, orphan))
+	assert.NoDirExists(t, filepath.Join(dir, scratch))
+	// Too young to remove
+	assert.FileExists(t, filepath.Join(dir, young))
+	assert.FileExists(t, filepath.Join(dir, resp.Id+"-1-stdout"))
+	assert.FileExists(t, filepath.Join(dir, restarted.String()+"-1-stdout"))
+	assert.FileExists(t, filepath.Join(dir, "events.jsonl"))
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/gopheryan/jobby/job"
+)
+
+// QuotaLimits caps the disk space used by each user's job output
+type QuotaLimits struct {
+	// Bytes of output (across all of a user's jobs and attempts)
+	// kept on disk at once. Zero means unlimited
+	PerUserBytes int64
+	// What happens to a job that writes past its owner's quota
+	Action job.QuotaAction
+}
+
+// Output usage of a single user. Shared by all of their jobs
+type userQuota struct {
+	limit int64
+	used  atomic.Int64
+}
+
+func (q *userQuota) Reserve(n int) int {
+	for {
+		used := q.used.Load()
+		granted := min(int64(n), max(q.limit-used, 0))
+		if granted == 0 {
+			return 0
+		}
+		if q.used.CompareAndSwap(used, used+granted) {
+			return int(granted)
+		}
+	}
+}
+
+// Release gives back space once output is deleted
+func (q *userQuota) Release(n int64) {
+	q.used.Add(-n)
+}
+
+func (q *userQuota) exhausted() bool {
+	return q.used.Load() >= q.limit
+}
+
+type quotaTracker struct {
+	limits QuotaLimits
+	// used as: map[string]*userQuota
+	users sync.Map
+}
+
+// Nil when output is unlimited
+func (t *quotaTracker) forUser(user string) *userQuota {
+	if t.limits.PerUserBytes <= 0 {
+		return nil
+	}
+	q, _ := t.users.LoadOrStore(user, &userQuota{limit: t.limits.PerUserBytes})
+	return q.(*userQuota)
+}
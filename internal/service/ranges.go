@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Send exactly the range of an exited attempt's output that 'req' asked for
+func (j *Jobby) streamRange(srv jobmanagerpb.JobManager_GetJobOutputServer, subLogger *slog.Logger, user string, jobData *jobData,
+	attempt *attempt, req *jobmanagerpb.GetJobOutputRequest) error {
+	if req.CollapseRepeatedLines || req.StripTimestamps ||
+		(req.Mode != jobmanagerpb.StreamMode_STREAM_MODE_UNSPECIFIED && req.Mode != jobmanagerpb.StreamMode_STREAM_MODE_RAW) {
+		// Offsets are into the output as it's stored
+		return status.Error(codes.InvalidArgument, "Byte ranges can't be combined with a stream mode, collapsing or stripping timestamps")
+	}
+	select {
+	case <-attempt.job.Done():
+	default:
+		return status.Error(codes.FailedPrecondition, "Byte ranges are only served for attempts that have exited")
+	}
+
+	outputs, err := attempt.job.Outputs()
+	if err != nil {
+		subLogger.Error("Failed to describe job output", "error", err)
+		return status.Error(codes.Internal, "Error attaching to job output")
+	}
+	var output job.OutputDescriptor
+	switch req.Type {
+	case jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT:
+		output = outputs[0]
+	case jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR:
+		output = outputs[1]
+	default:
+		return status.Error(codes.InvalidArgument, "Must specify valid output type")
+	}
+
+	size := uint64(output.Size)
+	start, end := req.Range.Start, req.Range.End
+	if end == 0 {
+		end = size
+	}
+	if start > end {
+		return status.Error(codes.InvalidArgument, "Range must not start after it ends")
+	}
+	if end > size {
+		return status.Error(codes.OutOfRange, fmt.Sprintf("Range is past the end of the output (%d bytes)", size))
+	}
+
+	var reader io.ReadCloser
+	if output.Kind == job.OutputStdout {
+		reader, err = attempt.job.StdoutRange(int64(start), int64(end))
+	} else {
+		reader, err = attempt.job.StderrRange(int64(start), int64(end))
+	}
+	if err != nil {
+		subLogger.Error("Failed to read job output range", "error", err)
+		return status.Error(codes.Internal, "Error attaching to job output")
+	}
+
+	maxBytes := bulkMessageBytes
+	if req.BatchMaxBytes != 0 {
+		maxBytes = int(req.BatchMaxBytes)
+	}
+	pump := func(reader io.Reader, send func([]byte) error) (error, error) {
+		var sent uint64
+		readErr, sendErr := bulkOutput(reader, maxBytes, func(data []byte) error {
+			sent += uint64(len(data))
+			return send(data)
+		})
+		if errors.Is(readErr, io.EOF) && sent != end-start {
+			// The output was removed out from under us. Don't pass a short
+			// read off as the whole range
+			readErr = io.ErrUnexpectedEOF
+		}
+		return readErr, sendErr
+	}
+	return j.streamOutput(srv.Context(), subLogger, user, jobData, reader, pump, srv.Send)
+}
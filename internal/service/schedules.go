@@ -0,0 +1,486 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/cron"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Label put on jobs started by a schedule, naming it
+const scheduleLabel = "schedule"
+
+var scheduleNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+var errNoScheduleStore = status.Error(codes.FailedPrecondition, "Server has no metadata store to keep schedules in")
+
+// Runs starting later than this after their time (ex: as no server was
+// up when they were due) were missed. Well over the scheduler's interval
+const missedRunGrace = time.Minute
+
+// Most missed runs RUN_ALL catches up on. Older ones are skipped
+const maxCatchUpRuns = 100
+
+// Runs kept in each schedule's history (see ListScheduleRuns)
+const maxScheduleRuns = 50
+
+const maxScheduleJitter = time.Hour
+
+// How many upcoming runs ListScheduleRuns lists by default, and at most
+const (
+	defaultUpcomingRuns = 10
+	maxUpcomingRuns     = 100
+)
+
+// Schedules of different owners may share a name
+func scheduleID(owner, name string) string {
+	return owner + "/" + name
+}
+
+func scheduleName(schedule store.Schedule) string {
+	return strings.TrimPrefix(schedule.ID, schedule.Owner+"/")
+}
+
+// The spec each run of the schedule starts
+func scheduledSpec(name string, spec *jobmanagerpb.JobSpec) *jobmanagerpb.JobSpec {
+	spec = proto.Clone(spec).(*jobmanagerpb.JobSpec)
+	labels := maps.Clone(spec.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[scheduleLabel] = name
+	spec.Labels = labels
+	// The scheduler can't wait on one schedule without holding up the rest
+	spec.WaitForMutex = false
+	return spec
+}
+
+// The cron time the schedule's runs are counted from: its last run's, or
+// when it was created if it hasn't run. Zero if neither is known
+func lastDue(schedule store.Schedule) time.Time {
+	switch {
+	case !schedule.LastDue.IsZero():
+		return schedule.LastDue
+	case !schedule.LastRun.IsZero():
+		return schedule.LastRun
+	}
+	return schedule.Created
+}
+
+// When the schedule is next due, not counting jitter. Zero if never.
+// Cron expressions are in the server's time zone
+func nextRun(schedule store.Schedule, expr cron.Expression) time.Time {
+	return expr.Next(lastDue(schedule).In(time.Local))
+}
+
+// When the run due at 'due' starts: up to the schedule's jitter later. The
+// delay is derived from the schedule and the time, so it's known in
+// advance and every server agrees on it
+func runStart(schedule store.Schedule, due time.Time) time.Time {
+	if schedule.Jitter <= 0 {
+		return due
+	}
+	h := fnv.New64a()
+	h.Write([]byte(schedule.ID))
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(due.Unix())))
+	return due.Add(time.Duration(h.Sum64() % uint64(schedule.Jitter)))
+}
+
+func scheduleToProto(schedule store.Schedule) *jobmanagerpb.Schedule {
+	out := &jobmanagerpb.Schedule{
+		Name: scheduleName(schedule),
+		Cron: schedule.Cron,
+		Spec: schedule.Spec,
+	}
+	if !schedule.Created.IsZero() {
+		out.CreatedAt = timestamppb.New(schedule.Created)
+	}
+	if !schedule.LastRun.IsZero() {
+		out.LastRun = timestamppb.New(schedule.LastRun)
+	}
+	if expr, err := cron.Parse(schedule.Cron); err == nil {
+		if next := nextRun(schedule, expr); !next.IsZero() {
+			out.NextRun = timestamppb.New(runStart(schedule, next))
+		}
+	}
+	out.MissedRuns = schedule.MissedRuns
+	if schedule.Jitter > 0 {
+		out.Jitter = durationpb.New(schedule.Jitter)
+	}
+	return out
+}
+
+func scheduleRunToProto(schedule store.Schedule, run store.ScheduleRun) *jobmanagerpb.ScheduleRun {
+	out := &jobmanagerpb.ScheduleRun{
+		ScheduledFor: timestamppb.New(run.Due),
+		StartAt:      timestamppb.New(runStart(schedule, run.Due)),
+		Skipped:      run.Skipped,
+		Missed:       uint32(run.Missed),
+	}
+	if !run.Started.IsZero() {
+		out.StartedAt = timestamppb.New(run.Started)
+	}
+	if run.JobID != uuid.Nil {
+		out.JobId = run.JobID.String()
+	}
+	return out
+}
+
+func (j *Jobby) PutSchedule(ctx context.Context, req *jobmanagerpb.PutScheduleRequest) (*jobmanagerpb.PutScheduleResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	subLogger := slog.With("user", user, "request", loggableRequest(req, func(req *jobmanagerpb.PutScheduleRequest) *jobmanagerpb.JobSpec {
+		return req.GetSchedule().GetSpec()
+	}))
+	subLogger.Info("Handling 'PutSchedule' request")
+	if j.store == nil {
+		return nil, errNoScheduleStore
+	}
+	schedule := req.Schedule
+	if schedule == nil {
+		return nil, status.Error(codes.InvalidArgument, "schedule is required")
+	}
+	if !scheduleNamePattern.MatchString(schedule.Name) {
+		return nil, status.Error(codes.InvalidArgument, "schedule name must be 1-64 letters, digits, '-', '_' or '.'")
+	}
+	if _, err := cron.Parse(schedule.Cron); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid cron expression: %s", err)
+	}
+	if schedule.Spec == nil {
+		return nil, status.Error(codes.InvalidArgument, "schedule spec is required")
+	}
+	if _, ok := jobmanagerpb.MissedRunPolicy_name[int32(schedule.MissedRuns)]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "Unknown missed_runs policy %d", schedule.MissedRuns)
+	}
+	var jitter time.Duration
+	if schedule.Jitter != nil {
+		if !schedule.Jitter.IsValid() || schedule.Jitter.AsDuration() < 0 || schedule.Jitter.AsDuration() > maxScheduleJitter {
+			return nil, status.Errorf(codes.InvalidArgument, "jitter must be between 0 and %s", maxScheduleJitter)
+		}
+		jitter = schedule.Jitter.AsDuration()
+	}
+	// As it will be started, with the schedule's label
+	spec, st := j.renderSpec(scheduledSpec(schedule.Name, schedule.Spec))
+	if st != nil {
+		return nil, st.Err()
+	}
+	if err := validateSpec(spec); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := j.checkRendered(user, schedule.Spec, spec); err != nil {
+		return nil, err
+	}
+
+	stored := store.Schedule{
+		ID:         scheduleID(user, schedule.Name),
+		Owner:      user,
+		Cron:       schedule.Cron,
+		Spec:       schedule.Spec,
+		MissedRuns: schedule.MissedRuns,
+		Jitter:     jitter,
+	}
+	// Replacing a schedule keeps its history, so it doesn't run again
+	// for a time it already ran
+	existing, err := j.store.GetSchedule(ctx, stored.ID)
+	created := errors.Is(err, store.ErrNotFound)
+	if err != nil && !created {
+		subLogger.Error("Error getting schedule", "error", err)
+		return nil, status.Error(codes.Internal, "Error saving schedule")
+	}
+	if created {
+		stored.Created = j.clock.Now()
+	} else {
+		stored.Created, stored.LastRun, stored.LastDue, stored.Runs = existing.Created, existing.LastRun, existing.LastDue, existing.Runs
+	}
+	if err := j.store.PutSchedule(ctx, stored); err != nil {
+		subLogger.Error("Error putting schedule", "error", err)
+		return nil, status.Error(codes.Internal, "Error saving schedule")
+	}
+	return &jobmanagerpb.PutScheduleResponse{Schedule: scheduleToProto(stored), Created: created}, nil
+}
+
+func (j *Jobby) ListSchedules(ctx context.Context, req *jobmanagerpb.ListSchedulesRequest) (*jobmanagerpb.ListSchedulesResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	slog.Info("Handling 'ListSchedules' request", "user", user, "request", req)
+	if j.store == nil {
+		return nil, errNoScheduleStore
+	}
+	schedules, err := j.store.ListSchedules(ctx, user)
+	if err != nil {
+		slog.Error("Error listing schedules", "user", user, "error", err)
+		return nil, status.Error(codes.Internal, "Error listing schedules")
+	}
+	resp := &jobmanagerpb.ListSchedulesResponse{}
+	for _, schedule := range schedules {
+		resp.Schedules = append(resp.Schedules, scheduleToProto(schedule))
+	}
+	slices.SortFunc(resp.Schedules, func(a, b *jobmanagerpb.Schedule) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return resp, nil
+}
+
+func (j *Jobby) DeleteSchedule(ctx context.Context, req *jobmanagerpb.DeleteScheduleRequest) (*jobmanagerpb.DeleteScheduleResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	slog.Info("Handling 'DeleteSchedule' request", "user", user, "request", req)
+	if j.store == nil {
+		return nil, errNoScheduleStore
+	}
+	id := scheduleID(user, req.Name)
+	if _, err := j.store.GetSchedule(ctx, id); errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "Schedule '%s' not found", req.Name)
+	} else if err != nil {
+		slog.Error("Error getting schedule", "user", user, "error", err)
+		return nil, status.Error(codes.Internal, "Error deleting schedule")
+	}
+	if err := j.store.DeleteSchedule(ctx, id); err != nil {
+		slog.Error("Error deleting schedule", "user", user, "error", err)
+		return nil, status.Error(codes.Internal, "Error deleting schedule")
+	}
+	return &jobmanagerpb.DeleteScheduleResponse{}, nil
+}
+
+func (j *Jobby) ListScheduleRuns(ctx context.Context, req *jobmanagerpb.ListScheduleRunsRequest) (*jobmanagerpb.ListScheduleRunsResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	slog.Info("Handling 'ListScheduleRuns' request", "user", user, "request", req)
+	if j.store == nil {
+		return nil, errNoScheduleStore
+	}
+	upcoming := int(req.Upcoming)
+	if upcoming == 0 {
+		upcoming = defaultUpcomingRuns
+	} else if upcoming > maxUpcomingRuns {
+		return nil, status.Errorf(codes.InvalidArgument, "upcoming must not exceed %d", maxUpcomingRuns)
+	}
+	schedule, err := j.store.GetSchedule(ctx, scheduleID(user, req.Name))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "Schedule '%s' not found", req.Name)
+	} else if err != nil {
+		slog.Error("Error getting schedule", "user", user, "error", err)
+		return nil, status.Error(codes.Internal, "Error listing schedule runs")
+	}
+
+	resp := &jobmanagerpb.ListScheduleRunsResponse{}
+	for _, run := range slices.Backward(schedule.Runs) {
+		resp.Past = append(resp.Past, scheduleRunToProto(schedule, run))
+	}
+	expr, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return resp, nil
+	}
+	for next := nextRun(schedule, expr); !next.IsZero() && len(resp.Upcoming) < upcoming; next = expr.Next(next) {
+		resp.Upcoming = append(resp.Upcoming, &jobmanagerpb.ScheduleRun{
+			ScheduledFor: timestamppb.New(next),
+			StartAt:      timestamppb.New(runStart(schedule, next)),
+		})
+	}
+	return resp, nil
+}
+
+// Runs of a schedule whose time to start has come
+type dueRuns struct {
+	// Oldest first. Only the latest maxCatchUpRuns
+	times []time.Time
+	// Runs before them, left out
+	dropped int
+	// The latest of those. Zero if none
+	lastDropped time.Time
+}
+
+func findDueRuns(schedule store.Schedule, expr cron.Expression, now time.Time) dueRuns {
+	var due dueRuns
+	for next := nextRun(schedule, expr); !next.IsZero() && !runStart(schedule, next).After(now); next = expr.Next(next) {
+		if len(due.times) == maxCatchUpRuns {
+			due.lastDropped = due.times[0]
+			due.dropped++
+			due.times = due.times[1:]
+		}
+		due.times = append(due.times, next)
+	}
+	return due
+}
+
+// RunSchedules starts a job for each schedule that's due as of 'now', as
+// the schedule's owner. Runs missed while no server was up to start them
+// are handled as the schedule's missed run policy says. Runs that can't
+// start (ex: the server is at capacity, or the last run is still going)
+// are skipped, except that RUN_ALL schedules wait for the last run to
+// finish. It should be run by one of the servers sharing the store (see
+// package leader). Returns the number of jobs started
+func (j *Jobby) RunSchedules(ctx context.Context, now time.Time) (int, error) {
+	if j.store == nil {
+		return 0, nil
+	}
+	schedules, err := j.store.ListSchedules(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	started := 0
+	var errs []error
+	for _, schedule := range schedules {
+		expr, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("schedule %s: %w", schedule.ID, err))
+			continue
+		}
+		if lastDue(schedule).IsZero() {
+			// Nothing to count from. Start counting now
+			schedule.Created = now
+			errs = append(errs, j.store.PutSchedule(ctx, schedule))
+			continue
+		}
+		due := findDueRuns(schedule, expr, now)
+		if len(due.times) == 0 {
+			continue
+		}
+
+		runs, handled := j.runDueRuns(ctx, schedule, due, now)
+		if handled.IsZero() {
+			// Still waiting for the last run to finish
+			continue
+		}
+		// Put back what's stored now, in case the schedule was replaced
+		// since we listed it
+		current, err := j.store.GetSchedule(ctx, schedule.ID)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		current.LastDue = handled
+		for _, run := range runs {
+			if !run.Started.IsZero() {
+				current.LastRun = run.Started
+				started++
+			}
+		}
+		current.Runs = append(current.Runs, runs...)
+		current.Runs = current.Runs[max(len(current.Runs)-maxScheduleRuns, 0):]
+		errs = append(errs, j.store.PutSchedule(ctx, current))
+	}
+	return started, errors.Join(errs...)
+}
+
+// Start the schedule's due runs as its missed run policy says. Returns what
+// came of them, and the time of the last one dealt with. That's zero if
+// none were, as the schedule is waiting for the last run to finish
+func (j *Jobby) runDueRuns(ctx context.Context, schedule store.Schedule, due dueRuns, now time.Time) ([]store.ScheduleRun, time.Time) {
+	subLogger := slog.With("user", schedule.Owner, "schedule", scheduleName(schedule))
+	latest := due.times[len(due.times)-1]
+	var runs []store.ScheduleRun
+	switch schedule.MissedRuns {
+	case jobmanagerpb.MissedRunPolicy_MISSED_RUN_POLICY_SKIP:
+		// Runs that are merely late (ex: by their jitter) still start
+		missed := due.dropped
+		var lastMissed time.Time
+		var onTime []time.Time
+		for _, next := range due.times {
+			if now.Sub(runStart(schedule, next)) > missedRunGrace {
+				missed++
+				lastMissed = next
+			} else {
+				onTime = append(onTime, next)
+			}
+		}
+		if missed > 0 {
+			subLogger.Info("Skipped missed scheduled runs", "count", missed)
+			runs = append(runs, store.ScheduleRun{Due: lastMissed, Skipped: "missed", Missed: missed - 1})
+		}
+		for _, next := range onTime {
+			run, _ := j.startScheduledRun(ctx, subLogger, schedule, next, now)
+			runs = append(runs, run)
+		}
+		return runs, latest
+
+	case jobmanagerpb.MissedRunPolicy_MISSED_RUN_POLICY_RUN_ALL:
+		handled := time.Time{}
+		if due.dropped > 0 {
+			subLogger.Info("Skipped scheduled runs too old to catch up on", "count", due.dropped)
+			runs = append(runs, store.ScheduleRun{Due: due.lastDropped, Skipped: "too many missed runs to catch up on", Missed: due.dropped - 1})
+			handled = due.lastDropped
+		}
+		for _, next := range due.times {
+			run, busy := j.startScheduledRun(ctx, subLogger, schedule, next, now)
+			if busy {
+				// Tried again next time
+				break
+			}
+			runs = append(runs, run)
+			handled = next
+		}
+		return runs, handled
+
+	default:
+		// Once, for the latest
+		run, _ := j.startScheduledRun(ctx, subLogger, schedule, latest, now)
+		run.Missed = len(due.times) - 1 + due.dropped
+		return append(runs, run), latest
+	}
+}
+
+// Start the schedule's run due at 'due', as of 'now'. 'busy' is set if it
+// couldn't because the last run (or another job holding its mutex) is still going
+func (j *Jobby) startScheduledRun(ctx context.Context, subLogger *slog.Logger, schedule store.Schedule, due, now time.Time) (run store.ScheduleRun, busy bool) {
+	run = store.ScheduleRun{Due: due}
+	req := &jobmanagerpb.StartJobRequest{Spec: scheduledSpec(scheduleName(schedule), schedule.Spec)}
+	var resp *jobmanagerpb.StartJobResponse
+	// The policy may have changed since the schedule was put
+	err := j.checkStart(schedule.Owner, req)
+	if err == nil {
+		resp, err = j.startJob(ctx, subLogger, schedule.Owner, req)
+	}
+	if err != nil {
+		code := status.Code(err)
+		busy = code == codes.AlreadyExists || code == codes.Aborted
+		if !busy || schedule.MissedRuns != jobmanagerpb.MissedRunPolicy_MISSED_RUN_POLICY_RUN_ALL {
+			subLogger.Warn("Skipped scheduled run", "due", due, "error", err)
+		}
+		run.Skipped = status.Convert(err).Message()
+		return run, busy
+	}
+	subLogger.Info("Started scheduled job", "job-id", resp.Id, "due", due)
+	run.Started = now
+	run.JobID, _ = uuid.Parse(resp.Id)
+	return run, false
+}
+
+// Checks a scheduled run, which the policy's interceptor never sees
+func (j *Jobby) checkStart(owner string, req *jobmanagerpb.StartJobRequest) error {
+	if j.policy == nil {
+		return nil
+	}
+	return j.policy.CheckStart(owner, req)
+}
+
+// RunScheduler runs schedules every interval until the context is cancelled
+func (j *Jobby) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := j.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			if _, err := j.RunSchedules(ctx, now); err != nil && ctx.Err() == nil {
+				slog.Error("Failed to run schedules", "error", err)
+			}
+		}
+	}
+}
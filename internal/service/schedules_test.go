@@ -0,0 +1,313 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/policy"
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestSchedules(t *testing.T) {
+	ctx := context.Background()
+	metadata, err := store.OpenBolt(filepath.Join(t.TempDir(), "jobby.db"))
+	require.NoError(t, err)
+	defer metadata.Close()
+	created := time.Date(2025, time.January, 15, 10, 30, 20, 0, time.Local)
+	fake := clock.NewFake(created)
+	users := &mockUserGetter{user: "alice"}
+	jobService := service.NewJobService(users, t.TempDir(), service.WithStore(metadata), service.WithClock(fake))
+	hourly := &jobmanagerpb.Schedule{
+		Name: "hourly",
+		Cron: "0 * * * *",
+		Spec: &jobmanagerpb.JobSpec{Command: "/bin/true", Labels: map[string]string{"team": "infra"}},
+	}
+
+	t.Run("invalid", func(tt *testing.T) {
+		for _, schedule := range []*jobmanagerpb.Schedule{
+			nil,
+			{Name: "has spaces", Cron: "@daily", Spec: hourly.Spec},
+			{Name: "nightly", Cron: "0 25 * * *", Spec: hourly.Spec},
+			{Name: "nightly", Cron: "@daily"},
+			{Name: "nightly", Cron: "@daily", Spec: &jobmanagerpb.JobSpec{}},
+		} {
+			_, err := jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: schedule})
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err), schedule)
+		}
+	})
+
+	t.Run("put", func(tt *testing.T) {
+		resp, err := jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: hourly})
+		require.NoError(tt, err)
+		assert.True(tt, resp.Created)
+		assert.True(tt, created.Equal(resp.Schedule.CreatedAt.AsTime()))
+		assert.Nil(tt, resp.Schedule.LastRun)
+		assert.True(tt, created.Truncate(time.Hour).Add(time.Hour).Equal(resp.Schedule.NextRun.AsTime()))
+
+		resp, err = jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: hourly})
+		require.NoError(tt, err)
+		assert.False(tt, resp.Created)
+	})
+
+	t.Run("run", func(tt *testing.T) {
+		started, err := jobService.RunSchedules(ctx, created.Add(10*time.Minute))
+		require.NoError(tt, err)
+		assert.Zero(tt, started)
+
+		due := time.Date(2025, time.January, 15, 11, 0, 5, 0, time.Local)
+		started, err = jobService.RunSchedules(ctx, due)
+		require.NoError(tt, err)
+		assert.Equal(tt, 1, started)
+		// Not again for the same hour
+		started, err = jobService.RunSchedules(ctx, due.Add(10*time.Second))
+		require.NoError(tt, err)
+		assert.Zero(tt, started)
+
+		list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+		require.NoError(tt, err)
+		require.Len(tt, list.Jobs, 1)
+		assert.Equal(tt, map[string]string{"team": "infra", "schedule": "hourly"}, list.Jobs[0].Spec.Labels)
+
+		schedules, err := jobService.ListSchedules(ctx, &jobmanagerpb.ListSchedulesRequest{})
+		require.NoError(tt, err)
+		require.Len(tt, schedules.Schedules, 1)
+		assert.True(tt, due.Equal(schedules.Schedules[0].LastRun.AsTime()))
+		assert.True(tt, due.Truncate(time.Hour).Add(time.Hour).Equal(schedules.Schedules[0].NextRun.AsTime()))
+		// The caller's spec, without the label
+		assert.Equal(tt, map[string]string{"team": "infra"}, schedules.Schedules[0].Spec.Labels)
+
+		// Hours of downtime are made up for with one run, once the last
+		// one is done
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: list.Jobs[0].JobId})
+		require.NoError(tt, err)
+		started, err = jobService.RunSchedules(ctx, due.Add(5*time.Hour))
+		require.NoError(tt, err)
+		assert.Equal(tt, 1, started)
+	})
+
+	t.Run("owners", func(tt *testing.T) {
+		users.user = "bob"
+		defer func() { users.user = "alice" }()
+		schedules, err := jobService.ListSchedules(ctx, &jobmanagerpb.ListSchedulesRequest{})
+		require.NoError(tt, err)
+		assert.Empty(tt, schedules.Schedules)
+		_, err = jobService.DeleteSchedule(ctx, &jobmanagerpb.DeleteScheduleRequest{Name: "hourly"})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("delete", func(tt *testing.T) {
+		_, err := jobService.DeleteSchedule(ctx, &jobmanagerpb.DeleteScheduleRequest{Name: "hourly"})
+		require.NoError(tt, err)
+		schedules, err := jobService.ListSchedules(ctx, &jobmanagerpb.ListSchedulesRequest{})
+		require.NoError(tt, err)
+		assert.Empty(tt, schedules.Schedules)
+	})
+
+	t.Run("no store", func(tt *testing.T) {
+		noStore := service.NewJobService(users, tt.TempDir())
+		_, err := noStore.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: hourly})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestMissedScheduleRuns(t *testing.T) {
+	ctx := context.Background()
+	metadata, err := store.OpenBolt(filepath.Join(t.TempDir(), "jobby.db"))
+	require.NoError(t, err)
+	defer metadata.Close()
+	created := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.Local)
+	fake := clock.NewFake(created)
+	users := &mockUserGetter{user: "alice"}
+	jobService := service.NewJobService(users, t.TempDir(), service.WithStore(metadata), service.WithClock(fake))
+	put := func(tt *testing.T, name string, policy jobmanagerpb.MissedRunPolicy, command string, args ...string) {
+		_, err := jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: &jobmanagerpb.Schedule{
+			Name:       name,
+			Cron:       "0 * * * *",
+			MissedRuns: policy,
+			// Or they'd be duplicates of each other
+			Spec: &jobmanagerpb.JobSpec{Command: command, Args: args, Env: map[string]string{"SCHEDULE": name}},
+		}})
+		require.NoError(tt, err)
+	}
+	runs := func(tt *testing.T, name string) *jobmanagerpb.ListScheduleRunsResponse {
+		resp, err := jobService.ListScheduleRuns(ctx, &jobmanagerpb.ListScheduleRunsRequest{Name: name, Upcoming: 2})
+		require.NoError(tt, err)
+		return resp
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, time.January, 15, hour, minute, 0, 0, time.Local)
+	}
+
+	put(t, "skip", jobmanagerpb.MissedRunPolicy_MISSED_RUN_POLICY_SKIP, "/bin/true")
+	put(t, "once", jobmanagerpb.MissedRunPolicy_MISSED_RUN_POLICY_RUN_ONCE, "/bin/true")
+	put(t, "all", jobmanagerpb.MissedRunPolicy_MISSED_RUN_POLICY_RUN_ALL, "/bin/sleep", "sleep", "30")
+
+	// Down until 15:10, so the runs at 11:00 to 15:00 were missed
+	started, err := jobService.RunSchedules(ctx, at(15, 10))
+	require.NoError(t, err)
+	assert.Equal(t, 2, started)
+
+	t.Run("skip", func(tt *testing.T) {
+		resp := runs(tt, "skip")
+		require.Len(tt, resp.Past, 1)
+		assert.True(tt, at(15, 0).Equal(resp.Past[0].ScheduledFor.AsTime()))
+		assert.Equal(tt, "missed", resp.Past[0].Skipped)
+		assert.Equal(tt, uint32(4), resp.Past[0].Missed)
+		assert.Empty(tt, resp.Past[0].JobId)
+		require.Len(tt, resp.Upcoming, 2)
+		assert.True(tt, at(16, 0).Equal(resp.Upcoming[0].ScheduledFor.AsTime()))
+		assert.True(tt, at(17, 0).Equal(resp.Upcoming[1].ScheduledFor.AsTime()))
+	})
+
+	t.Run("once", func(tt *testing.T) {
+		resp := runs(tt, "once")
+		require.Len(tt, resp.Past, 1)
+		assert.True(tt, at(15, 0).Equal(resp.Past[0].ScheduledFor.AsTime()))
+		assert.True(tt, at(15, 10).Equal(resp.Past[0].StartedAt.AsTime()))
+		assert.Equal(tt, uint32(4), resp.Past[0].Missed)
+		assert.NotEmpty(tt, resp.Past[0].JobId)
+	})
+
+	t.Run("all", func(tt *testing.T) {
+		// The rest wait for the first, which is still going
+		resp := runs(tt, "all")
+		require.Len(tt, resp.Past, 1)
+		assert.True(tt, at(11, 0).Equal(resp.Past[0].ScheduledFor.AsTime()))
+		require.NotEmpty(tt, resp.Past[0].JobId)
+		assert.True(tt, at(12, 0).Equal(resp.Upcoming[0].ScheduledFor.AsTime()))
+
+		started, err := jobService.RunSchedules(ctx, at(15, 11))
+		require.NoError(tt, err)
+		assert.Zero(tt, started)
+
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{Id: resp.Past[0].JobId})
+		require.NoError(tt, err)
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{Id: resp.Past[0].JobId})
+		require.NoError(tt, err)
+		started, err = jobService.RunSchedules(ctx, at(15, 12))
+		require.NoError(tt, err)
+		assert.Equal(tt, 1, started)
+
+		resp = runs(tt, "all")
+		require.Len(tt, resp.Past, 2)
+		// Newest first
+		assert.True(tt, at(12, 0).Equal(resp.Past[0].ScheduledFor.AsTime()))
+		assert.True(tt, at(13, 0).Equal(resp.Upcoming[0].ScheduledFor.AsTime()))
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{Id: resp.Past[0].JobId})
+		require.NoError(tt, err)
+	})
+
+	t.Run("on-time", func(tt *testing.T) {
+		// Nothing was missed, so skip runs too
+		_, err := jobService.RunSchedules(ctx, at(16, 0).Add(5*time.Second))
+		require.NoError(tt, err)
+		resp := runs(tt, "skip")
+		require.Len(tt, resp.Past, 2)
+		assert.True(tt, at(16, 0).Equal(resp.Past[0].ScheduledFor.AsTime()))
+		assert.NotEmpty(tt, resp.Past[0].JobId)
+		assert.Zero(tt, resp.Past[0].Missed)
+	})
+
+	t.Run("not-found", func(tt *testing.T) {
+		_, err := jobService.ListScheduleRuns(ctx, &jobmanagerpb.ListScheduleRunsRequest{Name: "nope"})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		_, err = jobService.ListScheduleRuns(ctx, &jobmanagerpb.ListScheduleRunsRequest{Name: "skip", Upcoming: 1000})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestScheduleJitter(t *testing.T) {
+	ctx := context.Background()
+	metadata, err := store.OpenBolt(filepath.Join(t.TempDir(), "jobby.db"))
+	require.NoError(t, err)
+	defer metadata.Close()
+	created := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.Local)
+	users := &mockUserGetter{user: "alice"}
+	jobService := service.NewJobService(users, t.TempDir(), service.WithStore(metadata), service.WithClock(clock.NewFake(created)))
+	schedule := &jobmanagerpb.Schedule{
+		Name:   "hourly",
+		Cron:   "0 * * * *",
+		Spec:   &jobmanagerpb.JobSpec{Command: "/bin/true"},
+		Jitter: durationpb.New(30 * time.Minute),
+	}
+
+	t.Run("invalid", func(tt *testing.T) {
+		for _, jitter := range []time.Duration{-time.Minute, 2 * time.Hour} {
+			invalid := proto.Clone(schedule).(*jobmanagerpb.Schedule)
+			invalid.Jitter = durationpb.New(jitter)
+			_, err := jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: invalid})
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err), jitter)
+		}
+		invalid := proto.Clone(schedule).(*jobmanagerpb.Schedule)
+		invalid.MissedRuns = 42
+		_, err := jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: invalid})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	resp, err := jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: schedule})
+	require.NoError(t, err)
+	runs, err := jobService.ListScheduleRuns(ctx, &jobmanagerpb.ListScheduleRunsRequest{Name: "hourly"})
+	require.NoError(t, err)
+	require.Len(t, runs.Upcoming, 10)
+	offsets := map[time.Duration]bool{}
+	for _, run := range runs.Upcoming {
+		offset := run.StartAt.AsTime().Sub(run.ScheduledFor.AsTime())
+		assert.GreaterOrEqual(t, offset, time.Duration(0))
+		assert.Less(t, offset, 30*time.Minute)
+		offsets[offset] = true
+	}
+	// Each run's own
+	assert.Greater(t, len(offsets), 1)
+	next := runs.Upcoming[0]
+	assert.True(t, next.StartAt.AsTime().Equal(resp.Schedule.NextRun.AsTime()))
+
+	// Due, but not started until its jitter is up
+	started, err := jobService.RunSchedules(ctx, next.StartAt.AsTime().Add(-time.Nanosecond))
+	require.NoError(t, err)
+	assert.Zero(t, started)
+	started, err = jobService.RunSchedules(ctx, next.StartAt.AsTime())
+	require.NoError(t, err)
+	assert.Equal(t, 1, started)
+}
+
+func TestSchedulePolicy(t *testing.T) {
+	ctx := context.Background()
+	metadata, err := store.OpenBolt(filepath.Join(t.TempDir(), "jobby.db"))
+	require.NoError(t, err)
+	defer metadata.Close()
+	created := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.Local)
+	noFalse, err := policy.NewRule("no-false", "", `command != "/bin/false"`)
+	require.NoError(t, err)
+	jobService := service.NewJobService(&mockUserGetter{user: "alice"}, t.TempDir(), service.WithStore(metadata),
+		service.WithClock(clock.NewFake(created)), service.WithPolicy(policy.New(noFalse)))
+
+	// As if put before the rule was added, as the interceptor would have refused it
+	_, err = jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: &jobmanagerpb.Schedule{
+		Name: "hourly",
+		Cron: "0 * * * *",
+		Spec: &jobmanagerpb.JobSpec{Command: "/bin/false"},
+	}})
+	require.NoError(t, err)
+	started, err := jobService.RunSchedules(ctx, created.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, started)
+
+	runs, err := jobService.ListScheduleRuns(ctx, &jobmanagerpb.ListScheduleRunsRequest{Name: "hourly"})
+	require.NoError(t, err)
+	require.Len(t, runs.Past, 1)
+	assert.Equal(t, "Denied by policy rule 'no-false'", runs.Past[0].Skipped)
+	list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Jobs)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"path/filepath"
+
+	"github.com/gopheryan/jobby/job"
+)
+
+// Jobs with a scratch directory (see JobSpec.scratch) find it here, as do
+// their hooks
+const ScratchEnv = "JOBBY_SCRATCH"
+
+// WithScratchTmpfs lets jobs keep their scratch directory in a tmpfs of up to
+// 'maxBytes'. Without it they may only have one on disk. The server needs
+// CAP_SYS_ADMIN to mount them
+func WithScratchTmpfs(maxBytes uint64) Option {
+	return func(j *Jobby) {
+		j.maxScratchTmpfs = maxBytes
+	}
+}
+
+// Where the job's scratch directory is. Empty if it has none
+func (d *jobData) scratchPath() string {
+	if d.spec.Scratch == nil {
+		return ""
+	}
+	return filepath.Join(d.directory, d.id.String()+"-scratch")
+}
+
+// Create the job's scratch directory, if it asked for one
+func (d *jobData) createScratch() error {
+	path := d.scratchPath()
+	if path == "" {
+		return nil
+	}
+	return job.CreateScratch(path, d.spec.Scratch.TmpfsBytes)
+}
+
+// Delete the job's scratch directory, if it has one
+func (d *jobData) removeScratch() error {
+	path := d.scratchPath()
+	if path == "" {
+		return nil
+	}
+	return job.RemoveScratch(path, d.spec.Scratch.TmpfsBytes > 0)
+}
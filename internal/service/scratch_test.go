@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestScratch(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, dir, service.WithScratchTmpfs(1<<20))
+	// Finds its scratch directory, and leaves something in it
+	fill := &jobmanagerpb.JobSpec{Shell: `echo -n "$JOBBY_SCRATCH" && echo hello > "$JOBBY_SCRATCH/hello"`, Scratch: &jobmanagerpb.Scratch{}}
+
+	run := func(tt *testing.T, spec *jobmanagerpb.JobSpec) (string, string) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec, Force: true})
+		require.NoError(tt, err)
+		st, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		require.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_COMPLETED, st.StateReason)
+		stdout, err := os.ReadFile(filepath.Join(dir, resp.Id+"-1-stdout"))
+		require.NoError(tt, err)
+		return resp.Id, string(stdout)
+	}
+
+	t.Run("disk", func(tt *testing.T) {
+		id, scratch := run(tt, fill)
+		assert.Equal(tt, filepath.Join(dir, id+"-scratch"), scratch)
+		data, err := os.ReadFile(filepath.Join(scratch, "hello"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "hello\n", string(data))
+
+		// Kept until the job is deleted
+		_, err = jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{Id: id, Force: true})
+		require.NoError(tt, err)
+		assert.NoDirExists(tt, scratch)
+	})
+
+	t.Run("none", func(tt *testing.T) {
+		_, scratch := run(tt, &jobmanagerpb.JobSpec{Shell: `echo -n "$JOBBY_SCRATCH"`})
+		assert.Empty(tt, scratch)
+	})
+
+	t.Run("tmpfs", func(tt *testing.T) {
+		if os.Geteuid() != 0 {
+			tt.Skip("mounting tmpfs requires root")
+		}
+		spec := &jobmanagerpb.JobSpec{Shell: fill.Shell, Scratch: &jobmanagerpb.Scratch{TmpfsBytes: 1 << 20}}
+		id, scratch := run(tt, spec)
+		var fs unix.Statfs_t
+		require.NoError(tt, unix.Statfs(scratch, &fs))
+		assert.Equal(tt, int64(unix.TMPFS_MAGIC), fs.Type)
+
+		_, err := jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{Id: id, Force: true})
+		require.NoError(tt, err)
+		assert.NoDirExists(tt, scratch)
+	})
+
+	t.Run("tmpfs too big", func(tt *testing.T) {
+		spec := &jobmanagerpb.JobSpec{Command: "/bin/true", Scratch: &jobmanagerpb.Scratch{TmpfsBytes: 2 << 20}}
+		_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+
+		noTmpfs := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+		_, err = noTmpfs.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+)
+
+// Search parameters of a ListJobs request. Zero values match every job
+type jobFilter struct {
+	commandContains string
+	startedAfter    time.Time
+	startedBefore   time.Time
+	exitCode        *int32
+	session         string
+	// Canonical id of the parent job
+	parent string
+}
+
+func newJobFilter(req *jobmanagerpb.ListJobsRequest) (jobFilter, error) {
+	filter := jobFilter{
+		commandContains: req.CommandContains,
+		exitCode:        req.ExitCode,
+		session:         req.SessionId,
+	}
+	if req.StartedAfter != nil {
+		filter.startedAfter = req.StartedAfter.AsTime()
+	}
+	if req.StartedBefore != nil {
+		filter.startedBefore = req.StartedBefore.AsTime()
+	}
+	if req.ParentId != "" {
+		parent, err := jobid.Parse(req.ParentId)
+		if err != nil {
+			return jobFilter{}, errors.New("parent_id must be a valid job id")
+		}
+		filter.parent = parent.String()
+	}
+	if !filter.startedAfter.IsZero() && !filter.startedBefore.IsZero() && !filter.startedAfter.Before(filter.startedBefore) {
+		return jobFilter{}, errors.New("started_after must be before started_before")
+	}
+	return filter, nil
+}
+
+func (f jobFilter) matches(record *jobmanagerpb.JobRecord) bool {
+	if !strings.Contains(record.Command, f.commandContains) && !strings.Contains(record.Spec.GetShell(), f.commandContains) {
+		return false
+	}
+	started := record.StartTime.AsTime()
+	if !f.startedAfter.IsZero() && !started.After(f.startedAfter) {
+		return false
+	}
+	if !f.startedBefore.IsZero() && !started.Before(f.startedBefore) {
+		return false
+	}
+	if f.exitCode != nil && (record.ExitCode == nil || *record.ExitCode != *f.exitCode) {
+		return false
+	}
+	if f.session != "" && record.SessionId != f.session {
+		return false
+	}
+	if f.parent != "" && record.ParentId != f.parent {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Entries a log stream may fall behind by before newer ones are dropped
+const logStreamBuffer = 256
+
+// An entry of the server's own log
+type logEntry struct {
+	time    time.Time
+	level   slog.Level
+	message string
+	// Flattened. Keys of attributes in groups are prefixed with the group ("group.key")
+	attrs map[string]string
+	// Entries the stream dropped right before this one, because it fell behind
+	dropped uint64
+}
+
+// A StreamServerLogs caller
+type logSubscriber struct {
+	level   slog.Level
+	entries chan logEntry
+	// Entries dropped since the last one sent. Guarded by the hub's lock
+	dropped uint64
+}
+
+// Shared by a LogBroadcaster and the handlers derived from it
+type logHub struct {
+	lock        sync.Mutex
+	subscribers map[*logSubscriber]struct{}
+}
+
+// LogBroadcaster is a slog.Handler that passes records on to another
+// handler, and to the admins following the log with StreamServerLogs
+// (see WithServerLogs). Install it with slog.SetDefault
+type LogBroadcaster struct {
+	next slog.Handler
+	hub  *logHub
+	// Attributes added with WithAttrs, already flattened
+	attrs []slog.Attr
+	// Groups opened with WithGroup, joined with dots
+	prefix string
+}
+
+func NewLogBroadcaster(next slog.Handler) *LogBroadcaster {
+	return &LogBroadcaster{
+		next: next,
+		hub:  &logHub{subscribers: map[*logSubscriber]struct{}{}},
+	}
+}
+
+// Records a subscriber wants are enabled even when 'next' would drop them
+func (b *LogBroadcaster) Enabled(ctx context.Context, level slog.Level) bool {
+	if b.next.Enabled(ctx, level) {
+		return true
+	}
+	b.hub.lock.Lock()
+	defer b.hub.lock.Unlock()
+	for subscriber := range b.hub.subscribers {
+		if level >= subscriber.level {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *LogBroadcaster) Handle(ctx context.Context, record slog.Record) error {
+	b.broadcast(record)
+	if !b.next.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return b.next.Handle(ctx, record)
+}
+
+func (b *LogBroadcaster) broadcast(record slog.Record) {
+	b.hub.lock.Lock()
+	defer b.hub.lock.Unlock()
+	if len(b.hub.subscribers) == 0 {
+		return
+	}
+	var entry *logEntry
+	for subscriber := range b.hub.subscribers {
+		if record.Level < subscriber.level {
+			continue
+		}
+		if entry == nil {
+			entry = b.entry(record)
+		}
+		out := *entry
+		out.dropped = subscriber.dropped
+		// Never block logging on a slow stream
+		select {
+		case subscriber.entries <- out:
+			subscriber.dropped = 0
+		default:
+			subscriber.dropped++
+		}
+	}
+}
+
+func (b *LogBroadcaster) entry(record slog.Record) *logEntry {
+	entry := &logEntry{
+		time:    record.Time,
+		level:   record.Level,
+		message: record.Message,
+		attrs:   make(map[string]string, len(b.attrs)+record.NumAttrs()),
+	}
+	for _, attr := range b.attrs {
+		flattenAttr(entry.attrs, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		flattenAttr(entry.attrs, b.prefix, attr)
+		return true
+	})
+	return entry
+}
+
+func flattenAttr(out map[string]string, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	key := attr.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix
+	}
+	if value.Kind() == slog.KindGroup {
+		for _, member := range value.Group() {
+			flattenAttr(out, key, member)
+		}
+		return
+	}
+	if attr.Key == "" {
+		// Empty attributes are ignored, as slog's handlers do
+		return
+	}
+	out[key] = value.String()
+}
+
+func (b *LogBroadcaster) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *b
+	derived.next = b.next.WithAttrs(attrs)
+	derived.attrs = slices.Clip(b.attrs)
+	for _, attr := range attrs {
+		// Flattened now, under the groups opened so far
+		flat := map[string]string{}
+		flattenAttr(flat, b.prefix, attr)
+		for key, value := range flat {
+			derived.attrs = append(derived.attrs, slog.String(key, value))
+		}
+	}
+	return &derived
+}
+
+func (b *LogBroadcaster) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return b
+	}
+	derived := *b
+	derived.next = b.next.WithGroup(name)
+	if b.prefix != "" {
+		name = b.prefix + "." + name
+	}
+	derived.prefix = name
+	return &derived
+}
+
+// Follow the log from now on, at 'level' and above. Call 'unsubscribe' once done
+func (b *LogBroadcaster) subscribe(level slog.Level) (entries <-chan logEntry, unsubscribe func()) {
+	subscriber := &logSubscriber{level: level, entries: make(chan logEntry, logStreamBuffer)}
+	b.hub.lock.Lock()
+	defer b.hub.lock.Unlock()
+	b.hub.subscribers[subscriber] = struct{}{}
+	return subscriber.entries, func() {
+		b.hub.lock.Lock()
+		defer b.hub.lock.Unlock()
+		delete(b.hub.subscribers, subscriber)
+	}
+}
+
+// Levels between the named ones are rounded down
+func logLevelToProto(level slog.Level) jobmanagerpb.LogLevel {
+	switch {
+	case level >= slog.LevelError:
+		return jobmanagerpb.LogLevel_LOG_LEVEL_ERROR
+	case level >= slog.LevelWarn:
+		return jobmanagerpb.LogLevel_LOG_LEVEL_WARN
+	case level >= slog.LevelInfo:
+		return jobmanagerpb.LogLevel_LOG_LEVEL_INFO
+	default:
+		return jobmanagerpb.LogLevel_LOG_LEVEL_DEBUG
+	}
+}
+
+func logLevelFromProto(level jobmanagerpb.LogLevel) (slog.Level, bool) {
+	switch level {
+	case jobmanagerpb.LogLevel_LOG_LEVEL_DEBUG:
+		return slog.LevelDebug, true
+	case jobmanagerpb.LogLevel_LOG_LEVEL_UNSPECIFIED, jobmanagerpb.LogLevel_LOG_LEVEL_INFO:
+		return slog.LevelInfo, true
+	case jobmanagerpb.LogLevel_LOG_LEVEL_WARN:
+		return slog.LevelWarn, true
+	case jobmanagerpb.LogLevel_LOG_LEVEL_ERROR:
+		return slog.LevelError, true
+	default:
+		return 0, false
+	}
+}
+
+func (e logEntry) toProto() *jobmanagerpb.ServerLogEntry {
+	return &jobmanagerpb.ServerLogEntry{
+		Time:    timestamppb.New(e.time),
+		Level:   logLevelToProto(e.level),
+		Message: e.message,
+		Attrs:   e.attrs,
+		Dropped: e.dropped,
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogBroadcaster(t *testing.T) {
+	var out bytes.Buffer
+	logs := NewLogBroadcaster(slog.NewTextHandler(&out, nil))
+	logger := slog.New(logs)
+
+	entries, unsubscribe := logs.subscribe(slog.LevelDebug)
+	logger.With("job-id", "1234").WithGroup("request").Debug("Handling request", "user", "ryan", slog.Group("spec", "command", "/bin/true"))
+	entry := <-entries
+	assert.Equal(t, slog.LevelDebug, entry.level)
+	assert.Equal(t, "Handling request", entry.message)
+	assert.Equal(t, map[string]string{
+		"job-id":               "1234",
+		"request.user":         "ryan",
+		"request.spec.command": "/bin/true",
+	}, entry.attrs)
+	// Debug is still below what the next handler takes
+	assert.Empty(t, out.String())
+
+	logger.Info("Hello")
+	entry = <-entries
+	assert.Equal(t, "Hello", entry.message)
+	assert.Contains(t, out.String(), "msg=Hello")
+
+	t.Run("slow", func(tt *testing.T) {
+		for range logStreamBuffer + 3 {
+			logger.Info("Filler")
+		}
+		for range logStreamBuffer {
+			<-entries
+		}
+		logger.Info("Caught up")
+		entry := <-entries
+		assert.Equal(tt, "Caught up", entry.message)
+		assert.Equal(tt, uint64(3), entry.dropped)
+	})
+
+	t.Run("level", func(tt *testing.T) {
+		warnings, unsubscribeWarnings := logs.subscribe(slog.LevelWarn)
+		defer unsubscribeWarnings()
+		logger.Info("Quiet")
+		logger.Error("Loud")
+		assert.Equal(tt, "Quiet", (<-entries).message)
+		assert.Equal(tt, "Loud", (<-entries).message)
+		assert.Equal(tt, "Loud", (<-warnings).message)
+		assert.Empty(tt, warnings)
+	})
+
+	unsubscribe()
+	assert.False(t, logs.Enabled(context.Background(), slog.LevelDebug))
+}
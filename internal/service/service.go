@@ -2,36 +2,61 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
+	"maps"
+	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/buildinfo"
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/encryption"
+	"github.com/gopheryan/jobby/internal/features"
+	"github.com/gopheryan/jobby/internal/jobid"
+	"github.com/gopheryan/jobby/internal/policy"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/internal/templates"
 	"github.com/gopheryan/jobby/job"
 	"github.com/gopheryan/jobby/jobmanagerpb"
+	jobmanagerv2 "github.com/gopheryan/jobby/jobmanagerpb/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const defaultOutputBufferSize = 4096
 
+// How much of an attempt's stderr to include in its history
+const historyTailSize = 1024
+
+// How often GetJobProgress checks whether an attempt that
+// exited is followed by another one
+const progressPollInterval = 100 * time.Millisecond
+
 type UserGetter interface {
 	GetUserContext(context.Context) string
 }
 
+// Implemented by requests that identify a job. IDs may be
+// sent as raw bytes, in text form, or both
 type JobIDGetter interface {
 	GetJobId() []byte
-}
-
-type jobData struct {
-	// User who owns the job
-	Owner string
-	Job   *job.Job
+	GetId() string
 }
 
 type Jobby struct {
@@ -39,78 +64,456 @@ type Jobby struct {
 	// Used to determine which user a request is coming from
 	// decouples the service from the auth strategy
 	userGetter UserGetter
+	// Nil unless jobs are stopped once their owner's credentials lapse
+	credentialLeases *CredentialLeases
+	// Nil unless jobs may be given tokens (see WithJobTokens)
+	jobTokens *JobTokens
+	// Nil unless there are hooks (see WithPreStartHooks and WithPostJobHooks)
+	hooks *hookRunner
 	// Base directory in which to store output files for jobs
 	directory string
 	// Keep track of jobs!
 	// used as: map[uuid.UUID]*jobData
 	jobDirectory sync.Map
+	// Jobs deleted without force, until they're restored or purged.
+	// used as: map[uuid.UUID]*deletedJob
+	deletedJobs sync.Map
+	// Bounds on how long finished jobs are kept
+	retention RetentionLimits
+	// How output is grouped into GetJobOutput messages by default
+	batching OutputBatching
+	// Bandwidth limits for GetJobOutput streams
+	throttle *throttler
+	// Per-user limits on output kept on disk
+	quotas *quotaTracker
+	// Presets of limits jobs can select by name
+	runtimeClasses map[string]job.Limits
+	// Used by jobs that don't select a class. May be empty
+	defaultRuntimeClass string
+	// Network egress policies jobs can select by name
+	egressPolicies map[string]job.Egress
+	// Jobs the operator defined for users to start by name (see WithTemplates)
+	templates map[string]*templates.Template
+	// Wraps the data keys of encrypted output. Nil writes plaintext output
+	keys encryption.KeyWrapper
+	// Applied to every job's output before it's stored
+	redactions []job.Redaction
+	// When job output is synced to disk
+	outputSync job.SyncPolicy
+	// GPUs on this node jobs may be granted, ordered by index
+	gpus []job.GPU
+	// Largest tmpfs a job's scratch directory may be. Zero keeps them on disk
+	maxScratchTmpfs uint64
+	// Per-owner resource usage
+	usage *usageTracker
+	// Recent runs of each owner's jobs (see GetJobStats)
+	stats *statsTracker
+	// Usage metrics are registered here. Nil leaves them unexported
+	metrics prometheus.Registerer
+	// Limits how many jobs run at once
+	scheduler *scheduler
+	// Lifecycle events of every job
+	events *EventLog
+	// Catches accidental resubmissions of running jobs
+	duplicates *duplicateDetector
+	// Held by jobs started with a mutex
+	mutexes *mutexTable
+	// Checks jobs started from templates once they're rendered. Nil if
+	// there are no rules (see WithPolicy)
+	policy *policy.Policy
+	// Jobs may be started with 'public' (see WithPublicJobs)
+	publicJobs bool
+	// Sessions ended with EndSession
+	sessions *sessionTracker
+	// Users allowed to call admin RPCs (ex: StreamServerLogs)
+	admins []string
+	// Nil if the server's logs can't be streamed
+	serverLogs *LogBroadcaster
+	// Times jobs, retention and garbage collection (see WithClock)
+	clock clock.Clock
+	// Nil outside of tests (see WithFaultInjector)
+	faults *job.FaultInjector
+	// Feature flags (see WithFeatures)
+	features features.Set
+	// Set by Drain. New jobs are refused
+	draining atomic.Bool
+	// Where job records and events are written through to. Nil if
+	// they're only kept here (see WithStore)
+	store store.Store
+	// Which server this is, among those sharing the store (see WithNode)
+	node string
+}
+
+// Option customizes optional service behavior
+type Option func(*Jobby)
+
+// WithRetention sets the default and maximum retention of finished jobs
+func WithRetention(limits RetentionLimits) Option {
+	return func(j *Jobby) {
+		j.retention = limits
+	}
+}
+
+// WithOutputBatching sets the default batching of streamed job output.
+// Requests may override it
+func WithOutputBatching(batching OutputBatching) Option {
+	return func(j *Jobby) {
+		j.batching = batching
+	}
+}
+
+// WithOutputRateLimits caps the bandwidth used to stream job output
+func WithOutputRateLimits(limits OutputRateLimits) Option {
+	return func(j *Jobby) {
+		j.throttle = newThrottler(limits)
+	}
+}
+
+// WithQuotaLimits caps how much output each user's jobs may keep on disk
+func WithQuotaLimits(limits QuotaLimits) Option {
+	return func(j *Jobby) {
+		j.quotas = &quotaTracker{limits: limits}
+	}
+}
+
+// WithOutputEncryption encrypts job output files with per-job
+// data keys, wrapped by 'keys' (see the encryption package)
+func WithOutputEncryption(keys encryption.KeyWrapper) Option {
+	return func(j *Jobby) {
+		j.keys = keys
+	}
+}
+
+// WithRedactions masks text matching any of 'redactions' in the output of
+// every job. Output is redacted before it's written, so it never reaches
+// the disk or clients
+func WithRedactions(redactions []job.Redaction) Option {
+	return func(j *Jobby) {
+		j.redactions = redactions
+	}
+}
+
+// WithOutputSync sets when job output is synced to disk, trading
+// throughput for output that survives host crashes
+func WithOutputSync(policy job.SyncPolicy) Option {
+	return func(j *Jobby) {
+		j.outputSync = policy
+	}
+}
+
+// WithRuntimeClasses sets the runtime classes jobs may select, and the one
+// applied to jobs that don't select one (empty for no limits)
+func WithRuntimeClasses(classes map[string]job.Limits, defaultClass string) Option {
+	return func(j *Jobby) {
+		j.runtimeClasses = classes
+		j.defaultRuntimeClass = defaultClass
+	}
+}
+
+// WithEgressPolicies sets the network egress policies jobs may select.
+// Only jobs whose runtime class isolates the network can select one
+func WithEgressPolicies(policies map[string]job.Egress) Option {
+	return func(j *Jobby) {
+		j.egressPolicies = policies
+	}
+}
+
+// WithTemplates sets the templates jobs may be started from, by name
+// (see JobSpec.template)
+func WithTemplates(templates map[string]*templates.Template) Option {
+	return func(j *Jobby) {
+		j.templates = templates
+	}
+}
+
+// WithPolicy checks jobs against 'p' where the policy's interceptor can't:
+// those started from templates once the template is rendered, as it only
+// sees the request, and scheduled runs before each one starts
+func WithPolicy(p *policy.Policy) Option {
+	return func(j *Jobby) {
+		j.policy = p
+	}
+}
+
+// WithGPUs sets the GPUs jobs may be granted access to (see job.GPUs)
+func WithGPUs(gpus []job.GPU) Option {
+	return func(j *Jobby) {
+		j.gpus = gpus
+	}
+}
+
+// WithUsageAccounting sets the windows GetUsageSummary summarizes
+// usage over, and who may see the usage of every owner
+func WithUsageAccounting(accounting UsageAccounting) Option {
+	return func(j *Jobby) {
+		j.usage = newUsageTracker(accounting)
+	}
+}
+
+// WithMetrics registers the service's metrics (ex: per-owner CPU time) with 'reg'
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(j *Jobby) {
+		j.metrics = reg
+	}
 }
 
-func NewJobService(userGetter UserGetter, dir string) *Jobby {
-	return &Jobby{
+// WithCapacity limits how many jobs run at once. Beyond the limit, new jobs
+// preempt running jobs of lower priority or are refused
+func WithCapacity(capacity Capacity) Option {
+	return func(j *Jobby) {
+		j.scheduler = newScheduler(capacity)
+	}
+}
+
+// WithEventLog records job lifecycle events to 'events' rather than
+// only keeping them in memory (see OpenEventLog)
+func WithEventLog(events *EventLog) Option {
+	return func(j *Jobby) {
+		j.events = events
+	}
+}
+
+// PublicMethods serve public jobs to anyone (see WithPublicJobs). They're
+// the only ones callers without a client certificate should be let into
+var PublicMethods = []string{
+	"/jobby.JobManager/GetStatus",
+	"/jobby.JobManager/GetJobOutput",
+	"/jobmanager.v2.JobManager/GetStatus",
+	"/jobmanager.v2.JobManager/GetJobOutput",
+}
+
+// WithPublicJobs lets jobs be started with 'public', so anyone may read
+// their status and output through PublicMethods. Including anonymous callers,
+// if the auth layer lets them in
+func WithPublicJobs() Option {
+	return func(j *Jobby) {
+		j.publicJobs = true
+	}
+}
+
+// WithAdmins names the users allowed to call admin RPCs (ex: StreamServerLogs)
+func WithAdmins(admins []string) Option {
+	return func(j *Jobby) {
+		j.admins = admins
+	}
+}
+
+// WithClock replaces the real clock, for tests that move time along themselves
+func WithClock(c clock.Clock) Option {
+	return func(j *Jobby) {
+		j.clock = c
+	}
+}
+
+// WithFeatures switches feature flags from their defaults
+func WithFeatures(set features.Set) Option {
+	return func(j *Jobby) {
+		j.features = set
+	}
+}
+
+// WithFaultInjector makes jobs fail at the points 'faults' is told to, for
+// tests of how the service handles it
+func WithFaultInjector(faults *job.FaultInjector) Option {
+	return func(j *Jobby) {
+		j.faults = faults
+	}
+}
+
+// WithStore writes job records and events through to 's' as they change,
+// so they can be seen outside the server. Records are removed from it
+// along with their jobs
+func WithStore(s store.Store) Option {
+	return func(j *Jobby) {
+		j.store = s
+	}
+}
+
+// WithNode names this server in the records it writes to the store, so
+// servers sharing one can tell whose jobs are whose. Names must be unique
+// among them
+func WithNode(name string) Option {
+	return func(j *Jobby) {
+		j.node = name
+	}
+}
+
+// WithServerLogs lets admins follow the server's log with StreamServerLogs.
+// 'logs' must be the default logger's handler (see slog.SetDefault)
+func WithServerLogs(logs *LogBroadcaster) Option {
+	return func(j *Jobby) {
+		j.serverLogs = logs
+	}
+}
+
+func NewJobService(userGetter UserGetter, dir string, opts ...Option) *Jobby {
+	j := &Jobby{
 		userGetter: userGetter,
 		directory:  dir,
+		retention:  defaultRetentionLimits,
+		batching:   defaultOutputBatching,
+		throttle:   newThrottler(OutputRateLimits{}),
+		quotas:     &quotaTracker{},
+		usage:      newUsageTracker(defaultUsageAccounting),
+		stats:      newStatsTracker(),
+		scheduler:  newScheduler(Capacity{}),
+		events:     newMemoryEventLog(),
+		duplicates: newDuplicateDetector(),
+		mutexes:    newMutexTable(),
+		sessions:   newSessionTracker(),
+		clock:      clock.Real,
+	}
+	for _, opt := range opts {
+		opt(j)
 	}
+	j.usage.clock = j.clock
+	j.events.setStore(j.store)
+	j.sessions.clock = j.clock
+	if j.metrics != nil {
+		j.metrics.MustRegister(j.usage.collectors()...)
+		j.metrics.MustRegister(buildCollectors(j.features)...)
+	}
+	return j
 }
 
+// Register serves the original (v1) API, and the v2 API unless its
+// feature flag is off
 func (j *Jobby) Register(srv *grpc.Server) {
 	srv.RegisterService(&jobmanagerpb.JobManager_ServiceDesc, j)
+	if j.features.Enabled(features.V2API) {
+		srv.RegisterService(&jobmanagerv2.JobManager_ServiceDesc, &jobbyV2{v1: j})
+	}
 }
 
 func (j *Jobby) GetJobOutput(req *jobmanagerpb.GetJobOutputRequest, srv jobmanagerpb.JobManager_GetJobOutputServer) error {
-	subLogger := slog.With("user", j.userGetter.GetUserContext(srv.Context()), "request", req)
+	user := j.userGetter.GetUserContext(srv.Context())
+	subLogger := slog.With("user", user, "request", req)
 	subLogger.Info("Handling 'GetJobOutput' request")
 
-	jobData, st := j.getJob(srv.Context(), req)
+	jobData, st := j.getReadableJob(srv.Context(), req)
 	if st != nil {
 		return st.Err()
 	}
 
+	attempt, ok := jobData.attempt(req.Attempt)
+	if !ok {
+		return status.Error(codes.NotFound, "No such attempt exists")
+	}
+	if req.Range != nil {
+		return j.streamRange(srv, subLogger, user, jobData, attempt, req)
+	}
+
+	batching, err := j.batching.override(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var transform outputTransform
+	switch {
+	case req.CollapseRepeatedLines:
+		// Has to work with whole lines anyway
+		transform = &lineCollapser{}
+	case req.Mode == jobmanagerpb.StreamMode_STREAM_MODE_LINES:
+		transform = &lineBuffer{}
+	case req.Mode == jobmanagerpb.StreamMode_STREAM_MODE_RAW,
+		req.Mode == jobmanagerpb.StreamMode_STREAM_MODE_UNSPECIFIED:
+	default:
+		return status.Error(codes.InvalidArgument, "Unknown stream mode")
+	}
+	if req.StripTimestamps && jobData.spec.TimestampOutput {
+		// First, so the other transforms see lines as the job wrote them
+		strip := &timestampStripper{}
+		if transform == nil {
+			transform = strip
+		} else {
+			transform = transformChain{strip, transform}
+		}
+	}
+
 	var reader io.ReadCloser
-	var err error
-	if req.Type == jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT {
-		reader, err = jobData.Job.Stdout()
-	} else if req.Type == jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR {
-		reader, err = jobData.Job.Stderr()
-	} else {
+	switch {
+	case req.Type == jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT && req.NoFollow:
+		reader, err = attempt.job.StdoutSnapshot()
+	case req.Type == jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT:
+		reader, err = attempt.job.Stdout()
+	case req.Type == jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR && req.NoFollow:
+		reader, err = attempt.job.StderrSnapshot()
+	case req.Type == jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR:
+		reader, err = attempt.job.Stderr()
+	default:
 		return status.Error(codes.InvalidArgument, "Must specify valid output type")
 	}
 	if err != nil {
 		return status.Error(codes.Internal, "Error attaching to job output")
 	}
 
+	pump := batchedPump(batching, transform)
+	if req.NoFollow && transform == nil {
+		// A download of output already written. Send it as fast as we can
+		maxBytes := bulkMessageBytes
+		if req.BatchMaxBytes != 0 {
+			maxBytes = int(req.BatchMaxBytes)
+		}
+		pump = func(reader io.Reader, send func([]byte) error) (error, error) {
+			return bulkOutput(reader, maxBytes, send)
+		}
+	}
+	return j.streamOutput(srv.Context(), subLogger, user, jobData, reader, pump, srv.Send)
+}
+
+// Moves output from a reader to 'send' until the reader fails
+// (io.EOF included) or a send fails. See batchOutput
+type outputPump func(reader io.Reader, send func([]byte) error) (readErr error, sendErr error)
+
+func batchedPump(batching OutputBatching, transform outputTransform) outputPump {
+	return func(reader io.Reader, send func([]byte) error) (error, error) {
+		return batchOutput(reader, batching, transform, send)
+	}
+}
+
+// Send what 'reader' yields until it runs out, the caller goes away
+// or the job is stopped or deleted. Closes the reader
+func (j *Jobby) streamOutput(callerCtx context.Context, subLogger *slog.Logger, user string, jobData *jobData, reader io.ReadCloser,
+	pump outputPump, send func(*jobmanagerpb.GetJobOutputResponse) error) error {
 	// The caller can cancel/detach at any time. This cancellation is communicated
-	// to this handler via context cancellation
-	stop := context.AfterFunc(srv.Context(), func() {
-		subLogger.Info("GetJobOutput request cancelled")
+	// to this handler via context cancellation. So is the job being stopped or deleted
+	ctx, detach := jobData.attachReader(callerCtx)
+	defer detach()
+	stop := context.AfterFunc(ctx, func() {
+		subLogger.Info("Output stream cancelled", "cause", context.Cause(ctx))
 		// One call to 'Close' will shut down this whole operation...
 		// This is going to cause an error on our reader
-		if err = reader.Close(); err != nil {
+		if err := reader.Close(); err != nil {
 			subLogger.Error("Error closing job output reader", slog.String("error", err.Error()))
 		}
 
 	})
 	defer stop()
 
-	var readError error
-	var sendError error
-	var count int
-	buf := make([]byte, defaultOutputBufferSize)
-	// Read and send until one side fails
-	for readError == nil && sendError == nil {
-		count, readError = reader.Read(buf)
-		if count > 0 {
-			// Copy only as much as the reader returned
-			dst := make([]byte, count)
-			copy(dst, buf[:count])
-			sendError = srv.Send(&jobmanagerpb.GetJobOutputResponse{
-				Data: dst,
-			})
+	limiters := j.throttle.forStream(user)
+	// Checksum of what's sent, for the message that ends the stream
+	sum := sha256.New()
+	var total uint64
+	readError, sendError := pump(reader, func(data []byte) error {
+		if err := waitForBytes(ctx, limiters, len(data)); err != nil {
+			return err
 		}
+		sum.Write(data)
+		total += uint64(len(data))
+		return send(&jobmanagerpb.GetJobOutputResponse{
+			Data: data,
+		})
+	})
+
+	if errors.Is(readError, io.EOF) && sendError == nil && ctx.Err() == nil {
+		// Reached the end of the output. Let the caller check they got all of it
+		sendError = send(&jobmanagerpb.GetJobOutputResponse{
+			End: &jobmanagerpb.OutputEnd{TotalBytes: total, Sha256: sum.Sum(nil)},
+		})
 	}
 
 	if readError != nil {
-		if errors.Is(readError, io.EOF) || srv.Context().Err() != nil {
+		if errors.Is(readError, io.EOF) || ctx.Err() != nil {
 			// Silence readError if we got an EOF (clean end of stream)
 			// or we notice that the context was cancelled
 			// In the latter case, we intentionally closed our reader to
@@ -120,6 +523,10 @@ func (j *Jobby) GetJobOutput(req *jobmanagerpb.GetJobOutputRequest, srv jobmanag
 	}
 
 	var allErrors error
+	if callerCtx.Err() == nil && ctx.Err() != nil {
+		// Detached by the job being stopped or deleted. Tell the caller why
+		return context.Cause(ctx)
+	}
 	if allErrors = errors.Join(
 		reader.Close(),
 		sendError,
@@ -131,7 +538,7 @@ func (j *Jobby) GetJobOutput(req *jobmanagerpb.GetJobOutputRequest, srv jobmanag
 	} else {
 		// gRPC library is smart enough to translate this
 		// to the 'cancelled' status code for us (if it isn't nil)
-		return srv.Context().Err()
+		return callerCtx.Err()
 	}
 }
 
@@ -148,47 +555,337 @@ func jobStateToStatus(state job.State) *jobmanagerpb.Status {
 	}
 }
 
+func exitReasonToProto(reason job.ExitReason) jobmanagerpb.ExitReason {
+	switch reason {
+	case job.ExitReasonExited:
+		return jobmanagerpb.ExitReason_EXIT_REASON_EXITED
+	case job.ExitReasonSignaled:
+		return jobmanagerpb.ExitReason_EXIT_REASON_SIGNALED
+	case job.ExitReasonOOMKilled:
+		return jobmanagerpb.ExitReason_EXIT_REASON_OOM_KILLED
+	case job.ExitReasonTimedOut:
+		return jobmanagerpb.ExitReason_EXIT_REASON_TIMED_OUT
+	case job.ExitReasonQuotaExceeded:
+		return jobmanagerpb.ExitReason_EXIT_REASON_QUOTA_EXCEEDED
+	case job.ExitReasonStopped:
+		return jobmanagerpb.ExitReason_EXIT_REASON_STOPPED
+	case job.ExitReasonPreempted:
+		return jobmanagerpb.ExitReason_EXIT_REASON_PREEMPTED
+	case job.ExitReasonUnknown:
+		return jobmanagerpb.ExitReason_EXIT_REASON_UNKNOWN
+	default:
+		return jobmanagerpb.ExitReason_EXIT_REASON_UNSPECIFIED
+	}
+}
+
+// Name of the signal (ex: SIGKILL), or empty if there wasn't one
+func signalName(signal syscall.Signal) string {
+	if signal == 0 {
+		return ""
+	}
+	if name := unix.SignalName(signal); name != "" {
+		return name
+	}
+	return fmt.Sprintf("signal %d", signal)
+}
+
+// In hindsight, I could've just used a non-pointer value in the protos
+// and returned '-1' when the exit code is not available.
+// You could also argue that nil/non-nil is a more explicit way
+// to communicate presence (which is where I lean)
+func convertExitCode(i *int) *int32 {
+	if i == nil {
+		return nil
+	}
+	out := int32(*i)
+	return &out
+}
+
 func (j *Jobby) GetStatus(ctx context.Context, req *jobmanagerpb.GetStatusRequest) (*jobmanagerpb.GetStatusResponse, error) {
 	slog.Info("Handling 'GetStatus' request", "user", j.userGetter.GetUserContext(ctx), "request", req)
+	jobData, st := j.getReadableJob(ctx, req)
+	if st != nil {
+		return nil, st.Err()
+	}
+
+	return jobData.statusResponse(), nil
+}
+
+func (j *Jobby) WaitJob(ctx context.Context, req *jobmanagerpb.WaitJobRequest) (*jobmanagerpb.GetStatusResponse, error) {
+	slog.Info("Handling 'WaitJob' request", "user", j.userGetter.GetUserContext(ctx), "request", req)
 	jobData, st := j.getJob(ctx, req)
 	if st != nil {
 		return nil, st.Err()
 	}
 
-	// In hindsight, I could've just used a non-pointer value in the protos
-	// and returned '-1' when the exit code is not available.
-	// You could also argue that nil/non-nil is a more explicit way
-	// to communicate presence (which is where I lean)
-	convertExitCode := func(i *int) *int32 {
-		if i == nil {
-			return nil
-		}
-		out := int32(*i)
-		return &out
+	select {
+	case <-jobData.finished:
+		return jobData.statusResponse(), nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
 	}
+}
 
-	status := jobData.Job.Status()
-	return &jobmanagerpb.GetStatusResponse{
-		CurrentStatus: *jobStateToStatus(status.CurrentState),
-		ExitCode:      convertExitCode(status.ReturnCode),
-	}, nil
+// Status of the job's latest attempt
+func (d *jobData) statusResponse() *jobmanagerpb.GetStatusResponse {
+	latest := d.latest().job
+	status := latest.Status()
+	processes, err := latest.Processes()
+	if err != nil {
+		// The rest of the status is still worth having
+		slog.Error("Failed to list job processes", "job-id", d.id, "error", err)
+	}
+	out := &jobmanagerpb.GetStatusResponse{
+		CurrentStatus:     *jobStateToStatus(status.CurrentState),
+		ExitCode:          convertExitCode(status.ReturnCode),
+		Duration:          durationpb.New(status.Duration),
+		QuotaExceeded:     status.QuotaExceeded,
+		TimedOut:          status.TimedOut,
+		ExitReason:        exitReasonToProto(status.ExitReason),
+		Signal:            signalName(status.Signal),
+		Queued:            d.isQueued(),
+		Progress:          progressToProto(status.Progress),
+		Pid:               int32(status.PID),
+		Processes:         processesToProto(processes),
+		OutputContentType: d.spec.OutputContentType,
+		Outcome:           specOutcome(d.spec, status),
+	}
+	out.StateReason, out.StateMessage = d.stateReason()
+	return out
 }
 
 func (j *Jobby) StartJob(ctx context.Context, req *jobmanagerpb.StartJobRequest) (*jobmanagerpb.StartJobResponse, error) {
-	subLogger := slog.With("user", j.userGetter.GetUserContext(ctx), "request", req)
+	owner := j.userGetter.GetUserContext(ctx)
+	subLogger := slog.With("user", owner, "request", loggableRequest(req, (*jobmanagerpb.StartJobRequest).GetSpec))
 	subLogger.Info("Handling 'StartJob' request")
-	if req.Command == "" {
-		return nil, status.Error(codes.InvalidArgument, "Must provide non-empty command")
+	return j.startJob(ctx, subLogger, owner, req)
+}
+
+// Start a job for 'owner', who may not be the caller (ex: for schedules,
+// which run without one)
+func (j *Jobby) startJob(ctx context.Context, subLogger *slog.Logger, owner string, req *jobmanagerpb.StartJobRequest) (*jobmanagerpb.StartJobResponse, error) {
+	if j.draining.Load() {
+		return nil, errShuttingDown
+	}
+	spec, st := j.renderSpec(requestSpec(req))
+	if st != nil {
+		return nil, st.Err()
+	}
+	if err := validateSpec(spec); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := j.checkRendered(owner, requestSpec(req), spec); err != nil {
+		return nil, err
+	}
+	if spec.Public && !j.publicJobs {
+		return nil, status.Error(codes.FailedPrecondition, "Server doesn't allow public jobs")
+	}
+	tokenScopes := specTokenScopes(spec)
+	if len(tokenScopes) > 0 && j.jobTokens == nil {
+		return nil, status.Error(codes.FailedPrecondition, "Server doesn't give jobs tokens")
+	}
+	if scratch := spec.Scratch; scratch != nil && scratch.TmpfsBytes > 0 {
+		if j.maxScratchTmpfs == 0 {
+			return nil, status.Error(codes.FailedPrecondition, "Server doesn't allow tmpfs scratch directories")
+		}
+		if scratch.TmpfsBytes > j.maxScratchTmpfs {
+			return nil, status.Errorf(codes.InvalidArgument, "scratch.tmpfs_bytes must not exceed %d", j.maxScratchTmpfs)
+		}
+	}
+	if len(req.SessionId) > maxSessionIDLength {
+		return nil, status.Errorf(codes.InvalidArgument, "session_id must not be longer than %d bytes", maxSessionIDLength)
+	}
+	retention, err := j.retention.resolve(spec.Retention)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.CacheTtl != nil && (!req.CacheTtl.IsValid() || req.CacheTtl.AsDuration() < 0) {
+		return nil, status.Error(codes.InvalidArgument, "cache_ttl must not be negative")
+	}
+
+	className := spec.RuntimeClass
+	if className == "" {
+		className = j.defaultRuntimeClass
+	}
+	limits, ok := j.runtimeClasses[className]
+	if className != "" && !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "Unknown runtime class '%s'", className)
+	}
+	if spec.EgressPolicy != "" {
+		policy, ok := j.egressPolicies[spec.EgressPolicy]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "Unknown egress policy '%s'", spec.EgressPolicy)
+		}
+		if !limits.Isolation.Network {
+			return nil, status.Errorf(codes.InvalidArgument, "Runtime class '%s' doesn't isolate the network", className)
+		}
+		limits.Isolation.Egress = policy
+	}
+	if len(spec.Gpus) > 0 {
+		if limits.Cgroup == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Runtime class '%s' doesn't run jobs in a cgroup, so GPUs can't be granted", className)
+		}
+		gpus, err := j.grantedGPUs(spec.Gpus)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		limits.GPUs = gpus
+	}
+
+	if req.SessionId != "" && j.sessions.hasEnded(owner, req.SessionId) {
+		return nil, status.Errorf(codes.FailedPrecondition, "Session '%s' has ended", req.SessionId)
+	}
+	// Started with a job's token, so it's that job's child
+	var parent uuid.UUID
+	if j.jobTokens != nil {
+		if token, ok := j.jobTokens.fromContext(ctx); ok && token.owner == owner && token.has(jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_START_CHILD_JOBS) {
+			parent = token.job
+		}
+	}
+	command, args := specCommand(spec)
+	hash := specHash(command, args, spec.Env)
+	if req.CacheTtl != nil {
+		if cached := j.cachedResult(owner, spec, hash, req.CacheTtl.AsDuration()); cached != nil {
+			subLogger.Info("Reusing results of an identical job", "job-id", cached.id)
+			cached.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CACHE_HIT, owner, 0, "")
+			return &jobmanagerpb.StartJobResponse{
+				JobId:  cached.id[:],
+				Id:     cached.id.String(),
+				Cached: true,
+			}, nil
+		}
+	}
+	quota := j.quotas.forUser(owner)
+	if quota != nil && quota.exhausted() {
+		return nil, status.Error(codes.ResourceExhausted, "Output quota exceeded. Wait for old jobs to expire")
 	}
 
 	jobId := uuid.New()
-	newJob, err := job.New(job.JobArgs{
-		Command:    req.Command,
-		Args:       req.Args,
-		StdoutPath: outFilePath(j.directory, jobId, "stdout"),
-		StderrPath: outFilePath(j.directory, jobId, "sterr"),
-	})
+	releaseMutex, err := j.takeMutex(ctx, subLogger, spec, jobId)
+	if err != nil {
+		return nil, err
+	}
+	started := false
+	defer func() {
+		if !started {
+			releaseMutex()
+		}
+	}()
+
+	var wrappedKey []byte
+	if j.keys != nil {
+		dataKey, err := encryption.NewDataKey()
+		if err == nil {
+			wrappedKey, err = j.keys.Wrap(dataKey)
+		}
+		if err != nil {
+			subLogger.Error("Error creating output key", "error", err)
+			return nil, status.Error(codes.Internal, "Error starting job")
+		}
+	}
+
+	newJob := &jobData{
+		Owner:        owner,
+		id:           jobId,
+		spec:         spec,
+		specHash:     hash,
+		maxAttempts:  max(spec.MaxAttempts, 1),
+		directory:    j.directory,
+		retention:    retention,
+		quota:        quota,
+		quotaAction:  j.quotas.limits.Action,
+		startedAt:    j.clock.Now(),
+		runtimeClass: className,
+		limits:       specLimits(spec, limits),
+		keys:         j.keys,
+		wrappedKey:   wrappedKey,
+		redactions:   j.redactions,
+		outputSync:   j.outputSync,
+		usage:        j.usage,
+		stats:        j.stats,
+		store:        j.store,
+		node:         j.node,
+		scheduler:    j.scheduler,
+		events:       j.events,
+		hooks:        j.hooks,
+		clock:        j.clock,
+		faults:       j.faults,
+		finished:     make(chan struct{}),
+		session:      req.SessionId,
+		parent:       parent,
+		releaseMutex: releaseMutex,
+	}
+	if spec.Lease != nil {
+		newJob.leaseExpires = newJob.startedAt.Add(spec.Lease.AsDuration())
+	}
+	duplicate, done := j.duplicates.check(newJob, &j.jobDirectory, req.Force)
+	if done == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "Identical job %s is already running. Set force to start another", duplicate.id)
+	}
+	defer done()
+	var warnings []string
+	if duplicate != nil {
+		warnings = append(warnings, fmt.Sprintf("Identical job %s is already running", duplicate.id))
+	}
+
+	if headroom := j.scheduler.capacity.Headroom; headroom.enabled() {
+		short, err := headroom.check(j.directory, newJob.limits)
+		if err != nil {
+			// Our problem, not the job's. Start it anyway
+			subLogger.Error("Error checking host headroom", "error", err)
+		} else if short != "" {
+			subLogger.Warn("Refusing job without enough host headroom", "reason", short)
+			return nil, status.Errorf(codes.ResourceExhausted, "Host is short of resources (%s). Try again later", short)
+		}
+	}
+	if !j.scheduler.admit(newJob) {
+		return nil, status.Error(codes.ResourceExhausted, "Server is at capacity. Try again later or with a higher priority")
+	}
+	if spec.CaptureEnvironment {
+		newJob.snapshot = captureSnapshot(command, spec.Env, j.redactions, newJob.startedAt)
+	}
+	// Shell jobs keep their command line in the event log, since
+	// their command and args only say that a shell ran
+	var createdDetail string
+	if spec.Shell != "" {
+		createdDetail = "shell: " + spec.Shell
+	}
+	newJob.recordEvent(jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED, owner, 0, createdDetail)
+	if len(tokenScopes) > 0 {
+		if newJob.token, err = j.jobTokens.issue(jobId, owner, tokenScopes); err != nil {
+			j.scheduler.release(newJob)
+			subLogger.Error("Error creating job token", "error", err)
+			return nil, status.Error(codes.Internal, "Error starting job")
+		}
+		newJob.tokens = j.jobTokens
+	}
+	// Undoes the above for jobs that never start
+	abandon := func() {
+		j.scheduler.release(newJob)
+		if newJob.token != "" {
+			j.jobTokens.revoke(newJob.token)
+		}
+		if err := newJob.removeScratch(); err != nil {
+			subLogger.Error("Error removing scratch directory", "error", err)
+		}
+	}
+	// Before the hooks, which may fill it
+	if err := newJob.createScratch(); err != nil {
+		abandon()
+		subLogger.Error("Error creating scratch directory", "error", err)
+		return nil, status.Error(codes.Internal, "Error starting job")
+	}
+	if err := j.hooks.beforeAttempt(ctx, newJob, 1); err != nil {
+		abandon()
+		return nil, status.Errorf(codes.FailedPrecondition, "Pre-start hook failed: %s", err)
+	}
+	// Nobody else can see the job yet, but startAttempt
+	// expects the lock to be held
+	newJob.lock.Lock()
+	first, err := newJob.startAttempt()
+	newJob.lock.Unlock()
 	if err != nil {
+		abandon()
 		// Don't leak error details to the caller
 		// log them, but don't return them
 		// (though, the client is ours so maybe it's ok?)
@@ -196,13 +893,19 @@ func (j *Jobby) StartJob(ctx context.Context, req *jobmanagerpb.StartJobRequest)
 		return nil, status.Error(codes.Internal, "Error starting job")
 	}
 
-	j.jobDirectory.Store(jobId, &jobData{
-		Job:   newJob,
-		Owner: j.userGetter.GetUserContext(ctx),
-	})
+	started = true
+	j.jobDirectory.Store(jobId, newJob)
+	j.usage.jobStarted(owner)
+	newJob.persist()
+	go newJob.supervise(first)
+	if spec.Lease != nil {
+		go newJob.watchLease()
+	}
 
 	return &jobmanagerpb.StartJobResponse{
-		JobId: jobId[:],
+		JobId:    jobId[:],
+		Id:       jobId.String(),
+		Warnings: warnings,
 	}, nil
 }
 
@@ -214,7 +917,9 @@ func (j *Jobby) StopJob(ctx context.Context, req *jobmanagerpb.StopJobRequest) (
 		return nil, st.Err()
 	}
 
-	err := jobData.Job.Stop()
+	err := jobData.stop()
+	// A queued job is finished on the spot, without an attempt to supervise
+	jobData.persist()
 	if err != nil {
 		sublogger.Error("Error stopping job", "error", err)
 		return nil, status.Error(codes.Internal, fmt.Errorf("failed to stop job: %w", err).Error())
@@ -227,6 +932,535 @@ func (j *Jobby) StopJob(ctx context.Context, req *jobmanagerpb.StopJobRequest) (
 	}
 }
 
+func (j *Jobby) GetJobHistory(ctx context.Context, req *jobmanagerpb.GetJobHistoryRequest) (*jobmanagerpb.GetJobHistoryResponse, error) {
+	slog.Info("Handling 'GetJobHistory' request", "user", j.userGetter.GetUserContext(ctx), "request", req)
+	jobData, st := j.getJob(ctx, req)
+	if st != nil {
+		return nil, st.Err()
+	}
+
+	history := jobData.history()
+	resp := &jobmanagerpb.GetJobHistoryResponse{
+		Attempts: make([]*jobmanagerpb.Attempt, 0, len(history)),
+	}
+	for _, a := range history {
+		resp.Attempts = append(resp.Attempts, jobData.attemptToProto(a))
+	}
+	return resp, nil
+}
+
+func (j *Jobby) DescribeJob(ctx context.Context, req *jobmanagerpb.DescribeJobRequest) (*jobmanagerpb.DescribeJobResponse, error) {
+	slog.Info("Handling 'DescribeJob' request", "user", j.userGetter.GetUserContext(ctx), "request", req)
+	jobData, st := j.getJob(ctx, req)
+	if st != nil {
+		return nil, st.Err()
+	}
+
+	history := jobData.history()
+	resp := &jobmanagerpb.DescribeJobResponse{
+		Record:   jobData.record(),
+		Status:   jobData.statusResponse(),
+		Attempts: make([]*jobmanagerpb.Attempt, 0, len(history)),
+		Usage:    &jobmanagerpb.JobResourceUsage{},
+	}
+	var cpuTime, wallTime time.Duration
+	for _, a := range history {
+		attempt := jobData.attemptToProto(a)
+		resp.Attempts = append(resp.Attempts, attempt)
+		status := a.job.Status()
+		cpuTime += status.CPUTime
+		wallTime += status.Duration
+		resp.Usage.OutputBytes += attempt.StdoutBytes + attempt.StderrBytes
+	}
+	resp.Usage.CpuTime = durationpb.New(cpuTime)
+	resp.Usage.WallTime = durationpb.New(wallTime)
+	for _, event := range j.events.forJob(jobData.id) {
+		resp.Events = append(resp.Events, event.toProto())
+	}
+	// Best effort, like the attempts' output sizes
+	if outputs, err := history[len(history)-1].job.Outputs(); err == nil {
+		for _, output := range outputs {
+			resp.Outputs = append(resp.Outputs, jobData.outputToProto(output))
+		}
+	} else {
+		slog.Warn("Failed to describe job output", "job-id", jobData.id, "error", err)
+	}
+	return resp, nil
+}
+
+func (d *jobData) outputToProto(output job.OutputDescriptor) *jobmanagerpb.OutputDescriptor {
+	outputType := jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT
+	if output.Kind == job.OutputStderr {
+		outputType = jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR
+	}
+	return &jobmanagerpb.OutputDescriptor{
+		Type:      outputType,
+		Bytes:     uint64(output.Size),
+		Files:     uint32(len(output.Files)),
+		Segmented: output.Segments != nil,
+		Encrypted: d.wrappedKey != nil,
+		Sink:      output.Sink,
+	}
+}
+
+func (j *Jobby) ExportJobs(req *jobmanagerpb.ExportJobsRequest, srv jobmanagerpb.JobManager_ExportJobsServer) error {
+	user := j.userGetter.GetUserContext(srv.Context())
+	slog.Info("Handling 'ExportJobs' request", "user", user, "request", req)
+
+	// Snapshot the records up front so we aren't
+	// ranging over the map while sending
+	records := j.userRecords(user, jobFilter{})
+	for _, record := range records {
+		if err := srv.Send(record); err != nil {
+			// Most likely the caller went away
+			slog.Warn("Failed to send job record", "user", user, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *Jobby) ListJobs(ctx context.Context, req *jobmanagerpb.ListJobsRequest) (*jobmanagerpb.ListJobsResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	slog.Info("Handling 'ListJobs' request", "user", user, "request", req)
+
+	filter, err := newJobFilter(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &jobmanagerpb.ListJobsResponse{Jobs: j.userRecords(user, filter)}, nil
+}
+
+func (j *Jobby) EndSession(ctx context.Context, req *jobmanagerpb.EndSessionRequest) (*jobmanagerpb.EndSessionResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	subLogger := slog.With("user", user, "request", req)
+	subLogger.Info("Handling 'EndSession' request")
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id must not be empty")
+	}
+
+	// Before looking for jobs, so none join once they're found
+	j.sessions.end(user, req.SessionId)
+	resp := &jobmanagerpb.EndSessionResponse{}
+	if !req.StopJobs {
+		return resp, nil
+	}
+	var jobs []*jobData
+	j.jobDirectory.Range(func(_, value any) bool {
+		if data, ok := value.(*jobData); ok && data.Owner == user && data.session == req.SessionId && !data.isFinished() {
+			jobs = append(jobs, data)
+		}
+		return true
+	})
+	slices.SortFunc(jobs, func(a, b *jobData) int {
+		return a.startedAt.Compare(b.startedAt)
+	})
+	failed := false
+	for _, data := range jobs {
+		if err := data.stop(); err != nil {
+			subLogger.Error("Error stopping job", "job-id", data.id, "error", err)
+			failed = true
+			continue
+		}
+		resp.StoppedJobIds = append(resp.StoppedJobIds, data.id.String())
+	}
+	if failed {
+		return nil, status.Error(codes.Internal, "Failed to stop some of the session's jobs")
+	}
+	return resp, nil
+}
+
+func (j *Jobby) StreamServerLogs(req *jobmanagerpb.StreamServerLogsRequest, srv jobmanagerpb.JobManager_StreamServerLogsServer) error {
+	user := j.userGetter.GetUserContext(srv.Context())
+	slog.Info("Handling 'StreamServerLogs' request", "user", user, "request", req)
+	if !slices.Contains(j.admins, user) {
+		return status.Error(codes.PermissionDenied, "Only admins may stream server logs")
+	}
+	if j.serverLogs == nil {
+		return status.Error(codes.FailedPrecondition, "Server logs can't be streamed")
+	}
+	level, ok := logLevelFromProto(req.Level)
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "Unknown log level %v", req.Level)
+	}
+
+	entries, unsubscribe := j.serverLogs.subscribe(level)
+	defer unsubscribe()
+	for {
+		select {
+		case <-srv.Context().Done():
+			return status.FromContextError(srv.Context().Err()).Err()
+		case entry := <-entries:
+			if err := srv.Send(entry.toProto()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (j *Jobby) GetServerInfo(ctx context.Context, req *jobmanagerpb.GetServerInfoRequest) (*jobmanagerpb.GetServerInfoResponse, error) {
+	slog.Info("Handling 'GetServerInfo' request", "user", j.userGetter.GetUserContext(ctx))
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		slog.Error("Error getting hostname", "error", err)
+		return nil, status.Error(codes.Internal, "Error getting server info")
+	}
+	resp := &jobmanagerpb.GetServerInfoResponse{Hostname: hostname}
+	for _, gpu := range j.gpus {
+		resp.Gpus = append(resp.Gpus, &jobmanagerpb.GPU{
+			Index: gpu.Index,
+			Uuid:  gpu.UUID,
+			Model: gpu.Model,
+			BusId: gpu.BusID,
+		})
+	}
+	build := buildinfo.Get()
+	resp.Build = &jobmanagerpb.BuildInfo{
+		Version:   build.Version,
+		Commit:    build.Commit,
+		Modified:  build.Modified,
+		GoVersion: build.GoVersion,
+	}
+	if !build.CommitTime.IsZero() {
+		resp.Build.CommitTime = timestamppb.New(build.CommitTime)
+	}
+	for _, feature := range j.features.All() {
+		resp.Features = append(resp.Features, &jobmanagerpb.FeatureFlag{
+			Name:         string(feature.Flag),
+			Description:  feature.Description,
+			Enabled:      feature.Enabled,
+			Experimental: feature.Experimental,
+			Rpcs:         feature.RPCs,
+		})
+	}
+	return resp, nil
+}
+
+func (j *Jobby) GetUsageSummary(ctx context.Context, req *jobmanagerpb.GetUsageSummaryRequest) (*jobmanagerpb.GetUsageSummaryResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	slog.Info("Handling 'GetUsageSummary' request", "user", user, "request", req)
+
+	windows := slices.Sorted(slices.Values(j.usage.accounting.Windows))
+	if req.Window != nil {
+		window := req.Window.AsDuration()
+		if !slices.Contains(windows, window) {
+			return nil, status.Errorf(codes.InvalidArgument, "Unknown window %s. Windows are %v", window, windows)
+		}
+		windows = []time.Duration{window}
+	}
+	owner := user
+	if j.usage.canViewAll(user) {
+		owner = ""
+	}
+
+	resp := &jobmanagerpb.GetUsageSummaryResponse{}
+	for _, window := range windows {
+		usage := j.usage.summary(window, owner)
+		out := &jobmanagerpb.UsageWindow{Window: durationpb.New(window)}
+		for _, name := range slices.Sorted(maps.Keys(usage)) {
+			u := usage[name]
+			out.Owners = append(out.Owners, &jobmanagerpb.OwnerUsage{
+				Owner:            name,
+				JobsStarted:      u.jobsStarted,
+				AttemptsFinished: u.attemptsFinished,
+				CpuSeconds:       u.cpuTime.Seconds(),
+				WallSeconds:      u.wallTime.Seconds(),
+			})
+		}
+		resp.Windows = append(resp.Windows, out)
+	}
+	return resp, nil
+}
+
+func (j *Jobby) GetJobStats(ctx context.Context, req *jobmanagerpb.GetJobStatsRequest) (*jobmanagerpb.GetJobStatsResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	slog.Info("Handling 'GetJobStats' request", "user", user, "request", req)
+	key := statsKey{owner: user}
+	if req.Command != "" {
+		if len(req.JobId) != 0 || req.Id != "" {
+			return nil, status.Error(codes.InvalidArgument, "Provide either a job id or a command, not both")
+		}
+		key.command = req.Command
+	} else {
+		jobData, st := j.getJob(ctx, req)
+		if st != nil {
+			return nil, st.Err()
+		}
+		key.specHash = jobData.specHash
+	}
+	return statsToProto(j.stats.runs(key)), nil
+}
+
+func (j *Jobby) GetJobEvents(ctx context.Context, req *jobmanagerpb.GetJobEventsRequest) (*jobmanagerpb.GetJobEventsResponse, error) {
+	user := j.userGetter.GetUserContext(ctx)
+	slog.Info("Handling 'GetJobEvents' request", "user", user, "request", req)
+	id, err := jobid.Resolve(req.JobId, req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Must provide valid job id")
+	}
+
+	// Events outlive the job, so they're authorized on their own
+	events := j.events.forJob(id)
+	if len(events) == 0 || events[0].Owner != user {
+		return nil, status.Error(codes.NotFound, "No such job exists")
+	}
+	resp := &jobmanagerpb.GetJobEventsResponse{}
+	for _, event := range events {
+		resp.Events = append(resp.Events, event.toProto())
+	}
+	return resp, nil
+}
+
+func (j *Jobby) ListOutputSegments(ctx context.Context, req *jobmanagerpb.ListOutputSegmentsRequest) (*jobmanagerpb.ListOutputSegmentsResponse, error) {
+	slog.Info("Handling 'ListOutputSegments' request", "user", j.userGetter.GetUserContext(ctx), "request", req)
+	jobData, st := j.getJob(ctx, req)
+	if st != nil {
+		return nil, st.Err()
+	}
+	attempt, ok := jobData.attempt(req.Attempt)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "No such attempt exists")
+	}
+
+	var segments []job.OutputSegment
+	var err error
+	switch req.Type {
+	case jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT:
+		segments, err = attempt.job.StdoutSegments()
+	case jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR:
+		segments, err = attempt.job.StderrSegments()
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Must specify valid output type")
+	}
+	if errors.Is(err, job.ErrNotSegmented) {
+		return nil, status.Error(codes.FailedPrecondition, "Job output isn't segmented")
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, "Error listing output segments")
+	}
+
+	resp := &jobmanagerpb.ListOutputSegmentsResponse{}
+	for _, segment := range segments {
+		// Segments hold output from their start until they end (or now)
+		if req.Until != nil && !segment.Start.Before(req.Until.AsTime()) {
+			continue
+		}
+		if req.Since != nil && !segment.End.IsZero() && !segment.End.After(req.Since.AsTime()) {
+			continue
+		}
+		out := &jobmanagerpb.OutputSegment{
+			Number:    uint32(segment.Number),
+			StartTime: timestamppb.New(segment.Start),
+			SizeBytes: uint64(segment.Size),
+		}
+		if !segment.End.IsZero() {
+			out.EndTime = timestamppb.New(segment.End)
+		}
+		resp.Segments = append(resp.Segments, out)
+	}
+	return resp, nil
+}
+
+func (j *Jobby) GetOutputSegment(req *jobmanagerpb.GetOutputSegmentRequest, srv jobmanagerpb.JobManager_GetOutputSegmentServer) error {
+	user := j.userGetter.GetUserContext(srv.Context())
+	subLogger := slog.With("user", user, "request", req)
+	subLogger.Info("Handling 'GetOutputSegment' request")
+
+	jobData, st := j.getJob(srv.Context(), req)
+	if st != nil {
+		return st.Err()
+	}
+	attempt, ok := jobData.attempt(req.Attempt)
+	if !ok {
+		return status.Error(codes.NotFound, "No such attempt exists")
+	}
+
+	var reader io.ReadCloser
+	var err error
+	switch req.Type {
+	case jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT:
+		reader, err = attempt.job.StdoutSegment(int(req.Segment))
+	case jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR:
+		reader, err = attempt.job.StderrSegment(int(req.Segment))
+	default:
+		return status.Error(codes.InvalidArgument, "Must specify valid output type")
+	}
+	switch {
+	case errors.Is(err, job.ErrNotSegmented):
+		return status.Error(codes.FailedPrecondition, "Job output isn't segmented")
+	case errors.Is(err, job.ErrNoSuchSegment):
+		return status.Error(codes.NotFound, "No such segment exists")
+	case err != nil:
+		return status.Error(codes.Internal, "Error attaching to job output")
+	}
+	return j.streamOutput(srv.Context(), subLogger, user, jobData, reader, batchedPump(j.batching, nil), srv.Send)
+}
+
+func (j *Jobby) GetJobProgress(req *jobmanagerpb.GetJobProgressRequest, srv jobmanagerpb.JobManager_GetJobProgressServer) error {
+	slog.Info("Handling 'GetJobProgress' request", "user", j.userGetter.GetUserContext(srv.Context()), "request", req)
+	jobData, st := j.getJob(srv.Context(), req)
+	if st != nil {
+		return st.Err()
+	}
+
+	var sentAttempt uint32
+	var sentTime time.Time
+	finished := false
+	for {
+		current := jobData.latest()
+		progress, changed := current.job.Progress()
+		var reportTime time.Time
+		if progress != nil {
+			reportTime = progress.Time
+		}
+		if current.number != sentAttempt || !reportTime.Equal(sentTime) {
+			if err := srv.Send(&jobmanagerpb.GetJobProgressResponse{
+				Attempt:  current.number,
+				Progress: progressToProto(progress),
+			}); err != nil {
+				return err
+			}
+			sentAttempt, sentTime = current.number, reportTime
+		}
+		// One last look after the job finishes, for reports made on the way out
+		if finished {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-jobData.finished:
+			finished = true
+		case <-current.job.Done():
+			// Another attempt may follow. Check back until the job is finished
+			select {
+			case <-jobData.finished:
+				finished = true
+			case <-time.After(progressPollInterval):
+			case <-srv.Context().Done():
+				return srv.Context().Err()
+			}
+		case <-srv.Context().Done():
+			return srv.Context().Err()
+		}
+	}
+}
+
+// Look up the GPUs a job asked for by index
+func (j *Jobby) grantedGPUs(indices []uint32) ([]job.GPU, error) {
+	var granted []job.GPU
+	for _, index := range indices {
+		i := slices.IndexFunc(j.gpus, func(gpu job.GPU) bool { return gpu.Index == index })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown GPU %d", index)
+		}
+		if slices.Contains(granted, j.gpus[i]) {
+			return nil, fmt.Errorf("GPU %d requested more than once", index)
+		}
+		granted = append(granted, j.gpus[i])
+	}
+	return granted, nil
+}
+
+// Records of the user's jobs that match the filter, oldest first
+func (j *Jobby) userRecords(user string, filter jobFilter) []*jobmanagerpb.JobRecord {
+	var records []*jobmanagerpb.JobRecord
+	j.jobDirectory.Range(func(_, value any) bool {
+		if data, ok := value.(*jobData); ok && data.Owner == user {
+			if record := data.record(); filter.matches(record) {
+				records = append(records, record)
+			}
+		}
+		return true
+	})
+	slices.SortFunc(records, func(a, b *jobmanagerpb.JobRecord) int {
+		return a.StartTime.AsTime().Compare(b.StartTime.AsTime())
+	})
+	return records
+}
+
+func (d *jobData) attemptToProto(a *attempt) *jobmanagerpb.Attempt {
+	status := a.job.Status()
+	out := &jobmanagerpb.Attempt{
+		Number:        a.number,
+		Status:        *jobStateToStatus(status.CurrentState),
+		ExitCode:      convertExitCode(status.ReturnCode),
+		StartTime:     timestamppb.New(status.StartTime),
+		Duration:      durationpb.New(status.Duration),
+		QuotaExceeded: status.QuotaExceeded,
+		TimedOut:      status.TimedOut,
+		ExitReason:    exitReasonToProto(status.ExitReason),
+		Signal:        signalName(status.Signal),
+		Outcome:       specOutcome(d.spec, status),
+	}
+	if !status.EndTime.IsZero() {
+		out.EndTime = timestamppb.New(status.EndTime)
+	}
+
+	// Output files are best effort. They may have been cleaned up
+	outputs, err := a.job.Outputs()
+	if err != nil {
+		slog.Warn("Failed to describe job output", "job-id", d.id, "error", err)
+		return out
+	}
+	stdout, stderr := outputs[0], outputs[1]
+	out.StdoutBytes = uint64(stdout.Size)
+	out.StderrBytes = uint64(stderr.Size)
+	if tail, err := d.readTail(stderr, historyTailSize); err == nil {
+		out.StderrTail = tail
+	} else {
+		slog.Warn("Failed to read stderr tail", "path", stderr.Path, "error", err)
+	}
+	return out
+}
+
+// Read up to 'n' bytes from the end of a job's output, of the plaintext if
+// it's encrypted. Output split into segments is read across them
+func (d *jobData) readTail(output job.OutputDescriptor, n int64) ([]byte, error) {
+	var tail []byte
+	for i := len(output.Files) - 1; i >= 0 && int64(len(tail)) < n; i-- {
+		fileTail, err := d.readFileTail(output.Files[i].Path, n-int64(len(tail)))
+		if errors.Is(err, fs.ErrNotExist) && i < len(output.Files)-1 {
+			// Discarded since we listed it. So is everything older
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		tail = append(fileTail, tail...)
+	}
+	return tail, nil
+}
+
+// readTail of a single file
+func (d *jobData) readFileTail(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.keys != nil {
+		key, err := d.outputKey()
+		if err != nil {
+			return nil, err
+		}
+		return encryption.ReadTail(f, info.Size(), key, filepath.Base(path), n)
+	}
+
+	offset := max(info.Size()-n, 0)
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return tail, nil
+}
+
 // Try to make loading from the map a little less painful
 func loadJob(m *sync.Map, id uuid.UUID) (*jobData, bool) {
 	if data, exists := m.Load(id); exists {
@@ -241,15 +1475,23 @@ func loadJob(m *sync.Map, id uuid.UUID) (*jobData, bool) {
 
 // Most endpoints need to do this lookup so let's be consistent about it
 func (j *Jobby) getJob(ctx context.Context, getter JobIDGetter) (*jobData, *status.Status) {
-	jobId := getter.GetJobId()
-	var id uuid.UUID
-	var err error
-	if id, err = uuid.FromBytes(jobId); err != nil {
-		slog.Error("Failed to parse job id", "job-id", jobId, "error", err)
+	return j.lookupJob(ctx, getter, false)
+}
+
+// Like getJob, but anyone may read public jobs. Only for PublicMethods
+func (j *Jobby) getReadableJob(ctx context.Context, getter JobIDGetter) (*jobData, *status.Status) {
+	// Public jobs can't be started with publicJobs off, but check anyway
+	return j.lookupJob(ctx, getter, j.publicJobs)
+}
+
+func (j *Jobby) lookupJob(ctx context.Context, getter JobIDGetter, public bool) (*jobData, *status.Status) {
+	id, err := jobid.Resolve(getter.GetJobId(), getter.GetId())
+	if err != nil {
+		slog.Error("Failed to parse job id", "job-id", getter.GetJobId(), "job-id-text", getter.GetId(), "error", err)
 		return nil, status.New(codes.InvalidArgument, "Must provide valid job id")
 	}
 
-	if jobData, ok := loadJob(&j.jobDirectory, id); ok && jobData.Owner == j.userGetter.GetUserContext(ctx) {
+	if jobData, ok := loadJob(&j.jobDirectory, id); ok && (jobData.Owner == j.userGetter.GetUserContext(ctx) || public && jobData.spec.Public) {
 		return jobData, nil
 	} else {
 		// Return the same "not found" error for cases where job is actually not found
@@ -259,7 +1501,3 @@ func (j *Jobby) getJob(ctx context.Context, getter JobIDGetter) (*jobData, *stat
 		return nil, status.New(codes.NotFound, "No such job exists")
 	}
 }
-
-func outFilePath(base string, u uuid.UUID, prefix string) string {
-	return filepath.Join(base, fmt.Sprintf("%s-%s", u.String(), prefix))
-}
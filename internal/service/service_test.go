@@ -3,22 +3,42 @@ package service_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/authinterceptors"
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/encryption"
+	"github.com/gopheryan/jobby/internal/features"
 	"github.com/gopheryan/jobby/internal/service"
 	"github.com/gopheryan/jobby/internal/testutils"
+	"github.com/gopheryan/jobby/job"
 	"github.com/gopheryan/jobby/jobmanagerpb"
+	jobmanagerv2 "github.com/gopheryan/jobby/jobmanagerpb/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-const echoPathRelative = "../../testdata/testprograms/echo"
+const testJobPath = "../../testdata/testprograms/testjob"
 
 type mockUserGetter struct {
 	user string
@@ -37,59 +57,2345 @@ func TestUnaryCalls(t *testing.T) {
 
 	t.Run("start-stop-status", func(tt *testing.T) {
 		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
-			Command: echoPathRelative,
-			Args:    []string{"echo", "5"},
+			Command: testJobPath,
+			Args:    []string{"testjob", "5"},
 		})
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 		require.NotNil(t, resp.JobId)
 
+		// Lines are written half a second apart, so it's still running
+		running, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{
+			JobId: resp.JobId,
+		})
+		require.NoError(t, err)
+		assert.NotZero(t, running.Pid)
+		require.Len(t, running.Processes, 1)
+		assert.Equal(t, running.Pid, running.Processes[0].Pid)
+		assert.Equal(t, int32(os.Getpid()), running.Processes[0].Ppid)
+		assert.Equal(t, "testjob", running.Processes[0].Command)
+
+		stopResp, err := jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{
+			JobId: resp.JobId,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, stopResp)
+
+		statusResp, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{
+			JobId: resp.JobId,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, statusResp)
+		require.Equal(t, jobmanagerpb.Status_STATUS_STOPPED, statusResp.CurrentStatus)
+		assert.Equal(t, running.Pid, statusResp.Pid)
+		assert.Empty(t, statusResp.Processes)
+	})
+
+	t.Run("text-job-id", func(tt *testing.T) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command: testJobPath,
+			Args:    []string{"testjob", "1"},
+		})
+		require.NoError(tt, err)
+		id, err := uuid.FromBytes(resp.JobId)
+		require.NoError(tt, err)
+		assert.Equal(tt, id.String(), resp.Id)
+
+		// Either form, or both, identify the job
+		for _, req := range []*jobmanagerpb.GetStatusRequest{
+			{Id: resp.Id},
+			{JobId: resp.JobId, Id: resp.Id},
+		} {
+			_, err = jobService.GetStatus(ctx, req)
+			assert.NoError(tt, err)
+		}
+
+		for _, req := range []*jobmanagerpb.GetStatusRequest{
+			{},
+			{Id: "not-a-job"},
+			{JobId: resp.JobId, Id: uuid.NewString()},
+		} {
+			_, err = jobService.GetStatus(ctx, req)
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+		}
+	})
+
+	t.Run("invalid-user", func(tt *testing.T) {
+		// Create a job
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command: testJobPath,
+			Args:    []string{"testjob", "5"},
+		})
+		require.NoError(tt, err)
+		require.NotNil(tt, resp)
+		require.NotNil(tt, resp.JobId)
+
+		// Change the user
+		mockUserGetter.user = "anotheruser"
+
+		// Stopping the job should fail with "not found" since we are no longer
+		// the right user
 		stopResp, err := jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{
 			JobId: resp.JobId,
 		})
-		require.NoError(t, err)
-		require.NotNil(t, stopResp)
+		require.Error(tt, err)
+		stat, ok := status.FromError(err)
+		require.True(tt, ok)
+		require.Equal(tt, codes.NotFound, stat.Code())
+		require.Nil(t, stopResp)
+	})
+
+}
+
+// Failed attempts should be retried and each one
+// should show up in the job's history
+func TestJobHistory(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+
+	// Exits immediately with a non-zero code
+	resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command:     testJobPath,
+		Args:        []string{"testjob", "-exit", "1"},
+		MaxAttempts: 3,
+	})
+	require.NoError(t, err)
+
+	var history *jobmanagerpb.GetJobHistoryResponse
+	require.Eventually(t, func() bool {
+		history, err = jobService.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: resp.JobId})
+		require.NoError(t, err)
+		return len(history.Attempts) == 3 && history.Attempts[2].EndTime != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for idx, attempt := range history.Attempts {
+		assert.Equal(t, uint32(idx+1), attempt.Number)
+		assert.Equal(t, jobmanagerpb.Status_STATUS_COMPLETE, attempt.Status)
+		require.NotNil(t, attempt.ExitCode)
+		assert.NotZero(t, *attempt.ExitCode)
+		assert.False(t, attempt.EndTime.AsTime().Before(attempt.StartTime.AsTime()))
+		assert.Positive(t, attempt.Duration.AsDuration())
+	}
+
+	// Successful jobs only run once
+	resp, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command:     testJobPath,
+		Args:        []string{"testjob", "1"},
+		MaxAttempts: 3,
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		status, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+		require.NoError(t, err)
+		return status.CurrentStatus == jobmanagerpb.Status_STATUS_COMPLETE
+	}, 5*time.Second, 10*time.Millisecond)
+
+	history, err = jobService.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	require.Len(t, history.Attempts, 1)
+	assert.Equal(t, uint64(len("stdout 1\n")), history.Attempts[0].StdoutBytes)
+	assert.Equal(t, "stderr 1\n", string(history.Attempts[0].StderrTail))
+
+	_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command:     testJobPath,
+		Args:        []string{"testjob", "1"},
+		MaxAttempts: 1000,
+	})
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+// Exit code rules decide the outcome of each attempt, and whether it's retried
+func TestExitCodeRules(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	rules := []*jobmanagerpb.ExitCodeRule{
+		{Codes: []int32{2, 3}, Outcome: jobmanagerpb.Outcome_OUTCOME_WARNING},
+		{Codes: []int32{4}, Outcome: jobmanagerpb.Outcome_OUTCOME_FAILURE},
+		{Codes: []int32{5}, Outcome: jobmanagerpb.Outcome_OUTCOME_INFRASTRUCTURE_FAILURE},
+		// Shadowed by the first rule
+		{Codes: []int32{3}, Outcome: jobmanagerpb.Outcome_OUTCOME_FAILURE},
+	}
+
+	for _, tc := range []struct {
+		code     int
+		outcome  jobmanagerpb.Outcome
+		attempts int
+		event    jobmanagerpb.JobEventType
+	}{
+		{0, jobmanagerpb.Outcome_OUTCOME_SUCCESS, 1, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_EXITED},
+		{3, jobmanagerpb.Outcome_OUTCOME_WARNING, 1, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_EXITED},
+		{4, jobmanagerpb.Outcome_OUTCOME_FAILURE, 1, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_ATTEMPT_FAILED},
+		{5, jobmanagerpb.Outcome_OUTCOME_INFRASTRUCTURE_FAILURE, 2, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_ATTEMPT_FAILED},
+		// Not covered by a rule
+		{1, jobmanagerpb.Outcome_OUTCOME_RETRYABLE, 2, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_ATTEMPT_FAILED},
+	} {
+		t.Run(fmt.Sprint(tc.code), func(tt *testing.T) {
+			resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+				Spec: &jobmanagerpb.JobSpec{
+					Command:       "/bin/sh",
+					Args:          []string{"sh", "-c", fmt.Sprintf("exit %d", tc.code)},
+					MaxAttempts:   2,
+					ExitCodeRules: rules,
+				},
+			})
+			require.NoError(tt, err)
+			statusResp, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+			require.NoError(tt, err)
+			assert.Equal(tt, tc.outcome, statusResp.Outcome)
+
+			history, err := jobService.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: resp.JobId})
+			require.NoError(tt, err)
+			require.Len(tt, history.Attempts, tc.attempts)
+			for _, attempt := range history.Attempts {
+				assert.Equal(tt, tc.outcome, attempt.Outcome)
+			}
+			events, err := jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: resp.JobId})
+			require.NoError(tt, err)
+			assert.Equal(tt, tc.event, events.Events[len(events.Events)-1].Type)
+		})
+	}
+}
+
+// Expired jobs and their output are removed by the garbage collector
+func TestRetention(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, outDir,
+		service.WithRetention(service.RetentionLimits{
+			DefaultTTL: time.Hour,
+			MaxTTL:     24 * time.Hour,
+		}),
+	)
+
+	t.Run("limits", func(tt *testing.T) {
+		for _, policy := range []*jobmanagerpb.RetentionPolicy{
+			{Policy: &jobmanagerpb.RetentionPolicy_KeepForever{KeepForever: true}},
+			{Policy: &jobmanagerpb.RetentionPolicy_Ttl{Ttl: durationpb.New(48 * time.Hour)}},
+			{Policy: &jobmanagerpb.RetentionPolicy_Ttl{Ttl: durationpb.New(-time.Second)}},
+		} {
+			_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+				Command:   testJobPath,
+				Args:      []string{"testjob", "1"},
+				Retention: policy,
+			})
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+		}
+	})
+
+	t.Run("collect", func(tt *testing.T) {
+		shortLived, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command:   testJobPath,
+			Args:      []string{"testjob", "1"},
+			Retention: &jobmanagerpb.RetentionPolicy{Policy: &jobmanagerpb.RetentionPolicy_Ttl{Ttl: durationpb.New(time.Minute)}},
+		})
+		require.NoError(tt, err)
+		// Uses the default TTL. The first job may still be running
+		longLived, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command: testJobPath,
+			Args:    []string{"testjob", "1"},
+			Force:   true,
+		})
+		require.NoError(tt, err)
+
+		for _, id := range [][]byte{shortLived.JobId, longLived.JobId} {
+			require.Eventually(tt, func() bool {
+				history, err := jobService.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: id})
+				require.NoError(tt, err)
+				return history.Attempts[0].EndTime != nil
+			}, 5*time.Second, 10*time.Millisecond)
+		}
+
+		// Nothing has expired yet
+		assert.Zero(tt, jobService.CollectGarbage(time.Now()))
+
+		assert.Equal(tt, 1, jobService.CollectGarbage(time.Now().Add(2*time.Minute)))
+		_, err = jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: shortLived.JobId})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		_, err = jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: longLived.JobId})
+		assert.NoError(tt, err)
+
+		// Only the long lived job's output remains
+		entries, err := os.ReadDir(outDir)
+		require.NoError(tt, err)
+		id, err := uuid.FromBytes(longLived.JobId)
+		require.NoError(tt, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.ElementsMatch(tt, []string{id.String() + "-1-stdout", id.String() + "-1-stderr"}, names)
+	})
+
+	t.Run("collector", func(tt *testing.T) {
+		fake := clock.NewFake(time.Now())
+		collected := service.NewJobService(&mockUserGetter{user: "someuser"}, tt.TempDir(),
+			service.WithRetention(service.RetentionLimits{DefaultTTL: time.Hour}),
+			service.WithClock(fake),
+		)
+		resp, err := collected.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command: testJobPath,
+			Args:    []string{"testjob", "1"},
+		})
+		require.NoError(tt, err)
+		_, err = collected.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+
+		gcCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go collected.RunGarbageCollector(gcCtx, time.Minute)
+		require.NoError(tt, fake.WaitFor(ctx, 1))
+		// Past the job's TTL
+		fake.Advance(time.Hour)
+		require.Eventually(tt, func() bool {
+			_, err := collected.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+			return status.Code(err) == codes.NotFound
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+// Deleted jobs are hidden until restored, or purged once the grace period is up
+func TestDeleteJob(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	users := &mockUserGetter{user: "someuser"}
+	fake := clock.NewFake(time.Now())
+	jobService := service.NewJobService(users, outDir,
+		service.WithRetention(service.RetentionLimits{
+			AllowKeepForever:  true,
+			DeleteGracePeriod: time.Hour,
+		}),
+		service.WithClock(fake),
+	)
+	startFinished := func(tt *testing.T) []byte {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command: testJobPath,
+			Args:    []string{"testjob", "1"},
+			Force:   true,
+		})
+		require.NoError(tt, err)
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+	listed := func(tt *testing.T, id []byte) bool {
+		list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+		require.NoError(tt, err)
+		return slices.ContainsFunc(list.Jobs, func(record *jobmanagerpb.JobRecord) bool {
+			return bytes.Equal(record.JobId, id)
+		})
+	}
+	outputFiles := func(tt *testing.T, id []byte) int {
+		entries, err := os.ReadDir(outDir)
+		require.NoError(tt, err)
+		parsed, err := uuid.FromBytes(id)
+		require.NoError(tt, err)
+		count := 0
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), parsed.String()) {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("running", func(tt *testing.T) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command: testJobPath,
+			Args:    []string{"testjob", "5"},
+		})
+		require.NoError(tt, err)
+		_, err = jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{JobId: resp.JobId})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+	})
+
+	t.Run("restore", func(tt *testing.T) {
+		id := startFinished(tt)
+		resp, err := jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.WithinDuration(tt, time.Now().Add(time.Hour), resp.RestorableUntil.AsTime(), time.Minute)
+
+		_, err = jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		assert.False(tt, listed(tt, id))
+		_, err = jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{JobId: id})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		// Output is kept for the grace period
+		assert.Positive(tt, outputFiles(tt, id))
+		assert.Zero(tt, jobService.CollectGarbage(fake.Now()))
+
+		// Only the owner may restore it
+		users.user = "someoneelse"
+		_, err = jobService.RestoreJob(ctx, &jobmanagerpb.RestoreJobRequest{JobId: id})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		users.user = "someuser"
+
+		_, err = jobService.RestoreJob(ctx, &jobmanagerpb.RestoreJobRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.True(tt, listed(tt, id))
+		_, err = jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+		assert.NoError(tt, err)
+		_, err = jobService.RestoreJob(ctx, &jobmanagerpb.RestoreJobRequest{JobId: id})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+
+		events, err := jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: id})
+		require.NoError(tt, err)
+		last := events.Events[len(events.Events)-2:]
+		assert.Equal(tt, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_DELETED, last[0].Type)
+		assert.Equal(tt, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_RESTORED, last[1].Type)
+	})
+
+	t.Run("grace period", func(tt *testing.T) {
+		id := startFinished(tt)
+		resp, err := jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.WithinDuration(tt, fake.Now().Add(time.Hour), resp.RestorableUntil.AsTime(), 0)
+
+		// Can't be restored once the grace period is up, even before it's purged
+		fake.Advance(time.Hour)
+		_, err = jobService.RestoreJob(ctx, &jobmanagerpb.RestoreJobRequest{JobId: id})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		assert.Positive(tt, outputFiles(tt, id))
+
+		assert.Equal(tt, 1, jobService.CollectGarbage(fake.Now()))
+		assert.Zero(tt, outputFiles(tt, id))
+	})
+
+	t.Run("force", func(tt *testing.T) {
+		id := startFinished(tt)
+		resp, err := jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{JobId: id, Force: true})
+		require.NoError(tt, err)
+		assert.Nil(tt, resp.RestorableUntil)
+		assert.Zero(tt, outputFiles(tt, id))
+		_, err = jobService.RestoreJob(ctx, &jobmanagerpb.RestoreJobRequest{JobId: id})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+
+		// Purges jobs that were already deleted
+		id = startFinished(tt)
+		_, err = jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{JobId: id})
+		require.NoError(tt, err)
+		_, err = jobService.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{JobId: id, Force: true})
+		require.NoError(tt, err)
+		assert.Zero(tt, outputFiles(tt, id))
+		fake.Advance(2 * time.Hour)
+		assert.Zero(tt, jobService.CollectGarbage(fake.Now()))
+	})
+}
+
+// Streams attached to a job end as soon as it's stopped or deleted,
+// with a status saying why
+func TestDetachReaders(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(),
+		service.WithRetention(service.RetentionLimits{DefaultTTL: time.Minute}),
+		// Keeps streams of finished jobs going for a while
+		service.WithOutputRateLimits(service.OutputRateLimits{PerStream: 64}),
+	)
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	start := func(tt *testing.T, script string) []byte {
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/sh", Args: []string{"sh", "-c", script}},
+		})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+	attach := func(tt *testing.T, id []byte) jobmanagerpb.JobManager_GetJobOutputClient {
+		outputClient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+			JobId:         id,
+			Type:          jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+			BatchMaxBytes: 16,
+		})
+		require.NoError(tt, err)
+		// Attached once the first output arrives
+		_, err = outputClient.Recv()
+		require.NoError(tt, err)
+		return outputClient
+	}
+	drain := func(outputClient jobmanagerpb.JobManager_GetJobOutputClient) error {
+		for {
+			if _, err := outputClient.Recv(); err != nil {
+				return err
+			}
+		}
+	}
+
+	t.Run("stopped", func(tt *testing.T) {
+		id := start(tt, "echo started; sleep 30")
+		outputClients := []jobmanagerpb.JobManager_GetJobOutputClient{attach(tt, id), attach(tt, id)}
+
+		_, err := jobClient.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: id})
+		require.NoError(tt, err)
+		for _, outputClient := range outputClients {
+			err := drain(outputClient)
+			assert.Equal(tt, codes.Aborted, status.Code(err))
+			assert.Equal(tt, "Job was stopped", status.Convert(err).Message())
+		}
+
+		// Streams attached afterwards read what's left as usual
+		outputClient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+			JobId: id,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+		})
+		require.NoError(tt, err)
+		assert.ErrorIs(tt, drain(outputClient), io.EOF)
+	})
+
+	t.Run("deleted", func(tt *testing.T) {
+		id := start(tt, "seq 1000")
+		require.Eventually(tt, func() bool {
+			resp, err := jobClient.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+			require.NoError(tt, err)
+			return resp.CurrentStatus == jobmanagerpb.Status_STATUS_COMPLETE
+		}, 5*time.Second, 10*time.Millisecond)
+		outputClient := attach(tt, id)
+
+		require.Positive(tt, jobService.CollectGarbage(time.Now().Add(2*time.Minute)))
+		err := drain(outputClient)
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		assert.Equal(tt, "Job was deleted", status.Convert(err).Message())
+	})
+}
+
+// Jobs with an output window only keep their latest output, which
+// is all their owner's quota is charged for
+func TestWaitJob(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	start := func(tt *testing.T, script string) []byte {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/sh", Args: []string{"sh", "-c", script}},
+		})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+
+	t.Run("exit code", func(tt *testing.T) {
+		id := start(tt, "sleep 0.2; exit 3")
+		resp, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, resp.CurrentStatus)
+		require.NotNil(tt, resp.ExitCode)
+		assert.Equal(tt, int32(3), *resp.ExitCode)
+	})
+
+	t.Run("deadline", func(tt *testing.T) {
+		id := start(tt, "sleep 5")
+		waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err := jobService.WaitJob(waitCtx, &jobmanagerpb.WaitJobRequest{JobId: id})
+		assert.Equal(tt, codes.DeadlineExceeded, status.Code(err))
+
+		// Stopping the job finishes it
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: id})
+		require.NoError(tt, err)
+		resp, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_STOPPED, resp.CurrentStatus)
+	})
+
+	t.Run("not found", func(tt *testing.T) {
+		_, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{Id: uuid.NewString()})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestPublicJobs(t *testing.T) {
+	ctx := context.Background()
+	userGetter := &mockUserGetter{user: "someuser"}
+	jobService := service.NewJobService(userGetter, t.TempDir(), service.WithPublicJobs())
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	start := func(tt *testing.T, public bool) []byte {
+		userGetter.user = "someuser"
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/echo", Args: []string{"testjob", "build log"}, Public: public},
+		})
+		require.NoError(tt, err)
+		_, err = jobClient.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+	readOutput := func(id []byte) ([]byte, error) {
+		outputClient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+			JobId: id,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var output []byte
+		for {
+			resp, err := outputClient.Recv()
+			if errors.Is(err, io.EOF) {
+				return output, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, resp.Data...)
+		}
+	}
+
+	t.Run("public", func(tt *testing.T) {
+		id := start(tt, true)
+		for _, user := range []string{"someotheruser", authinterceptors.AnonymousUser} {
+			userGetter.user = user
+			resp, err := jobClient.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+			require.NoError(tt, err)
+			assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, resp.CurrentStatus)
+			output, err := readOutput(id)
+			require.NoError(tt, err)
+			assert.Equal(tt, "build log\n", string(output))
+
+			// Read only
+			_, err = jobClient.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: id})
+			assert.Equal(tt, codes.NotFound, status.Code(err))
+		}
+	})
+
+	t.Run("private", func(tt *testing.T) {
+		id := start(tt, false)
+		userGetter.user = "someotheruser"
+		_, err := jobClient.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		_, err = readOutput(id)
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("not allowed", func(tt *testing.T) {
+		jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+		_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/true", Public: true},
+		})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestJobProgress(t *testing.T) {
+	ctx := context.Background()
+	events, err := service.OpenEventLog(filepath.Join(t.TempDir(), "events.jsonl"), time.Hour)
+	require.NoError(t, err)
+	defer events.Close()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(), service.WithEventLog(events))
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{
+			Command: "/bin/sh",
+			Args: []string{"sh", "-c", `sleep 0.2; echo "JOBBY_PROGRESS: 5% warming up"; sleep 0.2
+echo "JOBBY_PROGRESS: 50% half"; sleep 0.2; echo "JOBBY_PROGRESS: 100% done"`},
+			TrackProgress: true,
+		},
+	})
+	require.NoError(t, err)
+
+	// Streams reports until the job is finished
+	stream, err := jobClient.GetJobProgress(ctx, &jobmanagerpb.GetJobProgressRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	var percents []float64
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+		assert.Equal(t, uint32(1), msg.Attempt)
+		if msg.Progress != nil {
+			percents = append(percents, msg.Progress.Percent)
+		}
+	}
+	assert.Equal(t, []float64{5, 50, 100}, percents)
+
+	statusResp, err := jobClient.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	require.NotNil(t, statusResp.Progress)
+	assert.Equal(t, 100.0, statusResp.Progress.Percent)
+	assert.Equal(t, "done", statusResp.Progress.Message)
+
+	// Only steps of 10% are recorded
+	eventsResp, err := jobClient.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	var details []string
+	for _, event := range eventsResp.Events {
+		if event.Type == jobmanagerpb.JobEventType_JOB_EVENT_TYPE_PROGRESS {
+			details = append(details, event.Detail)
+		}
+	}
+	assert.Equal(t, []string{"50% half", "100% done"}, details)
+}
+
+func TestOutputWindow(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, outDir,
+		service.WithRetention(service.RetentionLimits{DefaultTTL: time.Minute}),
+		// Less than the job writes, but more than its window
+		service.WithQuotaLimits(service.QuotaLimits{PerUserBytes: 6144}),
+	)
+	// 16 lines of 512 bytes. The pauses keep them separate writes
+	resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{
+			Command:           "/bin/sh",
+			Args:              []string{"sh", "-c", `for i in $(seq 1 16); do printf "%0511d\n" $i; sleep 0.02; done`},
+			OutputWindowBytes: 4096,
+		},
+	})
+	require.NoError(t, err)
+
+	var attempt *jobmanagerpb.Attempt
+	require.Eventually(t, func() bool {
+		history, err := jobService.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: resp.JobId})
+		require.NoError(t, err)
+		attempt = history.Attempts[0]
+		return attempt.EndTime != nil
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.False(t, attempt.QuotaExceeded)
+	require.NotNil(t, attempt.ExitCode)
+	assert.Equal(t, int32(0), *attempt.ExitCode)
+	assert.Equal(t, uint64(4096), attempt.StdoutBytes)
+
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	outputClient, err := jobmanagerpb.NewJobManagerClient(srv.Conn()).GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+		JobId: resp.JobId,
+		Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+	})
+	require.NoError(t, err)
+	var output bytes.Buffer
+	for {
+		msg, err := outputClient.Recv()
+		if err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+		output.Write(msg.Data)
+	}
+	var expected strings.Builder
+	for i := 9; i <= 16; i++ {
+		fmt.Fprintf(&expected, "%0511d\n", i)
+	}
+	assert.Equal(t, expected.String(), output.String())
+
+	// The segments still around go with the job
+	assert.Equal(t, 1, jobService.CollectGarbage(time.Now().Add(2*time.Minute)))
+	entries, err := os.ReadDir(outDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// no_follow streams end at the end of the output so far, even while the job runs
+func TestNoFollow(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{
+			Command: "/bin/sh",
+			Args:    []string{"sh", "-c", "echo hello; printf partial; sleep 30"},
+		},
+	})
+	require.NoError(t, err)
+	defer jobClient.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+
+	readAll := func(req *jobmanagerpb.GetJobOutputRequest) string {
+		req.JobId = resp.JobId
+		req.NoFollow = true
+		outputClient, err := jobClient.GetJobOutput(ctx, req)
+		require.NoError(t, err)
+		var output bytes.Buffer
+		for {
+			msg, err := outputClient.Recv()
+			if err != nil {
+				require.ErrorIs(t, err, io.EOF)
+				return output.String()
+			}
+			output.Write(msg.Data)
+		}
+	}
+
+	// Returns rather than waiting on the job, so it's fine to poll
+	require.Eventually(t, func() bool {
+		return readAll(&jobmanagerpb.GetJobOutputRequest{Type: jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT}) == "hello\npartial"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	t.Run("lines", func(tt *testing.T) {
+		// Partial lines are flushed at the end of the stream, same as when a job exits
+		assert.Equal(tt, "hello\npartial", readAll(&jobmanagerpb.GetJobOutputRequest{
+			Type: jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+			Mode: jobmanagerpb.StreamMode_STREAM_MODE_LINES,
+		}))
+	})
+
+	t.Run("stderr", func(tt *testing.T) {
+		assert.Empty(tt, readAll(&jobmanagerpb.GetJobOutputRequest{Type: jobmanagerpb.OutputType_OUTPUT_TYPE_STDERR}))
+	})
+
+	statusResp, err := jobClient.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	assert.Equal(t, jobmanagerpb.Status_STATUS_RUNNING, statusResp.CurrentStatus)
+}
+
+func TestOutputEnd(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	// Output and the message that ended the stream, if any
+	readAll := func(req *jobmanagerpb.GetJobOutputRequest) (string, *jobmanagerpb.OutputEnd, error) {
+		req.Type = jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT
+		outputClient, err := jobClient.GetJobOutput(ctx, req)
+		require.NoError(t, err)
+		var output bytes.Buffer
+		var end *jobmanagerpb.OutputEnd
+		for {
+			msg, err := outputClient.Recv()
+			if errors.Is(err, io.EOF) {
+				return output.String(), end, nil
+			} else if err != nil {
+				return output.String(), end, err
+			}
+			require.Nil(t, end, "data after the end of the stream")
+			output.Write(msg.Data)
+			end = msg.End
+		}
+	}
+
+	resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{
+			Command: "/bin/sh",
+			Args:    []string{"sh", "-c", "echo hello; echo again; echo again"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = jobClient.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+
+	t.Run("raw", func(tt *testing.T) {
+		output, end, err := readAll(&jobmanagerpb.GetJobOutputRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		require.NotNil(tt, end)
+		sum := sha256.Sum256([]byte(output))
+		assert.Equal(tt, "hello\nagain\nagain\n", output)
+		assert.EqualValues(tt, len(output), end.TotalBytes)
+		assert.Equal(tt, sum[:], end.Sha256)
+	})
+
+	t.Run("transformed", func(tt *testing.T) {
+		// Covers what was sent, not what the job wrote
+		output, end, err := readAll(&jobmanagerpb.GetJobOutputRequest{JobId: resp.JobId, CollapseRepeatedLines: true})
+		require.NoError(tt, err)
+		require.NotNil(tt, end)
+		sum := sha256.Sum256([]byte(output))
+		assert.EqualValues(tt, len(output), end.TotalBytes)
+		assert.Equal(tt, sum[:], end.Sha256)
+	})
+
+	t.Run("stopped", func(tt *testing.T) {
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/sh", Args: []string{"sh", "-c", "echo hello; sleep 30"}},
+		})
+		require.NoError(tt, err)
+		time.AfterFunc(100*time.Millisecond, func() {
+			jobClient.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		})
+		// Ending early isn't the end of the output
+		_, end, err := readAll(&jobmanagerpb.GetJobOutputRequest{JobId: resp.JobId})
+		assert.Equal(tt, codes.Aborted, status.Code(err))
+		assert.Nil(tt, end)
+	})
+}
+
+func TestOutputRanges(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	readRange := func(id []byte, byteRange *jobmanagerpb.ByteRange) (string, error) {
+		outputClient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+			JobId: id,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+			Range: byteRange,
+		})
+		require.NoError(t, err)
+		var output bytes.Buffer
+		for {
+			msg, err := outputClient.Recv()
+			if errors.Is(err, io.EOF) {
+				return output.String(), nil
+			} else if err != nil {
+				return output.String(), err
+			}
+			output.Write(msg.Data)
+		}
+	}
+	const output = "0123456789abcdefghij0123456789abcdefghij"
+
+	for name, spec := range map[string]*jobmanagerpb.JobSpec{
+		"plain": {Command: "/bin/printf", Args: []string{"printf", output}},
+		// Skipped through rather than seeked in
+		"segmented": {
+			Command:        "/bin/printf",
+			Args:           []string{"printf", output},
+			OutputSegments: &jobmanagerpb.SegmentPolicy{MaxBytes: 4096},
+		},
+	} {
+		t.Run(name, func(tt *testing.T) {
+			resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+			require.NoError(tt, err)
+			_, err = jobClient.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+			require.NoError(tt, err)
+
+			got, err := readRange(resp.JobId, &jobmanagerpb.ByteRange{Start: 5, End: 25})
+			require.NoError(tt, err)
+			assert.Equal(tt, output[5:25], got)
+			got, err = readRange(resp.JobId, &jobmanagerpb.ByteRange{Start: 30})
+			require.NoError(tt, err)
+			assert.Equal(tt, output[30:], got)
+			got, err = readRange(resp.JobId, &jobmanagerpb.ByteRange{Start: 40, End: 40})
+			require.NoError(tt, err)
+			assert.Empty(tt, got)
+
+			_, err = readRange(resp.JobId, &jobmanagerpb.ByteRange{Start: 30, End: 41})
+			assert.Equal(tt, codes.OutOfRange, status.Code(err))
+			_, err = readRange(resp.JobId, &jobmanagerpb.ByteRange{Start: 30, End: 20})
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+		})
+	}
+
+	t.Run("running", func(tt *testing.T) {
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: "/bin/sleep", Args: []string{"sleep", "30"}},
+		})
+		require.NoError(tt, err)
+		defer jobClient.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		_, err = readRange(resp.JobId, &jobmanagerpb.ByteRange{})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestOutputSegments(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	// 8 lines of 1024 bytes, 4 to a segment. The pauses keep them separate writes
+	resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{
+			Command:        "/bin/sh",
+			Args:           []string{"sh", "-c", `for i in $(seq 1 8); do printf "%01023d\n" $i; sleep 0.02; done`},
+			OutputSegments: &jobmanagerpb.SegmentPolicy{MaxBytes: 4096},
+		},
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		statusResp, err := jobClient.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+		require.NoError(t, err)
+		return statusResp.CurrentStatus == jobmanagerpb.Status_STATUS_COMPLETE
+	}, 10*time.Second, 10*time.Millisecond)
+
+	list, err := jobClient.ListOutputSegments(ctx, &jobmanagerpb.ListOutputSegmentsRequest{
+		JobId: resp.JobId,
+		Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Segments, 2)
+	for i, segment := range list.Segments {
+		assert.Equal(t, uint32(i+1), segment.Number)
+		assert.Equal(t, uint64(4096), segment.SizeBytes)
+		require.NotNil(t, segment.EndTime)
+	}
+	first, second := list.Segments[0], list.Segments[1]
+
+	t.Run("time range", func(tt *testing.T) {
+		// Each segment ends where the next starts
+		list, err := jobClient.ListOutputSegments(ctx, &jobmanagerpb.ListOutputSegmentsRequest{
+			JobId: resp.JobId,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+			Since: first.EndTime,
+		})
+		require.NoError(tt, err)
+		require.Len(tt, list.Segments, 1)
+		assert.Equal(tt, uint32(2), list.Segments[0].Number)
+
+		list, err = jobClient.ListOutputSegments(ctx, &jobmanagerpb.ListOutputSegmentsRequest{
+			JobId: resp.JobId,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+			Until: second.StartTime,
+		})
+		require.NoError(tt, err)
+		require.Len(tt, list.Segments, 1)
+		assert.Equal(tt, uint32(1), list.Segments[0].Number)
+	})
+
+	t.Run("fetch", func(tt *testing.T) {
+		stream, err := jobClient.GetOutputSegment(ctx, &jobmanagerpb.GetOutputSegmentRequest{
+			JobId:   resp.JobId,
+			Type:    jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+			Segment: 2,
+		})
+		require.NoError(tt, err)
+		var output bytes.Buffer
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				require.ErrorIs(tt, err, io.EOF)
+				break
+			}
+			output.Write(msg.Data)
+		}
+		var expected strings.Builder
+		for i := 5; i <= 8; i++ {
+			fmt.Fprintf(&expected, "%01023d\n", i)
+		}
+		assert.Equal(tt, expected.String(), output.String())
+
+		stream, err = jobClient.GetOutputSegment(ctx, &jobmanagerpb.GetOutputSegmentRequest{
+			JobId:   resp.JobId,
+			Type:    jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+			Segment: 3,
+		})
+		require.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("not segmented", func(tt *testing.T) {
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: testJobPath, Args: []string{"testjob", "1"}},
+		})
+		require.NoError(tt, err)
+		_, err = jobClient.ListOutputSegments(ctx, &jobmanagerpb.ListOutputSegmentsRequest{
+			JobId: resp.JobId,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+		})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+// Output past the owner's quota stops the job, and no new jobs
+// can start until old output is cleaned up
+func TestQuota(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(),
+		service.WithRetention(service.RetentionLimits{DefaultTTL: time.Minute}),
+		service.WithQuotaLimits(service.QuotaLimits{PerUserBytes: int64(len("stdout 1\nstderr 1\n"))}),
+	)
+
+	resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command:     testJobPath,
+		Args:        []string{"testjob", "5"},
+		MaxAttempts: 3,
+	})
+	require.NoError(t, err)
+
+	var statusResp *jobmanagerpb.GetStatusResponse
+	require.Eventually(t, func() bool {
+		statusResp, err = jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+		require.NoError(t, err)
+		return statusResp.CurrentStatus == jobmanagerpb.Status_STATUS_COMPLETE
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, statusResp.QuotaExceeded)
+	// Killed, not retried
+	assert.Nil(t, statusResp.ExitCode)
+	history, err := jobService.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	assert.Len(t, history.Attempts, 1)
+
+	_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command: testJobPath,
+		Args:    []string{"testjob", "1"},
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Deleting the output frees up the quota
+	require.Eventually(t, func() bool {
+		return jobService.CollectGarbage(time.Now().Add(time.Hour)) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command: testJobPath,
+		Args:    []string{"testjob", "1"},
+	})
+	assert.NoError(t, err)
+}
+
+// Output is encrypted on disk but reads back as plaintext
+func TestOutputEncryption(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	keys, err := encryption.NewLocalWrapper(bytes.Repeat([]byte{1}, encryption.KeySize))
+	require.NoError(t, err)
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, outDir,
+		service.WithOutputEncryption(keys),
+	)
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command: testJobPath,
+		Args:    []string{"testjob", "3"},
+	})
+	require.NoError(t, err)
+
+	outputClient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+		JobId: resp.JobId,
+		Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+	})
+	require.NoError(t, err)
+	var fullOutput bytes.Buffer
+	var msg *jobmanagerpb.GetJobOutputResponse
+	for err == nil {
+		msg, err = outputClient.Recv()
+		if err == nil {
+			_, _ = fullOutput.Write(msg.Data)
+		}
+	}
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, "stdout 1\nstdout 2\nstdout 3\n", fullOutput.String())
+
+	history, err := jobClient.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	require.Len(t, history.Attempts, 1)
+	assert.Equal(t, uint64(fullOutput.Len()), history.Attempts[0].StdoutBytes)
+	assert.Equal(t, "stderr 1\nstderr 2\nstderr 3\n", string(history.Attempts[0].StderrTail))
+
+	// Nothing readable is left on disk
+	entries, err := os.ReadDir(outDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(outDir, entry.Name()))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "stdout 1")
+		assert.NotContains(t, string(data), "stderr 1")
+	}
+}
+
+// Secrets are masked before output is stored, so neither
+// the files nor anything read from them contain them
+func TestRedactions(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	redaction, err := job.NewRedaction(`(stdout|stderr) [0-9]+`, "$1 ***")
+	require.NoError(t, err)
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, outDir,
+		service.WithRedactions([]job.Redaction{redaction}),
+	)
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command: testJobPath,
+		Args:    []string{"testjob", "2"},
+	})
+	require.NoError(t, err)
+
+	outputClient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+		JobId: resp.JobId,
+		Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+	})
+	require.NoError(t, err)
+	var fullOutput bytes.Buffer
+	var msg *jobmanagerpb.GetJobOutputResponse
+	for err == nil {
+		msg, err = outputClient.Recv()
+		if err == nil {
+			_, _ = fullOutput.Write(msg.Data)
+		}
+	}
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, "stdout ***\nstdout ***\n", fullOutput.String())
+
+	history, err := jobClient.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	require.Len(t, history.Attempts, 1)
+	assert.Equal(t, "stderr ***\nstderr ***\n", string(history.Attempts[0].StderrTail))
+
+	entries, err := os.ReadDir(outDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(outDir, entry.Name()))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), " 1")
+	}
+}
+
+func TestTimestampOutput(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	before := time.Now()
+	resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{
+			Command:         "/bin/sh",
+			Args:            []string{"sh", "-c", "echo one; echo two; echo two; echo two"},
+			TimestampOutput: true,
+		},
+	})
+	require.NoError(t, err)
+	_, err = jobClient.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+
+	readAll := func(req *jobmanagerpb.GetJobOutputRequest) string {
+		req.JobId = resp.JobId
+		req.Type = jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT
+		outputClient, err := jobClient.GetJobOutput(ctx, req)
+		require.NoError(t, err)
+		var output bytes.Buffer
+		for {
+			msg, err := outputClient.Recv()
+			if err != nil {
+				require.ErrorIs(t, err, io.EOF)
+				return output.String()
+			}
+			output.Write(msg.Data)
+		}
+	}
+
+	lines := strings.SplitAfter(readAll(&jobmanagerpb.GetJobOutputRequest{}), "\n")
+	require.Len(t, lines, 5)
+	for i, want := range []string{"one\n", "two\n", "two\n", "two\n"} {
+		stamped, rest, ok := job.CutTimestamp([]byte(lines[i]))
+		require.True(t, ok, lines[i])
+		assert.Equal(t, want, string(rest))
+		assert.False(t, stamped.Before(before.Truncate(time.Second)))
+	}
+
+	assert.Equal(t, "one\ntwo\ntwo\ntwo\n", readAll(&jobmanagerpb.GetJobOutputRequest{StripTimestamps: true}))
+	assert.Equal(t, "one\ntwo\ntwo\ntwo\n", readAll(&jobmanagerpb.GetJobOutputRequest{StripTimestamps: true, NoFollow: true}))
+	// Only identical once the timestamps are gone
+	assert.Equal(t, "one\ntwo\nlast line repeated 2 times\n", readAll(&jobmanagerpb.GetJobOutputRequest{
+		StripTimestamps:       true,
+		CollapseRepeatedLines: true,
+	}))
+}
+
+func TestListJobs(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "someuser"}
+	jobService := service.NewJobService(users, t.TempDir())
+
+	start := func(command string, args ...string) []byte {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{Command: command, Args: args},
+		})
+		require.NoError(t, err)
+		return resp.JobId
+	}
+	before := time.Now()
+	succeeded := start(testJobPath, "testjob", "1")
+	// Fails with exit code 1
+	failed := start(testJobPath, "testjob", "-exit", "1")
+	middle := time.Now()
+	shell := start("/bin/sh", "sh", "-c", "exit 3")
+
+	require.Eventually(t, func() bool {
+		resp, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+		require.NoError(t, err)
+		for _, record := range resp.Jobs {
+			if record.EndTime == nil {
+				return false
+			}
+		}
+		return len(resp.Jobs) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	exitCode := func(code int32) *int32 { return &code }
+	for _, tc := range []struct {
+		name string
+		req  *jobmanagerpb.ListJobsRequest
+		want [][]byte
+	}{
+		{name: "all", req: &jobmanagerpb.ListJobsRequest{}, want: [][]byte{succeeded, failed, shell}},
+		{name: "command", req: &jobmanagerpb.ListJobsRequest{CommandContains: "testjob"}, want: [][]byte{succeeded, failed}},
+		{name: "no-match", req: &jobmanagerpb.ListJobsRequest{CommandContains: "python"}},
+		{name: "exit-code", req: &jobmanagerpb.ListJobsRequest{ExitCode: exitCode(3)}, want: [][]byte{shell}},
+		{name: "exit-code-zero", req: &jobmanagerpb.ListJobsRequest{ExitCode: exitCode(0)}, want: [][]byte{succeeded}},
+		{name: "after", req: &jobmanagerpb.ListJobsRequest{StartedAfter: timestamppb.New(middle)}, want: [][]byte{shell}},
+		{name: "before", req: &jobmanagerpb.ListJobsRequest{StartedBefore: timestamppb.New(middle)}, want: [][]byte{succeeded, failed}},
+		{
+			name: "combined",
+			req: &jobmanagerpb.ListJobsRequest{
+				CommandContains: "testjob",
+				StartedAfter:    timestamppb.New(before),
+				ExitCode:        exitCode(0),
+			},
+			want: [][]byte{succeeded},
+		},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			resp, err := jobService.ListJobs(ctx, tc.req)
+			require.NoError(tt, err)
+			var got [][]byte
+			for _, record := range resp.Jobs {
+				got = append(got, record.JobId)
+			}
+			assert.Equal(tt, tc.want, got)
+		})
+	}
+
+	t.Run("invalid-range", func(tt *testing.T) {
+		_, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{
+			StartedAfter:  timestamppb.New(middle),
+			StartedBefore: timestamppb.New(before),
+		})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("other-user", func(tt *testing.T) {
+		users.user = "anotheruser"
+		resp, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+		require.NoError(tt, err)
+		assert.Empty(tt, resp.Jobs)
+	})
+}
+
+func TestSessions(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "someuser"}
+	jobService := service.NewJobService(users, t.TempDir())
+
+	start := func(tt *testing.T, session string, script string) []byte {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec:      &jobmanagerpb.JobSpec{Command: "/bin/sh", Args: []string{"sh", "-c", script}},
+			SessionId: session,
+			Force:     true,
+		})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+	sessionJobs := func(tt *testing.T, session string) [][]byte {
+		resp, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{SessionId: session})
+		require.NoError(tt, err)
+		var ids [][]byte
+		for _, record := range resp.Jobs {
+			assert.Equal(tt, session, record.SessionId)
+			ids = append(ids, record.JobId)
+		}
+		return ids
+	}
+
+	t.Run("stop jobs", func(tt *testing.T) {
+		done := start(tt, "ci-1", "exit 0")
+		_, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: done})
+		require.NoError(tt, err)
+		running := start(tt, "ci-1", "sleep 30")
+		other := start(tt, "ci-2", "sleep 30")
+		unsessioned := start(tt, "", "sleep 30")
+		assert.Equal(tt, [][]byte{done, running}, sessionJobs(tt, "ci-1"))
+
+		// Other users' sessions are their own
+		users.user = "anotheruser"
+		resp, err := jobService.EndSession(ctx, &jobmanagerpb.EndSessionRequest{SessionId: "ci-1", StopJobs: true})
+		require.NoError(tt, err)
+		assert.Empty(tt, resp.StoppedJobIds)
+		users.user = "someuser"
+
+		resp, err = jobService.EndSession(ctx, &jobmanagerpb.EndSessionRequest{SessionId: "ci-1", StopJobs: true})
+		require.NoError(tt, err)
+		runningID, err := uuid.FromBytes(running)
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{runningID.String()}, resp.StoppedJobIds)
+		final, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: running})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_STOPPED, final.CurrentStatus)
+
+		for _, id := range [][]byte{other, unsessioned} {
+			current, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+			require.NoError(tt, err)
+			assert.Equal(tt, jobmanagerpb.Status_STATUS_RUNNING, current.CurrentStatus)
+			_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: id})
+			require.NoError(tt, err)
+		}
+	})
+
+	t.Run("ended", func(tt *testing.T) {
+		running := start(tt, "ci-3", "sleep 30")
+		resp, err := jobService.EndSession(ctx, &jobmanagerpb.EndSessionRequest{SessionId: "ci-3"})
+		require.NoError(tt, err)
+		assert.Empty(tt, resp.StoppedJobIds)
+
+		// Left running, but nothing more may join
+		current, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: running})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_RUNNING, current.CurrentStatus)
+		_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec:      &jobmanagerpb.JobSpec{Command: "/bin/true"},
+			SessionId: "ci-3",
+		})
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: running})
+		require.NoError(tt, err)
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		_, err := jobService.EndSession(ctx, &jobmanagerpb.EndSessionRequest{})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+		_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec:      &jobmanagerpb.JobSpec{Command: "/bin/true"},
+			SessionId: strings.Repeat("x", 129),
+		})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestStreamServerLogs(t *testing.T) {
+	ctx := context.Background()
+	logs := service.NewLogBroadcaster(slog.NewTextHandler(io.Discard, nil))
+	users := &mockUserGetter{user: "admin"}
+	jobService := service.NewJobService(users, t.TempDir(), service.WithAdmins([]string{"admin"}), service.WithServerLogs(logs))
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	t.Run("admin", func(tt *testing.T) {
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream, err := jobClient.StreamServerLogs(streamCtx, &jobmanagerpb.StreamServerLogsRequest{
+			Level: jobmanagerpb.LogLevel_LOG_LEVEL_WARN,
+		})
+		require.NoError(tt, err)
+
+		// Only entries logged after the stream is handled are sent, so keep logging
+		logger := slog.New(logs)
+		go func() {
+			for streamCtx.Err() == nil {
+				logger.Info("Not shown")
+				logger.Warn("Something's off", "job-id", "1234")
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		entry, err := stream.Recv()
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.LogLevel_LOG_LEVEL_WARN, entry.Level)
+		assert.Equal(tt, "Something's off", entry.Message)
+		assert.Equal(tt, map[string]string{"job-id": "1234"}, entry.Attrs)
+		assert.False(tt, entry.Time.AsTime().IsZero())
+	})
+
+	t.Run("not admin", func(tt *testing.T) {
+		users.user = "someuser"
+		defer func() { users.user = "admin" }()
+		stream, err := jobClient.StreamServerLogs(ctx, &jobmanagerpb.StreamServerLogsRequest{})
+		require.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("unavailable", func(tt *testing.T) {
+		jobService := service.NewJobService(&mockUserGetter{user: "admin"}, t.TempDir(), service.WithAdmins([]string{"admin"}))
+		server := grpc.NewServer()
+		jobService.Register(server)
+		srv := testutils.GrpcLocalServer{}
+		require.NoError(tt, srv.ListenAndServe(server))
+		defer func() {
+			server.Stop()
+			_ = srv.Done()
+		}()
+		stream, err := jobmanagerpb.NewJobManagerClient(srv.Conn()).StreamServerLogs(ctx, &jobmanagerpb.StreamServerLogsRequest{})
+		require.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestRuntimeClasses(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(),
+		service.WithRuntimeClasses(map[string]job.Limits{
+			"short":     {Timeout: 100 * time.Millisecond},
+			"unlimited": {},
+			"isolated":  {Isolation: job.Isolation{Network: true}},
+		}, "short"),
+		service.WithEgressPolicies(map[string]job.Egress{
+			"loopback": {Mode: job.EgressLoopback},
+		}),
+	)
+
+	waitForStatus := func(tt *testing.T, id []byte) *jobmanagerpb.GetStatusResponse {
+		var resp *jobmanagerpb.GetStatusResponse
+		require.Eventually(tt, func() bool {
+			var err error
+			resp, err = jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+			require.NoError(tt, err)
+			return resp.CurrentStatus == jobmanagerpb.Status_STATUS_COMPLETE
+		}, 5*time.Second, 10*time.Millisecond)
+		return resp
+	}
+
+	t.Run("default-class", func(tt *testing.T) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command: testJobPath,
+			Args:    []string{"testjob", "5"},
+		})
+		require.NoError(tt, err)
+		statusResp := waitForStatus(tt, resp.JobId)
+		assert.True(tt, statusResp.TimedOut)
+		assert.Equal(tt, jobmanagerpb.ExitReason_EXIT_REASON_TIMED_OUT, statusResp.ExitReason)
+		assert.Equal(tt, "SIGKILL", statusResp.Signal)
+	})
+
+	t.Run("selected-class", func(tt *testing.T) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command:      testJobPath,
+			Args:         []string{"testjob", "1"},
+			RuntimeClass: "unlimited",
+		})
+		require.NoError(tt, err)
+		statusResp := waitForStatus(tt, resp.JobId)
+		assert.False(tt, statusResp.TimedOut)
+		assert.Equal(tt, jobmanagerpb.ExitReason_EXIT_REASON_EXITED, statusResp.ExitReason)
+		assert.Empty(tt, statusResp.Signal)
+		require.NotNil(tt, statusResp.ExitCode)
+		assert.Zero(tt, *statusResp.ExitCode)
+	})
 
-		time.Sleep(1 * time.Second)
+	t.Run("spec-timeout", func(tt *testing.T) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{
+				Command:      testJobPath,
+				Args:         []string{"testjob", "5"},
+				RuntimeClass: "unlimited",
+				Timeout:      durationpb.New(100 * time.Millisecond),
+			},
+		})
+		require.NoError(tt, err)
+		assert.True(tt, waitForStatus(tt, resp.JobId).TimedOut)
+	})
 
-		statusResp, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{
-			JobId: resp.JobId,
+	t.Run("unknown-class", func(tt *testing.T) {
+		_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command:      testJobPath,
+			Args:         []string{"testjob", "1"},
+			RuntimeClass: "huge",
 		})
-		require.NoError(t, err)
-		require.NotNil(t, statusResp)
-		require.Equal(t, jobmanagerpb.Status_STATUS_STOPPED, statusResp.CurrentStatus)
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
 	})
 
-	t.Run("invalid-user", func(tt *testing.T) {
-		// Create a job
+	t.Run("egress-policy", func(tt *testing.T) {
+		if os.Geteuid() != 0 {
+			tt.Skip("network namespaces require root")
+		}
 		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
-			Command: echoPathRelative,
-			Args:    []string{"echo", "5"},
+			Spec: &jobmanagerpb.JobSpec{
+				Command:      "/bin/sh",
+				Args:         []string{"sh", "-c", "ip link | grep -q LOOPBACK,UP"},
+				RuntimeClass: "isolated",
+				EgressPolicy: "loopback",
+			},
 		})
 		require.NoError(tt, err)
-		require.NotNil(tt, resp)
-		require.NotNil(tt, resp.JobId)
+		statusResp := waitForStatus(tt, resp.JobId)
+		require.NotNil(tt, statusResp.ExitCode)
+		assert.Zero(tt, *statusResp.ExitCode)
+	})
 
-		// Change the user
-		mockUserGetter.user = "anotheruser"
+	t.Run("invalid-egress-policy", func(tt *testing.T) {
+		for _, spec := range []*jobmanagerpb.JobSpec{
+			{Command: testJobPath, RuntimeClass: "isolated", EgressPolicy: "internet"},
+			// The class has to isolate the network for a policy to mean anything
+			{Command: testJobPath, RuntimeClass: "unlimited", EgressPolicy: "loopback"},
+		} {
+			_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+		}
+	})
+}
 
-		// Stopping the job should fail with "not found" since we are no longer
-		// the right user
-		stopResp, err := jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{
-			JobId: resp.JobId,
+func TestGPUs(t *testing.T) {
+	gpus := []job.GPU{
+		{Index: 0, UUID: "GPU-aaaa", Model: "Tesla T4", BusID: "0000:3b:00.0"},
+		{Index: 2, UUID: "GPU-bbbb", Model: "Tesla T4", BusID: "0000:af:00.0"},
+	}
+	srv := testutils.GrpcLocalServer{}
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(),
+		service.WithRuntimeClasses(map[string]job.Limits{
+			"unlimited": {},
+			"gpu":       {Cgroup: &job.CgroupLimits{Parent: t.TempDir()}},
+		}, ""),
+		service.WithGPUs(gpus),
+	)
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	ctx := context.Background()
+
+	t.Run("server-info", func(tt *testing.T) {
+		hostname, err := os.Hostname()
+		require.NoError(tt, err)
+		info, err := jobmanagerpb.NewJobManagerClient(srv.Conn()).GetServerInfo(ctx, &jobmanagerpb.GetServerInfoRequest{})
+		require.NoError(tt, err)
+		assert.Equal(tt, hostname, info.Hostname)
+		require.Len(tt, info.Gpus, 2)
+		assert.Equal(tt, uint32(2), info.Gpus[1].Index)
+		assert.Equal(tt, "GPU-bbbb", info.Gpus[1].Uuid)
+		assert.Equal(tt, "Tesla T4", info.Gpus[1].Model)
+		assert.Equal(tt, "0000:af:00.0", info.Gpus[1].BusId)
+
+		infoV2, err := jobmanagerv2.NewJobManagerClient(srv.Conn()).GetServerInfo(ctx, &jobmanagerv2.GetServerInfoRequest{})
+		require.NoError(tt, err)
+		assert.Equal(tt, hostname, infoV2.Hostname)
+		require.Len(tt, infoV2.Gpus, 2)
+		assert.Equal(tt, "GPU-aaaa", infoV2.Gpus[0].Uuid)
+	})
+
+	t.Run("invalid-gpus", func(tt *testing.T) {
+		for _, spec := range []*jobmanagerpb.JobSpec{
+			{Command: testJobPath, RuntimeClass: "gpu", Gpus: []uint32{1}},
+			{Command: testJobPath, RuntimeClass: "gpu", Gpus: []uint32{0, 0}},
+			// Access is enforced through the job's cgroup
+			{Command: testJobPath, RuntimeClass: "unlimited", Gpus: []uint32{0}},
+		} {
+			_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err), spec.Gpus)
+		}
+	})
+}
+
+func TestUsageSummary(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "alice"}
+	registry := prometheus.NewRegistry()
+	jobService := service.NewJobService(users, t.TempDir(),
+		service.WithUsageAccounting(service.UsageAccounting{
+			Windows: []time.Duration{time.Hour, time.Minute},
+			Viewers: []string{"finance"},
+		}),
+		service.WithMetrics(registry),
+	)
+
+	// Burns a little CPU
+	_, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{
+			Command: "/bin/sh",
+			Args:    []string{"sh", "-c", "i=0; while [ $i -lt 100000 ]; do i=$((i+1)); done"},
+		},
+	})
+	require.NoError(t, err)
+	users.user = "bob"
+	_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Command: testJobPath, Args: []string{"testjob", "1"}})
+	require.NoError(t, err)
+
+	summary := func(tt *testing.T, user string, req *jobmanagerpb.GetUsageSummaryRequest) *jobmanagerpb.GetUsageSummaryResponse {
+		users.user = user
+		resp, err := jobService.GetUsageSummary(ctx, req)
+		require.NoError(tt, err)
+		return resp
+	}
+	// Attempts count once they finish
+	require.Eventually(t, func() bool {
+		resp := summary(t, "finance", &jobmanagerpb.GetUsageSummaryRequest{})
+		owners := resp.Windows[0].Owners
+		return len(owners) == 2 && owners[0].AttemptsFinished == 1 && owners[1].AttemptsFinished == 1
+	}, 10*time.Second, 10*time.Millisecond)
+
+	t.Run("own-usage", func(tt *testing.T) {
+		resp := summary(tt, "alice", &jobmanagerpb.GetUsageSummaryRequest{})
+		require.Len(tt, resp.Windows, 2)
+		assert.Equal(tt, time.Minute, resp.Windows[0].Window.AsDuration())
+		assert.Equal(tt, time.Hour, resp.Windows[1].Window.AsDuration())
+		for _, window := range resp.Windows {
+			require.Len(tt, window.Owners, 1)
+			usage := window.Owners[0]
+			assert.Equal(tt, "alice", usage.Owner)
+			assert.Equal(tt, uint64(1), usage.JobsStarted)
+			assert.Equal(tt, uint64(1), usage.AttemptsFinished)
+			assert.Positive(tt, usage.CpuSeconds)
+			assert.Positive(tt, usage.WallSeconds)
+		}
+
+		// Users without any usage get an empty summary
+		resp = summary(tt, "carol", &jobmanagerpb.GetUsageSummaryRequest{})
+		require.Len(tt, resp.Windows, 2)
+		assert.Empty(tt, resp.Windows[0].Owners)
+	})
+
+	t.Run("all-owners", func(tt *testing.T) {
+		resp := summary(tt, "finance", &jobmanagerpb.GetUsageSummaryRequest{Window: durationpb.New(time.Hour)})
+		require.Len(tt, resp.Windows, 1)
+		owners := resp.Windows[0].Owners
+		require.Len(tt, owners, 2)
+		assert.Equal(tt, "alice", owners[0].Owner)
+		assert.Equal(tt, "bob", owners[1].Owner)
+		assert.Equal(tt, uint64(1), owners[1].JobsStarted)
+	})
+
+	t.Run("unknown-window", func(tt *testing.T) {
+		users.user = "alice"
+		_, err := jobService.GetUsageSummary(ctx, &jobmanagerpb.GetUsageSummaryRequest{Window: durationpb.New(2 * time.Hour)})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("metrics", func(tt *testing.T) {
+		assert.NoError(tt, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP jobby_jobs_started_total Jobs started, by owner
+# TYPE jobby_jobs_started_total counter
+jobby_jobs_started_total{owner="alice"} 1
+jobby_jobs_started_total{owner="bob"} 1
+`), "jobby_jobs_started_total"))
+		count, err := testutil.GatherAndCount(registry, "jobby_job_cpu_seconds_total", "jobby_job_wall_seconds_total")
+		require.NoError(tt, err)
+		assert.Equal(tt, 4, count)
+	})
+}
+
+func TestServerInfo(t *testing.T) {
+	ctx := context.Background()
+	featureSet, err := features.New(map[string]bool{"v2_api": false})
+	require.NoError(t, err)
+	registry := prometheus.NewRegistry()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(),
+		service.WithFeatures(featureSet),
+		service.WithMetrics(registry),
+	)
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+
+	info, err := jobmanagerpb.NewJobManagerClient(srv.Conn()).GetServerInfo(ctx, &jobmanagerpb.GetServerInfoRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, info.Build)
+	assert.NotEmpty(t, info.Build.Version)
+	assert.Equal(t, runtime.Version(), info.Build.GoVersion)
+	require.Len(t, info.Features, 5)
+	assert.Equal(t, "grpc_admin", info.Features[0].Name)
+	assert.False(t, info.Features[0].Enabled)
+	assert.Equal(t, "grpc_reflection", info.Features[1].Name)
+	assert.True(t, info.Features[1].Enabled)
+	assert.Equal(t, "process_adoption", info.Features[2].Name)
+	assert.True(t, info.Features[2].Experimental)
+	assert.False(t, info.Features[2].Enabled)
+	assert.Equal(t, "server_log_streaming", info.Features[3].Name)
+	assert.Equal(t, []string{"StreamServerLogs"}, info.Features[3].Rpcs)
+	assert.Equal(t, "v2_api", info.Features[4].Name)
+	assert.False(t, info.Features[4].Enabled)
+
+	// The flag is off, so v2 isn't served
+	_, err = jobmanagerv2.NewJobManagerClient(srv.Conn()).GetServerInfo(ctx, &jobmanagerv2.GetServerInfoRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP jobby_feature_enabled 1 if the feature flag is on, 0 if it's off
+# TYPE jobby_feature_enabled gauge
+jobby_feature_enabled{feature="grpc_admin"} 0
+jobby_feature_enabled{feature="grpc_reflection"} 1
+jobby_feature_enabled{feature="process_adoption"} 0
+jobby_feature_enabled{feature="server_log_streaming"} 1
+jobby_feature_enabled{feature="v2_api"} 0
+`), "jobby_feature_enabled"))
+	count, err := testutil.GatherAndCount(registry, "jobby_build_info")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestDuplicateJobs(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "alice"}
+	jobService := service.NewJobService(users, t.TempDir())
+	start := func(env map[string]string, force bool) (*jobmanagerpb.StartJobResponse, error) {
+		return jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec:  &jobmanagerpb.JobSpec{Command: "/bin/sleep", Args: []string{"sleep", "30"}, Env: env},
+			Force: force,
 		})
-		require.Error(tt, err)
-		stat, ok := status.FromError(err)
-		require.True(tt, ok)
-		require.Equal(tt, codes.NotFound, stat.Code())
-		require.Nil(t, stopResp)
+	}
+	stop := func(tt *testing.T, resp *jobmanagerpb.StartJobResponse) {
+		_, err := jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+	}
+
+	users.user = "alice"
+	first, err := start(map[string]string{"A": "1", "B": "2"}, false)
+	require.NoError(t, err)
+	assert.Empty(t, first.Warnings)
+
+	t.Run("refused", func(tt *testing.T) {
+		users.user = "alice"
+		_, err := start(map[string]string{"B": "2", "A": "1"}, false)
+		assert.Equal(tt, codes.AlreadyExists, status.Code(err))
+		assert.Contains(tt, status.Convert(err).Message(), first.Id)
+	})
+
+	t.Run("forced", func(tt *testing.T) {
+		users.user = "alice"
+		resp, err := start(map[string]string{"A": "1", "B": "2"}, true)
+		require.NoError(tt, err)
+		require.Len(tt, resp.Warnings, 1)
+		assert.Contains(tt, resp.Warnings[0], first.Id)
+		stop(tt, resp)
+	})
+
+	t.Run("not-duplicates", func(tt *testing.T) {
+		// Different environment
+		users.user = "alice"
+		resp, err := start(map[string]string{"A": "1"}, false)
+		require.NoError(tt, err)
+		assert.Empty(tt, resp.Warnings)
+		stop(tt, resp)
+
+		// Different owner
+		users.user = "bob"
+		resp, err = start(map[string]string{"A": "1", "B": "2"}, false)
+		require.NoError(tt, err)
+		assert.Empty(tt, resp.Warnings)
+		stop(tt, resp)
+	})
+
+	t.Run("finished", func(tt *testing.T) {
+		users.user = "alice"
+		stop(tt, first)
+		// Once it has exited
+		var resp *jobmanagerpb.StartJobResponse
+		require.Eventually(tt, func() bool {
+			var err error
+			resp, err = start(map[string]string{"A": "1", "B": "2"}, false)
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Empty(tt, resp.Warnings)
+		stop(tt, resp)
+	})
+}
+
+func TestJobMutexes(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "alice"}
+	jobService := service.NewJobService(users, t.TempDir())
+	start := func(ctx context.Context, mutex string, wait bool) (*jobmanagerpb.StartJobResponse, error) {
+		return jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec:  &jobmanagerpb.JobSpec{Command: "/bin/sleep", Args: []string{"sleep", "30"}, Mutex: mutex, WaitForMutex: wait},
+			Force: true,
+		})
+	}
+	stop := func(tt *testing.T, resp *jobmanagerpb.StartJobResponse) {
+		_, err := jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+	}
+
+	first, err := start(ctx, "db-migration", false)
+	require.NoError(t, err)
+
+	t.Run("refused", func(tt *testing.T) {
+		// Whoever owns the holder
+		users.user = "bob"
+		_, err := start(ctx, "db-migration", false)
+		assert.Equal(tt, codes.Aborted, status.Code(err))
+		assert.Contains(tt, status.Convert(err).Message(), first.Id)
+	})
+
+	t.Run("other-mutex", func(tt *testing.T) {
+		users.user = "bob"
+		resp, err := start(ctx, "backup", false)
+		require.NoError(tt, err)
+		stop(tt, resp)
+	})
+
+	t.Run("wait-timeout", func(tt *testing.T) {
+		users.user = "bob"
+		waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := start(waitCtx, "db-migration", true)
+		assert.Equal(tt, codes.DeadlineExceeded, status.Code(err))
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		_, err := start(ctx, "", true)
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("wait", func(tt *testing.T) {
+		// Not changed while jobs wait, since they read it
+		users.user = "alice"
+		started := make(chan *jobmanagerpb.StartJobResponse, 2)
+		for range 2 {
+			go func() {
+				resp, err := start(ctx, "db-migration", true)
+				assert.NoError(tt, err)
+				started <- resp
+			}()
+		}
+		select {
+		case <-started:
+			tt.Fatal("Started while another job held the mutex")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		stop(tt, first)
+		second := <-started
+		firstStatus, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: first.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_STOPPED, firstStatus.CurrentStatus)
+		// One at a time
+		select {
+		case <-started:
+			tt.Fatal("Both waiting jobs started at once")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		stop(tt, second)
+		stop(tt, <-started)
+	})
+}
+
+func TestJobEvents(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "alice"}
+	events, err := service.OpenEventLog(filepath.Join(t.TempDir(), "events.jsonl"), time.Hour)
+	require.NoError(t, err)
+	defer events.Close()
+	jobService := service.NewJobService(users, t.TempDir(),
+		service.WithRetention(service.RetentionLimits{DefaultTTL: time.Minute}),
+		service.WithEventLog(events),
+	)
+	getEvents := func(tt *testing.T, id []byte) []*jobmanagerpb.JobEvent {
+		resp, err := jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: id})
+		require.NoError(tt, err)
+		return resp.Events
+	}
+	eventTypes := func(events []*jobmanagerpb.JobEvent) []jobmanagerpb.JobEventType {
+		var types []jobmanagerpb.JobEventType
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		return types
+	}
+
+	t.Run("stopped", func(tt *testing.T) {
+		users.user = "alice"
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Command: "/bin/sleep", Args: []string{"sleep", "30"}})
+		require.NoError(tt, err)
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+
+		var events []*jobmanagerpb.JobEvent
+		require.Eventually(tt, func() bool {
+			events = getEvents(tt, resp.JobId)
+			return len(events) == 4
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(tt, []jobmanagerpb.JobEventType{
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_STARTED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_SIGNALED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_EXITED,
+		}, eventTypes(events))
+		assert.Equal(tt, "alice", events[0].Actor)
+		assert.Equal(tt, "server", events[1].Actor)
+		assert.Equal(tt, uint32(1), events[1].Attempt)
+		assert.Equal(tt, "alice", events[2].Actor)
+		assert.Equal(tt, "SIGKILL (STOPPED)", events[2].Detail)
+		assert.Equal(tt, "STOPPED (SIGKILL)", events[3].Detail)
+		for i := 1; i < len(events); i++ {
+			assert.False(tt, events[i].Time.AsTime().Before(events[i-1].Time.AsTime()))
+		}
+
+		// Only the owner can see them
+		users.user = "bob"
+		_, err = jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: resp.JobId})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("retried", func(tt *testing.T) {
+		users.user = "alice"
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{
+				Command:     "/bin/sh",
+				Args:        []string{"sh", "-c", "exit 3"},
+				MaxAttempts: 2,
+			},
+		})
+		require.NoError(tt, err)
+
+		var events []*jobmanagerpb.JobEvent
+		require.Eventually(tt, func() bool {
+			events = getEvents(tt, resp.JobId)
+			return len(events) == 5
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(tt, []jobmanagerpb.JobEventType{
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_STARTED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_ATTEMPT_FAILED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_STARTED,
+			jobmanagerpb.JobEventType_JOB_EVENT_TYPE_ATTEMPT_FAILED,
+		}, eventTypes(events))
+		assert.Equal(tt, uint32(2), events[4].Attempt)
+		assert.Equal(tt, "EXITED (exit code 3)", events[4].Detail)
+	})
+
+	t.Run("garbage-collected", func(tt *testing.T) {
+		users.user = "alice"
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Command: testJobPath, Args: []string{"testjob", "1"}})
+		require.NoError(tt, err)
+		require.Eventually(tt, func() bool {
+			return len(getEvents(tt, resp.JobId)) == 3
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// Events outlive the job
+		require.Positive(tt, jobService.CollectGarbage(time.Now().Add(2*time.Minute)))
+		events := getEvents(tt, resp.JobId)
+		require.Len(tt, events, 4)
+		assert.Equal(tt, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_GARBAGE_COLLECTED, events[3].Type)
+		assert.Equal(tt, "server", events[3].Actor)
+
+		// Until the event retention passes too
+		jobService.CollectGarbage(time.Now().Add(2 * time.Hour))
+		_, err = jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: resp.JobId})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("v2", func(tt *testing.T) {
+		users.user = "alice"
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Command: testJobPath, Args: []string{"testjob", "1"}})
+		require.NoError(tt, err)
+		srv := testutils.GrpcLocalServer{}
+		server := grpc.NewServer()
+		jobService.Register(server)
+		require.NoError(tt, srv.ListenAndServe(server))
+		defer func() {
+			server.Stop()
+			_ = srv.Done()
+		}()
+		events, err := jobmanagerv2.NewJobManagerClient(srv.Conn()).GetJobEvents(ctx, &jobmanagerv2.GetJobEventsRequest{JobId: resp.Id})
+		require.NoError(tt, err)
+		require.NotEmpty(tt, events.Events)
+		assert.Equal(tt, jobmanagerv2.JobEventType_JOB_EVENT_TYPE_CREATED, events.Events[0].Type)
+	})
+}
+
+func TestShellJobs(t *testing.T) {
+	ctx := context.Background()
+	events, err := service.OpenEventLog(filepath.Join(t.TempDir(), "events.jsonl"), time.Hour)
+	require.NoError(t, err)
+	defer events.Close()
+	jobService := service.NewJobService(&mockUserGetter{user: "alice"}, t.TempDir(), service.WithEventLog(events))
+
+	wait := func(tt *testing.T, shell string) (*jobmanagerpb.StartJobResponse, *jobmanagerpb.GetStatusResponse) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Shell: shell}})
+		require.NoError(tt, err)
+		statusResp, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		return resp, statusResp
+	}
+
+	// Pipelines and expansions only work if a shell runs the line
+	resp, statusResp := wait(t, `test "$(echo hi | tr a-z A-Z)" = HI`)
+	require.NotNil(t, statusResp.ExitCode)
+	assert.Equal(t, int32(0), *statusResp.ExitCode)
+	_, statusResp = wait(t, "echo hi | grep -q bye")
+	require.NotNil(t, statusResp.ExitCode)
+	assert.Equal(t, int32(1), *statusResp.ExitCode)
+
+	eventsResp, err := jobService.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	require.NotEmpty(t, eventsResp.Events)
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED, eventsResp.Events[0].Type)
+	assert.Equal(t, `shell: test "$(echo hi | tr a-z A-Z)" = HI`, eventsResp.Events[0].Detail)
+
+	// Found by their command line, and shown to older clients as the shell
+	list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{CommandContains: "grep -q"})
+	require.NoError(t, err)
+	require.Len(t, list.Jobs, 1)
+	assert.Equal(t, "/bin/sh", list.Jobs[0].Command)
+	assert.Equal(t, []string{"sh", "-c", "echo hi | grep -q bye"}, list.Jobs[0].Args)
+	assert.Equal(t, "echo hi | grep -q bye", list.Jobs[0].Spec.Shell)
+}
+
+func TestPreemption(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir(),
+		service.WithCapacity(service.Capacity{MaxRunningJobs: 1, PreemptionGrace: 5 * time.Second}),
+	)
+	start := func(script string, priority int32, requeue bool) (*jobmanagerpb.StartJobResponse, error) {
+		return jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{
+				Command:             "/bin/sh",
+				Args:                []string{"sh", "-c", script},
+				Priority:            priority,
+				RequeueOnPreemption: requeue,
+			},
+		})
+	}
+	getStatus := func(tt *testing.T, id []byte) *jobmanagerpb.GetStatusResponse {
+		resp, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+		require.NoError(tt, err)
+		return resp
+	}
+	record := func(tt *testing.T, id string) *jobmanagerpb.JobRecord {
+		resp, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+		require.NoError(tt, err)
+		for _, record := range resp.Jobs {
+			if record.Id == id {
+				return record
+			}
+		}
+		require.FailNow(tt, "job not listed")
+		return nil
+	}
+	// Exits with 1 on SIGTERM, so being preempted doesn't look like success
+	const lowScript = "trap 'exit 1' TERM; i=0; while [ $i -lt 10 ]; do sleep 0.05; i=$((i+1)); done"
+
+	t.Run("requeue", func(tt *testing.T) {
+		low, err := start(lowScript, 0, true)
+		require.NoError(tt, err)
+
+		// Equally important jobs have to wait their turn
+		_, err = start("true", 0, false)
+		assert.Equal(tt, codes.ResourceExhausted, status.Code(err))
+
+		high, err := start("sleep 0.5", 5, false)
+		require.NoError(tt, err)
+		require.Eventually(tt, func() bool {
+			resp := getStatus(tt, low.JobId)
+			return resp.ExitReason == jobmanagerpb.ExitReason_EXIT_REASON_PREEMPTED && resp.Queued
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_STOPPED, getStatus(tt, low.JobId).CurrentStatus)
+		assert.Nil(tt, record(tt, low.Id).EndTime)
+
+		// Runs again once the high priority job is done
+		require.Eventually(tt, func() bool {
+			return getStatus(tt, low.JobId).CurrentStatus == jobmanagerpb.Status_STATUS_COMPLETE
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, getStatus(tt, high.JobId).CurrentStatus)
+		resp := getStatus(tt, low.JobId)
+		require.NotNil(tt, resp.ExitCode)
+		assert.Zero(tt, *resp.ExitCode)
+		assert.False(tt, resp.Queued)
+		assert.Equal(tt, uint32(2), record(tt, low.Id).Attempts)
+		// Finished as far as duplicate detection goes, so the next subtest may reuse the script
+		require.Eventually(tt, func() bool {
+			return record(tt, low.Id).EndTime != nil
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("no-requeue", func(tt *testing.T) {
+		low, err := start(lowScript, 0, false)
+		require.NoError(tt, err)
+		_, err = start("true", 1, false)
+		require.NoError(tt, err)
+
+		require.Eventually(tt, func() bool {
+			return record(tt, low.Id).EndTime != nil
+		}, 5*time.Second, 10*time.Millisecond)
+		resp := getStatus(tt, low.JobId)
+		assert.Equal(tt, jobmanagerpb.ExitReason_EXIT_REASON_PREEMPTED, resp.ExitReason)
+		assert.False(tt, resp.Queued)
+		assert.Equal(tt, uint32(1), record(tt, low.Id).Attempts)
 	})
 
+	t.Run("stop-queued", func(tt *testing.T) {
+		// Waits for the previous subtest's jobs to make room
+		var low *jobmanagerpb.StartJobResponse
+		require.Eventually(tt, func() bool {
+			var err error
+			low, err = start(lowScript, 0, true)
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		high, err := start("sleep 0.5", 1, false)
+		require.NoError(tt, err)
+		require.Eventually(tt, func() bool {
+			return getStatus(tt, low.JobId).Queued
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: low.JobId})
+		require.NoError(tt, err)
+		assert.False(tt, getStatus(tt, low.JobId).Queued)
+		assert.NotNil(tt, record(tt, low.Id).EndTime)
+
+		// It doesn't come back once there's room
+		require.Eventually(tt, func() bool {
+			return getStatus(tt, high.JobId).CurrentStatus == jobmanagerpb.Status_STATUS_COMPLETE
+		}, 5*time.Second, 10*time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(tt, uint32(1), record(tt, low.Id).Attempts)
+	})
 }
 
 // Streaming is a little more challenging
 // We could generate some mocks (I like github.com/maxbrunsfeld/counterfeiter)
 // But for basic black box tests, a local server is easy enough to spin up
+// Failures inside the job runner are rolled back and reported, not leaked
+func TestJobFaults(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	faults := job.NewFaultInjector()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, outDir,
+		// A slot that isn't given back would block every later job
+		service.WithCapacity(service.Capacity{MaxRunningJobs: 1}),
+		service.WithFaultInjector(faults),
+	)
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+	injected := errors.New("injected")
+
+	for name, fault := range map[string]job.Fault{
+		"create output": job.FaultCreateOutput,
+		"start":         job.FaultStart,
+	} {
+		t.Run(name, func(tt *testing.T) {
+			faults.Inject(fault, job.Injection{Err: injected, Times: 1})
+			_, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+				Command: testJobPath,
+				Args:    []string{"testjob", "1"},
+			})
+			assert.Equal(tt, codes.Internal, status.Code(err))
+
+			list, err := jobClient.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+			require.NoError(tt, err)
+			assert.Empty(tt, list.Jobs)
+			entries, err := os.ReadDir(outDir)
+			require.NoError(tt, err)
+			assert.Empty(tt, entries)
+		})
+	}
+
+	resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Command: testJobPath,
+		Args:    []string{"testjob", "1"},
+	})
+	require.NoError(t, err)
+	_, err = jobClient.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+
+	readAll := func(tt *testing.T) error {
+		outputClient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+			JobId: resp.JobId,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+		})
+		require.NoError(tt, err)
+		for {
+			if _, err := outputClient.Recv(); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, fault := range map[string]job.Fault{
+		"watch": job.FaultWatch,
+		"read":  job.FaultRead,
+	} {
+		t.Run(name, func(tt *testing.T) {
+			faults.Inject(fault, job.Injection{Err: injected})
+			defer faults.Clear(fault)
+			assert.Equal(tt, codes.Internal, status.Code(readAll(tt)))
+
+			// The job itself is fine
+			statusResp, err := jobClient.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+			require.NoError(tt, err)
+			assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, statusResp.CurrentStatus)
+		})
+	}
+	assert.ErrorIs(t, readAll(t), io.EOF)
+}
+
 func TestService(t *testing.T) {
 	srv := testutils.GrpcLocalServer{}
 	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, os.TempDir())
@@ -110,8 +2416,8 @@ func TestService(t *testing.T) {
 
 	t.Run("stream-stdout", func(tt *testing.T) {
 		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
-			Command: echoPathRelative,
-			Args:    []string{"echo", "5"},
+			Command: testJobPath,
+			Args:    []string{"testjob", "5"},
 		})
 		require.NoError(tt, err)
 		require.NotNil(tt, resp)
@@ -144,8 +2450,8 @@ func TestService(t *testing.T) {
 
 	t.Run("stream-stderr-cancel", func(tt *testing.T) {
 		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
-			Command: echoPathRelative,
-			Args:    []string{"echo", "5"},
+			Command: testJobPath,
+			Args:    []string{"testjob", "5"},
 		})
 		require.NoError(tt, err)
 		require.NotNil(tt, resp)
@@ -168,4 +2474,156 @@ func TestService(t *testing.T) {
 		require.True(tt, ok)
 		require.Equal(tt, codes.Canceled, st.Code())
 	})
+
+	t.Run("export", func(tt *testing.T) {
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command:     testJobPath,
+			Args:        []string{"testjob", "1"},
+			MaxAttempts: 2,
+		})
+		require.NoError(tt, err)
+
+		var exported *jobmanagerpb.JobRecord
+		require.Eventually(tt, func() bool {
+			stream, err := jobClient.ExportJobs(ctx, &jobmanagerpb.ExportJobsRequest{})
+			require.NoError(tt, err)
+			var records []*jobmanagerpb.JobRecord
+			for {
+				msg, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(tt, err)
+				records = append(records, msg)
+			}
+			// Jobs from the earlier subtests are exported too, oldest first
+			require.NotEmpty(tt, records)
+			exported = records[len(records)-1]
+			return exported.EndTime != nil
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.Equal(tt, resp.JobId, exported.JobId)
+		assert.Equal(tt, testJobPath, exported.Command)
+		assert.Equal(tt, []string{"testjob", "1"}, exported.Args)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, exported.Status)
+		require.NotNil(tt, exported.ExitCode)
+		assert.Zero(tt, *exported.ExitCode)
+		assert.Equal(tt, uint32(1), exported.Attempts)
+		assert.Equal(tt, uint32(2), exported.MaxAttempts)
+		assert.False(tt, exported.EndTime.AsTime().Before(exported.StartTime.AsTime()))
+		assert.Positive(tt, exported.Duration.AsDuration())
+		// Requests without a spec get one built from their fields
+		assert.Equal(tt, testJobPath, exported.Spec.GetCommand())
+		assert.Equal(tt, uint32(2), exported.Spec.GetMaxAttempts())
+	})
+
+	t.Run("spec", func(tt *testing.T) {
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{
+				Command: "/bin/sh",
+				Args:    []string{"sh", "-c", "echo $GREETING"},
+				Env:     map[string]string{"GREETING": "hello"},
+				Labels:  map[string]string{"team": "infra"},
+				// Parameters are allowed
+				OutputContentType: "text/plain; charset=utf-8",
+			},
+		})
+		require.NoError(tt, err)
+		statusResp, err := jobClient.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, "text/plain; charset=utf-8", statusResp.OutputContentType)
+
+		outputclient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+			JobId: resp.JobId,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+		})
+		require.NoError(tt, err)
+		var output bytes.Buffer
+		for {
+			msg, err := outputclient.Recv()
+			if err != nil {
+				assert.ErrorIs(tt, err, io.EOF)
+				break
+			}
+			_, _ = output.Write(msg.Data)
+		}
+		assert.Equal(tt, "hello\n", output.String())
+
+		stream, err := jobClient.ExportJobs(ctx, &jobmanagerpb.ExportJobsRequest{})
+		require.NoError(tt, err)
+		var exported *jobmanagerpb.JobRecord
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(tt, err)
+			exported = msg
+		}
+		require.NotNil(tt, exported)
+		assert.Equal(tt, resp.JobId, exported.JobId)
+		assert.Equal(tt, "/bin/sh", exported.Command)
+		assert.Equal(tt, map[string]string{"team": "infra"}, exported.Spec.GetLabels())
+	})
+
+	t.Run("scheduling", func(tt *testing.T) {
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{
+				Command: "/bin/sh",
+				Args:    []string{"sh", "-c", "nice; ionice; grep Cpus_allowed_list /proc/self/status"},
+				Scheduling: &jobmanagerpb.Scheduling{
+					Nice:       proto.Int32(5),
+					IoClass:    jobmanagerpb.IOClass_IO_CLASS_BEST_EFFORT,
+					IoPriority: 6,
+					Cpus:       []uint32{0},
+				},
+			},
+		})
+		require.NoError(tt, err)
+
+		outputclient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+			JobId: resp.JobId,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+		})
+		require.NoError(tt, err)
+		var output bytes.Buffer
+		for {
+			msg, err := outputclient.Recv()
+			if err != nil {
+				assert.ErrorIs(tt, err, io.EOF)
+				break
+			}
+			_, _ = output.Write(msg.Data)
+		}
+		assert.Equal(tt, "5\nbest-effort: prio 6\nCpus_allowed_list:\t0\n", output.String())
+	})
+
+	t.Run("invalid-spec", func(tt *testing.T) {
+		for _, spec := range []*jobmanagerpb.JobSpec{
+			{},
+			{Command: testJobPath, Env: map[string]string{"A=B": "c"}},
+			{Command: testJobPath, Labels: map[string]string{"": "empty"}},
+			{Command: testJobPath, Timeout: durationpb.New(-time.Second)},
+			{Command: testJobPath, MaxAttempts: 100},
+			{Command: testJobPath, Scheduling: &jobmanagerpb.Scheduling{Nice: proto.Int32(-5)}},
+			{Command: testJobPath, Scheduling: &jobmanagerpb.Scheduling{Nice: proto.Int32(20)}},
+			{Command: testJobPath, Scheduling: &jobmanagerpb.Scheduling{IoClass: 5}},
+			{Command: testJobPath, Scheduling: &jobmanagerpb.Scheduling{IoPriority: 8}},
+			{Command: testJobPath, Scheduling: &jobmanagerpb.Scheduling{Cpus: []uint32{4096}}},
+			{Command: testJobPath, OutputWindowBytes: 100},
+			{Command: testJobPath, OutputSegments: &jobmanagerpb.SegmentPolicy{MaxBytes: 100}},
+			{Command: testJobPath, OutputSegments: &jobmanagerpb.SegmentPolicy{Interval: durationpb.New(time.Millisecond)}},
+			{Command: testJobPath, OutputContentType: "application/yaml"},
+			{Command: testJobPath, OutputContentType: "not a type"},
+			{Command: testJobPath, ExitCodeRules: []*jobmanagerpb.ExitCodeRule{{Codes: []int32{1}}}},
+			{Command: testJobPath, ExitCodeRules: []*jobmanagerpb.ExitCodeRule{{Outcome: jobmanagerpb.Outcome_OUTCOME_WARNING}}},
+			{Command: testJobPath, ExitCodeRules: []*jobmanagerpb.ExitCodeRule{{Codes: []int32{256}, Outcome: jobmanagerpb.Outcome_OUTCOME_WARNING}}},
+			{Command: testJobPath, Shell: "echo hi"},
+			{Args: []string{"hi"}, Shell: "echo"},
+			{Shell: "echo \x00"},
+		} {
+			_, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+			assert.Equal(tt, codes.InvalidArgument, status.Code(err), spec.String())
+		}
+	})
 }
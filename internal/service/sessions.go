@@ -0,0 +1,51 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+)
+
+// Longest session id StartJob accepts
+const maxSessionIDLength = 128
+
+// How long ended sessions are remembered, turning away jobs that try to join them
+const endedSessionMemory = 24 * time.Hour
+
+// Sessions belong to the user that names them, so users can't end each other's
+type sessionKey struct {
+	owner string
+	id    string
+}
+
+// Remembers which sessions were ended (see EndSession). There's nothing to
+// remember about the others: they start with the first job that names them
+type sessionTracker struct {
+	clock clock.Clock
+	lock  sync.Mutex
+	ended map[sessionKey]time.Time
+}
+
+func newSessionTracker() *sessionTracker {
+	return &sessionTracker{clock: clock.Real, ended: map[sessionKey]time.Time{}}
+}
+
+func (t *sessionTracker) hasEnded(owner string, id string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	endedAt, ok := t.ended[sessionKey{owner, id}]
+	return ok && t.clock.Since(endedAt) < endedSessionMemory
+}
+
+func (t *sessionTracker) end(owner string, id string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.clock.Now()
+	for key, endedAt := range t.ended {
+		if now.Sub(endedAt) >= endedSessionMemory {
+			delete(t.ended, key)
+		}
+	}
+	t.ended[sessionKey{owner, id}] = now
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/buildinfo"
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Names of environment variables whose values are masked in snapshots
+var secretEnvName = regexp.MustCompile(`(?i)(SECRET|TOKEN|PASSW(OR)?D|CREDENTIAL|PRIVATE|API_?KEY|ACCESS_?KEY|AUTH)`)
+
+// Record what a job is about to be launched with (see LaunchSnapshot).
+// Whatever can't be found out is left empty rather than failing the job
+func captureSnapshot(command string, env map[string]string, redactions []job.Redaction, now time.Time) *jobmanagerpb.LaunchSnapshot {
+	snapshot := &jobmanagerpb.LaunchSnapshot{
+		CapturedAt:    timestamppb.New(now),
+		Env:           make(map[string]string),
+		ServerVersion: buildinfo.Get().Version,
+	}
+	// The same environment job.New gives the process: ours, then the job's
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		snapshot.Env[name] = value
+	}
+	for name, value := range env {
+		snapshot.Env[name] = value
+	}
+	for name, value := range snapshot.Env {
+		if secretEnvName.MatchString(name) {
+			snapshot.Env[name] = job.DefaultRedactionReplacement
+		} else {
+			snapshot.Env[name] = job.Redact(value, redactions)
+		}
+	}
+
+	// Jobs run in the server's working directory
+	var err error
+	if snapshot.WorkingDirectory, err = os.Getwd(); err != nil {
+		slog.Warn("Failed to get working directory for launch snapshot", "error", err)
+	}
+	if snapshot.BinaryPath, err = filepath.EvalSymlinks(command); err != nil {
+		snapshot.BinaryPath = command
+	}
+	if snapshot.BinarySha256, err = fileSHA256(snapshot.BinaryPath); err != nil {
+		slog.Warn("Failed to hash binary for launch snapshot", "command", command, "error", err)
+	}
+	if snapshot.Hostname, err = os.Hostname(); err != nil {
+		slog.Warn("Failed to get hostname for launch snapshot", "error", err)
+	}
+	var uname syscall.Utsname
+	if err := syscall.Uname(&uname); err == nil {
+		snapshot.KernelRelease = utsString(uname.Release[:])
+	}
+	return snapshot
+}
+
+// Hex encoded SHA-256 of the file's contents
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Utsname fields are NUL terminated
+func utsString(field []int8) string {
+	var b strings.Builder
+	for _, c := range field {
+		if c == 0 {
+			break
+		}
+		b.WriteByte(byte(c))
+	}
+	return b.String()
+}
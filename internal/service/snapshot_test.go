@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLaunchSnapshot(t *testing.T) {
+	ctx := context.Background()
+	hunter2, err := job.NewRedaction(`hunter\d`, "")
+	require.NoError(t, err)
+	jobService := service.NewJobService(&mockUserGetter{user: "alice"}, t.TempDir(),
+		service.WithRedactions([]job.Redaction{hunter2}))
+	start := func(tt *testing.T, capture bool) *jobmanagerpb.JobRecord {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Spec: &jobmanagerpb.JobSpec{
+				Command:            "/bin/true",
+				Env:                map[string]string{"GREETING": "hello", "GITHUB_TOKEN": "abc123", "MOTTO": "my password is hunter2"},
+				CaptureEnvironment: capture,
+			},
+			Force: true,
+		})
+		require.NoError(tt, err)
+		list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+		require.NoError(tt, err)
+		for _, record := range list.Jobs {
+			if record.Id == resp.Id {
+				return record
+			}
+		}
+		tt.Fatal("job isn't listed")
+		return nil
+	}
+
+	t.Run("captured", func(tt *testing.T) {
+		snapshot := start(tt, true).LaunchSnapshot
+		require.NotNil(tt, snapshot)
+		assert.Equal(tt, "hello", snapshot.Env["GREETING"])
+		assert.Equal(tt, job.DefaultRedactionReplacement, snapshot.Env["GITHUB_TOKEN"])
+		assert.Equal(tt, "my password is "+job.DefaultRedactionReplacement, snapshot.Env["MOTTO"])
+		// The server's own environment is in there too
+		assert.Equal(tt, os.Getenv("PATH"), snapshot.Env["PATH"])
+
+		wd, err := os.Getwd()
+		require.NoError(tt, err)
+		assert.Equal(tt, wd, snapshot.WorkingDirectory)
+		binary, err := filepath.EvalSymlinks("/bin/true")
+		require.NoError(tt, err)
+		assert.Equal(tt, binary, snapshot.BinaryPath)
+		data, err := os.ReadFile(binary)
+		require.NoError(tt, err)
+		sum := sha256.Sum256(data)
+		assert.Equal(tt, hex.EncodeToString(sum[:]), snapshot.BinarySha256)
+		assert.NotEmpty(tt, snapshot.Hostname)
+		assert.NotEmpty(tt, snapshot.KernelRelease)
+		assert.NotEmpty(tt, snapshot.ServerVersion)
+		assert.NotNil(tt, snapshot.CapturedAt)
+	})
+
+	t.Run("not-asked-for", func(tt *testing.T) {
+		assert.Nil(tt, start(tt, false).LaunchSnapshot)
+	})
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"golang.org/x/sys/unix"
+	"google.golang.org/protobuf/proto"
+)
+
+// Keeps labels useful for bookkeeping without letting them grow into storage
+const (
+	maxLabels           = 32
+	maxLabelKeyLength   = 63
+	maxLabelValueLength = 255
+)
+
+// Keeps exit code rules to something a person would write
+const maxExitCodeRules = 32
+
+// Longest shell command line a job may have. Far more than anyone types, but
+// scripts belong in files rather than in the job spec
+const maxShellLength = 16 * 1024
+
+// Mutex names are identifiers (ex: "db-migration"), not data
+const maxMutexLength = 128
+
+// Smallest output window a job may ask for. Smaller ones would
+// rotate output segments every few lines
+const minOutputWindowBytes = 4096
+
+// Smallest output segments a job may ask for. Every segment is a file
+// of its own, so tiny ones would flood the output directory
+const (
+	minSegmentBytes    = 4096
+	minSegmentInterval = time.Second
+)
+
+// Content types a job may declare for its output. Only ones clients know
+// how to render, so a typo doesn't leave output unrendered without notice
+var outputContentTypes = []string{"text/plain", "application/json", "application/junit+xml"}
+
+// The job spec of a StartJobRequest. Requests from older clients
+// only have the flat fields, so they're copied into a new spec
+func requestSpec(req *jobmanagerpb.StartJobRequest) *jobmanagerpb.JobSpec {
+	if req.Spec != nil {
+		return req.Spec
+	}
+	return &jobmanagerpb.JobSpec{
+		Command:      req.Command,
+		Args:         req.Args,
+		MaxAttempts:  req.MaxAttempts,
+		Retention:    req.Retention,
+		RuntimeClass: req.RuntimeClass,
+	}
+}
+
+// A copy of 'req' fit for logs, with the values in its spec's environment
+// masked since they're often secrets (ex: API keys). 'spec' finds the spec
+func loggableRequest[T proto.Message](req T, spec func(T) *jobmanagerpb.JobSpec) T {
+	if len(spec(req).GetEnv()) == 0 {
+		return req
+	}
+	masked := proto.Clone(req).(T)
+	env := spec(masked).Env
+	for name := range env {
+		env[name] = job.DefaultRedactionReplacement
+	}
+	return masked
+}
+
+// Checks the parts of a spec that don't depend on server settings
+func validateSpec(spec *jobmanagerpb.JobSpec) error {
+	if spec.Shell != "" {
+		if spec.Command != "" || len(spec.Args) != 0 {
+			return errors.New("shell jobs must not have a command or args")
+		}
+		if len(spec.Shell) > maxShellLength || strings.ContainsRune(spec.Shell, 0) {
+			return fmt.Errorf("shell must be at most %d bytes, without NUL bytes", maxShellLength)
+		}
+	} else if spec.Command == "" {
+		return errors.New("command must not be empty")
+	}
+	if spec.MaxAttempts > maxAttemptsLimit {
+		return fmt.Errorf("max_attempts must not exceed %d", maxAttemptsLimit)
+	}
+	if spec.Stdin && (spec.MaxAttempts > 1 || spec.RequeueOnPreemption) {
+		return errors.New("jobs with stdin can't have retries or requeue_on_preemption")
+	}
+	if spec.Timeout != nil && spec.Timeout.AsDuration() <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if spec.ExpectedRuntime != nil && spec.ExpectedRuntime.AsDuration() <= 0 {
+		return errors.New("expected_runtime must be positive")
+	}
+	if spec.Lease != nil && spec.Lease.AsDuration() <= 0 {
+		return errors.New("lease must be positive")
+	}
+	if spec.OutputWindowBytes != 0 && (spec.OutputWindowBytes < minOutputWindowBytes || spec.OutputWindowBytes > math.MaxInt64) {
+		return fmt.Errorf("output_window_bytes must be 0 (keep everything) or at least %d", minOutputWindowBytes)
+	}
+	if segments := spec.OutputSegments; segments != nil {
+		if segments.MaxBytes != 0 && (segments.MaxBytes < minSegmentBytes || segments.MaxBytes > math.MaxInt64) {
+			return fmt.Errorf("output_segments.max_bytes must be 0 (no limit) or at least %d", minSegmentBytes)
+		}
+		if segments.Interval != nil && (!segments.Interval.IsValid() || segments.Interval.AsDuration() < minSegmentInterval) {
+			return fmt.Errorf("output_segments.interval must be at least %s", minSegmentInterval)
+		}
+	}
+	if spec.OutputContentType != "" {
+		mediaType, _, err := mime.ParseMediaType(spec.OutputContentType)
+		if err != nil || !slices.Contains(outputContentTypes, mediaType) {
+			return fmt.Errorf("output_content_type must be one of %s", strings.Join(outputContentTypes, ", "))
+		}
+	}
+	if len(spec.ExitCodeRules) > maxExitCodeRules {
+		return fmt.Errorf("no more than %d exit code rules are allowed", maxExitCodeRules)
+	}
+	for _, rule := range spec.ExitCodeRules {
+		if _, ok := jobmanagerpb.Outcome_name[int32(rule.Outcome)]; !ok || rule.Outcome == jobmanagerpb.Outcome_OUTCOME_UNSPECIFIED {
+			return errors.New("exit code rules must have an outcome")
+		}
+		if len(rule.Codes) == 0 {
+			return errors.New("exit code rules must have codes")
+		}
+		for _, code := range rule.Codes {
+			if code < 0 || code > 255 {
+				return fmt.Errorf("invalid exit code %d", code)
+			}
+		}
+	}
+	for key, value := range spec.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") || strings.ContainsRune(value, 0) {
+			return fmt.Errorf("invalid environment variable '%s'", key)
+		}
+	}
+	if len(spec.Labels) > maxLabels {
+		return fmt.Errorf("no more than %d labels are allowed", maxLabels)
+	}
+	for key, value := range spec.Labels {
+		if key == "" || len(key) > maxLabelKeyLength || len(value) > maxLabelValueLength {
+			return fmt.Errorf("label keys must be 1-%d bytes and values at most %d bytes", maxLabelKeyLength, maxLabelValueLength)
+		}
+	}
+	if len(spec.Mutex) > maxMutexLength || strings.ContainsRune(spec.Mutex, 0) {
+		return fmt.Errorf("mutex must be at most %d bytes, without NUL bytes", maxMutexLength)
+	}
+	if spec.WaitForMutex && spec.Mutex == "" {
+		return errors.New("wait_for_mutex requires a mutex")
+	}
+	for _, scope := range spec.TokenScopes {
+		if _, ok := jobmanagerpb.JobTokenScope_name[int32(scope)]; !ok || scope == jobmanagerpb.JobTokenScope_JOB_TOKEN_SCOPE_UNSPECIFIED {
+			return fmt.Errorf("invalid token scope %d", scope)
+		}
+	}
+	return validateScheduling(spec.Scheduling)
+}
+
+// Jobs may only make themselves nicer than the server
+func validateScheduling(sched *jobmanagerpb.Scheduling) error {
+	if sched == nil {
+		return nil
+	}
+	if sched.Nice != nil && (*sched.Nice < 0 || *sched.Nice > job.MaxNice) {
+		return fmt.Errorf("nice must be between 0 and %d", job.MaxNice)
+	}
+	if _, ok := jobmanagerpb.IOClass_name[int32(sched.IoClass)]; !ok {
+		return fmt.Errorf("unknown io_class %d", sched.IoClass)
+	}
+	if sched.IoPriority > job.MaxIOPriority {
+		return fmt.Errorf("io_priority must not exceed %d", job.MaxIOPriority)
+	}
+	if len(sched.Cpus) == 0 {
+		return nil
+	}
+	// The job may only use CPUs we're allowed to use ourselves
+	var allowed unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &allowed); err != nil {
+		return fmt.Errorf("error checking cpus: %w", err)
+	}
+	for _, cpu := range sched.Cpus {
+		if cpu >= uint32(len(allowed)*64) || !allowed.IsSet(int(cpu)) {
+			return fmt.Errorf("cpu %d isn't available", cpu)
+		}
+	}
+	return nil
+}
+
+// The spec's environment in the form exec expects, sorted so jobs are reproducible
+// What the job runs: its command and args, or the shell running its command line
+func specCommand(spec *jobmanagerpb.JobSpec) (string, []string) {
+	if spec.Shell != "" {
+		return job.ShellCommand(spec.Shell)
+	}
+	return spec.Command, spec.Args
+}
+
+func specEnv(spec *jobmanagerpb.JobSpec) []string {
+	env := make([]string, 0, len(spec.Env))
+	for key, value := range spec.Env {
+		env = append(env, key+"="+value)
+	}
+	slices.Sort(env)
+	return env
+}
+
+// Apply the spec's timeout on top of the runtime class. A job
+// may shorten the class's timeout but never extend it
+func specLimits(spec *jobmanagerpb.JobSpec, class job.Limits) job.Limits {
+	if spec.Timeout == nil {
+		return class
+	}
+	timeout := spec.Timeout.AsDuration()
+	if class.Timeout == 0 || timeout < class.Timeout {
+		class.Timeout = timeout
+	}
+	return class
+}
+
+// The spec's scheduling settings in the form the job package expects
+func specScheduling(spec *jobmanagerpb.JobSpec) job.Scheduling {
+	sched := spec.Scheduling
+	if sched == nil {
+		return job.Scheduling{}
+	}
+	var out job.Scheduling
+	if sched.Nice != nil {
+		nice := int(*sched.Nice)
+		out.Nice = &nice
+	}
+	switch sched.IoClass {
+	case jobmanagerpb.IOClass_IO_CLASS_BEST_EFFORT:
+		out.IOClass = job.IOClassBestEffort
+		out.IOPriority = int(sched.IoPriority)
+	case jobmanagerpb.IOClass_IO_CLASS_IDLE:
+		out.IOClass = job.IOClassIdle
+	}
+	for _, cpu := range sched.Cpus {
+		out.CPUs = append(out.CPUs, int(cpu))
+	}
+	return out
+}
+
+// The spec's output segment policy in the form the job package expects
+func specSegments(spec *jobmanagerpb.JobSpec) job.SegmentPolicy {
+	segments := spec.OutputSegments
+	if segments == nil {
+		return job.SegmentPolicy{}
+	}
+	return job.SegmentPolicy{
+		MaxBytes: int64(segments.MaxBytes),
+		Interval: segments.Interval.AsDuration(),
+	}
+}
+
+// How the spec's rules classify an attempt that exited with 'status'
+func specOutcome(spec *jobmanagerpb.JobSpec, status job.Status) jobmanagerpb.Outcome {
+	if status.CurrentState != job.JobstatusComplete || status.ReturnCode == nil {
+		// Running, stopped, or killed by a signal
+		return jobmanagerpb.Outcome_OUTCOME_UNSPECIFIED
+	}
+	code := int32(*status.ReturnCode)
+	for _, rule := range spec.ExitCodeRules {
+		if slices.Contains(rule.Codes, code) {
+			return rule.Outcome
+		}
+	}
+	if code == 0 {
+		return jobmanagerpb.Outcome_OUTCOME_SUCCESS
+	}
+	return jobmanagerpb.Outcome_OUTCOME_RETRYABLE
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggableRequest(t *testing.T) {
+	req := &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{
+		Command: "/bin/true",
+		Env:     map[string]string{"API_KEY": "hunter2", "HOME": "/root"},
+	}}
+	logged := loggableRequest(req, (*jobmanagerpb.StartJobRequest).GetSpec)
+	assert.Equal(t, map[string]string{"API_KEY": job.DefaultRedactionReplacement, "HOME": job.DefaultRedactionReplacement}, logged.Spec.Env)
+	assert.Equal(t, "/bin/true", logged.Spec.Command)
+	// The request itself is left alone
+	assert.Equal(t, "hunter2", req.Spec.Env["API_KEY"])
+
+	// Nothing to mask
+	flat := &jobmanagerpb.StartJobRequest{Command: "/bin/true"}
+	assert.Same(t, flat, loggableRequest(flat, (*jobmanagerpb.StartJobRequest).GetSpec))
+}
@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestStateReason(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	shell := func(script string) *jobmanagerpb.JobSpec {
+		return &jobmanagerpb.JobSpec{Command: "/bin/sh", Args: []string{"sh", "-c", script}}
+	}
+	start := func(tt *testing.T, spec *jobmanagerpb.JobSpec) []byte {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+	wait := func(tt *testing.T, id []byte) *jobmanagerpb.GetStatusResponse {
+		resp, err := jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: id})
+		require.NoError(tt, err)
+		return resp
+	}
+
+	t.Run("completed", func(tt *testing.T) {
+		resp := wait(tt, start(tt, shell("true")))
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_COMPLETED, resp.StateReason)
+		assert.Equal(tt, "exited with code 0", resp.StateMessage)
+	})
+
+	t.Run("failed", func(tt *testing.T) {
+		resp := wait(tt, start(tt, shell("exit 3")))
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_FAILED, resp.StateReason)
+		assert.Equal(tt, "exited with code 3", resp.StateMessage)
+	})
+
+	t.Run("running", func(tt *testing.T) {
+		id := start(tt, shell("sleep 10"))
+		resp, err := jobService.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_UNSPECIFIED, resp.StateReason)
+		assert.Empty(tt, resp.StateMessage)
+
+		_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: id})
+		require.NoError(tt, err)
+		resp = wait(tt, id)
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_USER_STOPPED, resp.StateReason)
+		assert.Equal(tt, "stopped by someuser", resp.StateMessage)
+	})
+
+	t.Run("timeout", func(tt *testing.T) {
+		spec := shell("sleep 10")
+		spec.Timeout = durationpb.New(100 * time.Millisecond)
+		resp := wait(tt, start(tt, spec))
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_TIMEOUT, resp.StateReason)
+	})
+
+	t.Run("record", func(tt *testing.T) {
+		id := start(tt, shell("exit 1"))
+		wait(tt, id)
+		resp, err := jobService.DescribeJob(ctx, &jobmanagerpb.DescribeJobRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_FAILED, resp.Record.StateReason)
+		assert.Equal(tt, resp.Status.StateMessage, resp.Record.StateMessage)
+	})
+
+	t.Run("server shutdown", func(tt *testing.T) {
+		id := start(tt, shell("trap 'exit 0' TERM; while true; do sleep 0.05; done"))
+		require.NoError(tt, jobService.Drain(ctx, service.DrainPolicy{Timeout: time.Second, StopGrace: time.Second}))
+		resp := wait(tt, id)
+		assert.Equal(tt, jobmanagerpb.StateReason_STATE_REASON_SERVER_SHUTDOWN, resp.StateReason)
+	})
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// Runs kept per command or spec. Older ones are forgotten
+	maxStatsRuns = 256
+	// Commands and specs runs are kept for. The least recently run are
+	// forgotten first
+	maxStatsKeys = 10000
+)
+
+// How a finished attempt went
+type runSample struct {
+	duration time.Duration
+	// Nil if the attempt was killed by a signal
+	exitCode    *int
+	outputBytes int64
+}
+
+// Which runs a set of stats covers. Runs are only ever grouped with
+// the same owner's, so users can't learn about each other's jobs
+type statsKey struct {
+	owner string
+	// One of these is set
+	specHash string
+	command  string
+}
+
+type runHistory struct {
+	// Oldest first
+	runs []runSample
+	// When the history was last added to, relative to the others
+	updated uint64
+}
+
+// Keeps the recent runs of each owner's jobs, both by spec and by command,
+// for GetJobStats and for guessing how long running jobs have left (see Drain).
+// Unlike the jobs themselves, runs are kept after the jobs expire
+type statsTracker struct {
+	lock    sync.Mutex
+	history map[statsKey]*runHistory
+	updates uint64
+}
+
+func newStatsTracker() *statsTracker {
+	return &statsTracker{history: map[statsKey]*runHistory{}}
+}
+
+// Record an attempt of a job that has exited. Attempts that didn't
+// get to run their course (ex: stopped or preempted) are skipped
+func (t *statsTracker) attemptFinished(owner, specHash, command string, status job.Status, outputBytes int64) {
+	switch status.ExitReason {
+	case job.ExitReasonNone, job.ExitReasonStopped, job.ExitReasonPreempted, job.ExitReasonUnknown:
+		return
+	}
+	sample := runSample{duration: status.Duration, exitCode: status.ReturnCode, outputBytes: outputBytes}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.add(statsKey{owner: owner, specHash: specHash}, sample)
+	t.add(statsKey{owner: owner, command: command}, sample)
+}
+
+// Caller must hold the lock
+func (t *statsTracker) add(key statsKey, sample runSample) {
+	history, ok := t.history[key]
+	if !ok {
+		if len(t.history) >= maxStatsKeys {
+			t.evict()
+		}
+		history = &runHistory{}
+		t.history[key] = history
+	}
+	t.updates++
+	history.updated = t.updates
+	history.runs = append(history.runs, sample)
+	if len(history.runs) > maxStatsRuns {
+		history.runs = slices.Delete(history.runs, 0, len(history.runs)-maxStatsRuns)
+	}
+}
+
+// Forget the least recently run key. Caller must hold the lock
+func (t *statsTracker) evict() {
+	var oldest statsKey
+	var oldestUpdate uint64
+	for key, history := range t.history {
+		if oldestUpdate == 0 || history.updated < oldestUpdate {
+			oldest, oldestUpdate = key, history.updated
+		}
+	}
+	delete(t.history, oldest)
+}
+
+// Snapshot of the runs recorded for 'key', oldest first
+func (t *statsTracker) runs(key statsKey) []runSample {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	history, ok := t.history[key]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(history.runs)
+}
+
+// Median runtime of the owner's recent runs of identical jobs.
+// False if there are none
+func (t *statsTracker) expectedRuntime(owner, specHash string) (time.Duration, bool) {
+	runs := t.runs(statsKey{owner: owner, specHash: specHash})
+	if len(runs) == 0 {
+		return 0, false
+	}
+	durations := sortedField(runs, func(run runSample) time.Duration { return run.duration })
+	return percentile(durations, 50), true
+}
+
+// What runs of the job are grouped under by command. Shell jobs run the
+// shell, so their command line says more
+func statsCommand(spec *jobmanagerpb.JobSpec) string {
+	if spec.Shell != "" {
+		return spec.Shell
+	}
+	return spec.Command
+}
+
+// Bytes the attempt kept on disk across its output streams
+func attemptOutputBytes(a *attempt) int64 {
+	outputs, err := a.job.Outputs()
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, output := range outputs {
+		total += output.Size
+	}
+	return total
+}
+
+func statsToProto(runs []runSample) *jobmanagerpb.GetJobStatsResponse {
+	out := &jobmanagerpb.GetJobStatsResponse{
+		Runs:      uint32(len(runs)),
+		ExitCodes: map[int32]uint32{},
+	}
+	if len(runs) == 0 {
+		return out
+	}
+	for _, run := range runs {
+		if run.exitCode == nil {
+			out.Signaled++
+		} else {
+			out.ExitCodes[int32(*run.exitCode)]++
+		}
+	}
+
+	durations := sortedField(runs, func(run runSample) time.Duration { return run.duration })
+	out.Duration = &jobmanagerpb.DurationDistribution{
+		Min:    durationpb.New(durations[0]),
+		Median: durationpb.New(percentile(durations, 50)),
+		P90:    durationpb.New(percentile(durations, 90)),
+		Max:    durationpb.New(durations[len(durations)-1]),
+		Mean:   durationpb.New(mean(durations)),
+	}
+	sizes := sortedField(runs, func(run runSample) uint64 { return uint64(max(run.outputBytes, 0)) })
+	out.OutputBytes = &jobmanagerpb.SizeDistribution{
+		Min:    sizes[0],
+		Median: percentile(sizes, 50),
+		P90:    percentile(sizes, 90),
+		Max:    sizes[len(sizes)-1],
+		Mean:   mean(sizes),
+	}
+	return out
+}
+
+func sortedField[T cmp.Ordered](runs []runSample, field func(runSample) T) []T {
+	out := make([]T, 0, len(runs))
+	for _, run := range runs {
+		out = append(out, field(run))
+	}
+	slices.Sort(out)
+	return out
+}
+
+// The 'p'th percentile of 'sorted', rounding down to the run below it.
+// 'sorted' must not be empty
+func percentile[T any](sorted []T, p int) T {
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+func mean[T time.Duration | uint64](values []T) T {
+	var total T
+	for _, value := range values {
+		total += value
+	}
+	return total / T(len(values))
+}
@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestJobStats(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserGetter{user: "alice"}
+	jobService := service.NewJobService(users, t.TempDir())
+	run := func(tt *testing.T, spec *jobmanagerpb.JobSpec, stop bool) []byte {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec, Force: true})
+		require.NoError(tt, err)
+		if stop {
+			_, err = jobService.StopJob(ctx, &jobmanagerpb.StopJobRequest{JobId: resp.JobId})
+			require.NoError(tt, err)
+		}
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+
+	echo := &jobmanagerpb.JobSpec{Command: "/bin/echo", Args: []string{"echo", "hello"}}
+	id := run(t, echo, false)
+	run(t, echo, false)
+	run(t, &jobmanagerpb.JobSpec{Command: "/bin/echo", Args: []string{"echo", "other", "args"}}, false)
+	run(t, &jobmanagerpb.JobSpec{Shell: "exit 3"}, false)
+	run(t, &jobmanagerpb.JobSpec{Shell: "kill -9 $$"}, false)
+	// Doesn't get to run its course, so it doesn't count
+	run(t, &jobmanagerpb.JobSpec{Shell: "sleep 10"}, true)
+
+	t.Run("by-job", func(tt *testing.T) {
+		resp, err := jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{JobId: id})
+		require.NoError(tt, err)
+		assert.Equal(tt, uint32(2), resp.Runs)
+		assert.Equal(tt, map[int32]uint32{0: 2}, resp.ExitCodes)
+		assert.Equal(tt, uint64(len("hello\n")), resp.OutputBytes.Median)
+		assert.Positive(tt, resp.Duration.Median.AsDuration())
+		assert.LessOrEqual(tt, resp.Duration.Min.AsDuration(), resp.Duration.Max.AsDuration())
+	})
+
+	t.Run("by-command", func(tt *testing.T) {
+		resp, err := jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{Command: "/bin/echo"})
+		require.NoError(tt, err)
+		assert.Equal(tt, uint32(3), resp.Runs)
+		assert.Equal(tt, uint64(len("other args\n")), resp.OutputBytes.Max)
+
+		resp, err = jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{Command: "exit 3"})
+		require.NoError(tt, err)
+		assert.Equal(tt, map[int32]uint32{3: 1}, resp.ExitCodes)
+		resp, err = jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{Command: "kill -9 $$"})
+		require.NoError(tt, err)
+		assert.Equal(tt, uint32(1), resp.Signaled)
+		resp, err = jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{Command: "sleep 10"})
+		require.NoError(tt, err)
+		assert.Zero(tt, resp.Runs)
+		assert.Nil(tt, resp.Duration)
+	})
+
+	t.Run("other-owner", func(tt *testing.T) {
+		users.user = "bob"
+		defer func() { users.user = "alice" }()
+		resp, err := jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{Command: "/bin/echo"})
+		require.NoError(tt, err)
+		assert.Zero(tt, resp.Runs)
+		_, err = jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{JobId: id})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		_, err := jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{JobId: id, Command: "/bin/echo"})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+		_, err = jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	// Stats outlive the jobs they came from
+	t.Run("after-expiry", func(tt *testing.T) {
+		jobService.CollectGarbage(time.Now().Add(365 * 24 * time.Hour))
+		resp, err := jobService.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{Command: "/bin/echo"})
+		require.NoError(tt, err)
+		assert.Equal(tt, uint32(3), resp.Runs)
+	})
+}
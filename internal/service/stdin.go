@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (j *Jobby) WriteJobStdin(srv jobmanagerpb.JobManager_WriteJobStdinServer) error {
+	first, err := srv.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "Must name the job in the first message")
+	}
+	if err != nil {
+		return err
+	}
+	user := j.userGetter.GetUserContext(srv.Context())
+	// Not the request, which may carry a lot of data
+	subLogger := slog.With("user", user, "job-id", first.Id)
+	subLogger.Info("Handling 'WriteJobStdin' request")
+
+	jobData, st := j.getJob(srv.Context(), first)
+	if st != nil {
+		return st.Err()
+	}
+	stdin, err := jobData.latest().job.Stdin()
+	switch {
+	case errors.Is(err, job.ErrNoStdin):
+		return status.Error(codes.FailedPrecondition, "Job wasn't started with stdin")
+	case errors.Is(err, job.ErrStdinTaken):
+		return status.Error(codes.FailedPrecondition, "Job's stdin is already being written, or was closed")
+	case err != nil:
+		subLogger.Error("Error opening job stdin", "error", err)
+		return status.Error(codes.Internal, "Error opening job stdin")
+	}
+	// However the stream ends, the job sees EOF. Closing also unblocks
+	// a write the job isn't reading if the caller goes away
+	defer stdin.Close()
+	stop := context.AfterFunc(srv.Context(), func() { _ = stdin.Close() })
+	defer stop()
+
+	var written uint64
+	data := first.Data
+	for {
+		n, err := stdin.Write(data)
+		written += uint64(n)
+		if err != nil {
+			if srv.Context().Err() != nil {
+				return status.FromContextError(srv.Context().Err()).Err()
+			}
+			// Exited (or closed its stdin) without reading everything
+			subLogger.Info("Job stopped reading stdin", "bytes", written, "error", err)
+			break
+		}
+
+		msg, err := srv.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data = msg.Data
+	}
+	return srv.SendAndClose(&jobmanagerpb.WriteJobStdinResponse{BytesWritten: written})
+}
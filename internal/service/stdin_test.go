@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/internal/testutils"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWriteJobStdin(t *testing.T) {
+	ctx := context.Background()
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	srv := testutils.GrpcLocalServer{}
+	server := grpc.NewServer()
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+	jobClient := jobmanagerpb.NewJobManagerClient(srv.Conn())
+
+	start := func(tt *testing.T, spec *jobmanagerpb.JobSpec) []byte {
+		resp, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec})
+		require.NoError(tt, err)
+		return resp.JobId
+	}
+	// Sends each chunk in a message of its own
+	upload := func(id []byte, chunks ...string) (*jobmanagerpb.WriteJobStdinResponse, error) {
+		stream, err := jobClient.WriteJobStdin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&jobmanagerpb.WriteJobStdinRequest{JobId: id}))
+		for _, chunk := range chunks {
+			if err := stream.Send(&jobmanagerpb.WriteJobStdinRequest{Data: []byte(chunk)}); err != nil {
+				break
+			}
+		}
+		return stream.CloseAndRecv()
+	}
+	stdout := func(id []byte) string {
+		outputClient, err := jobClient.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{
+			JobId: id,
+			Type:  jobmanagerpb.OutputType_OUTPUT_TYPE_STDOUT,
+		})
+		require.NoError(t, err)
+		var output bytes.Buffer
+		for {
+			msg, err := outputClient.Recv()
+			if err != nil {
+				require.ErrorIs(t, err, io.EOF)
+				return output.String()
+			}
+			output.Write(msg.Data)
+		}
+	}
+
+	t.Run("piped", func(tt *testing.T) {
+		id := start(tt, &jobmanagerpb.JobSpec{Command: "/bin/cat", Args: []string{"cat"}, Stdin: true})
+		// More than a pipe holds, so the job has to keep up
+		big := strings.Repeat("x", 256*1024) + "\n"
+		resp, err := upload(id, "hello\n", big)
+		require.NoError(tt, err)
+		assert.Equal(tt, uint64(len("hello\n")+len(big)), resp.BytesWritten)
+		// cat exits once it sees EOF
+		assert.Equal(tt, "hello\n"+big, stdout(id))
+
+		_, err = upload(id, "again\n")
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("exits early", func(tt *testing.T) {
+		id := start(tt, &jobmanagerpb.JobSpec{Command: "/usr/bin/head", Args: []string{"head", "-c", "3"}, Stdin: true})
+		resp, err := upload(id, "abc", strings.Repeat("y", 1024*1024))
+		require.NoError(tt, err)
+		assert.Less(tt, resp.BytesWritten, uint64(1024*1024+3))
+		assert.Equal(tt, "abc", stdout(id))
+	})
+
+	t.Run("no stdin", func(tt *testing.T) {
+		id := start(tt, &jobmanagerpb.JobSpec{Command: "/bin/cat", Args: []string{"cat"}})
+		_, err := upload(id, "hello\n")
+		assert.Equal(tt, codes.FailedPrecondition, status.Code(err))
+		// Read /dev/null
+		assert.Empty(tt, stdout(id))
+	})
+
+	t.Run("retries", func(tt *testing.T) {
+		_, err := jobClient.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{
+			Command:     "/bin/cat",
+			Args:        []string{"cat"},
+			Stdin:       true,
+			MaxAttempts: 2,
+		}})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("unnamed", func(tt *testing.T) {
+		stream, err := jobClient.WriteJobStdin(ctx)
+		require.NoError(tt, err)
+		_, err = stream.CloseAndRecv()
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+}
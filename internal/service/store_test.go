@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	metadata, err := store.OpenBolt(filepath.Join(t.TempDir(), "jobby.db"))
+	require.NoError(t, err)
+	defer metadata.Close()
+	jobService := service.NewJobService(&mockUserGetter{user: "alice"}, t.TempDir(),
+		service.WithStore(metadata),
+		service.WithRetention(service.RetentionLimits{DefaultTTL: time.Hour}),
+	)
+
+	resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+		Spec: &jobmanagerpb.JobSpec{Command: "/bin/echo", Args: []string{"echo", "hello"}},
+	})
+	require.NoError(t, err)
+	_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+	require.NoError(t, err)
+	id, err := uuid.FromBytes(resp.JobId)
+	require.NoError(t, err)
+
+	// Written once the job finished, along with its events
+	require.Eventually(t, func() bool {
+		stored, err := metadata.GetJob(ctx, id)
+		return err == nil && stored.Record.EndTime != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	stored, err := metadata.GetJob(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", stored.Owner)
+	assert.Equal(t, jobmanagerpb.Status_STATUS_COMPLETE, stored.Record.Status)
+	events, err := metadata.ListEvents(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_CREATED.String(), events[0].Type)
+	assert.Equal(t, jobmanagerpb.JobEventType_JOB_EVENT_TYPE_EXITED.String(), events[2].Type)
+
+	// Goes along with the job
+	assert.Equal(t, 1, jobService.CollectGarbage(time.Now().Add(2*time.Hour)))
+	_, err = metadata.GetJob(ctx, id)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestCollectStore(t *testing.T) {
+	ctx := context.Background()
+	metadata, err := store.OpenBolt(filepath.Join(t.TempDir(), "jobby.db"))
+	require.NoError(t, err)
+	defer metadata.Close()
+	jobService := service.NewJobService(&mockUserGetter{user: "alice"}, t.TempDir(),
+		service.WithStore(metadata),
+		service.WithNode("jobby-1"),
+		service.WithRetention(service.RetentionLimits{DefaultTTL: time.Hour, AllowKeepForever: true}),
+	)
+	now := time.Now()
+	ended := timestamppb.New(now.Add(-2 * time.Hour))
+	put := func(node string, record *jobmanagerpb.JobRecord) uuid.UUID {
+		id := uuid.New()
+		require.NoError(t, metadata.PutJob(ctx, store.Job{ID: id, Owner: "alice", Node: node, Record: record}))
+		require.NoError(t, metadata.AppendEvent(ctx, store.Event{JobID: id, Owner: "alice", Type: "JOB_EVENT_TYPE_CREATED", Time: now}))
+		return id
+	}
+	expired := put("jobby-2", &jobmanagerpb.JobRecord{EndTime: ended})
+	running := put("jobby-2", &jobmanagerpb.JobRecord{})
+	longer := put("jobby-2", &jobmanagerpb.JobRecord{EndTime: ended, Spec: &jobmanagerpb.JobSpec{
+		Retention: &jobmanagerpb.RetentionPolicy{Policy: &jobmanagerpb.RetentionPolicy_Ttl{Ttl: durationpb.New(3 * time.Hour)}},
+	}})
+	forever := put("jobby-2", &jobmanagerpb.JobRecord{EndTime: ended, Spec: &jobmanagerpb.JobSpec{
+		Retention: &jobmanagerpb.RetentionPolicy{Policy: &jobmanagerpb.RetentionPolicy_KeepForever{KeepForever: true}},
+	}})
+	// Collected by its own server
+	local := put("jobby-1", &jobmanagerpb.JobRecord{EndTime: ended})
+
+	removed, err := jobService.CollectStore(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, err = metadata.GetJob(ctx, expired)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	events, err := metadata.ListEvents(ctx, expired)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	for _, id := range []uuid.UUID{running, longer, forever, local} {
+		_, err = metadata.GetJob(ctx, id)
+		assert.NoError(t, err)
+	}
+}
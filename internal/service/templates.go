@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+
+	"github.com/gopheryan/jobby/internal/templates"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Label put on jobs started from a template, naming it
+const templateLabel = "template"
+
+// The template 'ref' names, filled in
+func (j *Jobby) renderTemplate(ref *jobmanagerpb.TemplateRef) (*templates.Template, string, []string, map[string]string, *status.Status) {
+	tmpl, ok := j.templates[ref.GetName()]
+	if !ok {
+		return nil, "", nil, nil, status.Newf(codes.NotFound, "Template '%s' not found", ref.GetName())
+	}
+	command, args, params, err := tmpl.Render(ref.GetParams())
+	if err != nil {
+		return nil, "", nil, nil, status.Newf(codes.InvalidArgument, "Invalid parameters for template '%s':\n%s", tmpl.Name, err)
+	}
+	return tmpl, command, args, params, nil
+}
+
+// The spec to run. Specs naming a template get its command and args in
+// place of the template. Others are returned as they are
+func (j *Jobby) renderSpec(spec *jobmanagerpb.JobSpec) (*jobmanagerpb.JobSpec, *status.Status) {
+	if spec.Template == nil {
+		return spec, nil
+	}
+	if spec.Command != "" || len(spec.Args) != 0 || spec.Shell != "" {
+		return nil, status.New(codes.InvalidArgument, "template jobs must not have a command, args or shell")
+	}
+	_, command, args, _, st := j.renderTemplate(spec.Template)
+	if st != nil {
+		return nil, st
+	}
+	rendered := proto.Clone(spec).(*jobmanagerpb.JobSpec)
+	rendered.Template = nil
+	rendered.Command, rendered.Args = command, args
+	rendered.Labels = maps.Clone(spec.Labels)
+	if rendered.Labels == nil {
+		rendered.Labels = map[string]string{}
+	}
+	rendered.Labels[templateLabel] = spec.Template.Name
+	return rendered, nil
+}
+
+// Checks 'rendered', the spec 'spec' rendered into, against the policy.
+// Specs without a template were checked in full by the policy's interceptor
+func (j *Jobby) checkRendered(owner string, spec *jobmanagerpb.JobSpec, rendered *jobmanagerpb.JobSpec) error {
+	if j.policy == nil || spec.Template == nil {
+		return nil
+	}
+	return j.policy.CheckRendered(owner, rendered, spec.Template)
+}
+
+func (j *Jobby) PreviewTemplate(ctx context.Context, req *jobmanagerpb.PreviewTemplateRequest) (*jobmanagerpb.PreviewTemplateResponse, error) {
+	slog.Info("Handling 'PreviewTemplate' request", "user", j.userGetter.GetUserContext(ctx), "request", req)
+	tmpl, command, args, params, st := j.renderTemplate(req.Template)
+	if st != nil {
+		return nil, st.Err()
+	}
+	return &jobmanagerpb.PreviewTemplateResponse{
+		Command:     command,
+		Args:        args,
+		Params:      params,
+		Description: tmpl.Description,
+	}, nil
+}
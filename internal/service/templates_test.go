@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/policy"
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/internal/templates"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTemplates(t *testing.T) {
+	ctx := context.Background()
+	keep := "7"
+	echo, err := templates.New(templates.Template{
+		Name:        "echo",
+		Description: "Say something",
+		Command:     "/bin/echo",
+		Args:        []string{"{{text}}", "--keep={{keep}}"},
+		Params: []templates.Param{
+			{Name: "text", MaxLength: 32},
+			{Name: "keep", Type: templates.TypeInt, Default: &keep},
+		},
+	})
+	require.NoError(t, err)
+	jobService := service.NewJobService(&mockUserGetter{user: "alice"}, t.TempDir(),
+		service.WithTemplates(map[string]*templates.Template{"echo": echo}))
+	ref := &jobmanagerpb.TemplateRef{Name: "echo", Params: map[string]string{"text": "hello world"}}
+
+	t.Run("preview", func(tt *testing.T) {
+		resp, err := jobService.PreviewTemplate(ctx, &jobmanagerpb.PreviewTemplateRequest{Template: ref})
+		require.NoError(tt, err)
+		assert.Equal(tt, "/bin/echo", resp.Command)
+		assert.Equal(tt, []string{"hello world", "--keep=7"}, resp.Args)
+		assert.Equal(tt, map[string]string{"text": "hello world", "keep": "7"}, resp.Params)
+		assert.Equal(tt, "Say something", resp.Description)
+	})
+
+	t.Run("start", func(tt *testing.T) {
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Template: ref}})
+		require.NoError(tt, err)
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+
+		list, err := jobService.ListJobs(ctx, &jobmanagerpb.ListJobsRequest{})
+		require.NoError(tt, err)
+		require.Len(tt, list.Jobs, 1)
+		spec := list.Jobs[0].Spec
+		assert.Equal(tt, "/bin/echo", spec.Command)
+		assert.Equal(tt, []string{"hello world", "--keep=7"}, spec.Args)
+		assert.Nil(tt, spec.Template)
+		assert.Equal(tt, map[string]string{"template": "echo"}, spec.Labels)
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		_, err := jobService.PreviewTemplate(ctx, &jobmanagerpb.PreviewTemplateRequest{Template: &jobmanagerpb.TemplateRef{Name: "rm"}})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+
+		_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{
+			Template: &jobmanagerpb.TemplateRef{Name: "echo", Params: map[string]string{"keep": "a week"}},
+		}})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+		assert.Equal(tt, "Invalid parameters for template 'echo':\n"+
+			`parameter 'keep' must be an integer, got "a week"`+"\n"+
+			"parameter 'text' is required", status.Convert(err).Message())
+
+		_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Template: ref, Command: "/bin/sh"}})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("policy", func(tt *testing.T) {
+		// Template jobs are checked as rendered, not just as requested
+		noEcho, err := policy.NewRule("no-echo", "", `command != "/bin/echo" || params["text"] == "hi"`)
+		require.NoError(tt, err)
+		metadata, err := store.OpenBolt(filepath.Join(tt.TempDir(), "jobby.db"))
+		require.NoError(tt, err)
+		defer metadata.Close()
+		jobService := service.NewJobService(&mockUserGetter{user: "alice"}, tt.TempDir(),
+			service.WithTemplates(map[string]*templates.Template{"echo": echo}),
+			service.WithPolicy(policy.New(noEcho)),
+			service.WithStore(metadata))
+
+		_, err = jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{Template: ref}})
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+		_, err = jobService.PutSchedule(ctx, &jobmanagerpb.PutScheduleRequest{Schedule: &jobmanagerpb.Schedule{
+			Name: "nightly",
+			Cron: "0 0 * * *",
+			Spec: &jobmanagerpb.JobSpec{Template: ref},
+		}})
+		assert.Equal(tt, codes.PermissionDenied, status.Code(err))
+
+		resp, err := jobService.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: &jobmanagerpb.JobSpec{
+			Template: &jobmanagerpb.TemplateRef{Name: "echo", Params: map[string]string{"text": "hi"}},
+		}})
+		require.NoError(tt, err)
+		_, err = jobService.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// OutputRateLimits caps how fast job output is streamed to clients, in bytes per second.
+// Zero leaves the corresponding scope unlimited
+type OutputRateLimits struct {
+	// Applied to each GetJobOutput stream on its own
+	PerStream int
+	// Shared by all of a user's streams
+	PerUser int
+	// Shared by every stream on the server
+	Global int
+}
+
+// Limiters shared between streams
+type throttler struct {
+	limits OutputRateLimits
+	global *rate.Limiter
+	// used as: map[string]*rate.Limiter
+	users sync.Map
+}
+
+func newThrottler(limits OutputRateLimits) *throttler {
+	return &throttler{
+		limits: limits,
+		global: newLimiter(limits.Global),
+	}
+}
+
+// Nil when unlimited.
+// Allows up to a second's worth of data in a single burst
+func newLimiter(bytesPerSec int) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), bytesPerSec)
+}
+
+// Limiters that apply to a new stream owned by 'user'
+func (t *throttler) forStream(user string) []*rate.Limiter {
+	var limiters []*rate.Limiter
+	if l := newLimiter(t.limits.PerStream); l != nil {
+		limiters = append(limiters, l)
+	}
+	if t.limits.PerUser > 0 {
+		l, _ := t.users.LoadOrStore(user, newLimiter(t.limits.PerUser))
+		limiters = append(limiters, l.(*rate.Limiter))
+	}
+	if t.global != nil {
+		limiters = append(limiters, t.global)
+	}
+	return limiters
+}
+
+// Blocks until every limiter allows 'n' more bytes
+func waitForBytes(ctx context.Context, limiters []*rate.Limiter, n int) error {
+	for _, l := range limiters {
+		// WaitN rejects requests larger than the burst,
+		// so large messages are paid for in pieces
+		for remaining := n; remaining > 0; {
+			take := min(remaining, l.Burst())
+			if err := l.WaitN(ctx, take); err != nil {
+				return err
+			}
+			remaining -= take
+		}
+	}
+	return nil
+}
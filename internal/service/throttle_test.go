@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottler(t *testing.T) {
+	t.Run("unlimited", func(tt *testing.T) {
+		assert.Empty(tt, newThrottler(OutputRateLimits{}).forStream("someuser"))
+	})
+
+	t.Run("scopes", func(tt *testing.T) {
+		throttle := newThrottler(OutputRateLimits{PerStream: 10, PerUser: 20, Global: 30})
+
+		first := throttle.forStream("someuser")
+		second := throttle.forStream("someuser")
+		other := throttle.forStream("anotheruser")
+		require.Len(tt, first, 3)
+
+		// Every stream gets its own limiter
+		assert.NotSame(tt, first[0], second[0])
+		// Streams of the same user share one
+		assert.Same(tt, first[1], second[1])
+		assert.NotSame(tt, first[1], other[1])
+		// Everybody shares the global limiter
+		assert.Same(tt, first[2], other[2])
+	})
+
+	t.Run("wait", func(tt *testing.T) {
+		limiters := newThrottler(OutputRateLimits{PerStream: 10000}).forStream("someuser")
+
+		// The first second's worth is allowed right away. Anything
+		// beyond that (even within a single message) has to wait
+		start := time.Now()
+		require.NoError(tt, waitForBytes(context.Background(), limiters, 15000))
+		assert.GreaterOrEqual(tt, time.Since(start), 400*time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Error(tt, waitForBytes(ctx, limiters, 15000))
+	})
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gopheryan/jobby/job"
+)
+
+// Partial lines longer than this are passed along as-is
+// rather than held in memory waiting for a newline
+const maxLineLength = 64 * 1024
+
+// Rewrites job output on its way to the client
+type outputTransform interface {
+	// Takes newly read output and returns whatever is ready to be sent
+	Transform(data []byte) []byte
+	// Whether output is being held back waiting for more (ex: a partial line)
+	Holding() bool
+	// Returns anything held back. Called once the output is exhausted
+	// or output has been held for too long
+	Flush() []byte
+}
+
+// Only sends complete lines
+type lineBuffer struct {
+	// Trailing output that doesn't end in a newline yet
+	partial []byte
+}
+
+func (l *lineBuffer) Transform(data []byte) []byte {
+	l.partial = append(l.partial, data...)
+	end := bytes.LastIndexByte(l.partial, '\n') + 1
+	if len(l.partial)-end > maxLineLength {
+		// Not much of a line. Send it rather than hold on to it
+		end = len(l.partial)
+	}
+	if end == 0 {
+		return nil
+	}
+
+	out := l.partial[:end]
+	l.partial = bytes.Clone(l.partial[end:])
+	return out
+}
+
+func (l *lineBuffer) Holding() bool {
+	return len(l.partial) > 0
+}
+
+func (l *lineBuffer) Flush() []byte {
+	out := l.partial
+	l.partial = nil
+	return out
+}
+
+// Collapses runs of identical lines into a single
+// "last line repeated N times" marker
+type lineCollapser struct {
+	// Trailing output that doesn't end in a newline yet
+	partial []byte
+	// The last complete line sent, including its newline
+	last []byte
+	// How many times 'last' has been repeated since it was sent
+	repeats int
+}
+
+func (c *lineCollapser) Transform(data []byte) []byte {
+	var out []byte
+	c.partial = append(c.partial, data...)
+	for {
+		idx := bytes.IndexByte(c.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		out = c.addLine(out, c.partial[:idx+1])
+		c.partial = c.partial[idx+1:]
+	}
+
+	if len(c.partial) > maxLineLength {
+		// Not much of a line. Give up on collapsing it
+		out = c.endRun(out)
+		out = append(out, c.partial...)
+		c.partial = nil
+		c.last = nil
+	} else {
+		// Don't pin the (possibly large) buffer behind the partial line
+		c.partial = bytes.Clone(c.partial)
+	}
+	return out
+}
+
+func (c *lineCollapser) addLine(out, line []byte) []byte {
+	if c.last != nil && bytes.Equal(line, c.last) {
+		c.repeats++
+		return out
+	}
+	out = c.endRun(out)
+	c.last = append(c.last[:0], line...)
+	return append(out, line...)
+}
+
+// Report the run of repeats that just ended (if any)
+func (c *lineCollapser) endRun(out []byte) []byte {
+	switch c.repeats {
+	case 0:
+	case 1:
+		// The marker wouldn't be any shorter
+		out = append(out, c.last...)
+	default:
+		out = fmt.Appendf(out, "last line repeated %d times\n", c.repeats)
+	}
+	c.repeats = 0
+	return out
+}
+
+func (c *lineCollapser) Holding() bool {
+	return len(c.partial) > 0
+}
+
+func (c *lineCollapser) Flush() []byte {
+	out := c.endRun(nil)
+	out = append(out, c.partial...)
+	c.partial = nil
+	return out
+}
+
+// Removes the timestamps jobs started with timestamp_output have on
+// each line (see job.TimestampLayout). Lines without one pass as they are
+type timestampStripper struct {
+	// The start of the current line, while it could still be a timestamp
+	prefix []byte
+	// Past the start of the current line. The rest passes as it is
+	inLine bool
+}
+
+func (s *timestampStripper) Transform(data []byte) []byte {
+	var out []byte
+	for len(data) > 0 {
+		if s.inLine {
+			end := bytes.IndexByte(data, '\n')
+			if end < 0 {
+				return append(out, data...)
+			}
+			out = append(out, data[:end+1]...)
+			data = data[end+1:]
+			s.inLine = false
+			continue
+		}
+
+		n := min(len(data), job.TimestampPrefixLength-len(s.prefix))
+		if end := bytes.IndexByte(data[:n], '\n'); end >= 0 {
+			// Too short for a timestamp
+			n = end + 1
+		}
+		s.prefix = append(s.prefix, data[:n]...)
+		data = data[n:]
+		if s.prefix[len(s.prefix)-1] == '\n' {
+			out = append(out, s.prefix...)
+			s.prefix = s.prefix[:0]
+			continue
+		}
+		if len(s.prefix) < job.TimestampPrefixLength {
+			// Wait for the rest of it
+			break
+		}
+		if _, _, ok := job.CutTimestamp(s.prefix); !ok {
+			out = append(out, s.prefix...)
+		}
+		s.prefix = s.prefix[:0]
+		s.inLine = true
+	}
+	return out
+}
+
+func (s *timestampStripper) Holding() bool {
+	return len(s.prefix) > 0
+}
+
+func (s *timestampStripper) Flush() []byte {
+	out := bytes.Clone(s.prefix)
+	if len(out) > 0 {
+		// Whatever follows continues the line
+		s.inLine = true
+	}
+	s.prefix = s.prefix[:0]
+	return out
+}
+
+// Applies each transform to the output of the one before it
+type transformChain []outputTransform
+
+func (c transformChain) Transform(data []byte) []byte {
+	for _, t := range c {
+		data = t.Transform(data)
+	}
+	return data
+}
+
+func (c transformChain) Holding() bool {
+	for _, t := range c {
+		if t.Holding() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c transformChain) Flush() []byte {
+	var out []byte
+	for _, t := range c {
+		out = append(t.Transform(out), t.Flush()...)
+	}
+	return out
+}
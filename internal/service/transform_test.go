@@ -0,0 +1,107 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Feed input through a transform in the given chunks and collect the output
+func runTransform(transform outputTransform, chunks ...string) string {
+	var out []byte
+	for _, chunk := range chunks {
+		out = append(out, transform.Transform([]byte(chunk))...)
+	}
+	return string(append(out, transform.Flush()...))
+}
+
+func TestLineCollapser(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"no-repeats", []string{"a\nb\nc\n"}, "a\nb\nc\n"},
+		{"single-repeat", []string{"a\na\nb\n"}, "a\na\nb\n"},
+		{"collapsed", []string{"a\nerr\nerr\nerr\nerr\nb\n"}, "a\nerr\nlast line repeated 3 times\nb\n"},
+		{"split-lines", []string{"er", "r\ne", "rr\ner", "r\n"}, "err\nlast line repeated 2 times\n"},
+		{"repeats-at-end", []string{"x\nx\nx\n"}, "x\nlast line repeated 2 times\n"},
+		{"partial-at-end", []string{"x\nx\nx"}, "x\nx\nx"},
+		{"empty-lines", []string{"\n\n\n"}, "\nlast line repeated 2 times\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert.Equal(tt, tc.want, runTransform(&lineCollapser{}, tc.chunks...))
+		})
+	}
+
+	t.Run("long-partial", func(tt *testing.T) {
+		collapser := &lineCollapser{}
+		long := strings.Repeat("z", maxLineLength+1)
+		// Too long to hold on to
+		assert.Equal(tt, long, string(collapser.Transform([]byte(long))))
+		assert.Empty(tt, collapser.Flush())
+	})
+}
+
+func TestLineBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []string
+	}{
+		{"whole-lines", []string{"a\nb\n"}, []string{"a\nb\n"}},
+		{"split-lines", []string{"a\nb", "b\nc", "c", "\n"}, []string{"a\n", "bb\n", "", "cc\n"}},
+		{"no-newline", []string{"abc"}, []string{""}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(tt *testing.T) {
+			buffer := &lineBuffer{}
+			for idx, chunk := range tc.chunks {
+				assert.Equal(tt, tc.want[idx], string(buffer.Transform([]byte(chunk))))
+			}
+		})
+	}
+
+	t.Run("flush", func(tt *testing.T) {
+		buffer := &lineBuffer{}
+		assert.Equal(tt, "a\n", string(buffer.Transform([]byte("a\nparti"))))
+		assert.True(tt, buffer.Holding())
+		assert.Equal(tt, "parti", string(buffer.Flush()))
+		assert.False(tt, buffer.Holding())
+	})
+
+	t.Run("long-partial", func(tt *testing.T) {
+		buffer := &lineBuffer{}
+		long := strings.Repeat("z", maxLineLength+1)
+		assert.Equal(tt, "a\n"+long, string(buffer.Transform([]byte("a\n"+long))))
+		assert.False(tt, buffer.Holding())
+	})
+}
+
+func TestTimestampStripper(t *testing.T) {
+	const ts = "2025-06-01T12:00:00.000000000Z "
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"whole-lines", []string{ts + "a\n" + ts + "b\n"}, "a\nb\n"},
+		{"split-prefix", []string{ts[:10], ts[10:] + "a", "a\n" + ts[:3], ts[3:] + "b\n"}, "aa\nb\n"},
+		{"unstamped", []string{"plain line\n\n" + ts + "x\n"}, "plain line\n\nx\n"},
+		{"partial-at-end", []string{ts + "a\n" + ts[:5]}, "a\n" + ts[:5]},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert.Equal(tt, tc.want, runTransform(&timestampStripper{}, tc.chunks...))
+		})
+	}
+
+	t.Run("collapsed", func(tt *testing.T) {
+		// Repeats only look identical once their timestamps are gone
+		chain := transformChain{&timestampStripper{}, &lineCollapser{}}
+		got := runTransform(chain, ts+"err\n"+ts[:20], ts[20:]+"err\n"+ts+"err\n")
+		assert.Equal(tt, "err\nlast line repeated 2 times\n", got)
+	})
+}
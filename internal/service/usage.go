@@ -0,0 +1,155 @@
+package service
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/job"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UsageAccounting controls the per-owner usage summaries served by GetUsageSummary
+type UsageAccounting struct {
+	// Trailing windows usage is summarized over (ex: 24h). Usage older
+	// than the longest window is forgotten
+	Windows []time.Duration
+	// Users who may see the usage of every owner. Everyone else only sees their own
+	Viewers []string
+}
+
+var defaultUsageAccounting = UsageAccounting{
+	Windows: []time.Duration{time.Hour, 24 * time.Hour, 7 * 24 * time.Hour},
+}
+
+// Resources consumed by a single owner
+type ownerUsage struct {
+	jobsStarted      uint64
+	attemptsFinished uint64
+	cpuTime          time.Duration
+	wallTime         time.Duration
+}
+
+// Something an owner did that counts toward their usage
+type usageEvent struct {
+	owner string
+	at    time.Time
+	// A new job, rather than an attempt that finished
+	jobStarted bool
+	// Used by the finished attempt
+	cpuTime  time.Duration
+	wallTime time.Duration
+}
+
+// Keeps the usage of every owner over the longest window, and
+// cumulative totals in Prometheus counters
+type usageTracker struct {
+	accounting UsageAccounting
+	clock      clock.Clock
+
+	lock sync.Mutex
+	// Oldest first
+	events []usageEvent
+
+	jobsStarted      *prometheus.CounterVec
+	attemptsFinished *prometheus.CounterVec
+	cpuSeconds       *prometheus.CounterVec
+	wallSeconds      *prometheus.CounterVec
+}
+
+func newUsageTracker(accounting UsageAccounting) *usageTracker {
+	return &usageTracker{
+		accounting: accounting,
+		clock:      clock.Real,
+		jobsStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobby_jobs_started_total",
+			Help: "Jobs started, by owner",
+		}, []string{"owner"}),
+		attemptsFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobby_job_attempts_finished_total",
+			Help: "Job attempts that finished, by owner",
+		}, []string{"owner"}),
+		cpuSeconds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobby_job_cpu_seconds_total",
+			Help: "User and system CPU time used by finished job attempts, by owner",
+		}, []string{"owner"}),
+		wallSeconds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobby_job_wall_seconds_total",
+			Help: "Wall clock time finished job attempts ran for, by owner",
+		}, []string{"owner"}),
+	}
+}
+
+func (t *usageTracker) collectors() []prometheus.Collector {
+	return []prometheus.Collector{t.jobsStarted, t.attemptsFinished, t.cpuSeconds, t.wallSeconds}
+}
+
+func (t *usageTracker) jobStarted(owner string) {
+	t.jobsStarted.WithLabelValues(owner).Inc()
+	t.record(usageEvent{owner: owner, jobStarted: true})
+}
+
+// Count an attempt once it has exited. Only then is its CPU time known
+func (t *usageTracker) attemptFinished(owner string, status job.Status) {
+	t.attemptsFinished.WithLabelValues(owner).Inc()
+	t.cpuSeconds.WithLabelValues(owner).Add(status.CPUTime.Seconds())
+	t.wallSeconds.WithLabelValues(owner).Add(status.Duration.Seconds())
+	t.record(usageEvent{owner: owner, cpuTime: status.CPUTime, wallTime: status.Duration})
+}
+
+func (t *usageTracker) record(event usageEvent) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	// Taken under the lock so events stay in order
+	event.at = t.clock.Now()
+	t.events = append(t.events, event)
+	t.expire(event.at)
+}
+
+// Forget events older than the longest window. Caller must hold the lock
+func (t *usageTracker) expire(now time.Time) {
+	if len(t.accounting.Windows) == 0 {
+		t.events = nil
+		return
+	}
+	cutoff := now.Add(-slices.Max(t.accounting.Windows))
+	i, _ := slices.BinarySearchFunc(t.events, cutoff, func(e usageEvent, cutoff time.Time) int {
+		return e.at.Compare(cutoff)
+	})
+	if i > 0 {
+		t.events = slices.Delete(t.events, 0, i)
+	}
+}
+
+// Whether 'user' may see the usage of every owner
+func (t *usageTracker) canViewAll(user string) bool {
+	return slices.Contains(t.accounting.Viewers, user)
+}
+
+// Usage of each owner over the trailing 'window'. Limited to
+// 'owner' unless it's empty
+func (t *usageTracker) summary(window time.Duration, owner string) map[string]*ownerUsage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	cutoff := t.clock.Now().Add(-window)
+	usage := map[string]*ownerUsage{}
+	for _, event := range t.events {
+		if event.at.Before(cutoff) || (owner != "" && event.owner != owner) {
+			continue
+		}
+		u, ok := usage[event.owner]
+		if !ok {
+			u = &ownerUsage{}
+			usage[event.owner] = u
+		}
+		if event.jobStarted {
+			u.jobsStarted++
+		} else {
+			u.attemptsFinished++
+			u.cpuTime += event.cpuTime
+			u.wallTime += event.wallTime
+		}
+	}
+	return usage
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTracker(t *testing.T) {
+	tracker := newUsageTracker(UsageAccounting{Windows: []time.Duration{time.Minute, time.Hour}})
+	tracker.jobStarted("someuser")
+	tracker.attemptFinished("someuser", job.Status{CPUTime: time.Second, Duration: 3 * time.Second})
+	tracker.attemptFinished("someuser", job.Status{CPUTime: 2 * time.Second, Duration: 3 * time.Second})
+	tracker.jobStarted("otheruser")
+
+	usage := tracker.summary(time.Minute, "")
+	require.Len(t, usage, 2)
+	assert.Equal(t, ownerUsage{jobsStarted: 1, attemptsFinished: 2, cpuTime: 3 * time.Second, wallTime: 6 * time.Second}, *usage["someuser"])
+	assert.Equal(t, ownerUsage{jobsStarted: 1}, *usage["otheruser"])
+
+	usage = tracker.summary(time.Minute, "otheruser")
+	assert.Len(t, usage, 1)
+	assert.Contains(t, usage, "otheruser")
+
+	t.Run("windows", func(tt *testing.T) {
+		// Pretend the first two events are older
+		tracker.lock.Lock()
+		tracker.events[0].at = time.Now().Add(-2 * time.Hour)
+		tracker.events[1].at = time.Now().Add(-30 * time.Minute)
+		tracker.lock.Unlock()
+
+		assert.Equal(tt, uint64(1), tracker.summary(time.Minute, "someuser")["someuser"].attemptsFinished)
+		assert.Equal(tt, uint64(2), tracker.summary(time.Hour, "someuser")["someuser"].attemptsFinished)
+
+		// Events older than the longest window are dropped as new ones come in
+		tracker.jobStarted("someuser")
+		tracker.lock.Lock()
+		assert.Len(tt, tracker.events, 4)
+		tracker.lock.Unlock()
+		// Only the new job is left
+		assert.Equal(tt, uint64(1), tracker.summary(time.Hour, "someuser")["someuser"].jobsStarted)
+	})
+
+	t.Run("viewers", func(tt *testing.T) {
+		tracker := newUsageTracker(UsageAccounting{Viewers: []string{"finance"}})
+		assert.True(tt, tracker.canViewAll("finance"))
+		assert.False(tt, tracker.canViewAll("someuser"))
+	})
+}
@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	jobmanagerv2 "github.com/gopheryan/jobby/jobmanagerpb/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Serves the v2 API by translating to and from v1 and calling into Jobby.
+// Both versions see the same jobs, so a job started with one
+// can be inspected with the other
+type jobbyV2 struct {
+	jobmanagerv2.UnimplementedJobManagerServer
+	v1 *Jobby
+}
+
+// Copy the fields 'from' and 'to' have in common. v2 messages keep v1's field
+// numbers (and wire types) except for job IDs, which callers fill in themselves.
+// v1 takes job IDs in text form too, so they're passed along as is and
+// validated the same way for both versions.
+// Fields 'to' doesn't have are dropped rather than kept as unknown fields
+func convertMessage(from proto.Message, to proto.Message) error {
+	data, err := proto.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return proto.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, to)
+}
+
+func (s *jobbyV2) StartJob(ctx context.Context, req *jobmanagerv2.StartJobRequest) (*jobmanagerv2.StartJobResponse, error) {
+	spec := &jobmanagerpb.JobSpec{}
+	if req.Spec != nil {
+		if err := convertMessage(req.Spec, spec); err != nil {
+			return nil, status.Error(codes.Internal, "Error translating request")
+		}
+	}
+	resp, err := s.v1.StartJob(ctx, &jobmanagerpb.StartJobRequest{Spec: spec, Force: req.Force, SessionId: req.SessionId, CacheTtl: req.CacheTtl})
+	if err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.StartJobResponse{JobId: resp.Id, Warnings: resp.Warnings, Cached: resp.Cached}, nil
+}
+
+func (s *jobbyV2) StopJob(ctx context.Context, req *jobmanagerv2.StopJobRequest) (*jobmanagerv2.StopJobResponse, error) {
+	if _, err := s.v1.StopJob(ctx, &jobmanagerpb.StopJobRequest{Id: req.JobId}); err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.StopJobResponse{}, nil
+}
+
+func (s *jobbyV2) GetStatus(ctx context.Context, req *jobmanagerv2.GetStatusRequest) (*jobmanagerv2.GetStatusResponse, error) {
+	resp, err := s.v1.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{Id: req.JobId})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.GetStatusResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) GetJobHistory(ctx context.Context, req *jobmanagerv2.GetJobHistoryRequest) (*jobmanagerv2.GetJobHistoryResponse, error) {
+	resp, err := s.v1.GetJobHistory(ctx, &jobmanagerpb.GetJobHistoryRequest{Id: req.JobId})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.GetJobHistoryResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+// Passes v1 output messages on to a v2 stream
+type outputStreamV2 struct {
+	jobmanagerv2.JobManager_GetJobOutputServer
+}
+
+func (o outputStreamV2) Send(msg *jobmanagerpb.GetJobOutputResponse) error {
+	return o.JobManager_GetJobOutputServer.Send(outputResponseV2(msg))
+}
+
+// Small enough to copy by hand, no need for a round trip through the
+// wire format on every chunk of output
+func outputResponseV2(msg *jobmanagerpb.GetJobOutputResponse) *jobmanagerv2.GetJobOutputResponse {
+	out := &jobmanagerv2.GetJobOutputResponse{Data: msg.Data}
+	if msg.End != nil {
+		out.End = &jobmanagerv2.OutputEnd{TotalBytes: msg.End.TotalBytes, Sha256: msg.End.Sha256}
+	}
+	return out
+}
+
+func (s *jobbyV2) GetJobOutput(req *jobmanagerv2.GetJobOutputRequest, srv jobmanagerv2.JobManager_GetJobOutputServer) error {
+	// The text ID would land in v1's bytes field, so it's left out of the conversion
+	clone := proto.Clone(req).(*jobmanagerv2.GetJobOutputRequest)
+	clone.JobId = ""
+	v1Req := &jobmanagerpb.GetJobOutputRequest{}
+	if err := convertMessage(clone, v1Req); err != nil {
+		return status.Error(codes.Internal, "Error translating request")
+	}
+	v1Req.Id = req.JobId
+	return s.v1.GetJobOutput(v1Req, outputStreamV2{srv})
+}
+
+// Passes v1 job records on to a v2 stream
+type exportStreamV2 struct {
+	jobmanagerv2.JobManager_ExportJobsServer
+}
+
+func (e exportStreamV2) Send(record *jobmanagerpb.JobRecord) error {
+	out, err := recordToV2(record)
+	if err != nil {
+		return err
+	}
+	return e.JobManager_ExportJobsServer.Send(out)
+}
+
+func recordToV2(record *jobmanagerpb.JobRecord) (*jobmanagerv2.JobRecord, error) {
+	// The raw ID isn't valid UTF-8, so it's left out of the conversion
+	clone := proto.Clone(record).(*jobmanagerpb.JobRecord)
+	clone.JobId = nil
+	out := &jobmanagerv2.JobRecord{}
+	if err := convertMessage(clone, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	out.JobId = record.Id
+	return out, nil
+}
+
+func (s *jobbyV2) ExportJobs(req *jobmanagerv2.ExportJobsRequest, srv jobmanagerv2.JobManager_ExportJobsServer) error {
+	return s.v1.ExportJobs(&jobmanagerpb.ExportJobsRequest{}, exportStreamV2{srv})
+}
+
+func (s *jobbyV2) ListJobs(ctx context.Context, req *jobmanagerv2.ListJobsRequest) (*jobmanagerv2.ListJobsResponse, error) {
+	v1Req := &jobmanagerpb.ListJobsRequest{}
+	if err := convertMessage(req, v1Req); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating request")
+	}
+	resp, err := s.v1.ListJobs(ctx, v1Req)
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.ListJobsResponse{}
+	for _, record := range resp.Jobs {
+		converted, err := recordToV2(record)
+		if err != nil {
+			return nil, err
+		}
+		out.Jobs = append(out.Jobs, converted)
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) GetServerInfo(ctx context.Context, req *jobmanagerv2.GetServerInfoRequest) (*jobmanagerv2.GetServerInfoResponse, error) {
+	resp, err := s.v1.GetServerInfo(ctx, &jobmanagerpb.GetServerInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.GetServerInfoResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) GetUsageSummary(ctx context.Context, req *jobmanagerv2.GetUsageSummaryRequest) (*jobmanagerv2.GetUsageSummaryResponse, error) {
+	v1Req := &jobmanagerpb.GetUsageSummaryRequest{}
+	if err := convertMessage(req, v1Req); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating request")
+	}
+	resp, err := s.v1.GetUsageSummary(ctx, v1Req)
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.GetUsageSummaryResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) GetJobEvents(ctx context.Context, req *jobmanagerv2.GetJobEventsRequest) (*jobmanagerv2.GetJobEventsResponse, error) {
+	resp, err := s.v1.GetJobEvents(ctx, &jobmanagerpb.GetJobEventsRequest{Id: req.JobId})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.GetJobEventsResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) ListOutputSegments(ctx context.Context, req *jobmanagerv2.ListOutputSegmentsRequest) (*jobmanagerv2.ListOutputSegmentsResponse, error) {
+	resp, err := s.v1.ListOutputSegments(ctx, &jobmanagerpb.ListOutputSegmentsRequest{
+		Id:      req.JobId,
+		Type:    jobmanagerpb.OutputType(req.Type),
+		Attempt: req.Attempt,
+		Since:   req.Since,
+		Until:   req.Until,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.ListOutputSegmentsResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+// Passes v1 output messages on to a v2 segment stream
+type segmentStreamV2 struct {
+	jobmanagerv2.JobManager_GetOutputSegmentServer
+}
+
+func (o segmentStreamV2) Send(msg *jobmanagerpb.GetJobOutputResponse) error {
+	return o.JobManager_GetOutputSegmentServer.Send(outputResponseV2(msg))
+}
+
+func (s *jobbyV2) GetOutputSegment(req *jobmanagerv2.GetOutputSegmentRequest, srv jobmanagerv2.JobManager_GetOutputSegmentServer) error {
+	return s.v1.GetOutputSegment(&jobmanagerpb.GetOutputSegmentRequest{
+		Id:      req.JobId,
+		Type:    jobmanagerpb.OutputType(req.Type),
+		Attempt: req.Attempt,
+		Segment: req.Segment,
+	}, segmentStreamV2{srv})
+}
+
+func (s *jobbyV2) WaitJob(ctx context.Context, req *jobmanagerv2.WaitJobRequest) (*jobmanagerv2.GetStatusResponse, error) {
+	resp, err := s.v1.WaitJob(ctx, &jobmanagerpb.WaitJobRequest{Id: req.JobId})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.GetStatusResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+// Passes v1 progress messages on to a v2 stream
+type progressStreamV2 struct {
+	jobmanagerv2.JobManager_GetJobProgressServer
+}
+
+func (p progressStreamV2) Send(msg *jobmanagerpb.GetJobProgressResponse) error {
+	out := &jobmanagerv2.GetJobProgressResponse{}
+	if err := convertMessage(msg, out); err != nil {
+		return status.Error(codes.Internal, "Error translating response")
+	}
+	return p.JobManager_GetJobProgressServer.Send(out)
+}
+
+func (s *jobbyV2) GetJobProgress(req *jobmanagerv2.GetJobProgressRequest, srv jobmanagerv2.JobManager_GetJobProgressServer) error {
+	return s.v1.GetJobProgress(&jobmanagerpb.GetJobProgressRequest{Id: req.JobId}, progressStreamV2{srv})
+}
+
+func (s *jobbyV2) EndSession(ctx context.Context, req *jobmanagerv2.EndSessionRequest) (*jobmanagerv2.EndSessionResponse, error) {
+	resp, err := s.v1.EndSession(ctx, &jobmanagerpb.EndSessionRequest{SessionId: req.SessionId, StopJobs: req.StopJobs})
+	if err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.EndSessionResponse{StoppedJobIds: resp.StoppedJobIds}, nil
+}
+
+func (s *jobbyV2) StreamServerLogs(req *jobmanagerv2.StreamServerLogsRequest, srv jobmanagerv2.JobManager_StreamServerLogsServer) error {
+	return s.v1.StreamServerLogs(&jobmanagerpb.StreamServerLogsRequest{Level: jobmanagerpb.LogLevel(req.Level)}, serverLogStreamV2{srv})
+}
+
+// Passes v1 log entries on to a v2 stream
+type serverLogStreamV2 struct {
+	jobmanagerv2.JobManager_StreamServerLogsServer
+}
+
+func (s serverLogStreamV2) Send(entry *jobmanagerpb.ServerLogEntry) error {
+	out := &jobmanagerv2.ServerLogEntry{}
+	if err := convertMessage(entry, out); err != nil {
+		return status.Error(codes.Internal, "Error translating response")
+	}
+	return s.JobManager_StreamServerLogsServer.Send(out)
+}
+
+func (s *jobbyV2) DeleteJob(ctx context.Context, req *jobmanagerv2.DeleteJobRequest) (*jobmanagerv2.DeleteJobResponse, error) {
+	resp, err := s.v1.DeleteJob(ctx, &jobmanagerpb.DeleteJobRequest{Id: req.JobId, Force: req.Force})
+	if err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.DeleteJobResponse{RestorableUntil: resp.RestorableUntil}, nil
+}
+
+func (s *jobbyV2) RestoreJob(ctx context.Context, req *jobmanagerv2.RestoreJobRequest) (*jobmanagerv2.RestoreJobResponse, error) {
+	if _, err := s.v1.RestoreJob(ctx, &jobmanagerpb.RestoreJobRequest{Id: req.JobId}); err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.RestoreJobResponse{}, nil
+}
+
+func (s *jobbyV2) AdoptProcess(ctx context.Context, req *jobmanagerv2.AdoptProcessRequest) (*jobmanagerv2.AdoptProcessResponse, error) {
+	resp, err := s.v1.AdoptProcess(ctx, &jobmanagerpb.AdoptProcessRequest{
+		Pid:        req.Pid,
+		StdoutPath: req.StdoutPath,
+		StderrPath: req.StderrPath,
+		Owner:      req.Owner,
+		Labels:     req.Labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.AdoptProcessResponse{JobId: resp.Id}, nil
+}
+
+func (s *jobbyV2) GetJobStats(ctx context.Context, req *jobmanagerv2.GetJobStatsRequest) (*jobmanagerv2.GetJobStatsResponse, error) {
+	resp, err := s.v1.GetJobStats(ctx, &jobmanagerpb.GetJobStatsRequest{Id: req.JobId, Command: req.Command})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.GetJobStatsResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) DescribeJob(ctx context.Context, req *jobmanagerv2.DescribeJobRequest) (*jobmanagerv2.DescribeJobResponse, error) {
+	resp, err := s.v1.DescribeJob(ctx, &jobmanagerpb.DescribeJobRequest{Id: req.JobId})
+	if err != nil {
+		return nil, err
+	}
+	// The record's job id is bytes in v1, so it's converted on its own
+	record, err := recordToV2(resp.Record)
+	if err != nil {
+		return nil, err
+	}
+	resp.Record = nil
+	out := &jobmanagerv2.DescribeJobResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	out.Record = record
+	return out, nil
+}
+
+// Passes v2 stdin messages on to v1
+type stdinStreamV2 struct {
+	jobmanagerv2.JobManager_WriteJobStdinServer
+}
+
+func (s stdinStreamV2) Recv() (*jobmanagerpb.WriteJobStdinRequest, error) {
+	msg, err := s.JobManager_WriteJobStdinServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return &jobmanagerpb.WriteJobStdinRequest{Id: msg.JobId, Data: msg.Data}, nil
+}
+
+func (s stdinStreamV2) SendAndClose(resp *jobmanagerpb.WriteJobStdinResponse) error {
+	return s.JobManager_WriteJobStdinServer.SendAndClose(&jobmanagerv2.WriteJobStdinResponse{BytesWritten: resp.BytesWritten})
+}
+
+func (s *jobbyV2) WriteJobStdin(srv jobmanagerv2.JobManager_WriteJobStdinServer) error {
+	return s.v1.WriteJobStdin(stdinStreamV2{srv})
+}
+
+func (s *jobbyV2) RenewJobLease(ctx context.Context, req *jobmanagerv2.RenewJobLeaseRequest) (*jobmanagerv2.RenewJobLeaseResponse, error) {
+	resp, err := s.v1.RenewJobLease(ctx, &jobmanagerpb.RenewJobLeaseRequest{Id: req.JobId})
+	if err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.RenewJobLeaseResponse{ExpiresAt: resp.ExpiresAt}, nil
+}
+
+func (s *jobbyV2) ReportJobProgress(ctx context.Context, req *jobmanagerv2.ReportJobProgressRequest) (*jobmanagerv2.ReportJobProgressResponse, error) {
+	_, err := s.v1.ReportJobProgress(ctx, &jobmanagerpb.ReportJobProgressRequest{Id: req.JobId, Percent: req.Percent, Message: req.Message})
+	if err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.ReportJobProgressResponse{}, nil
+}
+
+func (s *jobbyV2) AnnotateJob(ctx context.Context, req *jobmanagerv2.AnnotateJobRequest) (*jobmanagerv2.AnnotateJobResponse, error) {
+	_, err := s.v1.AnnotateJob(ctx, &jobmanagerpb.AnnotateJobRequest{Id: req.JobId, Annotations: req.Annotations})
+	if err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.AnnotateJobResponse{}, nil
+}
+
+func (s *jobbyV2) PutSchedule(ctx context.Context, req *jobmanagerv2.PutScheduleRequest) (*jobmanagerv2.PutScheduleResponse, error) {
+	v1Req := &jobmanagerpb.PutScheduleRequest{}
+	if err := convertMessage(req, v1Req); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating request")
+	}
+	resp, err := s.v1.PutSchedule(ctx, v1Req)
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.PutScheduleResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) ListSchedules(ctx context.Context, req *jobmanagerv2.ListSchedulesRequest) (*jobmanagerv2.ListSchedulesResponse, error) {
+	resp, err := s.v1.ListSchedules(ctx, &jobmanagerpb.ListSchedulesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.ListSchedulesResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) DeleteSchedule(ctx context.Context, req *jobmanagerv2.DeleteScheduleRequest) (*jobmanagerv2.DeleteScheduleResponse, error) {
+	if _, err := s.v1.DeleteSchedule(ctx, &jobmanagerpb.DeleteScheduleRequest{Name: req.Name}); err != nil {
+		return nil, err
+	}
+	return &jobmanagerv2.DeleteScheduleResponse{}, nil
+}
+
+func (s *jobbyV2) ListScheduleRuns(ctx context.Context, req *jobmanagerv2.ListScheduleRunsRequest) (*jobmanagerv2.ListScheduleRunsResponse, error) {
+	resp, err := s.v1.ListScheduleRuns(ctx, &jobmanagerpb.ListScheduleRunsRequest{Name: req.Name, Upcoming: req.Upcoming})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.ListScheduleRunsResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) PreviewTemplate(ctx context.Context, req *jobmanagerv2.PreviewTemplateRequest) (*jobmanagerv2.PreviewTemplateResponse, error) {
+	v1Req := &jobmanagerpb.PreviewTemplateRequest{}
+	if err := convertMessage(req, v1Req); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating request")
+	}
+	resp, err := s.v1.PreviewTemplate(ctx, v1Req)
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.PreviewTemplateResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
+
+func (s *jobbyV2) CheckOutputDirectory(ctx context.Context, req *jobmanagerv2.CheckOutputDirectoryRequest) (*jobmanagerv2.CheckOutputDirectoryResponse, error) {
+	resp, err := s.v1.CheckOutputDirectory(ctx, &jobmanagerpb.CheckOutputDirectoryRequest{RemoveOrphans: req.RemoveOrphans})
+	if err != nil {
+		return nil, err
+	}
+	out := &jobmanagerv2.CheckOutputDirectoryResponse{}
+	if err := convertMessage(resp, out); err != nil {
+		return nil, status.Error(codes.Internal, "Error translating response")
+	}
+	return out, nil
+}
@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/service"
+	"github.com/gopheryan/jobby/internal/testutils"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	jobmanagerv2 "github.com/gopheryan/jobby/jobmanagerpb/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// The v2 API is served next to v1 and shares its jobs
+func TestServiceV2(t *testing.T) {
+	srv := testutils.GrpcLocalServer{}
+	jobService := service.NewJobService(&mockUserGetter{user: "someuser"}, t.TempDir())
+	server := grpc.NewServer()
+
+	jobService.Register(server)
+	require.NoError(t, srv.ListenAndServe(server))
+	t.Cleanup(func() {
+		server.Stop()
+		_ = srv.Done()
+	})
+
+	ctx := context.Background()
+	v1Client := jobmanagerpb.NewJobManagerClient(srv.Conn())
+	v2Client := jobmanagerv2.NewJobManagerClient(srv.Conn())
+
+	resp, err := v2Client.StartJob(ctx, &jobmanagerv2.StartJobRequest{
+		Spec: &jobmanagerv2.JobSpec{
+			Command:     testJobPath,
+			Args:        []string{"testjob", "2"},
+			MaxAttempts: 2,
+			Labels:      map[string]string{"api": "v2"},
+		},
+	})
+	require.NoError(t, err)
+	id, err := uuid.Parse(resp.JobId)
+	require.NoError(t, err)
+
+	t.Run("output", func(tt *testing.T) {
+		stream, err := v2Client.GetJobOutput(ctx, &jobmanagerv2.GetJobOutputRequest{
+			JobId: resp.JobId,
+			Type:  jobmanagerv2.OutputType_OUTPUT_TYPE_STDOUT,
+			Mode:  jobmanagerv2.StreamMode_STREAM_MODE_LINES,
+		})
+		require.NoError(tt, err)
+		var output bytes.Buffer
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				assert.ErrorIs(tt, err, io.EOF)
+				break
+			}
+			_, _ = output.Write(msg.Data)
+		}
+		assert.Equal(tt, "stdout 1\nstdout 2\n", output.String())
+	})
+
+	t.Run("status", func(tt *testing.T) {
+		statusResp, err := v2Client.GetStatus(ctx, &jobmanagerv2.GetStatusRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerv2.Status_STATUS_COMPLETE, statusResp.CurrentStatus)
+		require.NotNil(tt, statusResp.ExitCode)
+		assert.Zero(tt, *statusResp.ExitCode)
+		assert.Positive(tt, statusResp.Duration.AsDuration())
+		assert.Equal(tt, jobmanagerv2.ExitReason_EXIT_REASON_EXITED, statusResp.ExitReason)
+
+		// Same job through v1
+		v1Resp, err := v1Client.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{JobId: id[:]})
+		require.NoError(tt, err)
+		assert.Equal(tt, jobmanagerpb.Status_STATUS_COMPLETE, v1Resp.CurrentStatus)
+	})
+
+	t.Run("history", func(tt *testing.T) {
+		history, err := v2Client.GetJobHistory(ctx, &jobmanagerv2.GetJobHistoryRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		require.Len(tt, history.Attempts, 1)
+		assert.Equal(tt, uint32(1), history.Attempts[0].Number)
+		assert.Equal(tt, uint64(len("stdout 1\nstdout 2\n")), history.Attempts[0].StdoutBytes)
+	})
+
+	t.Run("export", func(tt *testing.T) {
+		var exported *jobmanagerv2.JobRecord
+		require.Eventually(tt, func() bool {
+			stream, err := v2Client.ExportJobs(ctx, &jobmanagerv2.ExportJobsRequest{})
+			require.NoError(tt, err)
+			for {
+				msg, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(tt, err)
+				exported = msg
+			}
+			return exported != nil && exported.EndTime != nil
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.Equal(tt, resp.JobId, exported.JobId)
+		assert.Equal(tt, testJobPath, exported.Spec.GetCommand())
+		assert.Equal(tt, uint32(2), exported.Spec.GetMaxAttempts())
+		assert.Equal(tt, map[string]string{"api": "v2"}, exported.Spec.GetLabels())
+		// v1 only fields aren't carried along as unknown fields
+		assert.Empty(tt, exported.ProtoReflect().GetUnknown())
+	})
+
+	t.Run("describe", func(tt *testing.T) {
+		described, err := v2Client.DescribeJob(ctx, &jobmanagerv2.DescribeJobRequest{JobId: resp.JobId})
+		require.NoError(tt, err)
+		assert.Equal(tt, resp.JobId, described.Record.JobId)
+		assert.Equal(tt, jobmanagerv2.Status_STATUS_COMPLETE, described.Status.CurrentStatus)
+		require.Len(tt, described.Attempts, 1)
+		require.Len(tt, described.Outputs, 2)
+		assert.Equal(tt, jobmanagerv2.OutputType_OUTPUT_TYPE_STDOUT, described.Outputs[0].Type)
+	})
+
+	t.Run("stdin", func(tt *testing.T) {
+		started, err := v2Client.StartJob(ctx, &jobmanagerv2.StartJobRequest{
+			Spec: &jobmanagerv2.JobSpec{Command: "/bin/cat", Args: []string{"cat"}, Stdin: true},
+		})
+		require.NoError(tt, err)
+		stream, err := v2Client.WriteJobStdin(ctx)
+		require.NoError(tt, err)
+		require.NoError(tt, stream.Send(&jobmanagerv2.WriteJobStdinRequest{JobId: started.JobId, Data: []byte("hello\n")}))
+		written, err := stream.CloseAndRecv()
+		require.NoError(tt, err)
+		assert.Equal(tt, uint64(6), written.BytesWritten)
+	})
+
+	t.Run("lease", func(tt *testing.T) {
+		started, err := v2Client.StartJob(ctx, &jobmanagerv2.StartJobRequest{
+			Spec: &jobmanagerv2.JobSpec{Command: "/bin/sleep", Args: []string{"sleep", "10"}, Lease: durationpb.New(time.Minute)},
+		})
+		require.NoError(tt, err)
+		defer v2Client.StopJob(ctx, &jobmanagerv2.StopJobRequest{JobId: started.JobId})
+		renewed, err := v2Client.RenewJobLease(ctx, &jobmanagerv2.RenewJobLeaseRequest{JobId: started.JobId})
+		require.NoError(tt, err)
+		assert.WithinDuration(tt, time.Now().Add(time.Minute), renewed.ExpiresAt.AsTime(), 10*time.Second)
+	})
+
+	t.Run("list", func(tt *testing.T) {
+		list, err := v2Client.ListJobs(ctx, &jobmanagerv2.ListJobsRequest{CommandContains: "testjob"})
+		require.NoError(tt, err)
+		require.Len(tt, list.Jobs, 1)
+		assert.Equal(tt, resp.JobId, list.Jobs[0].JobId)
+	})
+
+	t.Run("v1-job", func(tt *testing.T) {
+		v1Resp, err := v1Client.StartJob(ctx, &jobmanagerpb.StartJobRequest{
+			Command: testJobPath,
+			Args:    []string{"testjob", "500"},
+		})
+		require.NoError(tt, err)
+		v1ID, err := uuid.FromBytes(v1Resp.JobId)
+		require.NoError(tt, err)
+
+		_, err = v2Client.StopJob(ctx, &jobmanagerv2.StopJobRequest{JobId: v1ID.String()})
+		require.NoError(tt, err)
+		require.Eventually(tt, func() bool {
+			statusResp, err := v2Client.GetStatus(ctx, &jobmanagerv2.GetStatusRequest{JobId: v1ID.String()})
+			require.NoError(tt, err)
+			return statusResp.CurrentStatus == jobmanagerv2.Status_STATUS_STOPPED
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		_, err := v2Client.GetStatus(ctx, &jobmanagerv2.GetStatusRequest{JobId: "not-a-job"})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+
+		_, err = v2Client.GetStatus(ctx, &jobmanagerv2.GetStatusRequest{JobId: uuid.NewString()})
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+
+		_, err = v2Client.StartJob(ctx, &jobmanagerv2.StartJobRequest{})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,78 @@
+package spiffeauth
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// Source provides both our own X.509 SVID and the trust bundles
+// used to verify peers. workloadapi.X509Source satisfies this
+// and keeps both up to date as the SPIFFE agent rotates them
+type Source interface {
+	x509svid.Source
+	x509bundle.Source
+}
+
+// Connect to the SPIFFE Workload API at the given socket address
+// (ex: unix:///run/spire/agent.sock) and wait for the first SVID.
+// An empty address falls back to the SPIFFE_ENDPOINT_SOCKET environment variable.
+// The caller must Close the returned source
+func NewSource(ctx context.Context, socketAddr string) (*workloadapi.X509Source, error) {
+	var opts []workloadapi.X509SourceOption
+	if socketAddr != "" {
+		opts = append(opts, workloadapi.WithClientOptions(workloadapi.WithAddr(socketAddr)))
+	}
+
+	source, err := workloadapi.NewX509Source(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching X.509 SVID from workload API: %w", err)
+	}
+	return source, nil
+}
+
+// Build an authorizer that accepts a specific SPIFFE ID when one is given,
+// otherwise any member of the trust domain. Both empty accepts any peer
+// that chains back to a trusted bundle
+func Authorizer(id, trustDomain string) (tlsconfig.Authorizer, error) {
+	if id != "" {
+		parsed, err := spiffeid.FromString(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE ID '%s': %w", id, err)
+		}
+		return tlsconfig.AuthorizeID(parsed), nil
+	}
+
+	if trustDomain != "" {
+		td, err := spiffeid.TrustDomainFromString(trustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust domain '%s': %w", trustDomain, err)
+		}
+		return tlsconfig.AuthorizeMemberOf(td), nil
+	}
+
+	return tlsconfig.AuthorizeAny(), nil
+}
+
+// ServerTLSConfig requires clients to present an SVID trusted by the source.
+// Certificates are fetched from the source on every handshake, so rotated
+// SVIDs are picked up without restarting the server
+func ServerTLSConfig(source Source, authorizer tlsconfig.Authorizer) *tls.Config {
+	cfg := tlsconfig.MTLSServerConfig(source, source, authorizer)
+	cfg.MinVersion = tls.VersionTLS13
+	return cfg
+}
+
+// ClientTLSConfig presents our SVID to the server and verifies the
+// server's SVID with the authorizer
+func ClientTLSConfig(source Source, authorizer tlsconfig.Authorizer) *tls.Config {
+	cfg := tlsconfig.MTLSClientConfig(source, source, authorizer)
+	cfg.MinVersion = tls.VersionTLS13
+	return cfg
+}
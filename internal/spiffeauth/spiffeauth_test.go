@@ -0,0 +1,144 @@
+package spiffeauth_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/certgen"
+	"github.com/gopheryan/jobby/internal/spiffeauth"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Stands in for the workload API
+type staticSource struct {
+	*x509svid.SVID
+	*x509bundle.Bundle
+}
+
+func newStaticSource(t *testing.T, ca *certgen.CertKey, id string) staticSource {
+	spiffeID := spiffeid.RequireFromString(id)
+	// Any leaf with a single SPIFFE URI SAN will do
+	leaf, err := ca.IssueClient("workload", time.Hour, id)
+	require.NoError(t, err)
+
+	return staticSource{
+		SVID: &x509svid.SVID{
+			ID:           spiffeID,
+			Certificates: []*x509.Certificate{leaf.Cert},
+			PrivateKey:   leaf.Key,
+		},
+		Bundle: x509bundle.FromX509Authorities(spiffeID.TrustDomain(), []*x509.Certificate{ca.Cert}),
+	}
+}
+
+// Loopback TCP rather than net.Pipe since a failed handshake
+// leaves one side writing an alert nobody reads
+func handshake(t *testing.T, serverCfg, clientCfg *tls.Config) (*tls.Conn, error, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	type result struct {
+		conn *tls.Conn
+		err  error
+	}
+	srvResult := make(chan result)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			srvResult <- result{err: err}
+			return
+		}
+		srv := tls.Server(conn, serverCfg)
+		t.Cleanup(func() { srv.Close() })
+		srvResult <- result{conn: srv, err: srv.Handshake()}
+	}()
+
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	cli := tls.Client(clientConn, clientCfg)
+	t.Cleanup(func() { cli.Close() })
+
+	cliErr := cli.Handshake()
+	if cliErr == nil {
+		// TLS 1.3 clients finish before the server has verified their
+		// certificate. A read surfaces the server's verdict
+		_ = cli.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		if _, err := cli.Read(make([]byte, 1)); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			cliErr = err
+		}
+	} else {
+		cli.Close()
+	}
+	srv := <-srvResult
+	return srv.conn, srv.err, cliErr
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca, err := certgen.NewCA("SpiffeCA", time.Hour)
+	require.NoError(t, err)
+
+	serverSource := newStaticSource(t, ca, "spiffe://jobby.local/server")
+	clientSource := newStaticSource(t, ca, "spiffe://jobby.local/user/alice")
+
+	serverAuth, err := spiffeauth.Authorizer("", "jobby.local")
+	require.NoError(t, err)
+	clientAuth, err := spiffeauth.Authorizer("spiffe://jobby.local/server", "")
+	require.NoError(t, err)
+
+	t.Run("authorized", func(tt *testing.T) {
+		srv, srvErr, cliErr := handshake(tt,
+			spiffeauth.ServerTLSConfig(serverSource, serverAuth),
+			spiffeauth.ClientTLSConfig(clientSource, clientAuth),
+		)
+		require.NoError(tt, cliErr)
+		require.NoError(tt, srvErr)
+
+		// The client's SPIFFE ID makes it through to the server, where
+		// the URI identity mode picks it up
+		peerCerts := srv.ConnectionState().PeerCertificates
+		require.NotEmpty(tt, peerCerts)
+		require.Len(tt, peerCerts[0].URIs, 1)
+		assert.Equal(tt, "spiffe://jobby.local/user/alice", peerCerts[0].URIs[0].String())
+	})
+
+	t.Run("wrong-server-id", func(tt *testing.T) {
+		otherAuth, err := spiffeauth.Authorizer("spiffe://jobby.local/not-the-server", "")
+		require.NoError(tt, err)
+		_, _, cliErr := handshake(tt,
+			spiffeauth.ServerTLSConfig(serverSource, serverAuth),
+			spiffeauth.ClientTLSConfig(clientSource, otherAuth),
+		)
+		assert.Error(tt, cliErr)
+	})
+
+	t.Run("foreign-trust-domain", func(tt *testing.T) {
+		otherCA, err := certgen.NewCA("OtherCA", time.Hour)
+		require.NoError(tt, err)
+		foreignSource := newStaticSource(tt, otherCA, "spiffe://elsewhere.local/user/mallory")
+		// Trust the server so the only failure is on the server side
+		foreignSource.Bundle = serverSource.Bundle
+
+		_, srvErr, _ := handshake(tt,
+			spiffeauth.ServerTLSConfig(serverSource, serverAuth),
+			spiffeauth.ClientTLSConfig(foreignSource, clientAuth),
+		)
+		assert.Error(tt, srvErr)
+	})
+}
+
+func TestAuthorizerInvalid(t *testing.T) {
+	_, err := spiffeauth.Authorizer("not-a-spiffe-id", "")
+	assert.Error(t, err)
+	_, err = spiffeauth.Authorizer("", "Not A Domain!")
+	assert.Error(t, err)
+}
@@ -0,0 +1,351 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	bolt "go.etcd.io/bbolt"
+)
+
+// How long to wait for another process to let go of the database file
+const boltOpenTimeout = 5 * time.Second
+
+var (
+	// Job ID -> boltJob
+	jobsBucket = []byte("jobs")
+	// Job ID + sequence number -> boltEvent, so a job's events are
+	// next to each other and in order
+	eventsBucket = []byte("events")
+	// Schedule ID -> boltSchedule
+	schedulesBucket = []byte("schedules")
+	// Lease name -> boltLease
+	leasesBucket = []byte("leases")
+)
+
+type boltJob struct {
+	Owner  string `json:"owner"`
+	Node   string `json:"node,omitempty"`
+	Record []byte `json:"record"`
+}
+
+type boltEvent struct {
+	Owner   string    `json:"owner"`
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`
+	Attempt uint32    `json:"attempt,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+type boltSchedule struct {
+	Owner   string    `json:"owner"`
+	Cron    string    `json:"cron"`
+	Spec    []byte    `json:"spec"`
+	Created time.Time `json:"created"`
+	LastRun time.Time `json:"last_run"`
+	LastDue time.Time `json:"last_due"`
+	// A jobmanagerpb.MissedRunPolicy
+	MissedRuns int32         `json:"missed_runs,omitempty"`
+	Jitter     time.Duration `json:"jitter,omitempty"`
+	Runs       []ScheduleRun `json:"runs,omitempty"`
+}
+
+type boltLease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+// Keeps metadata in a single bbolt file. Only one server may have it open
+type boltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens the bbolt database at 'path', creating it if needed
+func OpenBolt(path string) (Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("error opening bolt store: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{jobsBucket, eventsBucket, schedulesBucket, leasesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("error creating bolt buckets: %w", err)
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *boltStore) GetJob(_ context.Context, id uuid.UUID) (Job, error) {
+	var job Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(jobsBucket).Get(id[:])
+		if data == nil {
+			return ErrNotFound
+		}
+		var err error
+		job, err = decodeBoltJob(id[:], data)
+		return err
+	})
+	return job, err
+}
+
+func (s *boltStore) PutJob(_ context.Context, job Job) error {
+	record, err := marshalRecord(job.Record)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(boltJob{Owner: job.Owner, Node: job.Node, Record: record})
+	if err != nil {
+		return fmt.Errorf("error encoding job: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put(job.ID[:], data)
+	})
+}
+
+func (s *boltStore) ListJobs(_ context.Context, owner string) ([]Job, error) {
+	var jobs []Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(key, data []byte) error {
+			job, err := decodeBoltJob(key, data)
+			if err != nil {
+				return err
+			}
+			if owner == "" || job.Owner == owner {
+				jobs = append(jobs, job)
+			}
+			return nil
+		})
+	})
+	return jobs, err
+}
+
+func (s *boltStore) DeleteJob(_ context.Context, id uuid.UUID) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Delete(id[:])
+	})
+}
+
+// Decoded from the bytes bolt hands out, which are only valid within the transaction
+func decodeBoltJob(key, data []byte) (Job, error) {
+	id, err := uuid.FromBytes(key)
+	if err != nil {
+		return Job{}, fmt.Errorf("error decoding job id: %w", err)
+	}
+	var stored boltJob
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return Job{}, fmt.Errorf("error decoding job: %w", err)
+	}
+	record, err := unmarshalRecord(stored.Record)
+	if err != nil {
+		return Job{}, err
+	}
+	return Job{ID: id, Owner: stored.Owner, Node: stored.Node, Record: record}, nil
+}
+
+func (s *boltStore) AppendEvent(_ context.Context, event Event) error {
+	data, err := json.Marshal(boltEvent{
+		Owner:   event.Owner,
+		Type:    event.Type,
+		Time:    event.Time,
+		Actor:   event.Actor,
+		Attempt: event.Attempt,
+		Detail:  event.Detail,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding event: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(eventsBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		return bucket.Put(binary.BigEndian.AppendUint64(event.JobID[:], seq), data)
+	})
+}
+
+func (s *boltStore) ListEvents(_ context.Context, jobID uuid.UUID) ([]Event, error) {
+	var events []Event
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(eventsBucket).Cursor()
+		for key, data := cursor.Seek(jobID[:]); key != nil && bytes.HasPrefix(key, jobID[:]); key, data = cursor.Next() {
+			var stored boltEvent
+			if err := json.Unmarshal(data, &stored); err != nil {
+				return fmt.Errorf("error decoding event: %w", err)
+			}
+			events = append(events, Event{
+				JobID:   jobID,
+				Owner:   stored.Owner,
+				Type:    stored.Type,
+				Time:    stored.Time,
+				Actor:   stored.Actor,
+				Attempt: stored.Attempt,
+				Detail:  stored.Detail,
+			})
+		}
+		return nil
+	})
+	return events, err
+}
+
+func (s *boltStore) DeleteEvents(_ context.Context, jobID uuid.UUID) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(eventsBucket).Cursor()
+		// Deleting moves the cursor to the next key
+		for key, _ := cursor.Seek(jobID[:]); key != nil && bytes.HasPrefix(key, jobID[:]); key, _ = cursor.Seek(jobID[:]) {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) GetSchedule(_ context.Context, id string) (Schedule, error) {
+	var schedule Schedule
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(schedulesBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		var err error
+		schedule, err = decodeBoltSchedule(id, data)
+		return err
+	})
+	return schedule, err
+}
+
+func (s *boltStore) PutSchedule(_ context.Context, schedule Schedule) error {
+	if schedule.ID == "" {
+		return errors.New("schedule must have an id")
+	}
+	spec, err := marshalSpec(schedule.Spec)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(boltSchedule{
+		Owner:      schedule.Owner,
+		Cron:       schedule.Cron,
+		Spec:       spec,
+		Created:    schedule.Created,
+		LastRun:    schedule.LastRun,
+		LastDue:    schedule.LastDue,
+		MissedRuns: int32(schedule.MissedRuns),
+		Jitter:     schedule.Jitter,
+		Runs:       schedule.Runs,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding schedule: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulesBucket).Put([]byte(schedule.ID), data)
+	})
+}
+
+func (s *boltStore) ListSchedules(_ context.Context, owner string) ([]Schedule, error) {
+	var schedules []Schedule
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulesBucket).ForEach(func(key, data []byte) error {
+			schedule, err := decodeBoltSchedule(string(key), data)
+			if err != nil {
+				return err
+			}
+			if owner == "" || schedule.Owner == owner {
+				schedules = append(schedules, schedule)
+			}
+			return nil
+		})
+	})
+	return schedules, err
+}
+
+func (s *boltStore) DeleteSchedule(_ context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulesBucket).Delete([]byte(id))
+	})
+}
+
+func decodeBoltSchedule(id string, data []byte) (Schedule, error) {
+	var stored boltSchedule
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return Schedule{}, fmt.Errorf("error decoding schedule: %w", err)
+	}
+	spec, err := unmarshalSpec(stored.Spec)
+	if err != nil {
+		return Schedule{}, err
+	}
+	return Schedule{
+		ID:         id,
+		Owner:      stored.Owner,
+		Cron:       stored.Cron,
+		Spec:       spec,
+		Created:    stored.Created,
+		LastRun:    stored.LastRun,
+		LastDue:    stored.LastDue,
+		MissedRuns: jobmanagerpb.MissedRunPolicy(stored.MissedRuns),
+		Jitter:     stored.Jitter,
+		Runs:       stored.Runs,
+	}, nil
+}
+
+// Only one server can have the file open, so leases only matter
+// between electors within it
+func (s *boltStore) AcquireLease(_ context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	acquired := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(leasesBucket)
+		now := time.Now()
+		if data := bucket.Get([]byte(name)); data != nil {
+			var lease boltLease
+			if err := json.Unmarshal(data, &lease); err != nil {
+				return fmt.Errorf("error decoding lease: %w", err)
+			}
+			if lease.Holder != holder && now.Before(lease.Expires) {
+				return nil
+			}
+		}
+		data, err := json.Marshal(boltLease{Holder: holder, Expires: now.Add(ttl)})
+		if err != nil {
+			return fmt.Errorf("error encoding lease: %w", err)
+		}
+		acquired = true
+		return bucket.Put([]byte(name), data)
+	})
+	return acquired, err
+}
+
+func (s *boltStore) ReleaseLease(_ context.Context, name, holder string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(leasesBucket)
+		data := bucket.Get([]byte(name))
+		if data == nil {
+			return nil
+		}
+		var lease boltLease
+		if err := json.Unmarshal(data, &lease); err != nil {
+			return fmt.Errorf("error decoding lease: %w", err)
+		}
+		if lease.Holder != holder {
+			return nil
+		}
+		return bucket.Delete([]byte(name))
+	})
+}
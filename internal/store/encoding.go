@@ -0,0 +1,65 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Records and specs are stored as protobuf, so fields added to them later
+// don't need a migration
+
+func marshalRecord(record *jobmanagerpb.JobRecord) ([]byte, error) {
+	data, err := proto.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding job record: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalRecord(data []byte) (*jobmanagerpb.JobRecord, error) {
+	record := &jobmanagerpb.JobRecord{}
+	if err := proto.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("error decoding job record: %w", err)
+	}
+	return record, nil
+}
+
+func marshalSpec(spec *jobmanagerpb.JobSpec) ([]byte, error) {
+	data, err := proto.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding job spec: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalSpec(data []byte) (*jobmanagerpb.JobSpec, error) {
+	spec := &jobmanagerpb.JobSpec{}
+	if err := proto.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("error decoding job spec: %w", err)
+	}
+	return spec, nil
+}
+
+// Schedule runs are plain data, so they're stored as JSON
+func marshalRuns(runs []ScheduleRun) ([]byte, error) {
+	data, err := json.Marshal(runs)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding schedule runs: %w", err)
+	}
+	return data, nil
+}
+
+// Schedules stored before runs were kept have none
+func unmarshalRuns(data []byte) ([]ScheduleRun, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var runs []ScheduleRun
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("error decoding schedule runs: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,308 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	// Registers the "postgres" database/sql driver
+	_ "github.com/lib/pq"
+)
+
+// Tables are created if they don't exist yet. Columns are only ever
+// added, so servers of different versions can share a database
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS jobby_jobs (
+	id UUID PRIMARY KEY,
+	owner TEXT NOT NULL,
+	record BYTEA NOT NULL
+);
+CREATE INDEX IF NOT EXISTS jobby_jobs_owner ON jobby_jobs (owner);
+
+CREATE TABLE IF NOT EXISTS jobby_events (
+	seq BIGSERIAL PRIMARY KEY,
+	job_id UUID NOT NULL,
+	owner TEXT NOT NULL,
+	type TEXT NOT NULL,
+	time TIMESTAMPTZ NOT NULL,
+	actor TEXT NOT NULL,
+	attempt BIGINT NOT NULL,
+	detail TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS jobby_events_job_id ON jobby_events (job_id, seq);
+
+CREATE TABLE IF NOT EXISTS jobby_schedules (
+	id TEXT PRIMARY KEY,
+	owner TEXT NOT NULL,
+	cron TEXT NOT NULL,
+	spec BYTEA NOT NULL,
+	last_run TIMESTAMPTZ
+);
+
+ALTER TABLE jobby_jobs ADD COLUMN IF NOT EXISTS node TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobby_schedules ADD COLUMN IF NOT EXISTS created TIMESTAMPTZ;
+ALTER TABLE jobby_schedules ADD COLUMN IF NOT EXISTS last_due TIMESTAMPTZ;
+ALTER TABLE jobby_schedules ADD COLUMN IF NOT EXISTS missed_runs INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobby_schedules ADD COLUMN IF NOT EXISTS jitter BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE jobby_schedules ADD COLUMN IF NOT EXISTS runs BYTEA;
+
+CREATE TABLE IF NOT EXISTS jobby_leases (
+	name TEXT PRIMARY KEY,
+	holder TEXT NOT NULL,
+	expires TIMESTAMPTZ NOT NULL
+);
+`
+
+// Keeps metadata in PostgreSQL, where any number of servers can share it
+type postgresStore struct {
+	db *sql.DB
+}
+
+// OpenPostgres connects to the database at 'dsn' and creates jobby's
+// tables in it if they aren't there yet
+func OpenPostgres(ctx context.Context, dsn string) (Store, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening postgres store: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, postgresSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("error creating postgres tables: %w", err)
+	}
+	return &postgresStore{db: db}, nil
+}
+
+func (s *postgresStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *postgresStore) GetJob(ctx context.Context, id uuid.UUID) (Job, error) {
+	var owner, node string
+	var record []byte
+	err := s.db.QueryRowContext(ctx, `SELECT owner, node, record FROM jobby_jobs WHERE id = $1`, id).Scan(&owner, &node, &record)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, ErrNotFound
+	}
+	if err != nil {
+		return Job{}, fmt.Errorf("error getting job: %w", err)
+	}
+	decoded, err := unmarshalRecord(record)
+	if err != nil {
+		return Job{}, err
+	}
+	return Job{ID: id, Owner: owner, Node: node, Record: decoded}, nil
+}
+
+func (s *postgresStore) PutJob(ctx context.Context, job Job) error {
+	record, err := marshalRecord(job.Record)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO jobby_jobs (id, owner, node, record) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET owner = EXCLUDED.owner, node = EXCLUDED.node, record = EXCLUDED.record`,
+		job.ID, job.Owner, job.Node, record)
+	if err != nil {
+		return fmt.Errorf("error putting job: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) ListJobs(ctx context.Context, owner string) ([]Job, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, owner, node, record FROM jobby_jobs WHERE $1 = '' OR owner = $1`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error listing jobs: %w", err)
+	}
+	defer rows.Close()
+	var jobs []Job
+	for rows.Next() {
+		var job Job
+		var record []byte
+		if err := rows.Scan(&job.ID, &job.Owner, &job.Node, &record); err != nil {
+			return nil, fmt.Errorf("error listing jobs: %w", err)
+		}
+		if job.Record, err = unmarshalRecord(record); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (s *postgresStore) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM jobby_jobs WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error deleting job: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) AppendEvent(ctx context.Context, event Event) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO jobby_events (job_id, owner, type, time, actor, attempt, detail)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		event.JobID, event.Owner, event.Type, event.Time, event.Actor, int64(event.Attempt), event.Detail)
+	if err != nil {
+		return fmt.Errorf("error appending event: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) ListEvents(ctx context.Context, jobID uuid.UUID) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT owner, type, time, actor, attempt, detail FROM jobby_events
+		WHERE job_id = $1 ORDER BY seq`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing events: %w", err)
+	}
+	defer rows.Close()
+	var events []Event
+	for rows.Next() {
+		event := Event{JobID: jobID}
+		var attempt int64
+		if err := rows.Scan(&event.Owner, &event.Type, &event.Time, &event.Actor, &attempt, &event.Detail); err != nil {
+			return nil, fmt.Errorf("error listing events: %w", err)
+		}
+		event.Attempt = uint32(attempt)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing events: %w", err)
+	}
+	return events, nil
+}
+
+func (s *postgresStore) DeleteEvents(ctx context.Context, jobID uuid.UUID) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM jobby_events WHERE job_id = $1`, jobID); err != nil {
+		return fmt.Errorf("error deleting events: %w", err)
+	}
+	return nil
+}
+
+const scheduleColumns = `id, owner, cron, spec, created, last_run, last_due, missed_runs, jitter, runs`
+
+// Reads a row of scheduleColumns
+func scanSchedule(row interface{ Scan(...any) error }) (Schedule, error) {
+	var schedule Schedule
+	var spec, runs []byte
+	var created, lastRun, lastDue sql.NullTime
+	var missedRuns int32
+	var jitter int64
+	err := row.Scan(&schedule.ID, &schedule.Owner, &schedule.Cron, &spec, &created, &lastRun, &lastDue, &missedRuns, &jitter, &runs)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if schedule.Spec, err = unmarshalSpec(spec); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.Runs, err = unmarshalRuns(runs); err != nil {
+		return Schedule{}, err
+	}
+	schedule.Created = created.Time
+	schedule.LastRun = lastRun.Time
+	schedule.LastDue = lastDue.Time
+	schedule.MissedRuns = jobmanagerpb.MissedRunPolicy(missedRuns)
+	schedule.Jitter = time.Duration(jitter)
+	return schedule, nil
+}
+
+func (s *postgresStore) GetSchedule(ctx context.Context, id string) (Schedule, error) {
+	schedule, err := scanSchedule(s.db.QueryRowContext(ctx, `SELECT `+scheduleColumns+` FROM jobby_schedules WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Schedule{}, ErrNotFound
+	}
+	if err != nil {
+		return Schedule{}, fmt.Errorf("error getting schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+func (s *postgresStore) PutSchedule(ctx context.Context, schedule Schedule) error {
+	if schedule.ID == "" {
+		return errors.New("schedule must have an id")
+	}
+	spec, err := marshalSpec(schedule.Spec)
+	if err != nil {
+		return err
+	}
+	runs, err := marshalRuns(schedule.Runs)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO jobby_schedules (`+scheduleColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET owner = EXCLUDED.owner, cron = EXCLUDED.cron,
+			spec = EXCLUDED.spec, created = EXCLUDED.created, last_run = EXCLUDED.last_run,
+			last_due = EXCLUDED.last_due, missed_runs = EXCLUDED.missed_runs,
+			jitter = EXCLUDED.jitter, runs = EXCLUDED.runs`,
+		schedule.ID, schedule.Owner, schedule.Cron, spec, nullTime(schedule.Created), nullTime(schedule.LastRun),
+		nullTime(schedule.LastDue), int32(schedule.MissedRuns), int64(schedule.Jitter), runs)
+	if err != nil {
+		return fmt.Errorf("error putting schedule: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) ListSchedules(ctx context.Context, owner string) ([]Schedule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+scheduleColumns+` FROM jobby_schedules WHERE $1 = '' OR owner = $1`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error listing schedules: %w", err)
+	}
+	defer rows.Close()
+	var schedules []Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error listing schedules: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func (s *postgresStore) DeleteSchedule(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM jobby_schedules WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error deleting schedule: %w", err)
+	}
+	return nil
+}
+
+// Expiry is by the database's clock, so servers with skewed clocks still agree
+func (s *postgresStore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	// No row comes back when someone else holds an unexpired lease
+	var got string
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO jobby_leases (name, holder, expires) VALUES ($1, $2, now() + $3 * interval '1 microsecond')
+		ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, expires = EXCLUDED.expires
+		WHERE jobby_leases.holder = EXCLUDED.holder OR jobby_leases.expires < now()
+		RETURNING holder`,
+		name, holder, ttl.Microseconds()).Scan(&got)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error acquiring lease: %w", err)
+	}
+	return true, nil
+}
+
+func (s *postgresStore) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM jobby_leases WHERE name = $1 AND holder = $2`, name, holder); err != nil {
+		return fmt.Errorf("error releasing lease: %w", err)
+	}
+	return nil
+}
+
+// Zero times are stored as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,131 @@
+// Package store keeps job metadata outside the server's memory, so it can
+// outlive the server or be shared between servers. Small deployments can
+// keep it in an embedded bbolt file, larger ones centralize it in PostgreSQL
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+)
+
+// Backends Open knows about
+const (
+	BackendBolt     = "bolt"
+	BackendPostgres = "postgres"
+)
+
+// Returned when a job or schedule isn't in the store
+var ErrNotFound = errors.New("not found")
+
+// Job is a job's metadata as of the last time it was put
+type Job struct {
+	ID    uuid.UUID
+	Owner string
+	// Server the job runs on. Jobs never move between servers, even when
+	// they share a store. Empty if the server wasn't given a name
+	Node string
+	// See ListJobs
+	Record *jobmanagerpb.JobRecord
+}
+
+// Event is one of a job's lifecycle events (see GetJobEvents)
+type Event struct {
+	JobID uuid.UUID
+	// Owner of the job, so events can be authorized after the job is gone
+	Owner   string
+	Type    string
+	Time    time.Time
+	Actor   string
+	Attempt uint32
+	Detail  string
+}
+
+// Schedule starts a job on a recurring basis
+type Schedule struct {
+	ID    string
+	Owner string
+	// Standard five field cron expression (ex: "0 3 * * *")
+	Cron string
+	Spec *jobmanagerpb.JobSpec
+	// When the schedule was first put. Zero for schedules put before
+	// servers kept track
+	Created time.Time
+	// When the schedule last started a job. Zero if it never has
+	LastRun time.Time
+	// The cron time of the last run. Zero for schedules that last ran
+	// before servers kept track, whose runs count from LastRun
+	LastDue time.Time
+	// Unspecified runs once
+	MissedRuns jobmanagerpb.MissedRunPolicy
+	Jitter     time.Duration
+	// The latest runs, oldest first
+	Runs []ScheduleRun
+}
+
+// What came of a time a schedule was due
+type ScheduleRun struct {
+	Due time.Time `json:"due"`
+	// Zero if no job was started
+	Started time.Time `json:"started"`
+	JobID   uuid.UUID `json:"job_id"`
+	// Why no job was started
+	Skipped string `json:"skipped,omitempty"`
+	// Earlier runs that were missed, which this one stood in for
+	// or was skipped along with
+	Missed int `json:"missed,omitempty"`
+}
+
+// Store is where job metadata is kept. Implementations are safe for
+// concurrent use
+type Store interface {
+	// ErrNotFound if there's no such job
+	GetJob(ctx context.Context, id uuid.UUID) (Job, error)
+	// Adds the job or replaces what was stored for it
+	PutJob(ctx context.Context, job Job) error
+	// Jobs of 'owner', or of everyone if it's empty. In no particular order
+	ListJobs(ctx context.Context, owner string) ([]Job, error)
+	// Not an error if there's no such job. The job's events are kept
+	DeleteJob(ctx context.Context, id uuid.UUID) error
+
+	AppendEvent(ctx context.Context, event Event) error
+	// Events of the job, oldest first. Empty if there are none
+	ListEvents(ctx context.Context, jobID uuid.UUID) ([]Event, error)
+	DeleteEvents(ctx context.Context, jobID uuid.UUID) error
+
+	// ErrNotFound if there's no such schedule
+	GetSchedule(ctx context.Context, id string) (Schedule, error)
+	// Adds the schedule or replaces what was stored for it
+	PutSchedule(ctx context.Context, schedule Schedule) error
+	// Schedules of 'owner', or of everyone if it's empty. In no particular order
+	ListSchedules(ctx context.Context, owner string) ([]Schedule, error)
+	// Not an error if there's no such schedule
+	DeleteSchedule(ctx context.Context, id string) error
+
+	// Take the lease called 'name' for 'holder' until 'ttl' from now, or
+	// extend it if 'holder' already has it. False if someone else has it.
+	// Servers sharing a store use leases to take turns at work only one
+	// of them should be doing (see package leader)
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Give up the lease, if 'holder' has it
+	ReleaseLease(ctx context.Context, name, holder string) error
+
+	Close() error
+}
+
+// Open connects to a store. 'location' is a file path for bolt, and a
+// connection string (ex: "postgres://jobby@db.internal/jobby") for postgres
+func Open(ctx context.Context, backend, location string) (Store, error) {
+	switch backend {
+	case BackendBolt:
+		return OpenBolt(location)
+	case BackendPostgres:
+		return OpenPostgres(ctx, location)
+	default:
+		return nil, fmt.Errorf("unknown store backend '%s'", backend)
+	}
+}
@@ -0,0 +1,192 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/store"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBolt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobby.db")
+	s, err := store.Open(context.Background(), store.BackendBolt, path)
+	require.NoError(t, err)
+	testStore(t, s)
+	require.NoError(t, s.Close())
+
+	// Everything is still there after a restart
+	s, err = store.OpenBolt(path)
+	require.NoError(t, err)
+	defer s.Close()
+	jobs, err := s.ListJobs(context.Background(), "")
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+// Needs a database to create tables in (ex: postgres://localhost/jobby_test?sslmode=disable)
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("JOBBY_TEST_POSTGRES")
+	if dsn == "" {
+		t.Skip("JOBBY_TEST_POSTGRES isn't set")
+	}
+	s, err := store.Open(context.Background(), store.BackendPostgres, dsn)
+	require.NoError(t, err)
+	defer s.Close()
+	testStore(t, s)
+}
+
+func TestUnknownBackend(t *testing.T) {
+	_, err := store.Open(context.Background(), "sqlite", "jobby.db")
+	assert.Error(t, err)
+}
+
+// What every backend must do. Leaves one job behind
+func testStore(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	t.Run("jobs", func(tt *testing.T) {
+		alice := store.Job{ID: uuid.New(), Owner: "alice", Node: "jobby-1", Record: &jobmanagerpb.JobRecord{Command: "/bin/true", Attempts: 1}}
+		bob := store.Job{ID: uuid.New(), Owner: "bob", Record: &jobmanagerpb.JobRecord{Command: "/bin/false"}}
+		require.NoError(tt, s.PutJob(ctx, alice))
+		require.NoError(tt, s.PutJob(ctx, bob))
+
+		got, err := s.GetJob(ctx, alice.ID)
+		require.NoError(tt, err)
+		assert.Equal(tt, "alice", got.Owner)
+		assert.Equal(tt, "jobby-1", got.Node)
+		assert.True(tt, proto.Equal(alice.Record, got.Record))
+
+		alice.Record.Attempts = 2
+		require.NoError(tt, s.PutJob(ctx, alice))
+		got, err = s.GetJob(ctx, alice.ID)
+		require.NoError(tt, err)
+		assert.Equal(tt, uint32(2), got.Record.Attempts)
+
+		jobs, err := s.ListJobs(ctx, "bob")
+		require.NoError(tt, err)
+		require.Len(tt, jobs, 1)
+		assert.Equal(tt, bob.ID, jobs[0].ID)
+		jobs, err = s.ListJobs(ctx, "")
+		require.NoError(tt, err)
+		assert.GreaterOrEqual(tt, len(jobs), 2)
+
+		require.NoError(tt, s.DeleteJob(ctx, bob.ID))
+		require.NoError(tt, s.DeleteJob(ctx, bob.ID))
+		_, err = s.GetJob(ctx, bob.ID)
+		assert.ErrorIs(tt, err, store.ErrNotFound)
+	})
+
+	t.Run("events", func(tt *testing.T) {
+		id, other := uuid.New(), uuid.New()
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		for i, eventType := range []string{"JOB_EVENT_TYPE_CREATED", "JOB_EVENT_TYPE_STARTED", "JOB_EVENT_TYPE_EXITED"} {
+			require.NoError(tt, s.AppendEvent(ctx, store.Event{
+				JobID: id, Owner: "alice", Type: eventType, Time: now.Add(time.Duration(i) * time.Second), Actor: "server", Attempt: uint32(i),
+			}))
+		}
+		require.NoError(tt, s.AppendEvent(ctx, store.Event{JobID: other, Owner: "bob", Type: "JOB_EVENT_TYPE_CREATED", Time: now, Actor: "bob"}))
+
+		events, err := s.ListEvents(ctx, id)
+		require.NoError(tt, err)
+		require.Len(tt, events, 3)
+		assert.Equal(tt, "JOB_EVENT_TYPE_CREATED", events[0].Type)
+		assert.Equal(tt, "JOB_EVENT_TYPE_EXITED", events[2].Type)
+		assert.Equal(tt, uint32(2), events[2].Attempt)
+		assert.True(tt, now.Add(2*time.Second).Equal(events[2].Time))
+
+		require.NoError(tt, s.DeleteEvents(ctx, id))
+		events, err = s.ListEvents(ctx, id)
+		require.NoError(tt, err)
+		assert.Empty(tt, events)
+		events, err = s.ListEvents(ctx, other)
+		require.NoError(tt, err)
+		assert.Len(tt, events, 1)
+		require.NoError(tt, s.DeleteEvents(ctx, other))
+	})
+
+	t.Run("schedules", func(tt *testing.T) {
+		nightly := store.Schedule{
+			ID:      uuid.NewString(),
+			Owner:   "alice",
+			Cron:    "0 3 * * *",
+			Spec:    &jobmanagerpb.JobSpec{Command: "/usr/bin/backup"},
+			Created: time.Now().UTC().Truncate(time.Microsecond),
+		}
+		require.NoError(tt, s.PutSchedule(ctx, nightly))
+		got, err := s.GetSchedule(ctx, nightly.ID)
+		require.NoError(tt, err)
+		assert.Equal(tt, "0 3 * * *", got.Cron)
+		assert.True(tt, got.LastRun.IsZero())
+		assert.True(tt, nightly.Created.Equal(got.Created))
+		assert.Equal(tt, "/usr/bin/backup", got.Spec.Command)
+
+		nightly.LastRun = time.Now().UTC().Truncate(time.Microsecond)
+		nightly.LastDue = nightly.LastRun.Truncate(time.Minute)
+		nightly.MissedRuns = jobmanagerpb.MissedRunPolicy_MISSED_RUN_POLICY_RUN_ALL
+		nightly.Jitter = 5 * time.Minute
+		nightly.Runs = []store.ScheduleRun{
+			{Due: nightly.LastDue.Add(-24 * time.Hour), Skipped: "missed", Missed: 2},
+			{Due: nightly.LastDue, Started: nightly.LastRun, JobID: uuid.New()},
+		}
+		require.NoError(tt, s.PutSchedule(ctx, nightly))
+		schedules, err := s.ListSchedules(ctx, "alice")
+		require.NoError(tt, err)
+		require.Len(tt, schedules, 1)
+		assert.True(tt, nightly.LastRun.Equal(schedules[0].LastRun))
+		assert.True(tt, nightly.LastDue.Equal(schedules[0].LastDue))
+		assert.Equal(tt, nightly.MissedRuns, schedules[0].MissedRuns)
+		assert.Equal(tt, nightly.Jitter, schedules[0].Jitter)
+		require.Len(tt, schedules[0].Runs, 2)
+		assert.Equal(tt, "missed", schedules[0].Runs[0].Skipped)
+		assert.Equal(tt, 2, schedules[0].Runs[0].Missed)
+		assert.Equal(tt, nightly.Runs[1].JobID, schedules[0].Runs[1].JobID)
+		assert.True(tt, nightly.Runs[1].Started.Equal(schedules[0].Runs[1].Started))
+		schedules, err = s.ListSchedules(ctx, "bob")
+		require.NoError(tt, err)
+		assert.Empty(tt, schedules)
+
+		assert.Error(tt, s.PutSchedule(ctx, store.Schedule{Spec: &jobmanagerpb.JobSpec{}}))
+		require.NoError(tt, s.DeleteSchedule(ctx, nightly.ID))
+		_, err = s.GetSchedule(ctx, nightly.ID)
+		assert.ErrorIs(tt, err, store.ErrNotFound)
+	})
+
+	t.Run("leases", func(tt *testing.T) {
+		name := uuid.NewString()
+		acquired, err := s.AcquireLease(ctx, name, "first", time.Hour)
+		require.NoError(tt, err)
+		assert.True(tt, acquired)
+		acquired, err = s.AcquireLease(ctx, name, "second", time.Hour)
+		require.NoError(tt, err)
+		assert.False(tt, acquired)
+		// Renewal
+		acquired, err = s.AcquireLease(ctx, name, "first", time.Millisecond)
+		require.NoError(tt, err)
+		assert.True(tt, acquired)
+
+		// Up for grabs once it expires
+		time.Sleep(10 * time.Millisecond)
+		acquired, err = s.AcquireLease(ctx, name, "second", time.Hour)
+		require.NoError(tt, err)
+		assert.True(tt, acquired)
+
+		// Only the holder can release it
+		require.NoError(tt, s.ReleaseLease(ctx, name, "first"))
+		acquired, err = s.AcquireLease(ctx, name, "first", time.Hour)
+		require.NoError(tt, err)
+		assert.False(tt, acquired)
+		require.NoError(tt, s.ReleaseLease(ctx, name, "second"))
+		acquired, err = s.AcquireLease(ctx, name, "first", time.Hour)
+		require.NoError(tt, err)
+		assert.True(tt, acquired)
+		require.NoError(tt, s.ReleaseLease(ctx, name, "first"))
+	})
+}
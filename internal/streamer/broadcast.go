@@ -0,0 +1,114 @@
+package streamer
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrFellBehind is returned to followers of a Broadcast that let more
+// output pile up than it keeps for them
+var ErrFellBehind = errors.New("reader fell too far behind the output")
+
+// Broadcast hands whatever is written to it to every follower. It's the read
+// path for output that isn't kept in a file a LiveFileStreamer could watch
+// (ex: output sent to a FIFO another program consumes). Followers only see
+// what's written after they start following.
+//
+// Writes never wait for followers. Each one gets up to 'maxLag' bytes of
+// unread output, after which it's cut off with ErrFellBehind
+type Broadcast struct {
+	maxLag int
+
+	lock sync.Mutex
+	// Signaled whenever there's something new for followers: output,
+	// the end of it, or one of them being closed
+	wake      *sync.Cond
+	followers map[*follower]struct{}
+	closed    bool
+}
+
+func NewBroadcast(maxLag int) *Broadcast {
+	b := &Broadcast{maxLag: maxLag, followers: map[*follower]struct{}{}}
+	b.wake = sync.NewCond(&b.lock)
+	return b
+}
+
+func (b *Broadcast) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return 0, errors.New("write to closed broadcast")
+	}
+	for f := range b.followers {
+		if len(f.pending)+len(p) > b.maxLag {
+			f.pending, f.err = nil, ErrFellBehind
+			delete(b.followers, f)
+			continue
+		}
+		f.pending = append(f.pending, p...)
+	}
+	b.wake.Broadcast()
+	return len(p), nil
+}
+
+// Close ends the output. Followers get EOF once they've read what's left
+func (b *Broadcast) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	b.wake.Broadcast()
+	return nil
+}
+
+// Follow reads what's written from now on, until the broadcast is closed.
+// Following a closed broadcast reads nothing
+func (b *Broadcast) Follow() io.ReadCloser {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	f := &follower{broadcast: b}
+	if !b.closed {
+		b.followers[f] = struct{}{}
+	}
+	return f
+}
+
+type follower struct {
+	broadcast *Broadcast
+	// Guarded by the broadcast's lock
+	pending []byte
+	// Set when the follower is cut off
+	err    error
+	closed bool
+}
+
+func (f *follower) Read(p []byte) (int, error) {
+	b := f.broadcast
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for len(f.pending) == 0 && f.err == nil && !f.closed && !b.closed {
+		b.wake.Wait()
+	}
+	switch {
+	case len(f.pending) > 0:
+		n := copy(p, f.pending)
+		f.pending = f.pending[n:]
+		return n, nil
+	case f.err != nil:
+		return 0, f.err
+	default:
+		// Like LiveFileStreamer, closing early ends the output
+		return 0, io.EOF
+	}
+}
+
+func (f *follower) Close() error {
+	b := f.broadcast
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	f.closed = true
+	f.pending = nil
+	delete(b.followers, f)
+	b.wake.Broadcast()
+	return nil
+}
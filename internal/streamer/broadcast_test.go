@@ -0,0 +1,77 @@
+package streamer_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/streamer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcast(t *testing.T) {
+	b := streamer.NewBroadcast(16)
+	_, err := b.Write([]byte("before anyone follows"))
+	require.NoError(t, err)
+
+	first := b.Follow()
+	second := b.Follow()
+	// Blocks until there's output
+	read := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(first)
+		read <- data
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_, err = b.Write([]byte("hello "))
+	require.NoError(t, err)
+	_, err = b.Write([]byte("world"))
+	require.NoError(t, err)
+
+	// Closing a follower early ends its output without touching the others
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(second, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+	require.NoError(t, second.Close())
+	_, err = second.Read(buf)
+	assert.ErrorIs(t, err, io.EOF)
+
+	require.NoError(t, b.Close())
+	select {
+	case data := <-read:
+		assert.Equal(t, "hello world", string(data))
+	case <-time.After(5 * time.Second):
+		t.Fatal("follower didn't see the end of the output")
+	}
+	_, err = b.Write([]byte("more"))
+	assert.Error(t, err)
+
+	data, err := io.ReadAll(b.Follow())
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+func TestBroadcastFellBehind(t *testing.T) {
+	b := streamer.NewBroadcast(8)
+	slow := b.Follow()
+	fast := b.Follow()
+
+	_, err := b.Write([]byte("12345"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(fast, buf)
+	require.NoError(t, err)
+	// Too much for the follower that hasn't read anything. Writes still succeed
+	n, err := b.Write([]byte("67890"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	_, err = slow.Read(buf)
+	assert.ErrorIs(t, err, streamer.ErrFellBehind)
+	_, err = io.ReadFull(fast, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "67890", string(buf))
+}
@@ -24,14 +24,19 @@ import (
 type LiveFileStreamer struct {
 	// The file to 'LiveStream' from
 	file *os.File
-
-	// A FileWriteWatcher that is watching the associated
-	// File handle
-	writeWatcher *FileWriteWatcher
+	// Needed to re-create the watcher after a pause
+	path string
 
 	// Indicates that the file will receive no more writes
 	writerDone chan struct{}
 
+	// Guards the fields below. Close may be called from any goroutine
+	lock sync.Mutex
+	// A FileWriteWatcher that is watching the associated
+	// File handle. Nil while paused
+	writeWatcher *FileWriteWatcher
+	closed       bool
+
 	// manage close behavior
 	closeOnce *sync.Once
 }
@@ -48,7 +53,7 @@ func NewLiveFileStreamer(path string, writerDone chan struct{}) (*LiveFileStream
 		return nil, fmt.Errorf("failed to create watcher: %w", err)
 	}
 
-	return &LiveFileStreamer{file: readHandle, writeWatcher: watcher, writerDone: writerDone, closeOnce: &sync.Once{}}, nil
+	return &LiveFileStreamer{file: readHandle, path: path, writeWatcher: watcher, writerDone: writerDone, closeOnce: &sync.Once{}}, nil
 }
 
 func (l *LiveFileStreamer) Read(p []byte) (int, error) {
@@ -61,11 +66,25 @@ func (l *LiveFileStreamer) Read(p []byte) (int, error) {
 			return count, err
 		}
 
+		watcher, rearmed, err := l.watcher()
+		if err != nil {
+			return 0, err
+		}
+		if watcher == nil {
+			// No more writes are coming. This is the last read
+			return l.file.Read(p)
+		}
+		if rearmed {
+			// The file may have been written to before
+			// the new watch was in place
+			continue
+		}
+
 		select {
-		case _, ok := <-l.writeWatcher.Events():
+		case _, ok := <-watcher.Events():
 			if !ok {
-				if l.writeWatcher.Error() != nil {
-					return 0, fmt.Errorf("watcher encountered unexpected error: %w", l.writeWatcher.Error())
+				if watcher.Error() != nil {
+					return 0, fmt.Errorf("watcher encountered unexpected error: %w", watcher.Error())
 				}
 				return l.file.Read(p)
 			}
@@ -73,10 +92,10 @@ func (l *LiveFileStreamer) Read(p []byte) (int, error) {
 			// Do not take this path again
 			l.writerDone = nil
 			// We must take care to drain the watcher channel
-			err := l.writeWatcher.Close()
+			err := watcher.Close()
 			if err != nil {
 				// That's not good
-				for range l.writeWatcher.Events() {
+				for range watcher.Events() {
 				}
 				return 0, err
 			}
@@ -84,18 +103,76 @@ func (l *LiveFileStreamer) Read(p []byte) (int, error) {
 	}
 }
 
+// Returns the current watcher, re-creating it if we were paused.
+// Nil if the writer is done (or we're closed) and there is nothing to wait for
+func (l *LiveFileStreamer) watcher() (*FileWriteWatcher, bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.writeWatcher != nil {
+		return l.writeWatcher, false, nil
+	}
+	if l.closed || l.writerDone == nil {
+		return nil, false, nil
+	}
+	select {
+	case <-l.writerDone:
+		l.writerDone = nil
+		return nil, false, nil
+	default:
+	}
+
+	watcher, err := NewWatcher(l.path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to re-create watcher: %w", err)
+	}
+	l.writeWatcher = watcher
+	return watcher, true, nil
+}
+
+// Pause releases the file watch until the next call to Read, so writes
+// to the file stop waking us up while nobody is reading.
+// Useful when the consumer of our output falls behind.
+// Must not be called concurrently with Read
+func (l *LiveFileStreamer) Pause() error {
+	l.lock.Lock()
+	watcher := l.writeWatcher
+	l.writeWatcher = nil
+	l.lock.Unlock()
+
+	if watcher == nil {
+		return nil
+	}
+	err := watcher.Close()
+	// Drain events channel as per our contract
+	// with the WriteWatcher
+	for range watcher.Events() {
+	}
+	return err
+}
+
 // Safe for multiple calls, but subsequent
 // calls are ineffectual and always return nil
 func (l *LiveFileStreamer) Close() error {
 	var err error
 	l.closeOnce.Do(func() {
+		l.lock.Lock()
+		l.closed = true
+		watcher := l.writeWatcher
+		l.lock.Unlock()
+
+		var watchErr error
+		if watcher != nil {
+			watchErr = watcher.Close()
+		}
 		err = errors.Join(
-			l.writeWatcher.Close(),
+			watchErr,
 			l.file.Close(),
 		)
-		// Drain events channel as per our contract
-		// with the WriteWatcher
-		for range l.writeWatcher.Events() {
+		if watcher != nil {
+			// Drain events channel as per our contract
+			// with the WriteWatcher
+			for range watcher.Events() {
+			}
 		}
 	})
 	return err
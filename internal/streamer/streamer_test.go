@@ -3,9 +3,11 @@ package streamer_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -147,3 +149,42 @@ func NewTestResources(dir string) (TestResources, error) {
 		WriteHandle: testFile,
 	}, allErrs
 }
+
+// Many streamers reading finished output at once, as when lots of clients
+// fetch output together. Reading with plain read(2) calls is the baseline
+// any other read path must beat: one submitting the reads on shared
+// io_uring rings was tried against it, and was slower
+func BenchmarkLiveFileStreamer(b *testing.B) {
+	const streams = 64
+	const fileSize = 1 << 20
+	dir := b.TempDir()
+	data := bytes.Repeat([]byte("0123456789abcdef"), fileSize/16)
+	for i := range streams {
+		require.NoError(b, os.WriteFile(filepath.Join(dir, fmt.Sprint(i)), data, 0666))
+	}
+
+	b.SetBytes(streams * fileSize)
+	for b.Loop() {
+		var wg sync.WaitGroup
+		for i := range streams {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				done := make(chan struct{})
+				close(done)
+				testStreamer, err := streamer.NewLiveFileStreamer(filepath.Join(dir, fmt.Sprint(i)), done)
+				if err != nil {
+					b.Error(err)
+					return
+				}
+				defer testStreamer.Close()
+				// Output is read in message sized chunks
+				buf := make([]byte, 4096)
+				if _, err := io.CopyBuffer(io.Discard, struct{ io.Reader }{testStreamer}, buf); err != nil {
+					b.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
@@ -72,3 +72,53 @@ func TestHappyRead(t *testing.T) {
 
 	assert.NoError(t, fs.Close())
 }
+
+// Pausing releases the watch and the next Read picks up where we left off,
+// including writes made while paused
+func TestPauseResume(t *testing.T) {
+	writeHandle, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer writeHandle.Close()
+	done := make(chan struct{})
+	fs, err := NewLiveFileStreamer(writeHandle.Name(), done)
+	require.NoError(t, err)
+
+	_, err = writeHandle.Write([]byte("before"))
+	require.NoError(t, err)
+	buf := make([]byte, 6)
+	_, err = io.ReadFull(fs, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "before", string(buf))
+
+	require.NoError(t, fs.Pause())
+	assert.Nil(t, fs.writeWatcher)
+	// Pausing twice is harmless
+	require.NoError(t, fs.Pause())
+
+	_, err = writeHandle.Write([]byte("paused"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(fs, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "paused", string(buf))
+
+	// Blocks on the re-created watch until the next write
+	readDone := make(chan error)
+	go func() {
+		_, err := io.ReadFull(fs, buf)
+		readDone <- err
+	}()
+	_, err = writeHandle.Write([]byte("resume"))
+	require.NoError(t, err)
+	require.NoError(t, <-readDone)
+	assert.Equal(t, "resume", string(buf))
+
+	// A paused streamer still finishes once the writer is done
+	require.NoError(t, fs.Pause())
+	close(done)
+	count, err := fs.Read(buf)
+	assert.Zero(t, count)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Nil(t, fs.writeWatcher)
+
+	assert.NoError(t, fs.Close())
+}
@@ -0,0 +1,250 @@
+// Package templates renders job templates: commands operators define once
+// in the server's config, which users start by name with parameters
+// (ex: a backup job taking the database to back up). Parameters are typed
+// and checked before anything runs, and each fills in part of an
+// argument, never more, so values can't add arguments or reach a shell
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Parameter types
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeBool   = "bool"
+	TypeEnum   = "enum"
+)
+
+// Param is a value users fill in, written '{{name}}' in the template
+type Param struct {
+	Name        string
+	Type        string
+	Description string
+	// Used when the parameter isn't given. Parameters without one must be
+	Default *string
+	// int only. Both inclusive
+	Min, Max *int64
+	// string only. A regexp the whole value must match, and a limit on
+	// its length in bytes (0 for none)
+	Pattern   string
+	MaxLength int
+	// enum only. The values allowed
+	Values []string
+
+	pattern *regexp.Regexp
+}
+
+// Template is a command with parameters
+type Template struct {
+	Name        string
+	Description string
+	Command     string
+	Args        []string
+	// By name
+	Params []Param
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]*)\s*\}\}`)
+
+var paramNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// New checks a template and gets it ready to render. Every placeholder
+// must name one of its parameters, and their defaults must be valid
+func New(t Template) (*Template, error) {
+	if t.Command == "" {
+		return nil, errors.New("command must not be empty")
+	}
+	t.Args = slices.Clone(t.Args)
+	t.Params = slices.Clone(t.Params)
+	slices.SortFunc(t.Params, func(a, b Param) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for i := range t.Params {
+		p := &t.Params[i]
+		if !paramNamePattern.MatchString(p.Name) {
+			return nil, fmt.Errorf("invalid parameter name '%s'", p.Name)
+		}
+		if i > 0 && t.Params[i-1].Name == p.Name {
+			return nil, fmt.Errorf("parameter '%s' is declared twice", p.Name)
+		}
+		if err := p.compile(); err != nil {
+			return nil, fmt.Errorf("parameter '%s': %w", p.Name, err)
+		}
+	}
+	for _, part := range append([]string{t.Command}, t.Args...) {
+		if err := t.checkPlaceholders(part); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+func (p *Param) compile() error {
+	if p.Type == "" {
+		p.Type = TypeString
+	}
+	switch p.Type {
+	case TypeString:
+		if p.Pattern != "" {
+			pattern, err := regexp.Compile(`^(?:` + p.Pattern + `)$`)
+			if err != nil {
+				return fmt.Errorf("invalid pattern: %w", err)
+			}
+			p.pattern = pattern
+		}
+		if p.MaxLength < 0 {
+			return errors.New("max_length must not be negative")
+		}
+	case TypeInt:
+		if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+			return errors.New("min must not be greater than max")
+		}
+	case TypeBool:
+	case TypeEnum:
+		if len(p.Values) == 0 {
+			return errors.New("enum parameters must have values")
+		}
+	default:
+		return fmt.Errorf("unknown type '%s'. Must be one of string, int, bool or enum", p.Type)
+	}
+	if p.Type != TypeInt && (p.Min != nil || p.Max != nil) {
+		return errors.New("only int parameters may have a min or max")
+	}
+	if p.Type != TypeString && (p.Pattern != "" || p.MaxLength != 0) {
+		return errors.New("only string parameters may have a pattern or max_length")
+	}
+	if p.Type != TypeEnum && len(p.Values) > 0 {
+		return errors.New("only enum parameters may have values")
+	}
+	if p.Default != nil {
+		if _, err := p.check(*p.Default); err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *Template) checkPlaceholders(text string) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if t.param(match[1]) == nil {
+			return fmt.Errorf("placeholder '%s' names no parameter", match[0])
+		}
+	}
+	if rest := placeholderPattern.ReplaceAllString(text, ""); strings.Contains(rest, "{{") {
+		return fmt.Errorf("unterminated placeholder in '%s'", text)
+	}
+	return nil
+}
+
+func (t *Template) param(name string) *Param {
+	i, found := slices.BinarySearchFunc(t.Params, name, func(p Param, name string) int {
+		return strings.Compare(p.Name, name)
+	})
+	if !found {
+		return nil
+	}
+	return &t.Params[i]
+}
+
+// Check 'value' against the parameter. Returns it as it's filled in (ex:
+// "007" becomes "7" for ints)
+func (p *Param) check(value string) (string, error) {
+	switch p.Type {
+	case TypeInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("must be an integer, got %q", value)
+		}
+		switch {
+		case p.Min != nil && p.Max != nil && (n < *p.Min || n > *p.Max):
+			return "", fmt.Errorf("must be between %d and %d, got %d", *p.Min, *p.Max, n)
+		case p.Min != nil && n < *p.Min:
+			return "", fmt.Errorf("must be at least %d, got %d", *p.Min, n)
+		case p.Max != nil && n > *p.Max:
+			return "", fmt.Errorf("must be at most %d, got %d", *p.Max, n)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case TypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("must be true or false, got %q", value)
+		}
+		return strconv.FormatBool(b), nil
+	case TypeEnum:
+		if !slices.Contains(p.Values, value) {
+			return "", fmt.Errorf("must be one of %s, got %q", strings.Join(p.Values, ", "), value)
+		}
+		return value, nil
+	default:
+		if strings.ContainsRune(value, 0) {
+			return "", errors.New("must not contain NUL bytes")
+		}
+		if p.MaxLength > 0 && len(value) > p.MaxLength {
+			return "", fmt.Errorf("must be at most %d bytes, got %d", p.MaxLength, len(value))
+		}
+		if p.pattern != nil && !p.pattern.MatchString(value) {
+			return "", fmt.Errorf("must match %s, got %q", p.Pattern, value)
+		}
+		return value, nil
+	}
+}
+
+// Resolve checks 'values' against the parameters and fills in defaults.
+// Every problem is reported, one per parameter, in order of name
+func (t *Template) Resolve(values map[string]string) (map[string]string, error) {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if t.param(name) == nil {
+			errs = append(errs, fmt.Errorf("unknown parameter '%s'", name))
+		}
+	}
+	resolved := make(map[string]string, len(t.Params))
+	for i := range t.Params {
+		p := &t.Params[i]
+		value, ok := values[p.Name]
+		if !ok {
+			if p.Default == nil {
+				errs = append(errs, fmt.Errorf("parameter '%s' is required", p.Name))
+				continue
+			}
+			value = *p.Default
+		}
+		checked, err := p.check(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parameter '%s' %w", p.Name, err))
+			continue
+		}
+		resolved[p.Name] = checked
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return resolved, nil
+}
+
+// Render fills in the template with 'values', as Resolve checks them.
+// Returns the command and args to run, and the values used
+func (t *Template) Render(values map[string]string) (command string, args []string, resolved map[string]string, err error) {
+	resolved, err = t.Resolve(values)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	fill := func(text string) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			return resolved[placeholderPattern.FindStringSubmatch(placeholder)[1]]
+		})
+	}
+	command = fill(t.Command)
+	for _, arg := range t.Args {
+		args = append(args, fill(arg))
+	}
+	return command, args, resolved, nil
+}
@@ -0,0 +1,95 @@
+package templates_test
+
+import (
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func backup(t *testing.T) *templates.Template {
+	t.Helper()
+	tmpl, err := templates.New(templates.Template{
+		Name:    "backup",
+		Command: "/usr/local/bin/backup",
+		Args:    []string{"backup", "--db={{ database }}", "--keep={{keep}}", "--mode={{mode}}", "--verify={{verify}}"},
+		Params: []templates.Param{
+			{Name: "database", Pattern: "[a-z]+", MaxLength: 16},
+			{Name: "keep", Type: templates.TypeInt, Min: ptr[int64](1), Max: ptr[int64](30), Default: ptr("7")},
+			{Name: "mode", Type: templates.TypeEnum, Values: []string{"full", "incremental"}, Default: ptr("full")},
+			{Name: "verify", Type: templates.TypeBool, Default: ptr("false")},
+		},
+	})
+	require.NoError(t, err)
+	return tmpl
+}
+
+func TestRender(t *testing.T) {
+	tmpl := backup(t)
+
+	command, args, resolved, err := tmpl.Render(map[string]string{"database": "orders", "keep": "014", "verify": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/local/bin/backup", command)
+	assert.Equal(t, []string{"backup", "--db=orders", "--keep=14", "--mode=full", "--verify=true"}, args)
+	assert.Equal(t, map[string]string{"database": "orders", "keep": "14", "mode": "full", "verify": "true"}, resolved)
+
+	// Values fill in one argument, whatever they hold
+	tmpl, err = templates.New(templates.Template{Command: "/bin/echo", Args: []string{"{{text}}"}, Params: []templates.Param{{Name: "text"}}})
+	require.NoError(t, err)
+	_, args, _, err = tmpl.Render(map[string]string{"text": "a b; rm -rf {{text}}"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a b; rm -rf {{text}}"}, args)
+}
+
+func TestRenderErrors(t *testing.T) {
+	tmpl := backup(t)
+	for _, tc := range []struct {
+		name   string
+		values map[string]string
+		err    string
+	}{
+		{"missing", map[string]string{}, "parameter 'database' is required"},
+		{"unknown", map[string]string{"database": "orders", "db": "x"}, "unknown parameter 'db'"},
+		{"pattern", map[string]string{"database": "Orders"}, `parameter 'database' must match [a-z]+, got "Orders"`},
+		{"length", map[string]string{"database": "abcdefghijklmnopq"}, "parameter 'database' must be at most 16 bytes, got 17"},
+		{"not an int", map[string]string{"database": "orders", "keep": "a week"}, `parameter 'keep' must be an integer, got "a week"`},
+		{"range", map[string]string{"database": "orders", "keep": "31"}, "parameter 'keep' must be between 1 and 30, got 31"},
+		{"enum", map[string]string{"database": "orders", "mode": "partial"}, `parameter 'mode' must be one of full, incremental, got "partial"`},
+		{"bool", map[string]string{"database": "orders", "verify": "yes"}, `parameter 'verify' must be true or false, got "yes"`},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			_, _, _, err := tmpl.Render(tc.values)
+			assert.EqualError(tt, err, tc.err)
+		})
+	}
+
+	// Every problem at once
+	_, err := tmpl.Resolve(map[string]string{"keep": "0", "mode": "partial"})
+	assert.EqualError(t, err, "parameter 'database' is required\n"+
+		"parameter 'keep' must be between 1 and 30, got 0\n"+
+		`parameter 'mode' must be one of full, incremental, got "partial"`)
+}
+
+func TestNewErrors(t *testing.T) {
+	for name, tmpl := range map[string]templates.Template{
+		"no command":        {},
+		"undeclared":        {Command: "/bin/echo", Args: []string{"{{text}}"}},
+		"unterminated":      {Command: "/bin/echo", Args: []string{"{{text"}, Params: []templates.Param{{Name: "text"}}},
+		"bad name":          {Command: "/bin/echo", Params: []templates.Param{{Name: "a-b"}}},
+		"twice":             {Command: "/bin/echo", Params: []templates.Param{{Name: "a"}, {Name: "a"}}},
+		"unknown type":      {Command: "/bin/echo", Params: []templates.Param{{Name: "a", Type: "float"}}},
+		"bad pattern":       {Command: "/bin/echo", Params: []templates.Param{{Name: "a", Pattern: "("}}},
+		"min over max":      {Command: "/bin/echo", Params: []templates.Param{{Name: "a", Type: "int", Min: ptr[int64](2), Max: ptr[int64](1)}}},
+		"range on a string": {Command: "/bin/echo", Params: []templates.Param{{Name: "a", Max: ptr[int64](1)}}},
+		"empty enum":        {Command: "/bin/echo", Params: []templates.Param{{Name: "a", Type: "enum"}}},
+		"bad default":       {Command: "/bin/echo", Params: []templates.Param{{Name: "a", Type: "int", Default: ptr("x")}}},
+	} {
+		_, err := templates.New(tmpl)
+		assert.Error(t, err, name)
+	}
+}
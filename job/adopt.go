@@ -0,0 +1,92 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"golang.org/x/sys/unix"
+)
+
+type AdoptArgs struct {
+	// Of a running process that isn't ours (ex: a daemon started by init)
+	PID int
+	// Files the process already writes its output to. They may be the same
+	// file. Read like any job's output, but never created or truncated
+	StdoutPath string
+	StderrPath string
+	// See JobArgs.OnSignal
+	OnSignal func(signal syscall.Signal, reason ExitReason)
+	// Nil uses the real one
+	Clock clock.Clock
+}
+
+// Adopt tracks a process someone else started as a job, so it can be
+// followed and stopped like one. Only its exit is seen, not its exit
+// code: the process isn't our child, so we can't reap it. Such jobs end
+// with ExitReasonUnknown unless we stopped them. Times are from the adoption
+func Adopt(args AdoptArgs) (*Job, error) {
+	if args.PID <= 1 || args.PID == os.Getpid() {
+		return nil, fmt.Errorf("can't adopt process %d", args.PID)
+	}
+	for _, path := range []string{args.StdoutPath, args.StderrPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("error checking output file: %w", err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("output file '%s' is not a regular file", path)
+		}
+	}
+
+	// Tells us when the process exits, and can't be fooled by its PID
+	// being reused afterwards
+	pidfd, err := unix.PidfdOpen(args.PID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error opening process %d: %w", args.PID, err)
+	}
+	// Signals through a pidfd of its own
+	process, err := os.FindProcess(args.PID)
+	if err != nil {
+		_ = unix.Close(pidfd)
+		return nil, fmt.Errorf("error opening process %d: %w", args.PID, err)
+	}
+
+	jobClock := clock.Or(args.Clock)
+	newJob := &Job{
+		cmd:        exec.Cmd{Process: process},
+		stdoutPath: args.StdoutPath,
+		stderrPath: args.StderrPath,
+		onSignal:   args.OnSignal,
+		adopted:    true,
+		state:      newLifecycle(),
+		clock:      jobClock,
+		startTime:  jobClock.Now(),
+	}
+	go func() {
+		if err := waitPidfd(pidfd); err != nil {
+			slog.Error("Failed to wait for adopted process", "pid", args.PID, "error", err)
+		}
+		_ = unix.Close(pidfd)
+		newJob.signalLock.Lock()
+		// No ProcessState: only the process's parent learns how it exited
+		newJob.state.exited(&exitInfo{endTime: jobClock.Now()})
+		newJob.signalLock.Unlock()
+	}()
+	return newJob, nil
+}
+
+// Blocks until the process behind 'pidfd' has exited
+func waitPidfd(pidfd int) error {
+	fds := []unix.PollFd{{Fd: int32(pidfd), Events: unix.POLLIN}}
+	for {
+		_, err := unix.Poll(fds, -1)
+		if !errors.Is(err, unix.EINTR) {
+			return err
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package job
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// What a process isolated in its own network namespace may reach
+type EgressMode int
+
+const (
+	// Nothing at all. No interfaces are up, not even loopback
+	EgressNone EgressMode = iota
+	// Only loopback is up
+	EgressLoopback
+	// Connected to the host through a veth pair. nftables rules
+	// in the namespace drop traffic to anything not on the allowlist
+	EgressAllowlist
+)
+
+// Egress controls outbound traffic of jobs with network isolation.
+// Ignored unless Isolation.Network is set.
+// Rules live in the job's namespace, so a job with CAP_NET_ADMIN
+// (ex: running as root) is able to remove them
+type Egress struct {
+	Mode EgressMode
+	// Destinations EgressAllowlist jobs may reach. Loopback is always allowed
+	Allow []EgressRule
+	// Addresses for the veth pairs of EgressAllowlist jobs. Required by that mode
+	Uplinks *UplinkPool
+}
+
+type EgressRule struct {
+	Prefix netip.Prefix
+	// TCP or UDP destination port. Zero allows every port and protocol
+	Port uint16
+}
+
+// Name of the interface inside the job's namespace
+const uplinkJobName = "eth0"
+
+// UplinkPool hands out the /30 subnets connecting EgressAllowlist jobs to the
+// host. The host needs IP forwarding and NAT for the pool's prefix for
+// jobs to reach anything past it. May be shared between jobs
+type UplinkPool struct {
+	lock   sync.Mutex
+	prefix netip.Prefix
+	inUse  []bool
+	// Where to start looking for a free subnet. Handing them out round
+	// robin gives the kernel time to clean up after finished jobs
+	next int
+}
+
+// NewUplinkPool carves /30 subnets out of the IPv4 'prefix'
+func NewUplinkPool(prefix netip.Prefix) (*UplinkPool, error) {
+	if !prefix.Addr().Is4() || prefix.Bits() > 30 {
+		return nil, fmt.Errorf("uplink prefix '%s' must be an IPv4 prefix of /30 or bigger", prefix)
+	}
+	return &UplinkPool{
+		prefix: prefix.Masked(),
+		inUse:  make([]bool, 1<<(30-prefix.Bits())),
+	}, nil
+}
+
+// Addresses of one veth pair
+type uplink struct {
+	index int
+	// Name of the host side interface
+	hostName string
+	host     netip.Prefix
+	job      netip.Prefix
+}
+
+func (p *UplinkPool) acquire() (uplink, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for range p.inUse {
+		index := p.next
+		p.next = (p.next + 1) % len(p.inUse)
+		if p.inUse[index] {
+			continue
+		}
+		p.inUse[index] = true
+
+		base := p.prefix.Addr().As4()
+		subnet := binary.BigEndian.Uint32(base[:]) + uint32(index)*4
+		return uplink{
+			index:    index,
+			hostName: fmt.Sprintf("jobby%d", index),
+			host:     netip.PrefixFrom(addrFromUint32(subnet+1), 30),
+			job:      netip.PrefixFrom(addrFromUint32(subnet+2), 30),
+		}, nil
+	}
+	return uplink{}, errors.New("no free uplink subnets")
+}
+
+func (p *UplinkPool) release(u uplink) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.inUse[u.index] = false
+}
+
+func addrFromUint32(v uint32) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return netip.AddrFrom4(b)
+}
+
+func prefixToIPNet(p netip.Prefix) *net.IPNet {
+	return &net.IPNet{
+		IP:   p.Addr().AsSlice(),
+		Mask: net.CIDRMask(p.Bits(), p.Addr().BitLen()),
+	}
+}
+
+// A network namespace set up for a job. Nil if there's nothing to clean up
+type network struct {
+	uplinks *UplinkPool
+	uplink  uplink
+}
+
+// Disconnect the job from the host. The namespace itself goes away with the job.
+// From inside the namespace (when setup fails) the host side isn't visible,
+// but it goes away along with the namespace too
+func (n *network) close() error {
+	if n == nil {
+		return nil
+	}
+	defer n.uplinks.release(n.uplink)
+	link, err := netlink.LinkByName(n.uplink.hostName)
+	if errors.As(err, &netlink.LinkNotFoundError{}) {
+		// Already removed along with the namespace
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error finding uplink: %w", err)
+	}
+	if err := netlink.LinkDel(link); err != nil {
+		return fmt.Errorf("error removing uplink: %w", err)
+	}
+	return nil
+}
+
+// Whether the namespace has to be set up before the process starts.
+// Otherwise the process gets a fresh (empty) one from clone(2)
+func (i Isolation) setupNetwork() bool {
+	return i.Network && i.Egress.Mode != EgressNone
+}
+
+// Move the calling (locked) thread into a new network namespace and set it up
+func setupNetwork(egress Egress) (*network, error) {
+	hostNS, err := netns.Get()
+	if err != nil {
+		return nil, fmt.Errorf("error opening network namespace: %w", err)
+	}
+	defer hostNS.Close()
+	if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+		return nil, fmt.Errorf("error creating network namespace: %w", err)
+	}
+
+	lo, err := netlink.LinkByName("lo")
+	if err == nil {
+		err = netlink.LinkSetUp(lo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error bringing up loopback: %w", err)
+	}
+	if egress.Mode == EgressLoopback {
+		return nil, nil
+	}
+
+	if egress.Uplinks == nil {
+		return nil, errors.New("allowlist egress requires an uplink pool")
+	}
+	u, err := egress.Uplinks.acquire()
+	if err != nil {
+		return nil, err
+	}
+	n := &network{uplinks: egress.Uplinks, uplink: u}
+	if err := connectUplink(hostNS, u); err != nil {
+		_ = n.close()
+		return nil, err
+	}
+	if err := applyEgressRules(egress.Allow); err != nil {
+		_ = n.close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// Create the veth pair between the current namespace and 'hostNS'
+func connectUplink(hostNS netns.NsHandle, u uplink) error {
+	veth := &netlink.Veth{
+		LinkAttrs:     netlink.LinkAttrs{Name: uplinkJobName},
+		PeerName:      u.hostName,
+		PeerNamespace: netlink.NsFd(hostNS),
+	}
+	if err := netlink.LinkAdd(veth); err != nil {
+		return fmt.Errorf("error creating uplink: %w", err)
+	}
+
+	host, err := netlink.NewHandleAt(hostNS)
+	if err != nil {
+		return fmt.Errorf("error opening host namespace: %w", err)
+	}
+	defer host.Close()
+	hostLink, err := host.LinkByName(u.hostName)
+	if err != nil {
+		return fmt.Errorf("error finding uplink: %w", err)
+	}
+	if err := host.AddrAdd(hostLink, &netlink.Addr{IPNet: prefixToIPNet(u.host)}); err != nil {
+		return fmt.Errorf("error addressing uplink: %w", err)
+	}
+	if err := host.LinkSetUp(hostLink); err != nil {
+		return fmt.Errorf("error bringing up uplink: %w", err)
+	}
+
+	jobLink, err := netlink.LinkByName(uplinkJobName)
+	if err != nil {
+		return fmt.Errorf("error finding uplink: %w", err)
+	}
+	if err := netlink.AddrAdd(jobLink, &netlink.Addr{IPNet: prefixToIPNet(u.job)}); err != nil {
+		return fmt.Errorf("error addressing uplink: %w", err)
+	}
+	if err := netlink.LinkSetUp(jobLink); err != nil {
+		return fmt.Errorf("error bringing up uplink: %w", err)
+	}
+	route := &netlink.Route{LinkIndex: jobLink.Attrs().Index, Gw: u.host.Addr().AsSlice()}
+	if err := netlink.RouteAdd(route); err != nil {
+		return fmt.Errorf("error adding default route: %w", err)
+	}
+	return nil
+}
+
+// Drop outgoing traffic from the current namespace unless it's to loopback or the allowlist.
+// Incoming traffic is left alone, there's nothing listening on the host side of the uplink
+func applyEgressRules(allow []EgressRule) error {
+	conn, err := nftables.New()
+	if err != nil {
+		return fmt.Errorf("error connecting to nftables: %w", err)
+	}
+	table := conn.AddTable(&nftables.Table{Family: nftables.TableFamilyINet, Name: "jobby"})
+	policy := nftables.ChainPolicyDrop
+	chain := conn.AddChain(&nftables.Chain{
+		Name:     "egress",
+		Table:    table,
+		Type:     nftables.ChainTypeFilter,
+		Hooknum:  nftables.ChainHookOutput,
+		Priority: nftables.ChainPriorityFilter,
+		Policy:   &policy,
+	})
+
+	conn.AddRule(&nftables.Rule{Table: table, Chain: chain, Exprs: []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: interfaceName("lo")},
+		&expr.Verdict{Kind: expr.VerdictAccept},
+	}})
+	for _, rule := range allow {
+		for _, exprs := range egressRuleExprs(rule) {
+			conn.AddRule(&nftables.Rule{Table: table, Chain: chain, Exprs: exprs})
+		}
+	}
+
+	if err := conn.Flush(); err != nil {
+		return fmt.Errorf("error applying egress rules: %w", err)
+	}
+	return nil
+}
+
+// Interface names are compared as fixed size, NUL padded strings
+func interfaceName(name string) []byte {
+	data := make([]byte, unix.IFNAMSIZ)
+	copy(data, name)
+	return data
+}
+
+// The nftables rules accepting traffic to 'rule'. Rules with
+// a port take one for each of TCP and UDP
+func egressRuleExprs(rule EgressRule) [][]expr.Any {
+	family, offset := byte(unix.NFPROTO_IPV4), uint32(16)
+	if rule.Prefix.Addr().Is6() {
+		family, offset = unix.NFPROTO_IPV6, 24
+	}
+	prefix := rule.Prefix.Masked()
+	addr := prefix.Addr().AsSlice()
+	mask := net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen())
+	destination := []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyNFPROTO, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{family}},
+		&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: offset, Len: uint32(len(addr))},
+		&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: uint32(len(addr)), Mask: mask, Xor: make([]byte, len(addr))},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: addr},
+	}
+	accept := &expr.Verdict{Kind: expr.VerdictAccept}
+	if rule.Port == 0 {
+		return [][]expr.Any{append(destination, accept)}
+	}
+
+	port := binary.BigEndian.AppendUint16(nil, rule.Port)
+	var rules [][]expr.Any
+	for _, protocol := range []byte{unix.IPPROTO_TCP, unix.IPPROTO_UDP} {
+		exprs := append([]expr.Any{}, destination...)
+		exprs = append(exprs,
+			&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{protocol}},
+			&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: port},
+			accept,
+		)
+		rules = append(rules, exprs)
+	}
+	return rules
+}
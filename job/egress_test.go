@@ -0,0 +1,36 @@
+package job
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUplinkPool(t *testing.T) {
+	for _, prefix := range []string{"10.0.0.0/31", "fd00::/64"} {
+		_, err := NewUplinkPool(netip.MustParsePrefix(prefix))
+		assert.Error(t, err, prefix)
+	}
+
+	// Room for two jobs
+	pool, err := NewUplinkPool(netip.MustParsePrefix("10.1.2.3/29"))
+	require.NoError(t, err)
+	first, err := pool.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParsePrefix("10.1.2.1/30"), first.host)
+	assert.Equal(t, netip.MustParsePrefix("10.1.2.2/30"), first.job)
+	second, err := pool.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParsePrefix("10.1.2.5/30"), second.host)
+	assert.Equal(t, netip.MustParsePrefix("10.1.2.6/30"), second.job)
+	assert.NotEqual(t, first.hostName, second.hostName)
+
+	_, err = pool.acquire()
+	assert.Error(t, err)
+	pool.release(first)
+	again, err := pool.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+}
@@ -0,0 +1,125 @@
+package job
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Fault is a point in the job runner that failures can be injected into
+// (see FaultInjector)
+type Fault string
+
+const (
+	// Creating an output file as the job starts
+	FaultCreateOutput Fault = "CREATE_OUTPUT"
+	// Starting the process
+	FaultStart Fault = "START"
+	// Reaping the process. Only delays apply: the job still exits
+	FaultWait Fault = "WAIT"
+	// Opening a reader of the job's output (ex: Stdout)
+	FaultWatch Fault = "WATCH"
+	// Each read from a reader of the job's output
+	FaultRead Fault = "READ"
+)
+
+// Injection is what happens when a fault point is reached
+type Injection struct {
+	// Returned from the point. Nil only delays
+	Err error
+	// How long to block first
+	Delay time.Duration
+	// Hits to let through before injecting
+	Skip int
+	// Hits to inject into after the skipped ones. Zero means all of them
+	Times int
+}
+
+// FaultInjector makes the job runner fail (or stall) at chosen points, so
+// tests can exercise error handling that's hard to trigger for real (see
+// JobArgs.Faults). A nil FaultInjector injects nothing, which is all
+// the server ever uses
+type FaultInjector struct {
+	lock       sync.Mutex
+	injections map[Fault]*Injection
+	hits       map[Fault]int
+}
+
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{injections: map[Fault]*Injection{}, hits: map[Fault]int{}}
+}
+
+// Inject 'injection' into 'point' from now on, replacing any earlier one
+func (f *FaultInjector) Inject(point Fault, injection Injection) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.injections[point] = &injection
+}
+
+// Clear stops injecting into 'point'
+func (f *FaultInjector) Clear(point Fault) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.injections, point)
+}
+
+// Hits is the number of times 'point' was reached, injected into or not
+func (f *FaultInjector) Hits(point Fault) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.hits[point]
+}
+
+// Called on reaching 'point'. Returns the error to fail with, if any
+func (f *FaultInjector) hit(point Fault) error {
+	if f == nil {
+		return nil
+	}
+	f.lock.Lock()
+	f.hits[point]++
+	injection := f.injections[point]
+	var delay time.Duration
+	var err error
+	switch {
+	case injection == nil:
+	case injection.Skip > 0:
+		injection.Skip--
+	default:
+		delay, err = injection.Delay, injection.Err
+		if injection.Times > 0 {
+			if injection.Times--; injection.Times == 0 {
+				delete(f.injections, point)
+			}
+		}
+	}
+	f.lock.Unlock()
+
+	time.Sleep(delay)
+	return err
+}
+
+// Injects FaultRead into an output reader
+type faultReader struct {
+	io.ReadCloser
+	faults *FaultInjector
+}
+
+func (r *faultReader) Read(p []byte) (int, error) {
+	if err := r.faults.hit(FaultRead); err != nil {
+		return 0, err
+	}
+	return r.ReadCloser.Read(p)
+}
+
+// Injects FaultWatch and FaultRead into the output reader that's being
+// returned along with 'err'
+func (j *Job) injectFaults(reader io.ReadCloser, err error) (io.ReadCloser, error) {
+	if err != nil || j.faults == nil {
+		return reader, err
+	}
+	if err := j.faults.hit(FaultWatch); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+	return &faultReader{ReadCloser: reader, faults: j.faults}, nil
+}
@@ -0,0 +1,167 @@
+package job
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/link"
+)
+
+// Where the NVIDIA driver describes each GPU it manages
+const nvidiaProcDir = "/proc/driver/nvidia/gpus"
+
+// Character device major of NVIDIA devices. GPUs are /dev/nvidia<minor>.
+// Minors from nvidiaFirstControlMinor up are control devices
+// (nvidia-modeset, nvidiactl) every CUDA process needs
+const (
+	nvidiaMajor             = 195
+	nvidiaFirstControlMinor = 254
+)
+
+// BPF_DEVCG_DEV_CHAR from linux/bpf.h
+const bpfDevCGDevChar = 2
+
+// GPU is an NVIDIA GPU on this node
+type GPU struct {
+	// Device minor number, as in /dev/nvidia<Index>
+	Index uint32
+	// ex: GPU-2f6f6f9a-6b65-5c1f-9b0e-7d5a7b3e4c11
+	UUID  string
+	Model string
+	// PCI bus location, ex: 0000:3b:00.0
+	BusID string
+}
+
+// GPUs lists the NVIDIA GPUs on this node, ordered by index.
+// Empty if the driver isn't loaded
+func GPUs() ([]GPU, error) {
+	return discoverGPUs(nvidiaProcDir)
+}
+
+func discoverGPUs(procDir string) ([]GPU, error) {
+	entries, err := os.ReadDir(procDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing GPUs: %w", err)
+	}
+
+	var gpus []GPU
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "information"))
+		if err != nil {
+			return nil, fmt.Errorf("error reading GPU information: %w", err)
+		}
+		gpu, err := parseGPUInformation(data)
+		if err != nil {
+			return nil, fmt.Errorf("GPU %s: %w", entry.Name(), err)
+		}
+		gpus = append(gpus, gpu)
+	}
+	slices.SortFunc(gpus, func(a, b GPU) int { return int(a.Index) - int(b.Index) })
+	return gpus, nil
+}
+
+// Parse the driver's "Key: value" description of a GPU
+func parseGPUInformation(data []byte) (GPU, error) {
+	var gpu GPU
+	haveMinor := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Model":
+			gpu.Model = value
+		case "GPU UUID":
+			gpu.UUID = value
+		case "Bus Location":
+			gpu.BusID = value
+		case "Device Minor":
+			minor, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || minor >= nvidiaFirstControlMinor {
+				return GPU{}, fmt.Errorf("invalid device minor '%s'", value)
+			}
+			gpu.Index = uint32(minor)
+			haveMinor = true
+		}
+	}
+	if !haveMinor {
+		return GPU{}, errors.New("missing device minor")
+	}
+	return gpu, nil
+}
+
+// Environment telling CUDA which GPUs to use. Indices would be
+// renumbered by CUDA, so GPUs are referred to by UUID
+func gpuEnv(gpus []GPU) []string {
+	uuids := make([]string, 0, len(gpus))
+	for _, gpu := range gpus {
+		uuids = append(uuids, gpu.UUID)
+	}
+	visible := strings.Join(uuids, ",")
+	return []string{"CUDA_VISIBLE_DEVICES=" + visible, "NVIDIA_VISIBLE_DEVICES=" + visible}
+}
+
+// Device cgroup program denying access to the GPUs not in 'granted'.
+// Everything else, including the NVIDIA control devices, is left alone
+func gpuDeviceFilter(granted []GPU) asm.Instructions {
+	// Arguments are a struct bpf_cgroup_dev_ctx: the access type (device
+	// type in the low 16 bits), then the device's major and minor
+	insns := asm.Instructions{
+		asm.LoadMem(asm.R2, asm.R1, 0, asm.Word),
+		asm.And.Imm(asm.R2, 0xFFFF),
+		asm.LoadMem(asm.R3, asm.R1, 4, asm.Word),
+		asm.LoadMem(asm.R4, asm.R1, 8, asm.Word),
+		asm.JNE.Imm(asm.R2, bpfDevCGDevChar, "allow"),
+		asm.JNE.Imm(asm.R3, nvidiaMajor, "allow"),
+		asm.JGE.Imm(asm.R4, nvidiaFirstControlMinor, "allow"),
+	}
+	for _, gpu := range granted {
+		insns = append(insns, asm.JEq.Imm(asm.R4, int32(gpu.Index), "allow"))
+	}
+	return append(insns,
+		asm.Mov.Imm(asm.R0, 0),
+		asm.Return(),
+		asm.Mov.Imm(asm.R0, 1).WithSymbol("allow"),
+		asm.Return(),
+	)
+}
+
+// Restrict the processes in 'cgroup' to the 'granted' GPUs. The program
+// stays attached until the cgroup is removed
+func restrictGPUs(cgroup *os.File, granted []GPU) error {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:         ebpf.CGroupDevice,
+		Instructions: gpuDeviceFilter(granted),
+		License:      "Apache-2.0",
+	})
+	if err != nil {
+		return fmt.Errorf("error loading GPU device filter: %w", err)
+	}
+	// The cgroup holds its own reference once it's attached
+	defer prog.Close()
+	err = link.RawAttachProgram(link.RawAttachProgramOptions{
+		Target:  int(cgroup.Fd()),
+		Program: prog,
+		Attach:  ebpf.AttachCGroupDevice,
+	})
+	if err != nil {
+		return fmt.Errorf("error attaching GPU device filter: %w", err)
+	}
+	return nil
+}
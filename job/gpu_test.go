@@ -0,0 +1,60 @@
+package job
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const t4Information = `Model: 		 Tesla T4
+IRQ:   		 40
+GPU UUID: 	 GPU-2f6f6f9a-6b65-5c1f-9b0e-7d5a7b3e4c11
+Video BIOS: 	 90.04.96.00.02
+Bus Type: 	 PCIe
+DMA Size: 	 47 bits
+DMA Mask: 	 0x7fffffffffff
+Bus Location: 	 0000:00:1e.0
+Device Minor: 	 1
+GPU Excluded:	 No
+`
+
+func TestDiscoverGPUs(t *testing.T) {
+	gpus, err := discoverGPUs(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, gpus)
+
+	dir := t.TempDir()
+	for busID, information := range map[string]string{
+		"0000:00:1e.0": t4Information,
+		"0000:00:1d.0": "Model: \t\t Tesla T4\nGPU UUID: \t GPU-0\nBus Location: \t 0000:00:1d.0\nDevice Minor: \t 0\n",
+	} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, busID), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, busID, "information"), []byte(information), 0644))
+	}
+	gpus, err = discoverGPUs(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []GPU{
+		{Index: 0, UUID: "GPU-0", Model: "Tesla T4", BusID: "0000:00:1d.0"},
+		{Index: 1, UUID: "GPU-2f6f6f9a-6b65-5c1f-9b0e-7d5a7b3e4c11", Model: "Tesla T4", BusID: "0000:00:1e.0"},
+	}, gpus)
+
+	for _, information := range []string{
+		"Model: Tesla T4\n",
+		"Device Minor: lots\n",
+		// A control device, not a GPU
+		"Device Minor: 255\n",
+	} {
+		_, err := parseGPUInformation([]byte(information))
+		assert.Error(t, err, information)
+	}
+}
+
+func TestGPUEnv(t *testing.T) {
+	assert.Equal(t, []string{
+		"CUDA_VISIBLE_DEVICES=GPU-a,GPU-b",
+		"NVIDIA_VISIBLE_DEVICES=GPU-a,GPU-b",
+	}, gpuEnv([]GPU{{Index: 3, UUID: "GPU-a"}, {Index: 1, UUID: "GPU-b"}}))
+}
@@ -4,11 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/encryption"
 	"github.com/gopheryan/jobby/internal/streamer"
 )
 
@@ -26,40 +34,175 @@ const (
 	JobStatusStopped State = "STOPPED"
 )
 
-func newState(processExited, userKilled bool) State {
-	if !processExited {
+func newState(state jobState) State {
+	if state.phase != phaseExited {
 		return JobStatusRunning
 	}
 
-	if userKilled {
+	if state.causes&(stopByUser|stopByPreemption) != 0 {
 		return JobStatusStopped
 	}
 
 	return JobstatusComplete
 }
 
+// Why the process exited
+type ExitReason string
+
+const (
+	// The process is still running
+	ExitReasonNone ExitReason = ""
+	// The process exited on its own. See the exit code
+	ExitReasonExited ExitReason = "EXITED"
+	// Killed by a signal we didn't send. See Status.Signal
+	ExitReasonSignaled ExitReason = "SIGNALED"
+	// Killed by the OOM killer for going over its cgroup's memory limit
+	ExitReasonOOMKilled ExitReason = "OOM_KILLED"
+	// Killed for running longer than Limits.Timeout
+	ExitReasonTimedOut ExitReason = "TIMED_OUT"
+	// Killed for writing more output than its quota allows
+	ExitReasonQuotaExceeded ExitReason = "QUOTA_EXCEEDED"
+	// Killed at the user's request
+	ExitReasonStopped ExitReason = "STOPPED"
+	// Stopped to make room for a more important job (see Job.Preempt)
+	ExitReasonPreempted ExitReason = "PREEMPTED"
+	// An adopted process exited, but not being its parent we can't
+	// tell how (see Adopt)
+	ExitReasonUnknown ExitReason = "UNKNOWN"
+)
+
 type Status struct {
 	CurrentState State
 	ReturnCode   *int
+	// Host PID of the main process (see Processes for the rest).
+	// The system may reuse it once the process exits
+	PID int
+	// Wall clock time the process was started
+	StartTime time.Time
+	// Wall clock time the process exited. Zero while the process is running
+	EndTime time.Time
+	// How long the process ran, or has been running so far.
+	// Measured with the monotonic clock, so unlike EndTime - StartTime
+	// it isn't thrown off by NTP adjustments or manual clock changes
+	Duration time.Duration
+	// Output went over the job's quota. Depending on the quota action the
+	// process was killed or output past the quota was discarded
+	QuotaExceeded bool
+	// The process was killed for running longer than Limits.Timeout
+	TimedOut bool
+	// Why the process exited. ExitReasonNone while it's running
+	ExitReason ExitReason
+	// Signal that killed the process. Zero if it exited on its own (or is running)
+	Signal syscall.Signal
+	// User and system CPU time used by the process and the descendants it
+	// waited for. Zero until the process exits
+	CPUTime time.Duration
+	// Latest progress report (see ProgressPrefix). Nil if there hasn't been one
+	Progress *Progress
+	// Processes the job left behind that are still running. Always
+	// zero unless the server is a subreaper (see EnableSubreaper)
+	Orphans int
 }
 
 type JobArgs struct {
-	Command    string
-	Args       []string
+	Command string
+	Args    []string
+	// KEY=VALUE pairs added to the server's environment
+	Env []string
+	// Directory to create output files in. When set, StdoutPath and StderrPath
+	// are names relative to it and may not escape it (see ValidateOutputName).
+	// When empty they're used as given
+	OutputDir  string
 	StdoutPath string
 	StderrPath string
+	// Path of a FIFO or Unix socket to send standard output to instead of
+	// StdoutPath, for other programs to consume as it's written. The output
+	// isn't stored, so readers of the job's output only see what's written
+	// while they follow (see Stdout). Can't be combined with segments or
+	// encryption
+	StdoutSink string
+	// StdoutSink for standard error
+	StderrSink string
+	// Limits how much output the job may write. Nil means unlimited
+	Quota       OutputQuota
+	QuotaAction QuotaAction
+	// Keep only about the last this many bytes of each output file on disk,
+	// discarding older output as the job writes more (see OutputFiles).
+	// Quotas implementing ReleasableQuota get the space back.
+	// Zero keeps everything
+	OutputWindow int64
+	// Split each output file into segments that can be listed and read one
+	// by one (see Job.StdoutSegments). Implied by OutputWindow. The zero
+	// value writes a single file
+	Segments SegmentPolicy
+	// Resource limits and isolation for the process
+	Limits Limits
+	// Data key to encrypt the output files with (see the encryption package).
+	// Each file's key is derived from its base name. Nil writes plaintext
+	OutputKey []byte
+	// Masks secrets in the output before it's written (see NewRedaction)
+	Redactions []Redaction
+	// Prefix each line of output with when it was captured (see TimestampLayout)
+	Timestamps bool
+	// Give the process a pipe to read stdin from (see Job.Stdin). Without
+	// one it reads /dev/null
+	Stdin bool
+	// Priority and CPU affinity of the process
+	Scheduling Scheduling
+	// Called whenever the job signals its process, with why (ex: ExitReasonTimedOut).
+	// It's called while the job is signaling, so it must not signal the job (ex: Stop)
+	OnSignal func(signal syscall.Signal, reason ExitReason)
+	// Called with each progress report the job writes (see ProgressPrefix).
+	// Nil doesn't look for reports at all, so output isn't copied just for them
+	OnProgress func(Progress)
+	// When output is synced to disk. The zero value leaves it to the kernel
+	Sync SyncPolicy
+	// Times the job, its timeout and output segments. Nil uses the real one
+	Clock clock.Clock
+	// Fails the job at chosen points, for testing error handling. Nil in
+	// production
+	Faults *FaultInjector
 }
 
 type Job struct {
-	jobLock       sync.Mutex
-	cmd           exec.Cmd
-	processExited bool
-	processDone   chan struct{}
-	exitErr       *exec.ExitError
-	userKilled    bool
+	cmd exec.Cmd
+	// Read without locking (see lifecycle)
+	state *lifecycle
+	// Held while signaling the process and while marking it exited, so
+	// stop causes are only recorded for signals sent before it was reaped
+	signalLock sync.Mutex
+	clock      clock.Clock
+	startTime  time.Time
+	// Nil outside of tests (see JobArgs.Faults)
+	faults *FaultInjector
+	// Output went over the quota. Apart from the lifecycle,
+	// since quotas that truncate output don't stop the process
+	quotaExceeded atomic.Bool
+	onSignal      func(syscall.Signal, ExitReason)
+	// Nil unless JobArgs.Stdin was set. Closed once the process exits
+	stdin      *os.File
+	stdinTaken atomic.Bool
 
 	stdoutPath string
 	stderrPath string
+	// Nil if output is written in plaintext
+	outputKey []byte
+	// Nil unless JobArgs.OnProgress was set
+	progress *progressTracker
+	// Nil unless the job's output is segmented
+	stdoutSegments *segmentWriter
+	stderrSegments *segmentWriter
+	// Nil unless the output goes to a sink
+	stdoutSink *outputSink
+	stderrSink *outputSink
+	// Tracks the processes the job leaves behind. Nil unless the
+	// server is a subreaper, and then 'reaperTag' marks them
+	reaper    *reaper
+	reaperTag string
+	// Of the job's cgroup. Empty if it has none
+	cgroupPath string
+	// Started by someone else (see Adopt)
+	adopted bool
 }
 
 func logFileClose(f *os.File) {
@@ -77,61 +220,398 @@ func New(args JobArgs) (*Job, error) {
 		Path: args.Command,
 		Args: args.Args,
 	}
+	if len(args.Env) > 0 {
+		// Later entries win, so these override ours
+		c.Env = append(os.Environ(), args.Env...)
+	}
+	jobClock := clock.Or(args.Clock)
+	reaper := currentReaper()
+	var reaperTag string
+	if reaper != nil {
+		var err error
+		if reaperTag, err = newReaperTag(); err != nil {
+			return nil, err
+		}
+		if c.Env == nil {
+			c.Env = os.Environ()
+		}
+		// After the job's own variables, so it can't hide from us
+		c.Env = append(c.Env, reaperTagEnv+"="+reaperTag)
+	}
+
+	stdoutPath, stderrPath := args.StdoutPath, args.StderrPath
+	if args.OutputDir != "" {
+		if err := ValidateOutputDir(args.OutputDir); err != nil {
+			return nil, err
+		}
+		stdoutPath = filepath.Join(args.OutputDir, args.StdoutPath)
+		stderrPath = filepath.Join(args.OutputDir, args.StderrPath)
+	}
+	// Sinks stand in for the output files, wherever they are
+	if args.StdoutSink != "" {
+		stdoutPath = args.StdoutSink
+	}
+	if args.StderrSink != "" {
+		stderrPath = args.StderrSink
+	}
+
+	if args.OutputWindow < 0 || (args.OutputWindow > 0 && args.OutputWindow < outputWindowSegments) {
+		return nil, fmt.Errorf("output window must be at least %d bytes", outputWindowSegments)
+	}
+	if args.Segments.MaxBytes < 0 || args.Segments.Interval < 0 {
+		return nil, errors.New("output segment limits may not be negative")
+	}
+	if err := args.Sync.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid output sync policy: %w", err)
+	}
+	hasSinks := args.StdoutSink != "" || args.StderrSink != ""
+	if hasSinks && (args.OutputWindow > 0 || args.Segments.enabled()) {
+		return nil, errors.New("output sinks can't be segmented")
+	}
+	if hasSinks && args.OutputKey != nil {
+		return nil, errors.New("output sinks can't be encrypted")
+	}
 
 	// Create our output files!
-	stdoutFile, err := createOutputFile(args.StdoutPath)
-	stderrFile, err2 := createOutputFile(args.StderrPath)
-	if err := errors.Join(err, err2); err != nil {
-		logFileClose(stdoutFile)
-		logFileClose(stderrFile)
-		return nil, fmt.Errorf("error creating output file(s): %w", err)
+	var stdout, stderr io.Writer
+	var stdoutSegments, stderrSegments *segmentWriter
+	var stdoutSink, stderrSink *outputSink
+	var closeOutputs func()
+	// Closes and removes the output files, for when the job never starts.
+	// Only the ones we created, so refusing a file never removes it
+	var discardOutputs func()
+	var syncer *outputSyncer
+	if args.OutputWindow > 0 || args.Segments.enabled() {
+		// Segments are encrypted one by one, so they take care of it themselves
+		var err, err2 error
+		syncFinished := args.Sync.Mode != SyncNone
+		if err = args.Faults.hit(FaultCreateOutput); err == nil {
+			stdoutSegments, err = newSegmentWriter(args.OutputDir, args.StdoutPath, args.OutputWindow, args.Segments, args.OutputKey, args.Quota, syncFinished, jobClock)
+		}
+		if err == nil {
+			if err2 = args.Faults.hit(FaultCreateOutput); err2 == nil {
+				stderrSegments, err2 = newSegmentWriter(args.OutputDir, args.StderrPath, args.OutputWindow, args.Segments, args.OutputKey, args.Quota, syncFinished, jobClock)
+			}
+		}
+		closeOutputs = func() {
+			for _, segments := range []*segmentWriter{stdoutSegments, stderrSegments} {
+				if segments == nil {
+					continue
+				}
+				if err := segments.Close(); err != nil {
+					slog.Error("Failed to close file", "error", err)
+				}
+			}
+		}
+		discardOutputs = func() {
+			closeOutputs()
+			for _, segments := range []*segmentWriter{stdoutSegments, stderrSegments} {
+				if segments != nil {
+					removeOutputFile(segmentPath(segments.path, 1))
+				}
+			}
+		}
+		if err := errors.Join(err, err2); err != nil {
+			discardOutputs()
+			return nil, fmt.Errorf("error creating output file(s): %w", err)
+		}
+		stdout, stderr = stdoutSegments, stderrSegments
+		syncer = newOutputSyncer(stdoutPath, stderrPath, stdoutSegments, stderrSegments)
+	} else {
+		// Either a file or a sink, whichever the stream goes to
+		createOutput := func(name string, sinkPath string) (*os.File, *outputSink, error) {
+			if err := args.Faults.hit(FaultCreateOutput); err != nil {
+				return nil, nil, err
+			}
+			if sinkPath != "" {
+				sink, err := openSink(sinkPath)
+				return nil, sink, err
+			}
+			file, err := createOutputFile(args.OutputDir, name)
+			return file, nil, err
+		}
+		var stdoutFile, stderrFile *os.File
+		var err, err2 error
+		stdoutFile, stdoutSink, err = createOutput(args.StdoutPath, args.StdoutSink)
+		stderrFile, stderrSink, err2 = createOutput(args.StderrPath, args.StderrSink)
+		closeOutputs = func() {
+			logFileClose(stdoutFile)
+			logFileClose(stderrFile)
+			for _, sink := range []*outputSink{stdoutSink, stderrSink} {
+				if err := sink.Close(); err != nil {
+					slog.Error("Failed to close output sink", "error", err)
+				}
+			}
+		}
+		discardOutputs = func() {
+			closeOutputs()
+			if stdoutFile != nil {
+				removeOutputFile(stdoutPath)
+			}
+			if stderrFile != nil {
+				removeOutputFile(stderrPath)
+			}
+		}
+		if err := errors.Join(err, err2); err != nil {
+			discardOutputs()
+			return nil, fmt.Errorf("error creating output file(s): %w", err)
+		}
+
+		// Sinks have nothing to sync
+		var files []interface{ Sync() error }
+		if stdoutSink != nil {
+			stdout = stdoutSink
+		} else {
+			stdout = stdoutFile
+			files = append(files, stdoutFile)
+		}
+		if stderrSink != nil {
+			stderr = stderrSink
+		} else {
+			stderr = stderrFile
+			files = append(files, stderrFile)
+		}
+		syncer = newOutputSyncer(stdoutPath, stderrPath, files...)
+		if args.OutputKey != nil {
+			// Like quotas below, encrypted output has to flow through us.
+			// exec.Cmd copies it from a pipe, and Wait waits for the copy to finish
+			stdout, err = encryption.NewWriter(stdoutFile, args.OutputKey, filepath.Base(stdoutPath))
+			if err == nil {
+				stderr, err = encryption.NewWriter(stderrFile, args.OutputKey, filepath.Base(stderrPath))
+			}
+			if err != nil {
+				discardOutputs()
+				return nil, fmt.Errorf("error setting up output encryption: %w", err)
+			}
+		}
+	}
+	c.Stdout = stdout
+	c.Stderr = stderr
+	// Closed once output exceeds the quota
+	var quotaHit <-chan struct{}
+	if args.Quota != nil {
+		// Output has to flow through us to be counted. Quotas count the
+		// plaintext, so they're applied before encryption
+		c.Stdout, c.Stderr, quotaHit = newQuotaWriters(stdout, stderr, args.Quota)
+	}
+	if args.Timestamps {
+		// Ahead of the quota, so prefixes count against it like they do on disk.
+		// Behind progress tracking, which has to see the lines as written
+		c.Stdout = &timestampWriter{dst: c.Stdout, clock: jobClock}
+		c.Stderr = &timestampWriter{dst: c.Stderr, clock: jobClock}
+	}
+	var progress *progressTracker
+	if args.OnProgress != nil {
+		// Behind the redactors, so reports are redacted like the rest of the output
+		progress = newProgressTracker(args.OnProgress, jobClock)
+		c.Stdout = &progressWriter{dst: c.Stdout, tracker: progress}
+		c.Stderr = &progressWriter{dst: c.Stderr, tracker: progress}
+	}
+	// Hold on to partial lines until the process exits
+	var redactors []*redactWriter
+	if len(args.Redactions) > 0 {
+		// Redacted text never counts against the quota or reaches the disk
+		redactors = []*redactWriter{
+			{file: c.Stdout, redactions: args.Redactions},
+			{file: c.Stderr, redactions: args.Redactions},
+		}
+		c.Stdout, c.Stderr = redactors[0], redactors[1]
 	}
 
-	c.Stdout = stdoutFile
-	c.Stderr = stderrFile
+	if err := args.Scheduling.Validate(); err != nil {
+		discardOutputs()
+		return nil, fmt.Errorf("invalid job scheduling: %w", err)
+	}
+	// Ours rather than exec.Cmd.StdinPipe, so they're closed however starting goes
+	var stdinReader, stdin *os.File
+	if args.Stdin {
+		var err error
+		if stdinReader, stdin, err = os.Pipe(); err != nil {
+			discardOutputs()
+			return nil, fmt.Errorf("error creating stdin pipe: %w", err)
+		}
+		c.Stdin = stdinReader
+	}
+	// The process has its own copy of the read end, if it started
+	defer logFileClose(stdinReader)
+	cgroup, err := args.Limits.prepare(&c)
+	if err != nil {
+		discardOutputs()
+		return nil, fmt.Errorf("error preparing job limits: %w", err)
+	}
+	cleanupCgroup := func() {
+		if cgroup == nil {
+			return
+		}
+		if err := removeCgroup(cgroup); err != nil {
+			slog.Error("Failed to remove job cgroup", "error", err)
+		}
+	}
+	// Set once the process starts
+	var network *network
+	cleanupNetwork := func() {
+		if err := network.close(); err != nil {
+			slog.Error("Failed to clean up job network", "error", err)
+		}
+	}
 
-	if err = c.Start(); err != nil {
-		logFileClose(stdoutFile)
-		logFileClose(stderrFile)
+	startTime := jobClock.Now()
+	started := reaper.starting(reaperTag)
+	if err = args.Faults.hit(FaultStart); err == nil {
+		network, err = args.Limits.start(&c, args.Scheduling)
+	}
+	if err != nil {
+		started(0)
+		discardOutputs()
+		cleanupCgroup()
+		logFileClose(stdin)
 		return nil, fmt.Errorf("error starting process: %w", err)
 	}
+	started(c.Process.Pid)
+	if err = args.Limits.apply(c.Process.Pid); err != nil {
+		// Don't leave it running without its limits
+		_ = c.Process.Kill()
+		_ = c.Wait()
+		reaper.exited(c.Process.Pid, reaperTag)
+		closeOutputs()
+		cleanupCgroup()
+		cleanupNetwork()
+		logFileClose(stdin)
+		return nil, fmt.Errorf("error applying job limits: %w", err)
+	}
 
+	var cgroupPath string
+	if cgroup != nil {
+		cgroupPath = cgroup.Name()
+	}
 	newJob := &Job{
-		cmd:         c,
-		stdoutPath:  args.StdoutPath,
-		stderrPath:  args.StderrPath,
-		processDone: make(chan struct{}),
-		exitErr:     &exec.ExitError{},
+		cmd:            c,
+		stdoutPath:     stdoutPath,
+		stderrPath:     stderrPath,
+		outputKey:      args.OutputKey,
+		onSignal:       args.OnSignal,
+		stdin:          stdin,
+		progress:       progress,
+		stdoutSegments: stdoutSegments,
+		stderrSegments: stderrSegments,
+		stdoutSink:     stdoutSink,
+		stderrSink:     stderrSink,
+		reaper:         reaper,
+		reaperTag:      reaperTag,
+		cgroupPath:     cgroupPath,
+		state:          newLifecycle(),
+		clock:          jobClock,
+		faults:         args.Faults,
+		startTime:      startTime,
+	}
+
+	if timeout := args.Limits.Timeout; timeout > 0 {
+		go func() {
+			timer := jobClock.NewTimer(timeout)
+			defer timer.Stop()
+			select {
+			case <-timer.C():
+				newJob.onTimeout()
+			case <-newJob.state.done:
+			}
+		}()
+	}
+
+	if args.Sync.Mode == SyncPeriodic {
+		go func() {
+			ticker := jobClock.NewTicker(args.Sync.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					if err := syncer.sync(); err != nil {
+						slog.Error("Failed to sync job output", "error", err)
+					}
+				case <-newJob.state.done:
+					return
+				}
+			}
+		}()
+	}
+
+	if quotaHit != nil {
+		go func() {
+			select {
+			case <-quotaHit:
+				newJob.onQuotaExceeded(args.QuotaAction)
+			case <-newJob.state.done:
+			}
+		}()
 	}
 
 	// Now create a goroutine which will watch for the process to exit
-	// it will atomically update the 'processExited' and 'exitErr' upon
-	// process exit. Output files will be closed *after* releasing
-	// the job lock
+	// and move the job to phaseExited. Output files will be closed
+	// *after* that, once nothing reads them as a running job's
 	go func() {
-		defer logFileClose(stdoutFile)
-		defer logFileClose(stderrFile)
+		defer cleanupCgroup()
+		defer cleanupNetwork()
+		defer closeOutputs()
 
 		err := c.Wait()
-		// Lock the job while we update the exit status
-		newJob.jobLock.Lock()
-		// This will unlock *before* the output files close.
-		// We may consider holding the lock until the files are
-		// closed, but I don't believe we need that guarantee
-		// Other methods can be assure that observing
-		// 'processExited == true' means that the last write to
-		// the output files have completed
-		defer newJob.jobLock.Unlock()
-
-		close(newJob.processDone)
-		newJob.processExited = true
-		_ = errors.As(err, &newJob.exitErr)
+		_ = args.Faults.hit(FaultWait)
+		reaper.exited(c.Process.Pid, reaperTag)
+		if stdin != nil {
+			// Nothing reads it anymore. Writers may have closed it already
+			_ = stdin.Close()
+		}
+		// Output is done being copied, so whatever's left is the last partial line
+		for _, redactor := range redactors {
+			if flushErr := redactor.Flush(); flushErr != nil {
+				slog.Error("Failed to write redacted output", "error", flushErr)
+			}
+		}
+		// Before the job is seen to exit, so output of exited jobs is on disk
+		if args.Sync.Mode != SyncNone {
+			if syncErr := syncer.sync(); syncErr != nil {
+				slog.Error("Failed to sync job output", "error", syncErr)
+			}
+		}
+		// Periodic syncs mustn't run into the files being closed
+		syncer.stop()
+		// The cgroup is removed once we return, so check it while it's around
+		oomKilled := false
+		if cgroup != nil {
+			var oomErr error
+			if oomKilled, oomErr = cgroupOOMKilled(cgroup); oomErr != nil {
+				slog.Error("Failed to check job cgroup for OOM kills", "error", oomErr)
+			}
+		}
+		if err != nil && c.ProcessState == nil {
+			slog.Error("Failed to wait for job process", "error", err)
+		}
+		// Observing phaseExited means the last write to the output files
+		// has completed. The files are closed after, but I don't believe
+		// anyone needs that guarantee
+		newJob.signalLock.Lock()
+		// Wait sets ProcessState whether the exit was successful or not
+		newJob.state.exited(&exitInfo{
+			processState: c.ProcessState,
+			endTime:      jobClock.Now(),
+			oomKilled:    oomKilled,
+		})
+		newJob.signalLock.Unlock()
 	}()
 
 	return newJob, err
 }
 
-func createOutputFile(path string) (*os.File, error) {
+// For output files of jobs that never started. Nothing was written to
+// them, so there's no quota to give back
+func removeOutputFile(path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Error("Failed to remove output file", "error", err)
+	}
+}
+
+func createOutputFile(dir string, path string) (*os.File, error) {
+	if dir != "" {
+		return createBeneath(dir, path)
+	}
 	// We need to open a file for writing, create if not exists,
 	// and truncate existing files
 	const flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
@@ -140,56 +620,293 @@ func createOutputFile(path string) (*os.File, error) {
 }
 
 func (j *Job) Status() Status {
-	j.jobLock.Lock()
-
-	currentState := newState(j.processExited, j.userKilled)
+	state := j.state.get()
 	var exitCode *int
-	// ExitCode returns the exit code of the exited process,
-	// or -1 if the process hasn't exited or was terminated by a signal.
-	// Safe to call on nil ExitErr
-	if tmp := j.exitErr.ExitCode(); tmp != -1 {
-		exitCode = &tmp
+	var endTime time.Time
+	var cpuTime time.Duration
+	if exit := state.exit; exit != nil {
+		endTime = exit.endTime
+		// -1 if the process was killed by a signal. Safe on a nil ProcessState
+		if code := exit.processState.ExitCode(); code != -1 {
+			exitCode = &code
+		}
+		if exit.processState != nil {
+			cpuTime = exit.processState.UserTime() + exit.processState.SystemTime()
+		}
+	}
+	reason, signal := j.exitReason(state)
+	progress, _ := j.Progress()
+	orphans := len(j.reaper.pids(j.reaperTag))
+
+	// With the real clock both times carry a monotonic reading
+	// from time.Now, which Sub and Since prefer over the wall clock
+	var duration time.Duration
+	if endTime.IsZero() {
+		duration = j.clock.Since(j.startTime)
+	} else {
+		duration = endTime.Sub(j.startTime)
 	}
-
-	j.jobLock.Unlock()
 
 	return Status{
-		CurrentState: currentState,
-		ReturnCode:   exitCode,
+		CurrentState:  newState(state),
+		ReturnCode:    exitCode,
+		PID:           j.cmd.Process.Pid,
+		QuotaExceeded: j.quotaExceeded.Load(),
+		TimedOut:      state.causes&stopByTimeout != 0,
+		ExitReason:    reason,
+		Signal:        signal,
+		CPUTime:       cpuTime,
+		Progress:      progress,
+		Orphans:       orphans,
+		// Strip the monotonic readings. They are meaningless
+		// outside this process and Duration already covers them
+		StartTime: j.startTime.Round(0),
+		EndTime:   endTime.Round(0),
+		Duration:  duration,
 	}
 }
 
-func (j *Job) Stop() error {
-	var err error
-	j.jobLock.Lock()
-	if !j.processExited {
-		err = j.cmd.Process.Kill()
-		if err == nil {
-			// Track that a successful kill signal was
-			// sent to a running process by the caller
-			j.userKilled = true
+func (j *Job) exitReason(state jobState) (ExitReason, syscall.Signal) {
+	if state.phase != phaseExited {
+		return ExitReasonNone, 0
+	}
+
+	var signal syscall.Signal
+	// ProcessState is nil if Wait failed before the process was reaped
+	if state.exit.processState != nil {
+		if ws, ok := state.exit.processState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			signal = ws.Signal()
 		}
 	}
-	j.jobLock.Unlock()
 
-	if err != nil {
-		err = fmt.Errorf("failed to send kill signal to process: %w", err)
+	switch {
+	case state.causes&stopByUser != 0:
+		return ExitReasonStopped, signal
+	case state.causes&stopByPreemption != 0:
+		return ExitReasonPreempted, signal
+	case state.exit.oomKilled:
+		// Reported even if the process survived the kill of one of its children,
+		// since that's most likely why it failed
+		return ExitReasonOOMKilled, signal
+	case state.causes&stopByTimeout != 0:
+		return ExitReasonTimedOut, signal
+	case j.adopted:
+		return ExitReasonUnknown, signal
+	case signal == 0:
+		return ExitReasonExited, signal
+	case j.quotaExceeded.Load():
+		return ExitReasonQuotaExceeded, signal
+	default:
+		return ExitReasonSignaled, signal
+	}
+}
+
+func (j *Job) onQuotaExceeded(action QuotaAction) {
+	j.quotaExceeded.Store(true)
+	if action != QuotaActionStop {
+		return
+	}
+	j.signalLock.Lock()
+	defer j.signalLock.Unlock()
+	if j.state.get().phase == phaseExited {
+		return
+	}
+	slog.Warn("Killing process that exceeded its output quota", "pid", j.cmd.Process.Pid)
+	if err := j.signal(syscall.SIGKILL, ExitReasonQuotaExceeded, stopByQuota); err != nil {
+		slog.Error("Failed to kill process over quota", "error", err)
+	}
+}
+
+func (j *Job) onTimeout() {
+	j.signalLock.Lock()
+	defer j.signalLock.Unlock()
+	if j.state.get().phase == phaseExited {
+		return
+	}
+	slog.Warn("Killing process that exceeded its timeout", "pid", j.cmd.Process.Pid)
+	if err := j.signal(syscall.SIGKILL, ExitReasonTimedOut, stopByTimeout); err != nil {
+		slog.Error("Failed to kill process after timeout", "error", err)
 	}
-	return err
 }
 
-func (j *Job) watchOutput(path string) (io.ReadCloser, error) {
-	fileStreamer, err := streamer.NewLiveFileStreamer(path, j.processDone)
+// Send 'sig' to the process (and anything it left behind), record 'cause'
+// and report it to OnSignal. Caller must hold the signal lock
+func (j *Job) signal(sig syscall.Signal, reason ExitReason, cause stopCause) error {
+	if err := j.cmd.Process.Signal(sig); err != nil {
+		return err
+	}
+	j.reaper.signal(j.reaperTag, sig)
+	// Can't fail: the process can't be marked exited while we hold the lock
+	j.state.stopping(cause)
+	if j.onSignal != nil {
+		j.onSignal(sig, reason)
+	}
+	return nil
+}
+
+// Done is closed once the process has exited
+func (j *Job) Done() <-chan struct{} {
+	return j.state.done
+}
+
+func (j *Job) Stop() error {
+	j.signalLock.Lock()
+	defer j.signalLock.Unlock()
+	if j.state.get().phase == phaseExited {
+		// Whatever the process left behind can still be stopped
+		j.reaper.signal(j.reaperTag, syscall.SIGKILL)
+		return nil
+	}
+	if err := j.signal(syscall.SIGKILL, ExitReasonStopped, stopByUser); err != nil {
+		return fmt.Errorf("failed to send kill signal to process: %w", err)
+	}
+	return nil
+}
+
+// Preempt asks the process to exit with SIGTERM, and kills it if it's
+// still running after 'grace'. The job ends up stopped, with ExitReasonPreempted
+func (j *Job) Preempt(grace time.Duration) error {
+	j.signalLock.Lock()
+	defer j.signalLock.Unlock()
+	if state := j.state.get(); state.phase == phaseExited || state.causes&stopByPreemption != 0 {
+		return nil
+	}
+	if err := j.signal(syscall.SIGTERM, ExitReasonPreempted, stopByPreemption); err != nil {
+		return fmt.Errorf("failed to send terminate signal to process: %w", err)
+	}
+
+	go func() {
+		timer := j.clock.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+			j.signalLock.Lock()
+			defer j.signalLock.Unlock()
+			if j.state.get().phase == phaseExited {
+				return
+			}
+			slog.Warn("Killing preempted process that didn't exit in time", "pid", j.cmd.Process.Pid)
+			if err := j.signal(syscall.SIGKILL, ExitReasonPreempted, stopByPreemption); err != nil {
+				slog.Error("Failed to kill preempted process", "error", err)
+			}
+		case <-j.state.done:
+		}
+	}()
+	return nil
+}
+
+// Reads the output file at 'path', following it until 'done' is closed
+func (j *Job) watchOutput(path string, done chan struct{}) (io.ReadCloser, error) {
+	fileStreamer, err := streamer.NewLiveFileStreamer(path, done)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file streamer: %w", err)
 	}
-	return fileStreamer, nil
+	if j.outputKey == nil {
+		return fileStreamer, nil
+	}
+	reader, err := encryption.NewReader(fileStreamer, j.outputKey, filepath.Base(path))
+	if err != nil {
+		_ = fileStreamer.Close()
+		return nil, fmt.Errorf("failed to create output decrypter: %w", err)
+	}
+	return reader, nil
 }
 
+// Stdout follows the process's standard output as it's written. With an
+// output window, it starts from the oldest output still on disk
 func (j *Job) Stdout() (io.ReadCloser, error) {
-	return j.watchOutput(j.stdoutPath)
+	if j.stdoutSink != nil {
+		return j.injectFaults(j.stdoutSink.followers.Follow(), nil)
+	}
+	if j.stdoutSegments != nil {
+		return j.injectFaults(newSegmentReader(j.stdoutSegments, true))
+	}
+	return j.injectFaults(j.watchOutput(j.stdoutPath, j.state.done))
 }
 
+// Stderr is Stdout for standard error
 func (j *Job) Stderr() (io.ReadCloser, error) {
-	return j.watchOutput(j.stderrPath)
+	if j.stderrSink != nil {
+		return j.injectFaults(j.stderrSink.followers.Follow(), nil)
+	}
+	if j.stderrSegments != nil {
+		return j.injectFaults(newSegmentReader(j.stderrSegments, true))
+	}
+	return j.injectFaults(j.watchOutput(j.stderrPath, j.state.done))
+}
+
+// StdoutSnapshot reads the standard output written so far. Unlike Stdout,
+// it ends at the end of the output rather than waiting for more. Output
+// sent to a sink was never kept, so there's nothing to read
+func (j *Job) StdoutSnapshot() (io.ReadCloser, error) {
+	return j.injectFaults(j.snapshot(j.stdoutPath, j.stdoutSegments, j.stdoutSink))
+}
+
+// StderrSnapshot is StdoutSnapshot for standard error
+func (j *Job) StderrSnapshot() (io.ReadCloser, error) {
+	return j.injectFaults(j.snapshot(j.stderrPath, j.stderrSegments, j.stderrSink))
+}
+
+func (j *Job) snapshot(path string, segments *segmentWriter, sink *outputSink) (io.ReadCloser, error) {
+	if sink != nil {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	if segments != nil {
+		return newSegmentReader(segments, false)
+	}
+	return j.watchOutput(path, segmentDone)
+}
+
+// StdoutSegments lists the segments of standard output still on disk, oldest
+// first. ErrNotSegmented unless the job was started with segmented output
+func (j *Job) StdoutSegments() ([]OutputSegment, error) {
+	if j.stdoutSegments == nil {
+		return nil, ErrNotSegmented
+	}
+	return j.stdoutSegments.list(), nil
+}
+
+// StderrSegments is StdoutSegments for standard error
+func (j *Job) StderrSegments() ([]OutputSegment, error) {
+	if j.stderrSegments == nil {
+		return nil, ErrNotSegmented
+	}
+	return j.stderrSegments.list(), nil
+}
+
+// StdoutSegment reads segment 'n' of standard output. The segment being
+// written is followed until the job moves on to the next one or exits
+func (j *Job) StdoutSegment(n int) (io.ReadCloser, error) {
+	if j.stdoutSegments == nil {
+		return nil, ErrNotSegmented
+	}
+	return j.injectFaults(j.stdoutSegments.openExact(n))
+}
+
+// StderrSegment is StdoutSegment for standard error
+func (j *Job) StderrSegment(n int) (io.ReadCloser, error) {
+	if j.stderrSegments == nil {
+		return nil, ErrNotSegmented
+	}
+	return j.injectFaults(j.stderrSegments.openExact(n))
+}
+
+// ReportProgress records a progress report made some other way than by a
+// line of output (ex: through the API), as if the job had written it
+func (j *Job) ReportProgress(progress Progress) error {
+	if j.progress == nil {
+		return ErrProgressNotTracked
+	}
+	j.progress.report(progress)
+	return nil
+}
+
+// Progress is the job's latest progress report, or nil if it hasn't made one
+// (or JobArgs.OnProgress wasn't set). The channel is closed once there's a newer one
+func (j *Job) Progress() (*Progress, <-chan struct{}) {
+	if j.progress == nil {
+		// Never closed
+		return nil, nil
+	}
+	return j.progress.get()
 }
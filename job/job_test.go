@@ -2,19 +2,30 @@ package job_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/gopheryan/jobby/internal/clock"
 	"github.com/gopheryan/jobby/job"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
 )
 
-const echoPathRelative = "../testdata/testprograms/echo"
+const testJobPath = "../testdata/testprograms/testjob"
 
 func expectEchoOutput(stdout bool, count int) string {
 	prefix := "stdout"
@@ -33,9 +44,9 @@ func expectEchoOutput(stdout bool, count int) string {
 func TestJob(t *testing.T) {
 	dir := t.TempDir()
 	j, err := job.New(job.JobArgs{
-		Command: echoPathRelative,
+		Command: testJobPath,
 		// should take >=2.5 seconds to complete
-		Args:       []string{"echo", "5"},
+		Args:       []string{"testjob", "5"},
 		StdoutPath: filepath.Join(dir, "file.stdout"),
 		StderrPath: filepath.Join(dir, "file.sterr"),
 	})
@@ -59,8 +70,17 @@ func TestJob(t *testing.T) {
 
 	// Note: the process has already exited by now, but stderr should still be readable
 	// In fact, let's validate that assumption
+	<-j.Done()
 	status = j.Status()
 	assert.Equal(t, status.CurrentState, job.JobstatusComplete)
+	require.NotNil(t, status.ReturnCode)
+	assert.Equal(t, 0, *status.ReturnCode)
+	assert.False(t, status.StartTime.IsZero())
+	assert.True(t, status.EndTime.After(status.StartTime))
+	// testjob sleeps for half a second between lines
+	assert.GreaterOrEqual(t, status.Duration, 2*time.Second)
+	// Duration no longer grows once the process has exited
+	assert.Equal(t, status.Duration, j.Status().Duration)
 
 	stderrData, err := io.ReadAll(serr)
 	require.NoError(t, err)
@@ -70,9 +90,9 @@ func TestJob(t *testing.T) {
 func TestJobBadOutputPaths(t *testing.T) {
 	// path does not exist
 	j, err := job.New(job.JobArgs{
-		Command: echoPathRelative,
+		Command: testJobPath,
 		// should take >=2.5 seconds to complete
-		Args:       []string{"echo", "5"},
+		Args:       []string{"testjob", "5"},
 		StdoutPath: "/var/a",
 		StderrPath: "/bar/a",
 	})
@@ -80,12 +100,83 @@ func TestJobBadOutputPaths(t *testing.T) {
 	assert.Nil(t, j)
 }
 
+func TestValidateOutputName(t *testing.T) {
+	for _, name := range []string{"stdout", "job-1-stdout", "logs/stdout"} {
+		assert.NoError(t, job.ValidateOutputName(name), name)
+	}
+	for _, name := range []string{
+		"", ".", "..", "../stdout", "logs/../../stdout", "/etc/passwd",
+		"logs//stdout", "logs/./stdout", "stdout/", "std\x00out", strings.Repeat("a", 256),
+	} {
+		assert.Error(t, job.ValidateOutputName(name), name)
+	}
+}
+
+func TestJobOutputDir(t *testing.T) {
+	newJob := func(dir string, stdout string) (*job.Job, error) {
+		return job.New(job.JobArgs{
+			Command:    testJobPath,
+			Args:       []string{"testjob", "1"},
+			OutputDir:  dir,
+			StdoutPath: stdout,
+			StderrPath: "stderr",
+		})
+	}
+
+	t.Run("beneath", func(tt *testing.T) {
+		dir := tt.TempDir()
+		j, err := newJob(dir, "stdout")
+		require.NoError(tt, err)
+		<-j.Done()
+		data, err := os.ReadFile(filepath.Join(dir, "stdout"))
+		require.NoError(tt, err)
+		assert.Equal(tt, expectEchoOutput(true, 1), string(data))
+	})
+
+	t.Run("escape", func(tt *testing.T) {
+		dir := tt.TempDir()
+		_, err := newJob(filepath.Join(dir, "sub"), "../stdout")
+		assert.Error(tt, err)
+		_, err = newJob(dir, filepath.Join(dir, "stdout"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("symlink", func(tt *testing.T) {
+		dir := tt.TempDir()
+		target := filepath.Join(tt.TempDir(), "target")
+		require.NoError(tt, os.Symlink(target, filepath.Join(dir, "stdout")))
+		_, err := newJob(dir, "stdout")
+		assert.Error(tt, err)
+		assert.NoFileExists(tt, target)
+
+		// Symlinked directories inside the output directory are refused too
+		require.NoError(tt, os.Symlink(filepath.Dir(target), filepath.Join(dir, "logs")))
+		_, err = newJob(dir, "logs/stdout")
+		assert.Error(tt, err)
+	})
+
+	t.Run("privileged", func(tt *testing.T) {
+		_, err := newJob("/etc", "stdout")
+		assert.Error(tt, err)
+		assert.NoFileExists(tt, "/etc/stdout")
+
+		// Following a symlink to a privileged directory is no better
+		link := filepath.Join(tt.TempDir(), "etc")
+		require.NoError(tt, os.Symlink("/etc", link))
+		_, err = newJob(link, "stdout")
+		assert.Error(tt, err)
+
+		_, err = newJob("relative/dir", "stdout")
+		assert.Error(tt, err)
+	})
+}
+
 func TestJobStop(t *testing.T) {
 	dir := t.TempDir()
 	j, err := job.New(job.JobArgs{
-		Command: echoPathRelative,
+		Command: testJobPath,
 		// should take >=250 seconds to complete
-		Args:       []string{"echo", "500"},
+		Args:       []string{"testjob", "500"},
 		StdoutPath: filepath.Join(dir, "file.stdout"),
 		StderrPath: filepath.Join(dir, "file.sterr"),
 	})
@@ -93,17 +184,10 @@ func TestJobStop(t *testing.T) {
 	assert.NoError(t, j.Stop(), "Failed to Stop job")
 
 	// Wait a reasonable amount of time for the job to stop
-	ticker := time.NewTicker(10 * time.Millisecond)
-loop:
-	for {
-		select {
-		case <-ticker.C:
-			if j.Status().CurrentState == job.JobStatusStopped {
-				break loop
-			}
-		case <-time.After(1 * time.Second):
-			break loop
-		}
+	select {
+	case <-j.Done():
+	case <-time.After(1 * time.Second):
+		require.FailNow(t, "job should have stopped")
 	}
 	assert.Equal(t, job.JobStatusStopped, j.Status().CurrentState)
 }
@@ -113,8 +197,8 @@ func TestDetachAndReattach(t *testing.T) {
 	// shortly after
 	dir := t.TempDir()
 	j, err := job.New(job.JobArgs{
-		Command:    echoPathRelative,
-		Args:       []string{"echo", "15"},
+		Command:    testJobPath,
+		Args:       []string{"testjob", "15"},
 		StdoutPath: filepath.Join(dir, "file.stdout"),
 		StderrPath: filepath.Join(dir, "file.sterr"),
 	})
@@ -147,3 +231,1274 @@ func TestDetachAndReattach(t *testing.T) {
 	assert.Equal(t, job.JobStatusStopped, j.Status().CurrentState)
 	require.NoError(t, sout2.Close())
 }
+
+// Output is kept byte for byte, whatever it is
+func TestJobBinaryOutput(t *testing.T) {
+	dir := t.TempDir()
+	j, err := job.New(job.JobArgs{
+		Command:    testJobPath,
+		Args:       []string{"testjob", "-binary", "1000", "-exit", "4"},
+		StdoutPath: filepath.Join(dir, "stdout"),
+		StderrPath: filepath.Join(dir, "stderr"),
+	})
+	require.NoError(t, err)
+	<-j.Done()
+
+	stdout, err := j.Stdout()
+	require.NoError(t, err)
+	defer stdout.Close()
+	data, err := io.ReadAll(stdout)
+	require.NoError(t, err)
+	expected := make([]byte, 1000)
+	for i := range expected {
+		expected[i] = byte(i)
+	}
+	assert.Equal(t, expected, data)
+
+	status := j.Status()
+	require.NotNil(t, status.ReturnCode)
+	assert.Equal(t, 4, *status.ReturnCode)
+}
+
+func TestJobFaults(t *testing.T) {
+	injected := errors.New("injected")
+	start := func(tt *testing.T, dir string, faults *job.FaultInjector, segments job.SegmentPolicy, args ...string) (*job.Job, error) {
+		return job.New(job.JobArgs{
+			Command:    testJobPath,
+			Args:       append([]string{"testjob"}, args...),
+			OutputDir:  dir,
+			StdoutPath: "stdout",
+			StderrPath: "stderr",
+			Segments:   segments,
+			Faults:     faults,
+		})
+	}
+	// Jobs that fail to start leave nothing behind
+	requireEmpty := func(tt *testing.T, dir string) {
+		entries, err := os.ReadDir(dir)
+		require.NoError(tt, err)
+		assert.Empty(tt, entries)
+	}
+
+	for name, segments := range map[string]job.SegmentPolicy{
+		"create output":   {},
+		"create segments": {MaxBytes: 1024},
+	} {
+		t.Run(name, func(tt *testing.T) {
+			dir := tt.TempDir()
+			faults := job.NewFaultInjector()
+			// Stdout gets created, stderr doesn't
+			faults.Inject(job.FaultCreateOutput, job.Injection{Err: injected, Skip: 1})
+			_, err := start(tt, dir, faults, segments)
+			assert.ErrorIs(tt, err, injected)
+			assert.Equal(tt, 2, faults.Hits(job.FaultCreateOutput))
+			requireEmpty(tt, dir)
+		})
+	}
+
+	t.Run("start", func(tt *testing.T) {
+		dir := tt.TempDir()
+		faults := job.NewFaultInjector()
+		faults.Inject(job.FaultStart, job.Injection{Err: injected, Times: 1})
+		_, err := start(tt, dir, faults, job.SegmentPolicy{})
+		assert.ErrorIs(tt, err, injected)
+		requireEmpty(tt, dir)
+
+		// Only the first start failed
+		j, err := start(tt, dir, faults, job.SegmentPolicy{})
+		require.NoError(tt, err)
+		<-j.Done()
+		assert.Equal(tt, job.JobstatusComplete, j.Status().CurrentState)
+	})
+
+	t.Run("wait", func(tt *testing.T) {
+		faults := job.NewFaultInjector()
+		faults.Inject(job.FaultWait, job.Injection{Err: injected, Delay: 300 * time.Millisecond})
+		j, err := start(tt, tt.TempDir(), faults, job.SegmentPolicy{})
+		require.NoError(tt, err)
+		// The process exits right away, but it isn't reaped until the delay is up
+		select {
+		case <-j.Done():
+			tt.Fatal("job exited before the delay was up")
+		case <-time.After(100 * time.Millisecond):
+		}
+		assert.Equal(tt, job.JobStatusRunning, j.Status().CurrentState)
+		<-j.Done()
+		// Errors aren't injected into reaping
+		status := j.Status()
+		require.NotNil(tt, status.ReturnCode)
+		assert.Equal(tt, 0, *status.ReturnCode)
+	})
+
+	t.Run("read", func(tt *testing.T) {
+		faults := job.NewFaultInjector()
+		j, err := start(tt, tt.TempDir(), faults, job.SegmentPolicy{}, "-burst", "100")
+		require.NoError(tt, err)
+		<-j.Done()
+
+		faults.Inject(job.FaultWatch, job.Injection{Err: injected, Times: 1})
+		_, err = j.Stdout()
+		assert.ErrorIs(tt, err, injected)
+
+		// Reads fail after the first one
+		faults.Inject(job.FaultRead, job.Injection{Err: injected, Skip: 1})
+		stdout, err := j.StdoutSnapshot()
+		require.NoError(tt, err)
+		defer stdout.Close()
+		_, err = io.ReadAll(stdout)
+		assert.ErrorIs(tt, err, injected)
+
+		faults.Clear(job.FaultRead)
+		stdout, err = j.Stdout()
+		require.NoError(tt, err)
+		defer stdout.Close()
+		data, err := io.ReadAll(stdout)
+		require.NoError(tt, err)
+		assert.Len(tt, data, 100)
+	})
+}
+
+// Grants a fixed number of bytes in total
+type fixedQuota struct {
+	lock      sync.Mutex
+	remaining int
+}
+
+func (q *fixedQuota) Reserve(n int) int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	granted := min(n, q.remaining)
+	q.remaining -= granted
+	return granted
+}
+
+// Output discarded by an output window is given back
+func (q *fixedQuota) Release(n int64) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.remaining += int(n)
+}
+
+func TestJobQuota(t *testing.T) {
+	// Enough for the first line of stdout and stderr
+	limit := len("stdout 1\nstderr 1\n")
+
+	t.Run("stop", func(tt *testing.T) {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    testJobPath,
+			Args:       []string{"testjob", "5"},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+			Quota:      &fixedQuota{remaining: limit},
+		})
+		require.NoError(tt, err)
+
+		select {
+		case <-j.Done():
+		case <-time.After(2 * time.Second):
+			require.FailNow(tt, "process should have been killed")
+		}
+		status := j.Status()
+		assert.True(tt, status.QuotaExceeded)
+		assert.Equal(tt, job.JobstatusComplete, status.CurrentState)
+		// Killed by a signal
+		assert.Nil(tt, status.ReturnCode)
+
+		stdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
+		require.NoError(tt, err)
+		stderr, err := os.ReadFile(filepath.Join(dir, "stderr"))
+		require.NoError(tt, err)
+		assert.Equal(tt, limit, len(stdout)+len(stderr))
+	})
+
+	t.Run("truncate", func(tt *testing.T) {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:     testJobPath,
+			Args:        []string{"testjob", "3"},
+			StdoutPath:  filepath.Join(dir, "stdout"),
+			StderrPath:  filepath.Join(dir, "stderr"),
+			Quota:       &fixedQuota{remaining: limit},
+			QuotaAction: job.QuotaActionTruncate,
+		})
+		require.NoError(tt, err)
+
+		<-j.Done()
+		status := j.Status()
+		assert.True(tt, status.QuotaExceeded)
+		// Runs to completion, minus the output
+		require.NotNil(tt, status.ReturnCode)
+		assert.Equal(tt, 0, *status.ReturnCode)
+
+		stdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "stdout 1\n", string(stdout))
+	})
+}
+
+func TestJobOutputWindow(t *testing.T) {
+	// Each line is a write of its own, so each gets a segment
+	line := len("stdout 1\n")
+	window := int64(4 * line)
+
+	for _, key := range [][]byte{nil, bytes.Repeat([]byte{1}, 32)} {
+		t.Run(fmt.Sprintf("encrypted=%t", key != nil), func(tt *testing.T) {
+			dir := tt.TempDir()
+			j, err := job.New(job.JobArgs{
+				Command:      testJobPath,
+				Args:         []string{"testjob", "6"},
+				OutputDir:    dir,
+				StdoutPath:   "stdout",
+				StderrPath:   "stderr",
+				OutputWindow: window,
+				OutputKey:    key,
+				// Only enough for the lines on disk (and the one being written)
+				// at once, so discarded output must be given back
+				Quota: &fixedQuota{remaining: 9 * line},
+			})
+			require.NoError(tt, err)
+
+			// Readers that keep up see everything
+			stdout, err := j.Stdout()
+			require.NoError(tt, err)
+			defer stdout.Close()
+			data, err := io.ReadAll(stdout)
+			require.NoError(tt, err)
+			assert.Equal(tt, expectEchoOutput(true, 6), string(data))
+
+			<-j.Done()
+			status := j.Status()
+			assert.False(tt, status.QuotaExceeded)
+			require.NotNil(tt, status.ReturnCode)
+			assert.Equal(tt, 0, *status.ReturnCode)
+
+			// Only the newest segments are left
+			files, err := job.OutputFiles(filepath.Join(dir, "stdout"))
+			require.NoError(tt, err)
+			assert.Equal(tt, []string{
+				filepath.Join(dir, "stdout.3"),
+				filepath.Join(dir, "stdout.4"),
+				filepath.Join(dir, "stdout.5"),
+				filepath.Join(dir, "stdout.6"),
+			}, files)
+
+			// Readers that come later start with the oldest output still around
+			stderr, err := j.Stderr()
+			require.NoError(tt, err)
+			defer stderr.Close()
+			data, err = io.ReadAll(stderr)
+			require.NoError(tt, err)
+			assert.Equal(tt, strings.TrimPrefix(expectEchoOutput(false, 6), expectEchoOutput(false, 2)), string(data))
+		})
+	}
+
+	t.Run("invalid", func(tt *testing.T) {
+		dir := tt.TempDir()
+		_, err := job.New(job.JobArgs{
+			Command:      testJobPath,
+			Args:         []string{"testjob", "1"},
+			StdoutPath:   filepath.Join(dir, "stdout"),
+			StderrPath:   filepath.Join(dir, "stderr"),
+			OutputWindow: 3,
+		})
+		assert.Error(tt, err)
+	})
+}
+
+func TestJobOutputSegments(t *testing.T) {
+	line := int64(len("stdout 1\n"))
+
+	for name, policy := range map[string]job.SegmentPolicy{
+		"size": {MaxBytes: line},
+		// Lines are written half a second apart
+		"interval": {Interval: 200 * time.Millisecond},
+	} {
+		t.Run(name, func(tt *testing.T) {
+			dir := tt.TempDir()
+			started := time.Now()
+			j, err := job.New(job.JobArgs{
+				Command:    testJobPath,
+				Args:       []string{"testjob", "4"},
+				OutputDir:  dir,
+				StdoutPath: "stdout",
+				StderrPath: "stderr",
+				Segments:   policy,
+			})
+			require.NoError(tt, err)
+			<-j.Done()
+
+			segments, err := j.StdoutSegments()
+			require.NoError(tt, err)
+			require.Len(tt, segments, 4)
+			for i, segment := range segments {
+				assert.Equal(tt, i+1, segment.Number)
+				assert.Equal(tt, line, segment.Size)
+				assert.False(tt, segment.Start.Before(started))
+				assert.True(tt, segment.End.After(segment.Start))
+				if i > 0 {
+					assert.Equal(tt, segments[i-1].End, segment.Start)
+				}
+			}
+
+			segment, err := j.StdoutSegment(2)
+			require.NoError(tt, err)
+			defer segment.Close()
+			data, err := io.ReadAll(segment)
+			require.NoError(tt, err)
+			assert.Equal(tt, "stdout 2\n", string(data))
+
+			_, err = j.StderrSegment(5)
+			assert.ErrorIs(tt, err, job.ErrNoSuchSegment)
+
+			// Readers of the whole stream don't notice the segments
+			stdout, err := j.Stdout()
+			require.NoError(tt, err)
+			defer stdout.Close()
+			data, err = io.ReadAll(stdout)
+			require.NoError(tt, err)
+			assert.Equal(tt, expectEchoOutput(true, 4), string(data))
+		})
+	}
+
+	t.Run("not segmented", func(tt *testing.T) {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    testJobPath,
+			Args:       []string{"testjob", "1"},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+		})
+		require.NoError(tt, err)
+		<-j.Done()
+		_, err = j.StdoutSegments()
+		assert.ErrorIs(tt, err, job.ErrNotSegmented)
+		_, err = j.StdoutSegment(1)
+		assert.ErrorIs(tt, err, job.ErrNotSegmented)
+	})
+}
+
+// Snapshots end where the output ends, without waiting for the job
+func TestJobOutputSnapshot(t *testing.T) {
+	for name, policy := range map[string]job.SegmentPolicy{
+		"plain":    {},
+		"segments": {MaxBytes: 4},
+	} {
+		t.Run(name, func(tt *testing.T) {
+			dir := tt.TempDir()
+			j, err := job.New(job.JobArgs{
+				Command:    "/bin/sh",
+				Args:       []string{"sh", "-c", "echo one; echo two; exec sleep 30"},
+				OutputDir:  dir,
+				StdoutPath: "stdout",
+				StderrPath: "stderr",
+				Segments:   policy,
+			})
+			require.NoError(tt, err)
+			defer func() {
+				assert.NoError(tt, j.Stop())
+				<-j.Done()
+			}()
+
+			require.Eventually(tt, func() bool {
+				stdout, err := j.StdoutSnapshot()
+				require.NoError(tt, err)
+				defer stdout.Close()
+				data, err := io.ReadAll(stdout)
+				require.NoError(tt, err)
+				return string(data) == "one\ntwo\n"
+			}, 5*time.Second, 10*time.Millisecond)
+
+			stderr, err := j.StderrSnapshot()
+			require.NoError(tt, err)
+			defer stderr.Close()
+			data, err := io.ReadAll(stderr)
+			require.NoError(tt, err)
+			assert.Empty(tt, data)
+		})
+	}
+}
+
+func TestJobSinks(t *testing.T) {
+	// Late enough for followers to start following first
+	const script = "sleep 0.3; echo one; echo two >&2"
+	mkfifo := func(tt *testing.T) string {
+		path := filepath.Join(tt.TempDir(), "fifo")
+		require.NoError(tt, unix.Mkfifo(path, 0600))
+		return path
+	}
+	readAll := func(r io.Reader) <-chan string {
+		read := make(chan string, 1)
+		go func() {
+			data, _ := io.ReadAll(r)
+			read <- string(data)
+		}()
+		return read
+	}
+
+	t.Run("fifo", func(tt *testing.T) {
+		fifo := mkfifo(tt)
+		reader, err := os.OpenFile(fifo, os.O_RDONLY|unix.O_NONBLOCK, 0)
+		require.NoError(tt, err)
+		defer reader.Close()
+		consumed := readAll(reader)
+
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    "/bin/sh",
+			Args:       []string{"sh", "-c", script},
+			OutputDir:  dir,
+			StderrPath: "stderr",
+			StdoutSink: fifo,
+		})
+		require.NoError(tt, err)
+		stdout, err := j.Stdout()
+		require.NoError(tt, err)
+		defer stdout.Close()
+		followed := readAll(stdout)
+		<-j.Done()
+
+		assert.Equal(tt, "one\n", <-consumed)
+		assert.Equal(tt, "one\n", <-followed)
+		// Nothing was kept
+		snapshot, err := j.StdoutSnapshot()
+		require.NoError(tt, err)
+		data, err := io.ReadAll(snapshot)
+		require.NoError(tt, err)
+		assert.Empty(tt, data)
+		data, err = os.ReadFile(filepath.Join(dir, "stderr"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "two\n", string(data))
+
+		outputs, err := j.Outputs()
+		require.NoError(tt, err)
+		assert.True(tt, outputs[0].Sink)
+		assert.Equal(tt, fifo, outputs[0].Path)
+		assert.Empty(tt, outputs[0].Files)
+		assert.False(tt, outputs[1].Sink)
+		assert.Len(tt, outputs[1].Files, 1)
+	})
+
+	t.Run("socket", func(tt *testing.T) {
+		path := filepath.Join(tt.TempDir(), "sock")
+		listener, err := net.Listen("unix", path)
+		require.NoError(tt, err)
+		defer listener.Close()
+
+		j, err := job.New(job.JobArgs{
+			Command:    "/bin/sh",
+			Args:       []string{"sh", "-c", script},
+			StdoutPath: filepath.Join(tt.TempDir(), "stdout"),
+			StderrSink: path,
+		})
+		require.NoError(tt, err)
+		conn, err := listener.Accept()
+		require.NoError(tt, err)
+		defer conn.Close()
+		<-j.Done()
+		assert.Equal(tt, "two\n", <-readAll(conn))
+	})
+
+	// The job carries on without it, and followers still get the output
+	t.Run("consumer-gone", func(tt *testing.T) {
+		fifo := mkfifo(tt)
+		reader, err := os.OpenFile(fifo, os.O_RDONLY|unix.O_NONBLOCK, 0)
+		require.NoError(tt, err)
+		j, err := job.New(job.JobArgs{
+			Command:    "/bin/sh",
+			Args:       []string{"sh", "-c", "sleep 0.3; echo one; echo two"},
+			StdoutSink: fifo,
+			StderrPath: filepath.Join(tt.TempDir(), "stderr"),
+		})
+		require.NoError(tt, err)
+		require.NoError(tt, reader.Close())
+		stdout, err := j.Stdout()
+		require.NoError(tt, err)
+		defer stdout.Close()
+		assert.Equal(tt, "one\ntwo\n", <-readAll(stdout))
+		<-j.Done()
+		status := j.Status()
+		require.NotNil(tt, status.ReturnCode)
+		assert.Equal(tt, 0, *status.ReturnCode)
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		dir := tt.TempDir()
+		regular := filepath.Join(dir, "regular")
+		require.NoError(tt, os.WriteFile(regular, nil, 0600))
+		unread := mkfifo(tt)
+		for name, args := range map[string]job.JobArgs{
+			"regular-file": {StdoutSink: regular},
+			"missing":      {StdoutSink: filepath.Join(dir, "missing")},
+			// Nobody reads it
+			"no-reader": {StdoutSink: unread},
+			"encrypted": {StdoutSink: unread, OutputKey: make([]byte, 32)},
+			"segmented": {StdoutSink: unread, Segments: job.SegmentPolicy{MaxBytes: 4096}},
+		} {
+			args.Command, args.Args = testJobPath, []string{"testjob"}
+			args.OutputDir, args.StdoutPath, args.StderrPath = dir, "stdout", "stderr"
+			_, err := job.New(args)
+			assert.Error(tt, err, name)
+		}
+		// Only the file it created is removed
+		entries, err := os.ReadDir(dir)
+		require.NoError(tt, err)
+		assert.Len(tt, entries, 1)
+	})
+}
+
+func TestAdopt(t *testing.T) {
+	// Started as if by someone else, writing to a file of its own
+	startProcess := func(tt *testing.T, script string) (*exec.Cmd, string) {
+		output := filepath.Join(tt.TempDir(), "output")
+		file, err := os.Create(output)
+		require.NoError(tt, err)
+		defer file.Close()
+		cmd := exec.Command("/bin/sh", "-c", script)
+		cmd.Stdout, cmd.Stderr = file, file
+		require.NoError(tt, cmd.Start())
+		// The adopted job can't reap it, so we do
+		tt.Cleanup(func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		})
+		return cmd, output
+	}
+
+	t.Run("stopped", func(tt *testing.T) {
+		cmd, output := startProcess(tt, "echo one; exec sleep 30")
+		var signals []job.ExitReason
+		j, err := job.Adopt(job.AdoptArgs{
+			PID:        cmd.Process.Pid,
+			StdoutPath: output,
+			StderrPath: output,
+			OnSignal: func(_ syscall.Signal, reason job.ExitReason) {
+				signals = append(signals, reason)
+			},
+		})
+		require.NoError(tt, err)
+		assert.Equal(tt, job.JobStatusRunning, j.Status().CurrentState)
+		assert.Equal(tt, cmd.Process.Pid, j.Status().PID)
+
+		stdout, err := j.Stdout()
+		require.NoError(tt, err)
+		defer stdout.Close()
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(stdout, buf)
+		require.NoError(tt, err)
+		assert.Equal(tt, "one\n", string(buf))
+
+		require.NoError(tt, j.Stop())
+		select {
+		case <-j.Done():
+		case <-time.After(5 * time.Second):
+			tt.Fatal("adopted process wasn't seen to exit")
+		}
+		status := j.Status()
+		assert.Equal(tt, job.JobStatusStopped, status.CurrentState)
+		assert.Equal(tt, job.ExitReasonStopped, status.ExitReason)
+		assert.Nil(tt, status.ReturnCode)
+		assert.Equal(tt, []job.ExitReason{job.ExitReasonStopped}, signals)
+		// Whatever's left is read once it's over
+		data, err := io.ReadAll(stdout)
+		require.NoError(tt, err)
+		assert.Empty(tt, data)
+	})
+
+	t.Run("exited", func(tt *testing.T) {
+		cmd, output := startProcess(tt, "sleep 0.2")
+		j, err := job.Adopt(job.AdoptArgs{PID: cmd.Process.Pid, StdoutPath: output, StderrPath: output})
+		require.NoError(tt, err)
+		select {
+		case <-j.Done():
+		case <-time.After(5 * time.Second):
+			tt.Fatal("adopted process wasn't seen to exit")
+		}
+		status := j.Status()
+		assert.Equal(tt, job.JobstatusComplete, status.CurrentState)
+		assert.Equal(tt, job.ExitReasonUnknown, status.ExitReason)
+		assert.Nil(tt, status.ReturnCode)
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		cmd, output := startProcess(tt, "exec sleep 30")
+		for name, args := range map[string]job.AdoptArgs{
+			"init":        {PID: 1, StdoutPath: output, StderrPath: output},
+			"ourselves":   {PID: os.Getpid(), StdoutPath: output, StderrPath: output},
+			"no-process":  {PID: 1 << 30, StdoutPath: output, StderrPath: output},
+			"no-output":   {PID: cmd.Process.Pid, StdoutPath: output + ".missing", StderrPath: output},
+			"not-a-file":  {PID: cmd.Process.Pid, StdoutPath: output, StderrPath: filepath.Dir(output)},
+			"empty-paths": {PID: cmd.Process.Pid},
+		} {
+			_, err := job.Adopt(args)
+			assert.Error(tt, err, name)
+		}
+	})
+}
+
+func TestJobProcesses(t *testing.T) {
+	dir := t.TempDir()
+	j, err := job.New(job.JobArgs{
+		Command:    "/bin/sh",
+		Args:       []string{"sh", "-c", "sleep 30 & exec sleep 31"},
+		StdoutPath: filepath.Join(dir, "stdout"),
+		StderrPath: filepath.Join(dir, "stderr"),
+	})
+	require.NoError(t, err)
+	main := j.Status().PID
+
+	var processes []job.Process
+	require.Eventually(t, func() bool {
+		processes, err = j.Processes()
+		require.NoError(t, err)
+		// Until the shell execs, its command is still 'sh'
+		return len(processes) == 2 && processes[0].Command == "sleep"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, job.Process{PID: main, PPID: os.Getpid(), Command: "sleep"}, processes[0])
+	assert.Equal(t, main, processes[1].PPID)
+	assert.Equal(t, "sleep", processes[1].Command)
+
+	require.NoError(t, j.Stop())
+	<-j.Done()
+	// The background sleep isn't ours to stop, without a subreaper
+	require.NoError(t, unix.Kill(processes[1].PID, unix.SIGKILL))
+	processes, err = j.Processes()
+	require.NoError(t, err)
+	assert.Empty(t, processes)
+	assert.Equal(t, main, j.Status().PID)
+}
+
+// Subreaping is for the whole process, so it's tested in a process of its own
+func TestJobSubreaper(t *testing.T) {
+	if os.Getenv("JOBBY_TEST_SUBREAPER") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestJobSubreaper$", "-test.v")
+		cmd.Env = append(os.Environ(), "JOBBY_TEST_SUBREAPER=1")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return
+	}
+
+	require.NoError(t, job.EnableSubreaper(job.ReaperOptions{SweepInterval: 10 * time.Millisecond}))
+	dir := t.TempDir()
+	// The subshell exits right away, orphaning the sleep
+	j, err := job.New(job.JobArgs{
+		Command:    "/bin/sh",
+		Args:       []string{"sh", "-c", "(sleep 30 &); echo forked"},
+		StdoutPath: filepath.Join(dir, "stdout"),
+		StderrPath: filepath.Join(dir, "stderr"),
+	})
+	require.NoError(t, err)
+	<-j.Done()
+	assert.Equal(t, job.ExitReasonExited, j.Status().ExitReason)
+
+	require.Eventually(t, func() bool {
+		return j.Status().Orphans == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	// Stopping the job stops what it left behind, which is then reaped
+	require.NoError(t, j.Stop())
+	require.Eventually(t, func() bool {
+		return j.Status().Orphans == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestJobProgress(t *testing.T) {
+	dir := t.TempDir()
+	script := `echo "JOBBY_PROGRESS: 10%"
+echo "not JOBBY_PROGRESS: 20%"
+echo "JOBBY_PROGRESS: 150%" >&2
+echo "JOBBY_PROGRESS: lots"
+printf "JOBBY_PROGRESS: 42.5%% copying\r\n"
+printf "JOBBY_PROGRESS: 60%%"`
+
+	var lock sync.Mutex
+	var reports []job.Progress
+	j, err := job.New(job.JobArgs{
+		Command:    "/bin/sh",
+		Args:       []string{"sh", "-c", script},
+		StdoutPath: filepath.Join(dir, "stdout"),
+		StderrPath: filepath.Join(dir, "stderr"),
+		OnProgress: func(progress job.Progress) {
+			lock.Lock()
+			defer lock.Unlock()
+			reports = append(reports, progress)
+		},
+	})
+	require.NoError(t, err)
+	<-j.Done()
+
+	// Out of range, malformed and unfinished reports are ignored
+	lock.Lock()
+	defer lock.Unlock()
+	require.Len(t, reports, 2)
+	assert.Equal(t, 10.0, reports[0].Percent)
+	assert.Empty(t, reports[0].Message)
+	assert.Equal(t, 42.5, reports[1].Percent)
+	assert.Equal(t, "copying", reports[1].Message)
+	assert.False(t, reports[1].Time.Before(reports[0].Time))
+
+	progress := j.Status().Progress
+	require.NotNil(t, progress)
+	assert.Equal(t, reports[1], *progress)
+	lock.Unlock()
+
+	// Reported some other way, as if the job wrote it
+	require.NoError(t, j.ReportProgress(job.Progress{Percent: 75, Message: "from the API"}))
+	progress = j.Status().Progress
+	require.NotNil(t, progress)
+	assert.Equal(t, 75.0, progress.Percent)
+	assert.Equal(t, "from the API", progress.Message)
+	lock.Lock()
+	assert.Len(t, reports, 3)
+
+	// Reports stay in the output
+	stdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
+	require.NoError(t, err)
+	assert.Equal(t, "JOBBY_PROGRESS: 10%\nnot JOBBY_PROGRESS: 20%\nJOBBY_PROGRESS: lots\nJOBBY_PROGRESS: 42.5% copying\r\nJOBBY_PROGRESS: 60%", string(stdout))
+
+	// Not looked for unless asked
+	j, err = job.New(job.JobArgs{
+		Command:    "/bin/sh",
+		Args:       []string{"sh", "-c", script},
+		StdoutPath: filepath.Join(dir, "stdout2"),
+		StderrPath: filepath.Join(dir, "stderr2"),
+	})
+	require.NoError(t, err)
+	<-j.Done()
+	assert.Nil(t, j.Status().Progress)
+	assert.ErrorIs(t, j.ReportProgress(job.Progress{Percent: 50}), job.ErrProgressNotTracked)
+}
+
+func TestJobSync(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		policy   job.SyncPolicy
+		segments job.SegmentPolicy
+	}{
+		{name: "none", policy: job.SyncPolicy{Mode: job.SyncNone}},
+		{name: "exit", policy: job.SyncPolicy{Mode: job.SyncOnExit}},
+		{name: "periodic", policy: job.SyncPolicy{Mode: job.SyncPeriodic, Interval: 10 * time.Millisecond}},
+		{name: "segments", policy: job.SyncPolicy{Mode: job.SyncPeriodic, Interval: 10 * time.Millisecond}, segments: job.SegmentPolicy{MaxBytes: 8}},
+	} {
+		t.Run(tc.name, func(tt *testing.T) {
+			dir := tt.TempDir()
+			j, err := job.New(job.JobArgs{
+				Command:    "/bin/sh",
+				Args:       []string{"sh", "-c", "for i in 1 2 3 4 5; do echo line $i; sleep 0.02; done"},
+				OutputDir:  dir,
+				StdoutPath: "stdout",
+				StderrPath: "stderr",
+				Segments:   tc.segments,
+				Sync:       tc.policy,
+			})
+			require.NoError(tt, err)
+			<-j.Done()
+
+			reader, err := j.Stdout()
+			require.NoError(tt, err)
+			defer reader.Close()
+			output, err := io.ReadAll(reader)
+			require.NoError(tt, err)
+			assert.Equal(tt, "line 1\nline 2\nline 3\nline 4\nline 5\n", string(output))
+		})
+	}
+
+	t.Run("invalid", func(tt *testing.T) {
+		dir := tt.TempDir()
+		_, err := job.New(job.JobArgs{
+			Command:    "/bin/true",
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+			Sync:       job.SyncPolicy{Mode: job.SyncPeriodic},
+		})
+		assert.Error(tt, err)
+	})
+}
+
+func TestOutputFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"out.2", "out.10", "out.1", "out.01", "out.x", "out-1.1", "other.3"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	files, err := job.OutputFiles(filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "out.1"),
+		filepath.Join(dir, "out.2"),
+		filepath.Join(dir, "out.10"),
+	}, files)
+
+	// An output file without a window is all there is
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "out"), nil, 0600))
+	files, err = job.OutputFiles(filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "out")}, files)
+
+	files, err = job.OutputFiles(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestJobOutputs(t *testing.T) {
+	line := int64(len("stdout 1\n"))
+
+	for name, args := range map[string]job.JobArgs{
+		"plain":     {},
+		"encrypted": {OutputKey: bytes.Repeat([]byte{1}, 32)},
+		"segments":  {Segments: job.SegmentPolicy{MaxBytes: line}},
+	} {
+		t.Run(name, func(tt *testing.T) {
+			dir := tt.TempDir()
+			args.Command = testJobPath
+			args.Args = []string{"testjob", "3"}
+			args.OutputDir = dir
+			args.StdoutPath = "stdout"
+			args.StderrPath = "stderr"
+			j, err := job.New(args)
+			require.NoError(tt, err)
+			<-j.Done()
+
+			outputs, err := j.Outputs()
+			require.NoError(tt, err)
+			require.Len(tt, outputs, 2)
+			stdout, stderr := outputs[0], outputs[1]
+			assert.Equal(tt, job.OutputStdout, stdout.Kind)
+			assert.Equal(tt, filepath.Join(dir, "stdout"), stdout.Path)
+			assert.Equal(tt, 3*line, stdout.Size)
+			assert.Equal(tt, job.OutputStderr, stderr.Kind)
+			assert.Equal(tt, filepath.Join(dir, "stderr"), stderr.Path)
+			assert.Equal(tt, 3*int64(len("stderr 1\n")), stderr.Size)
+
+			// The files are the ones on disk
+			var paths []string
+			for _, file := range stdout.Files {
+				paths = append(paths, file.Path)
+			}
+			onDisk, err := job.OutputFiles(stdout.Path)
+			require.NoError(tt, err)
+			assert.Equal(tt, onDisk, paths)
+
+			if args.Segments.MaxBytes == 0 {
+				assert.Nil(tt, stdout.Segments)
+				return
+			}
+			require.Len(tt, stdout.Segments, 3)
+			for i, file := range stdout.Files {
+				assert.Equal(tt, stdout.Segments[i].Size, file.Size)
+			}
+		})
+	}
+
+	t.Run("removed", func(tt *testing.T) {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    testJobPath,
+			Args:       []string{"testjob", "1"},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+		})
+		require.NoError(tt, err)
+		<-j.Done()
+		require.NoError(tt, os.Remove(filepath.Join(dir, "stdout")))
+
+		outputs, err := j.Outputs()
+		require.NoError(tt, err)
+		assert.Empty(tt, outputs[0].Files)
+		assert.Zero(tt, outputs[0].Size)
+		assert.Len(tt, outputs[1].Files, 1)
+	})
+}
+
+func TestJobLimits(t *testing.T) {
+	t.Run("timeout", func(tt *testing.T) {
+		dir := tt.TempDir()
+		fake := clock.NewFake(time.Now())
+		j, err := job.New(job.JobArgs{
+			Command:    testJobPath,
+			Args:       []string{"testjob", "5"},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+			Limits:     job.Limits{Timeout: time.Hour},
+			Clock:      fake,
+		})
+		require.NoError(tt, err)
+
+		// The timer is started after the process
+		require.NoError(tt, fake.WaitFor(context.Background(), 1))
+		fake.Advance(time.Hour)
+		select {
+		case <-j.Done():
+		case <-time.After(2 * time.Second):
+			require.FailNow(tt, "process should have timed out")
+		}
+		status := j.Status()
+		assert.True(tt, status.TimedOut)
+		assert.Equal(tt, job.JobstatusComplete, status.CurrentState)
+		assert.Nil(tt, status.ReturnCode)
+		assert.Equal(tt, job.ExitReasonTimedOut, status.ExitReason)
+		assert.Equal(tt, syscall.SIGKILL, status.Signal)
+		assert.Equal(tt, time.Hour, status.Duration)
+	})
+
+	t.Run("rlimit", func(tt *testing.T) {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    testJobPath,
+			Args:       []string{"testjob", "1"},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+			// Not even enough room for the first line
+			Limits: job.Limits{Rlimits: []job.Rlimit{{Resource: unix.RLIMIT_FSIZE, Soft: 4, Hard: 4}}},
+		})
+		require.NoError(tt, err)
+
+		<-j.Done()
+		assert.False(tt, j.Status().TimedOut)
+		// Writes past the limit fail (Go ignores SIGXFSZ, so the process isn't killed)
+		stdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "stdo", string(stdout))
+	})
+}
+
+func TestJobScheduling(t *testing.T) {
+	run := func(tt *testing.T, sched job.Scheduling) string {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    "/bin/sh",
+			Args:       []string{"sh", "-c", "nice; ionice; grep Cpus_allowed_list /proc/self/status"},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+			Scheduling: sched,
+		})
+		require.NoError(tt, err)
+		<-j.Done()
+		stdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
+		require.NoError(tt, err)
+		return string(stdout)
+	}
+	var defaults string
+
+	t.Run("defaults", func(tt *testing.T) {
+		defaults = run(tt, job.Scheduling{})
+		assert.True(tt, strings.HasPrefix(defaults, "0\n"), defaults)
+	})
+
+	t.Run("applied", func(tt *testing.T) {
+		nice := 10
+		out := run(tt, job.Scheduling{Nice: &nice, IOClass: job.IOClassIdle, CPUs: []int{0}})
+		assert.Equal(tt, "10\nidle\nCpus_allowed_list:\t0\n", out)
+	})
+
+	t.Run("not-leaked", func(tt *testing.T) {
+		// The thread the job was started from isn't reused
+		for range 5 {
+			assert.Equal(tt, defaults, run(tt, job.Scheduling{}))
+		}
+	})
+
+	t.Run("invalid", func(tt *testing.T) {
+		nice := 20
+		for _, sched := range []job.Scheduling{
+			{Nice: &nice},
+			{IOClass: job.IOClassBestEffort, IOPriority: 8},
+			{IOClass: 7},
+			{CPUs: []int{-1}},
+		} {
+			dir := tt.TempDir()
+			_, err := job.New(job.JobArgs{
+				Command:    testJobPath,
+				Args:       []string{"testjob", "1"},
+				StdoutPath: filepath.Join(dir, "stdout"),
+				StderrPath: filepath.Join(dir, "stderr"),
+				Scheduling: sched,
+			})
+			assert.Error(tt, err)
+		}
+	})
+}
+
+func TestJobCPUTime(t *testing.T) {
+	dir := t.TempDir()
+	j, err := job.New(job.JobArgs{
+		Command:    "/bin/sh",
+		Args:       []string{"sh", "-c", "i=0; while [ $i -lt 100000 ]; do i=$((i+1)); done"},
+		StdoutPath: filepath.Join(dir, "stdout"),
+		StderrPath: filepath.Join(dir, "stderr"),
+	})
+	require.NoError(t, err)
+	assert.Zero(t, j.Status().CPUTime)
+
+	<-j.Done()
+	status := j.Status()
+	assert.Positive(t, status.CPUTime)
+	assert.LessOrEqual(t, status.CPUTime, status.Duration)
+}
+
+func TestJobPreempt(t *testing.T) {
+	var signalsLock sync.Mutex
+	var signals []string
+	start := func(tt *testing.T, script string) (*job.Job, string) {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    "/bin/sh",
+			Args:       []string{"sh", "-c", script},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+			OnSignal: func(signal syscall.Signal, reason job.ExitReason) {
+				signalsLock.Lock()
+				defer signalsLock.Unlock()
+				signals = append(signals, fmt.Sprintf("%s %s", unix.SignalName(signal), reason))
+			},
+		})
+		require.NoError(tt, err)
+		return j, dir
+	}
+
+	t.Run("graceful", func(tt *testing.T) {
+		j, dir := start(tt, "trap 'echo cleaning up; exit 3' TERM; echo ready; while :; do sleep 0.05; done")
+		require.Eventually(tt, func() bool {
+			stdout, _ := os.ReadFile(filepath.Join(dir, "stdout"))
+			return string(stdout) == "ready\n"
+		}, 2*time.Second, 10*time.Millisecond)
+		require.NoError(tt, j.Preempt(5*time.Second))
+		// Preempting twice is harmless
+		require.NoError(tt, j.Preempt(5*time.Second))
+
+		select {
+		case <-j.Done():
+		case <-time.After(2 * time.Second):
+			require.FailNow(tt, "process should have exited on SIGTERM")
+		}
+		status := j.Status()
+		assert.Equal(tt, job.JobStatusStopped, status.CurrentState)
+		assert.Equal(tt, job.ExitReasonPreempted, status.ExitReason)
+		require.NotNil(tt, status.ReturnCode)
+		assert.Equal(tt, 3, *status.ReturnCode)
+		stdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "ready\ncleaning up\n", string(stdout))
+	})
+
+	t.Run("killed", func(tt *testing.T) {
+		j, dir := start(tt, "trap '' TERM; echo ready; while :; do sleep 0.05; done")
+		require.Eventually(tt, func() bool {
+			stdout, _ := os.ReadFile(filepath.Join(dir, "stdout"))
+			return string(stdout) == "ready\n"
+		}, 2*time.Second, 10*time.Millisecond)
+		require.NoError(tt, j.Preempt(100*time.Millisecond))
+
+		select {
+		case <-j.Done():
+		case <-time.After(2 * time.Second):
+			require.FailNow(tt, "process should have been killed after the grace period")
+		}
+		status := j.Status()
+		assert.Equal(tt, job.ExitReasonPreempted, status.ExitReason)
+		assert.Equal(tt, syscall.SIGKILL, status.Signal)
+
+		signalsLock.Lock()
+		defer signalsLock.Unlock()
+		// Both subtests' signals
+		assert.Equal(tt, []string{
+			"SIGTERM PREEMPTED",
+			"SIGTERM PREEMPTED", "SIGKILL PREEMPTED",
+		}, signals)
+	})
+}
+
+func TestJobExitReason(t *testing.T) {
+	run := func(tt *testing.T, script string) *job.Job {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    "/bin/sh",
+			Args:       []string{"sh", "-c", script},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+		})
+		require.NoError(tt, err)
+		return j
+	}
+
+	t.Run("running", func(tt *testing.T) {
+		j := run(tt, "sleep 5")
+		defer func() { _ = j.Stop() }()
+		assert.Equal(tt, job.ExitReasonNone, j.Status().ExitReason)
+	})
+
+	t.Run("exited", func(tt *testing.T) {
+		j := run(tt, "exit 3")
+		<-j.Done()
+		status := j.Status()
+		assert.Equal(tt, job.ExitReasonExited, status.ExitReason)
+		assert.Zero(tt, status.Signal)
+	})
+
+	t.Run("signaled", func(tt *testing.T) {
+		// Not OOM killed, so just a signal we didn't send
+		j := run(tt, "kill -9 $$")
+		<-j.Done()
+		status := j.Status()
+		assert.Equal(tt, job.ExitReasonSignaled, status.ExitReason)
+		assert.Equal(tt, syscall.SIGKILL, status.Signal)
+		assert.Nil(tt, status.ReturnCode)
+	})
+
+	t.Run("stopped", func(tt *testing.T) {
+		j := run(tt, "sleep 5")
+		require.NoError(tt, j.Stop())
+		<-j.Done()
+		assert.Equal(tt, job.ExitReasonStopped, j.Status().ExitReason)
+	})
+}
+
+func TestJobEgress(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("network namespaces require root")
+	}
+	run := func(tt *testing.T, script string, egress job.Egress) (*job.Job, string) {
+		dir := tt.TempDir()
+		j, err := job.New(job.JobArgs{
+			Command:    "/bin/sh",
+			Args:       []string{"sh", "-c", script},
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+			Limits:     job.Limits{Isolation: job.Isolation{Network: true, Egress: egress}},
+		})
+		require.NoError(tt, err)
+		<-j.Done()
+		stdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
+		require.NoError(tt, err)
+		return j, string(stdout)
+	}
+
+	t.Run("none", func(tt *testing.T) {
+		_, stdout := run(tt, "ip link", job.Egress{})
+		assert.Contains(tt, stdout, "lo:")
+		assert.NotContains(tt, stdout, "UP")
+	})
+
+	t.Run("loopback", func(tt *testing.T) {
+		_, stdout := run(tt, "ip link", job.Egress{Mode: job.EgressLoopback})
+		assert.Contains(tt, stdout, "LOOPBACK,UP")
+		assert.NotContains(tt, stdout, "eth0")
+	})
+
+	t.Run("allowlist", func(tt *testing.T) {
+		uplinks, err := job.NewUplinkPool(netip.MustParsePrefix("10.254.254.0/29"))
+		require.NoError(tt, err)
+		// Jobs reach the host through the host side of their uplink
+		listener, err := net.Listen("tcp", "0.0.0.0:0")
+		require.NoError(tt, err)
+		defer listener.Close()
+		go func() {
+			_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = io.WriteString(w, "reached")
+			}))
+		}()
+		port := uint16(listener.Addr().(*net.TCPAddr).Port)
+		// Subnets are handed out in order, so the first job's host is at .1 and the second's at .5
+		hosts := netip.MustParsePrefix("10.254.254.0/29")
+		fetch := "wget -q -t 1 -T 1 -O - http://%s:%d/"
+
+		allowed, stdout := run(tt, fmt.Sprintf(fetch, "10.254.254.1", port), job.Egress{
+			Mode:    job.EgressAllowlist,
+			Allow:   []job.EgressRule{{Prefix: hosts, Port: port}},
+			Uplinks: uplinks,
+		})
+		assert.Equal(tt, "reached", stdout)
+		assert.Equal(tt, job.ExitReasonExited, allowed.Status().ExitReason)
+		assert.Zero(tt, *allowed.Status().ReturnCode)
+
+		denied, stdout := run(tt, fmt.Sprintf(fetch, "10.254.254.5", port), job.Egress{
+			Mode:    job.EgressAllowlist,
+			Allow:   []job.EgressRule{{Prefix: hosts, Port: port + 1}},
+			Uplinks: uplinks,
+		})
+		assert.Empty(tt, stdout)
+		assert.NotZero(tt, *denied.Status().ReturnCode)
+
+		// The host side of the uplinks is removed with the jobs
+		require.Eventually(tt, func() bool {
+			interfaces, err := net.Interfaces()
+			require.NoError(tt, err)
+			for _, iface := range interfaces {
+				if strings.HasPrefix(iface.Name, "jobby") {
+					return false
+				}
+			}
+			return true
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("no-uplinks", func(tt *testing.T) {
+		dir := tt.TempDir()
+		_, err := job.New(job.JobArgs{
+			Command:    "/bin/true",
+			StdoutPath: filepath.Join(dir, "stdout"),
+			StderrPath: filepath.Join(dir, "stderr"),
+			Limits: job.Limits{Isolation: job.Isolation{
+				Network: true,
+				Egress:  job.Egress{Mode: job.EgressAllowlist},
+			}},
+		})
+		assert.Error(tt, err)
+	})
+}
+
+// A cgroup v2 hierarchy to create job cgroups in. Skips the test without one
+func cgroupParent(t *testing.T) string {
+	if os.Geteuid() != 0 {
+		t.Skip("creating cgroups requires root")
+	}
+	for _, dir := range []string{"/sys/fs/cgroup", "/sys/fs/cgroup/unified"} {
+		if _, err := os.Stat(filepath.Join(dir, "cgroup.controllers")); err == nil {
+			return dir
+		}
+	}
+	t.Skip("no cgroup v2 hierarchy")
+	return ""
+}
+
+func TestJobGPUs(t *testing.T) {
+	parent := cgroupParent(t)
+	// Stand-ins for /dev/nvidia0 and /dev/nvidia1. There's no driver behind
+	// them, so opening one fails with ENXIO unless the device filter blocks it first
+	devDir := t.TempDir()
+	for minor := range 2 {
+		path := filepath.Join(devDir, fmt.Sprintf("nvidia%d", minor))
+		require.NoError(t, unix.Mknod(path, unix.S_IFCHR|0666, int(unix.Mkdev(195, uint32(minor)))))
+	}
+
+	dir := t.TempDir()
+	j, err := job.New(job.JobArgs{
+		Command:    "/bin/sh",
+		Args:       []string{"sh", "-c", fmt.Sprintf("echo $CUDA_VISIBLE_DEVICES; cat %[1]s/nvidia0; cat %[1]s/nvidia1", devDir)},
+		StdoutPath: filepath.Join(dir, "stdout"),
+		StderrPath: filepath.Join(dir, "stderr"),
+		Limits: job.Limits{
+			Cgroup: &job.CgroupLimits{Parent: parent},
+			GPUs:   []job.GPU{{Index: 0, UUID: "GPU-0"}},
+		},
+	})
+	require.NoError(t, err)
+	<-j.Done()
+
+	stdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
+	require.NoError(t, err)
+	assert.Equal(t, "GPU-0\n", string(stdout))
+	stderr, err := os.ReadFile(filepath.Join(dir, "stderr"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(stderr)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "No such device or address")
+	assert.Contains(t, lines[1], "Operation not permitted")
+
+	// Can't restrict devices without a cgroup
+	_, err = job.New(job.JobArgs{
+		Command:    "/bin/true",
+		StdoutPath: filepath.Join(dir, "stdout"),
+		StderrPath: filepath.Join(dir, "stderr"),
+		Limits:     job.Limits{GPUs: []job.GPU{{Index: 0, UUID: "GPU-0"}}},
+	})
+	assert.Error(t, err)
+}
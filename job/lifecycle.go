@@ -0,0 +1,115 @@
+package job
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Where a job's process is in its life. The public State is derived from it
+type phase int
+
+const (
+	phaseRunning phase = iota
+	// Signaled to exit by us (see stopCause). Running until it's reaped
+	phaseStopping
+	// Reaped. Nothing moves on from here
+	phaseExited
+)
+
+// Whether a job in phase 'p' may move to 'next'. Staying in phase to
+// record another stop cause counts as a move
+func (p phase) canMoveTo(next phase) bool {
+	switch p {
+	case phaseRunning:
+		return next == phaseStopping || next == phaseExited
+	case phaseStopping:
+		return next == phaseStopping || next == phaseExited
+	default:
+		return false
+	}
+}
+
+// Why we signaled a job's process to exit. More than one may apply
+type stopCause uint8
+
+const (
+	stopByUser stopCause = 1 << iota
+	stopByPreemption
+	stopByTimeout
+	stopByQuota
+)
+
+// How the process exited
+type exitInfo struct {
+	// Nil if Wait failed before the process was reaped
+	processState *os.ProcessState
+	endTime      time.Time
+	// The job's cgroup OOM killed one of its processes
+	oomKilled bool
+}
+
+// A job's state at one point in time. Never modified once published
+type jobState struct {
+	phase  phase
+	causes stopCause
+	// Set along with phaseExited
+	exit *exitInfo
+}
+
+// lifecycle is the job's state machine. The state is swapped atomically as a
+// whole, so readers never need a lock and always see a consistent state,
+// and moves are checked against canMoveTo so new phases can't be reached
+// from ones that don't expect them
+type lifecycle struct {
+	current atomic.Pointer[jobState]
+	// Closed once the process has exited
+	done chan struct{}
+}
+
+func newLifecycle() *lifecycle {
+	l := &lifecycle{done: make(chan struct{})}
+	l.current.Store(&jobState{phase: phaseRunning})
+	return l
+}
+
+func (l *lifecycle) get() jobState {
+	return *l.current.Load()
+}
+
+// Atomically replace the state with what 'update' makes of it. Returns the
+// resulting state, or the current one and false if the move isn't allowed
+func (l *lifecycle) update(update func(jobState) jobState) (jobState, bool) {
+	for {
+		current := l.current.Load()
+		next := update(*current)
+		if !current.phase.canMoveTo(next.phase) {
+			return *current, false
+		}
+		if l.current.CompareAndSwap(current, &next) {
+			if next.phase == phaseExited {
+				close(l.done)
+			}
+			return next, true
+		}
+	}
+}
+
+// Record that the process was signaled to exit for 'cause'
+func (l *lifecycle) stopping(cause stopCause) bool {
+	_, ok := l.update(func(s jobState) jobState {
+		s.phase = phaseStopping
+		s.causes |= cause
+		return s
+	})
+	return ok
+}
+
+// Record that the process was reaped
+func (l *lifecycle) exited(exit *exitInfo) {
+	l.update(func(s jobState) jobState {
+		s.phase = phaseExited
+		s.exit = exit
+		return s
+	})
+}
@@ -0,0 +1,36 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycle(t *testing.T) {
+	l := newLifecycle()
+	assert.Equal(t, phaseRunning, l.get().phase)
+
+	assert.True(t, l.stopping(stopByPreemption))
+	// Escalating adds a cause
+	assert.True(t, l.stopping(stopByUser))
+	assert.Equal(t, stopByPreemption|stopByUser, l.get().causes)
+	select {
+	case <-l.done:
+		t.Fatal("done before exiting")
+	default:
+	}
+
+	exit := &exitInfo{oomKilled: true}
+	l.exited(exit)
+	<-l.done
+	assert.Equal(t, jobState{phase: phaseExited, causes: stopByPreemption | stopByUser, exit: exit}, l.get())
+
+	// Exited is final
+	assert.False(t, l.stopping(stopByTimeout))
+	_, ok := l.update(func(s jobState) jobState {
+		s.phase = phaseRunning
+		return s
+	})
+	assert.False(t, ok)
+	assert.Equal(t, stopByPreemption|stopByUser, l.get().causes)
+}
@@ -0,0 +1,227 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Limits restricts the resources and privileges of a job's process
+type Limits struct {
+	// Kill the process once it has run this long. Zero means no limit
+	Timeout time.Duration
+	// Resource limits set with prlimit(2). They are applied right after the
+	// process starts, so its first instants run under our own limits
+	Rlimits []Rlimit
+	// Run the process in its own cgroup. Nil leaves it in ours
+	Cgroup *CgroupLimits
+	// Namespaces to isolate the process in. Creating them generally requires root
+	Isolation Isolation
+	// GPUs the process may use. Any others are blocked with a device filter
+	// on the process's cgroup, so this requires Cgroup. Nil leaves
+	// device access alone
+	GPUs []GPU
+}
+
+type Rlimit struct {
+	// One of the unix.RLIMIT_* constants
+	Resource int
+	Soft     uint64
+	Hard     uint64
+}
+
+// Limits enforced through a cgroup v2 created for the process
+type CgroupLimits struct {
+	// Existing cgroup directory (ex: /sys/fs/cgroup/jobby) to create job cgroups in.
+	// The memory, cpu and pids controllers must be enabled in its cgroup.subtree_control
+	Parent string
+	// memory.max in bytes. Zero means no limit
+	MemoryMax int64
+	// Share of a CPU the process may use (ex: 0.5 or 2). Zero means no limit
+	CPUs float64
+	// pids.max. Zero means no limit
+	PidsMax int64
+}
+
+type Isolation struct {
+	PID     bool
+	Network bool
+	Mount   bool
+	IPC     bool
+	UTS     bool
+	// What a process with network isolation may reach
+	Egress Egress
+}
+
+func (i Isolation) cloneflags() uintptr {
+	var flags uintptr
+	if i.PID {
+		flags |= unix.CLONE_NEWPID
+	}
+	if i.Network && !i.setupNetwork() {
+		flags |= unix.CLONE_NEWNET
+	}
+	if i.Mount {
+		flags |= unix.CLONE_NEWNS
+	}
+	if i.IPC {
+		flags |= unix.CLONE_NEWIPC
+	}
+	if i.UTS {
+		flags |= unix.CLONE_NEWUTS
+	}
+	return flags
+}
+
+// cpu.max period. The quota is scaled from it
+const cpuPeriod = 100000
+
+// Create a cgroup for a single process and apply the limits to it.
+// The returned directory is handed to the process with SysProcAttr.CgroupFD
+func (c *CgroupLimits) create() (*os.File, error) {
+	path, err := os.MkdirTemp(c.Parent, "job-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating cgroup: %w", err)
+	}
+
+	settings := map[string]string{}
+	if c.MemoryMax > 0 {
+		settings["memory.max"] = strconv.FormatInt(c.MemoryMax, 10)
+	}
+	if c.CPUs > 0 {
+		settings["cpu.max"] = fmt.Sprintf("%d %d", int64(c.CPUs*cpuPeriod), cpuPeriod)
+	}
+	if c.PidsMax > 0 {
+		settings["pids.max"] = strconv.FormatInt(c.PidsMax, 10)
+	}
+	for file, value := range settings {
+		if err := os.WriteFile(filepath.Join(path, file), []byte(value), 0); err != nil {
+			return nil, errors.Join(fmt.Errorf("error setting %s: %w", file, err), os.Remove(path))
+		}
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("error opening cgroup: %w", err), os.Remove(path))
+	}
+	return dir, nil
+}
+
+// Remove a cgroup created by 'create'. Only works once the process has exited
+func removeCgroup(dir *os.File) error {
+	return errors.Join(dir.Close(), os.Remove(dir.Name()))
+}
+
+// Whether the kernel OOM killed anything in the cgroup, according to memory.events
+func cgroupOOMKilled(dir *os.File) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir.Name(), "memory.events"))
+	if errors.Is(err, os.ErrNotExist) {
+		// The memory controller isn't enabled, so nothing could have been OOM killed
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("error reading memory.events: %w", err)
+	}
+	kills, err := parseOOMKills(data)
+	return kills > 0, err
+}
+
+// Count of 'oom_kill' events in the contents of a memory.events file
+func parseOOMKills(data []byte) (int64, error) {
+	for line := range strings.Lines(string(data)) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || key != "oom_kill" {
+			continue
+		}
+		kills, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing oom_kill count: %w", err)
+		}
+		return kills, nil
+	}
+	return 0, nil
+}
+
+// Configure the command before it starts. Returns the job's cgroup (if any)
+func (l Limits) prepare(c *exec.Cmd) (*os.File, error) {
+	c.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: l.Isolation.cloneflags(),
+	}
+	if len(l.GPUs) > 0 {
+		if l.Cgroup == nil {
+			return nil, errors.New("GPU access requires a cgroup")
+		}
+		if c.Env == nil {
+			c.Env = os.Environ()
+		}
+		// Last, so the job can't point CUDA at GPUs it can't open
+		c.Env = append(c.Env, gpuEnv(l.GPUs)...)
+	}
+	if l.Cgroup == nil {
+		return nil, nil
+	}
+	cgroup, err := l.Cgroup.create()
+	if err != nil {
+		return nil, err
+	}
+	if len(l.GPUs) > 0 {
+		if err := restrictGPUs(cgroup, l.GPUs); err != nil {
+			return nil, errors.Join(err, removeCgroup(cgroup))
+		}
+	}
+	c.SysProcAttr.UseCgroupFD = true
+	c.SysProcAttr.CgroupFD = int(cgroup.Fd())
+	return cgroup, nil
+}
+
+// Apply rlimits to the started process
+func (l Limits) apply(pid int) error {
+	for _, r := range l.Rlimits {
+		limit := unix.Rlimit{Cur: r.Soft, Max: r.Hard}
+		if err := unix.Prlimit(pid, r.Resource, &limit, nil); err != nil {
+			return fmt.Errorf("error setting rlimit %d: %w", r.Resource, err)
+		}
+	}
+	return nil
+}
+
+// Start the command with its scheduling settings, in a network namespace
+// set up for its egress policy if it needs one. Both are set up on a
+// locked thread, which the process then inherits them from when it's forked
+func (l Limits) start(c *exec.Cmd, sched Scheduling) (*network, error) {
+	if !l.Isolation.setupNetwork() && sched.isZero() {
+		return nil, c.Start()
+	}
+
+	type result struct {
+		network *network
+		err     error
+	}
+	done := make(chan result)
+	go func() {
+		// The thread is left as the job's, so it's never unlocked.
+		// The runtime gets rid of it once this goroutine returns
+		runtime.LockOSThread()
+		var n *network
+		err := sched.apply()
+		if err == nil && l.Isolation.setupNetwork() {
+			n, err = setupNetwork(l.Isolation.Egress)
+		}
+		if err == nil {
+			if err = c.Start(); err != nil {
+				_ = n.close()
+			}
+		}
+		done <- result{network: n, err: err}
+	}()
+	res := <-done
+	return res.network, res.err
+}
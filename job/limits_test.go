@@ -0,0 +1,26 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOOMKills(t *testing.T) {
+	kills, err := parseOOMKills([]byte("low 0\nhigh 12\nmax 40\noom 2\noom_kill 1\noom_group_kill 0\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), kills)
+
+	kills, err = parseOOMKills([]byte("low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n"))
+	require.NoError(t, err)
+	assert.Zero(t, kills)
+
+	// Older kernels may not report it at all
+	kills, err = parseOOMKills([]byte("low 0\nhigh 0\n"))
+	require.NoError(t, err)
+	assert.Zero(t, kills)
+
+	_, err = parseOOMKills([]byte("oom_kill lots\n"))
+	assert.Error(t, err)
+}
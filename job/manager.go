@@ -0,0 +1,363 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/internal/clock"
+)
+
+// What happened to a managed job
+type EventType string
+
+const (
+	// The job's process started
+	EventStarted EventType = "STARTED"
+	// The job signaled its process (ex: to stop it, or for timing out).
+	// See Event.Signal and Event.Reason
+	EventSignaled EventType = "SIGNALED"
+	// The job's process exited. Event.Status says how
+	EventExited EventType = "EXITED"
+	// The job and its output were removed, by Remove or for outliving
+	// its retention
+	EventRemoved EventType = "REMOVED"
+)
+
+// Event is a change in a managed job's state
+type Event struct {
+	Type  EventType
+	JobID uuid.UUID
+	Owner string
+	Time  time.Time
+	// The job's status as of the event
+	Status Status
+	// EventSignaled only
+	Signal syscall.Signal
+	Reason ExitReason
+}
+
+// Watchers that fall this many events behind miss the ones after
+const watchBuffer = 256
+
+type ManagerOptions struct {
+	// Directory output files are created in. Required
+	OutputDir string
+	// Bytes of output each owner's jobs may keep on disk at once. Zero
+	// means unlimited
+	QuotaPerOwner int64
+	// What happens to a job that writes past its owner's quota
+	QuotaAction QuotaAction
+	// How long finished jobs are kept before CollectGarbage removes them.
+	// Zero keeps them until they're removed
+	Retention time.Duration
+	// Called with every event, in order for each job. Called from the
+	// goroutine the change happened on, so it must not block or call
+	// back into the manager's job (ex: Stop from EventSignaled)
+	OnEvent func(Event)
+	// Nil uses the real one
+	Clock clock.Clock
+}
+
+// ManagedJob is a job the Manager started
+type ManagedJob struct {
+	*Job
+	ID    uuid.UUID
+	Owner string
+	// As passed to Start
+	Command string
+	Args    []string
+
+	quota *ownerQuota
+	// Closed once EventExited is published
+	exited chan struct{}
+}
+
+// FinishedAt is when the job's process exited. Zero while it's running
+func (j *ManagedJob) FinishedAt() time.Time {
+	if exit := j.state.get().exit; exit != nil {
+		return exit.endTime
+	}
+	return time.Time{}
+}
+
+// Whether the job has exited. Waits for its EventExited, so that always
+// comes before its EventRemoved
+func (j *ManagedJob) finished() bool {
+	select {
+	case <-j.Done():
+		<-j.exited
+		return true
+	default:
+		return false
+	}
+}
+
+// Manager runs jobs for their owners, so programs can embed job management
+// without the gRPC service: it keeps a registry of jobs by id, caps each
+// owner's output with a quota, removes finished jobs once their retention
+// is up, and publishes every change of state to watchers (see Watch) and
+// ManagerOptions.OnEvent
+type Manager struct {
+	opts  ManagerOptions
+	clock clock.Clock
+
+	lock   sync.Mutex
+	jobs   map[uuid.UUID]*ManagedJob
+	quotas map[string]*ownerQuota
+
+	watchLock sync.Mutex
+	watchers  map[chan Event]struct{}
+}
+
+func NewManager(opts ManagerOptions) (*Manager, error) {
+	if err := ValidateOutputDir(opts.OutputDir); err != nil {
+		return nil, err
+	}
+	if opts.Retention < 0 {
+		return nil, errors.New("retention must not be negative")
+	}
+	return &Manager{
+		opts:     opts,
+		clock:    clock.Or(opts.Clock),
+		jobs:     map[uuid.UUID]*ManagedJob{},
+		quotas:   map[string]*ownerQuota{},
+		watchers: map[chan Event]struct{}{},
+	}, nil
+}
+
+// Start runs a job for 'owner'. Its output goes to files in the manager's
+// output directory, named for its id, so args' OutputDir, StdoutPath,
+// StderrPath and Quota are set by the manager. OnSignal is still called
+func (m *Manager) Start(owner string, args JobArgs) (*ManagedJob, error) {
+	managed := &ManagedJob{
+		ID:      uuid.New(),
+		Owner:   owner,
+		Command: args.Command,
+		Args:    slices.Clone(args.Args),
+		exited:  make(chan struct{}),
+	}
+	args.OutputDir = m.opts.OutputDir
+	args.StdoutPath = managed.ID.String() + "-stdout"
+	args.StderrPath = managed.ID.String() + "-stderr"
+	args.Quota, args.QuotaAction = nil, m.opts.QuotaAction
+	if managed.quota = m.quotaFor(owner); managed.quota != nil {
+		args.Quota = managed.quota
+	}
+	if args.Clock == nil {
+		args.Clock = m.clock
+	}
+	onSignal := args.OnSignal
+	args.OnSignal = func(signal syscall.Signal, reason ExitReason) {
+		if onSignal != nil {
+			onSignal(signal, reason)
+		}
+		m.publish(Event{Type: EventSignaled, JobID: managed.ID, Owner: owner, Signal: signal, Reason: reason})
+	}
+
+	job, err := New(args)
+	if err != nil {
+		return nil, err
+	}
+	managed.Job = job
+	m.lock.Lock()
+	m.jobs[managed.ID] = managed
+	m.lock.Unlock()
+
+	m.publish(Event{Type: EventStarted, JobID: managed.ID, Owner: owner, Status: job.Status()})
+	go func() {
+		<-job.Done()
+		m.publish(Event{Type: EventExited, JobID: managed.ID, Owner: owner, Status: job.Status()})
+		close(managed.exited)
+	}()
+	return managed, nil
+}
+
+// Nil when output is unlimited
+func (m *Manager) quotaFor(owner string) *ownerQuota {
+	if m.opts.QuotaPerOwner <= 0 {
+		return nil
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	q, ok := m.quotas[owner]
+	if !ok {
+		q = &ownerQuota{limit: m.opts.QuotaPerOwner}
+		m.quotas[owner] = q
+	}
+	return q
+}
+
+// Get looks up a job by id
+func (m *Manager) Get(id uuid.UUID) (*ManagedJob, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// List returns the jobs of 'owner' (every job when empty), oldest first
+func (m *Manager) List(owner string) []*ManagedJob {
+	m.lock.Lock()
+	jobs := make([]*ManagedJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if owner == "" || job.Owner == owner {
+			jobs = append(jobs, job)
+		}
+	}
+	m.lock.Unlock()
+	slices.SortFunc(jobs, func(a, b *ManagedJob) int {
+		return a.startTime.Compare(b.startTime)
+	})
+	return jobs
+}
+
+// Watch returns the events of every job from now on, until the context is
+// cancelled. Then the channel is closed. A watcher that falls too far
+// behind misses events rather than holding up the jobs
+func (m *Manager) Watch(ctx context.Context) <-chan Event {
+	events := make(chan Event, watchBuffer)
+	m.watchLock.Lock()
+	m.watchers[events] = struct{}{}
+	m.watchLock.Unlock()
+	go func() {
+		<-ctx.Done()
+		m.watchLock.Lock()
+		delete(m.watchers, events)
+		m.watchLock.Unlock()
+		close(events)
+	}()
+	return events
+}
+
+func (m *Manager) publish(event Event) {
+	event.Time = m.clock.Now()
+	if m.opts.OnEvent != nil {
+		m.opts.OnEvent(event)
+	}
+	m.watchLock.Lock()
+	defer m.watchLock.Unlock()
+	for watcher := range m.watchers {
+		select {
+		case watcher <- event:
+		default:
+		}
+	}
+}
+
+// Stop stops a running job
+func (m *Manager) Stop(id uuid.UUID) error {
+	job, ok := m.Get(id)
+	if !ok {
+		return fmt.Errorf("job %s: %w", id, fs.ErrNotExist)
+	}
+	return job.Stop()
+}
+
+// Remove forgets a finished job and deletes its output, giving the space
+// back to its owner's quota
+func (m *Manager) Remove(id uuid.UUID) error {
+	m.lock.Lock()
+	job, ok := m.jobs[id]
+	finished := ok && job.finished()
+	if finished {
+		delete(m.jobs, id)
+	}
+	m.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("job %s: %w", id, fs.ErrNotExist)
+	}
+	if !finished {
+		return fmt.Errorf("job %s is still running", id)
+	}
+	return m.remove(job)
+}
+
+func (m *Manager) remove(job *ManagedJob) error {
+	outputs, err := job.Outputs()
+	var errs []error
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, output := range outputs {
+		for _, file := range output.Files {
+			if err := os.Remove(file.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			} else if err == nil && job.quota != nil {
+				job.quota.Release(file.Size)
+			}
+		}
+	}
+	m.publish(Event{Type: EventRemoved, JobID: job.ID, Owner: job.Owner, Status: job.Status()})
+	return errors.Join(errs...)
+}
+
+// CollectGarbage removes finished jobs that have outlived the retention as
+// of 'now'. Returns how many were removed
+func (m *Manager) CollectGarbage(now time.Time) (int, error) {
+	if m.opts.Retention == 0 {
+		return 0, nil
+	}
+	var expired []*ManagedJob
+	m.lock.Lock()
+	for id, job := range m.jobs {
+		if job.finished() && now.Sub(job.FinishedAt()) >= m.opts.Retention {
+			delete(m.jobs, id)
+			expired = append(expired, job)
+		}
+	}
+	m.lock.Unlock()
+	var errs []error
+	for _, job := range expired {
+		errs = append(errs, m.remove(job))
+	}
+	return len(expired), errors.Join(errs...)
+}
+
+// RunGarbageCollector collects garbage every interval until the context
+// is cancelled
+func (m *Manager) RunGarbageCollector(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := m.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			if _, err := m.CollectGarbage(now); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// An owner's share of the output directory. Shared by all of their jobs
+type ownerQuota struct {
+	limit int64
+	used  atomic.Int64
+}
+
+func (q *ownerQuota) Reserve(n int) int {
+	for {
+		used := q.used.Load()
+		granted := min(int64(n), max(q.limit-used, 0))
+		if granted == 0 {
+			return 0
+		}
+		if q.used.CompareAndSwap(used, used+granted) {
+			return int(granted)
+		}
+	}
+}
+
+func (q *ownerQuota) Release(n int64) {
+	q.used.Add(-n)
+}
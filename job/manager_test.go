@@ -0,0 +1,134 @@
+package job_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gopheryan/jobby/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nextEvent(t *testing.T, events <-chan job.Event) job.Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return job.Event{}
+	}
+}
+
+func TestManager(t *testing.T) {
+	dir := t.TempDir()
+	var lock sync.Mutex
+	var callbacks []job.EventType
+	manager, err := job.NewManager(job.ManagerOptions{
+		OutputDir:     dir,
+		QuotaPerOwner: 8,
+		QuotaAction:   job.QuotaActionTruncate,
+		Retention:     time.Minute,
+		OnEvent: func(event job.Event) {
+			lock.Lock()
+			defer lock.Unlock()
+			callbacks = append(callbacks, event.Type)
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := manager.Watch(ctx)
+
+	echo, err := manager.Start("alice", job.JobArgs{Command: "/bin/echo", Args: []string{"echo", "hello"}})
+	require.NoError(t, err)
+	event := nextEvent(t, events)
+	assert.Equal(t, job.EventStarted, event.Type)
+	assert.Equal(t, echo.ID, event.JobID)
+	assert.Equal(t, "alice", event.Owner)
+	event = nextEvent(t, events)
+	assert.Equal(t, job.EventExited, event.Type)
+	require.NotNil(t, event.Status.ReturnCode)
+	assert.Equal(t, 0, *event.Status.ReturnCode)
+	assert.False(t, echo.FinishedAt().IsZero())
+	stdout := filepath.Join(dir, echo.ID.String()+"-stdout")
+	output, err := os.ReadFile(stdout)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(output))
+
+	// Two bytes of alice's quota are left
+	truncated, err := manager.Start("alice", job.JobArgs{Command: "/bin/echo", Args: []string{"echo", "goodbye"}})
+	require.NoError(t, err)
+	<-truncated.Done()
+	output, err = os.ReadFile(filepath.Join(dir, truncated.ID.String()+"-stdout"))
+	require.NoError(t, err)
+	assert.Equal(t, "go", string(output))
+	// Bob's is separate
+	sleeper, err := manager.Start("bob", job.JobArgs{Command: "/bin/sleep", Args: []string{"sleep", "60"}})
+	require.NoError(t, err)
+
+	got, ok := manager.Get(echo.ID)
+	require.True(t, ok)
+	assert.Same(t, echo, got)
+	_, ok = manager.Get(uuid.New())
+	assert.False(t, ok)
+	assert.Equal(t, []*job.ManagedJob{echo, truncated}, manager.List("alice"))
+	assert.Equal(t, []*job.ManagedJob{echo, truncated, sleeper}, manager.List(""))
+
+	assert.ErrorContains(t, manager.Remove(sleeper.ID), "still running")
+	require.NoError(t, manager.Stop(sleeper.ID))
+	<-sleeper.Done()
+	assert.ErrorIs(t, manager.Stop(uuid.New()), fs.ErrNotExist)
+
+	// Removing the first job frees up alice's quota
+	require.NoError(t, manager.Remove(echo.ID))
+	assert.NoFileExists(t, stdout)
+	_, ok = manager.Get(echo.ID)
+	assert.False(t, ok)
+	again, err := manager.Start("alice", job.JobArgs{Command: "/bin/echo", Args: []string{"echo", "hello"}})
+	require.NoError(t, err)
+	<-again.Done()
+	output, err = os.ReadFile(filepath.Join(dir, again.ID.String()+"-stdout"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(output))
+
+	removed, err := manager.CollectGarbage(time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+	removed, err = manager.CollectGarbage(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	assert.Empty(t, manager.List(""))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	cancel()
+	counts := map[job.EventType]int{}
+	for event := range events {
+		counts[event.Type]++
+	}
+	// Already had echo's start and exit
+	assert.Equal(t, map[job.EventType]int{
+		job.EventStarted:  3,
+		job.EventSignaled: 1,
+		job.EventExited:   3,
+		job.EventRemoved:  4,
+	}, counts)
+	lock.Lock()
+	assert.Len(t, callbacks, 2+3+1+3+4)
+	lock.Unlock()
+}
+
+func TestNewManagerErrors(t *testing.T) {
+	_, err := job.NewManager(job.ManagerOptions{OutputDir: "relative"})
+	assert.Error(t, err)
+	_, err = job.NewManager(job.ManagerOptions{OutputDir: t.TempDir(), Retention: -time.Second})
+	assert.Error(t, err)
+}
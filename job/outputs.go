@@ -0,0 +1,101 @@
+package job
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/gopheryan/jobby/internal/encryption"
+)
+
+// Which of the process's output streams
+type OutputKind string
+
+const (
+	OutputStdout OutputKind = "stdout"
+	OutputStderr OutputKind = "stderr"
+)
+
+// A file holding some of a job's output
+type OutputFile struct {
+	Path string
+	// Bytes of output in the file, before encryption
+	Size int64
+}
+
+// OutputDescriptor describes where one of a job's output streams is stored
+type OutputDescriptor struct {
+	Kind OutputKind
+	// The path the job was given for the output. Segmented output is
+	// stored beside it instead (see OutputFiles). The sink's path if the
+	// output went to one
+	Path string
+	// Bytes of output still on disk, before encryption
+	Size int64
+	// The files the output is in, oldest first. Empty once they're removed,
+	// and for sinks, which keep nothing
+	Files []OutputFile
+	// Nil unless the job was started with segmented output
+	Segments []OutputSegment
+	// The output went to a FIFO or Unix socket (see JobArgs.StdoutSink)
+	Sink bool
+}
+
+// Outputs describes the job's standard output and standard error, in that
+// order. Sizes are of the output written so far
+func (j *Job) Outputs() ([]OutputDescriptor, error) {
+	stdout, err := j.describeOutput(OutputStdout, j.stdoutPath, j.stdoutSegments, j.stdoutSink)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := j.describeOutput(OutputStderr, j.stderrPath, j.stderrSegments, j.stderrSink)
+	if err != nil {
+		return nil, err
+	}
+	return []OutputDescriptor{stdout, stderr}, nil
+}
+
+func (j *Job) describeOutput(kind OutputKind, path string, segments *segmentWriter, sink *outputSink) (OutputDescriptor, error) {
+	output := OutputDescriptor{Kind: kind, Path: path}
+	if sink != nil {
+		// Opening the FIFO to measure it would block
+		output.Sink = true
+		return output, nil
+	}
+	if segments != nil {
+		// The writer tracks plaintext sizes, so there's no need to scan files
+		output.Segments = segments.list()
+		for _, segment := range output.Segments {
+			output.Files = append(output.Files, OutputFile{Path: segmentPath(path, segment.Number), Size: segment.Size})
+			output.Size += segment.Size
+		}
+		return output, nil
+	}
+
+	size, err := j.plaintextSize(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return output, nil
+	}
+	if err != nil {
+		return OutputDescriptor{}, err
+	}
+	output.Files = []OutputFile{{Path: path, Size: size}}
+	output.Size = size
+	return output, nil
+}
+
+func (j *Job) plaintextSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if j.outputKey == nil {
+		return info.Size(), nil
+	}
+	return encryption.PlaintextSize(f, info.Size())
+}
@@ -0,0 +1,130 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Longest output file name we accept. Matches NAME_MAX on Linux filesystems
+const maxOutputNameLength = 255
+
+// System directories output must never be written into, even if the server
+// is misconfigured (or runs as root). Checked after resolving symlinks
+var privilegedPaths = []string{
+	"/bin",
+	"/boot",
+	"/dev",
+	"/etc",
+	"/lib",
+	"/lib64",
+	"/proc",
+	"/root",
+	"/run",
+	"/sbin",
+	"/sys",
+	"/usr",
+}
+
+// PrivilegedPath reports whether 'path' is the root directory or lies within one
+// of the system directories output files may not be placed in. The check is
+// lexical. Returns the privileged directory that matched
+func PrivilegedPath(path string) (string, bool) {
+	path = filepath.Clean(path)
+	if path == "/" {
+		return path, true
+	}
+	for _, privileged := range privilegedPaths {
+		if path == privileged || strings.HasPrefix(path, privileged+"/") {
+			return privileged, true
+		}
+	}
+	return "", false
+}
+
+// ValidateOutputDir checks that 'dir' is an absolute directory outside of
+// the privileged system paths, following any symlinks along the way
+func ValidateOutputDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("output directory '%s' is not an absolute path", dir)
+	}
+	if privileged, ok := PrivilegedPath(dir); ok {
+		return fmt.Errorf("output directory '%s' is inside privileged path '%s'", dir, privileged)
+	}
+
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("error resolving output directory: %w", err)
+	}
+	if privileged, ok := PrivilegedPath(resolved); ok {
+		return fmt.Errorf("output directory '%s' resolves to '%s' inside privileged path '%s'", dir, resolved, privileged)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return fmt.Errorf("error checking output directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("output directory '%s' is not a directory", dir)
+	}
+	return nil
+}
+
+// ValidateOutputName checks that 'name' is safe to create beneath an output
+// directory: a clean, relative path with no '..' elements that can't refer
+// to the directory itself
+func ValidateOutputName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("output name must not be empty")
+	case strings.ContainsRune(name, 0):
+		return errors.New("output name must not contain NUL bytes")
+	case !filepath.IsLocal(name):
+		return fmt.Errorf("output name '%s' escapes the output directory", name)
+	case filepath.Clean(name) != name || name == ".":
+		return fmt.Errorf("output name '%s' is not a clean path", name)
+	}
+	for _, element := range strings.Split(name, "/") {
+		if len(element) > maxOutputNameLength {
+			return fmt.Errorf("output name element longer than %d bytes", maxOutputNameLength)
+		}
+	}
+	return nil
+}
+
+// Create (or truncate) the file 'name' beneath 'dir'. The kernel refuses to
+// resolve symlinks or anything outside of 'dir', so a path swapped out from under
+// us can't redirect output elsewhere. Kernels without openat2 (before 5.6)
+// fall back to openat with O_NOFOLLOW, which is only safe for plain file names
+func createBeneath(dir string, name string) (*os.File, error) {
+	if err := ValidateOutputName(name); err != nil {
+		return nil, err
+	}
+
+	dirFile, err := os.OpenFile(dir, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error opening output directory: %w", err)
+	}
+	defer dirFile.Close()
+
+	const flags = unix.O_CREAT | unix.O_WRONLY | unix.O_TRUNC | unix.O_CLOEXEC | unix.O_NOFOLLOW
+	fd, err := unix.Openat2(int(dirFile.Fd()), name, &unix.OpenHow{
+		Flags:   flags,
+		Mode:    0640,
+		Resolve: unix.RESOLVE_BENEATH | unix.RESOLVE_NO_SYMLINKS | unix.RESOLVE_NO_MAGICLINKS,
+	})
+	if errors.Is(err, unix.ENOSYS) {
+		if strings.Contains(name, "/") {
+			return nil, fmt.Errorf("output name '%s' has directories, which requires openat2", name)
+		}
+		fd, err = unix.Openat(int(dirFile.Fd()), name, flags, 0640)
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "openat2", Path: filepath.Join(dir, name), Err: err}
+	}
+	return os.NewFile(uintptr(fd), filepath.Join(dir, name)), nil
+}
@@ -0,0 +1,142 @@
+package job
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Process is one of a job's running processes
+type Process struct {
+	PID int
+	// The server's PID for the job's main process, and for the
+	// orphans it adopted (see EnableSubreaper)
+	PPID int
+	// Executable name, as in /proc/<pid>/comm (at most 15 bytes)
+	Command string
+}
+
+// Processes is a snapshot of the job's running processes, the main one
+// first and the rest by PID. PIDs are the host's, even with PID isolation.
+// Jobs with a cgroup are listed from it, which catches processes that left
+// the process tree. Otherwise it's the main process's descendants, and those
+// of any orphans the server adopted from the job. Empty once it all exited
+func (j *Job) Processes() ([]Process, error) {
+	exited := j.state.get().phase == phaseExited
+
+	main := j.cmd.Process.Pid
+	var pids []int
+	var err error
+	if j.cgroupPath != "" && !exited {
+		pids, err = readCgroupProcs(j.cgroupPath)
+	} else {
+		roots := j.reaper.pids(j.reaperTag)
+		if !exited {
+			roots = append(roots, main)
+		}
+		pids, err = descendants(roots)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]Process, 0, len(pids))
+	for _, pid := range pids {
+		stat, err := readProcStat(pid)
+		if err != nil || stat.state == 'Z' {
+			// Exited since we listed it
+			continue
+		}
+		processes = append(processes, Process{PID: pid, PPID: stat.ppid, Command: stat.comm})
+	}
+	slices.SortFunc(processes, func(a, b Process) int {
+		switch {
+		case a.PID == b.PID:
+			return 0
+		case a.PID == main:
+			return -1
+		case b.PID == main:
+			return 1
+		}
+		return cmp.Compare(a.PID, b.PID)
+	})
+	return processes, nil
+}
+
+func readCgroupProcs(path string) ([]int, error) {
+	data, err := os.ReadFile(filepath.Join(path, "cgroup.procs"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading cgroup.procs: %w", err)
+	}
+	var pids []int
+	for _, field := range strings.Fields(string(data)) {
+		pid, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing cgroup.procs: %w", err)
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+// 'roots' and every process descended from them
+func descendants(roots []int) ([]int, error) {
+	if len(roots) == 0 {
+		return nil, nil
+	}
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("error listing processes: %w", err)
+	}
+	children := map[int][]int{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if stat, err := readProcStat(pid); err == nil {
+			children[stat.ppid] = append(children[stat.ppid], pid)
+		}
+	}
+
+	pids := slices.Clone(roots)
+	for i := 0; i < len(pids); i++ {
+		pids = append(pids, children[pids[i]]...)
+	}
+	return pids, nil
+}
+
+// The parts of /proc/<pid>/stat we use
+type procStat struct {
+	comm  string
+	state byte
+	ppid  int
+}
+
+func readProcStat(pid int) (procStat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return procStat{}, err
+	}
+	// The command name is in parentheses, and may contain anything itself
+	start := bytes.IndexByte(data, '(')
+	end := bytes.LastIndexByte(data, ')')
+	if start < 0 || end < start {
+		return procStat{}, errors.New("malformed stat")
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 2 || len(fields[0]) != 1 {
+		return procStat{}, errors.New("malformed stat")
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return procStat{}, fmt.Errorf("malformed stat: %w", err)
+	}
+	return procStat{comm: string(data[start+1 : end]), state: fields[0][0], ppid: ppid}, nil
+}
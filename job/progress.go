@@ -0,0 +1,133 @@
+package job
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+)
+
+// Jobs report how far along they are by writing lines starting with this
+// to stdout or stderr, followed by a percentage and an optional message
+// (ex: "JOBBY_PROGRESS: 42% copying files"). The lines are left in the output
+const ProgressPrefix = "JOBBY_PROGRESS:"
+
+// Returned by Job.ReportProgress for jobs started without JobArgs.OnProgress
+var ErrProgressNotTracked = errors.New("job doesn't track progress")
+
+// Longer lines can't be progress reports, so they aren't held in memory
+const maxProgressLineLength = 1024
+
+// Progress is the latest report a job made of how far along it is
+type Progress struct {
+	// 0 to 100
+	Percent float64
+	// Whatever followed the percentage. May be empty
+	Message string
+	// When the report was written
+	Time time.Time
+}
+
+// Parse a progress report line, without its newline
+func parseProgress(line []byte) (Progress, bool) {
+	rest, ok := bytes.CutPrefix(line, []byte(ProgressPrefix))
+	if !ok {
+		return Progress{}, false
+	}
+	number, message, ok := strings.Cut(string(rest), "%")
+	if !ok {
+		return Progress{}, false
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || math.IsNaN(percent) || percent < 0 || percent > 100 {
+		return Progress{}, false
+	}
+	return Progress{Percent: percent, Message: strings.TrimSpace(message)}, true
+}
+
+// Holds a job's latest progress report
+type progressTracker struct {
+	// Called with each report (see JobArgs.OnProgress)
+	onProgress func(Progress)
+	clock      clock.Clock
+
+	lock   sync.Mutex
+	latest *Progress
+	// Closed and replaced with each report
+	changed chan struct{}
+}
+
+func newProgressTracker(onProgress func(Progress), clock clock.Clock) *progressTracker {
+	return &progressTracker{onProgress: onProgress, clock: clock, changed: make(chan struct{})}
+}
+
+func (t *progressTracker) report(progress Progress) {
+	progress.Time = t.clock.Now().Round(0)
+	t.lock.Lock()
+	t.latest = &progress
+	close(t.changed)
+	t.changed = make(chan struct{})
+	t.lock.Unlock()
+	t.onProgress(progress)
+}
+
+// Latest report, if any, and a channel closed once there's a newer one
+func (t *progressTracker) get() (*Progress, <-chan struct{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.latest == nil {
+		return nil, t.changed
+	}
+	latest := *t.latest
+	return &latest, t.changed
+}
+
+// Passes output through while looking for progress reports in it
+type progressWriter struct {
+	dst     io.Writer
+	tracker *progressTracker
+	// The current line so far, while it could still be a report
+	line []byte
+	// The current line can't be a report. Skip to the next one
+	skip bool
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.dst.Write(p)
+	w.scan(p[:n])
+	return n, err
+}
+
+func (w *progressWriter) scan(p []byte) {
+	for len(p) > 0 {
+		end := bytes.IndexByte(p, '\n')
+		chunk := p
+		if end >= 0 {
+			chunk = p[:end]
+		}
+		if !w.skip {
+			w.line = append(w.line, chunk...)
+			// Give up on lines as soon as they stray from the prefix
+			prefix := min(len(w.line), len(ProgressPrefix))
+			if len(w.line) > maxProgressLineLength || string(w.line[:prefix]) != ProgressPrefix[:prefix] {
+				w.line, w.skip = w.line[:0], true
+			}
+		}
+		if end < 0 {
+			return
+		}
+		if !w.skip {
+			if progress, ok := parseProgress(bytes.TrimSuffix(w.line, []byte("\r"))); ok {
+				w.tracker.report(progress)
+			}
+		}
+		w.line, w.skip = w.line[:0], false
+		p = p[end+1:]
+	}
+}
@@ -0,0 +1,55 @@
+package job
+
+import (
+	"io"
+	"sync"
+)
+
+// OutputQuota accounts for the bytes a job writes to its output files.
+// May be shared between jobs (ex: every job owned by a user)
+type OutputQuota interface {
+	// Reserve space for up to n more bytes. Returns how many bytes were granted
+	Reserve(n int) int
+}
+
+// What to do once a job's output exceeds its quota
+type QuotaAction int
+
+const (
+	// Kill the process
+	QuotaActionStop QuotaAction = iota
+	// Keep the process running but discard any further output
+	QuotaActionTruncate
+)
+
+// Writes to an output file as long as the quota allows it
+type quotaWriter struct {
+	file  io.Writer
+	quota OutputQuota
+	// Called when a write doesn't fit in the quota
+	exceeded func()
+}
+
+func (w *quotaWriter) Write(p []byte) (int, error) {
+	granted := w.quota.Reserve(len(p))
+	if granted > 0 {
+		if n, err := w.file.Write(p[:granted]); err != nil {
+			return n, err
+		}
+	}
+	if granted < len(p) {
+		w.exceeded()
+	}
+	// Report discarded output as written. Failing the write would
+	// only break the pipe and kill the process in a less obvious way
+	return len(p), nil
+}
+
+// Stdout and stderr share one notification
+func newQuotaWriters(stdout, stderr io.Writer, quota OutputQuota) (*quotaWriter, *quotaWriter, <-chan struct{}) {
+	hit := make(chan struct{})
+	exceeded := sync.OnceFunc(func() { close(hit) })
+	return &quotaWriter{file: stdout, quota: quota, exceeded: exceeded},
+		&quotaWriter{file: stderr, quota: quota, exceeded: exceeded},
+		hit
+}
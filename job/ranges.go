@@ -0,0 +1,55 @@
+package job
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// StdoutRange reads bytes [start, end) of the standard output written so
+// far, counting from the oldest output still on disk. The caller checks
+// the range against the output's size (see Outputs). Past the end, the
+// reader ends early
+func (j *Job) StdoutRange(start, end int64) (io.ReadCloser, error) {
+	return j.injectFaults(j.outputRange(j.stdoutPath, j.stdoutSegments, j.stdoutSink, start, end))
+}
+
+// StderrRange is StdoutRange for standard error
+func (j *Job) StderrRange(start, end int64) (io.ReadCloser, error) {
+	return j.injectFaults(j.outputRange(j.stderrPath, j.stderrSegments, j.stderrSink, start, end))
+}
+
+// Plain output in a single file is seeked in. Encrypted or segmented output
+// is read from the start and skipped through
+func (j *Job) outputRange(path string, segments *segmentWriter, sink *outputSink, start, end int64) (io.ReadCloser, error) {
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid output range [%d, %d)", start, end)
+	}
+	if segments == nil && sink == nil && j.outputKey == nil {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(start, io.SeekStart); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return rangeReader{io.LimitReader(f, end-start), f}, nil
+	}
+
+	reader, err := j.snapshot(path, segments, sink)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.CopyN(io.Discard, reader, start); err != nil && err != io.EOF {
+		_ = reader.Close()
+		return nil, err
+	}
+	return rangeReader{io.LimitReader(reader, end-start), reader}, nil
+}
+
+// Reads part of an output, closing the whole of it
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
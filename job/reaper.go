@@ -0,0 +1,307 @@
+package job
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Processes a job leaves behind (ex: daemons that double fork) are normally
+// reparented to init once their parent exits, where they outlive the job
+// unnoticed. As a child subreaper (PR_SET_CHILD_SUBREAPER in prctl(2)) the
+// server adopts them instead. The reaper reaps them once they exit, and
+// signals them along with the job they came from (see EnableSubreaper)
+
+// Environment variable tagging a job's processes, so orphans can be traced
+// back to their job. Processes that clear their environment lose it, and
+// are only reaped
+const reaperTagEnv = "JOBBY_JOB_TAG"
+
+type ReaperOptions struct {
+	// How often to look for orphans besides when SIGCHLD arrives.
+	// SIGCHLD alone isn't enough: signals coalesce, and processes
+	// are adopted while still running without one being sent
+	SweepInterval time.Duration
+	// Kill a job's orphans once its process exits, rather than only
+	// when the job is stopped
+	KillOnExit bool
+}
+
+// An orphan we adopted
+type orphan struct {
+	// Of the job it came from. Empty if it couldn't be traced
+	tag string
+}
+
+// What the reaper knows of a job with a tag
+type taggedJob struct {
+	exited bool
+}
+
+type reaper struct {
+	opts ReaperOptions
+	self int
+
+	// Held for reading while jobs start, and for writing while sweeping,
+	// so we never reap a job's process before it's registered with us
+	starts sync.RWMutex
+
+	// Guards everything below
+	lock sync.Mutex
+	// Processes started by jobs. exec.Cmd reaps those itself
+	jobs    map[int]struct{}
+	orphans map[int]orphan
+	tags    map[string]*taggedJob
+}
+
+var activeReaper struct {
+	lock   sync.Mutex
+	reaper *reaper
+}
+
+// EnableSubreaper makes the server a child subreaper for the rest of its
+// life, adopting and tracking the processes jobs leave behind. Call it
+// before starting any jobs: the processes of earlier ones would be taken
+// for orphans
+func EnableSubreaper(opts ReaperOptions) error {
+	if opts.SweepInterval <= 0 {
+		return errors.New("reaper sweep interval must be positive")
+	}
+	activeReaper.lock.Lock()
+	defer activeReaper.lock.Unlock()
+	if activeReaper.reaper != nil {
+		return errors.New("subreaper already enabled")
+	}
+	if err := unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("error becoming a child subreaper: %w", err)
+	}
+	r := &reaper{
+		opts:    opts,
+		self:    os.Getpid(),
+		jobs:    map[int]struct{}{},
+		orphans: map[int]orphan{},
+		tags:    map[string]*taggedJob{},
+	}
+	activeReaper.reaper = r
+	go r.run()
+	return nil
+}
+
+// Nil unless EnableSubreaper was called
+func currentReaper() *reaper {
+	activeReaper.lock.Lock()
+	defer activeReaper.lock.Unlock()
+	return activeReaper.reaper
+}
+
+func (r *reaper) run() {
+	children := make(chan os.Signal, 1)
+	signal.Notify(children, syscall.SIGCHLD)
+	ticker := time.NewTicker(r.opts.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-children:
+		case <-ticker.C:
+		}
+		if err := r.sweep(); err != nil {
+			slog.Error("Failed to look for orphaned job processes", "error", err)
+		}
+	}
+}
+
+// Tag for a job about to start, to add to its environment
+func newReaperTag() (string, error) {
+	var raw [8]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", fmt.Errorf("error generating job tag: %w", err)
+	}
+	return hex.EncodeToString(raw[:]), nil
+}
+
+// Keeps sweeps out until the returned function is called with the pid of
+// the process started for the job with 'tag' (0 if it failed to start).
+// Once it has been, 'exited' must be called when the process is reaped.
+// Processes that aren't jobs have no tag. Like the other methods, does
+// nothing on a nil reaper
+func (r *reaper) starting(tag string) func(pid int) {
+	if r == nil {
+		return func(int) {}
+	}
+	r.starts.RLock()
+	return func(pid int) {
+		defer r.starts.RUnlock()
+		if pid == 0 {
+			return
+		}
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.jobs[pid] = struct{}{}
+		if tag != "" {
+			r.tags[tag] = &taggedJob{}
+		}
+	}
+}
+
+// RunCommand runs 'cmd' like cmd.Run. Processes the server starts besides
+// jobs (ex: hooks) must be run with it once the subreaper is enabled, or
+// they're taken for orphans and reaped before cmd.Wait can reap them
+func RunCommand(cmd *exec.Cmd) error {
+	r := currentReaper()
+	started := r.starting("")
+	if err := cmd.Start(); err != nil {
+		started(0)
+		return err
+	}
+	started(cmd.Process.Pid)
+	err := cmd.Wait()
+	r.exited(cmd.Process.Pid, "")
+	return err
+}
+
+// The job's process with 'pid' was reaped
+func (r *reaper) exited(pid int, tag string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.jobs, pid)
+	job := r.tags[tag]
+	if job == nil {
+		return
+	}
+	job.exited = true
+	if r.opts.KillOnExit {
+		r.signalLocked(tag, syscall.SIGKILL)
+	}
+}
+
+// Send 'sig' to the orphans left by the job with 'tag'
+func (r *reaper) signal(tag string, sig syscall.Signal) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.signalLocked(tag, sig)
+}
+
+// Our orphans can't be reaped (so their pids can't be reused) without the lock
+func (r *reaper) signalLocked(tag string, sig syscall.Signal) {
+	for pid, orphan := range r.orphans {
+		if orphan.tag != tag {
+			continue
+		}
+		if err := unix.Kill(pid, sig); err != nil && !errors.Is(err, unix.ESRCH) {
+			slog.Error("Failed to signal orphaned job process", "pid", pid, "error", err)
+		}
+	}
+}
+
+// Orphans of the job with 'tag' that are still running, in no particular order
+func (r *reaper) pids(tag string) []int {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var pids []int
+	for pid, orphan := range r.orphans {
+		if orphan.tag == tag {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// Adopt new orphans and reap the ones that exited
+func (r *reaper) sweep() error {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return fmt.Errorf("error listing processes: %w", err)
+	}
+
+	r.starts.Lock()
+	defer r.starts.Unlock()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	alive := map[int]struct{}{}
+	seenTags := map[string]struct{}{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if _, ok := r.jobs[pid]; ok {
+			continue
+		}
+		stat, err := readProcStat(pid)
+		if err != nil || stat.ppid != r.self {
+			// Gone already, or not ours
+			continue
+		}
+		orphan, known := r.orphans[pid]
+		if stat.state == 'Z' {
+			var status unix.WaitStatus
+			if _, err := unix.Wait4(pid, &status, unix.WNOHANG, nil); err != nil {
+				slog.Error("Failed to reap orphaned job process", "pid", pid, "error", err)
+				continue
+			}
+			delete(r.orphans, pid)
+			continue
+		}
+		if !known {
+			orphan.tag = readTag(pid)
+			r.orphans[pid] = orphan
+			slog.Info("Adopted orphaned job process", "pid", pid, "tag", orphan.tag)
+		}
+		alive[pid] = struct{}{}
+		seenTags[orphan.tag] = struct{}{}
+		if job := r.tags[orphan.tag]; job != nil && job.exited && r.opts.KillOnExit {
+			// Orphaned by another orphan we killed
+			if err := unix.Kill(pid, syscall.SIGKILL); err != nil && !errors.Is(err, unix.ESRCH) {
+				slog.Error("Failed to kill orphaned job process", "pid", pid, "error", err)
+			}
+		}
+	}
+	for pid := range r.orphans {
+		if _, ok := alive[pid]; !ok {
+			// Gone without us seeing it as a zombie. Shouldn't happen
+			delete(r.orphans, pid)
+		}
+	}
+	for tag, job := range r.tags {
+		if _, ok := seenTags[tag]; !ok && job.exited {
+			// Nothing of the job is left to adopt
+			delete(r.tags, tag)
+		}
+	}
+	return nil
+}
+
+// The job tag in a process's environment. Empty if it has none
+func readTag(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+	if err != nil {
+		return ""
+	}
+	for entry := range bytes.SplitSeq(data, []byte{0}) {
+		if value, ok := bytes.CutPrefix(entry, []byte(reaperTagEnv+"=")); ok {
+			return string(value)
+		}
+	}
+	return ""
+}
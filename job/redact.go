@@ -0,0 +1,128 @@
+package job
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Partial lines longer than this are redacted and written as they are
+// rather than held in memory waiting for a newline. A secret straddling
+// the cut won't be matched
+const maxRedactLineLength = 64 * 1024
+
+// Used when a redaction doesn't specify its own replacement
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// Redaction masks text in a job's output before it's written to disk. Since
+// every reader (streams, history, exports) reads the files, none of them ever
+// see the original text. Patterns are matched against one line at a time
+type Redaction struct {
+	pattern *regexp.Regexp
+	// Expanded like regexp.Regexp.Expand, so it may refer to submatches
+	replacement []byte
+}
+
+// NewRedaction replaces matches of the RE2 expression 'pattern' with
+// 'replacement' ("$1" refers to the first submatch). An empty replacement
+// uses DefaultRedactionReplacement
+func NewRedaction(pattern string, replacement string) (Redaction, error) {
+	if pattern == "" {
+		return Redaction{}, errors.New("redaction pattern must not be empty")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Redaction{}, fmt.Errorf("invalid redaction pattern: %w", err)
+	}
+	if replacement == "" {
+		replacement = DefaultRedactionReplacement
+	}
+	return Redaction{pattern: re, replacement: []byte(replacement)}, nil
+}
+
+// Redact applies 'redactions' to 's' the way they're applied to a line of output
+func Redact(s string, redactions []Redaction) string {
+	if len(redactions) == 0 {
+		return s
+	}
+	return string(redactLine([]byte(s), redactions))
+}
+
+func redactLine(line []byte, redactions []Redaction) []byte {
+	for _, r := range redactions {
+		line = r.redact(line)
+	}
+	return line
+}
+
+// Like regexp.Regexp.ReplaceAll, but empty matches are left alone
+// rather than filled with the replacement
+func (r Redaction) redact(line []byte) []byte {
+	var out []byte
+	last := 0
+	for _, match := range r.pattern.FindAllSubmatchIndex(line, -1) {
+		if match[0] == match[1] {
+			continue
+		}
+		out = append(out, line[last:match[0]]...)
+		out = r.pattern.Expand(out, r.replacement, line, match)
+		last = match[1]
+	}
+	if last == 0 {
+		// Nothing matched (a match ending at 0 would be empty)
+		return line
+	}
+	return append(out, line[last:]...)
+}
+
+// Redacts output one line at a time on its way to a file
+type redactWriter struct {
+	file       io.Writer
+	redactions []Redaction
+	// Trailing output that doesn't end in a newline yet
+	partial []byte
+}
+
+func (w *redactWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	end := bytes.LastIndexByte(w.partial, '\n') + 1
+	if len(w.partial)-end > maxRedactLineLength {
+		// Not much of a line. Write it rather than hold on to it
+		end = len(w.partial)
+	}
+	if end == 0 {
+		return len(p), nil
+	}
+
+	if err := w.writeRedacted(w.partial[:end]); err != nil {
+		return 0, err
+	}
+	w.partial = bytes.Clone(w.partial[end:])
+	return len(p), nil
+}
+
+func (w *redactWriter) writeRedacted(data []byte) error {
+	var out []byte
+	for line := range bytes.Lines(data) {
+		// Keep patterns like \s from eating the newline
+		content, newline := bytes.CutSuffix(line, []byte("\n"))
+		out = append(out, redactLine(content, w.redactions)...)
+		if newline {
+			out = append(out, '\n')
+		}
+	}
+	_, err := w.file.Write(out)
+	return err
+}
+
+// Write out a trailing partial line. Called once the process's output is exhausted
+func (w *redactWriter) Flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	err := w.writeRedacted(w.partial)
+	w.partial = nil
+	return err
+}
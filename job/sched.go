@@ -0,0 +1,106 @@
+package job
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// I/O scheduling classes, as in IOPRIO_CLASS_* from linux/ioprio.h
+type IOClass int
+
+const (
+	// Leave the I/O class alone
+	IOClassNone IOClass = iota
+	// Always served first. Setting it requires CAP_SYS_ADMIN
+	IOClassRealtime
+	// Served in order of priority. The default for most processes
+	IOClassBestEffort
+	// Only served when no one else wants the disk
+	IOClassIdle
+)
+
+// Bounds of nice values and I/O priorities
+const (
+	MinNice       = -20
+	MaxNice       = 19
+	MaxIOPriority = 7
+)
+
+// CPUs a unix.CPUSet can hold
+const maxCPUs = 1024
+
+// From linux/ioprio.h
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+)
+
+// Scheduling controls how the kernel schedules a job's process against
+// everything else on the host. It's set before the process is started, so
+// even its first instruction runs with it. The zero value changes nothing
+type Scheduling struct {
+	// Nice value (MinNice to MaxNice). Nil keeps ours. Lowering it below
+	// ours requires CAP_SYS_NICE
+	Nice *int
+	// Zero keeps ours
+	IOClass IOClass
+	// Priority within IOClass, 0 (highest) to MaxIOPriority. Ignored for IOClassIdle
+	IOPriority int
+	// CPUs the process may run on. Empty allows the same CPUs as us
+	CPUs []int
+}
+
+func (s Scheduling) isZero() bool {
+	return s.Nice == nil && s.IOClass == IOClassNone && len(s.CPUs) == 0
+}
+
+// Validate checks the settings are within the kernel's bounds
+func (s Scheduling) Validate() error {
+	if s.Nice != nil && (*s.Nice < MinNice || *s.Nice > MaxNice) {
+		return fmt.Errorf("nice must be between %d and %d", MinNice, MaxNice)
+	}
+	if s.IOClass < IOClassNone || s.IOClass > IOClassIdle {
+		return fmt.Errorf("unknown I/O class %d", s.IOClass)
+	}
+	if s.IOPriority < 0 || s.IOPriority > MaxIOPriority {
+		return fmt.Errorf("I/O priority must be between 0 and %d", MaxIOPriority)
+	}
+	for _, cpu := range s.CPUs {
+		if cpu < 0 || cpu >= maxCPUs {
+			return fmt.Errorf("CPUs must be between 0 and %d", maxCPUs-1)
+		}
+	}
+	return nil
+}
+
+// Apply the settings to the calling thread, which must be locked. Nice
+// values, I/O priorities and affinity are all per thread on Linux and
+// are inherited by the processes the thread forks
+func (s Scheduling) apply() error {
+	if s.Nice != nil {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, unix.Gettid(), *s.Nice); err != nil {
+			return fmt.Errorf("error setting nice value: %w", err)
+		}
+	}
+	if s.IOClass != IOClassNone {
+		priority := s.IOPriority
+		if s.IOClass == IOClassIdle {
+			priority = 0
+		}
+		ioprio := int(s.IOClass)<<ioprioClassShift | priority
+		if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(unix.Gettid()), uintptr(ioprio)); errno != 0 {
+			return fmt.Errorf("error setting I/O priority: %w", errno)
+		}
+	}
+	if len(s.CPUs) > 0 {
+		var set unix.CPUSet
+		for _, cpu := range s.CPUs {
+			set.Set(cpu)
+		}
+		if err := unix.SchedSetaffinity(unix.Gettid(), &set); err != nil {
+			return fmt.Errorf("error setting CPU affinity: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// CreateScratch makes an empty directory at 'path' for a job to write
+// whatever it likes to. With a non-zero 'tmpfsBytes' a tmpfs of that size is
+// mounted on it, which takes CAP_SYS_ADMIN. Remove it with RemoveScratch
+func CreateScratch(path string, tmpfsBytes uint64) error {
+	if err := os.Mkdir(path, 0700); err != nil {
+		return fmt.Errorf("error creating scratch directory: %w", err)
+	}
+	if tmpfsBytes == 0 {
+		return nil
+	}
+	// The job's files are its own business. Nothing in there runs setuid
+	// or opens devices on the host's behalf
+	options := fmt.Sprintf("size=%d,mode=0700", tmpfsBytes)
+	if err := unix.Mount("tmpfs", path, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, options); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("error mounting scratch tmpfs: %w", err)
+	}
+	return nil
+}
+
+// RemoveScratch deletes a directory made by CreateScratch and everything in
+// it, unmounting its tmpfs if it has one. Processes the job left behind may
+// still have files open, so the unmount is lazy
+func RemoveScratch(path string, tmpfs bool) error {
+	if tmpfs {
+		err := unix.Unmount(path, unix.MNT_DETACH)
+		if err != nil && !errors.Is(err, unix.ENOENT) {
+			return fmt.Errorf("error unmounting scratch tmpfs: %w", err)
+		}
+	}
+	return os.RemoveAll(path)
+}
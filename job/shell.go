@@ -0,0 +1,9 @@
+package job
+
+// Shell runs command lines given as one string (ex: "make | tee log")
+const Shell = "/bin/sh"
+
+// ShellCommand is the Command and Args of a job running 'line' with Shell
+func ShellCommand(line string) (string, []string) {
+	return Shell, []string{"sh", "-c", line}
+}
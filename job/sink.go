@@ -0,0 +1,72 @@
+package job
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+
+	"github.com/gopheryan/jobby/internal/streamer"
+	"golang.org/x/sys/unix"
+)
+
+// Unread output each follower of a sink may have before it's cut off
+// (see streamer.Broadcast). Sinks don't keep output for slow readers
+const sinkMaxLag = 4 * 1024 * 1024
+
+// An output stream sent to a FIFO or Unix socket instead of a file
+// (see JobArgs.StdoutSink). Followers of the job's output get it from a
+// broadcast, since there's no file to read it back from
+type outputSink struct {
+	path      string
+	conn      io.WriteCloser
+	followers *streamer.Broadcast
+	// Set once writing to the sink fails. Only the goroutine
+	// copying the process's output touches it
+	broken bool
+}
+
+// The sink at 'path' has to exist already, and have something reading it.
+// Opening a FIFO nobody reads fails rather than holding up the job
+func openSink(path string) (*outputSink, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var conn io.WriteCloser
+	switch mode := info.Mode(); {
+	case mode&fs.ModeNamedPipe != 0:
+		conn, err = os.OpenFile(path, os.O_WRONLY|unix.O_NONBLOCK, 0)
+	case mode&fs.ModeSocket != 0:
+		conn, err = net.Dial("unix", path)
+	default:
+		return nil, fmt.Errorf("output sink '%s' is not a FIFO or Unix socket", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening output sink: %w", err)
+	}
+	return &outputSink{path: path, conn: conn, followers: streamer.NewBroadcast(sinkMaxLag)}, nil
+}
+
+// Never fails, so the process doesn't lose its output pipe when whatever
+// consumes the sink goes away. Followers keep getting the output
+func (s *outputSink) Write(p []byte) (int, error) {
+	if !s.broken {
+		if _, err := s.conn.Write(p); err != nil {
+			slog.Error("Failed to write to output sink, dropping further output", "path", s.path, "error", err)
+			s.broken = true
+		}
+	}
+	return s.followers.Write(p)
+}
+
+// Safe on a nil sink
+func (s *outputSink) Close() error {
+	if s == nil {
+		return nil
+	}
+	_ = s.followers.Close()
+	return s.conn.Close()
+}
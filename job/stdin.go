@@ -0,0 +1,25 @@
+package job
+
+import (
+	"errors"
+	"io"
+)
+
+// Returned by Job.Stdin for jobs started without JobArgs.Stdin
+var ErrNoStdin = errors.New("job has no stdin")
+
+// Returned by Job.Stdin once someone else has it
+var ErrStdinTaken = errors.New("job's stdin is already taken")
+
+// Stdin returns the write end of the process's stdin. Writes block while
+// the process isn't reading, and closing it gives the process EOF. There's
+// only one writer, so only the first caller gets it
+func (j *Job) Stdin() (io.WriteCloser, error) {
+	if j.stdin == nil {
+		return nil, ErrNoStdin
+	}
+	if !j.stdinTaken.CompareAndSwap(false, true) {
+		return nil, ErrStdinTaken
+	}
+	return j.stdin, nil
+}
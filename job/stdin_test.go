@@ -0,0 +1,63 @@
+package job_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopheryan/jobby/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdin(t *testing.T) {
+	newJob := func(tt *testing.T, dir string, stdin bool) *job.Job {
+		j, err := job.New(job.JobArgs{
+			Command:    "/bin/cat",
+			Args:       []string{"cat"},
+			OutputDir:  dir,
+			StdoutPath: "stdout",
+			StderrPath: "stderr",
+			Stdin:      stdin,
+		})
+		require.NoError(tt, err)
+		return j
+	}
+
+	t.Run("piped", func(tt *testing.T) {
+		dir := tt.TempDir()
+		j := newJob(tt, dir, true)
+		stdin, err := j.Stdin()
+		require.NoError(tt, err)
+		_, err = j.Stdin()
+		assert.ErrorIs(tt, err, job.ErrStdinTaken)
+
+		_, err = stdin.Write([]byte("hello\n"))
+		require.NoError(tt, err)
+		// Gives cat EOF, so it exits
+		require.NoError(tt, stdin.Close())
+		<-j.Done()
+		data, err := os.ReadFile(filepath.Join(dir, "stdout"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "hello\n", string(data))
+	})
+
+	t.Run("none", func(tt *testing.T) {
+		// Reads /dev/null, so it exits right away
+		j := newJob(tt, tt.TempDir(), false)
+		<-j.Done()
+		_, err := j.Stdin()
+		assert.ErrorIs(tt, err, job.ErrNoStdin)
+	})
+
+	t.Run("exited", func(tt *testing.T) {
+		j := newJob(tt, tt.TempDir(), true)
+		stdin, err := j.Stdin()
+		require.NoError(tt, err)
+		require.NoError(tt, j.Stop())
+		<-j.Done()
+		// Writers aren't left hanging once the process is gone
+		_, err = stdin.Write([]byte("too late\n"))
+		assert.Error(tt, err)
+	})
+}
@@ -0,0 +1,101 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// How hard a job tries to get its output onto disk (see JobArgs.Sync)
+type SyncMode int
+
+const (
+	// Leave writing output back to the kernel. Fastest, but whatever
+	// it hadn't written yet is lost if the host crashes
+	SyncNone SyncMode = iota
+	// Sync output once the job exits, so the output of finished jobs
+	// survives crashes
+	SyncOnExit
+	// Like SyncOnExit, and also sync every SyncPolicy.Interval while the
+	// job runs, bounding how much output a crash can lose
+	SyncPeriodic
+)
+
+type SyncPolicy struct {
+	Mode SyncMode
+	// How often SyncPeriodic syncs. Ignored by the other modes
+	Interval time.Duration
+}
+
+func (p SyncPolicy) Validate() error {
+	switch p.Mode {
+	case SyncNone, SyncOnExit:
+		return nil
+	case SyncPeriodic:
+		if p.Interval <= 0 {
+			return errors.New("periodic sync interval must be positive")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown sync mode %d", p.Mode)
+	}
+}
+
+// Syncs a job's output files, and the directories holding them so
+// the files themselves are sure to survive too
+type outputSyncer struct {
+	files []interface{ Sync() error }
+	dirs  []string
+
+	lock sync.Mutex
+	// Set once the files are about to be closed. Syncs do nothing after
+	stopped bool
+}
+
+func newOutputSyncer(stdoutPath string, stderrPath string, files ...interface{ Sync() error }) *outputSyncer {
+	dirs := []string{filepath.Dir(stdoutPath)}
+	if dir := filepath.Dir(stderrPath); dir != dirs[0] {
+		dirs = append(dirs, dir)
+	}
+	return &outputSyncer{files: slices.Clip(files), dirs: dirs}
+}
+
+func (s *outputSyncer) sync() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopped {
+		return nil
+	}
+	var errs []error
+	for _, file := range s.files {
+		if err := file.Sync(); err != nil {
+			errs = append(errs, fmt.Errorf("error syncing output file: %w", err))
+		}
+	}
+	for _, dir := range s.dirs {
+		if err := syncDir(dir); err != nil {
+			errs = append(errs, fmt.Errorf("error syncing output directory: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// No syncs after this returns. Waits for one in progress to finish
+func (s *outputSyncer) stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stopped = true
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	return errors.Join(err, dir.Close())
+}
@@ -0,0 +1,64 @@
+package job
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+)
+
+// Layout of the timestamps JobArgs.Timestamps prefixes lines with. Always
+// UTC with every digit of the nanoseconds, so prefixes are the same length
+const TimestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Length of a timestamp prefix, including the space that ends it
+const TimestampPrefixLength = len(TimestampLayout) + 1
+
+// AppendTimestamp appends the prefix for a line captured at 't'
+func AppendTimestamp(b []byte, t time.Time) []byte {
+	b = t.UTC().AppendFormat(b, TimestampLayout)
+	return append(b, ' ')
+}
+
+// CutTimestamp splits a timestamp prefix off the start of 'line'.
+// False if it doesn't start with one
+func CutTimestamp(line []byte) (time.Time, []byte, bool) {
+	if len(line) < TimestampPrefixLength || line[TimestampPrefixLength-1] != ' ' {
+		return time.Time{}, line, false
+	}
+	t, err := time.Parse(TimestampLayout, string(line[:TimestampPrefixLength-1]))
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	return t, line[TimestampPrefixLength:], true
+}
+
+// Prefixes each line of output with when it was written to us. Lines
+// arriving over several writes are stamped with the time of the first
+type timestampWriter struct {
+	dst   io.Writer
+	clock clock.Clock
+	// The last write ended partway through a line
+	midLine bool
+	buf     []byte
+}
+
+func (w *timestampWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	prefix := AppendTimestamp(nil, w.clock.Now())
+	w.buf = w.buf[:0]
+	for line := range bytes.Lines(p) {
+		if !w.midLine {
+			w.buf = append(w.buf, prefix...)
+		}
+		w.buf = append(w.buf, line...)
+		w.midLine = line[len(line)-1] != '\n'
+	}
+	if _, err := w.dst.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
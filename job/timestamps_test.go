@@ -0,0 +1,47 @@
+package job
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampWriter(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	fake := clock.NewFake(start)
+	var out bytes.Buffer
+	w := &timestampWriter{dst: &out, clock: fake}
+
+	_, err := w.Write([]byte("first\nsecond "))
+	require.NoError(t, err)
+	fake.Advance(1500 * time.Millisecond)
+	// Finishes the line it started. Only the next one gets the new time
+	_, err = w.Write([]byte("line\nthird\n"))
+	require.NoError(t, err)
+	assert.Equal(t,
+		"2025-06-01T17:00:00.000000000Z first\n"+
+			"2025-06-01T17:00:00.000000000Z second line\n"+
+			"2025-06-01T17:00:01.500000000Z third\n",
+		out.String())
+}
+
+func TestCutTimestamp(t *testing.T) {
+	when := time.Date(2025, 6, 1, 17, 0, 1, 5, time.UTC)
+	line := AppendTimestamp(nil, when)
+	assert.Len(t, line, TimestampPrefixLength)
+
+	stamped, rest, ok := CutTimestamp(append(line, "hello\n"...))
+	require.True(t, ok)
+	assert.True(t, when.Equal(stamped))
+	assert.Equal(t, "hello\n", string(rest))
+
+	for _, line := range []string{"", "hello\n", "2025-06-01T17:00:01Z hello", "2025-06-01T17:00:01.000000000Zhello"} {
+		_, rest, ok := CutTimestamp([]byte(line))
+		assert.False(t, ok, line)
+		assert.Equal(t, line, string(rest))
+	}
+}
@@ -0,0 +1,438 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopheryan/jobby/internal/clock"
+	"github.com/gopheryan/jobby/internal/encryption"
+	"github.com/gopheryan/jobby/internal/streamer"
+)
+
+// Output with a window is split into segments of about 1/n of the window.
+// Only the newest are kept, so between (n-1)/n of the window and all of it
+// stays on disk
+const outputWindowSegments = 4
+
+// Returned when asking for a segment that was discarded or not started yet
+var ErrNoSuchSegment = errors.New("no such output segment")
+
+// Returned when asking for the segments of output that isn't segmented
+var ErrNotSegmented = errors.New("output isn't segmented")
+
+// When to start a new output segment (see JobArgs.Segments).
+// Whichever limit is reached first applies
+type SegmentPolicy struct {
+	// Start a new segment once the current one holds this many bytes.
+	// Zero for no limit
+	MaxBytes int64
+	// Start a new segment at every multiple of Interval on the wall clock
+	// (ex: on every 5th minute), so segments line up with times people
+	// ask about. Zero for no limit
+	Interval time.Duration
+}
+
+// Whether the policy splits output at all
+func (p SegmentPolicy) enabled() bool {
+	return p.MaxBytes > 0 || p.Interval > 0
+}
+
+// One of the files a job's segmented output is kept in
+type OutputSegment struct {
+	// Starts at 1. Each new segment gets the next number
+	Number int
+	// When the segment was started
+	Start time.Time
+	// When the segment was done being written. Zero for the one being written
+	End time.Time
+	// Bytes of output in the segment, before encryption
+	Size int64
+}
+
+// ReleasableQuota is an OutputQuota that takes space back when a job
+// discards old output (see JobArgs.OutputWindow)
+type ReleasableQuota interface {
+	OutputQuota
+	Release(n int64)
+}
+
+// Already closed. Segments that are done being written (and snapshots) wait on it
+var segmentDone = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// Name of segment 'n' of the output file 'path'. Segments are never renamed,
+// since encryption keys are derived from file names
+func segmentPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// OutputFiles lists the files holding the output written to 'path', oldest
+// first. That's 'path' itself, unless the job had an output window, in which
+// case it's the segments still on disk. Empty if there aren't any
+func OutputFiles(path string) ([]string, error) {
+	if _, err := os.Stat(path); err == nil {
+		return []string{path}, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing output segments: %w", err)
+	}
+	prefix := filepath.Base(path) + "."
+	var segments []int
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+		// Only the names segmentPath gives out
+		if n, err := strconv.Atoi(suffix); err == nil && n > 0 && strconv.Itoa(n) == suffix {
+			segments = append(segments, n)
+		}
+	}
+	slices.Sort(segments)
+	files := make([]string, 0, len(segments))
+	for _, n := range segments {
+		files = append(files, segmentPath(path, n))
+	}
+	return files, nil
+}
+
+// Writes output as a series of segment files, discarding the oldest once
+// the rest still fill the output window
+type segmentWriter struct {
+	// As passed to createOutputFile
+	dir  string
+	name string
+	// Where segments are found. 'name' joined to 'dir'
+	path string
+	// Zero keeps every segment
+	window int64
+	policy SegmentPolicy
+	// Nil writes plaintext
+	key []byte
+	// Nil if discarded output isn't given back
+	quota ReleasableQuota
+	// Sync finished segments before closing them (see SyncPolicy)
+	syncFinished bool
+	clock        clock.Clock
+
+	lock sync.Mutex
+	// Segments still on disk, oldest first. The last is the one being written
+	segments []OutputSegment
+	file     *os.File
+	dst      io.Writer
+	// Closed once the current segment is done being written
+	done chan struct{}
+	// Set once the writer is closed
+	closed bool
+}
+
+func newSegmentWriter(dir string, name string, window int64, policy SegmentPolicy, key []byte, quota OutputQuota, syncFinished bool, clock clock.Clock) (*segmentWriter, error) {
+	w := &segmentWriter{
+		dir:          dir,
+		name:         name,
+		path:         name,
+		window:       window,
+		policy:       policy,
+		key:          key,
+		syncFinished: syncFinished,
+		clock:        clock,
+	}
+	if dir != "" {
+		w.path = filepath.Join(dir, name)
+	}
+	if window > 0 {
+		// Small enough that discarding one still leaves most of the window
+		size := window / outputWindowSegments
+		if w.policy.MaxBytes == 0 || size < w.policy.MaxBytes {
+			w.policy.MaxBytes = size
+		}
+	}
+	w.quota, _ = quota.(ReleasableQuota)
+
+	file, dst, err := w.create(1)
+	if err != nil {
+		return nil, err
+	}
+	w.file, w.dst = file, dst
+	w.segments = []OutputSegment{{Number: 1, Start: clock.Now()}}
+	w.done = make(chan struct{})
+	return w, nil
+}
+
+// Create segment 'n'
+func (w *segmentWriter) create(n int) (*os.File, io.Writer, error) {
+	name := segmentPath(w.name, n)
+	file, err := createOutputFile(w.dir, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if w.key == nil {
+		return file, file, nil
+	}
+	// Each segment is encrypted as a file of its own
+	dst, err := encryption.NewWriter(file, w.key, filepath.Base(name))
+	if err != nil {
+		logFileClose(file)
+		return nil, nil, fmt.Errorf("error setting up output encryption: %w", err)
+	}
+	return file, dst, nil
+}
+
+// The segment being written. Caller must hold the lock
+func (w *segmentWriter) current() *OutputSegment {
+	return &w.segments[len(w.segments)-1]
+}
+
+// Whether the current segment is full or its interval is over. Empty
+// segments are kept however long they've been open. Caller must hold the lock
+func (w *segmentWriter) shouldRotate(now time.Time) bool {
+	current := w.current()
+	switch {
+	case current.Size == 0:
+		return false
+	case w.policy.MaxBytes > 0 && current.Size >= w.policy.MaxBytes:
+		return true
+	default:
+		return w.policy.Interval > 0 && now.Truncate(w.policy.Interval).After(current.Start)
+	}
+}
+
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	// Only between writes, so records and lines written
+	// in one go aren't split across segments
+	if now := w.clock.Now(); w.shouldRotate(now) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.dst.Write(p)
+	w.current().Size += int64(n)
+	return n, err
+}
+
+// Move on to the next segment, discarding old ones the window doesn't need.
+// Caller must hold the lock
+func (w *segmentWriter) rotate(now time.Time) error {
+	number := w.current().Number + 1
+	file, dst, err := w.create(number)
+	if err != nil {
+		return fmt.Errorf("error creating output segment: %w", err)
+	}
+	if w.syncFinished {
+		if err := w.file.Sync(); err != nil {
+			slog.Error("Failed to sync output segment", "path", segmentPath(w.path, w.current().Number), "error", err)
+		}
+	}
+	logFileClose(w.file)
+	close(w.done)
+	w.current().End = now
+	w.file, w.dst = file, dst
+	w.segments = append(w.segments, OutputSegment{Number: number, Start: now})
+	w.done = make(chan struct{})
+
+	if w.window == 0 {
+		return nil
+	}
+	// Finished segments left after discarding the oldest
+	var rest int64
+	for _, segment := range w.segments[1:] {
+		rest += segment.Size
+	}
+	for len(w.segments) > 1 && rest >= w.window-w.window/outputWindowSegments {
+		oldest := w.segments[0]
+		// Readers that have it open can still finish it
+		err := os.Remove(segmentPath(w.path, oldest.Number))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Error("Failed to discard old output segment", "path", segmentPath(w.path, oldest.Number), "error", err)
+		} else if err == nil && w.quota != nil {
+			w.quota.Release(oldest.Size)
+		}
+		w.segments = w.segments[1:]
+		rest -= w.segments[0].Size
+	}
+	return nil
+}
+
+// Sync the segment being written. Earlier ones were synced as they were
+// finished, if at all (see syncFinished)
+func (w *segmentWriter) Sync() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close the current segment. Readers stop waiting for more output
+func (w *segmentWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed {
+		return nil
+	}
+	err := w.file.Close()
+	w.closed = true
+	w.current().End = w.clock.Now()
+	close(w.done)
+	return err
+}
+
+// Snapshot of the segments still on disk, oldest first
+func (w *segmentWriter) list() []OutputSegment {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return slices.Clone(w.segments)
+}
+
+// Open segment 'n' for reading, or the oldest one still on disk if it's gone.
+// Also returns the number of the segment opened. Unless 'follow' is set, the
+// segment being written is only read up to where it ends now
+func (w *segmentWriter) openSegment(n int, follow bool) (io.ReadCloser, int, error) {
+	// Segments are only discarded under the lock
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	n = max(n, w.segments[0].Number)
+	reader, err := w.openLocked(n, follow)
+	return reader, n, err
+}
+
+// Open exactly segment 'n' for reading. ErrNoSuchSegment if
+// it was discarded or hasn't been started yet
+func (w *segmentWriter) openExact(n int) (io.ReadCloser, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if n < w.segments[0].Number || n > w.current().Number {
+		return nil, ErrNoSuchSegment
+	}
+	return w.openLocked(n, true)
+}
+
+// Caller must hold the lock, and 'n' must still be on disk
+func (w *segmentWriter) openLocked(n int, follow bool) (io.ReadCloser, error) {
+	done := segmentDone
+	if follow && n == w.current().Number {
+		done = w.done
+	}
+	path := segmentPath(w.path, n)
+	fileStreamer, err := streamer.NewLiveFileStreamer(path, done)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file streamer: %w", err)
+	}
+	if w.key == nil {
+		return fileStreamer, nil
+	}
+	reader, err := encryption.NewReader(fileStreamer, w.key, filepath.Base(path))
+	if err != nil {
+		_ = fileStreamer.Close()
+		return nil, fmt.Errorf("failed to create output decrypter: %w", err)
+	}
+	return reader, nil
+}
+
+// Whether segment 'n' is the last one there will ever be
+func (w *segmentWriter) isLast(n int) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return n >= w.current().Number && w.closed
+}
+
+// Whether segment 'n' is the one being written (or the last one written)
+func (w *segmentWriter) isCurrent(n int) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return n >= w.current().Number
+}
+
+// Reads the segments of a segmentWriter in order, following the current one
+// as it's written. Segments discarded before they're reached are skipped
+type segmentReader struct {
+	writer *segmentWriter
+	// Unset to stop at the end of the current segment instead of following it
+	follow bool
+
+	// Guards everything below. Close may be called from any goroutine
+	lock    sync.Mutex
+	n       int
+	segment io.ReadCloser
+	closed  bool
+}
+
+func newSegmentReader(writer *segmentWriter, follow bool) (*segmentReader, error) {
+	segment, n, err := writer.openSegment(1, follow)
+	if err != nil {
+		return nil, err
+	}
+	return &segmentReader{writer: writer, follow: follow, n: n, segment: segment}, nil
+}
+
+func (r *segmentReader) Read(p []byte) (int, error) {
+	for {
+		r.lock.Lock()
+		segment := r.segment
+		r.lock.Unlock()
+
+		count, err := segment.Read(p)
+		if count > 0 || !errors.Is(err, io.EOF) {
+			return count, err
+		}
+
+		// The segment is done being written. Move on to the next one, if any
+		r.lock.Lock()
+		if r.closed || r.writer.isLast(r.n) || (!r.follow && r.writer.isCurrent(r.n)) {
+			r.lock.Unlock()
+			return 0, io.EOF
+		}
+		closeErr := r.segment.Close()
+		next, n, err := r.writer.openSegment(r.n+1, r.follow)
+		if err == nil {
+			r.segment, r.n = next, n
+		}
+		r.lock.Unlock()
+		if err = errors.Join(closeErr, err); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Pause passes through to the segment being read (see streamer.LiveFileStreamer)
+func (r *segmentReader) Pause() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if p, ok := r.segment.(interface{ Pause() error }); ok {
+		return p.Pause()
+	}
+	return nil
+}
+
+func (r *segmentReader) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.segment.Close()
+}
@@ -1,16 +1,23 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0-devel
+// 	protoc-gen-go v1.36.6
 // 	protoc        v3.14.0
 // source: jobby.proto
 
+// Version 1 of the JobManager API. Frozen apart from additive changes so
+// existing clients keep working. Breaking changes go in jobmanager.v2
+// (proto/jobmanager/v2), which the server also serves
+
 package jobmanagerpb
 
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -20,6 +27,180 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// What a job may do with its token
+type JobTokenScope int32
+
+const (
+	JobTokenScope_JOB_TOKEN_SCOPE_UNSPECIFIED JobTokenScope = 0
+	// StartJob, of children of the job. Same as JobSpec.child_jobs
+	JobTokenScope_JOB_TOKEN_SCOPE_START_CHILD_JOBS JobTokenScope = 1
+	// ReportJobProgress
+	JobTokenScope_JOB_TOKEN_SCOPE_REPORT_PROGRESS JobTokenScope = 2
+	// AnnotateJob
+	JobTokenScope_JOB_TOKEN_SCOPE_ANNOTATE JobTokenScope = 3
+)
+
+// Enum value maps for JobTokenScope.
+var (
+	JobTokenScope_name = map[int32]string{
+		0: "JOB_TOKEN_SCOPE_UNSPECIFIED",
+		1: "JOB_TOKEN_SCOPE_START_CHILD_JOBS",
+		2: "JOB_TOKEN_SCOPE_REPORT_PROGRESS",
+		3: "JOB_TOKEN_SCOPE_ANNOTATE",
+	}
+	JobTokenScope_value = map[string]int32{
+		"JOB_TOKEN_SCOPE_UNSPECIFIED":      0,
+		"JOB_TOKEN_SCOPE_START_CHILD_JOBS": 1,
+		"JOB_TOKEN_SCOPE_REPORT_PROGRESS":  2,
+		"JOB_TOKEN_SCOPE_ANNOTATE":         3,
+	}
+)
+
+func (x JobTokenScope) Enum() *JobTokenScope {
+	p := new(JobTokenScope)
+	*p = x
+	return p
+}
+
+func (x JobTokenScope) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobTokenScope) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[0].Descriptor()
+}
+
+func (JobTokenScope) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[0]
+}
+
+func (x JobTokenScope) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobTokenScope.Descriptor instead.
+func (JobTokenScope) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{0}
+}
+
+// What an attempt's exit code means, for tools that exit non-zero
+// for things that aren't failures (ex: a linter finding nits)
+type Outcome int32
+
+const (
+	// Still running, or killed by a signal
+	Outcome_OUTCOME_UNSPECIFIED Outcome = 0
+	// Not retried
+	Outcome_OUTCOME_SUCCESS Outcome = 1
+	// Succeeded, but something deserves a look. Not retried
+	Outcome_OUTCOME_WARNING Outcome = 2
+	// Failed in a way running it again won't fix. Not retried
+	Outcome_OUTCOME_FAILURE Outcome = 3
+	// Failed, but may succeed if run again. Retried while attempts remain
+	Outcome_OUTCOME_RETRYABLE Outcome = 4
+	// Failed because of something outside the job (ex: a service it needs
+	// was down). Retried while attempts remain
+	Outcome_OUTCOME_INFRASTRUCTURE_FAILURE Outcome = 5
+)
+
+// Enum value maps for Outcome.
+var (
+	Outcome_name = map[int32]string{
+		0: "OUTCOME_UNSPECIFIED",
+		1: "OUTCOME_SUCCESS",
+		2: "OUTCOME_WARNING",
+		3: "OUTCOME_FAILURE",
+		4: "OUTCOME_RETRYABLE",
+		5: "OUTCOME_INFRASTRUCTURE_FAILURE",
+	}
+	Outcome_value = map[string]int32{
+		"OUTCOME_UNSPECIFIED":            0,
+		"OUTCOME_SUCCESS":                1,
+		"OUTCOME_WARNING":                2,
+		"OUTCOME_FAILURE":                3,
+		"OUTCOME_RETRYABLE":              4,
+		"OUTCOME_INFRASTRUCTURE_FAILURE": 5,
+	}
+)
+
+func (x Outcome) Enum() *Outcome {
+	p := new(Outcome)
+	*p = x
+	return p
+}
+
+func (x Outcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Outcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[1].Descriptor()
+}
+
+func (Outcome) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[1]
+}
+
+func (x Outcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Outcome.Descriptor instead.
+func (Outcome) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{1}
+}
+
+type IOClass int32
+
+const (
+	IOClass_IO_CLASS_UNSPECIFIED IOClass = 0
+	// Served in order of priority
+	IOClass_IO_CLASS_BEST_EFFORT IOClass = 1
+	// Only served when nothing else wants the disk
+	IOClass_IO_CLASS_IDLE IOClass = 2
+)
+
+// Enum value maps for IOClass.
+var (
+	IOClass_name = map[int32]string{
+		0: "IO_CLASS_UNSPECIFIED",
+		1: "IO_CLASS_BEST_EFFORT",
+		2: "IO_CLASS_IDLE",
+	}
+	IOClass_value = map[string]int32{
+		"IO_CLASS_UNSPECIFIED": 0,
+		"IO_CLASS_BEST_EFFORT": 1,
+		"IO_CLASS_IDLE":        2,
+	}
+)
+
+func (x IOClass) Enum() *IOClass {
+	p := new(IOClass)
+	*p = x
+	return p
+}
+
+func (x IOClass) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IOClass) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[2].Descriptor()
+}
+
+func (IOClass) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[2]
+}
+
+func (x IOClass) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IOClass.Descriptor instead.
+func (IOClass) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{2}
+}
+
 type Status int32
 
 const (
@@ -59,11 +240,11 @@ func (x Status) String() string {
 }
 
 func (Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_jobby_proto_enumTypes[0].Descriptor()
+	return file_jobby_proto_enumTypes[3].Descriptor()
 }
 
 func (Status) Type() protoreflect.EnumType {
-	return &file_jobby_proto_enumTypes[0]
+	return &file_jobby_proto_enumTypes[3]
 }
 
 func (x Status) Number() protoreflect.EnumNumber {
@@ -72,7 +253,189 @@ func (x Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Status.Descriptor instead.
 func (Status) EnumDescriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{0}
+	return file_jobby_proto_rawDescGZIP(), []int{3}
+}
+
+// Why a job ended up in its final state. Unlike ExitReason, which is about
+// one attempt's process, this is about the job as a whole
+type StateReason int32
+
+const (
+	// The job hasn't finished (including while it's queued)
+	StateReason_STATE_REASON_UNSPECIFIED StateReason = 0
+	// The last attempt succeeded (see JobSpec.exit_code_rules)
+	StateReason_STATE_REASON_COMPLETED StateReason = 1
+	// The last attempt exited unsuccessfully, and it won't be retried
+	StateReason_STATE_REASON_FAILED StateReason = 2
+	// Stopped by its owner, while running or queued
+	StateReason_STATE_REASON_USER_STOPPED StateReason = 3
+	// Killed for exceeding its timeout
+	StateReason_STATE_REASON_TIMEOUT StateReason = 4
+	// Killed by the kernel's OOM killer
+	StateReason_STATE_REASON_OOM StateReason = 5
+	// Stopped to make room for a higher priority job, and not requeued
+	StateReason_STATE_REASON_PREEMPTED StateReason = 6
+	// Stopped, or never run again, because the server shut down
+	StateReason_STATE_REASON_SERVER_SHUTDOWN StateReason = 7
+	// A retry couldn't be started (ex: the command was deleted)
+	StateReason_STATE_REASON_EXEC_FAILED StateReason = 8
+	// Killed for writing more output than its owner's quota allows
+	StateReason_STATE_REASON_QUOTA_EXCEEDED StateReason = 9
+	// Killed by a signal the server didn't send (ex: a crash)
+	StateReason_STATE_REASON_SIGNALED StateReason = 10
+	// An adopted process exited, and the server can't tell how
+	StateReason_STATE_REASON_UNKNOWN StateReason = 11
+	// Stopped by the server because its owner's credentials lapsed
+	// without being renewed (see the server's credential leases)
+	StateReason_STATE_REASON_CREDENTIALS_EXPIRED StateReason = 12
+	// Stopped by the server because its lease wasn't renewed in time
+	// (see RenewJobLease)
+	StateReason_STATE_REASON_LEASE_EXPIRED StateReason = 13
+	// One of the server's pre-start hooks failed before a retry or
+	// requeued attempt. Failing before the first attempt fails StartJob
+	StateReason_STATE_REASON_PRE_HOOK_FAILED StateReason = 14
+)
+
+// Enum value maps for StateReason.
+var (
+	StateReason_name = map[int32]string{
+		0:  "STATE_REASON_UNSPECIFIED",
+		1:  "STATE_REASON_COMPLETED",
+		2:  "STATE_REASON_FAILED",
+		3:  "STATE_REASON_USER_STOPPED",
+		4:  "STATE_REASON_TIMEOUT",
+		5:  "STATE_REASON_OOM",
+		6:  "STATE_REASON_PREEMPTED",
+		7:  "STATE_REASON_SERVER_SHUTDOWN",
+		8:  "STATE_REASON_EXEC_FAILED",
+		9:  "STATE_REASON_QUOTA_EXCEEDED",
+		10: "STATE_REASON_SIGNALED",
+		11: "STATE_REASON_UNKNOWN",
+		12: "STATE_REASON_CREDENTIALS_EXPIRED",
+		13: "STATE_REASON_LEASE_EXPIRED",
+		14: "STATE_REASON_PRE_HOOK_FAILED",
+	}
+	StateReason_value = map[string]int32{
+		"STATE_REASON_UNSPECIFIED":         0,
+		"STATE_REASON_COMPLETED":           1,
+		"STATE_REASON_FAILED":              2,
+		"STATE_REASON_USER_STOPPED":        3,
+		"STATE_REASON_TIMEOUT":             4,
+		"STATE_REASON_OOM":                 5,
+		"STATE_REASON_PREEMPTED":           6,
+		"STATE_REASON_SERVER_SHUTDOWN":     7,
+		"STATE_REASON_EXEC_FAILED":         8,
+		"STATE_REASON_QUOTA_EXCEEDED":      9,
+		"STATE_REASON_SIGNALED":            10,
+		"STATE_REASON_UNKNOWN":             11,
+		"STATE_REASON_CREDENTIALS_EXPIRED": 12,
+		"STATE_REASON_LEASE_EXPIRED":       13,
+		"STATE_REASON_PRE_HOOK_FAILED":     14,
+	}
+)
+
+func (x StateReason) Enum() *StateReason {
+	p := new(StateReason)
+	*p = x
+	return p
+}
+
+func (x StateReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StateReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[4].Descriptor()
+}
+
+func (StateReason) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[4]
+}
+
+func (x StateReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StateReason.Descriptor instead.
+func (StateReason) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{4}
+}
+
+type ExitReason int32
+
+const (
+	ExitReason_EXIT_REASON_UNSPECIFIED ExitReason = 0
+	// Exited on its own. See the exit code
+	ExitReason_EXIT_REASON_EXITED ExitReason = 1
+	// Killed by a signal the server didn't send (ex: a crash)
+	ExitReason_EXIT_REASON_SIGNALED ExitReason = 2
+	// Killed by the kernel's OOM killer for exceeding its memory limit.
+	// Only detected for jobs whose runtime class sets a cgroup memory limit
+	ExitReason_EXIT_REASON_OOM_KILLED ExitReason = 3
+	// Killed for exceeding its timeout
+	ExitReason_EXIT_REASON_TIMED_OUT ExitReason = 4
+	// Killed for writing more output than its owner's quota allows
+	ExitReason_EXIT_REASON_QUOTA_EXCEEDED ExitReason = 5
+	// Stopped by the user
+	ExitReason_EXIT_REASON_STOPPED ExitReason = 6
+	// Stopped to make room for a higher priority job while the server was at capacity
+	ExitReason_EXIT_REASON_PREEMPTED ExitReason = 7
+	// An adopted process (see AdoptProcess) exited. The server isn't its
+	// parent, so it can't tell how
+	ExitReason_EXIT_REASON_UNKNOWN ExitReason = 8
+)
+
+// Enum value maps for ExitReason.
+var (
+	ExitReason_name = map[int32]string{
+		0: "EXIT_REASON_UNSPECIFIED",
+		1: "EXIT_REASON_EXITED",
+		2: "EXIT_REASON_SIGNALED",
+		3: "EXIT_REASON_OOM_KILLED",
+		4: "EXIT_REASON_TIMED_OUT",
+		5: "EXIT_REASON_QUOTA_EXCEEDED",
+		6: "EXIT_REASON_STOPPED",
+		7: "EXIT_REASON_PREEMPTED",
+		8: "EXIT_REASON_UNKNOWN",
+	}
+	ExitReason_value = map[string]int32{
+		"EXIT_REASON_UNSPECIFIED":    0,
+		"EXIT_REASON_EXITED":         1,
+		"EXIT_REASON_SIGNALED":       2,
+		"EXIT_REASON_OOM_KILLED":     3,
+		"EXIT_REASON_TIMED_OUT":      4,
+		"EXIT_REASON_QUOTA_EXCEEDED": 5,
+		"EXIT_REASON_STOPPED":        6,
+		"EXIT_REASON_PREEMPTED":      7,
+		"EXIT_REASON_UNKNOWN":        8,
+	}
+)
+
+func (x ExitReason) Enum() *ExitReason {
+	p := new(ExitReason)
+	*p = x
+	return p
+}
+
+func (x ExitReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExitReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[5].Descriptor()
+}
+
+func (ExitReason) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[5]
+}
+
+func (x ExitReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExitReason.Descriptor instead.
+func (ExitReason) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{5}
 }
 
 type OutputType int32
@@ -108,49 +471,6037 @@ func (x OutputType) String() string {
 }
 
 func (OutputType) Descriptor() protoreflect.EnumDescriptor {
-	return file_jobby_proto_enumTypes[1].Descriptor()
+	return file_jobby_proto_enumTypes[6].Descriptor()
 }
 
 func (OutputType) Type() protoreflect.EnumType {
-	return &file_jobby_proto_enumTypes[1]
+	return &file_jobby_proto_enumTypes[6]
 }
 
 func (x OutputType) Number() protoreflect.EnumNumber {
 	return protoreflect.EnumNumber(x)
 }
 
-// Deprecated: Use OutputType.Descriptor instead.
-func (OutputType) EnumDescriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use OutputType.Descriptor instead.
+func (OutputType) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{6}
+}
+
+type StreamMode int32
+
+const (
+	// Same as RAW
+	StreamMode_STREAM_MODE_UNSPECIFIED StreamMode = 0
+	// Send output as soon as it's read, even partial lines
+	StreamMode_STREAM_MODE_RAW StreamMode = 1
+	// Only send complete lines (see line_max_hold)
+	StreamMode_STREAM_MODE_LINES StreamMode = 2
+)
+
+// Enum value maps for StreamMode.
+var (
+	StreamMode_name = map[int32]string{
+		0: "STREAM_MODE_UNSPECIFIED",
+		1: "STREAM_MODE_RAW",
+		2: "STREAM_MODE_LINES",
+	}
+	StreamMode_value = map[string]int32{
+		"STREAM_MODE_UNSPECIFIED": 0,
+		"STREAM_MODE_RAW":         1,
+		"STREAM_MODE_LINES":       2,
+	}
+)
+
+func (x StreamMode) Enum() *StreamMode {
+	p := new(StreamMode)
+	*p = x
+	return p
+}
+
+func (x StreamMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StreamMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[7].Descriptor()
+}
+
+func (StreamMode) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[7]
+}
+
+func (x StreamMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StreamMode.Descriptor instead.
+func (StreamMode) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{7}
+}
+
+type JobEventType int32
+
+const (
+	JobEventType_JOB_EVENT_TYPE_UNSPECIFIED JobEventType = 0
+	// The job was submitted
+	JobEventType_JOB_EVENT_TYPE_CREATED JobEventType = 1
+	// An attempt's process started
+	JobEventType_JOB_EVENT_TYPE_STARTED JobEventType = 2
+	// The server signaled an attempt's process. The detail says which signal and why
+	JobEventType_JOB_EVENT_TYPE_SIGNALED JobEventType = 3
+	// An attempt exited unsuccessfully. It may be retried
+	JobEventType_JOB_EVENT_TYPE_ATTEMPT_FAILED JobEventType = 4
+	// An attempt exited successfully, or was stopped
+	JobEventType_JOB_EVENT_TYPE_EXITED JobEventType = 5
+	// A preempted job is waiting to run again
+	JobEventType_JOB_EVENT_TYPE_REQUEUED JobEventType = 6
+	// The job and its output were deleted after its retention
+	// (or the grace period of a deletion) expired
+	JobEventType_JOB_EVENT_TYPE_GARBAGE_COLLECTED JobEventType = 7
+	// An attempt reported progress past another 10%. The detail is the report
+	JobEventType_JOB_EVENT_TYPE_PROGRESS JobEventType = 8
+	// The owner deleted the job. The detail says until when it can be
+	// restored, or "purged" if it was deleted with force
+	JobEventType_JOB_EVENT_TYPE_DELETED JobEventType = 9
+	// The owner restored the job after deleting it
+	JobEventType_JOB_EVENT_TYPE_RESTORED JobEventType = 10
+	// A StartJob with cache_ttl reused the job's results instead of
+	// running an identical job. The actor is whoever started it
+	JobEventType_JOB_EVENT_TYPE_CACHE_HIT JobEventType = 11
+	// A hook the server runs before an attempt starts or once the job
+	// finishes succeeded. The detail names the hook
+	JobEventType_JOB_EVENT_TYPE_HOOK_SUCCEEDED JobEventType = 12
+	// A hook failed or timed out. The detail names the hook and says
+	// what went wrong
+	JobEventType_JOB_EVENT_TYPE_HOOK_FAILED JobEventType = 13
+)
+
+// Enum value maps for JobEventType.
+var (
+	JobEventType_name = map[int32]string{
+		0:  "JOB_EVENT_TYPE_UNSPECIFIED",
+		1:  "JOB_EVENT_TYPE_CREATED",
+		2:  "JOB_EVENT_TYPE_STARTED",
+		3:  "JOB_EVENT_TYPE_SIGNALED",
+		4:  "JOB_EVENT_TYPE_ATTEMPT_FAILED",
+		5:  "JOB_EVENT_TYPE_EXITED",
+		6:  "JOB_EVENT_TYPE_REQUEUED",
+		7:  "JOB_EVENT_TYPE_GARBAGE_COLLECTED",
+		8:  "JOB_EVENT_TYPE_PROGRESS",
+		9:  "JOB_EVENT_TYPE_DELETED",
+		10: "JOB_EVENT_TYPE_RESTORED",
+		11: "JOB_EVENT_TYPE_CACHE_HIT",
+		12: "JOB_EVENT_TYPE_HOOK_SUCCEEDED",
+		13: "JOB_EVENT_TYPE_HOOK_FAILED",
+	}
+	JobEventType_value = map[string]int32{
+		"JOB_EVENT_TYPE_UNSPECIFIED":       0,
+		"JOB_EVENT_TYPE_CREATED":           1,
+		"JOB_EVENT_TYPE_STARTED":           2,
+		"JOB_EVENT_TYPE_SIGNALED":          3,
+		"JOB_EVENT_TYPE_ATTEMPT_FAILED":    4,
+		"JOB_EVENT_TYPE_EXITED":            5,
+		"JOB_EVENT_TYPE_REQUEUED":          6,
+		"JOB_EVENT_TYPE_GARBAGE_COLLECTED": 7,
+		"JOB_EVENT_TYPE_PROGRESS":          8,
+		"JOB_EVENT_TYPE_DELETED":           9,
+		"JOB_EVENT_TYPE_RESTORED":          10,
+		"JOB_EVENT_TYPE_CACHE_HIT":         11,
+		"JOB_EVENT_TYPE_HOOK_SUCCEEDED":    12,
+		"JOB_EVENT_TYPE_HOOK_FAILED":       13,
+	}
+)
+
+func (x JobEventType) Enum() *JobEventType {
+	p := new(JobEventType)
+	*p = x
+	return p
+}
+
+func (x JobEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[8].Descriptor()
+}
+
+func (JobEventType) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[8]
+}
+
+func (x JobEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobEventType.Descriptor instead.
+func (JobEventType) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{8}
+}
+
+type LogLevel int32
+
+const (
+	LogLevel_LOG_LEVEL_UNSPECIFIED LogLevel = 0
+	LogLevel_LOG_LEVEL_DEBUG       LogLevel = 1
+	LogLevel_LOG_LEVEL_INFO        LogLevel = 2
+	LogLevel_LOG_LEVEL_WARN        LogLevel = 3
+	LogLevel_LOG_LEVEL_ERROR       LogLevel = 4
+)
+
+// Enum value maps for LogLevel.
+var (
+	LogLevel_name = map[int32]string{
+		0: "LOG_LEVEL_UNSPECIFIED",
+		1: "LOG_LEVEL_DEBUG",
+		2: "LOG_LEVEL_INFO",
+		3: "LOG_LEVEL_WARN",
+		4: "LOG_LEVEL_ERROR",
+	}
+	LogLevel_value = map[string]int32{
+		"LOG_LEVEL_UNSPECIFIED": 0,
+		"LOG_LEVEL_DEBUG":       1,
+		"LOG_LEVEL_INFO":        2,
+		"LOG_LEVEL_WARN":        3,
+		"LOG_LEVEL_ERROR":       4,
+	}
+)
+
+func (x LogLevel) Enum() *LogLevel {
+	p := new(LogLevel)
+	*p = x
+	return p
+}
+
+func (x LogLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[9].Descriptor()
+}
+
+func (LogLevel) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[9]
+}
+
+func (x LogLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogLevel.Descriptor instead.
+func (LogLevel) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{9}
+}
+
+type MissedRunPolicy int32
+
+const (
+	MissedRunPolicy_MISSED_RUN_POLICY_UNSPECIFIED MissedRunPolicy = 0
+	// Start none of the missed runs. The schedule picks up with its next one
+	MissedRunPolicy_MISSED_RUN_POLICY_SKIP MissedRunPolicy = 1
+	// Start one run in place of all the missed ones
+	MissedRunPolicy_MISSED_RUN_POLICY_RUN_ONCE MissedRunPolicy = 2
+	// Start every missed run (up to the last 100), one after another. Runs
+	// that come due while the one before is still going wait for it
+	// rather than being skipped
+	MissedRunPolicy_MISSED_RUN_POLICY_RUN_ALL MissedRunPolicy = 3
+)
+
+// Enum value maps for MissedRunPolicy.
+var (
+	MissedRunPolicy_name = map[int32]string{
+		0: "MISSED_RUN_POLICY_UNSPECIFIED",
+		1: "MISSED_RUN_POLICY_SKIP",
+		2: "MISSED_RUN_POLICY_RUN_ONCE",
+		3: "MISSED_RUN_POLICY_RUN_ALL",
+	}
+	MissedRunPolicy_value = map[string]int32{
+		"MISSED_RUN_POLICY_UNSPECIFIED": 0,
+		"MISSED_RUN_POLICY_SKIP":        1,
+		"MISSED_RUN_POLICY_RUN_ONCE":    2,
+		"MISSED_RUN_POLICY_RUN_ALL":     3,
+	}
+)
+
+func (x MissedRunPolicy) Enum() *MissedRunPolicy {
+	p := new(MissedRunPolicy)
+	*p = x
+	return p
+}
+
+func (x MissedRunPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MissedRunPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobby_proto_enumTypes[10].Descriptor()
+}
+
+func (MissedRunPolicy) Type() protoreflect.EnumType {
+	return &file_jobby_proto_enumTypes[10]
+}
+
+func (x MissedRunPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MissedRunPolicy.Descriptor instead.
+func (MissedRunPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{10}
+}
+
+// Everything needed to run a job. Shared by requests that start jobs
+// and responses that describe them, so new job settings are added here
+// rather than to each message
+type JobSpec struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Command string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Args    []string               `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// Added to the server's environment. Overrides variables of the same name
+	Env map[string]string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Total number of times the command may run. A new attempt is started
+	// whenever the previous one exits with a non-zero code.
+	// 0 and 1 both mean "no retries"
+	MaxAttempts uint32 `protobuf:"varint,4,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	// How long to keep the job around once it finishes.
+	// The server's default applies when unset
+	Retention *RetentionPolicy `protobuf:"bytes,5,opt,name=retention,proto3" json:"retention,omitempty"`
+	// Name of a server defined preset of resource limits, isolation and
+	// timeouts to run the job with. Empty selects the server's default class
+	RuntimeClass string `protobuf:"bytes,6,opt,name=runtime_class,json=runtimeClass,proto3" json:"runtime_class,omitempty"`
+	// Free form key/value pairs for the caller's own bookkeeping
+	Labels map[string]string `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Kill each attempt after it has run this long. Can only
+	// shorten the runtime class's timeout. Unset means no timeout
+	Timeout *durationpb.Duration `protobuf:"bytes,8,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// Name of a server defined policy for the network traffic the job may send.
+	// Requires a runtime class with network isolation.
+	// Empty uses the runtime class's policy
+	EgressPolicy string `protobuf:"bytes,9,opt,name=egress_policy,json=egressPolicy,proto3" json:"egress_policy,omitempty"`
+	// Indices of the GPUs (see GetServerInfo) the job may use. Requires
+	// a runtime class with a cgroup. GPUs aren't reserved, so jobs
+	// granted the same GPU share it
+	Gpus []uint32 `protobuf:"varint,10,rep,packed,name=gpus,proto3" json:"gpus,omitempty"`
+	// Priority and CPU affinity of the job's process. Unset runs it like the server
+	Scheduling *Scheduling `protobuf:"bytes,11,opt,name=scheduling,proto3" json:"scheduling,omitempty"`
+	// When the server is at capacity, a job may preempt (gracefully stop)
+	// running jobs of lower priority to make room. Higher runs first
+	Priority int32 `protobuf:"varint,12,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Run the job again once there's room if it's ever preempted.
+	// Otherwise preemption ends the job
+	RequeueOnPreemption bool `protobuf:"varint,13,opt,name=requeue_on_preemption,json=requeueOnPreemption,proto3" json:"requeue_on_preemption,omitempty"`
+	// Keep only about the last this many bytes of each output stream on
+	// disk, discarding older output as the job writes more. For long running
+	// jobs whose early output nobody needs. Readers start with the oldest
+	// output still kept. 0 keeps everything
+	OutputWindowBytes uint64 `protobuf:"varint,14,opt,name=output_window_bytes,json=outputWindowBytes,proto3" json:"output_window_bytes,omitempty"`
+	// Split each output stream into segments that can be listed and fetched
+	// one by one (see ListOutputSegments). Unset keeps a single file,
+	// unless output_window_bytes is set
+	OutputSegments *SegmentPolicy `protobuf:"bytes,15,opt,name=output_segments,json=outputSegments,proto3" json:"output_segments,omitempty"`
+	// Look for progress reports (see Progress) in the job's output. Otherwise
+	// the job's progress is never known
+	TrackProgress bool `protobuf:"varint,16,opt,name=track_progress,json=trackProgress,proto3" json:"track_progress,omitempty"`
+	// Let anyone read the job's status and output, including callers without
+	// a client certificate. Only on servers that allow public jobs. Everything
+	// else (ex: stopping the job) is still limited to its owner
+	Public bool `protobuf:"varint,17,opt,name=public,proto3" json:"public,omitempty"`
+	// MIME type of the job's stdout, for clients to render it by (ex: jobcli
+	// pretty-prints JSON). One of text/plain, application/json (a document,
+	// or a stream of them) or application/junit+xml. Parameters (ex:
+	// "; charset=utf-8") are kept but ignored. Empty if undeclared
+	OutputContentType string `protobuf:"bytes,18,opt,name=output_content_type,json=outputContentType,proto3" json:"output_content_type,omitempty"`
+	// How the job's exit codes are classified (see Outcome). The first rule
+	// with a code wins. Codes no rule has are SUCCESS if 0 and RETRYABLE
+	// otherwise, so by default any non-zero exit is retried
+	ExitCodeRules []*ExitCodeRule `protobuf:"bytes,19,rep,name=exit_code_rules,json=exitCodeRules,proto3" json:"exit_code_rules,omitempty"`
+	// Command line to run with /bin/sh -c (ex: "make test | tee log"), for
+	// pipelines and redirects. Kept as given, so it's clear what ran.
+	// Instead of command and args, which must be empty
+	Shell string `protobuf:"bytes,20,opt,name=shell,proto3" json:"shell,omitempty"`
+	// How long the job usually runs. When the server shuts down it waits
+	// for jobs expected to finish soon and stops the rest. Unset uses the
+	// median runtime of earlier runs of the same job (see GetJobStats)
+	ExpectedRuntime *durationpb.Duration `protobuf:"bytes,21,opt,name=expected_runtime,json=expectedRuntime,proto3" json:"expected_runtime,omitempty"`
+	// Record what the job launched with (see LaunchSnapshot), so what ran can
+	// be told long after. Hashing the binary makes starting the job slower.
+	// Ignored by AdoptProcess
+	CaptureEnvironment bool `protobuf:"varint,22,opt,name=capture_environment,json=captureEnvironment,proto3" json:"capture_environment,omitempty"`
+	// Prefix each line of output with when the server captured it, so
+	// output fetched later still shows when it was written. Prefixes are
+	// RFC 3339 UTC timestamps with nanoseconds and a trailing space
+	// (ex: "2025-06-01T12:00:00.000000000Z "). See strip_timestamps
+	TimestampOutput bool `protobuf:"varint,23,opt,name=timestamp_output,json=timestampOutput,proto3" json:"timestamp_output,omitempty"`
+	// Give the job a stdin to send data to with WriteJobStdin. Without it
+	// the job reads nothing. The data can't be sent again, so such jobs
+	// can't have retries or requeue_on_preemption
+	Stdin bool `protobuf:"varint,24,opt,name=stdin,proto3" json:"stdin,omitempty"`
+	// Stop the job unless its lease is renewed (see RenewJobLease) at
+	// least this often, counting from when it's started. For callers whose
+	// jobs should end when they go away. Unset jobs run regardless
+	Lease *durationpb.Duration `protobuf:"bytes,25,opt,name=lease,proto3" json:"lease,omitempty"`
+	// Give the job a token (in $JOBBY_JOB_TOKEN) to start child jobs with
+	// (see JobRecord.parent_id). The token may only start jobs, which run
+	// as the owner, and stops working once the job finishes. The server
+	// must have job tokens on
+	ChildJobs bool `protobuf:"varint,26,opt,name=child_jobs,json=childJobs,proto3" json:"child_jobs,omitempty"`
+	// What else the job's token may do, besides starting child jobs if
+	// child_jobs is set. Any scope gives the job a token. The token only
+	// acts on the job itself, whose id is in $JOBBY_JOB_ID
+	TokenScopes []JobTokenScope `protobuf:"varint,27,rep,packed,name=token_scopes,json=tokenScopes,proto3,enum=jobby.JobTokenScope" json:"token_scopes,omitempty"`
+	// Gives the job a directory of its own to write to, in $JOBBY_SCRATCH.
+	// Unset gives it none
+	Scratch *Scratch `protobuf:"bytes,28,opt,name=scratch,proto3" json:"scratch,omitempty"`
+	// Run one of the server's templates (see PreviewTemplate) instead of a
+	// command and args of the caller's. Not with command, args or shell.
+	// Started jobs have the rendered command and args instead, and are
+	// labeled template=<name>
+	Template *TemplateRef `protobuf:"bytes,29,opt,name=template,proto3" json:"template,omitempty"`
+	// Only one job holding a given mutex (ex: "db-migration") runs at a
+	// time, whoever owns it, so commands that mustn't overlap never do.
+	// Jobs hold their mutex from when they start until their last attempt
+	// finishes. Empty for none
+	Mutex string `protobuf:"bytes,30,opt,name=mutex,proto3" json:"mutex,omitempty"`
+	// When another job holds the mutex, wait in StartJob for it to be
+	// released (for as long as the call's deadline allows) instead of
+	// failing with ABORTED. Waiting jobs get it in the order they asked.
+	// Ignored by schedules, whose runs are skipped while the mutex is
+	// held (or retried later, for RUN_ALL)
+	WaitForMutex  bool `protobuf:"varint,31,opt,name=wait_for_mutex,json=waitForMutex,proto3" json:"wait_for_mutex,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobSpec) Reset() {
+	*x = JobSpec{}
+	mi := &file_jobby_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobSpec) ProtoMessage() {}
+
+func (x *JobSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobSpec.ProtoReflect.Descriptor instead.
+func (*JobSpec) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *JobSpec) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *JobSpec) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *JobSpec) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *JobSpec) GetMaxAttempts() uint32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *JobSpec) GetRetention() *RetentionPolicy {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+func (x *JobSpec) GetRuntimeClass() string {
+	if x != nil {
+		return x.RuntimeClass
+	}
+	return ""
+}
+
+func (x *JobSpec) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *JobSpec) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *JobSpec) GetEgressPolicy() string {
+	if x != nil {
+		return x.EgressPolicy
+	}
+	return ""
+}
+
+func (x *JobSpec) GetGpus() []uint32 {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+func (x *JobSpec) GetScheduling() *Scheduling {
+	if x != nil {
+		return x.Scheduling
+	}
+	return nil
+}
+
+func (x *JobSpec) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *JobSpec) GetRequeueOnPreemption() bool {
+	if x != nil {
+		return x.RequeueOnPreemption
+	}
+	return false
+}
+
+func (x *JobSpec) GetOutputWindowBytes() uint64 {
+	if x != nil {
+		return x.OutputWindowBytes
+	}
+	return 0
+}
+
+func (x *JobSpec) GetOutputSegments() *SegmentPolicy {
+	if x != nil {
+		return x.OutputSegments
+	}
+	return nil
+}
+
+func (x *JobSpec) GetTrackProgress() bool {
+	if x != nil {
+		return x.TrackProgress
+	}
+	return false
+}
+
+func (x *JobSpec) GetPublic() bool {
+	if x != nil {
+		return x.Public
+	}
+	return false
+}
+
+func (x *JobSpec) GetOutputContentType() string {
+	if x != nil {
+		return x.OutputContentType
+	}
+	return ""
+}
+
+func (x *JobSpec) GetExitCodeRules() []*ExitCodeRule {
+	if x != nil {
+		return x.ExitCodeRules
+	}
+	return nil
+}
+
+func (x *JobSpec) GetShell() string {
+	if x != nil {
+		return x.Shell
+	}
+	return ""
+}
+
+func (x *JobSpec) GetExpectedRuntime() *durationpb.Duration {
+	if x != nil {
+		return x.ExpectedRuntime
+	}
+	return nil
+}
+
+func (x *JobSpec) GetCaptureEnvironment() bool {
+	if x != nil {
+		return x.CaptureEnvironment
+	}
+	return false
+}
+
+func (x *JobSpec) GetTimestampOutput() bool {
+	if x != nil {
+		return x.TimestampOutput
+	}
+	return false
+}
+
+func (x *JobSpec) GetStdin() bool {
+	if x != nil {
+		return x.Stdin
+	}
+	return false
+}
+
+func (x *JobSpec) GetLease() *durationpb.Duration {
+	if x != nil {
+		return x.Lease
+	}
+	return nil
+}
+
+func (x *JobSpec) GetChildJobs() bool {
+	if x != nil {
+		return x.ChildJobs
+	}
+	return false
+}
+
+func (x *JobSpec) GetTokenScopes() []JobTokenScope {
+	if x != nil {
+		return x.TokenScopes
+	}
+	return nil
+}
+
+func (x *JobSpec) GetScratch() *Scratch {
+	if x != nil {
+		return x.Scratch
+	}
+	return nil
+}
+
+func (x *JobSpec) GetTemplate() *TemplateRef {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+func (x *JobSpec) GetMutex() string {
+	if x != nil {
+		return x.Mutex
+	}
+	return ""
+}
+
+func (x *JobSpec) GetWaitForMutex() bool {
+	if x != nil {
+		return x.WaitForMutex
+	}
+	return false
+}
+
+// Names a template and the parameter values to fill it in with
+type TemplateRef struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Parameters that aren't given take their defaults
+	Params        map[string]string `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TemplateRef) Reset() {
+	*x = TemplateRef{}
+	mi := &file_jobby_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemplateRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemplateRef) ProtoMessage() {}
+
+func (x *TemplateRef) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemplateRef.ProtoReflect.Descriptor instead.
+func (*TemplateRef) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TemplateRef) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TemplateRef) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+// A directory created for the job before its first attempt, shared by its
+// attempts and removed along with its output
+type Scratch struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Mount a tmpfs of this size on the directory, so its files are kept in
+	// memory and can't fill the host's disk. The server caps the size.
+	// 0 uses the disk the job's output is on
+	TmpfsBytes    uint64 `protobuf:"varint,1,opt,name=tmpfs_bytes,json=tmpfsBytes,proto3" json:"tmpfs_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Scratch) Reset() {
+	*x = Scratch{}
+	mi := &file_jobby_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Scratch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Scratch) ProtoMessage() {}
+
+func (x *Scratch) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Scratch.ProtoReflect.Descriptor instead.
+func (*Scratch) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Scratch) GetTmpfsBytes() uint64 {
+	if x != nil {
+		return x.TmpfsBytes
+	}
+	return 0
+}
+
+// How the kernel schedules a job against the rest of the host. Jobs may
+// lower their priority, but never raise it above the server's
+type Scheduling struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 (normal) to 19 (lowest priority). Unset keeps the server's
+	Nice *int32 `protobuf:"varint,1,opt,name=nice,proto3,oneof" json:"nice,omitempty"`
+	// Unspecified keeps the server's
+	IoClass IOClass `protobuf:"varint,2,opt,name=io_class,json=ioClass,proto3,enum=jobby.IOClass" json:"io_class,omitempty"`
+	// Priority within the best effort class, 0 (highest) to 7
+	IoPriority uint32 `protobuf:"varint,3,opt,name=io_priority,json=ioPriority,proto3" json:"io_priority,omitempty"`
+	// CPUs the job may run on. Empty allows any the server may use
+	Cpus          []uint32 `protobuf:"varint,4,rep,packed,name=cpus,proto3" json:"cpus,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Scheduling) Reset() {
+	*x = Scheduling{}
+	mi := &file_jobby_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Scheduling) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Scheduling) ProtoMessage() {}
+
+func (x *Scheduling) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Scheduling.ProtoReflect.Descriptor instead.
+func (*Scheduling) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Scheduling) GetNice() int32 {
+	if x != nil && x.Nice != nil {
+		return *x.Nice
+	}
+	return 0
+}
+
+func (x *Scheduling) GetIoClass() IOClass {
+	if x != nil {
+		return x.IoClass
+	}
+	return IOClass_IO_CLASS_UNSPECIFIED
+}
+
+func (x *Scheduling) GetIoPriority() uint32 {
+	if x != nil {
+		return x.IoPriority
+	}
+	return 0
+}
+
+func (x *Scheduling) GetCpus() []uint32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return nil
+}
+
+// When to start a new output segment. Whichever limit is reached first applies
+type SegmentPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Start a new segment once the current one holds this many bytes.
+	// 0 for no limit
+	MaxBytes uint64 `protobuf:"varint,1,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	// Start a new segment at every multiple of this on the wall clock
+	// (ex: every 5 minutes starts segments at 14:00, 14:05, ...).
+	// Unset for no limit
+	Interval      *durationpb.Duration `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SegmentPolicy) Reset() {
+	*x = SegmentPolicy{}
+	mi := &file_jobby_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SegmentPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentPolicy) ProtoMessage() {}
+
+func (x *SegmentPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentPolicy.ProtoReflect.Descriptor instead.
+func (*SegmentPolicy) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SegmentPolicy) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *SegmentPolicy) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+type ExitCodeRule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Exit codes (0-255) the rule applies to
+	Codes         []int32 `protobuf:"varint,1,rep,packed,name=codes,proto3" json:"codes,omitempty"`
+	Outcome       Outcome `protobuf:"varint,2,opt,name=outcome,proto3,enum=jobby.Outcome" json:"outcome,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExitCodeRule) Reset() {
+	*x = ExitCodeRule{}
+	mi := &file_jobby_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExitCodeRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExitCodeRule) ProtoMessage() {}
+
+func (x *ExitCodeRule) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExitCodeRule.ProtoReflect.Descriptor instead.
+func (*ExitCodeRule) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExitCodeRule) GetCodes() []int32 {
+	if x != nil {
+		return x.Codes
+	}
+	return nil
+}
+
+func (x *ExitCodeRule) GetOutcome() Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return Outcome_OUTCOME_UNSPECIFIED
+}
+
+type StartJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Use spec.command and friends instead. Ignored when spec is set
+	//
+	// Deprecated: Marked as deprecated in jobby.proto.
+	Command string `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	// Deprecated: Marked as deprecated in jobby.proto.
+	Args []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// Deprecated: Marked as deprecated in jobby.proto.
+	MaxAttempts uint32 `protobuf:"varint,3,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	// Deprecated: Marked as deprecated in jobby.proto.
+	Retention *RetentionPolicy `protobuf:"bytes,4,opt,name=retention,proto3" json:"retention,omitempty"`
+	// Deprecated: Marked as deprecated in jobby.proto.
+	RuntimeClass string   `protobuf:"bytes,5,opt,name=runtime_class,json=runtimeClass,proto3" json:"runtime_class,omitempty"`
+	Spec         *JobSpec `protobuf:"bytes,6,opt,name=spec,proto3" json:"spec,omitempty"`
+	// Start the job even if an identical one (same command, args and env)
+	// of the caller's is still running. Such requests fail with
+	// ALREADY_EXISTS otherwise
+	Force bool `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`
+	// Groups the job with others the caller starts in the same session,
+	// so they can be listed or stopped together (see EndSession). Any id
+	// the caller picks (ex: a CI run's id). Empty for none
+	SessionId string `protobuf:"bytes,8,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Reuse the results of an identical job (same command, args and env)
+	// of the caller's that succeeded no longer than this ago, instead of
+	// running the command again. Meant for deterministic commands. Unset
+	// always starts a new job
+	CacheTtl      *durationpb.Duration `protobuf:"bytes,9,opt,name=cache_ttl,json=cacheTtl,proto3" json:"cache_ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartJobRequest) Reset() {
+	*x = StartJobRequest{}
+	mi := &file_jobby_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartJobRequest) ProtoMessage() {}
+
+func (x *StartJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartJobRequest.ProtoReflect.Descriptor instead.
+func (*StartJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{6}
+}
+
+// Deprecated: Marked as deprecated in jobby.proto.
+func (x *StartJobRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in jobby.proto.
+func (x *StartJobRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in jobby.proto.
+func (x *StartJobRequest) GetMaxAttempts() uint32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in jobby.proto.
+func (x *StartJobRequest) GetRetention() *RetentionPolicy {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in jobby.proto.
+func (x *StartJobRequest) GetRuntimeClass() string {
+	if x != nil {
+		return x.RuntimeClass
+	}
+	return ""
+}
+
+func (x *StartJobRequest) GetSpec() *JobSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *StartJobRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *StartJobRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StartJobRequest) GetCacheTtl() *durationpb.Duration {
+	if x != nil {
+		return x.CacheTtl
+	}
+	return nil
+}
+
+type RetentionPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Policy:
+	//
+	//	*RetentionPolicy_Ttl
+	//	*RetentionPolicy_KeepForever
+	Policy        isRetentionPolicy_Policy `protobuf_oneof:"policy"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetentionPolicy) Reset() {
+	*x = RetentionPolicy{}
+	mi := &file_jobby_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetentionPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetentionPolicy) ProtoMessage() {}
+
+func (x *RetentionPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetentionPolicy.ProtoReflect.Descriptor instead.
+func (*RetentionPolicy) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RetentionPolicy) GetPolicy() isRetentionPolicy_Policy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+func (x *RetentionPolicy) GetTtl() *durationpb.Duration {
+	if x != nil {
+		if x, ok := x.Policy.(*RetentionPolicy_Ttl); ok {
+			return x.Ttl
+		}
+	}
+	return nil
+}
+
+func (x *RetentionPolicy) GetKeepForever() bool {
+	if x != nil {
+		if x, ok := x.Policy.(*RetentionPolicy_KeepForever); ok {
+			return x.KeepForever
+		}
+	}
+	return false
+}
+
+type isRetentionPolicy_Policy interface {
+	isRetentionPolicy_Policy()
+}
+
+type RetentionPolicy_Ttl struct {
+	// Output and job record are deleted this long after the job finishes
+	Ttl *durationpb.Duration `protobuf:"bytes,1,opt,name=ttl,proto3,oneof"`
+}
+
+type RetentionPolicy_KeepForever struct {
+	// Never delete. Only allowed if the server permits it
+	KeepForever bool `protobuf:"varint,2,opt,name=keep_forever,json=keepForever,proto3,oneof"`
+}
+
+func (*RetentionPolicy_Ttl) isRetentionPolicy_Policy() {}
+
+func (*RetentionPolicy_KeepForever) isRetentionPolicy_Policy() {}
+
+type StartJobResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of job_id
+	Id string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// Problems with the request that didn't stop the job from starting
+	// (ex: a forced duplicate of a running job)
+	Warnings []string `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Nothing was started. The job named is an earlier one whose results
+	// are being reused (see cache_ttl)
+	Cached        bool `protobuf:"varint,4,opt,name=cached,proto3" json:"cached,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartJobResponse) Reset() {
+	*x = StartJobResponse{}
+	mi := &file_jobby_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartJobResponse) ProtoMessage() {}
+
+func (x *StartJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartJobResponse.ProtoReflect.Descriptor instead.
+func (*StartJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StartJobResponse) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *StartJobResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StartJobResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *StartJobResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type StopJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id (ex: 9b2f2c4e-7c1e-4c52-a1a4-3f0e8f1d6b27).
+	// May be sent instead of job_id. If both are set they must match
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopJobRequest) Reset() {
+	*x = StopJobRequest{}
+	mi := &file_jobby_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopJobRequest) ProtoMessage() {}
+
+func (x *StopJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopJobRequest.ProtoReflect.Descriptor instead.
+func (*StopJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StopJobRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *StopJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type StopJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopJobResponse) Reset() {
+	*x = StopJobResponse{}
+	mi := &file_jobby_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopJobResponse) ProtoMessage() {}
+
+func (x *StopJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopJobResponse.ProtoReflect.Descriptor instead.
+func (*StopJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{10}
+}
+
+type GetStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id (ex: 9b2f2c4e-7c1e-4c52-a1a4-3f0e8f1d6b27).
+	// May be sent instead of job_id. If both are set they must match
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_jobby_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetStatusRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *GetStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type WaitJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitJobRequest) Reset() {
+	*x = WaitJobRequest{}
+	mi := &file_jobby_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitJobRequest) ProtoMessage() {}
+
+func (x *WaitJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitJobRequest.ProtoReflect.Descriptor instead.
+func (*WaitJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WaitJobRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *WaitJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CurrentStatus Status                 `protobuf:"varint,1,opt,name=current_status,json=currentStatus,proto3,enum=jobby.Status" json:"current_status,omitempty"`
+	// available when status is "COMPLETE"
+	ExitCode *int32 `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	// How long the latest attempt ran, or has been running so far.
+	// Measured with a monotonic clock so it is unaffected by
+	// adjustments to the server's wall clock
+	Duration *durationpb.Duration `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	// The job's output went over its owner's quota. Depending on the
+	// server's configuration it was either killed or its output truncated
+	QuotaExceeded bool `protobuf:"varint,4,opt,name=quota_exceeded,json=quotaExceeded,proto3" json:"quota_exceeded,omitempty"`
+	// The latest attempt was killed for exceeding its runtime class's timeout
+	TimedOut bool `protobuf:"varint,5,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	// Why the latest attempt exited. Unspecified while it's running
+	ExitReason ExitReason `protobuf:"varint,6,opt,name=exit_reason,json=exitReason,proto3,enum=jobby.ExitReason" json:"exit_reason,omitempty"`
+	// Name of the signal that killed the latest attempt (ex: SIGKILL). Empty if it
+	// exited on its own
+	Signal string `protobuf:"bytes,7,opt,name=signal,proto3" json:"signal,omitempty"`
+	// The job was preempted and is waiting for room to run again
+	Queued bool `protobuf:"varint,8,opt,name=queued,proto3" json:"queued,omitempty"`
+	// The latest attempt's latest progress report. Unset if it hasn't made one
+	Progress *Progress `protobuf:"bytes,9,opt,name=progress,proto3" json:"progress,omitempty"`
+	// Host PID of the latest attempt's main process. Still set after it
+	// exits, by which time the system may have reused it
+	Pid int32 `protobuf:"varint,10,opt,name=pid,proto3" json:"pid,omitempty"`
+	// Snapshot of the latest attempt's running processes, main process first.
+	// Listed from the job's cgroup if it has one (which catches daemons that
+	// left the process tree), otherwise from the main process's descendants.
+	// PIDs are the host's, even for jobs with pid isolation
+	Processes []*JobProcess `protobuf:"bytes,11,rep,name=processes,proto3" json:"processes,omitempty"`
+	// The job's output_content_type (see JobSpec)
+	OutputContentType string `protobuf:"bytes,12,opt,name=output_content_type,json=outputContentType,proto3" json:"output_content_type,omitempty"`
+	// How the latest attempt's exit code was classified (see JobSpec.exit_code_rules)
+	Outcome Outcome `protobuf:"varint,13,opt,name=outcome,proto3,enum=jobby.Outcome" json:"outcome,omitempty"`
+	// Why the job ended up in its final state, for clients to act on. Unset
+	// until the job is finished
+	StateReason StateReason `protobuf:"varint,14,opt,name=state_reason,json=stateReason,proto3,enum=jobby.StateReason" json:"state_reason,omitempty"`
+	// Human readable specifics (ex: "stopped by alice")
+	StateMessage  string `protobuf:"bytes,15,opt,name=state_message,json=stateMessage,proto3" json:"state_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_jobby_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetStatusResponse) GetCurrentStatus() Status {
+	if x != nil {
+		return x.CurrentStatus
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *GetStatusResponse) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *GetStatusResponse) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *GetStatusResponse) GetQuotaExceeded() bool {
+	if x != nil {
+		return x.QuotaExceeded
+	}
+	return false
+}
+
+func (x *GetStatusResponse) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+func (x *GetStatusResponse) GetExitReason() ExitReason {
+	if x != nil {
+		return x.ExitReason
+	}
+	return ExitReason_EXIT_REASON_UNSPECIFIED
+}
+
+func (x *GetStatusResponse) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *GetStatusResponse) GetQueued() bool {
+	if x != nil {
+		return x.Queued
+	}
+	return false
+}
+
+func (x *GetStatusResponse) GetProgress() *Progress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *GetStatusResponse) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *GetStatusResponse) GetProcesses() []*JobProcess {
+	if x != nil {
+		return x.Processes
+	}
+	return nil
+}
+
+func (x *GetStatusResponse) GetOutputContentType() string {
+	if x != nil {
+		return x.OutputContentType
+	}
+	return ""
+}
+
+func (x *GetStatusResponse) GetOutcome() Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return Outcome_OUTCOME_UNSPECIFIED
+}
+
+func (x *GetStatusResponse) GetStateReason() StateReason {
+	if x != nil {
+		return x.StateReason
+	}
+	return StateReason_STATE_REASON_UNSPECIFIED
+}
+
+func (x *GetStatusResponse) GetStateMessage() string {
+	if x != nil {
+		return x.StateMessage
+	}
+	return ""
+}
+
+type JobProcess struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Pid   int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Ppid  int32                  `protobuf:"varint,2,opt,name=ppid,proto3" json:"ppid,omitempty"`
+	// Executable name (at most 15 bytes, as in /proc/<pid>/comm)
+	Command       string `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobProcess) Reset() {
+	*x = JobProcess{}
+	mi := &file_jobby_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobProcess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobProcess) ProtoMessage() {}
+
+func (x *JobProcess) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobProcess.ProtoReflect.Descriptor instead.
+func (*JobProcess) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *JobProcess) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *JobProcess) GetPpid() int32 {
+	if x != nil {
+		return x.Ppid
+	}
+	return 0
+}
+
+func (x *JobProcess) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+// How far along a job says it is. Jobs started with track_progress report
+// progress by writing lines like "JOBBY_PROGRESS: 42% copying files"
+// (a percentage from 0 to 100, then an optional message) to stdout or
+// stderr. The lines stay in the output
+type Progress struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Percent float64                `protobuf:"fixed64,1,opt,name=percent,proto3" json:"percent,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// When the job wrote the report
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_jobby_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Progress) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *Progress) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Progress) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type GetJobOutputRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Type  OutputType             `protobuf:"varint,2,opt,name=type,proto3,enum=jobby.OutputType" json:"type,omitempty"`
+	// Attempt number to stream output from (starting at 1).
+	// 0 selects the latest attempt
+	Attempt uint32 `protobuf:"varint,3,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	// Override the server's output batching. Output is sent once this
+	// many bytes are buffered (also the largest message size).
+	// 0 uses the server default
+	BatchMaxBytes uint32 `protobuf:"varint,4,opt,name=batch_max_bytes,json=batchMaxBytes,proto3" json:"batch_max_bytes,omitempty"`
+	// Override how long output may be buffered before it is sent.
+	// Unset uses the server default, zero sends output immediately
+	BatchMaxDelay *durationpb.Duration `protobuf:"bytes,5,opt,name=batch_max_delay,json=batchMaxDelay,proto3" json:"batch_max_delay,omitempty"`
+	// Replace runs of identical lines with a single
+	// "last line repeated N times" line. Implies line mode
+	CollapseRepeatedLines bool       `protobuf:"varint,6,opt,name=collapse_repeated_lines,json=collapseRepeatedLines,proto3" json:"collapse_repeated_lines,omitempty"`
+	Mode                  StreamMode `protobuf:"varint,7,opt,name=mode,proto3,enum=jobby.StreamMode" json:"mode,omitempty"`
+	// Line mode only. Longest a partial line is held waiting for its
+	// newline before it's sent anyway. Unset uses the server default,
+	// zero holds partial lines until they are complete
+	LineMaxHold *durationpb.Duration `protobuf:"bytes,8,opt,name=line_max_hold,json=lineMaxHold,proto3" json:"line_max_hold,omitempty"`
+	// Canonical text form of the job id (ex: 9b2f2c4e-7c1e-4c52-a1a4-3f0e8f1d6b27).
+	// May be sent instead of job_id. If both are set they must match
+	Id string `protobuf:"bytes,9,opt,name=id,proto3" json:"id,omitempty"`
+	// Send the output written so far and end the stream, instead of
+	// following the job. Without a mode or collapsing, output is sent
+	// in messages of up to 1MiB (unless batch_max_bytes is set) with no
+	// batching delay
+	NoFollow bool `protobuf:"varint,10,opt,name=no_follow,json=noFollow,proto3" json:"no_follow,omitempty"`
+	// Remove the prefixes timestamp_output adds, sending the output as
+	// the job wrote it. No effect on jobs without them
+	StripTimestamps bool `protobuf:"varint,11,opt,name=strip_timestamps,json=stripTimestamps,proto3" json:"strip_timestamps,omitempty"`
+	// Send only this part of the output, for resuming downloads or
+	// fetching pieces of a large output in parallel. Only for attempts
+	// that have exited. Implies no_follow, and can't be combined with
+	// a mode, collapsing or stripping timestamps
+	Range         *ByteRange `protobuf:"bytes,12,opt,name=range,proto3" json:"range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobOutputRequest) Reset() {
+	*x = GetJobOutputRequest{}
+	mi := &file_jobby_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobOutputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobOutputRequest) ProtoMessage() {}
+
+func (x *GetJobOutputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobOutputRequest.ProtoReflect.Descriptor instead.
+func (*GetJobOutputRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetJobOutputRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *GetJobOutputRequest) GetType() OutputType {
+	if x != nil {
+		return x.Type
+	}
+	return OutputType_OUTPUT_TYPE_UNSPECIFIED
+}
+
+func (x *GetJobOutputRequest) GetAttempt() uint32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *GetJobOutputRequest) GetBatchMaxBytes() uint32 {
+	if x != nil {
+		return x.BatchMaxBytes
+	}
+	return 0
+}
+
+func (x *GetJobOutputRequest) GetBatchMaxDelay() *durationpb.Duration {
+	if x != nil {
+		return x.BatchMaxDelay
+	}
+	return nil
+}
+
+func (x *GetJobOutputRequest) GetCollapseRepeatedLines() bool {
+	if x != nil {
+		return x.CollapseRepeatedLines
+	}
+	return false
+}
+
+func (x *GetJobOutputRequest) GetMode() StreamMode {
+	if x != nil {
+		return x.Mode
+	}
+	return StreamMode_STREAM_MODE_UNSPECIFIED
+}
+
+func (x *GetJobOutputRequest) GetLineMaxHold() *durationpb.Duration {
+	if x != nil {
+		return x.LineMaxHold
+	}
+	return nil
+}
+
+func (x *GetJobOutputRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetJobOutputRequest) GetNoFollow() bool {
+	if x != nil {
+		return x.NoFollow
+	}
+	return false
+}
+
+func (x *GetJobOutputRequest) GetStripTimestamps() bool {
+	if x != nil {
+		return x.StripTimestamps
+	}
+	return false
+}
+
+func (x *GetJobOutputRequest) GetRange() *ByteRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+// Bytes [start, end) of an output, counting from the oldest output still
+// kept (see Attempt.stdout_bytes). Ranges past the end are OUT_OF_RANGE
+type ByteRange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Start uint64                 `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	// 0 for the end of the output
+	End           uint64 `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ByteRange) Reset() {
+	*x = ByteRange{}
+	mi := &file_jobby_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ByteRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ByteRange) ProtoMessage() {}
+
+func (x *ByteRange) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ByteRange.ProtoReflect.Descriptor instead.
+func (*ByteRange) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ByteRange) GetStart() uint64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *ByteRange) GetEnd() uint64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type GetJobOutputResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A chunk of output data from the job
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Set on the last message of a stream that reached the end of the
+	// output, which carries no data. Missing if the stream ended any
+	// other way
+	End           *OutputEnd `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobOutputResponse) Reset() {
+	*x = GetJobOutputResponse{}
+	mi := &file_jobby_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobOutputResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobOutputResponse) ProtoMessage() {}
+
+func (x *GetJobOutputResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobOutputResponse.ProtoReflect.Descriptor instead.
+func (*GetJobOutputResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetJobOutputResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *GetJobOutputResponse) GetEnd() *OutputEnd {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+// Sums up the output a stream sent, so clients can check they got all
+// of it intact
+type OutputEnd struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Bytes of data sent on the stream
+	TotalBytes uint64 `protobuf:"varint,1,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	// SHA-256 of the data sent on the stream
+	Sha256        []byte `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutputEnd) Reset() {
+	*x = OutputEnd{}
+	mi := &file_jobby_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutputEnd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputEnd) ProtoMessage() {}
+
+func (x *OutputEnd) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputEnd.ProtoReflect.Descriptor instead.
+func (*OutputEnd) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *OutputEnd) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *OutputEnd) GetSha256() []byte {
+	if x != nil {
+		return x.Sha256
+	}
+	return nil
+}
+
+type GetJobHistoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id (ex: 9b2f2c4e-7c1e-4c52-a1a4-3f0e8f1d6b27).
+	// May be sent instead of job_id. If both are set they must match
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobHistoryRequest) Reset() {
+	*x = GetJobHistoryRequest{}
+	mi := &file_jobby_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobHistoryRequest) ProtoMessage() {}
+
+func (x *GetJobHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetJobHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetJobHistoryRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *GetJobHistoryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Attempt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Starts at 1
+	Number uint32 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Status Status `protobuf:"varint,2,opt,name=status,proto3,enum=jobby.Status" json:"status,omitempty"`
+	// available once the attempt has exited
+	ExitCode  *int32                 `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// unset while the attempt is running
+	EndTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// Size of the attempt's output so far. The full output can be
+	// fetched with GetJobOutput using this attempt's number. Only
+	// counts the output still kept if the job has an output window
+	StdoutBytes uint64 `protobuf:"varint,6,opt,name=stdout_bytes,json=stdoutBytes,proto3" json:"stdout_bytes,omitempty"`
+	StderrBytes uint64 `protobuf:"varint,7,opt,name=stderr_bytes,json=stderrBytes,proto3" json:"stderr_bytes,omitempty"`
+	// The last few bytes of stderr, to make it easy to see why an attempt failed
+	StderrTail []byte `protobuf:"bytes,8,opt,name=stderr_tail,json=stderrTail,proto3" json:"stderr_tail,omitempty"`
+	// Monotonic runtime of the attempt. Prefer this over
+	// end_time - start_time, which can be skewed by clock changes
+	Duration *durationpb.Duration `protobuf:"bytes,9,opt,name=duration,proto3" json:"duration,omitempty"`
+	// See GetStatusResponse.quota_exceeded
+	QuotaExceeded bool `protobuf:"varint,10,opt,name=quota_exceeded,json=quotaExceeded,proto3" json:"quota_exceeded,omitempty"`
+	// See GetStatusResponse.timed_out
+	TimedOut bool `protobuf:"varint,11,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	// See GetStatusResponse.exit_reason
+	ExitReason ExitReason `protobuf:"varint,12,opt,name=exit_reason,json=exitReason,proto3,enum=jobby.ExitReason" json:"exit_reason,omitempty"`
+	// See GetStatusResponse.signal
+	Signal string `protobuf:"bytes,13,opt,name=signal,proto3" json:"signal,omitempty"`
+	// How the exit code was classified (see JobSpec.exit_code_rules)
+	Outcome       Outcome `protobuf:"varint,14,opt,name=outcome,proto3,enum=jobby.Outcome" json:"outcome,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attempt) Reset() {
+	*x = Attempt{}
+	mi := &file_jobby_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attempt) ProtoMessage() {}
+
+func (x *Attempt) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attempt.ProtoReflect.Descriptor instead.
+func (*Attempt) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Attempt) GetNumber() uint32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Attempt) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *Attempt) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *Attempt) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Attempt) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *Attempt) GetStdoutBytes() uint64 {
+	if x != nil {
+		return x.StdoutBytes
+	}
+	return 0
+}
+
+func (x *Attempt) GetStderrBytes() uint64 {
+	if x != nil {
+		return x.StderrBytes
+	}
+	return 0
+}
+
+func (x *Attempt) GetStderrTail() []byte {
+	if x != nil {
+		return x.StderrTail
+	}
+	return nil
+}
+
+func (x *Attempt) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *Attempt) GetQuotaExceeded() bool {
+	if x != nil {
+		return x.QuotaExceeded
+	}
+	return false
+}
+
+func (x *Attempt) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+func (x *Attempt) GetExitReason() ExitReason {
+	if x != nil {
+		return x.ExitReason
+	}
+	return ExitReason_EXIT_REASON_UNSPECIFIED
+}
+
+func (x *Attempt) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *Attempt) GetOutcome() Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return Outcome_OUTCOME_UNSPECIFIED
+}
+
+type GetJobHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attempts      []*Attempt             `protobuf:"bytes,1,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobHistoryResponse) Reset() {
+	*x = GetJobHistoryResponse{}
+	mi := &file_jobby_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobHistoryResponse) ProtoMessage() {}
+
+func (x *GetJobHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetJobHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetJobHistoryResponse) GetAttempts() []*Attempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+type ExportJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportJobsRequest) Reset() {
+	*x = ExportJobsRequest{}
+	mi := &file_jobby_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportJobsRequest) ProtoMessage() {}
+
+func (x *ExportJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportJobsRequest.ProtoReflect.Descriptor instead.
+func (*ExportJobsRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{23}
+}
+
+type JobRecord struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	JobId   []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Command string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Args    []string               `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+	// Status of the latest attempt
+	Status Status `protobuf:"varint,4,opt,name=status,proto3,enum=jobby.Status" json:"status,omitempty"`
+	// available once the latest attempt has exited
+	ExitCode *int32 `protobuf:"varint,5,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	// When the first attempt started
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// unset until the job is finished (including any retries)
+	EndTime     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Attempts    uint32                 `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	MaxAttempts uint32                 `protobuf:"varint,9,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	// Monotonic time from the start of the first attempt until the job
+	// finished (or until now, if it is still running)
+	Duration *durationpb.Duration `protobuf:"bytes,10,opt,name=duration,proto3" json:"duration,omitempty"`
+	// Empty if the job ran without a runtime class
+	RuntimeClass string `protobuf:"bytes,11,opt,name=runtime_class,json=runtimeClass,proto3" json:"runtime_class,omitempty"`
+	// The job as it was submitted. command, args and max_attempts above
+	// are kept for older clients
+	Spec *JobSpec `protobuf:"bytes,12,opt,name=spec,proto3" json:"spec,omitempty"`
+	// Canonical text form of job_id
+	Id string `protobuf:"bytes,13,opt,name=id,proto3" json:"id,omitempty"`
+	// Session the job was started in. Empty if none
+	SessionId string `protobuf:"bytes,14,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Registered with AdoptProcess rather than started by the server. The
+	// spec's command and args are what the process was running
+	Adopted bool `protobuf:"varint,15,opt,name=adopted,proto3" json:"adopted,omitempty"`
+	// Unset unless the spec asked for it (see capture_environment)
+	LaunchSnapshot *LaunchSnapshot `protobuf:"bytes,16,opt,name=launch_snapshot,json=launchSnapshot,proto3" json:"launch_snapshot,omitempty"`
+	// See GetStatusResponse.state_reason
+	StateReason  StateReason `protobuf:"varint,17,opt,name=state_reason,json=stateReason,proto3,enum=jobby.StateReason" json:"state_reason,omitempty"`
+	StateMessage string      `protobuf:"bytes,18,opt,name=state_message,json=stateMessage,proto3" json:"state_message,omitempty"`
+	// Job whose token started this one (see JobSpec.child_jobs). Empty if none
+	ParentId string `protobuf:"bytes,19,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	// See AnnotateJob
+	Annotations   map[string]string `protobuf:"bytes,20,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobRecord) Reset() {
+	*x = JobRecord{}
+	mi := &file_jobby_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobRecord) ProtoMessage() {}
+
+func (x *JobRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobRecord.ProtoReflect.Descriptor instead.
+func (*JobRecord) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *JobRecord) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *JobRecord) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *JobRecord) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *JobRecord) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *JobRecord) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *JobRecord) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *JobRecord) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *JobRecord) GetAttempts() uint32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *JobRecord) GetMaxAttempts() uint32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *JobRecord) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *JobRecord) GetRuntimeClass() string {
+	if x != nil {
+		return x.RuntimeClass
+	}
+	return ""
+}
+
+func (x *JobRecord) GetSpec() *JobSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *JobRecord) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *JobRecord) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *JobRecord) GetAdopted() bool {
+	if x != nil {
+		return x.Adopted
+	}
+	return false
+}
+
+func (x *JobRecord) GetLaunchSnapshot() *LaunchSnapshot {
+	if x != nil {
+		return x.LaunchSnapshot
+	}
+	return nil
+}
+
+func (x *JobRecord) GetStateReason() StateReason {
+	if x != nil {
+		return x.StateReason
+	}
+	return StateReason_STATE_REASON_UNSPECIFIED
+}
+
+func (x *JobRecord) GetStateMessage() string {
+	if x != nil {
+		return x.StateMessage
+	}
+	return ""
+}
+
+func (x *JobRecord) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *JobRecord) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+// What a job was launched with, recorded when it was started
+type LaunchSnapshot struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CapturedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=captured_at,json=capturedAt,proto3" json:"captured_at,omitempty"`
+	// The job's complete environment, the server's included. Values of
+	// variables that look secret (ex: *_TOKEN), and whatever the server's
+	// output redactions match, are masked
+	Env              map[string]string `protobuf:"bytes,2,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	WorkingDirectory string            `protobuf:"bytes,3,opt,name=working_directory,json=workingDirectory,proto3" json:"working_directory,omitempty"`
+	// The command with symlinks resolved
+	BinaryPath string `protobuf:"bytes,4,opt,name=binary_path,json=binaryPath,proto3" json:"binary_path,omitempty"`
+	// Hex encoded. Empty if the binary couldn't be read
+	BinarySha256 string `protobuf:"bytes,5,opt,name=binary_sha256,json=binarySha256,proto3" json:"binary_sha256,omitempty"`
+	Hostname     string `protobuf:"bytes,6,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	// Of the host's kernel (ex: 6.8.0-45-generic)
+	KernelRelease string `protobuf:"bytes,7,opt,name=kernel_release,json=kernelRelease,proto3" json:"kernel_release,omitempty"`
+	// The server's version (see BuildInfo)
+	ServerVersion string `protobuf:"bytes,8,opt,name=server_version,json=serverVersion,proto3" json:"server_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LaunchSnapshot) Reset() {
+	*x = LaunchSnapshot{}
+	mi := &file_jobby_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LaunchSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LaunchSnapshot) ProtoMessage() {}
+
+func (x *LaunchSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LaunchSnapshot.ProtoReflect.Descriptor instead.
+func (*LaunchSnapshot) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *LaunchSnapshot) GetCapturedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CapturedAt
+	}
+	return nil
+}
+
+func (x *LaunchSnapshot) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *LaunchSnapshot) GetWorkingDirectory() string {
+	if x != nil {
+		return x.WorkingDirectory
+	}
+	return ""
+}
+
+func (x *LaunchSnapshot) GetBinaryPath() string {
+	if x != nil {
+		return x.BinaryPath
+	}
+	return ""
+}
+
+func (x *LaunchSnapshot) GetBinarySha256() string {
+	if x != nil {
+		return x.BinarySha256
+	}
+	return ""
+}
+
+func (x *LaunchSnapshot) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *LaunchSnapshot) GetKernelRelease() string {
+	if x != nil {
+		return x.KernelRelease
+	}
+	return ""
+}
+
+func (x *LaunchSnapshot) GetServerVersion() string {
+	if x != nil {
+		return x.ServerVersion
+	}
+	return ""
+}
+
+// Unset parameters match every job
+type ListJobsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Case sensitive substring of the job's command, or of a shell job's
+	// command line
+	CommandContains string `protobuf:"bytes,1,opt,name=command_contains,json=commandContains,proto3" json:"command_contains,omitempty"`
+	// Bounds on when the job's first attempt started. Both are exclusive
+	StartedAfter  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started_after,json=startedAfter,proto3" json:"started_after,omitempty"`
+	StartedBefore *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started_before,json=startedBefore,proto3" json:"started_before,omitempty"`
+	// Only jobs whose latest attempt exited with this code
+	ExitCode *int32 `protobuf:"varint,4,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	// Only jobs started in this session
+	SessionId string `protobuf:"bytes,5,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Only children of this job (see JobRecord.parent_id)
+	ParentId      string `protobuf:"bytes,6,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_jobby_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListJobsRequest) GetCommandContains() string {
+	if x != nil {
+		return x.CommandContains
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetStartedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAfter
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetStartedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedBefore
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *ListJobsRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*JobRecord           `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_jobby_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListJobsResponse) GetJobs() []*JobRecord {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type GetServerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerInfoRequest) Reset() {
+	*x = GetServerInfoRequest{}
+	mi := &file_jobby_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoRequest) ProtoMessage() {}
+
+func (x *GetServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetServerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{28}
+}
+
+type GetServerInfoResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Hostname string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	// GPUs jobs can be granted, ordered by index
+	Gpus []*GPU `protobuf:"bytes,2,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	// What the server binary was built from
+	Build *BuildInfo `protobuf:"bytes,3,opt,name=build,proto3" json:"build,omitempty"`
+	// Every feature flag the server knows of, ordered by name
+	Features      []*FeatureFlag `protobuf:"bytes,4,rep,name=features,proto3" json:"features,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerInfoResponse) Reset() {
+	*x = GetServerInfoResponse{}
+	mi := &file_jobby_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoResponse) ProtoMessage() {}
+
+func (x *GetServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetServerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetServerInfoResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetGpus() []*GPU {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+func (x *GetServerInfoResponse) GetBuild() *BuildInfo {
+	if x != nil {
+		return x.Build
+	}
+	return nil
+}
+
+func (x *GetServerInfoResponse) GetFeatures() []*FeatureFlag {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+type BuildInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Release version (ex: v1.2.0). "(devel)" for builds that weren't stamped with one
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// VCS revision. Empty if unknown
+	Commit string `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	// When the commit was made. Unset if unknown
+	CommitTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=commit_time,json=commitTime,proto3" json:"commit_time,omitempty"`
+	// Built from a tree with uncommitted changes
+	Modified bool `protobuf:"varint,4,opt,name=modified,proto3" json:"modified,omitempty"`
+	// Go toolchain it was built with (ex: go1.24.3)
+	GoVersion     string `protobuf:"bytes,5,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuildInfo) Reset() {
+	*x = BuildInfo{}
+	mi := &file_jobby_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildInfo) ProtoMessage() {}
+
+func (x *BuildInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildInfo.ProtoReflect.Descriptor instead.
+func (*BuildInfo) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *BuildInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetCommitTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommitTime
+	}
+	return nil
+}
+
+func (x *BuildInfo) GetModified() bool {
+	if x != nil {
+		return x.Modified
+	}
+	return false
+}
+
+func (x *BuildInfo) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+type FeatureFlag struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Enabled     bool                   `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Not ready for general use. Off unless the server turns it on
+	Experimental bool `protobuf:"varint,4,opt,name=experimental,proto3" json:"experimental,omitempty"`
+	// RPCs (ex: StreamServerLogs) that fail with UNIMPLEMENTED while the flag is off
+	Rpcs          []string `protobuf:"bytes,5,rep,name=rpcs,proto3" json:"rpcs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeatureFlag) Reset() {
+	*x = FeatureFlag{}
+	mi := &file_jobby_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeatureFlag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureFlag) ProtoMessage() {}
+
+func (x *FeatureFlag) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureFlag.ProtoReflect.Descriptor instead.
+func (*FeatureFlag) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *FeatureFlag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FeatureFlag) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *FeatureFlag) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *FeatureFlag) GetExperimental() bool {
+	if x != nil {
+		return x.Experimental
+	}
+	return false
+}
+
+func (x *FeatureFlag) GetRpcs() []string {
+	if x != nil {
+		return x.Rpcs
+	}
+	return nil
+}
+
+type GPU struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Minor number of the GPU's device (/dev/nvidia<index>)
+	Index uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Uuid  string `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Model string `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	// PCI bus location (ex: 0000:3b:00.0)
+	BusId         string `protobuf:"bytes,4,opt,name=bus_id,json=busId,proto3" json:"bus_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GPU) Reset() {
+	*x = GPU{}
+	mi := &file_jobby_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPU) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPU) ProtoMessage() {}
+
+func (x *GPU) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPU.ProtoReflect.Descriptor instead.
+func (*GPU) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GPU) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GPU) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *GPU) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *GPU) GetBusId() string {
+	if x != nil {
+		return x.BusId
+	}
+	return ""
+}
+
+type GetUsageSummaryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One of the server's accounting windows. Unset summarizes every window
+	Window        *durationpb.Duration `protobuf:"bytes,1,opt,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageSummaryRequest) Reset() {
+	*x = GetUsageSummaryRequest{}
+	mi := &file_jobby_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageSummaryRequest) ProtoMessage() {}
+
+func (x *GetUsageSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetUsageSummaryRequest) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+type GetUsageSummaryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Shortest window first
+	Windows       []*UsageWindow `protobuf:"bytes,1,rep,name=windows,proto3" json:"windows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageSummaryResponse) Reset() {
+	*x = GetUsageSummaryResponse{}
+	mi := &file_jobby_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageSummaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageSummaryResponse) ProtoMessage() {}
+
+func (x *GetUsageSummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageSummaryResponse.ProtoReflect.Descriptor instead.
+func (*GetUsageSummaryResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GetUsageSummaryResponse) GetWindows() []*UsageWindow {
+	if x != nil {
+		return x.Windows
+	}
+	return nil
+}
+
+type UsageWindow struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Usage over the trailing window, up to now
+	Window *durationpb.Duration `protobuf:"bytes,1,opt,name=window,proto3" json:"window,omitempty"`
+	// Owners with any usage in the window, ordered by name. Only the
+	// caller's own usage unless the server lets them view everyone's
+	Owners        []*OwnerUsage `protobuf:"bytes,2,rep,name=owners,proto3" json:"owners,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageWindow) Reset() {
+	*x = UsageWindow{}
+	mi := &file_jobby_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageWindow) ProtoMessage() {}
+
+func (x *UsageWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageWindow.ProtoReflect.Descriptor instead.
+func (*UsageWindow) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *UsageWindow) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *UsageWindow) GetOwners() []*OwnerUsage {
+	if x != nil {
+		return x.Owners
+	}
+	return nil
+}
+
+type OwnerUsage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Owner string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Jobs submitted during the window
+	JobsStarted uint64 `protobuf:"varint,2,opt,name=jobs_started,json=jobsStarted,proto3" json:"jobs_started,omitempty"`
+	// Attempts that finished during the window. Attempts count
+	// toward the times below once they finish
+	AttemptsFinished uint64 `protobuf:"varint,3,opt,name=attempts_finished,json=attemptsFinished,proto3" json:"attempts_finished,omitempty"`
+	// User and system CPU time
+	CpuSeconds    float64 `protobuf:"fixed64,4,opt,name=cpu_seconds,json=cpuSeconds,proto3" json:"cpu_seconds,omitempty"`
+	WallSeconds   float64 `protobuf:"fixed64,5,opt,name=wall_seconds,json=wallSeconds,proto3" json:"wall_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OwnerUsage) Reset() {
+	*x = OwnerUsage{}
+	mi := &file_jobby_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OwnerUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OwnerUsage) ProtoMessage() {}
+
+func (x *OwnerUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OwnerUsage.ProtoReflect.Descriptor instead.
+func (*OwnerUsage) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *OwnerUsage) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *OwnerUsage) GetJobsStarted() uint64 {
+	if x != nil {
+		return x.JobsStarted
+	}
+	return 0
+}
+
+func (x *OwnerUsage) GetAttemptsFinished() uint64 {
+	if x != nil {
+		return x.AttemptsFinished
+	}
+	return 0
+}
+
+func (x *OwnerUsage) GetCpuSeconds() float64 {
+	if x != nil {
+		return x.CpuSeconds
+	}
+	return 0
+}
+
+func (x *OwnerUsage) GetWallSeconds() float64 {
+	if x != nil {
+		return x.WallSeconds
+	}
+	return 0
+}
+
+type GetJobEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobEventsRequest) Reset() {
+	*x = GetJobEventsRequest{}
+	mi := &file_jobby_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobEventsRequest) ProtoMessage() {}
+
+func (x *GetJobEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobEventsRequest.ProtoReflect.Descriptor instead.
+func (*GetJobEventsRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetJobEventsRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *GetJobEventsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetJobEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Oldest first
+	Events        []*JobEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobEventsResponse) Reset() {
+	*x = GetJobEventsResponse{}
+	mi := &file_jobby_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobEventsResponse) ProtoMessage() {}
+
+func (x *GetJobEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobEventsResponse.ProtoReflect.Descriptor instead.
+func (*GetJobEventsResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GetJobEventsResponse) GetEvents() []*JobEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type JobEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  JobEventType           `protobuf:"varint,1,opt,name=type,proto3,enum=jobby.JobEventType" json:"type,omitempty"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// Who caused the event: the user who made the request, or
+	// "server" for things the server did on its own (ex: timeouts)
+	Actor string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Attempt the event is about. 0 for events about the whole job
+	Attempt uint32 `protobuf:"varint,4,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	// Human readable specifics (ex: "SIGKILL (TIMED_OUT)")
+	Detail        string `protobuf:"bytes,5,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobEvent) Reset() {
+	*x = JobEvent{}
+	mi := &file_jobby_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobEvent) ProtoMessage() {}
+
+func (x *JobEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobEvent.ProtoReflect.Descriptor instead.
+func (*JobEvent) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *JobEvent) GetType() JobEventType {
+	if x != nil {
+		return x.Type
+	}
+	return JobEventType_JOB_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *JobEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *JobEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *JobEvent) GetAttempt() uint32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *JobEvent) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type ListOutputSegmentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id   string     `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Type OutputType `protobuf:"varint,3,opt,name=type,proto3,enum=jobby.OutputType" json:"type,omitempty"`
+	// Attempt number (starting at 1). 0 selects the latest attempt
+	Attempt uint32 `protobuf:"varint,4,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	// Only segments with output from this time on. Unset for no limit
+	Since *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=since,proto3" json:"since,omitempty"`
+	// Only segments with output from before this time. Unset for no limit
+	Until         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOutputSegmentsRequest) Reset() {
+	*x = ListOutputSegmentsRequest{}
+	mi := &file_jobby_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOutputSegmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOutputSegmentsRequest) ProtoMessage() {}
+
+func (x *ListOutputSegmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOutputSegmentsRequest.ProtoReflect.Descriptor instead.
+func (*ListOutputSegmentsRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ListOutputSegmentsRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *ListOutputSegmentsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ListOutputSegmentsRequest) GetType() OutputType {
+	if x != nil {
+		return x.Type
+	}
+	return OutputType_OUTPUT_TYPE_UNSPECIFIED
+}
+
+func (x *ListOutputSegmentsRequest) GetAttempt() uint32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *ListOutputSegmentsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListOutputSegmentsRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+type ListOutputSegmentsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Oldest first
+	Segments      []*OutputSegment `protobuf:"bytes,1,rep,name=segments,proto3" json:"segments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOutputSegmentsResponse) Reset() {
+	*x = ListOutputSegmentsResponse{}
+	mi := &file_jobby_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOutputSegmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOutputSegmentsResponse) ProtoMessage() {}
+
+func (x *ListOutputSegmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOutputSegmentsResponse.ProtoReflect.Descriptor instead.
+func (*ListOutputSegmentsResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ListOutputSegmentsResponse) GetSegments() []*OutputSegment {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+type OutputSegment struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Starts at 1. Each new segment gets the next number
+	Number    uint32                 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// When the segment was done being written. Unset for the one being written
+	EndTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// Bytes of output in the segment
+	SizeBytes     uint64 `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutputSegment) Reset() {
+	*x = OutputSegment{}
+	mi := &file_jobby_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutputSegment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputSegment) ProtoMessage() {}
+
+func (x *OutputSegment) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputSegment.ProtoReflect.Descriptor instead.
+func (*OutputSegment) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *OutputSegment) GetNumber() uint32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *OutputSegment) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *OutputSegment) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *OutputSegment) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+type GetOutputSegmentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id   string     `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Type OutputType `protobuf:"varint,3,opt,name=type,proto3,enum=jobby.OutputType" json:"type,omitempty"`
+	// Attempt number (starting at 1). 0 selects the latest attempt
+	Attempt uint32 `protobuf:"varint,4,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	// Number of the segment to stream
+	Segment       uint32 `protobuf:"varint,5,opt,name=segment,proto3" json:"segment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOutputSegmentRequest) Reset() {
+	*x = GetOutputSegmentRequest{}
+	mi := &file_jobby_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOutputSegmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOutputSegmentRequest) ProtoMessage() {}
+
+func (x *GetOutputSegmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOutputSegmentRequest.ProtoReflect.Descriptor instead.
+func (*GetOutputSegmentRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GetOutputSegmentRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *GetOutputSegmentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetOutputSegmentRequest) GetType() OutputType {
+	if x != nil {
+		return x.Type
+	}
+	return OutputType_OUTPUT_TYPE_UNSPECIFIED
+}
+
+func (x *GetOutputSegmentRequest) GetAttempt() uint32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *GetOutputSegmentRequest) GetSegment() uint32 {
+	if x != nil {
+		return x.Segment
+	}
+	return 0
+}
+
+type GetJobProgressRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobProgressRequest) Reset() {
+	*x = GetJobProgressRequest{}
+	mi := &file_jobby_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobProgressRequest) ProtoMessage() {}
+
+func (x *GetJobProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobProgressRequest.ProtoReflect.Descriptor instead.
+func (*GetJobProgressRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *GetJobProgressRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *GetJobProgressRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetJobProgressResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Attempt that made the report. A new attempt starts over without a report
+	Attempt uint32 `protobuf:"varint,1,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	// Unset until the attempt makes a report
+	Progress      *Progress `protobuf:"bytes,2,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobProgressResponse) Reset() {
+	*x = GetJobProgressResponse{}
+	mi := &file_jobby_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobProgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobProgressResponse) ProtoMessage() {}
+
+func (x *GetJobProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobProgressResponse.ProtoReflect.Descriptor instead.
+func (*GetJobProgressResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *GetJobProgressResponse) GetAttempt() uint32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *GetJobProgressResponse) GetProgress() *Progress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+type EndSessionRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Stop the session's jobs that are still running or queued
+	StopJobs      bool `protobuf:"varint,2,opt,name=stop_jobs,json=stopJobs,proto3" json:"stop_jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndSessionRequest) Reset() {
+	*x = EndSessionRequest{}
+	mi := &file_jobby_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndSessionRequest) ProtoMessage() {}
+
+func (x *EndSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndSessionRequest.ProtoReflect.Descriptor instead.
+func (*EndSessionRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *EndSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *EndSessionRequest) GetStopJobs() bool {
+	if x != nil {
+		return x.StopJobs
+	}
+	return false
+}
+
+type EndSessionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Jobs stopped by the request
+	StoppedJobIds []string `protobuf:"bytes,1,rep,name=stopped_job_ids,json=stoppedJobIds,proto3" json:"stopped_job_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndSessionResponse) Reset() {
+	*x = EndSessionResponse{}
+	mi := &file_jobby_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndSessionResponse) ProtoMessage() {}
+
+func (x *EndSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndSessionResponse.ProtoReflect.Descriptor instead.
+func (*EndSessionResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *EndSessionResponse) GetStoppedJobIds() []string {
+	if x != nil {
+		return x.StoppedJobIds
+	}
+	return nil
+}
+
+type StreamServerLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Entries below this level are left out. INFO when unset
+	Level         LogLevel `protobuf:"varint,1,opt,name=level,proto3,enum=jobby.LogLevel" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamServerLogsRequest) Reset() {
+	*x = StreamServerLogsRequest{}
+	mi := &file_jobby_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamServerLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamServerLogsRequest) ProtoMessage() {}
+
+func (x *StreamServerLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamServerLogsRequest.ProtoReflect.Descriptor instead.
+func (*StreamServerLogsRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *StreamServerLogsRequest) GetLevel() LogLevel {
+	if x != nil {
+		return x.Level
+	}
+	return LogLevel_LOG_LEVEL_UNSPECIFIED
+}
+
+type ServerLogEntry struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Time    *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Level   LogLevel               `protobuf:"varint,2,opt,name=level,proto3,enum=jobby.LogLevel" json:"level,omitempty"`
+	Message string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// Keys of attributes in groups are prefixed with the group (ex: "group.key")
+	Attrs map[string]string `protobuf:"bytes,4,rep,name=attrs,proto3" json:"attrs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Entries left out right before this one because the stream fell behind
+	Dropped       uint64 `protobuf:"varint,5,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerLogEntry) Reset() {
+	*x = ServerLogEntry{}
+	mi := &file_jobby_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerLogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerLogEntry) ProtoMessage() {}
+
+func (x *ServerLogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerLogEntry.ProtoReflect.Descriptor instead.
+func (*ServerLogEntry) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ServerLogEntry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ServerLogEntry) GetLevel() LogLevel {
+	if x != nil {
+		return x.Level
+	}
+	return LogLevel_LOG_LEVEL_UNSPECIFIED
+}
+
+func (x *ServerLogEntry) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServerLogEntry) GetAttrs() map[string]string {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+func (x *ServerLogEntry) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type DeleteJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// Delete the job and its output right away, with no way to restore
+	// it. Also purges a job that was already deleted without force
+	Force         bool `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteJobRequest) Reset() {
+	*x = DeleteJobRequest{}
+	mi := &file_jobby_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteJobRequest) ProtoMessage() {}
+
+func (x *DeleteJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteJobRequest.ProtoReflect.Descriptor instead.
+func (*DeleteJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *DeleteJobRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *DeleteJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteJobRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type DeleteJobResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Last moment the job can be restored. Unset if it was purged
+	RestorableUntil *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=restorable_until,json=restorableUntil,proto3" json:"restorable_until,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeleteJobResponse) Reset() {
+	*x = DeleteJobResponse{}
+	mi := &file_jobby_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteJobResponse) ProtoMessage() {}
+
+func (x *DeleteJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteJobResponse.ProtoReflect.Descriptor instead.
+func (*DeleteJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *DeleteJobResponse) GetRestorableUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RestorableUntil
+	}
+	return nil
+}
+
+type RestoreJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreJobRequest) Reset() {
+	*x = RestoreJobRequest{}
+	mi := &file_jobby_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreJobRequest) ProtoMessage() {}
+
+func (x *RestoreJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreJobRequest.ProtoReflect.Descriptor instead.
+func (*RestoreJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *RestoreJobRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *RestoreJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RestoreJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreJobResponse) Reset() {
+	*x = RestoreJobResponse{}
+	mi := &file_jobby_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreJobResponse) ProtoMessage() {}
+
+func (x *RestoreJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreJobResponse.ProtoReflect.Descriptor instead.
+func (*RestoreJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{53}
+}
+
+type AdoptProcessRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Pid   int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	// Absolute paths of the files the process writes its output to. They
+	// may be the same file. The server reads them, but never deletes them
+	StdoutPath string `protobuf:"bytes,2,opt,name=stdout_path,json=stdoutPath,proto3" json:"stdout_path,omitempty"`
+	StderrPath string `protobuf:"bytes,3,opt,name=stderr_path,json=stderrPath,proto3" json:"stderr_path,omitempty"`
+	// User the job belongs to. The caller when empty
+	Owner string `protobuf:"bytes,4,opt,name=owner,proto3" json:"owner,omitempty"`
+	// See JobSpec.labels
+	Labels        map[string]string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdoptProcessRequest) Reset() {
+	*x = AdoptProcessRequest{}
+	mi := &file_jobby_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdoptProcessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdoptProcessRequest) ProtoMessage() {}
+
+func (x *AdoptProcessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdoptProcessRequest.ProtoReflect.Descriptor instead.
+func (*AdoptProcessRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *AdoptProcessRequest) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *AdoptProcessRequest) GetStdoutPath() string {
+	if x != nil {
+		return x.StdoutPath
+	}
+	return ""
+}
+
+func (x *AdoptProcessRequest) GetStderrPath() string {
+	if x != nil {
+		return x.StderrPath
+	}
+	return ""
+}
+
+func (x *AdoptProcessRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *AdoptProcessRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type AdoptProcessResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of job_id
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdoptProcessResponse) Reset() {
+	*x = AdoptProcessResponse{}
+	mi := &file_jobby_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdoptProcessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdoptProcessResponse) ProtoMessage() {}
+
+func (x *AdoptProcessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdoptProcessResponse.ProtoReflect.Descriptor instead.
+func (*AdoptProcessResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *AdoptProcessResponse) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *AdoptProcessResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetJobStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Runs of jobs identical to this one (same command line and environment)
+	JobId []byte `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// Or every run of this command, whatever its args. Shell jobs go by
+	// their command line (see JobSpec.shell)
+	Command       string `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobStatsRequest) Reset() {
+	*x = GetJobStatsRequest{}
+	mi := &file_jobby_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobStatsRequest) ProtoMessage() {}
+
+func (x *GetJobStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetJobStatsRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *GetJobStatsRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *GetJobStatsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetJobStatsRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+type GetJobStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Finished attempts the stats cover. Attempts that were stopped or
+	// preempted are left out, since they didn't get to run their course
+	Runs     uint32                `protobuf:"varint,1,opt,name=runs,proto3" json:"runs,omitempty"`
+	Duration *DurationDistribution `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	// Bytes of stdout and stderr each run kept on disk
+	OutputBytes *SizeDistribution `protobuf:"bytes,3,opt,name=output_bytes,json=outputBytes,proto3" json:"output_bytes,omitempty"`
+	// How many runs exited with each code
+	ExitCodes map[int32]uint32 `protobuf:"bytes,4,rep,name=exit_codes,json=exitCodes,proto3" json:"exit_codes,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// Runs killed by a signal (ex: timed out or out of memory) instead
+	Signaled      uint32 `protobuf:"varint,5,opt,name=signaled,proto3" json:"signaled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobStatsResponse) Reset() {
+	*x = GetJobStatsResponse{}
+	mi := &file_jobby_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobStatsResponse) ProtoMessage() {}
+
+func (x *GetJobStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetJobStatsResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetJobStatsResponse) GetRuns() uint32 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *GetJobStatsResponse) GetDuration() *DurationDistribution {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *GetJobStatsResponse) GetOutputBytes() *SizeDistribution {
+	if x != nil {
+		return x.OutputBytes
+	}
+	return nil
+}
+
+func (x *GetJobStatsResponse) GetExitCodes() map[int32]uint32 {
+	if x != nil {
+		return x.ExitCodes
+	}
+	return nil
+}
+
+func (x *GetJobStatsResponse) GetSignaled() uint32 {
+	if x != nil {
+		return x.Signaled
+	}
+	return 0
+}
+
+// Unset when there are no runs
+type DurationDistribution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           *durationpb.Duration   `protobuf:"bytes,1,opt,name=min,proto3" json:"min,omitempty"`
+	Median        *durationpb.Duration   `protobuf:"bytes,2,opt,name=median,proto3" json:"median,omitempty"`
+	P90           *durationpb.Duration   `protobuf:"bytes,3,opt,name=p90,proto3" json:"p90,omitempty"`
+	Max           *durationpb.Duration   `protobuf:"bytes,4,opt,name=max,proto3" json:"max,omitempty"`
+	Mean          *durationpb.Duration   `protobuf:"bytes,5,opt,name=mean,proto3" json:"mean,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DurationDistribution) Reset() {
+	*x = DurationDistribution{}
+	mi := &file_jobby_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DurationDistribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DurationDistribution) ProtoMessage() {}
+
+func (x *DurationDistribution) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DurationDistribution.ProtoReflect.Descriptor instead.
+func (*DurationDistribution) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *DurationDistribution) GetMin() *durationpb.Duration {
+	if x != nil {
+		return x.Min
+	}
+	return nil
+}
+
+func (x *DurationDistribution) GetMedian() *durationpb.Duration {
+	if x != nil {
+		return x.Median
+	}
+	return nil
+}
+
+func (x *DurationDistribution) GetP90() *durationpb.Duration {
+	if x != nil {
+		return x.P90
+	}
+	return nil
+}
+
+func (x *DurationDistribution) GetMax() *durationpb.Duration {
+	if x != nil {
+		return x.Max
+	}
+	return nil
+}
+
+func (x *DurationDistribution) GetMean() *durationpb.Duration {
+	if x != nil {
+		return x.Mean
+	}
+	return nil
+}
+
+// Unset when there are no runs
+type SizeDistribution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           uint64                 `protobuf:"varint,1,opt,name=min,proto3" json:"min,omitempty"`
+	Median        uint64                 `protobuf:"varint,2,opt,name=median,proto3" json:"median,omitempty"`
+	P90           uint64                 `protobuf:"varint,3,opt,name=p90,proto3" json:"p90,omitempty"`
+	Max           uint64                 `protobuf:"varint,4,opt,name=max,proto3" json:"max,omitempty"`
+	Mean          uint64                 `protobuf:"varint,5,opt,name=mean,proto3" json:"mean,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SizeDistribution) Reset() {
+	*x = SizeDistribution{}
+	mi := &file_jobby_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SizeDistribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SizeDistribution) ProtoMessage() {}
+
+func (x *SizeDistribution) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SizeDistribution.ProtoReflect.Descriptor instead.
+func (*SizeDistribution) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *SizeDistribution) GetMin() uint64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *SizeDistribution) GetMedian() uint64 {
+	if x != nil {
+		return x.Median
+	}
+	return 0
+}
+
+func (x *SizeDistribution) GetP90() uint64 {
+	if x != nil {
+		return x.P90
+	}
+	return 0
+}
+
+func (x *SizeDistribution) GetMax() uint64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *SizeDistribution) GetMean() uint64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+type DescribeJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeJobRequest) Reset() {
+	*x = DescribeJobRequest{}
+	mi := &file_jobby_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeJobRequest) ProtoMessage() {}
+
+func (x *DescribeJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeJobRequest.ProtoReflect.Descriptor instead.
+func (*DescribeJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *DescribeJobRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *DescribeJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DescribeJobResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Spec (with labels), timestamps and launch snapshot
+	Record *JobRecord `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	// Of the latest attempt
+	Status *GetStatusResponse `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// Every attempt, as GetJobHistory returns them
+	Attempts []*Attempt `protobuf:"bytes,3,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	// What happened to the job, oldest first (see GetJobEvents)
+	Events []*JobEvent `protobuf:"bytes,4,rep,name=events,proto3" json:"events,omitempty"`
+	// Where the latest attempt's output is, stdout first
+	Outputs []*OutputDescriptor `protobuf:"bytes,5,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	// Summed over every attempt
+	Usage         *JobResourceUsage `protobuf:"bytes,6,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeJobResponse) Reset() {
+	*x = DescribeJobResponse{}
+	mi := &file_jobby_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeJobResponse) ProtoMessage() {}
+
+func (x *DescribeJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeJobResponse.ProtoReflect.Descriptor instead.
+func (*DescribeJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *DescribeJobResponse) GetRecord() *JobRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *DescribeJobResponse) GetStatus() *GetStatusResponse {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *DescribeJobResponse) GetAttempts() []*Attempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+func (x *DescribeJobResponse) GetEvents() []*JobEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *DescribeJobResponse) GetOutputs() []*OutputDescriptor {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+func (x *DescribeJobResponse) GetUsage() *JobResourceUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+// How one of an attempt's output streams is stored
+type OutputDescriptor struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  OutputType             `protobuf:"varint,1,opt,name=type,proto3,enum=jobby.OutputType" json:"type,omitempty"`
+	// Bytes still kept, before encryption
+	Bytes uint64 `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// Files the output is in. More than one if it's segmented
+	Files uint32 `protobuf:"varint,3,opt,name=files,proto3" json:"files,omitempty"`
+	// Split into segments (see ListOutputSegments)
+	Segmented bool `protobuf:"varint,4,opt,name=segmented,proto3" json:"segmented,omitempty"`
+	// Encrypted at rest
+	Encrypted bool `protobuf:"varint,5,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	// Sent to a FIFO or Unix socket instead of being stored
+	Sink          bool `protobuf:"varint,6,opt,name=sink,proto3" json:"sink,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutputDescriptor) Reset() {
+	*x = OutputDescriptor{}
+	mi := &file_jobby_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutputDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputDescriptor) ProtoMessage() {}
+
+func (x *OutputDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputDescriptor.ProtoReflect.Descriptor instead.
+func (*OutputDescriptor) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *OutputDescriptor) GetType() OutputType {
+	if x != nil {
+		return x.Type
+	}
+	return OutputType_OUTPUT_TYPE_UNSPECIFIED
+}
+
+func (x *OutputDescriptor) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *OutputDescriptor) GetFiles() uint32 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *OutputDescriptor) GetSegmented() bool {
+	if x != nil {
+		return x.Segmented
+	}
+	return false
+}
+
+func (x *OutputDescriptor) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+func (x *OutputDescriptor) GetSink() bool {
+	if x != nil {
+		return x.Sink
+	}
+	return false
+}
+
+type JobResourceUsage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// User and system CPU time of the attempts that have exited
+	CpuTime *durationpb.Duration `protobuf:"bytes,1,opt,name=cpu_time,json=cpuTime,proto3" json:"cpu_time,omitempty"`
+	// Of every attempt, including one that's still running
+	WallTime *durationpb.Duration `protobuf:"bytes,2,opt,name=wall_time,json=wallTime,proto3" json:"wall_time,omitempty"`
+	// Of every attempt's stdout and stderr still kept
+	OutputBytes   uint64 `protobuf:"varint,3,opt,name=output_bytes,json=outputBytes,proto3" json:"output_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobResourceUsage) Reset() {
+	*x = JobResourceUsage{}
+	mi := &file_jobby_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobResourceUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobResourceUsage) ProtoMessage() {}
+
+func (x *JobResourceUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobResourceUsage.ProtoReflect.Descriptor instead.
+func (*JobResourceUsage) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *JobResourceUsage) GetCpuTime() *durationpb.Duration {
+	if x != nil {
+		return x.CpuTime
+	}
+	return nil
+}
+
+func (x *JobResourceUsage) GetWallTime() *durationpb.Duration {
+	if x != nil {
+		return x.WallTime
+	}
+	return nil
+}
+
+func (x *JobResourceUsage) GetOutputBytes() uint64 {
+	if x != nil {
+		return x.OutputBytes
+	}
+	return 0
+}
+
+type WriteJobStdinRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only read from the first message
+	JobId []byte `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Data          []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteJobStdinRequest) Reset() {
+	*x = WriteJobStdinRequest{}
+	mi := &file_jobby_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteJobStdinRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteJobStdinRequest) ProtoMessage() {}
+
+func (x *WriteJobStdinRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteJobStdinRequest.ProtoReflect.Descriptor instead.
+func (*WriteJobStdinRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *WriteJobStdinRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *WriteJobStdinRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WriteJobStdinRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type WriteJobStdinResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Bytes the job was handed. Less than was sent if it exited early
+	BytesWritten  uint64 `protobuf:"varint,1,opt,name=bytes_written,json=bytesWritten,proto3" json:"bytes_written,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteJobStdinResponse) Reset() {
+	*x = WriteJobStdinResponse{}
+	mi := &file_jobby_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteJobStdinResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteJobStdinResponse) ProtoMessage() {}
+
+func (x *WriteJobStdinResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteJobStdinResponse.ProtoReflect.Descriptor instead.
+func (*WriteJobStdinResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *WriteJobStdinResponse) GetBytesWritten() uint64 {
+	if x != nil {
+		return x.BytesWritten
+	}
+	return 0
+}
+
+type RenewJobLeaseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewJobLeaseRequest) Reset() {
+	*x = RenewJobLeaseRequest{}
+	mi := &file_jobby_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewJobLeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewJobLeaseRequest) ProtoMessage() {}
+
+func (x *RenewJobLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewJobLeaseRequest.ProtoReflect.Descriptor instead.
+func (*RenewJobLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *RenewJobLeaseRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *RenewJobLeaseRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RenewJobLeaseResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When the lease now runs out
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewJobLeaseResponse) Reset() {
+	*x = RenewJobLeaseResponse{}
+	mi := &file_jobby_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewJobLeaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewJobLeaseResponse) ProtoMessage() {}
+
+func (x *RenewJobLeaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewJobLeaseResponse.ProtoReflect.Descriptor instead.
+func (*RenewJobLeaseResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *RenewJobLeaseResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ReportJobProgressRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// 0 to 100
+	Percent       float64 `protobuf:"fixed64,3,opt,name=percent,proto3" json:"percent,omitempty"`
+	Message       string  `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportJobProgressRequest) Reset() {
+	*x = ReportJobProgressRequest{}
+	mi := &file_jobby_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportJobProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportJobProgressRequest) ProtoMessage() {}
+
+func (x *ReportJobProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportJobProgressRequest.ProtoReflect.Descriptor instead.
+func (*ReportJobProgressRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *ReportJobProgressRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *ReportJobProgressRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReportJobProgressRequest) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *ReportJobProgressRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ReportJobProgressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportJobProgressResponse) Reset() {
+	*x = ReportJobProgressResponse{}
+	mi := &file_jobby_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportJobProgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportJobProgressResponse) ProtoMessage() {}
+
+func (x *ReportJobProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportJobProgressResponse.ProtoReflect.Descriptor instead.
+func (*ReportJobProgressResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{69}
+}
+
+type AnnotateJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId []byte                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Canonical text form of the job id. May be sent instead of job_id
+	Id string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// Replace earlier annotations with the same key. Empty values remove
+	// them. Keys and values have the same limits as labels
+	Annotations   map[string]string `protobuf:"bytes,3,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnnotateJobRequest) Reset() {
+	*x = AnnotateJobRequest{}
+	mi := &file_jobby_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnnotateJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnnotateJobRequest) ProtoMessage() {}
+
+func (x *AnnotateJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnnotateJobRequest.ProtoReflect.Descriptor instead.
+func (*AnnotateJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *AnnotateJobRequest) GetJobId() []byte {
+	if x != nil {
+		return x.JobId
+	}
+	return nil
+}
+
+func (x *AnnotateJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AnnotateJobRequest) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+type AnnotateJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnnotateJobResponse) Reset() {
+	*x = AnnotateJobResponse{}
+	mi := &file_jobby_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnnotateJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnnotateJobResponse) ProtoMessage() {}
+
+func (x *AnnotateJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnnotateJobResponse.ProtoReflect.Descriptor instead.
+func (*AnnotateJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{71}
+}
+
+// Starts a job on a recurring basis
+type Schedule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tells the schedule apart from its owner's others. Up to 64 letters,
+	// digits, '-', '_' and '.'
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Standard five field cron expression (ex: "0 3 * * *" or "@daily"), in
+	// the server's time zone
+	Cron string `protobuf:"bytes,2,opt,name=cron,proto3" json:"cron,omitempty"`
+	// What each run starts. Its jobs are labeled schedule=<name>
+	Spec *JobSpec `protobuf:"bytes,3,opt,name=spec,proto3" json:"spec,omitempty"`
+	// Set by the server
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// When the schedule last started a job. Unset if it never has
+	LastRun *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"`
+	// When it starts its next job. Unset if its cron expression never matches again
+	NextRun *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`
+	// What to do about runs that were missed while no server was running
+	// schedules (ex: during an upgrade). Unspecified runs once, like RUN_ONCE
+	MissedRuns MissedRunPolicy `protobuf:"varint,7,opt,name=missed_runs,json=missedRuns,proto3,enum=jobby.MissedRunPolicy" json:"missed_runs,omitempty"`
+	// Start each run up to this much after its cron time, so schedules
+	// with the same time don't all start at once. Each run's delay is
+	// picked in advance (see ListScheduleRuns). At most an hour
+	Jitter        *durationpb.Duration `protobuf:"bytes,8,opt,name=jitter,proto3" json:"jitter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_jobby_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *Schedule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Schedule) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *Schedule) GetSpec() *JobSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *Schedule) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Schedule) GetLastRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRun
+	}
+	return nil
+}
+
+func (x *Schedule) GetNextRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRun
+	}
+	return nil
+}
+
+func (x *Schedule) GetMissedRuns() MissedRunPolicy {
+	if x != nil {
+		return x.MissedRuns
+	}
+	return MissedRunPolicy_MISSED_RUN_POLICY_UNSPECIFIED
+}
+
+func (x *Schedule) GetJitter() *durationpb.Duration {
+	if x != nil {
+		return x.Jitter
+	}
+	return nil
+}
+
+// A time a schedule's cron expression matched, and what came of it
+type ScheduleRun struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When the cron expression matched
+	ScheduledFor *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=scheduled_for,json=scheduledFor,proto3" json:"scheduled_for,omitempty"`
+	// scheduled_for plus the run's jitter: when it's due to start
+	StartAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	// When its job was started. Unset if it wasn't, or hasn't been yet
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	// Of the job it started (ex: 9b2f2c4e-7c1e-4c52-a1a4-3f0e8f1d6b27).
+	// Empty if none
+	JobId string `protobuf:"bytes,4,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Why it didn't start a job (ex: the last run was still going).
+	// Empty for runs that did and upcoming runs
+	Skipped string `protobuf:"bytes,5,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// Runs missed before this one, which it ran in place of (RUN_ONCE)
+	// or was skipped along with
+	Missed        uint32 `protobuf:"varint,6,opt,name=missed,proto3" json:"missed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleRun) Reset() {
+	*x = ScheduleRun{}
+	mi := &file_jobby_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleRun) ProtoMessage() {}
+
+func (x *ScheduleRun) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleRun.ProtoReflect.Descriptor instead.
+func (*ScheduleRun) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *ScheduleRun) GetScheduledFor() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledFor
+	}
+	return nil
+}
+
+func (x *ScheduleRun) GetStartAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartAt
+	}
+	return nil
+}
+
+func (x *ScheduleRun) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ScheduleRun) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *ScheduleRun) GetSkipped() string {
+	if x != nil {
+		return x.Skipped
+	}
+	return ""
+}
+
+func (x *ScheduleRun) GetMissed() uint32 {
+	if x != nil {
+		return x.Missed
+	}
+	return 0
+}
+
+type PutScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *Schedule              `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutScheduleRequest) Reset() {
+	*x = PutScheduleRequest{}
+	mi := &file_jobby_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutScheduleRequest) ProtoMessage() {}
+
+func (x *PutScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutScheduleRequest.ProtoReflect.Descriptor instead.
+func (*PutScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *PutScheduleRequest) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type PutScheduleResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Schedule *Schedule              `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	// Whether the schedule is new, rather than replacing one of the same name
+	Created       bool `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutScheduleResponse) Reset() {
+	*x = PutScheduleResponse{}
+	mi := &file_jobby_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutScheduleResponse) ProtoMessage() {}
+
+func (x *PutScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutScheduleResponse.ProtoReflect.Descriptor instead.
+func (*PutScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *PutScheduleResponse) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *PutScheduleResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+type ListSchedulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesRequest) Reset() {
+	*x = ListSchedulesRequest{}
+	mi := &file_jobby_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesRequest) ProtoMessage() {}
+
+func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{76}
+}
+
+type ListSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*Schedule            `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesResponse) Reset() {
+	*x = ListSchedulesResponse{}
+	mi := &file_jobby_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesResponse) ProtoMessage() {}
+
+func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *ListSchedulesResponse) GetSchedules() []*Schedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+type DeleteScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleRequest) Reset() {
+	*x = DeleteScheduleRequest{}
+	mi := &file_jobby_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleRequest) ProtoMessage() {}
+
+func (x *DeleteScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *DeleteScheduleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleResponse) Reset() {
+	*x = DeleteScheduleResponse{}
+	mi := &file_jobby_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleResponse) ProtoMessage() {}
+
+func (x *DeleteScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{79}
+}
+
+type ListScheduleRunsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// How many upcoming runs to list, up to 100. 0 lists 10
+	Upcoming      uint32 `protobuf:"varint,2,opt,name=upcoming,proto3" json:"upcoming,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScheduleRunsRequest) Reset() {
+	*x = ListScheduleRunsRequest{}
+	mi := &file_jobby_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScheduleRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScheduleRunsRequest) ProtoMessage() {}
+
+func (x *ListScheduleRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScheduleRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListScheduleRunsRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *ListScheduleRunsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ListScheduleRunsRequest) GetUpcoming() uint32 {
+	if x != nil {
+		return x.Upcoming
+	}
+	return 0
 }
 
-type StartJobRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type ListScheduleRunsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first. Only the last 50 runs are kept
+	Past []*ScheduleRun `protobuf:"bytes,1,rep,name=past,proto3" json:"past,omitempty"`
+	// Soonest first, including runs that are overdue but not started yet
+	// (ex: waiting for the run before them to finish)
+	Upcoming      []*ScheduleRun `protobuf:"bytes,2,rep,name=upcoming,proto3" json:"upcoming,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Command string   `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
-	Args    []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StartJobRequest) Reset() {
-	*x = StartJobRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_jobby_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *ListScheduleRunsResponse) Reset() {
+	*x = ListScheduleRunsResponse{}
+	mi := &file_jobby_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *StartJobRequest) String() string {
+func (x *ListScheduleRunsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartJobRequest) ProtoMessage() {}
+func (*ListScheduleRunsResponse) ProtoMessage() {}
 
-func (x *StartJobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_jobby_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *ListScheduleRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[81]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -160,51 +6511,48 @@ func (x *StartJobRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartJobRequest.ProtoReflect.Descriptor instead.
-func (*StartJobRequest) Descriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use ListScheduleRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListScheduleRunsResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{81}
 }
 
-func (x *StartJobRequest) GetCommand() string {
+func (x *ListScheduleRunsResponse) GetPast() []*ScheduleRun {
 	if x != nil {
-		return x.Command
+		return x.Past
 	}
-	return ""
+	return nil
 }
 
-func (x *StartJobRequest) GetArgs() []string {
+func (x *ListScheduleRunsResponse) GetUpcoming() []*ScheduleRun {
 	if x != nil {
-		return x.Args
+		return x.Upcoming
 	}
 	return nil
 }
 
-type StartJobResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type PreviewTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Template      *TemplateRef           `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	JobId []byte `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StartJobResponse) Reset() {
-	*x = StartJobResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_jobby_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *PreviewTemplateRequest) Reset() {
+	*x = PreviewTemplateRequest{}
+	mi := &file_jobby_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *StartJobResponse) String() string {
+func (x *PreviewTemplateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartJobResponse) ProtoMessage() {}
+func (*PreviewTemplateRequest) ProtoMessage() {}
 
-func (x *StartJobResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_jobby_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *PreviewTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[82]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -214,44 +6562,46 @@ func (x *StartJobResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartJobResponse.ProtoReflect.Descriptor instead.
-func (*StartJobResponse) Descriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use PreviewTemplateRequest.ProtoReflect.Descriptor instead.
+func (*PreviewTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{82}
 }
 
-func (x *StartJobResponse) GetJobId() []byte {
+func (x *PreviewTemplateRequest) GetTemplate() *TemplateRef {
 	if x != nil {
-		return x.JobId
+		return x.Template
 	}
 	return nil
 }
 
-type StopJobRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type PreviewTemplateResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Command string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Args    []string               `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// Every parameter's value, as filled in. Includes defaults
+	Params map[string]string `protobuf:"bytes,3,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The operator's description of the template
+	Description   string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	JobId []byte `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StopJobRequest) Reset() {
-	*x = StopJobRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_jobby_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *PreviewTemplateResponse) Reset() {
+	*x = PreviewTemplateResponse{}
+	mi := &file_jobby_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *StopJobRequest) String() string {
+func (x *PreviewTemplateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopJobRequest) ProtoMessage() {}
+func (*PreviewTemplateResponse) ProtoMessage() {}
 
-func (x *StopJobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_jobby_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *PreviewTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[83]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -261,42 +6611,65 @@ func (x *StopJobRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopJobRequest.ProtoReflect.Descriptor instead.
-func (*StopJobRequest) Descriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use PreviewTemplateResponse.ProtoReflect.Descriptor instead.
+func (*PreviewTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{83}
 }
 
-func (x *StopJobRequest) GetJobId() []byte {
+func (x *PreviewTemplateResponse) GetCommand() string {
 	if x != nil {
-		return x.JobId
+		return x.Command
+	}
+	return ""
+}
+
+func (x *PreviewTemplateResponse) GetArgs() []string {
+	if x != nil {
+		return x.Args
 	}
 	return nil
 }
 
-type StopJobResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *PreviewTemplateResponse) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
 }
 
-func (x *StopJobResponse) Reset() {
-	*x = StopJobResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_jobby_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *PreviewTemplateResponse) GetDescription() string {
+	if x != nil {
+		return x.Description
 	}
+	return ""
 }
 
-func (x *StopJobResponse) String() string {
+type CheckOutputDirectoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Remove the orphaned files (and scratch directories) found. Ones
+	// modified in the last hour are left, as they may belong to jobs
+	// that are starting
+	RemoveOrphans bool `protobuf:"varint,1,opt,name=remove_orphans,json=removeOrphans,proto3" json:"remove_orphans,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckOutputDirectoryRequest) Reset() {
+	*x = CheckOutputDirectoryRequest{}
+	mi := &file_jobby_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckOutputDirectoryRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopJobResponse) ProtoMessage() {}
+func (*CheckOutputDirectoryRequest) ProtoMessage() {}
 
-func (x *StopJobResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_jobby_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *CheckOutputDirectoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[84]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -306,37 +6679,50 @@ func (x *StopJobResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopJobResponse.ProtoReflect.Descriptor instead.
-func (*StopJobResponse) Descriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use CheckOutputDirectoryRequest.ProtoReflect.Descriptor instead.
+func (*CheckOutputDirectoryRequest) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{84}
 }
 
-type GetStatusRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *CheckOutputDirectoryRequest) GetRemoveOrphans() bool {
+	if x != nil {
+		return x.RemoveOrphans
+	}
+	return false
+}
 
-	JobId []byte `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+// A file (or scratch directory) in the output directory
+type OutputDirectoryEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name within the output directory
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// On disk. Includes everything within scratch directories
+	SizeBytes uint64 `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// The job the name is for, if it's named like output. Empty otherwise
+	JobId    string                 `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Modified *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=modified,proto3" json:"modified,omitempty"`
+	// Whether remove_orphans removed it
+	Removed       bool `protobuf:"varint,5,opt,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStatusRequest) Reset() {
-	*x = GetStatusRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_jobby_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *OutputDirectoryEntry) Reset() {
+	*x = OutputDirectoryEntry{}
+	mi := &file_jobby_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStatusRequest) String() string {
+func (x *OutputDirectoryEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStatusRequest) ProtoMessage() {}
+func (*OutputDirectoryEntry) ProtoMessage() {}
 
-func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_jobby_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *OutputDirectoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[85]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -346,46 +6732,73 @@ func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
-func (*GetStatusRequest) Descriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use OutputDirectoryEntry.ProtoReflect.Descriptor instead.
+func (*OutputDirectoryEntry) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{85}
 }
 
-func (x *GetStatusRequest) GetJobId() []byte {
+func (x *OutputDirectoryEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OutputDirectoryEntry) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *OutputDirectoryEntry) GetJobId() string {
 	if x != nil {
 		return x.JobId
 	}
+	return ""
+}
+
+func (x *OutputDirectoryEntry) GetModified() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Modified
+	}
 	return nil
 }
 
-type GetStatusResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *OutputDirectoryEntry) GetRemoved() bool {
+	if x != nil {
+		return x.Removed
+	}
+	return false
+}
 
-	CurrentStatus Status `protobuf:"varint,1,opt,name=current_status,json=currentStatus,proto3,enum=jobby.Status" json:"current_status,omitempty"`
-	// available when status is "COMPLETE"
-	ExitCode *int32 `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+// Output a user's jobs keep on disk
+type UserDiskUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Bytes         uint64                 `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Files         uint32                 `protobuf:"varint,3,opt,name=files,proto3" json:"files,omitempty"`
+	Jobs          uint32                 `protobuf:"varint,4,opt,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStatusResponse) Reset() {
-	*x = GetStatusResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_jobby_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *UserDiskUsage) Reset() {
+	*x = UserDiskUsage{}
+	mi := &file_jobby_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStatusResponse) String() string {
+func (x *UserDiskUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStatusResponse) ProtoMessage() {}
+func (*UserDiskUsage) ProtoMessage() {}
 
-func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_jobby_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *UserDiskUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[86]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -395,52 +6808,66 @@ func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
-func (*GetStatusResponse) Descriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use UserDiskUsage.ProtoReflect.Descriptor instead.
+func (*UserDiskUsage) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{86}
 }
 
-func (x *GetStatusResponse) GetCurrentStatus() Status {
+func (x *UserDiskUsage) GetUser() string {
 	if x != nil {
-		return x.CurrentStatus
+		return x.User
 	}
-	return Status_STATUS_UNSPECIFIED
+	return ""
 }
 
-func (x *GetStatusResponse) GetExitCode() int32 {
-	if x != nil && x.ExitCode != nil {
-		return *x.ExitCode
+func (x *UserDiskUsage) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
 	}
 	return 0
 }
 
-type GetJobOutputRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	JobId []byte     `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	Type  OutputType `protobuf:"varint,2,opt,name=type,proto3,enum=jobby.OutputType" json:"type,omitempty"`
+func (x *UserDiskUsage) GetFiles() uint32 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
 }
 
-func (x *GetJobOutputRequest) Reset() {
-	*x = GetJobOutputRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_jobby_proto_msgTypes[6]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *UserDiskUsage) GetJobs() uint32 {
+	if x != nil {
+		return x.Jobs
 	}
+	return 0
 }
 
-func (x *GetJobOutputRequest) String() string {
+// Output a job should have on disk that isn't there
+type MissingOutput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Owner string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Name within the output directory
+	Name          string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MissingOutput) Reset() {
+	*x = MissingOutput{}
+	mi := &file_jobby_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MissingOutput) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetJobOutputRequest) ProtoMessage() {}
+func (*MissingOutput) ProtoMessage() {}
 
-func (x *GetJobOutputRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_jobby_proto_msgTypes[6]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *MissingOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[87]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -450,52 +6877,67 @@ func (x *GetJobOutputRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetJobOutputRequest.ProtoReflect.Descriptor instead.
-func (*GetJobOutputRequest) Descriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use MissingOutput.ProtoReflect.Descriptor instead.
+func (*MissingOutput) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{87}
 }
 
-func (x *GetJobOutputRequest) GetJobId() []byte {
+func (x *MissingOutput) GetJobId() string {
 	if x != nil {
 		return x.JobId
 	}
-	return nil
+	return ""
 }
 
-func (x *GetJobOutputRequest) GetType() OutputType {
+func (x *MissingOutput) GetOwner() string {
 	if x != nil {
-		return x.Type
+		return x.Owner
 	}
-	return OutputType_OUTPUT_TYPE_UNSPECIFIED
+	return ""
 }
 
-type GetJobOutputResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *MissingOutput) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
 
-	// A chunk of output data from the job
-	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+type CheckOutputDirectoryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Named like a job's output, but no job the server knows of has it.
+	// Files of jobs from before the server restarted aren't orphans while
+	// the metadata store keeps their records
+	Orphans []*OutputDirectoryEntry `protobuf:"bytes,1,rep,name=orphans,proto3" json:"orphans,omitempty"`
+	// Not named like output (ex: the event log). Never removed
+	Unrecognized []*OutputDirectoryEntry `protobuf:"bytes,2,rep,name=unrecognized,proto3" json:"unrecognized,omitempty"`
+	Missing      []*MissingOutput        `protobuf:"bytes,3,rep,name=missing,proto3" json:"missing,omitempty"`
+	// By user
+	Usage         []*UserDiskUsage `protobuf:"bytes,4,rep,name=usage,proto3" json:"usage,omitempty"`
+	OrphanedBytes uint64           `protobuf:"varint,5,opt,name=orphaned_bytes,json=orphanedBytes,proto3" json:"orphaned_bytes,omitempty"`
+	RemovedBytes  uint64           `protobuf:"varint,6,opt,name=removed_bytes,json=removedBytes,proto3" json:"removed_bytes,omitempty"`
+	// Everything in the output directory
+	TotalBytes    uint64 `protobuf:"varint,7,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetJobOutputResponse) Reset() {
-	*x = GetJobOutputResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_jobby_proto_msgTypes[7]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *CheckOutputDirectoryResponse) Reset() {
+	*x = CheckOutputDirectoryResponse{}
+	mi := &file_jobby_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetJobOutputResponse) String() string {
+func (x *CheckOutputDirectoryResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetJobOutputResponse) ProtoMessage() {}
+func (*CheckOutputDirectoryResponse) ProtoMessage() {}
 
-func (x *GetJobOutputResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_jobby_proto_msgTypes[7]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *CheckOutputDirectoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobby_proto_msgTypes[88]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -505,128 +6947,1001 @@ func (x *GetJobOutputResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetJobOutputResponse.ProtoReflect.Descriptor instead.
-func (*GetJobOutputResponse) Descriptor() ([]byte, []int) {
-	return file_jobby_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use CheckOutputDirectoryResponse.ProtoReflect.Descriptor instead.
+func (*CheckOutputDirectoryResponse) Descriptor() ([]byte, []int) {
+	return file_jobby_proto_rawDescGZIP(), []int{88}
 }
 
-func (x *GetJobOutputResponse) GetData() []byte {
+func (x *CheckOutputDirectoryResponse) GetOrphans() []*OutputDirectoryEntry {
 	if x != nil {
-		return x.Data
+		return x.Orphans
 	}
 	return nil
 }
 
-var File_jobby_proto protoreflect.FileDescriptor
+func (x *CheckOutputDirectoryResponse) GetUnrecognized() []*OutputDirectoryEntry {
+	if x != nil {
+		return x.Unrecognized
+	}
+	return nil
+}
+
+func (x *CheckOutputDirectoryResponse) GetMissing() []*MissingOutput {
+	if x != nil {
+		return x.Missing
+	}
+	return nil
+}
+
+func (x *CheckOutputDirectoryResponse) GetUsage() []*UserDiskUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *CheckOutputDirectoryResponse) GetOrphanedBytes() uint64 {
+	if x != nil {
+		return x.OrphanedBytes
+	}
+	return 0
+}
+
+func (x *CheckOutputDirectoryResponse) GetRemovedBytes() uint64 {
+	if x != nil {
+		return x.RemovedBytes
+	}
+	return 0
+}
 
-var file_jobby_proto_rawDesc = []byte{
-	0x0a, 0x0b, 0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x6a,
-	0x6f, 0x62, 0x62, 0x79, 0x22, 0x3f, 0x0a, 0x0f, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4a, 0x6f, 0x62,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
-	0x04, 0x61, 0x72, 0x67, 0x73, 0x22, 0x29, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4a, 0x6f,
-	0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64,
-	0x22, 0x27, 0x0a, 0x0e, 0x53, 0x74, 0x6f, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x22, 0x11, 0x0a, 0x0f, 0x53, 0x74, 0x6f,
-	0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x29, 0x0a, 0x10,
-	0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x22, 0x79, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x0e,
-	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x0d, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x12, 0x20, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64,
-	0x65, 0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f,
-	0x64, 0x65, 0x22, 0x53, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x4f, 0x75, 0x74, 0x70,
-	0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64,
-	0x12, 0x25, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11,
-	0x2e, 0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x54, 0x79, 0x70,
-	0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x2a, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4a, 0x6f,
-	0x62, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
-	0x61, 0x74, 0x61, 0x2a, 0x5d, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a,
-	0x12, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46,
-	0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
-	0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41,
-	0x54, 0x55, 0x53, 0x5f, 0x53, 0x54, 0x4f, 0x50, 0x50, 0x45, 0x44, 0x10, 0x02, 0x12, 0x13, 0x0a,
-	0x0f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45,
-	0x10, 0x03, 0x2a, 0x59, 0x0a, 0x0a, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x54, 0x79, 0x70, 0x65,
-	0x12, 0x1b, 0x0a, 0x17, 0x4f, 0x55, 0x54, 0x50, 0x55, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
-	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a,
-	0x12, 0x4f, 0x55, 0x54, 0x50, 0x55, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x54, 0x44,
-	0x4f, 0x55, 0x54, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x4f, 0x55, 0x54, 0x50, 0x55, 0x54, 0x5f,
-	0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x54, 0x44, 0x45, 0x52, 0x52, 0x10, 0x02, 0x32, 0x96, 0x02,
-	0x0a, 0x0a, 0x4a, 0x6f, 0x62, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x12, 0x3d, 0x0a, 0x08,
-	0x53, 0x74, 0x61, 0x72, 0x74, 0x4a, 0x6f, 0x62, 0x12, 0x16, 0x2e, 0x6a, 0x6f, 0x62, 0x62, 0x79,
-	0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x17, 0x2e, 0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4a, 0x6f,
-	0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x07, 0x53,
-	0x74, 0x6f, 0x70, 0x4a, 0x6f, 0x62, 0x12, 0x15, 0x2e, 0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x53,
-	0x74, 0x6f, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
-	0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x12, 0x17, 0x2e, 0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x47, 0x65, 0x74,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
-	0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4b, 0x0a, 0x0c, 0x47, 0x65, 0x74,
-	0x4a, 0x6f, 0x62, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x1a, 0x2e, 0x6a, 0x6f, 0x62, 0x62,
-	0x79, 0x2e, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6a, 0x6f, 0x62, 0x62, 0x79, 0x2e, 0x47, 0x65,
-	0x74, 0x4a, 0x6f, 0x62, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x79, 0x61, 0x6e, 0x2f, 0x6a,
-	0x6f, 0x62, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+func (x *CheckOutputDirectoryResponse) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
 }
 
+var File_jobby_proto protoreflect.FileDescriptor
+
+const file_jobby_proto_rawDesc = "" +
+	"\n" +
+	"\vjobby.proto\x12\x05jobby\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x80\v\n" +
+	"\aJobSpec\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x12)\n" +
+	"\x03env\x18\x03 \x03(\v2\x17.jobby.JobSpec.EnvEntryR\x03env\x12!\n" +
+	"\fmax_attempts\x18\x04 \x01(\rR\vmaxAttempts\x124\n" +
+	"\tretention\x18\x05 \x01(\v2\x16.jobby.RetentionPolicyR\tretention\x12#\n" +
+	"\rruntime_class\x18\x06 \x01(\tR\fruntimeClass\x122\n" +
+	"\x06labels\x18\a \x03(\v2\x1a.jobby.JobSpec.LabelsEntryR\x06labels\x123\n" +
+	"\atimeout\x18\b \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12#\n" +
+	"\regress_policy\x18\t \x01(\tR\fegressPolicy\x12\x12\n" +
+	"\x04gpus\x18\n" +
+	" \x03(\rR\x04gpus\x121\n" +
+	"\n" +
+	"scheduling\x18\v \x01(\v2\x11.jobby.SchedulingR\n" +
+	"scheduling\x12\x1a\n" +
+	"\bpriority\x18\f \x01(\x05R\bpriority\x122\n" +
+	"\x15requeue_on_preemption\x18\r \x01(\bR\x13requeueOnPreemption\x12.\n" +
+	"\x13output_window_bytes\x18\x0e \x01(\x04R\x11outputWindowBytes\x12=\n" +
+	"\x0foutput_segments\x18\x0f \x01(\v2\x14.jobby.SegmentPolicyR\x0eoutputSegments\x12%\n" +
+	"\x0etrack_progress\x18\x10 \x01(\bR\rtrackProgress\x12\x16\n" +
+	"\x06public\x18\x11 \x01(\bR\x06public\x12.\n" +
+	"\x13output_content_type\x18\x12 \x01(\tR\x11outputContentType\x12;\n" +
+	"\x0fexit_code_rules\x18\x13 \x03(\v2\x13.jobby.ExitCodeRuleR\rexitCodeRules\x12\x14\n" +
+	"\x05shell\x18\x14 \x01(\tR\x05shell\x12D\n" +
+	"\x10expected_runtime\x18\x15 \x01(\v2\x19.google.protobuf.DurationR\x0fexpectedRuntime\x12/\n" +
+	"\x13capture_environment\x18\x16 \x01(\bR\x12captureEnvironment\x12)\n" +
+	"\x10timestamp_output\x18\x17 \x01(\bR\x0ftimestampOutput\x12\x14\n" +
+	"\x05stdin\x18\x18 \x01(\bR\x05stdin\x12/\n" +
+	"\x05lease\x18\x19 \x01(\v2\x19.google.protobuf.DurationR\x05lease\x12\x1d\n" +
+	"\n" +
+	"child_jobs\x18\x1a \x01(\bR\tchildJobs\x127\n" +
+	"\ftoken_scopes\x18\x1b \x03(\x0e2\x14.jobby.JobTokenScopeR\vtokenScopes\x12(\n" +
+	"\ascratch\x18\x1c \x01(\v2\x0e.jobby.ScratchR\ascratch\x12.\n" +
+	"\btemplate\x18\x1d \x01(\v2\x12.jobby.TemplateRefR\btemplate\x12\x14\n" +
+	"\x05mutex\x18\x1e \x01(\tR\x05mutex\x12$\n" +
+	"\x0ewait_for_mutex\x18\x1f \x01(\bR\fwaitForMutex\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x94\x01\n" +
+	"\vTemplateRef\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x126\n" +
+	"\x06params\x18\x02 \x03(\v2\x1e.jobby.TemplateRef.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"*\n" +
+	"\aScratch\x12\x1f\n" +
+	"\vtmpfs_bytes\x18\x01 \x01(\x04R\n" +
+	"tmpfsBytes\"\x8e\x01\n" +
+	"\n" +
+	"Scheduling\x12\x17\n" +
+	"\x04nice\x18\x01 \x01(\x05H\x00R\x04nice\x88\x01\x01\x12)\n" +
+	"\bio_class\x18\x02 \x01(\x0e2\x0e.jobby.IOClassR\aioClass\x12\x1f\n" +
+	"\vio_priority\x18\x03 \x01(\rR\n" +
+	"ioPriority\x12\x12\n" +
+	"\x04cpus\x18\x04 \x03(\rR\x04cpusB\a\n" +
+	"\x05_nice\"c\n" +
+	"\rSegmentPolicy\x12\x1b\n" +
+	"\tmax_bytes\x18\x01 \x01(\x04R\bmaxBytes\x125\n" +
+	"\binterval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\binterval\"N\n" +
+	"\fExitCodeRule\x12\x14\n" +
+	"\x05codes\x18\x01 \x03(\x05R\x05codes\x12(\n" +
+	"\aoutcome\x18\x02 \x01(\x0e2\x0e.jobby.OutcomeR\aoutcome\"\xe2\x02\n" +
+	"\x0fStartJobRequest\x12\x1c\n" +
+	"\acommand\x18\x01 \x01(\tB\x02\x18\x01R\acommand\x12\x16\n" +
+	"\x04args\x18\x02 \x03(\tB\x02\x18\x01R\x04args\x12%\n" +
+	"\fmax_attempts\x18\x03 \x01(\rB\x02\x18\x01R\vmaxAttempts\x128\n" +
+	"\tretention\x18\x04 \x01(\v2\x16.jobby.RetentionPolicyB\x02\x18\x01R\tretention\x12'\n" +
+	"\rruntime_class\x18\x05 \x01(\tB\x02\x18\x01R\fruntimeClass\x12\"\n" +
+	"\x04spec\x18\x06 \x01(\v2\x0e.jobby.JobSpecR\x04spec\x12\x14\n" +
+	"\x05force\x18\a \x01(\bR\x05force\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\b \x01(\tR\tsessionId\x126\n" +
+	"\tcache_ttl\x18\t \x01(\v2\x19.google.protobuf.DurationR\bcacheTtl\"o\n" +
+	"\x0fRetentionPolicy\x12-\n" +
+	"\x03ttl\x18\x01 \x01(\v2\x19.google.protobuf.DurationH\x00R\x03ttl\x12#\n" +
+	"\fkeep_forever\x18\x02 \x01(\bH\x00R\vkeepForeverB\b\n" +
+	"\x06policy\"m\n" +
+	"\x10StartJobResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1a\n" +
+	"\bwarnings\x18\x03 \x03(\tR\bwarnings\x12\x16\n" +
+	"\x06cached\x18\x04 \x01(\bR\x06cached\"7\n" +
+	"\x0eStopJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\x11\n" +
+	"\x0fStopJobResponse\"9\n" +
+	"\x10GetStatusRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"7\n" +
+	"\x0eWaitJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xfe\x04\n" +
+	"\x11GetStatusResponse\x124\n" +
+	"\x0ecurrent_status\x18\x01 \x01(\x0e2\r.jobby.StatusR\rcurrentStatus\x12 \n" +
+	"\texit_code\x18\x02 \x01(\x05H\x00R\bexitCode\x88\x01\x01\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12%\n" +
+	"\x0equota_exceeded\x18\x04 \x01(\bR\rquotaExceeded\x12\x1b\n" +
+	"\ttimed_out\x18\x05 \x01(\bR\btimedOut\x122\n" +
+	"\vexit_reason\x18\x06 \x01(\x0e2\x11.jobby.ExitReasonR\n" +
+	"exitReason\x12\x16\n" +
+	"\x06signal\x18\a \x01(\tR\x06signal\x12\x16\n" +
+	"\x06queued\x18\b \x01(\bR\x06queued\x12+\n" +
+	"\bprogress\x18\t \x01(\v2\x0f.jobby.ProgressR\bprogress\x12\x10\n" +
+	"\x03pid\x18\n" +
+	" \x01(\x05R\x03pid\x12/\n" +
+	"\tprocesses\x18\v \x03(\v2\x11.jobby.JobProcessR\tprocesses\x12.\n" +
+	"\x13output_content_type\x18\f \x01(\tR\x11outputContentType\x12(\n" +
+	"\aoutcome\x18\r \x01(\x0e2\x0e.jobby.OutcomeR\aoutcome\x125\n" +
+	"\fstate_reason\x18\x0e \x01(\x0e2\x12.jobby.StateReasonR\vstateReason\x12#\n" +
+	"\rstate_message\x18\x0f \x01(\tR\fstateMessageB\f\n" +
+	"\n" +
+	"_exit_code\"L\n" +
+	"\n" +
+	"JobProcess\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x12\n" +
+	"\x04ppid\x18\x02 \x01(\x05R\x04ppid\x12\x18\n" +
+	"\acommand\x18\x03 \x01(\tR\acommand\"n\n" +
+	"\bProgress\x12\x18\n" +
+	"\apercent\x18\x01 \x01(\x01R\apercent\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\xf6\x03\n" +
+	"\x13GetJobOutputRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12%\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x11.jobby.OutputTypeR\x04type\x12\x18\n" +
+	"\aattempt\x18\x03 \x01(\rR\aattempt\x12&\n" +
+	"\x0fbatch_max_bytes\x18\x04 \x01(\rR\rbatchMaxBytes\x12A\n" +
+	"\x0fbatch_max_delay\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\rbatchMaxDelay\x126\n" +
+	"\x17collapse_repeated_lines\x18\x06 \x01(\bR\x15collapseRepeatedLines\x12%\n" +
+	"\x04mode\x18\a \x01(\x0e2\x11.jobby.StreamModeR\x04mode\x12=\n" +
+	"\rline_max_hold\x18\b \x01(\v2\x19.google.protobuf.DurationR\vlineMaxHold\x12\x0e\n" +
+	"\x02id\x18\t \x01(\tR\x02id\x12\x1b\n" +
+	"\tno_follow\x18\n" +
+	" \x01(\bR\bnoFollow\x12)\n" +
+	"\x10strip_timestamps\x18\v \x01(\bR\x0fstripTimestamps\x12&\n" +
+	"\x05range\x18\f \x01(\v2\x10.jobby.ByteRangeR\x05range\"3\n" +
+	"\tByteRange\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x04R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x04R\x03end\"N\n" +
+	"\x14GetJobOutputResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\"\n" +
+	"\x03end\x18\x02 \x01(\v2\x10.jobby.OutputEndR\x03end\"D\n" +
+	"\tOutputEnd\x12\x1f\n" +
+	"\vtotal_bytes\x18\x01 \x01(\x04R\n" +
+	"totalBytes\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\fR\x06sha256\"=\n" +
+	"\x14GetJobHistoryRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xc2\x04\n" +
+	"\aAttempt\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\rR\x06number\x12%\n" +
+	"\x06status\x18\x02 \x01(\x0e2\r.jobby.StatusR\x06status\x12 \n" +
+	"\texit_code\x18\x03 \x01(\x05H\x00R\bexitCode\x88\x01\x01\x129\n" +
+	"\n" +
+	"start_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12!\n" +
+	"\fstdout_bytes\x18\x06 \x01(\x04R\vstdoutBytes\x12!\n" +
+	"\fstderr_bytes\x18\a \x01(\x04R\vstderrBytes\x12\x1f\n" +
+	"\vstderr_tail\x18\b \x01(\fR\n" +
+	"stderrTail\x125\n" +
+	"\bduration\x18\t \x01(\v2\x19.google.protobuf.DurationR\bduration\x12%\n" +
+	"\x0equota_exceeded\x18\n" +
+	" \x01(\bR\rquotaExceeded\x12\x1b\n" +
+	"\ttimed_out\x18\v \x01(\bR\btimedOut\x122\n" +
+	"\vexit_reason\x18\f \x01(\x0e2\x11.jobby.ExitReasonR\n" +
+	"exitReason\x12\x16\n" +
+	"\x06signal\x18\r \x01(\tR\x06signal\x12(\n" +
+	"\aoutcome\x18\x0e \x01(\x0e2\x0e.jobby.OutcomeR\aoutcomeB\f\n" +
+	"\n" +
+	"_exit_code\"C\n" +
+	"\x15GetJobHistoryResponse\x12*\n" +
+	"\battempts\x18\x01 \x03(\v2\x0e.jobby.AttemptR\battempts\"\x13\n" +
+	"\x11ExportJobsRequest\"\xdf\x06\n" +
+	"\tJobRecord\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x18\n" +
+	"\acommand\x18\x02 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x03 \x03(\tR\x04args\x12%\n" +
+	"\x06status\x18\x04 \x01(\x0e2\r.jobby.StatusR\x06status\x12 \n" +
+	"\texit_code\x18\x05 \x01(\x05H\x00R\bexitCode\x88\x01\x01\x129\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1a\n" +
+	"\battempts\x18\b \x01(\rR\battempts\x12!\n" +
+	"\fmax_attempts\x18\t \x01(\rR\vmaxAttempts\x125\n" +
+	"\bduration\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\bduration\x12#\n" +
+	"\rruntime_class\x18\v \x01(\tR\fruntimeClass\x12\"\n" +
+	"\x04spec\x18\f \x01(\v2\x0e.jobby.JobSpecR\x04spec\x12\x0e\n" +
+	"\x02id\x18\r \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x0e \x01(\tR\tsessionId\x12\x18\n" +
+	"\aadopted\x18\x0f \x01(\bR\aadopted\x12>\n" +
+	"\x0flaunch_snapshot\x18\x10 \x01(\v2\x15.jobby.LaunchSnapshotR\x0elaunchSnapshot\x125\n" +
+	"\fstate_reason\x18\x11 \x01(\x0e2\x12.jobby.StateReasonR\vstateReason\x12#\n" +
+	"\rstate_message\x18\x12 \x01(\tR\fstateMessage\x12\x1b\n" +
+	"\tparent_id\x18\x13 \x01(\tR\bparentId\x12C\n" +
+	"\vannotations\x18\x14 \x03(\v2!.jobby.JobRecord.AnnotationsEntryR\vannotations\x1a>\n" +
+	"\x10AnnotationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
+	"\n" +
+	"_exit_code\"\x94\x03\n" +
+	"\x0eLaunchSnapshot\x12;\n" +
+	"\vcaptured_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"capturedAt\x120\n" +
+	"\x03env\x18\x02 \x03(\v2\x1e.jobby.LaunchSnapshot.EnvEntryR\x03env\x12+\n" +
+	"\x11working_directory\x18\x03 \x01(\tR\x10workingDirectory\x12\x1f\n" +
+	"\vbinary_path\x18\x04 \x01(\tR\n" +
+	"binaryPath\x12#\n" +
+	"\rbinary_sha256\x18\x05 \x01(\tR\fbinarySha256\x12\x1a\n" +
+	"\bhostname\x18\x06 \x01(\tR\bhostname\x12%\n" +
+	"\x0ekernel_release\x18\a \x01(\tR\rkernelRelease\x12%\n" +
+	"\x0eserver_version\x18\b \x01(\tR\rserverVersion\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xac\x02\n" +
+	"\x0fListJobsRequest\x12)\n" +
+	"\x10command_contains\x18\x01 \x01(\tR\x0fcommandContains\x12?\n" +
+	"\rstarted_after\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\fstartedAfter\x12A\n" +
+	"\x0estarted_before\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\rstartedBefore\x12 \n" +
+	"\texit_code\x18\x04 \x01(\x05H\x00R\bexitCode\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x05 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tparent_id\x18\x06 \x01(\tR\bparentIdB\f\n" +
+	"\n" +
+	"_exit_code\"8\n" +
+	"\x10ListJobsResponse\x12$\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x10.jobby.JobRecordR\x04jobs\"\x16\n" +
+	"\x14GetServerInfoRequest\"\xab\x01\n" +
+	"\x15GetServerInfoResponse\x12\x1a\n" +
+	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x1e\n" +
+	"\x04gpus\x18\x02 \x03(\v2\n" +
+	".jobby.GPUR\x04gpus\x12&\n" +
+	"\x05build\x18\x03 \x01(\v2\x10.jobby.BuildInfoR\x05build\x12.\n" +
+	"\bfeatures\x18\x04 \x03(\v2\x12.jobby.FeatureFlagR\bfeatures\"\xb5\x01\n" +
+	"\tBuildInfo\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12;\n" +
+	"\vcommit_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"commitTime\x12\x1a\n" +
+	"\bmodified\x18\x04 \x01(\bR\bmodified\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x05 \x01(\tR\tgoVersion\"\x95\x01\n" +
+	"\vFeatureFlag\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\x12\"\n" +
+	"\fexperimental\x18\x04 \x01(\bR\fexperimental\x12\x12\n" +
+	"\x04rpcs\x18\x05 \x03(\tR\x04rpcs\"\\\n" +
+	"\x03GPU\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x12\n" +
+	"\x04uuid\x18\x02 \x01(\tR\x04uuid\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x15\n" +
+	"\x06bus_id\x18\x04 \x01(\tR\x05busId\"K\n" +
+	"\x16GetUsageSummaryRequest\x121\n" +
+	"\x06window\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x06window\"G\n" +
+	"\x17GetUsageSummaryResponse\x12,\n" +
+	"\awindows\x18\x01 \x03(\v2\x12.jobby.UsageWindowR\awindows\"k\n" +
+	"\vUsageWindow\x121\n" +
+	"\x06window\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x06window\x12)\n" +
+	"\x06owners\x18\x02 \x03(\v2\x11.jobby.OwnerUsageR\x06owners\"\xb6\x01\n" +
+	"\n" +
+	"OwnerUsage\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12!\n" +
+	"\fjobs_started\x18\x02 \x01(\x04R\vjobsStarted\x12+\n" +
+	"\x11attempts_finished\x18\x03 \x01(\x04R\x10attemptsFinished\x12\x1f\n" +
+	"\vcpu_seconds\x18\x04 \x01(\x01R\n" +
+	"cpuSeconds\x12!\n" +
+	"\fwall_seconds\x18\x05 \x01(\x01R\vwallSeconds\"<\n" +
+	"\x13GetJobEventsRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"?\n" +
+	"\x14GetJobEventsResponse\x12'\n" +
+	"\x06events\x18\x01 \x03(\v2\x0f.jobby.JobEventR\x06events\"\xab\x01\n" +
+	"\bJobEvent\x12'\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x13.jobby.JobEventTypeR\x04type\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x18\n" +
+	"\aattempt\x18\x04 \x01(\rR\aattempt\x12\x16\n" +
+	"\x06detail\x18\x05 \x01(\tR\x06detail\"\xe7\x01\n" +
+	"\x19ListOutputSegmentsRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12%\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x11.jobby.OutputTypeR\x04type\x12\x18\n" +
+	"\aattempt\x18\x04 \x01(\rR\aattempt\x120\n" +
+	"\x05since\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\"N\n" +
+	"\x1aListOutputSegmentsResponse\x120\n" +
+	"\bsegments\x18\x01 \x03(\v2\x14.jobby.OutputSegmentR\bsegments\"\xb8\x01\n" +
+	"\rOutputSegment\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\rR\x06number\x129\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x04R\tsizeBytes\"\x9b\x01\n" +
+	"\x17GetOutputSegmentRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12%\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x11.jobby.OutputTypeR\x04type\x12\x18\n" +
+	"\aattempt\x18\x04 \x01(\rR\aattempt\x12\x18\n" +
+	"\asegment\x18\x05 \x01(\rR\asegment\">\n" +
+	"\x15GetJobProgressRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"_\n" +
+	"\x16GetJobProgressResponse\x12\x18\n" +
+	"\aattempt\x18\x01 \x01(\rR\aattempt\x12+\n" +
+	"\bprogress\x18\x02 \x01(\v2\x0f.jobby.ProgressR\bprogress\"O\n" +
+	"\x11EndSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tstop_jobs\x18\x02 \x01(\bR\bstopJobs\"<\n" +
+	"\x12EndSessionResponse\x12&\n" +
+	"\x0fstopped_job_ids\x18\x01 \x03(\tR\rstoppedJobIds\"@\n" +
+	"\x17StreamServerLogsRequest\x12%\n" +
+	"\x05level\x18\x01 \x01(\x0e2\x0f.jobby.LogLevelR\x05level\"\x8d\x02\n" +
+	"\x0eServerLogEntry\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12%\n" +
+	"\x05level\x18\x02 \x01(\x0e2\x0f.jobby.LogLevelR\x05level\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x126\n" +
+	"\x05attrs\x18\x04 \x03(\v2 .jobby.ServerLogEntry.AttrsEntryR\x05attrs\x12\x18\n" +
+	"\adropped\x18\x05 \x01(\x04R\adropped\x1a8\n" +
+	"\n" +
+	"AttrsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"O\n" +
+	"\x10DeleteJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x14\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\"Z\n" +
+	"\x11DeleteJobResponse\x12E\n" +
+	"\x10restorable_until\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x0frestorableUntil\":\n" +
+	"\x11RestoreJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\x14\n" +
+	"\x12RestoreJobResponse\"\xfa\x01\n" +
+	"\x13AdoptProcessRequest\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x1f\n" +
+	"\vstdout_path\x18\x02 \x01(\tR\n" +
+	"stdoutPath\x12\x1f\n" +
+	"\vstderr_path\x18\x03 \x01(\tR\n" +
+	"stderrPath\x12\x14\n" +
+	"\x05owner\x18\x04 \x01(\tR\x05owner\x12>\n" +
+	"\x06labels\x18\x05 \x03(\v2&.jobby.AdoptProcessRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"=\n" +
+	"\x14AdoptProcessResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"U\n" +
+	"\x12GetJobStatsRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x18\n" +
+	"\acommand\x18\x03 \x01(\tR\acommand\"\xc2\x02\n" +
+	"\x13GetJobStatsResponse\x12\x12\n" +
+	"\x04runs\x18\x01 \x01(\rR\x04runs\x127\n" +
+	"\bduration\x18\x02 \x01(\v2\x1b.jobby.DurationDistributionR\bduration\x12:\n" +
+	"\foutput_bytes\x18\x03 \x01(\v2\x17.jobby.SizeDistributionR\voutputBytes\x12H\n" +
+	"\n" +
+	"exit_codes\x18\x04 \x03(\v2).jobby.GetJobStatsResponse.ExitCodesEntryR\texitCodes\x12\x1a\n" +
+	"\bsignaled\x18\x05 \x01(\rR\bsignaled\x1a<\n" +
+	"\x0eExitCodesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\rR\x05value:\x028\x01\"\xff\x01\n" +
+	"\x14DurationDistribution\x12+\n" +
+	"\x03min\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x03min\x121\n" +
+	"\x06median\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x06median\x12+\n" +
+	"\x03p90\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x03p90\x12+\n" +
+	"\x03max\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03max\x12-\n" +
+	"\x04mean\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x04mean\"t\n" +
+	"\x10SizeDistribution\x12\x10\n" +
+	"\x03min\x18\x01 \x01(\x04R\x03min\x12\x16\n" +
+	"\x06median\x18\x02 \x01(\x04R\x06median\x12\x10\n" +
+	"\x03p90\x18\x03 \x01(\x04R\x03p90\x12\x10\n" +
+	"\x03max\x18\x04 \x01(\x04R\x03max\x12\x12\n" +
+	"\x04mean\x18\x05 \x01(\x04R\x04mean\";\n" +
+	"\x12DescribeJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xa8\x02\n" +
+	"\x13DescribeJobResponse\x12(\n" +
+	"\x06record\x18\x01 \x01(\v2\x10.jobby.JobRecordR\x06record\x120\n" +
+	"\x06status\x18\x02 \x01(\v2\x18.jobby.GetStatusResponseR\x06status\x12*\n" +
+	"\battempts\x18\x03 \x03(\v2\x0e.jobby.AttemptR\battempts\x12'\n" +
+	"\x06events\x18\x04 \x03(\v2\x0f.jobby.JobEventR\x06events\x121\n" +
+	"\aoutputs\x18\x05 \x03(\v2\x17.jobby.OutputDescriptorR\aoutputs\x12-\n" +
+	"\x05usage\x18\x06 \x01(\v2\x17.jobby.JobResourceUsageR\x05usage\"\xb5\x01\n" +
+	"\x10OutputDescriptor\x12%\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x11.jobby.OutputTypeR\x04type\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x04R\x05bytes\x12\x14\n" +
+	"\x05files\x18\x03 \x01(\rR\x05files\x12\x1c\n" +
+	"\tsegmented\x18\x04 \x01(\bR\tsegmented\x12\x1c\n" +
+	"\tencrypted\x18\x05 \x01(\bR\tencrypted\x12\x12\n" +
+	"\x04sink\x18\x06 \x01(\bR\x04sink\"\xa3\x01\n" +
+	"\x10JobResourceUsage\x124\n" +
+	"\bcpu_time\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\acpuTime\x126\n" +
+	"\twall_time\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bwallTime\x12!\n" +
+	"\foutput_bytes\x18\x03 \x01(\x04R\voutputBytes\"Q\n" +
+	"\x14WriteJobStdinRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"<\n" +
+	"\x15WriteJobStdinResponse\x12#\n" +
+	"\rbytes_written\x18\x01 \x01(\x04R\fbytesWritten\"=\n" +
+	"\x14RenewJobLeaseRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"R\n" +
+	"\x15RenewJobLeaseResponse\x129\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"u\n" +
+	"\x18ReportJobProgressRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x18\n" +
+	"\apercent\x18\x03 \x01(\x01R\apercent\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\x1b\n" +
+	"\x19ReportJobProgressResponse\"\xc9\x01\n" +
+	"\x12AnnotateJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\fR\x05jobId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12L\n" +
+	"\vannotations\x18\x03 \x03(\v2*.jobby.AnnotateJobRequest.AnnotationsEntryR\vannotations\x1a>\n" +
+	"\x10AnnotationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x15\n" +
+	"\x13AnnotateJobResponse\"\xeb\x02\n" +
+	"\bSchedule\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04cron\x18\x02 \x01(\tR\x04cron\x12\"\n" +
+	"\x04spec\x18\x03 \x01(\v2\x0e.jobby.JobSpecR\x04spec\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x125\n" +
+	"\blast_run\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\alastRun\x125\n" +
+	"\bnext_run\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\anextRun\x127\n" +
+	"\vmissed_runs\x18\a \x01(\x0e2\x16.jobby.MissedRunPolicyR\n" +
+	"missedRuns\x121\n" +
+	"\x06jitter\x18\b \x01(\v2\x19.google.protobuf.DurationR\x06jitter\"\x89\x02\n" +
+	"\vScheduleRun\x12?\n" +
+	"\rscheduled_for\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\fscheduledFor\x125\n" +
+	"\bstart_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\astartAt\x129\n" +
+	"\n" +
+	"started_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12\x15\n" +
+	"\x06job_id\x18\x04 \x01(\tR\x05jobId\x12\x18\n" +
+	"\askipped\x18\x05 \x01(\tR\askipped\x12\x16\n" +
+	"\x06missed\x18\x06 \x01(\rR\x06missed\"A\n" +
+	"\x12PutScheduleRequest\x12+\n" +
+	"\bschedule\x18\x01 \x01(\v2\x0f.jobby.ScheduleR\bschedule\"\\\n" +
+	"\x13PutScheduleResponse\x12+\n" +
+	"\bschedule\x18\x01 \x01(\v2\x0f.jobby.ScheduleR\bschedule\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\bR\acreated\"\x16\n" +
+	"\x14ListSchedulesRequest\"F\n" +
+	"\x15ListSchedulesResponse\x12-\n" +
+	"\tschedules\x18\x01 \x03(\v2\x0f.jobby.ScheduleR\tschedules\"+\n" +
+	"\x15DeleteScheduleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x18\n" +
+	"\x16DeleteScheduleResponse\"I\n" +
+	"\x17ListScheduleRunsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bupcoming\x18\x02 \x01(\rR\bupcoming\"r\n" +
+	"\x18ListScheduleRunsResponse\x12&\n" +
+	"\x04past\x18\x01 \x03(\v2\x12.jobby.ScheduleRunR\x04past\x12.\n" +
+	"\bupcoming\x18\x02 \x03(\v2\x12.jobby.ScheduleRunR\bupcoming\"H\n" +
+	"\x16PreviewTemplateRequest\x12.\n" +
+	"\btemplate\x18\x01 \x01(\v2\x12.jobby.TemplateRefR\btemplate\"\xe8\x01\n" +
+	"\x17PreviewTemplateResponse\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x12B\n" +
+	"\x06params\x18\x03 \x03(\v2*.jobby.PreviewTemplateResponse.ParamsEntryR\x06params\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"D\n" +
+	"\x1bCheckOutputDirectoryRequest\x12%\n" +
+	"\x0eremove_orphans\x18\x01 \x01(\bR\rremoveOrphans\"\xb2\x01\n" +
+	"\x14OutputDirectoryEntry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x04R\tsizeBytes\x12\x15\n" +
+	"\x06job_id\x18\x03 \x01(\tR\x05jobId\x126\n" +
+	"\bmodified\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bmodified\x12\x18\n" +
+	"\aremoved\x18\x05 \x01(\bR\aremoved\"c\n" +
+	"\rUserDiskUsage\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x04R\x05bytes\x12\x14\n" +
+	"\x05files\x18\x03 \x01(\rR\x05files\x12\x12\n" +
+	"\x04jobs\x18\x04 \x01(\rR\x04jobs\"P\n" +
+	"\rMissingOutput\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"\xdf\x02\n" +
+	"\x1cCheckOutputDirectoryResponse\x125\n" +
+	"\aorphans\x18\x01 \x03(\v2\x1b.jobby.OutputDirectoryEntryR\aorphans\x12?\n" +
+	"\funrecognized\x18\x02 \x03(\v2\x1b.jobby.OutputDirectoryEntryR\funrecognized\x12.\n" +
+	"\amissing\x18\x03 \x03(\v2\x14.jobby.MissingOutputR\amissing\x12*\n" +
+	"\x05usage\x18\x04 \x03(\v2\x14.jobby.UserDiskUsageR\x05usage\x12%\n" +
+	"\x0eorphaned_bytes\x18\x05 \x01(\x04R\rorphanedBytes\x12#\n" +
+	"\rremoved_bytes\x18\x06 \x01(\x04R\fremovedBytes\x12\x1f\n" +
+	"\vtotal_bytes\x18\a \x01(\x04R\n" +
+	"totalBytes*\x99\x01\n" +
+	"\rJobTokenScope\x12\x1f\n" +
+	"\x1bJOB_TOKEN_SCOPE_UNSPECIFIED\x10\x00\x12$\n" +
+	" JOB_TOKEN_SCOPE_START_CHILD_JOBS\x10\x01\x12#\n" +
+	"\x1fJOB_TOKEN_SCOPE_REPORT_PROGRESS\x10\x02\x12\x1c\n" +
+	"\x18JOB_TOKEN_SCOPE_ANNOTATE\x10\x03*\x9c\x01\n" +
+	"\aOutcome\x12\x17\n" +
+	"\x13OUTCOME_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fOUTCOME_SUCCESS\x10\x01\x12\x13\n" +
+	"\x0fOUTCOME_WARNING\x10\x02\x12\x13\n" +
+	"\x0fOUTCOME_FAILURE\x10\x03\x12\x15\n" +
+	"\x11OUTCOME_RETRYABLE\x10\x04\x12\"\n" +
+	"\x1eOUTCOME_INFRASTRUCTURE_FAILURE\x10\x05*P\n" +
+	"\aIOClass\x12\x18\n" +
+	"\x14IO_CLASS_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14IO_CLASS_BEST_EFFORT\x10\x01\x12\x11\n" +
+	"\rIO_CLASS_IDLE\x10\x02*]\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eSTATUS_RUNNING\x10\x01\x12\x12\n" +
+	"\x0eSTATUS_STOPPED\x10\x02\x12\x13\n" +
+	"\x0fSTATUS_COMPLETE\x10\x03*\xc9\x03\n" +
+	"\vStateReason\x12\x1c\n" +
+	"\x18STATE_REASON_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16STATE_REASON_COMPLETED\x10\x01\x12\x17\n" +
+	"\x13STATE_REASON_FAILED\x10\x02\x12\x1d\n" +
+	"\x19STATE_REASON_USER_STOPPED\x10\x03\x12\x18\n" +
+	"\x14STATE_REASON_TIMEOUT\x10\x04\x12\x14\n" +
+	"\x10STATE_REASON_OOM\x10\x05\x12\x1a\n" +
+	"\x16STATE_REASON_PREEMPTED\x10\x06\x12 \n" +
+	"\x1cSTATE_REASON_SERVER_SHUTDOWN\x10\a\x12\x1c\n" +
+	"\x18STATE_REASON_EXEC_FAILED\x10\b\x12\x1f\n" +
+	"\x1bSTATE_REASON_QUOTA_EXCEEDED\x10\t\x12\x19\n" +
+	"\x15STATE_REASON_SIGNALED\x10\n" +
+	"\x12\x18\n" +
+	"\x14STATE_REASON_UNKNOWN\x10\v\x12$\n" +
+	" STATE_REASON_CREDENTIALS_EXPIRED\x10\f\x12\x1e\n" +
+	"\x1aSTATE_REASON_LEASE_EXPIRED\x10\r\x12 \n" +
+	"\x1cSTATE_REASON_PRE_HOOK_FAILED\x10\x0e*\xff\x01\n" +
+	"\n" +
+	"ExitReason\x12\x1b\n" +
+	"\x17EXIT_REASON_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12EXIT_REASON_EXITED\x10\x01\x12\x18\n" +
+	"\x14EXIT_REASON_SIGNALED\x10\x02\x12\x1a\n" +
+	"\x16EXIT_REASON_OOM_KILLED\x10\x03\x12\x19\n" +
+	"\x15EXIT_REASON_TIMED_OUT\x10\x04\x12\x1e\n" +
+	"\x1aEXIT_REASON_QUOTA_EXCEEDED\x10\x05\x12\x17\n" +
+	"\x13EXIT_REASON_STOPPED\x10\x06\x12\x19\n" +
+	"\x15EXIT_REASON_PREEMPTED\x10\a\x12\x17\n" +
+	"\x13EXIT_REASON_UNKNOWN\x10\b*Y\n" +
+	"\n" +
+	"OutputType\x12\x1b\n" +
+	"\x17OUTPUT_TYPE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12OUTPUT_TYPE_STDOUT\x10\x01\x12\x16\n" +
+	"\x12OUTPUT_TYPE_STDERR\x10\x02*U\n" +
+	"\n" +
+	"StreamMode\x12\x1b\n" +
+	"\x17STREAM_MODE_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fSTREAM_MODE_RAW\x10\x01\x12\x15\n" +
+	"\x11STREAM_MODE_LINES\x10\x02*\xbb\x03\n" +
+	"\fJobEventType\x12\x1e\n" +
+	"\x1aJOB_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16JOB_EVENT_TYPE_CREATED\x10\x01\x12\x1a\n" +
+	"\x16JOB_EVENT_TYPE_STARTED\x10\x02\x12\x1b\n" +
+	"\x17JOB_EVENT_TYPE_SIGNALED\x10\x03\x12!\n" +
+	"\x1dJOB_EVENT_TYPE_ATTEMPT_FAILED\x10\x04\x12\x19\n" +
+	"\x15JOB_EVENT_TYPE_EXITED\x10\x05\x12\x1b\n" +
+	"\x17JOB_EVENT_TYPE_REQUEUED\x10\x06\x12$\n" +
+	" JOB_EVENT_TYPE_GARBAGE_COLLECTED\x10\a\x12\x1b\n" +
+	"\x17JOB_EVENT_TYPE_PROGRESS\x10\b\x12\x1a\n" +
+	"\x16JOB_EVENT_TYPE_DELETED\x10\t\x12\x1b\n" +
+	"\x17JOB_EVENT_TYPE_RESTORED\x10\n" +
+	"\x12\x1c\n" +
+	"\x18JOB_EVENT_TYPE_CACHE_HIT\x10\v\x12!\n" +
+	"\x1dJOB_EVENT_TYPE_HOOK_SUCCEEDED\x10\f\x12\x1e\n" +
+	"\x1aJOB_EVENT_TYPE_HOOK_FAILED\x10\r*w\n" +
+	"\bLogLevel\x12\x19\n" +
+	"\x15LOG_LEVEL_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fLOG_LEVEL_DEBUG\x10\x01\x12\x12\n" +
+	"\x0eLOG_LEVEL_INFO\x10\x02\x12\x12\n" +
+	"\x0eLOG_LEVEL_WARN\x10\x03\x12\x13\n" +
+	"\x0fLOG_LEVEL_ERROR\x10\x04*\x8f\x01\n" +
+	"\x0fMissedRunPolicy\x12!\n" +
+	"\x1dMISSED_RUN_POLICY_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16MISSED_RUN_POLICY_SKIP\x10\x01\x12\x1e\n" +
+	"\x1aMISSED_RUN_POLICY_RUN_ONCE\x10\x02\x12\x1d\n" +
+	"\x19MISSED_RUN_POLICY_RUN_ALL\x10\x032\xbc\x12\n" +
+	"\n" +
+	"JobManager\x12=\n" +
+	"\bStartJob\x12\x16.jobby.StartJobRequest\x1a\x17.jobby.StartJobResponse\"\x00\x12:\n" +
+	"\aStopJob\x12\x15.jobby.StopJobRequest\x1a\x16.jobby.StopJobResponse\"\x00\x12@\n" +
+	"\tGetStatus\x12\x17.jobby.GetStatusRequest\x1a\x18.jobby.GetStatusResponse\"\x00\x12<\n" +
+	"\aWaitJob\x12\x15.jobby.WaitJobRequest\x1a\x18.jobby.GetStatusResponse\"\x00\x12K\n" +
+	"\fGetJobOutput\x12\x1a.jobby.GetJobOutputRequest\x1a\x1b.jobby.GetJobOutputResponse\"\x000\x01\x12L\n" +
+	"\rGetJobHistory\x12\x1b.jobby.GetJobHistoryRequest\x1a\x1c.jobby.GetJobHistoryResponse\"\x00\x12<\n" +
+	"\n" +
+	"ExportJobs\x12\x18.jobby.ExportJobsRequest\x1a\x10.jobby.JobRecord\"\x000\x01\x12=\n" +
+	"\bListJobs\x12\x16.jobby.ListJobsRequest\x1a\x17.jobby.ListJobsResponse\"\x00\x12L\n" +
+	"\rGetServerInfo\x12\x1b.jobby.GetServerInfoRequest\x1a\x1c.jobby.GetServerInfoResponse\"\x00\x12R\n" +
+	"\x0fGetUsageSummary\x12\x1d.jobby.GetUsageSummaryRequest\x1a\x1e.jobby.GetUsageSummaryResponse\"\x00\x12I\n" +
+	"\fGetJobEvents\x12\x1a.jobby.GetJobEventsRequest\x1a\x1b.jobby.GetJobEventsResponse\"\x00\x12[\n" +
+	"\x12ListOutputSegments\x12 .jobby.ListOutputSegmentsRequest\x1a!.jobby.ListOutputSegmentsResponse\"\x00\x12S\n" +
+	"\x10GetOutputSegment\x12\x1e.jobby.GetOutputSegmentRequest\x1a\x1b.jobby.GetJobOutputResponse\"\x000\x01\x12Q\n" +
+	"\x0eGetJobProgress\x12\x1c.jobby.GetJobProgressRequest\x1a\x1d.jobby.GetJobProgressResponse\"\x000\x01\x12C\n" +
+	"\n" +
+	"EndSession\x12\x18.jobby.EndSessionRequest\x1a\x19.jobby.EndSessionResponse\"\x00\x12M\n" +
+	"\x10StreamServerLogs\x12\x1e.jobby.StreamServerLogsRequest\x1a\x15.jobby.ServerLogEntry\"\x000\x01\x12@\n" +
+	"\tDeleteJob\x12\x17.jobby.DeleteJobRequest\x1a\x18.jobby.DeleteJobResponse\"\x00\x12C\n" +
+	"\n" +
+	"RestoreJob\x12\x18.jobby.RestoreJobRequest\x1a\x19.jobby.RestoreJobResponse\"\x00\x12I\n" +
+	"\fAdoptProcess\x12\x1a.jobby.AdoptProcessRequest\x1a\x1b.jobby.AdoptProcessResponse\"\x00\x12F\n" +
+	"\vGetJobStats\x12\x19.jobby.GetJobStatsRequest\x1a\x1a.jobby.GetJobStatsResponse\"\x00\x12F\n" +
+	"\vDescribeJob\x12\x19.jobby.DescribeJobRequest\x1a\x1a.jobby.DescribeJobResponse\"\x00\x12N\n" +
+	"\rWriteJobStdin\x12\x1b.jobby.WriteJobStdinRequest\x1a\x1c.jobby.WriteJobStdinResponse\"\x00(\x01\x12L\n" +
+	"\rRenewJobLease\x12\x1b.jobby.RenewJobLeaseRequest\x1a\x1c.jobby.RenewJobLeaseResponse\"\x00\x12X\n" +
+	"\x11ReportJobProgress\x12\x1f.jobby.ReportJobProgressRequest\x1a .jobby.ReportJobProgressResponse\"\x00\x12F\n" +
+	"\vAnnotateJob\x12\x19.jobby.AnnotateJobRequest\x1a\x1a.jobby.AnnotateJobResponse\"\x00\x12F\n" +
+	"\vPutSchedule\x12\x19.jobby.PutScheduleRequest\x1a\x1a.jobby.PutScheduleResponse\"\x00\x12L\n" +
+	"\rListSchedules\x12\x1b.jobby.ListSchedulesRequest\x1a\x1c.jobby.ListSchedulesResponse\"\x00\x12O\n" +
+	"\x0eDeleteSchedule\x12\x1c.jobby.DeleteScheduleRequest\x1a\x1d.jobby.DeleteScheduleResponse\"\x00\x12U\n" +
+	"\x10ListScheduleRuns\x12\x1e.jobby.ListScheduleRunsRequest\x1a\x1f.jobby.ListScheduleRunsResponse\"\x00\x12R\n" +
+	"\x0fPreviewTemplate\x12\x1d.jobby.PreviewTemplateRequest\x1a\x1e.jobby.PreviewTemplateResponse\"\x00\x12a\n" +
+	"\x14CheckOutputDirectory\x12\".jobby.CheckOutputDirectoryRequest\x1a#.jobby.CheckOutputDirectoryResponse\"\x00B#Z!github.com/gopheryan/jobmanagerpbb\x06proto3"
+
 var (
 	file_jobby_proto_rawDescOnce sync.Once
-	file_jobby_proto_rawDescData = file_jobby_proto_rawDesc
+	file_jobby_proto_rawDescData []byte
 )
 
 func file_jobby_proto_rawDescGZIP() []byte {
 	file_jobby_proto_rawDescOnce.Do(func() {
-		file_jobby_proto_rawDescData = protoimpl.X.CompressGZIP(file_jobby_proto_rawDescData)
+		file_jobby_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_jobby_proto_rawDesc), len(file_jobby_proto_rawDesc)))
 	})
 	return file_jobby_proto_rawDescData
 }
 
-var file_jobby_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_jobby_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
-var file_jobby_proto_goTypes = []interface{}{
-	(Status)(0),                  // 0: jobby.Status
-	(OutputType)(0),              // 1: jobby.OutputType
-	(*StartJobRequest)(nil),      // 2: jobby.StartJobRequest
-	(*StartJobResponse)(nil),     // 3: jobby.StartJobResponse
-	(*StopJobRequest)(nil),       // 4: jobby.StopJobRequest
-	(*StopJobResponse)(nil),      // 5: jobby.StopJobResponse
-	(*GetStatusRequest)(nil),     // 6: jobby.GetStatusRequest
-	(*GetStatusResponse)(nil),    // 7: jobby.GetStatusResponse
-	(*GetJobOutputRequest)(nil),  // 8: jobby.GetJobOutputRequest
-	(*GetJobOutputResponse)(nil), // 9: jobby.GetJobOutputResponse
+var file_jobby_proto_enumTypes = make([]protoimpl.EnumInfo, 11)
+var file_jobby_proto_msgTypes = make([]protoimpl.MessageInfo, 99)
+var file_jobby_proto_goTypes = []any{
+	(JobTokenScope)(0),                   // 0: jobby.JobTokenScope
+	(Outcome)(0),                         // 1: jobby.Outcome
+	(IOClass)(0),                         // 2: jobby.IOClass
+	(Status)(0),                          // 3: jobby.Status
+	(StateReason)(0),                     // 4: jobby.StateReason
+	(ExitReason)(0),                      // 5: jobby.ExitReason
+	(OutputType)(0),                      // 6: jobby.OutputType
+	(StreamMode)(0),                      // 7: jobby.StreamMode
+	(JobEventType)(0),                    // 8: jobby.JobEventType
+	(LogLevel)(0),                        // 9: jobby.LogLevel
+	(MissedRunPolicy)(0),                 // 10: jobby.MissedRunPolicy
+	(*JobSpec)(nil),                      // 11: jobby.JobSpec
+	(*TemplateRef)(nil),                  // 12: jobby.TemplateRef
+	(*Scratch)(nil),                      // 13: jobby.Scratch
+	(*Scheduling)(nil),                   // 14: jobby.Scheduling
+	(*SegmentPolicy)(nil),                // 15: jobby.SegmentPolicy
+	(*ExitCodeRule)(nil),                 // 16: jobby.ExitCodeRule
+	(*StartJobRequest)(nil),              // 17: jobby.StartJobRequest
+	(*RetentionPolicy)(nil),              // 18: jobby.RetentionPolicy
+	(*StartJobResponse)(nil),             // 19: jobby.StartJobResponse
+	(*StopJobRequest)(nil),               // 20: jobby.StopJobRequest
+	(*StopJobResponse)(nil),              // 21: jobby.StopJobResponse
+	(*GetStatusRequest)(nil),             // 22: jobby.GetStatusRequest
+	(*WaitJobRequest)(nil),               // 23: jobby.WaitJobRequest
+	(*GetStatusResponse)(nil),            // 24: jobby.GetStatusResponse
+	(*JobProcess)(nil),                   // 25: jobby.JobProcess
+	(*Progress)(nil),                     // 26: jobby.Progress
+	(*GetJobOutputRequest)(nil),          // 27: jobby.GetJobOutputRequest
+	(*ByteRange)(nil),                    // 28: jobby.ByteRange
+	(*GetJobOutputResponse)(nil),         // 29: jobby.GetJobOutputResponse
+	(*OutputEnd)(nil),                    // 30: jobby.OutputEnd
+	(*GetJobHistoryRequest)(nil),         // 31: jobby.GetJobHistoryRequest
+	(*Attempt)(nil),                      // 32: jobby.Attempt
+	(*GetJobHistoryResponse)(nil),        // 33: jobby.GetJobHistoryResponse
+	(*ExportJobsRequest)(nil),            // 34: jobby.ExportJobsRequest
+	(*JobRecord)(nil),                    // 35: jobby.JobRecord
+	(*LaunchSnapshot)(nil),               // 36: jobby.LaunchSnapshot
+	(*ListJobsRequest)(nil),              // 37: jobby.ListJobsRequest
+	(*ListJobsResponse)(nil),             // 38: jobby.ListJobsResponse
+	(*GetServerInfoRequest)(nil),         // 39: jobby.GetServerInfoRequest
+	(*GetServerInfoResponse)(nil),        // 40: jobby.GetServerInfoResponse
+	(*BuildInfo)(nil),                    // 41: jobby.BuildInfo
+	(*FeatureFlag)(nil),                  // 42: jobby.FeatureFlag
+	(*GPU)(nil),                          // 43: jobby.GPU
+	(*GetUsageSummaryRequest)(nil),       // 44: jobby.GetUsageSummaryRequest
+	(*GetUsageSummaryResponse)(nil),      // 45: jobby.GetUsageSummaryResponse
+	(*UsageWindow)(nil),                  // 46: jobby.UsageWindow
+	(*OwnerUsage)(nil),                   // 47: jobby.OwnerUsage
+	(*GetJobEventsRequest)(nil),          // 48: jobby.GetJobEventsRequest
+	(*GetJobEventsResponse)(nil),         // 49: jobby.GetJobEventsResponse
+	(*JobEvent)(nil),                     // 50: jobby.JobEvent
+	(*ListOutputSegmentsRequest)(nil),    // 51: jobby.ListOutputSegmentsRequest
+	(*ListOutputSegmentsResponse)(nil),   // 52: jobby.ListOutputSegmentsResponse
+	(*OutputSegment)(nil),                // 53: jobby.OutputSegment
+	(*GetOutputSegmentRequest)(nil),      // 54: jobby.GetOutputSegmentRequest
+	(*GetJobProgressRequest)(nil),        // 55: jobby.GetJobProgressRequest
+	(*GetJobProgressResponse)(nil),       // 56: jobby.GetJobProgressResponse
+	(*EndSessionRequest)(nil),            // 57: jobby.EndSessionRequest
+	(*EndSessionResponse)(nil),           // 58: jobby.EndSessionResponse
+	(*StreamServerLogsRequest)(nil),      // 59: jobby.StreamServerLogsRequest
+	(*ServerLogEntry)(nil),               // 60: jobby.ServerLogEntry
+	(*DeleteJobRequest)(nil),             // 61: jobby.DeleteJobRequest
+	(*DeleteJobResponse)(nil),            // 62: jobby.DeleteJobResponse
+	(*RestoreJobRequest)(nil),            // 63: jobby.RestoreJobRequest
+	(*RestoreJobResponse)(nil),           // 64: jobby.RestoreJobResponse
+	(*AdoptProcessRequest)(nil),          // 65: jobby.AdoptProcessRequest
+	(*AdoptProcessResponse)(nil),         // 66: jobby.AdoptProcessResponse
+	(*GetJobStatsRequest)(nil),           // 67: jobby.GetJobStatsRequest
+	(*GetJobStatsResponse)(nil),          // 68: jobby.GetJobStatsResponse
+	(*DurationDistribution)(nil),         // 69: jobby.DurationDistribution
+	(*SizeDistribution)(nil),             // 70: jobby.SizeDistribution
+	(*DescribeJobRequest)(nil),           // 71: jobby.DescribeJobRequest
+	(*DescribeJobResponse)(nil),          // 72: jobby.DescribeJobResponse
+	(*OutputDescriptor)(nil),             // 73: jobby.OutputDescriptor
+	(*JobResourceUsage)(nil),             // 74: jobby.JobResourceUsage
+	(*WriteJobStdinRequest)(nil),         // 75: jobby.WriteJobStdinRequest
+	(*WriteJobStdinResponse)(nil),        // 76: jobby.WriteJobStdinResponse
+	(*RenewJobLeaseRequest)(nil),         // 77: jobby.RenewJobLeaseRequest
+	(*RenewJobLeaseResponse)(nil),        // 78: jobby.RenewJobLeaseResponse
+	(*ReportJobProgressRequest)(nil),     // 79: jobby.ReportJobProgressRequest
+	(*ReportJobProgressResponse)(nil),    // 80: jobby.ReportJobProgressResponse
+	(*AnnotateJobRequest)(nil),           // 81: jobby.AnnotateJobRequest
+	(*AnnotateJobResponse)(nil),          // 82: jobby.AnnotateJobResponse
+	(*Schedule)(nil),                     // 83: jobby.Schedule
+	(*ScheduleRun)(nil),                  // 84: jobby.ScheduleRun
+	(*PutScheduleRequest)(nil),           // 85: jobby.PutScheduleRequest
+	(*PutScheduleResponse)(nil),          // 86: jobby.PutScheduleResponse
+	(*ListSchedulesRequest)(nil),         // 87: jobby.ListSchedulesRequest
+	(*ListSchedulesResponse)(nil),        // 88: jobby.ListSchedulesResponse
+	(*DeleteScheduleRequest)(nil),        // 89: jobby.DeleteScheduleRequest
+	(*DeleteScheduleResponse)(nil),       // 90: jobby.DeleteScheduleResponse
+	(*ListScheduleRunsRequest)(nil),      // 91: jobby.ListScheduleRunsRequest
+	(*ListScheduleRunsResponse)(nil),     // 92: jobby.ListScheduleRunsResponse
+	(*PreviewTemplateRequest)(nil),       // 93: jobby.PreviewTemplateRequest
+	(*PreviewTemplateResponse)(nil),      // 94: jobby.PreviewTemplateResponse
+	(*CheckOutputDirectoryRequest)(nil),  // 95: jobby.CheckOutputDirectoryRequest
+	(*OutputDirectoryEntry)(nil),         // 96: jobby.OutputDirectoryEntry
+	(*UserDiskUsage)(nil),                // 97: jobby.UserDiskUsage
+	(*MissingOutput)(nil),                // 98: jobby.MissingOutput
+	(*CheckOutputDirectoryResponse)(nil), // 99: jobby.CheckOutputDirectoryResponse
+	nil,                                  // 100: jobby.JobSpec.EnvEntry
+	nil,                                  // 101: jobby.JobSpec.LabelsEntry
+	nil,                                  // 102: jobby.TemplateRef.ParamsEntry
+	nil,                                  // 103: jobby.JobRecord.AnnotationsEntry
+	nil,                                  // 104: jobby.LaunchSnapshot.EnvEntry
+	nil,                                  // 105: jobby.ServerLogEntry.AttrsEntry
+	nil,                                  // 106: jobby.AdoptProcessRequest.LabelsEntry
+	nil,                                  // 107: jobby.GetJobStatsResponse.ExitCodesEntry
+	nil,                                  // 108: jobby.AnnotateJobRequest.AnnotationsEntry
+	nil,                                  // 109: jobby.PreviewTemplateResponse.ParamsEntry
+	(*durationpb.Duration)(nil),          // 110: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),        // 111: google.protobuf.Timestamp
 }
 var file_jobby_proto_depIdxs = []int32{
-	0, // 0: jobby.GetStatusResponse.current_status:type_name -> jobby.Status
-	1, // 1: jobby.GetJobOutputRequest.type:type_name -> jobby.OutputType
-	2, // 2: jobby.JobManager.StartJob:input_type -> jobby.StartJobRequest
-	4, // 3: jobby.JobManager.StopJob:input_type -> jobby.StopJobRequest
-	6, // 4: jobby.JobManager.GetStatus:input_type -> jobby.GetStatusRequest
-	8, // 5: jobby.JobManager.GetJobOutput:input_type -> jobby.GetJobOutputRequest
-	3, // 6: jobby.JobManager.StartJob:output_type -> jobby.StartJobResponse
-	5, // 7: jobby.JobManager.StopJob:output_type -> jobby.StopJobResponse
-	7, // 8: jobby.JobManager.GetStatus:output_type -> jobby.GetStatusResponse
-	9, // 9: jobby.JobManager.GetJobOutput:output_type -> jobby.GetJobOutputResponse
-	6, // [6:10] is the sub-list for method output_type
-	2, // [2:6] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	100, // 0: jobby.JobSpec.env:type_name -> jobby.JobSpec.EnvEntry
+	18,  // 1: jobby.JobSpec.retention:type_name -> jobby.RetentionPolicy
+	101, // 2: jobby.JobSpec.labels:type_name -> jobby.JobSpec.LabelsEntry
+	110, // 3: jobby.JobSpec.timeout:type_name -> google.protobuf.Duration
+	14,  // 4: jobby.JobSpec.scheduling:type_name -> jobby.Scheduling
+	15,  // 5: jobby.JobSpec.output_segments:type_name -> jobby.SegmentPolicy
+	16,  // 6: jobby.JobSpec.exit_code_rules:type_name -> jobby.ExitCodeRule
+	110, // 7: jobby.JobSpec.expected_runtime:type_name -> google.protobuf.Duration
+	110, // 8: jobby.JobSpec.lease:type_name -> google.protobuf.Duration
+	0,   // 9: jobby.JobSpec.token_scopes:type_name -> jobby.JobTokenScope
+	13,  // 10: jobby.JobSpec.scratch:type_name -> jobby.Scratch
+	12,  // 11: jobby.JobSpec.template:type_name -> jobby.TemplateRef
+	102, // 12: jobby.TemplateRef.params:type_name -> jobby.TemplateRef.ParamsEntry
+	2,   // 13: jobby.Scheduling.io_class:type_name -> jobby.IOClass
+	110, // 14: jobby.SegmentPolicy.interval:type_name -> google.protobuf.Duration
+	1,   // 15: jobby.ExitCodeRule.outcome:type_name -> jobby.Outcome
+	18,  // 16: jobby.StartJobRequest.retention:type_name -> jobby.RetentionPolicy
+	11,  // 17: jobby.StartJobRequest.spec:type_name -> jobby.JobSpec
+	110, // 18: jobby.StartJobRequest.cache_ttl:type_name -> google.protobuf.Duration
+	110, // 19: jobby.RetentionPolicy.ttl:type_name -> google.protobuf.Duration
+	3,   // 20: jobby.GetStatusResponse.current_status:type_name -> jobby.Status
+	110, // 21: jobby.GetStatusResponse.duration:type_name -> google.protobuf.Duration
+	5,   // 22: jobby.GetStatusResponse.exit_reason:type_name -> jobby.ExitReason
+	26,  // 23: jobby.GetStatusResponse.progress:type_name -> jobby.Progress
+	25,  // 24: jobby.GetStatusResponse.processes:type_name -> jobby.JobProcess
+	1,   // 25: jobby.GetStatusResponse.outcome:type_name -> jobby.Outcome
+	4,   // 26: jobby.GetStatusResponse.state_reason:type_name -> jobby.StateReason
+	111, // 27: jobby.Progress.time:type_name -> google.protobuf.Timestamp
+	6,   // 28: jobby.GetJobOutputRequest.type:type_name -> jobby.OutputType
+	110, // 29: jobby.GetJobOutputRequest.batch_max_delay:type_name -> google.protobuf.Duration
+	7,   // 30: jobby.GetJobOutputRequest.mode:type_name -> jobby.StreamMode
+	110, // 31: jobby.GetJobOutputRequest.line_max_hold:type_name -> google.protobuf.Duration
+	28,  // 32: jobby.GetJobOutputRequest.range:type_name -> jobby.ByteRange
+	30,  // 33: jobby.GetJobOutputResponse.end:type_name -> jobby.OutputEnd
+	3,   // 34: jobby.Attempt.status:type_name -> jobby.Status
+	111, // 35: jobby.Attempt.start_time:type_name -> google.protobuf.Timestamp
+	111, // 36: jobby.Attempt.end_time:type_name -> google.protobuf.Timestamp
+	110, // 37: jobby.Attempt.duration:type_name -> google.protobuf.Duration
+	5,   // 38: jobby.Attempt.exit_reason:type_name -> jobby.ExitReason
+	1,   // 39: jobby.Attempt.outcome:type_name -> jobby.Outcome
+	32,  // 40: jobby.GetJobHistoryResponse.attempts:type_name -> jobby.Attempt
+	3,   // 41: jobby.JobRecord.status:type_name -> jobby.Status
+	111, // 42: jobby.JobRecord.start_time:type_name -> google.protobuf.Timestamp
+	111, // 43: jobby.JobRecord.end_time:type_name -> google.protobuf.Timestamp
+	110, // 44: jobby.JobRecord.duration:type_name -> google.protobuf.Duration
+	11,  // 45: jobby.JobRecord.spec:type_name -> jobby.JobSpec
+	36,  // 46: jobby.JobRecord.launch_snapshot:type_name -> jobby.LaunchSnapshot
+	4,   // 47: jobby.JobRecord.state_reason:type_name -> jobby.StateReason
+	103, // 48: jobby.JobRecord.annotations:type_name -> jobby.JobRecord.AnnotationsEntry
+	111, // 49: jobby.LaunchSnapshot.captured_at:type_name -> google.protobuf.Timestamp
+	104, // 50: jobby.LaunchSnapshot.env:type_name -> jobby.LaunchSnapshot.EnvEntry
+	111, // 51: jobby.ListJobsRequest.started_after:type_name -> google.protobuf.Timestamp
+	111, // 52: jobby.ListJobsRequest.started_before:type_name -> google.protobuf.Timestamp
+	35,  // 53: jobby.ListJobsResponse.jobs:type_name -> jobby.JobRecord
+	43,  // 54: jobby.GetServerInfoResponse.gpus:type_name -> jobby.GPU
+	41,  // 55: jobby.GetServerInfoResponse.build:type_name -> jobby.BuildInfo
+	42,  // 56: jobby.GetServerInfoResponse.features:type_name -> jobby.FeatureFlag
+	111, // 57: jobby.BuildInfo.commit_time:type_name -> google.protobuf.Timestamp
+	110, // 58: jobby.GetUsageSummaryRequest.window:type_name -> google.protobuf.Duration
+	46,  // 59: jobby.GetUsageSummaryResponse.windows:type_name -> jobby.UsageWindow
+	110, // 60: jobby.UsageWindow.window:type_name -> google.protobuf.Duration
+	47,  // 61: jobby.UsageWindow.owners:type_name -> jobby.OwnerUsage
+	50,  // 62: jobby.GetJobEventsResponse.events:type_name -> jobby.JobEvent
+	8,   // 63: jobby.JobEvent.type:type_name -> jobby.JobEventType
+	111, // 64: jobby.JobEvent.time:type_name -> google.protobuf.Timestamp
+	6,   // 65: jobby.ListOutputSegmentsRequest.type:type_name -> jobby.OutputType
+	111, // 66: jobby.ListOutputSegmentsRequest.since:type_name -> google.protobuf.Timestamp
+	111, // 67: jobby.ListOutputSegmentsRequest.until:type_name -> google.protobuf.Timestamp
+	53,  // 68: jobby.ListOutputSegmentsResponse.segments:type_name -> jobby.OutputSegment
+	111, // 69: jobby.OutputSegment.start_time:type_name -> google.protobuf.Timestamp
+	111, // 70: jobby.OutputSegment.end_time:type_name -> google.protobuf.Timestamp
+	6,   // 71: jobby.GetOutputSegmentRequest.type:type_name -> jobby.OutputType
+	26,  // 72: jobby.GetJobProgressResponse.progress:type_name -> jobby.Progress
+	9,   // 73: jobby.StreamServerLogsRequest.level:type_name -> jobby.LogLevel
+	111, // 74: jobby.ServerLogEntry.time:type_name -> google.protobuf.Timestamp
+	9,   // 75: jobby.ServerLogEntry.level:type_name -> jobby.LogLevel
+	105, // 76: jobby.ServerLogEntry.attrs:type_name -> jobby.ServerLogEntry.AttrsEntry
+	111, // 77: jobby.DeleteJobResponse.restorable_until:type_name -> google.protobuf.Timestamp
+	106, // 78: jobby.AdoptProcessRequest.labels:type_name -> jobby.AdoptProcessRequest.LabelsEntry
+	69,  // 79: jobby.GetJobStatsResponse.duration:type_name -> jobby.DurationDistribution
+	70,  // 80: jobby.GetJobStatsResponse.output_bytes:type_name -> jobby.SizeDistribution
+	107, // 81: jobby.GetJobStatsResponse.exit_codes:type_name -> jobby.GetJobStatsResponse.ExitCodesEntry
+	110, // 82: jobby.DurationDistribution.min:type_name -> google.protobuf.Duration
+	110, // 83: jobby.DurationDistribution.median:type_name -> google.protobuf.Duration
+	110, // 84: jobby.DurationDistribution.p90:type_name -> google.protobuf.Duration
+	110, // 85: jobby.DurationDistribution.max:type_name -> google.protobuf.Duration
+	110, // 86: jobby.DurationDistribution.mean:type_name -> google.protobuf.Duration
+	35,  // 87: jobby.DescribeJobResponse.record:type_name -> jobby.JobRecord
+	24,  // 88: jobby.DescribeJobResponse.status:type_name -> jobby.GetStatusResponse
+	32,  // 89: jobby.DescribeJobResponse.attempts:type_name -> jobby.Attempt
+	50,  // 90: jobby.DescribeJobResponse.events:type_name -> jobby.JobEvent
+	73,  // 91: jobby.DescribeJobResponse.outputs:type_name -> jobby.OutputDescriptor
+	74,  // 92: jobby.DescribeJobResponse.usage:type_name -> jobby.JobResourceUsage
+	6,   // 93: jobby.OutputDescriptor.type:type_name -> jobby.OutputType
+	110, // 94: jobby.JobResourceUsage.cpu_time:type_name -> google.protobuf.Duration
+	110, // 95: jobby.JobResourceUsage.wall_time:type_name -> google.protobuf.Duration
+	111, // 96: jobby.RenewJobLeaseResponse.expires_at:type_name -> google.protobuf.Timestamp
+	108, // 97: jobby.AnnotateJobRequest.annotations:type_name -> jobby.AnnotateJobRequest.AnnotationsEntry
+	11,  // 98: jobby.Schedule.spec:type_name -> jobby.JobSpec
+	111, // 99: jobby.Schedule.created_at:type_name -> google.protobuf.Timestamp
+	111, // 100: jobby.Schedule.last_run:type_name -> google.protobuf.Timestamp
+	111, // 101: jobby.Schedule.next_run:type_name -> google.protobuf.Timestamp
+	10,  // 102: jobby.Schedule.missed_runs:type_name -> jobby.MissedRunPolicy
+	110, // 103: jobby.Schedule.jitter:type_name -> google.protobuf.Duration
+	111, // 104: jobby.ScheduleRun.scheduled_for:type_name -> google.protobuf.Timestamp
+	111, // 105: jobby.ScheduleRun.start_at:type_name -> google.protobuf.Timestamp
+	111, // 106: jobby.ScheduleRun.started_at:type_name -> google.protobuf.Timestamp
+	83,  // 107: jobby.PutScheduleRequest.schedule:type_name -> jobby.Schedule
+	83,  // 108: jobby.PutScheduleResponse.schedule:type_name -> jobby.Schedule
+	83,  // 109: jobby.ListSchedulesResponse.schedules:type_name -> jobby.Schedule
+	84,  // 110: jobby.ListScheduleRunsResponse.past:type_name -> jobby.ScheduleRun
+	84,  // 111: jobby.ListScheduleRunsResponse.upcoming:type_name -> jobby.ScheduleRun
+	12,  // 112: jobby.PreviewTemplateRequest.template:type_name -> jobby.TemplateRef
+	109, // 113: jobby.PreviewTemplateResponse.params:type_name -> jobby.PreviewTemplateResponse.ParamsEntry
+	111, // 114: jobby.OutputDirectoryEntry.modified:type_name -> google.protobuf.Timestamp
+	96,  // 115: jobby.CheckOutputDirectoryResponse.orphans:type_name -> jobby.OutputDirectoryEntry
+	96,  // 116: jobby.CheckOutputDirectoryResponse.unrecognized:type_name -> jobby.OutputDirectoryEntry
+	98,  // 117: jobby.CheckOutputDirectoryResponse.missing:type_name -> jobby.MissingOutput
+	97,  // 118: jobby.CheckOutputDirectoryResponse.usage:type_name -> jobby.UserDiskUsage
+	17,  // 119: jobby.JobManager.StartJob:input_type -> jobby.StartJobRequest
+	20,  // 120: jobby.JobManager.StopJob:input_type -> jobby.StopJobRequest
+	22,  // 121: jobby.JobManager.GetStatus:input_type -> jobby.GetStatusRequest
+	23,  // 122: jobby.JobManager.WaitJob:input_type -> jobby.WaitJobRequest
+	27,  // 123: jobby.JobManager.GetJobOutput:input_type -> jobby.GetJobOutputRequest
+	31,  // 124: jobby.JobManager.GetJobHistory:input_type -> jobby.GetJobHistoryRequest
+	34,  // 125: jobby.JobManager.ExportJobs:input_type -> jobby.ExportJobsRequest
+	37,  // 126: jobby.JobManager.ListJobs:input_type -> jobby.ListJobsRequest
+	39,  // 127: jobby.JobManager.GetServerInfo:input_type -> jobby.GetServerInfoRequest
+	44,  // 128: jobby.JobManager.GetUsageSummary:input_type -> jobby.GetUsageSummaryRequest
+	48,  // 129: jobby.JobManager.GetJobEvents:input_type -> jobby.GetJobEventsRequest
+	51,  // 130: jobby.JobManager.ListOutputSegments:input_type -> jobby.ListOutputSegmentsRequest
+	54,  // 131: jobby.JobManager.GetOutputSegment:input_type -> jobby.GetOutputSegmentRequest
+	55,  // 132: jobby.JobManager.GetJobProgress:input_type -> jobby.GetJobProgressRequest
+	57,  // 133: jobby.JobManager.EndSession:input_type -> jobby.EndSessionRequest
+	59,  // 134: jobby.JobManager.StreamServerLogs:input_type -> jobby.StreamServerLogsRequest
+	61,  // 135: jobby.JobManager.DeleteJob:input_type -> jobby.DeleteJobRequest
+	63,  // 136: jobby.JobManager.RestoreJob:input_type -> jobby.RestoreJobRequest
+	65,  // 137: jobby.JobManager.AdoptProcess:input_type -> jobby.AdoptProcessRequest
+	67,  // 138: jobby.JobManager.GetJobStats:input_type -> jobby.GetJobStatsRequest
+	71,  // 139: jobby.JobManager.DescribeJob:input_type -> jobby.DescribeJobRequest
+	75,  // 140: jobby.JobManager.WriteJobStdin:input_type -> jobby.WriteJobStdinRequest
+	77,  // 141: jobby.JobManager.RenewJobLease:input_type -> jobby.RenewJobLeaseRequest
+	79,  // 142: jobby.JobManager.ReportJobProgress:input_type -> jobby.ReportJobProgressRequest
+	81,  // 143: jobby.JobManager.AnnotateJob:input_type -> jobby.AnnotateJobRequest
+	85,  // 144: jobby.JobManager.PutSchedule:input_type -> jobby.PutScheduleRequest
+	87,  // 145: jobby.JobManager.ListSchedules:input_type -> jobby.ListSchedulesRequest
+	89,  // 146: jobby.JobManager.DeleteSchedule:input_type -> jobby.DeleteScheduleRequest
+	91,  // 147: jobby.JobManager.ListScheduleRuns:input_type -> jobby.ListScheduleRunsRequest
+	93,  // 148: jobby.JobManager.PreviewTemplate:input_type -> jobby.PreviewTemplateRequest
+	95,  // 149: jobby.JobManager.CheckOutputDirectory:input_type -> jobby.CheckOutputDirectoryRequest
+	19,  // 150: jobby.JobManager.StartJob:output_type -> jobby.StartJobResponse
+	21,  // 151: jobby.JobManager.StopJob:output_type -> jobby.StopJobResponse
+	24,  // 152: jobby.JobManager.GetStatus:output_type -> jobby.GetStatusResponse
+	24,  // 153: jobby.JobManager.WaitJob:output_type -> jobby.GetStatusResponse
+	29,  // 154: jobby.JobManager.GetJobOutput:output_type -> jobby.GetJobOutputResponse
+	33,  // 155: jobby.JobManager.GetJobHistory:output_type -> jobby.GetJobHistoryResponse
+	35,  // 156: jobby.JobManager.ExportJobs:output_type -> jobby.JobRecord
+	38,  // 157: jobby.JobManager.ListJobs:output_type -> jobby.ListJobsResponse
+	40,  // 158: jobby.JobManager.GetServerInfo:output_type -> jobby.GetServerInfoResponse
+	45,  // 159: jobby.JobManager.GetUsageSummary:output_type -> jobby.GetUsageSummaryResponse
+	49,  // 160: jobby.JobManager.GetJobEvents:output_type -> jobby.GetJobEventsResponse
+	52,  // 161: jobby.JobManager.ListOutputSegments:output_type -> jobby.ListOutputSegmentsResponse
+	29,  // 162: jobby.JobManager.GetOutputSegment:output_type -> jobby.GetJobOutputResponse
+	56,  // 163: jobby.JobManager.GetJobProgress:output_type -> jobby.GetJobProgressResponse
+	58,  // 164: jobby.JobManager.EndSession:output_type -> jobby.EndSessionResponse
+	60,  // 165: jobby.JobManager.StreamServerLogs:output_type -> jobby.ServerLogEntry
+	62,  // 166: jobby.JobManager.DeleteJob:output_type -> jobby.DeleteJobResponse
+	64,  // 167: jobby.JobManager.RestoreJob:output_type -> jobby.RestoreJobResponse
+	66,  // 168: jobby.JobManager.AdoptProcess:output_type -> jobby.AdoptProcessResponse
+	68,  // 169: jobby.JobManager.GetJobStats:output_type -> jobby.GetJobStatsResponse
+	72,  // 170: jobby.JobManager.DescribeJob:output_type -> jobby.DescribeJobResponse
+	76,  // 171: jobby.JobManager.WriteJobStdin:output_type -> jobby.WriteJobStdinResponse
+	78,  // 172: jobby.JobManager.RenewJobLease:output_type -> jobby.RenewJobLeaseResponse
+	80,  // 173: jobby.JobManager.ReportJobProgress:output_type -> jobby.ReportJobProgressResponse
+	82,  // 174: jobby.JobManager.AnnotateJob:output_type -> jobby.AnnotateJobResponse
+	86,  // 175: jobby.JobManager.PutSchedule:output_type -> jobby.PutScheduleResponse
+	88,  // 176: jobby.JobManager.ListSchedules:output_type -> jobby.ListSchedulesResponse
+	90,  // 177: jobby.JobManager.DeleteSchedule:output_type -> jobby.DeleteScheduleResponse
+	92,  // 178: jobby.JobManager.ListScheduleRuns:output_type -> jobby.ListScheduleRunsResponse
+	94,  // 179: jobby.JobManager.PreviewTemplate:output_type -> jobby.PreviewTemplateResponse
+	99,  // 180: jobby.JobManager.CheckOutputDirectory:output_type -> jobby.CheckOutputDirectoryResponse
+	150, // [150:181] is the sub-list for method output_type
+	119, // [119:150] is the sub-list for method input_type
+	119, // [119:119] is the sub-list for extension type_name
+	119, // [119:119] is the sub-list for extension extendee
+	0,   // [0:119] is the sub-list for field type_name
 }
 
 func init() { file_jobby_proto_init() }
@@ -634,112 +7949,22 @@ func file_jobby_proto_init() {
 	if File_jobby_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_jobby_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StartJobRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_jobby_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StartJobResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_jobby_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopJobRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_jobby_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopJobResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_jobby_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetStatusRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_jobby_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetStatusResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_jobby_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetJobOutputRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_jobby_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetJobOutputResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
-	file_jobby_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_jobby_proto_msgTypes[3].OneofWrappers = []any{}
+	file_jobby_proto_msgTypes[7].OneofWrappers = []any{
+		(*RetentionPolicy_Ttl)(nil),
+		(*RetentionPolicy_KeepForever)(nil),
+	}
+	file_jobby_proto_msgTypes[13].OneofWrappers = []any{}
+	file_jobby_proto_msgTypes[21].OneofWrappers = []any{}
+	file_jobby_proto_msgTypes[24].OneofWrappers = []any{}
+	file_jobby_proto_msgTypes[26].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_jobby_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   8,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_jobby_proto_rawDesc), len(file_jobby_proto_rawDesc)),
+			NumEnums:      11,
+			NumMessages:   99,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -749,7 +7974,6 @@ func file_jobby_proto_init() {
 		MessageInfos:      file_jobby_proto_msgTypes,
 	}.Build()
 	File_jobby_proto = out.File
-	file_jobby_proto_rawDesc = nil
 	file_jobby_proto_goTypes = nil
 	file_jobby_proto_depIdxs = nil
 }
@@ -0,0 +1,2651 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: jobby_grpc.pb.go
+//
+// Generated by this command:
+//
+//	mockgen -source=jobby_grpc.pb.go -destination=jobmanagerpbtest/jobby_mock.go -package=jobmanagerpbtest -exclude_interfaces=UnsafeJobManagerServer -write_package_comment=false
+//
+
+package jobmanagerpbtest
+
+import (
+	context "context"
+	reflect "reflect"
+
+	jobmanagerpb "github.com/gopheryan/jobby/jobmanagerpb"
+	gomock "go.uber.org/mock/gomock"
+	grpc "google.golang.org/grpc"
+	metadata "google.golang.org/grpc/metadata"
+)
+
+// MockJobManagerClient is a mock of JobManagerClient interface.
+type MockJobManagerClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManagerClientMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManagerClientMockRecorder is the mock recorder for MockJobManagerClient.
+type MockJobManagerClientMockRecorder struct {
+	mock *MockJobManagerClient
+}
+
+// NewMockJobManagerClient creates a new mock instance.
+func NewMockJobManagerClient(ctrl *gomock.Controller) *MockJobManagerClient {
+	mock := &MockJobManagerClient{ctrl: ctrl}
+	mock.recorder = &MockJobManagerClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManagerClient) EXPECT() *MockJobManagerClientMockRecorder {
+	return m.recorder
+}
+
+// AdoptProcess mocks base method.
+func (m *MockJobManagerClient) AdoptProcess(ctx context.Context, in *jobmanagerpb.AdoptProcessRequest, opts ...grpc.CallOption) (*jobmanagerpb.AdoptProcessResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AdoptProcess", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.AdoptProcessResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdoptProcess indicates an expected call of AdoptProcess.
+func (mr *MockJobManagerClientMockRecorder) AdoptProcess(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdoptProcess", reflect.TypeOf((*MockJobManagerClient)(nil).AdoptProcess), varargs...)
+}
+
+// AnnotateJob mocks base method.
+func (m *MockJobManagerClient) AnnotateJob(ctx context.Context, in *jobmanagerpb.AnnotateJobRequest, opts ...grpc.CallOption) (*jobmanagerpb.AnnotateJobResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AnnotateJob", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.AnnotateJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnnotateJob indicates an expected call of AnnotateJob.
+func (mr *MockJobManagerClientMockRecorder) AnnotateJob(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotateJob", reflect.TypeOf((*MockJobManagerClient)(nil).AnnotateJob), varargs...)
+}
+
+// CheckOutputDirectory mocks base method.
+func (m *MockJobManagerClient) CheckOutputDirectory(ctx context.Context, in *jobmanagerpb.CheckOutputDirectoryRequest, opts ...grpc.CallOption) (*jobmanagerpb.CheckOutputDirectoryResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CheckOutputDirectory", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.CheckOutputDirectoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckOutputDirectory indicates an expected call of CheckOutputDirectory.
+func (mr *MockJobManagerClientMockRecorder) CheckOutputDirectory(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOutputDirectory", reflect.TypeOf((*MockJobManagerClient)(nil).CheckOutputDirectory), varargs...)
+}
+
+// DeleteJob mocks base method.
+func (m *MockJobManagerClient) DeleteJob(ctx context.Context, in *jobmanagerpb.DeleteJobRequest, opts ...grpc.CallOption) (*jobmanagerpb.DeleteJobResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteJob", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.DeleteJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteJob indicates an expected call of DeleteJob.
+func (mr *MockJobManagerClientMockRecorder) DeleteJob(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJob", reflect.TypeOf((*MockJobManagerClient)(nil).DeleteJob), varargs...)
+}
+
+// DeleteSchedule mocks base method.
+func (m *MockJobManagerClient) DeleteSchedule(ctx context.Context, in *jobmanagerpb.DeleteScheduleRequest, opts ...grpc.CallOption) (*jobmanagerpb.DeleteScheduleResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteSchedule", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.DeleteScheduleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule.
+func (mr *MockJobManagerClientMockRecorder) DeleteSchedule(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockJobManagerClient)(nil).DeleteSchedule), varargs...)
+}
+
+// DescribeJob mocks base method.
+func (m *MockJobManagerClient) DescribeJob(ctx context.Context, in *jobmanagerpb.DescribeJobRequest, opts ...grpc.CallOption) (*jobmanagerpb.DescribeJobResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeJob", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.DescribeJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeJob indicates an expected call of DescribeJob.
+func (mr *MockJobManagerClientMockRecorder) DescribeJob(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeJob", reflect.TypeOf((*MockJobManagerClient)(nil).DescribeJob), varargs...)
+}
+
+// EndSession mocks base method.
+func (m *MockJobManagerClient) EndSession(ctx context.Context, in *jobmanagerpb.EndSessionRequest, opts ...grpc.CallOption) (*jobmanagerpb.EndSessionResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EndSession", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.EndSessionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndSession indicates an expected call of EndSession.
+func (mr *MockJobManagerClientMockRecorder) EndSession(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndSession", reflect.TypeOf((*MockJobManagerClient)(nil).EndSession), varargs...)
+}
+
+// ExportJobs mocks base method.
+func (m *MockJobManagerClient) ExportJobs(ctx context.Context, in *jobmanagerpb.ExportJobsRequest, opts ...grpc.CallOption) (jobmanagerpb.JobManager_ExportJobsClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportJobs", varargs...)
+	ret0, _ := ret[0].(jobmanagerpb.JobManager_ExportJobsClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportJobs indicates an expected call of ExportJobs.
+func (mr *MockJobManagerClientMockRecorder) ExportJobs(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportJobs", reflect.TypeOf((*MockJobManagerClient)(nil).ExportJobs), varargs...)
+}
+
+// GetJobEvents mocks base method.
+func (m *MockJobManagerClient) GetJobEvents(ctx context.Context, in *jobmanagerpb.GetJobEventsRequest, opts ...grpc.CallOption) (*jobmanagerpb.GetJobEventsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetJobEvents", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobEventsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobEvents indicates an expected call of GetJobEvents.
+func (mr *MockJobManagerClientMockRecorder) GetJobEvents(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobEvents", reflect.TypeOf((*MockJobManagerClient)(nil).GetJobEvents), varargs...)
+}
+
+// GetJobHistory mocks base method.
+func (m *MockJobManagerClient) GetJobHistory(ctx context.Context, in *jobmanagerpb.GetJobHistoryRequest, opts ...grpc.CallOption) (*jobmanagerpb.GetJobHistoryResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetJobHistory", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobHistoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobHistory indicates an expected call of GetJobHistory.
+func (mr *MockJobManagerClientMockRecorder) GetJobHistory(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobHistory", reflect.TypeOf((*MockJobManagerClient)(nil).GetJobHistory), varargs...)
+}
+
+// GetJobOutput mocks base method.
+func (m *MockJobManagerClient) GetJobOutput(ctx context.Context, in *jobmanagerpb.GetJobOutputRequest, opts ...grpc.CallOption) (jobmanagerpb.JobManager_GetJobOutputClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetJobOutput", varargs...)
+	ret0, _ := ret[0].(jobmanagerpb.JobManager_GetJobOutputClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobOutput indicates an expected call of GetJobOutput.
+func (mr *MockJobManagerClientMockRecorder) GetJobOutput(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobOutput", reflect.TypeOf((*MockJobManagerClient)(nil).GetJobOutput), varargs...)
+}
+
+// GetJobProgress mocks base method.
+func (m *MockJobManagerClient) GetJobProgress(ctx context.Context, in *jobmanagerpb.GetJobProgressRequest, opts ...grpc.CallOption) (jobmanagerpb.JobManager_GetJobProgressClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetJobProgress", varargs...)
+	ret0, _ := ret[0].(jobmanagerpb.JobManager_GetJobProgressClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobProgress indicates an expected call of GetJobProgress.
+func (mr *MockJobManagerClientMockRecorder) GetJobProgress(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobProgress", reflect.TypeOf((*MockJobManagerClient)(nil).GetJobProgress), varargs...)
+}
+
+// GetJobStats mocks base method.
+func (m *MockJobManagerClient) GetJobStats(ctx context.Context, in *jobmanagerpb.GetJobStatsRequest, opts ...grpc.CallOption) (*jobmanagerpb.GetJobStatsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetJobStats", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobStats indicates an expected call of GetJobStats.
+func (mr *MockJobManagerClientMockRecorder) GetJobStats(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobStats", reflect.TypeOf((*MockJobManagerClient)(nil).GetJobStats), varargs...)
+}
+
+// GetOutputSegment mocks base method.
+func (m *MockJobManagerClient) GetOutputSegment(ctx context.Context, in *jobmanagerpb.GetOutputSegmentRequest, opts ...grpc.CallOption) (jobmanagerpb.JobManager_GetOutputSegmentClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetOutputSegment", varargs...)
+	ret0, _ := ret[0].(jobmanagerpb.JobManager_GetOutputSegmentClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutputSegment indicates an expected call of GetOutputSegment.
+func (mr *MockJobManagerClientMockRecorder) GetOutputSegment(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutputSegment", reflect.TypeOf((*MockJobManagerClient)(nil).GetOutputSegment), varargs...)
+}
+
+// GetServerInfo mocks base method.
+func (m *MockJobManagerClient) GetServerInfo(ctx context.Context, in *jobmanagerpb.GetServerInfoRequest, opts ...grpc.CallOption) (*jobmanagerpb.GetServerInfoResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetServerInfo", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.GetServerInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerInfo indicates an expected call of GetServerInfo.
+func (mr *MockJobManagerClientMockRecorder) GetServerInfo(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerInfo", reflect.TypeOf((*MockJobManagerClient)(nil).GetServerInfo), varargs...)
+}
+
+// GetStatus mocks base method.
+func (m *MockJobManagerClient) GetStatus(ctx context.Context, in *jobmanagerpb.GetStatusRequest, opts ...grpc.CallOption) (*jobmanagerpb.GetStatusResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetStatus", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.GetStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatus indicates an expected call of GetStatus.
+func (mr *MockJobManagerClientMockRecorder) GetStatus(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockJobManagerClient)(nil).GetStatus), varargs...)
+}
+
+// GetUsageSummary mocks base method.
+func (m *MockJobManagerClient) GetUsageSummary(ctx context.Context, in *jobmanagerpb.GetUsageSummaryRequest, opts ...grpc.CallOption) (*jobmanagerpb.GetUsageSummaryResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetUsageSummary", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.GetUsageSummaryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsageSummary indicates an expected call of GetUsageSummary.
+func (mr *MockJobManagerClientMockRecorder) GetUsageSummary(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsageSummary", reflect.TypeOf((*MockJobManagerClient)(nil).GetUsageSummary), varargs...)
+}
+
+// ListJobs mocks base method.
+func (m *MockJobManagerClient) ListJobs(ctx context.Context, in *jobmanagerpb.ListJobsRequest, opts ...grpc.CallOption) (*jobmanagerpb.ListJobsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListJobs", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.ListJobsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobs indicates an expected call of ListJobs.
+func (mr *MockJobManagerClientMockRecorder) ListJobs(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobs", reflect.TypeOf((*MockJobManagerClient)(nil).ListJobs), varargs...)
+}
+
+// ListOutputSegments mocks base method.
+func (m *MockJobManagerClient) ListOutputSegments(ctx context.Context, in *jobmanagerpb.ListOutputSegmentsRequest, opts ...grpc.CallOption) (*jobmanagerpb.ListOutputSegmentsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListOutputSegments", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.ListOutputSegmentsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutputSegments indicates an expected call of ListOutputSegments.
+func (mr *MockJobManagerClientMockRecorder) ListOutputSegments(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutputSegments", reflect.TypeOf((*MockJobManagerClient)(nil).ListOutputSegments), varargs...)
+}
+
+// ListScheduleRuns mocks base method.
+func (m *MockJobManagerClient) ListScheduleRuns(ctx context.Context, in *jobmanagerpb.ListScheduleRunsRequest, opts ...grpc.CallOption) (*jobmanagerpb.ListScheduleRunsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListScheduleRuns", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.ListScheduleRunsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduleRuns indicates an expected call of ListScheduleRuns.
+func (mr *MockJobManagerClientMockRecorder) ListScheduleRuns(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduleRuns", reflect.TypeOf((*MockJobManagerClient)(nil).ListScheduleRuns), varargs...)
+}
+
+// ListSchedules mocks base method.
+func (m *MockJobManagerClient) ListSchedules(ctx context.Context, in *jobmanagerpb.ListSchedulesRequest, opts ...grpc.CallOption) (*jobmanagerpb.ListSchedulesResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListSchedules", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.ListSchedulesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockJobManagerClientMockRecorder) ListSchedules(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockJobManagerClient)(nil).ListSchedules), varargs...)
+}
+
+// PreviewTemplate mocks base method.
+func (m *MockJobManagerClient) PreviewTemplate(ctx context.Context, in *jobmanagerpb.PreviewTemplateRequest, opts ...grpc.CallOption) (*jobmanagerpb.PreviewTemplateResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PreviewTemplate", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.PreviewTemplateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewTemplate indicates an expected call of PreviewTemplate.
+func (mr *MockJobManagerClientMockRecorder) PreviewTemplate(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewTemplate", reflect.TypeOf((*MockJobManagerClient)(nil).PreviewTemplate), varargs...)
+}
+
+// PutSchedule mocks base method.
+func (m *MockJobManagerClient) PutSchedule(ctx context.Context, in *jobmanagerpb.PutScheduleRequest, opts ...grpc.CallOption) (*jobmanagerpb.PutScheduleResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PutSchedule", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.PutScheduleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutSchedule indicates an expected call of PutSchedule.
+func (mr *MockJobManagerClientMockRecorder) PutSchedule(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutSchedule", reflect.TypeOf((*MockJobManagerClient)(nil).PutSchedule), varargs...)
+}
+
+// RenewJobLease mocks base method.
+func (m *MockJobManagerClient) RenewJobLease(ctx context.Context, in *jobmanagerpb.RenewJobLeaseRequest, opts ...grpc.CallOption) (*jobmanagerpb.RenewJobLeaseResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RenewJobLease", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.RenewJobLeaseResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenewJobLease indicates an expected call of RenewJobLease.
+func (mr *MockJobManagerClientMockRecorder) RenewJobLease(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewJobLease", reflect.TypeOf((*MockJobManagerClient)(nil).RenewJobLease), varargs...)
+}
+
+// ReportJobProgress mocks base method.
+func (m *MockJobManagerClient) ReportJobProgress(ctx context.Context, in *jobmanagerpb.ReportJobProgressRequest, opts ...grpc.CallOption) (*jobmanagerpb.ReportJobProgressResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReportJobProgress", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.ReportJobProgressResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReportJobProgress indicates an expected call of ReportJobProgress.
+func (mr *MockJobManagerClientMockRecorder) ReportJobProgress(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportJobProgress", reflect.TypeOf((*MockJobManagerClient)(nil).ReportJobProgress), varargs...)
+}
+
+// RestoreJob mocks base method.
+func (m *MockJobManagerClient) RestoreJob(ctx context.Context, in *jobmanagerpb.RestoreJobRequest, opts ...grpc.CallOption) (*jobmanagerpb.RestoreJobResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RestoreJob", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.RestoreJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreJob indicates an expected call of RestoreJob.
+func (mr *MockJobManagerClientMockRecorder) RestoreJob(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreJob", reflect.TypeOf((*MockJobManagerClient)(nil).RestoreJob), varargs...)
+}
+
+// StartJob mocks base method.
+func (m *MockJobManagerClient) StartJob(ctx context.Context, in *jobmanagerpb.StartJobRequest, opts ...grpc.CallOption) (*jobmanagerpb.StartJobResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StartJob", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.StartJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartJob indicates an expected call of StartJob.
+func (mr *MockJobManagerClientMockRecorder) StartJob(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartJob", reflect.TypeOf((*MockJobManagerClient)(nil).StartJob), varargs...)
+}
+
+// StopJob mocks base method.
+func (m *MockJobManagerClient) StopJob(ctx context.Context, in *jobmanagerpb.StopJobRequest, opts ...grpc.CallOption) (*jobmanagerpb.StopJobResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StopJob", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.StopJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopJob indicates an expected call of StopJob.
+func (mr *MockJobManagerClientMockRecorder) StopJob(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopJob", reflect.TypeOf((*MockJobManagerClient)(nil).StopJob), varargs...)
+}
+
+// StreamServerLogs mocks base method.
+func (m *MockJobManagerClient) StreamServerLogs(ctx context.Context, in *jobmanagerpb.StreamServerLogsRequest, opts ...grpc.CallOption) (jobmanagerpb.JobManager_StreamServerLogsClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StreamServerLogs", varargs...)
+	ret0, _ := ret[0].(jobmanagerpb.JobManager_StreamServerLogsClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamServerLogs indicates an expected call of StreamServerLogs.
+func (mr *MockJobManagerClientMockRecorder) StreamServerLogs(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamServerLogs", reflect.TypeOf((*MockJobManagerClient)(nil).StreamServerLogs), varargs...)
+}
+
+// WaitJob mocks base method.
+func (m *MockJobManagerClient) WaitJob(ctx context.Context, in *jobmanagerpb.WaitJobRequest, opts ...grpc.CallOption) (*jobmanagerpb.GetStatusResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WaitJob", varargs...)
+	ret0, _ := ret[0].(*jobmanagerpb.GetStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitJob indicates an expected call of WaitJob.
+func (mr *MockJobManagerClientMockRecorder) WaitJob(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitJob", reflect.TypeOf((*MockJobManagerClient)(nil).WaitJob), varargs...)
+}
+
+// WriteJobStdin mocks base method.
+func (m *MockJobManagerClient) WriteJobStdin(ctx context.Context, opts ...grpc.CallOption) (jobmanagerpb.JobManager_WriteJobStdinClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WriteJobStdin", varargs...)
+	ret0, _ := ret[0].(jobmanagerpb.JobManager_WriteJobStdinClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteJobStdin indicates an expected call of WriteJobStdin.
+func (mr *MockJobManagerClientMockRecorder) WriteJobStdin(ctx any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteJobStdin", reflect.TypeOf((*MockJobManagerClient)(nil).WriteJobStdin), varargs...)
+}
+
+// MockJobManager_GetJobOutputClient is a mock of JobManager_GetJobOutputClient interface.
+type MockJobManager_GetJobOutputClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_GetJobOutputClientMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_GetJobOutputClientMockRecorder is the mock recorder for MockJobManager_GetJobOutputClient.
+type MockJobManager_GetJobOutputClientMockRecorder struct {
+	mock *MockJobManager_GetJobOutputClient
+}
+
+// NewMockJobManager_GetJobOutputClient creates a new mock instance.
+func NewMockJobManager_GetJobOutputClient(ctrl *gomock.Controller) *MockJobManager_GetJobOutputClient {
+	mock := &MockJobManager_GetJobOutputClient{ctrl: ctrl}
+	mock.recorder = &MockJobManager_GetJobOutputClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_GetJobOutputClient) EXPECT() *MockJobManager_GetJobOutputClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockJobManager_GetJobOutputClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockJobManager_GetJobOutputClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockJobManager_GetJobOutputClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockJobManager_GetJobOutputClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_GetJobOutputClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_GetJobOutputClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockJobManager_GetJobOutputClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockJobManager_GetJobOutputClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockJobManager_GetJobOutputClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockJobManager_GetJobOutputClient) Recv() (*jobmanagerpb.GetJobOutputResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobOutputResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockJobManager_GetJobOutputClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockJobManager_GetJobOutputClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_GetJobOutputClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_GetJobOutputClientMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_GetJobOutputClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_GetJobOutputClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_GetJobOutputClientMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_GetJobOutputClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockJobManager_GetJobOutputClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockJobManager_GetJobOutputClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockJobManager_GetJobOutputClient)(nil).Trailer))
+}
+
+// MockJobManager_ExportJobsClient is a mock of JobManager_ExportJobsClient interface.
+type MockJobManager_ExportJobsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_ExportJobsClientMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_ExportJobsClientMockRecorder is the mock recorder for MockJobManager_ExportJobsClient.
+type MockJobManager_ExportJobsClientMockRecorder struct {
+	mock *MockJobManager_ExportJobsClient
+}
+
+// NewMockJobManager_ExportJobsClient creates a new mock instance.
+func NewMockJobManager_ExportJobsClient(ctrl *gomock.Controller) *MockJobManager_ExportJobsClient {
+	mock := &MockJobManager_ExportJobsClient{ctrl: ctrl}
+	mock.recorder = &MockJobManager_ExportJobsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_ExportJobsClient) EXPECT() *MockJobManager_ExportJobsClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockJobManager_ExportJobsClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockJobManager_ExportJobsClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockJobManager_ExportJobsClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockJobManager_ExportJobsClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_ExportJobsClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_ExportJobsClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockJobManager_ExportJobsClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockJobManager_ExportJobsClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockJobManager_ExportJobsClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockJobManager_ExportJobsClient) Recv() (*jobmanagerpb.JobRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*jobmanagerpb.JobRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockJobManager_ExportJobsClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockJobManager_ExportJobsClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_ExportJobsClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_ExportJobsClientMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_ExportJobsClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_ExportJobsClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_ExportJobsClientMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_ExportJobsClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockJobManager_ExportJobsClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockJobManager_ExportJobsClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockJobManager_ExportJobsClient)(nil).Trailer))
+}
+
+// MockJobManager_GetOutputSegmentClient is a mock of JobManager_GetOutputSegmentClient interface.
+type MockJobManager_GetOutputSegmentClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_GetOutputSegmentClientMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_GetOutputSegmentClientMockRecorder is the mock recorder for MockJobManager_GetOutputSegmentClient.
+type MockJobManager_GetOutputSegmentClientMockRecorder struct {
+	mock *MockJobManager_GetOutputSegmentClient
+}
+
+// NewMockJobManager_GetOutputSegmentClient creates a new mock instance.
+func NewMockJobManager_GetOutputSegmentClient(ctrl *gomock.Controller) *MockJobManager_GetOutputSegmentClient {
+	mock := &MockJobManager_GetOutputSegmentClient{ctrl: ctrl}
+	mock.recorder = &MockJobManager_GetOutputSegmentClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_GetOutputSegmentClient) EXPECT() *MockJobManager_GetOutputSegmentClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockJobManager_GetOutputSegmentClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockJobManager_GetOutputSegmentClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockJobManager_GetOutputSegmentClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockJobManager_GetOutputSegmentClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_GetOutputSegmentClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_GetOutputSegmentClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockJobManager_GetOutputSegmentClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockJobManager_GetOutputSegmentClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockJobManager_GetOutputSegmentClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockJobManager_GetOutputSegmentClient) Recv() (*jobmanagerpb.GetJobOutputResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobOutputResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockJobManager_GetOutputSegmentClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockJobManager_GetOutputSegmentClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_GetOutputSegmentClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_GetOutputSegmentClientMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_GetOutputSegmentClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_GetOutputSegmentClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_GetOutputSegmentClientMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_GetOutputSegmentClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockJobManager_GetOutputSegmentClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockJobManager_GetOutputSegmentClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockJobManager_GetOutputSegmentClient)(nil).Trailer))
+}
+
+// MockJobManager_GetJobProgressClient is a mock of JobManager_GetJobProgressClient interface.
+type MockJobManager_GetJobProgressClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_GetJobProgressClientMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_GetJobProgressClientMockRecorder is the mock recorder for MockJobManager_GetJobProgressClient.
+type MockJobManager_GetJobProgressClientMockRecorder struct {
+	mock *MockJobManager_GetJobProgressClient
+}
+
+// NewMockJobManager_GetJobProgressClient creates a new mock instance.
+func NewMockJobManager_GetJobProgressClient(ctrl *gomock.Controller) *MockJobManager_GetJobProgressClient {
+	mock := &MockJobManager_GetJobProgressClient{ctrl: ctrl}
+	mock.recorder = &MockJobManager_GetJobProgressClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_GetJobProgressClient) EXPECT() *MockJobManager_GetJobProgressClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockJobManager_GetJobProgressClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockJobManager_GetJobProgressClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockJobManager_GetJobProgressClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockJobManager_GetJobProgressClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_GetJobProgressClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_GetJobProgressClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockJobManager_GetJobProgressClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockJobManager_GetJobProgressClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockJobManager_GetJobProgressClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockJobManager_GetJobProgressClient) Recv() (*jobmanagerpb.GetJobProgressResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobProgressResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockJobManager_GetJobProgressClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockJobManager_GetJobProgressClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_GetJobProgressClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_GetJobProgressClientMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_GetJobProgressClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_GetJobProgressClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_GetJobProgressClientMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_GetJobProgressClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockJobManager_GetJobProgressClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockJobManager_GetJobProgressClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockJobManager_GetJobProgressClient)(nil).Trailer))
+}
+
+// MockJobManager_StreamServerLogsClient is a mock of JobManager_StreamServerLogsClient interface.
+type MockJobManager_StreamServerLogsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_StreamServerLogsClientMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_StreamServerLogsClientMockRecorder is the mock recorder for MockJobManager_StreamServerLogsClient.
+type MockJobManager_StreamServerLogsClientMockRecorder struct {
+	mock *MockJobManager_StreamServerLogsClient
+}
+
+// NewMockJobManager_StreamServerLogsClient creates a new mock instance.
+func NewMockJobManager_StreamServerLogsClient(ctrl *gomock.Controller) *MockJobManager_StreamServerLogsClient {
+	mock := &MockJobManager_StreamServerLogsClient{ctrl: ctrl}
+	mock.recorder = &MockJobManager_StreamServerLogsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_StreamServerLogsClient) EXPECT() *MockJobManager_StreamServerLogsClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockJobManager_StreamServerLogsClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockJobManager_StreamServerLogsClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockJobManager_StreamServerLogsClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockJobManager_StreamServerLogsClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_StreamServerLogsClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_StreamServerLogsClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockJobManager_StreamServerLogsClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockJobManager_StreamServerLogsClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockJobManager_StreamServerLogsClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockJobManager_StreamServerLogsClient) Recv() (*jobmanagerpb.ServerLogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*jobmanagerpb.ServerLogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockJobManager_StreamServerLogsClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockJobManager_StreamServerLogsClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_StreamServerLogsClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_StreamServerLogsClientMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_StreamServerLogsClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_StreamServerLogsClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_StreamServerLogsClientMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_StreamServerLogsClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockJobManager_StreamServerLogsClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockJobManager_StreamServerLogsClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockJobManager_StreamServerLogsClient)(nil).Trailer))
+}
+
+// MockJobManager_WriteJobStdinClient is a mock of JobManager_WriteJobStdinClient interface.
+type MockJobManager_WriteJobStdinClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_WriteJobStdinClientMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_WriteJobStdinClientMockRecorder is the mock recorder for MockJobManager_WriteJobStdinClient.
+type MockJobManager_WriteJobStdinClientMockRecorder struct {
+	mock *MockJobManager_WriteJobStdinClient
+}
+
+// NewMockJobManager_WriteJobStdinClient creates a new mock instance.
+func NewMockJobManager_WriteJobStdinClient(ctrl *gomock.Controller) *MockJobManager_WriteJobStdinClient {
+	mock := &MockJobManager_WriteJobStdinClient{ctrl: ctrl}
+	mock.recorder = &MockJobManager_WriteJobStdinClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_WriteJobStdinClient) EXPECT() *MockJobManager_WriteJobStdinClientMockRecorder {
+	return m.recorder
+}
+
+// CloseAndRecv mocks base method.
+func (m *MockJobManager_WriteJobStdinClient) CloseAndRecv() (*jobmanagerpb.WriteJobStdinResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAndRecv")
+	ret0, _ := ret[0].(*jobmanagerpb.WriteJobStdinResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseAndRecv indicates an expected call of CloseAndRecv.
+func (mr *MockJobManager_WriteJobStdinClientMockRecorder) CloseAndRecv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRecv", reflect.TypeOf((*MockJobManager_WriteJobStdinClient)(nil).CloseAndRecv))
+}
+
+// CloseSend mocks base method.
+func (m *MockJobManager_WriteJobStdinClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockJobManager_WriteJobStdinClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockJobManager_WriteJobStdinClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockJobManager_WriteJobStdinClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_WriteJobStdinClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_WriteJobStdinClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockJobManager_WriteJobStdinClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockJobManager_WriteJobStdinClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockJobManager_WriteJobStdinClient)(nil).Header))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_WriteJobStdinClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_WriteJobStdinClientMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_WriteJobStdinClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockJobManager_WriteJobStdinClient) Send(arg0 *jobmanagerpb.WriteJobStdinRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockJobManager_WriteJobStdinClientMockRecorder) Send(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockJobManager_WriteJobStdinClient)(nil).Send), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_WriteJobStdinClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_WriteJobStdinClientMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_WriteJobStdinClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockJobManager_WriteJobStdinClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockJobManager_WriteJobStdinClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockJobManager_WriteJobStdinClient)(nil).Trailer))
+}
+
+// MockJobManagerServer is a mock of JobManagerServer interface.
+type MockJobManagerServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManagerServerMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManagerServerMockRecorder is the mock recorder for MockJobManagerServer.
+type MockJobManagerServerMockRecorder struct {
+	mock *MockJobManagerServer
+}
+
+// NewMockJobManagerServer creates a new mock instance.
+func NewMockJobManagerServer(ctrl *gomock.Controller) *MockJobManagerServer {
+	mock := &MockJobManagerServer{ctrl: ctrl}
+	mock.recorder = &MockJobManagerServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManagerServer) EXPECT() *MockJobManagerServerMockRecorder {
+	return m.recorder
+}
+
+// AdoptProcess mocks base method.
+func (m *MockJobManagerServer) AdoptProcess(arg0 context.Context, arg1 *jobmanagerpb.AdoptProcessRequest) (*jobmanagerpb.AdoptProcessResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdoptProcess", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.AdoptProcessResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdoptProcess indicates an expected call of AdoptProcess.
+func (mr *MockJobManagerServerMockRecorder) AdoptProcess(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdoptProcess", reflect.TypeOf((*MockJobManagerServer)(nil).AdoptProcess), arg0, arg1)
+}
+
+// AnnotateJob mocks base method.
+func (m *MockJobManagerServer) AnnotateJob(arg0 context.Context, arg1 *jobmanagerpb.AnnotateJobRequest) (*jobmanagerpb.AnnotateJobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnnotateJob", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.AnnotateJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnnotateJob indicates an expected call of AnnotateJob.
+func (mr *MockJobManagerServerMockRecorder) AnnotateJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotateJob", reflect.TypeOf((*MockJobManagerServer)(nil).AnnotateJob), arg0, arg1)
+}
+
+// CheckOutputDirectory mocks base method.
+func (m *MockJobManagerServer) CheckOutputDirectory(arg0 context.Context, arg1 *jobmanagerpb.CheckOutputDirectoryRequest) (*jobmanagerpb.CheckOutputDirectoryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckOutputDirectory", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.CheckOutputDirectoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckOutputDirectory indicates an expected call of CheckOutputDirectory.
+func (mr *MockJobManagerServerMockRecorder) CheckOutputDirectory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOutputDirectory", reflect.TypeOf((*MockJobManagerServer)(nil).CheckOutputDirectory), arg0, arg1)
+}
+
+// DeleteJob mocks base method.
+func (m *MockJobManagerServer) DeleteJob(arg0 context.Context, arg1 *jobmanagerpb.DeleteJobRequest) (*jobmanagerpb.DeleteJobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteJob", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.DeleteJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteJob indicates an expected call of DeleteJob.
+func (mr *MockJobManagerServerMockRecorder) DeleteJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJob", reflect.TypeOf((*MockJobManagerServer)(nil).DeleteJob), arg0, arg1)
+}
+
+// DeleteSchedule mocks base method.
+func (m *MockJobManagerServer) DeleteSchedule(arg0 context.Context, arg1 *jobmanagerpb.DeleteScheduleRequest) (*jobmanagerpb.DeleteScheduleResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedule", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.DeleteScheduleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule.
+func (mr *MockJobManagerServerMockRecorder) DeleteSchedule(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockJobManagerServer)(nil).DeleteSchedule), arg0, arg1)
+}
+
+// DescribeJob mocks base method.
+func (m *MockJobManagerServer) DescribeJob(arg0 context.Context, arg1 *jobmanagerpb.DescribeJobRequest) (*jobmanagerpb.DescribeJobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeJob", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.DescribeJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeJob indicates an expected call of DescribeJob.
+func (mr *MockJobManagerServerMockRecorder) DescribeJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeJob", reflect.TypeOf((*MockJobManagerServer)(nil).DescribeJob), arg0, arg1)
+}
+
+// EndSession mocks base method.
+func (m *MockJobManagerServer) EndSession(arg0 context.Context, arg1 *jobmanagerpb.EndSessionRequest) (*jobmanagerpb.EndSessionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndSession", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.EndSessionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndSession indicates an expected call of EndSession.
+func (mr *MockJobManagerServerMockRecorder) EndSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndSession", reflect.TypeOf((*MockJobManagerServer)(nil).EndSession), arg0, arg1)
+}
+
+// ExportJobs mocks base method.
+func (m *MockJobManagerServer) ExportJobs(arg0 *jobmanagerpb.ExportJobsRequest, arg1 jobmanagerpb.JobManager_ExportJobsServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportJobs", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportJobs indicates an expected call of ExportJobs.
+func (mr *MockJobManagerServerMockRecorder) ExportJobs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportJobs", reflect.TypeOf((*MockJobManagerServer)(nil).ExportJobs), arg0, arg1)
+}
+
+// GetJobEvents mocks base method.
+func (m *MockJobManagerServer) GetJobEvents(arg0 context.Context, arg1 *jobmanagerpb.GetJobEventsRequest) (*jobmanagerpb.GetJobEventsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobEvents", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobEventsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobEvents indicates an expected call of GetJobEvents.
+func (mr *MockJobManagerServerMockRecorder) GetJobEvents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobEvents", reflect.TypeOf((*MockJobManagerServer)(nil).GetJobEvents), arg0, arg1)
+}
+
+// GetJobHistory mocks base method.
+func (m *MockJobManagerServer) GetJobHistory(arg0 context.Context, arg1 *jobmanagerpb.GetJobHistoryRequest) (*jobmanagerpb.GetJobHistoryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobHistory", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobHistoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobHistory indicates an expected call of GetJobHistory.
+func (mr *MockJobManagerServerMockRecorder) GetJobHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobHistory", reflect.TypeOf((*MockJobManagerServer)(nil).GetJobHistory), arg0, arg1)
+}
+
+// GetJobOutput mocks base method.
+func (m *MockJobManagerServer) GetJobOutput(arg0 *jobmanagerpb.GetJobOutputRequest, arg1 jobmanagerpb.JobManager_GetJobOutputServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobOutput", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetJobOutput indicates an expected call of GetJobOutput.
+func (mr *MockJobManagerServerMockRecorder) GetJobOutput(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobOutput", reflect.TypeOf((*MockJobManagerServer)(nil).GetJobOutput), arg0, arg1)
+}
+
+// GetJobProgress mocks base method.
+func (m *MockJobManagerServer) GetJobProgress(arg0 *jobmanagerpb.GetJobProgressRequest, arg1 jobmanagerpb.JobManager_GetJobProgressServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobProgress", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetJobProgress indicates an expected call of GetJobProgress.
+func (mr *MockJobManagerServerMockRecorder) GetJobProgress(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobProgress", reflect.TypeOf((*MockJobManagerServer)(nil).GetJobProgress), arg0, arg1)
+}
+
+// GetJobStats mocks base method.
+func (m *MockJobManagerServer) GetJobStats(arg0 context.Context, arg1 *jobmanagerpb.GetJobStatsRequest) (*jobmanagerpb.GetJobStatsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobStats", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.GetJobStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobStats indicates an expected call of GetJobStats.
+func (mr *MockJobManagerServerMockRecorder) GetJobStats(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobStats", reflect.TypeOf((*MockJobManagerServer)(nil).GetJobStats), arg0, arg1)
+}
+
+// GetOutputSegment mocks base method.
+func (m *MockJobManagerServer) GetOutputSegment(arg0 *jobmanagerpb.GetOutputSegmentRequest, arg1 jobmanagerpb.JobManager_GetOutputSegmentServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutputSegment", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetOutputSegment indicates an expected call of GetOutputSegment.
+func (mr *MockJobManagerServerMockRecorder) GetOutputSegment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutputSegment", reflect.TypeOf((*MockJobManagerServer)(nil).GetOutputSegment), arg0, arg1)
+}
+
+// GetServerInfo mocks base method.
+func (m *MockJobManagerServer) GetServerInfo(arg0 context.Context, arg1 *jobmanagerpb.GetServerInfoRequest) (*jobmanagerpb.GetServerInfoResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerInfo", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.GetServerInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerInfo indicates an expected call of GetServerInfo.
+func (mr *MockJobManagerServerMockRecorder) GetServerInfo(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerInfo", reflect.TypeOf((*MockJobManagerServer)(nil).GetServerInfo), arg0, arg1)
+}
+
+// GetStatus mocks base method.
+func (m *MockJobManagerServer) GetStatus(arg0 context.Context, arg1 *jobmanagerpb.GetStatusRequest) (*jobmanagerpb.GetStatusResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatus", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.GetStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatus indicates an expected call of GetStatus.
+func (mr *MockJobManagerServerMockRecorder) GetStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockJobManagerServer)(nil).GetStatus), arg0, arg1)
+}
+
+// GetUsageSummary mocks base method.
+func (m *MockJobManagerServer) GetUsageSummary(arg0 context.Context, arg1 *jobmanagerpb.GetUsageSummaryRequest) (*jobmanagerpb.GetUsageSummaryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsageSummary", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.GetUsageSummaryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsageSummary indicates an expected call of GetUsageSummary.
+func (mr *MockJobManagerServerMockRecorder) GetUsageSummary(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsageSummary", reflect.TypeOf((*MockJobManagerServer)(nil).GetUsageSummary), arg0, arg1)
+}
+
+// ListJobs mocks base method.
+func (m *MockJobManagerServer) ListJobs(arg0 context.Context, arg1 *jobmanagerpb.ListJobsRequest) (*jobmanagerpb.ListJobsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobs", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.ListJobsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobs indicates an expected call of ListJobs.
+func (mr *MockJobManagerServerMockRecorder) ListJobs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobs", reflect.TypeOf((*MockJobManagerServer)(nil).ListJobs), arg0, arg1)
+}
+
+// ListOutputSegments mocks base method.
+func (m *MockJobManagerServer) ListOutputSegments(arg0 context.Context, arg1 *jobmanagerpb.ListOutputSegmentsRequest) (*jobmanagerpb.ListOutputSegmentsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutputSegments", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.ListOutputSegmentsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutputSegments indicates an expected call of ListOutputSegments.
+func (mr *MockJobManagerServerMockRecorder) ListOutputSegments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutputSegments", reflect.TypeOf((*MockJobManagerServer)(nil).ListOutputSegments), arg0, arg1)
+}
+
+// ListScheduleRuns mocks base method.
+func (m *MockJobManagerServer) ListScheduleRuns(arg0 context.Context, arg1 *jobmanagerpb.ListScheduleRunsRequest) (*jobmanagerpb.ListScheduleRunsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduleRuns", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.ListScheduleRunsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduleRuns indicates an expected call of ListScheduleRuns.
+func (mr *MockJobManagerServerMockRecorder) ListScheduleRuns(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduleRuns", reflect.TypeOf((*MockJobManagerServer)(nil).ListScheduleRuns), arg0, arg1)
+}
+
+// ListSchedules mocks base method.
+func (m *MockJobManagerServer) ListSchedules(arg0 context.Context, arg1 *jobmanagerpb.ListSchedulesRequest) (*jobmanagerpb.ListSchedulesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.ListSchedulesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockJobManagerServerMockRecorder) ListSchedules(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockJobManagerServer)(nil).ListSchedules), arg0, arg1)
+}
+
+// PreviewTemplate mocks base method.
+func (m *MockJobManagerServer) PreviewTemplate(arg0 context.Context, arg1 *jobmanagerpb.PreviewTemplateRequest) (*jobmanagerpb.PreviewTemplateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewTemplate", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.PreviewTemplateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewTemplate indicates an expected call of PreviewTemplate.
+func (mr *MockJobManagerServerMockRecorder) PreviewTemplate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewTemplate", reflect.TypeOf((*MockJobManagerServer)(nil).PreviewTemplate), arg0, arg1)
+}
+
+// PutSchedule mocks base method.
+func (m *MockJobManagerServer) PutSchedule(arg0 context.Context, arg1 *jobmanagerpb.PutScheduleRequest) (*jobmanagerpb.PutScheduleResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutSchedule", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.PutScheduleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutSchedule indicates an expected call of PutSchedule.
+func (mr *MockJobManagerServerMockRecorder) PutSchedule(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutSchedule", reflect.TypeOf((*MockJobManagerServer)(nil).PutSchedule), arg0, arg1)
+}
+
+// RenewJobLease mocks base method.
+func (m *MockJobManagerServer) RenewJobLease(arg0 context.Context, arg1 *jobmanagerpb.RenewJobLeaseRequest) (*jobmanagerpb.RenewJobLeaseResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewJobLease", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.RenewJobLeaseResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenewJobLease indicates an expected call of RenewJobLease.
+func (mr *MockJobManagerServerMockRecorder) RenewJobLease(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewJobLease", reflect.TypeOf((*MockJobManagerServer)(nil).RenewJobLease), arg0, arg1)
+}
+
+// ReportJobProgress mocks base method.
+func (m *MockJobManagerServer) ReportJobProgress(arg0 context.Context, arg1 *jobmanagerpb.ReportJobProgressRequest) (*jobmanagerpb.ReportJobProgressResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportJobProgress", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.ReportJobProgressResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReportJobProgress indicates an expected call of ReportJobProgress.
+func (mr *MockJobManagerServerMockRecorder) ReportJobProgress(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportJobProgress", reflect.TypeOf((*MockJobManagerServer)(nil).ReportJobProgress), arg0, arg1)
+}
+
+// RestoreJob mocks base method.
+func (m *MockJobManagerServer) RestoreJob(arg0 context.Context, arg1 *jobmanagerpb.RestoreJobRequest) (*jobmanagerpb.RestoreJobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreJob", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.RestoreJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreJob indicates an expected call of RestoreJob.
+func (mr *MockJobManagerServerMockRecorder) RestoreJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreJob", reflect.TypeOf((*MockJobManagerServer)(nil).RestoreJob), arg0, arg1)
+}
+
+// StartJob mocks base method.
+func (m *MockJobManagerServer) StartJob(arg0 context.Context, arg1 *jobmanagerpb.StartJobRequest) (*jobmanagerpb.StartJobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartJob", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.StartJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartJob indicates an expected call of StartJob.
+func (mr *MockJobManagerServerMockRecorder) StartJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartJob", reflect.TypeOf((*MockJobManagerServer)(nil).StartJob), arg0, arg1)
+}
+
+// StopJob mocks base method.
+func (m *MockJobManagerServer) StopJob(arg0 context.Context, arg1 *jobmanagerpb.StopJobRequest) (*jobmanagerpb.StopJobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopJob", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.StopJobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopJob indicates an expected call of StopJob.
+func (mr *MockJobManagerServerMockRecorder) StopJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopJob", reflect.TypeOf((*MockJobManagerServer)(nil).StopJob), arg0, arg1)
+}
+
+// StreamServerLogs mocks base method.
+func (m *MockJobManagerServer) StreamServerLogs(arg0 *jobmanagerpb.StreamServerLogsRequest, arg1 jobmanagerpb.JobManager_StreamServerLogsServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamServerLogs", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamServerLogs indicates an expected call of StreamServerLogs.
+func (mr *MockJobManagerServerMockRecorder) StreamServerLogs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamServerLogs", reflect.TypeOf((*MockJobManagerServer)(nil).StreamServerLogs), arg0, arg1)
+}
+
+// WaitJob mocks base method.
+func (m *MockJobManagerServer) WaitJob(arg0 context.Context, arg1 *jobmanagerpb.WaitJobRequest) (*jobmanagerpb.GetStatusResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitJob", arg0, arg1)
+	ret0, _ := ret[0].(*jobmanagerpb.GetStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitJob indicates an expected call of WaitJob.
+func (mr *MockJobManagerServerMockRecorder) WaitJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitJob", reflect.TypeOf((*MockJobManagerServer)(nil).WaitJob), arg0, arg1)
+}
+
+// WriteJobStdin mocks base method.
+func (m *MockJobManagerServer) WriteJobStdin(arg0 jobmanagerpb.JobManager_WriteJobStdinServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteJobStdin", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteJobStdin indicates an expected call of WriteJobStdin.
+func (mr *MockJobManagerServerMockRecorder) WriteJobStdin(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteJobStdin", reflect.TypeOf((*MockJobManagerServer)(nil).WriteJobStdin), arg0)
+}
+
+// mustEmbedUnimplementedJobManagerServer mocks base method.
+func (m *MockJobManagerServer) mustEmbedUnimplementedJobManagerServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedJobManagerServer")
+}
+
+// mustEmbedUnimplementedJobManagerServer indicates an expected call of mustEmbedUnimplementedJobManagerServer.
+func (mr *MockJobManagerServerMockRecorder) mustEmbedUnimplementedJobManagerServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedJobManagerServer", reflect.TypeOf((*MockJobManagerServer)(nil).mustEmbedUnimplementedJobManagerServer))
+}
+
+// MockJobManager_GetJobOutputServer is a mock of JobManager_GetJobOutputServer interface.
+type MockJobManager_GetJobOutputServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_GetJobOutputServerMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_GetJobOutputServerMockRecorder is the mock recorder for MockJobManager_GetJobOutputServer.
+type MockJobManager_GetJobOutputServerMockRecorder struct {
+	mock *MockJobManager_GetJobOutputServer
+}
+
+// NewMockJobManager_GetJobOutputServer creates a new mock instance.
+func NewMockJobManager_GetJobOutputServer(ctrl *gomock.Controller) *MockJobManager_GetJobOutputServer {
+	mock := &MockJobManager_GetJobOutputServer{ctrl: ctrl}
+	mock.recorder = &MockJobManager_GetJobOutputServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_GetJobOutputServer) EXPECT() *MockJobManager_GetJobOutputServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockJobManager_GetJobOutputServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_GetJobOutputServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_GetJobOutputServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_GetJobOutputServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_GetJobOutputServerMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_GetJobOutputServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockJobManager_GetJobOutputServer) Send(arg0 *jobmanagerpb.GetJobOutputResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockJobManager_GetJobOutputServerMockRecorder) Send(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockJobManager_GetJobOutputServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockJobManager_GetJobOutputServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockJobManager_GetJobOutputServerMockRecorder) SendHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockJobManager_GetJobOutputServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_GetJobOutputServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_GetJobOutputServerMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_GetJobOutputServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockJobManager_GetJobOutputServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockJobManager_GetJobOutputServerMockRecorder) SetHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockJobManager_GetJobOutputServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockJobManager_GetJobOutputServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockJobManager_GetJobOutputServerMockRecorder) SetTrailer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockJobManager_GetJobOutputServer)(nil).SetTrailer), arg0)
+}
+
+// MockJobManager_ExportJobsServer is a mock of JobManager_ExportJobsServer interface.
+type MockJobManager_ExportJobsServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_ExportJobsServerMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_ExportJobsServerMockRecorder is the mock recorder for MockJobManager_ExportJobsServer.
+type MockJobManager_ExportJobsServerMockRecorder struct {
+	mock *MockJobManager_ExportJobsServer
+}
+
+// NewMockJobManager_ExportJobsServer creates a new mock instance.
+func NewMockJobManager_ExportJobsServer(ctrl *gomock.Controller) *MockJobManager_ExportJobsServer {
+	mock := &MockJobManager_ExportJobsServer{ctrl: ctrl}
+	mock.recorder = &MockJobManager_ExportJobsServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_ExportJobsServer) EXPECT() *MockJobManager_ExportJobsServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockJobManager_ExportJobsServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_ExportJobsServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_ExportJobsServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_ExportJobsServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_ExportJobsServerMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_ExportJobsServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockJobManager_ExportJobsServer) Send(arg0 *jobmanagerpb.JobRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockJobManager_ExportJobsServerMockRecorder) Send(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockJobManager_ExportJobsServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockJobManager_ExportJobsServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockJobManager_ExportJobsServerMockRecorder) SendHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockJobManager_ExportJobsServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_ExportJobsServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_ExportJobsServerMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_ExportJobsServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockJobManager_ExportJobsServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockJobManager_ExportJobsServerMockRecorder) SetHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockJobManager_ExportJobsServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockJobManager_ExportJobsServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockJobManager_ExportJobsServerMockRecorder) SetTrailer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockJobManager_ExportJobsServer)(nil).SetTrailer), arg0)
+}
+
+// MockJobManager_GetOutputSegmentServer is a mock of JobManager_GetOutputSegmentServer interface.
+type MockJobManager_GetOutputSegmentServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_GetOutputSegmentServerMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_GetOutputSegmentServerMockRecorder is the mock recorder for MockJobManager_GetOutputSegmentServer.
+type MockJobManager_GetOutputSegmentServerMockRecorder struct {
+	mock *MockJobManager_GetOutputSegmentServer
+}
+
+// NewMockJobManager_GetOutputSegmentServer creates a new mock instance.
+func NewMockJobManager_GetOutputSegmentServer(ctrl *gomock.Controller) *MockJobManager_GetOutputSegmentServer {
+	mock := &MockJobManager_GetOutputSegmentServer{ctrl: ctrl}
+	mock.recorder = &MockJobManager_GetOutputSegmentServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_GetOutputSegmentServer) EXPECT() *MockJobManager_GetOutputSegmentServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockJobManager_GetOutputSegmentServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_GetOutputSegmentServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_GetOutputSegmentServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_GetOutputSegmentServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_GetOutputSegmentServerMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_GetOutputSegmentServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockJobManager_GetOutputSegmentServer) Send(arg0 *jobmanagerpb.GetJobOutputResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockJobManager_GetOutputSegmentServerMockRecorder) Send(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockJobManager_GetOutputSegmentServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockJobManager_GetOutputSegmentServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockJobManager_GetOutputSegmentServerMockRecorder) SendHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockJobManager_GetOutputSegmentServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_GetOutputSegmentServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_GetOutputSegmentServerMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_GetOutputSegmentServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockJobManager_GetOutputSegmentServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockJobManager_GetOutputSegmentServerMockRecorder) SetHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockJobManager_GetOutputSegmentServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockJobManager_GetOutputSegmentServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockJobManager_GetOutputSegmentServerMockRecorder) SetTrailer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockJobManager_GetOutputSegmentServer)(nil).SetTrailer), arg0)
+}
+
+// MockJobManager_GetJobProgressServer is a mock of JobManager_GetJobProgressServer interface.
+type MockJobManager_GetJobProgressServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_GetJobProgressServerMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_GetJobProgressServerMockRecorder is the mock recorder for MockJobManager_GetJobProgressServer.
+type MockJobManager_GetJobProgressServerMockRecorder struct {
+	mock *MockJobManager_GetJobProgressServer
+}
+
+// NewMockJobManager_GetJobProgressServer creates a new mock instance.
+func NewMockJobManager_GetJobProgressServer(ctrl *gomock.Controller) *MockJobManager_GetJobProgressServer {
+	mock := &MockJobManager_GetJobProgressServer{ctrl: ctrl}
+	mock.recorder = &MockJobManager_GetJobProgressServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_GetJobProgressServer) EXPECT() *MockJobManager_GetJobProgressServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockJobManager_GetJobProgressServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_GetJobProgressServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_GetJobProgressServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_GetJobProgressServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_GetJobProgressServerMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_GetJobProgressServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockJobManager_GetJobProgressServer) Send(arg0 *jobmanagerpb.GetJobProgressResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockJobManager_GetJobProgressServerMockRecorder) Send(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockJobManager_GetJobProgressServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockJobManager_GetJobProgressServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockJobManager_GetJobProgressServerMockRecorder) SendHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockJobManager_GetJobProgressServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_GetJobProgressServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_GetJobProgressServerMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_GetJobProgressServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockJobManager_GetJobProgressServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockJobManager_GetJobProgressServerMockRecorder) SetHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockJobManager_GetJobProgressServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockJobManager_GetJobProgressServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockJobManager_GetJobProgressServerMockRecorder) SetTrailer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockJobManager_GetJobProgressServer)(nil).SetTrailer), arg0)
+}
+
+// MockJobManager_StreamServerLogsServer is a mock of JobManager_StreamServerLogsServer interface.
+type MockJobManager_StreamServerLogsServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_StreamServerLogsServerMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_StreamServerLogsServerMockRecorder is the mock recorder for MockJobManager_StreamServerLogsServer.
+type MockJobManager_StreamServerLogsServerMockRecorder struct {
+	mock *MockJobManager_StreamServerLogsServer
+}
+
+// NewMockJobManager_StreamServerLogsServer creates a new mock instance.
+func NewMockJobManager_StreamServerLogsServer(ctrl *gomock.Controller) *MockJobManager_StreamServerLogsServer {
+	mock := &MockJobManager_StreamServerLogsServer{ctrl: ctrl}
+	mock.recorder = &MockJobManager_StreamServerLogsServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_StreamServerLogsServer) EXPECT() *MockJobManager_StreamServerLogsServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockJobManager_StreamServerLogsServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_StreamServerLogsServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_StreamServerLogsServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_StreamServerLogsServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_StreamServerLogsServerMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_StreamServerLogsServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockJobManager_StreamServerLogsServer) Send(arg0 *jobmanagerpb.ServerLogEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockJobManager_StreamServerLogsServerMockRecorder) Send(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockJobManager_StreamServerLogsServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockJobManager_StreamServerLogsServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockJobManager_StreamServerLogsServerMockRecorder) SendHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockJobManager_StreamServerLogsServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_StreamServerLogsServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_StreamServerLogsServerMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_StreamServerLogsServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockJobManager_StreamServerLogsServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockJobManager_StreamServerLogsServerMockRecorder) SetHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockJobManager_StreamServerLogsServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockJobManager_StreamServerLogsServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockJobManager_StreamServerLogsServerMockRecorder) SetTrailer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockJobManager_StreamServerLogsServer)(nil).SetTrailer), arg0)
+}
+
+// MockJobManager_WriteJobStdinServer is a mock of JobManager_WriteJobStdinServer interface.
+type MockJobManager_WriteJobStdinServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobManager_WriteJobStdinServerMockRecorder
+	isgomock struct{}
+}
+
+// MockJobManager_WriteJobStdinServerMockRecorder is the mock recorder for MockJobManager_WriteJobStdinServer.
+type MockJobManager_WriteJobStdinServerMockRecorder struct {
+	mock *MockJobManager_WriteJobStdinServer
+}
+
+// NewMockJobManager_WriteJobStdinServer creates a new mock instance.
+func NewMockJobManager_WriteJobStdinServer(ctrl *gomock.Controller) *MockJobManager_WriteJobStdinServer {
+	mock := &MockJobManager_WriteJobStdinServer{ctrl: ctrl}
+	mock.recorder = &MockJobManager_WriteJobStdinServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobManager_WriteJobStdinServer) EXPECT() *MockJobManager_WriteJobStdinServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockJobManager_WriteJobStdinServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockJobManager_WriteJobStdinServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockJobManager_WriteJobStdinServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockJobManager_WriteJobStdinServer) Recv() (*jobmanagerpb.WriteJobStdinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*jobmanagerpb.WriteJobStdinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockJobManager_WriteJobStdinServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockJobManager_WriteJobStdinServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockJobManager_WriteJobStdinServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockJobManager_WriteJobStdinServerMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockJobManager_WriteJobStdinServer)(nil).RecvMsg), m)
+}
+
+// SendAndClose mocks base method.
+func (m *MockJobManager_WriteJobStdinServer) SendAndClose(arg0 *jobmanagerpb.WriteJobStdinResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAndClose", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAndClose indicates an expected call of SendAndClose.
+func (mr *MockJobManager_WriteJobStdinServerMockRecorder) SendAndClose(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAndClose", reflect.TypeOf((*MockJobManager_WriteJobStdinServer)(nil).SendAndClose), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockJobManager_WriteJobStdinServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockJobManager_WriteJobStdinServerMockRecorder) SendHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockJobManager_WriteJobStdinServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockJobManager_WriteJobStdinServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockJobManager_WriteJobStdinServerMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockJobManager_WriteJobStdinServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockJobManager_WriteJobStdinServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockJobManager_WriteJobStdinServerMockRecorder) SetHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockJobManager_WriteJobStdinServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockJobManager_WriteJobStdinServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockJobManager_WriteJobStdinServerMockRecorder) SetTrailer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockJobManager_WriteJobStdinServer)(nil).SetTrailer), arg0)
+}
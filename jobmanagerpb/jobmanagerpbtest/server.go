@@ -0,0 +1,27 @@
+// Package jobmanagerpbtest has test doubles for the JobManager API, so
+// clients can be tested without a server and the other way around.
+// The mocks are generated with gomock (run 'make mocks' after changing
+// jobby.proto)
+package jobmanagerpbtest
+
+import (
+	"github.com/gopheryan/jobby/jobmanagerpb"
+)
+
+// NewServer makes 'mock' something grpc.Server can register. Generated
+// servers must embed UnimplementedJobManagerServer, which a mock from
+// another package can't do itself. Every call goes to the mock
+func NewServer(mock *MockJobManagerServer) jobmanagerpb.JobManagerServer {
+	return &server{MockJobManagerServer: mock}
+}
+
+type unimplementedServer struct {
+	jobmanagerpb.UnimplementedJobManagerServer
+}
+
+type server struct {
+	*MockJobManagerServer
+	// Only here to satisfy the interface. Being embedded a level deeper,
+	// its methods lose out to the mock's
+	unimplementedServer
+}
@@ -0,0 +1,55 @@
+package jobmanagerpbtest_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/gopheryan/jobby/internal/testutils"
+	"github.com/gopheryan/jobby/jobmanagerpb"
+	"github.com/gopheryan/jobby/jobmanagerpb/jobmanagerpbtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+)
+
+var _ jobmanagerpb.JobManagerClient = (*jobmanagerpbtest.MockJobManagerClient)(nil)
+
+// The mock server answers real clients
+func TestServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := jobmanagerpbtest.NewMockJobManagerServer(ctrl)
+	mock.EXPECT().
+		GetStatus(gomock.Any(), gomock.Any()).
+		Return(&jobmanagerpb.GetStatusResponse{OutputContentType: "text/plain"}, nil)
+	mock.EXPECT().
+		GetJobOutput(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ *jobmanagerpb.GetJobOutputRequest, stream jobmanagerpb.JobManager_GetJobOutputServer) error {
+			return stream.Send(&jobmanagerpb.GetJobOutputResponse{Data: []byte("hello")})
+		})
+
+	srv := grpc.NewServer()
+	jobmanagerpb.RegisterJobManagerServer(srv, jobmanagerpbtest.NewServer(mock))
+	local := testutils.GrpcLocalServer{}
+	require.NoError(t, local.ListenAndServe(srv))
+	defer func() {
+		srv.Stop()
+		assert.NoError(t, local.Done())
+	}()
+	client := jobmanagerpb.NewJobManagerClient(local.Conn())
+
+	ctx := context.Background()
+	resp, err := client.GetStatus(ctx, &jobmanagerpb.GetStatusRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", resp.OutputContentType)
+
+	stream, err := client.GetJobOutput(ctx, &jobmanagerpb.GetJobOutputRequest{})
+	require.NoError(t, err)
+	output, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(output.Data))
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
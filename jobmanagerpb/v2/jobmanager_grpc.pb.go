@@ -0,0 +1,1469 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package jobmanagerv2
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// JobManagerClient is the client API for JobManager service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type JobManagerClient interface {
+	StartJob(ctx context.Context, in *StartJobRequest, opts ...grpc.CallOption) (*StartJobResponse, error)
+	StopJob(ctx context.Context, in *StopJobRequest, opts ...grpc.CallOption) (*StopJobResponse, error)
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	// Blocks until the job is finished (no attempt running or queued to run
+	// again), then returns its final status. Use a deadline to give up waiting
+	WaitJob(ctx context.Context, in *WaitJobRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	// Server will close the send-stream once output is exhausted, after a
+	// message with the length and checksum of what it sent (see OutputEnd)
+	// Streams end early with ABORTED if the job is stopped, or NOT_FOUND
+	// if it's deleted, while they're attached
+	GetJobOutput(ctx context.Context, in *GetJobOutputRequest, opts ...grpc.CallOption) (JobManager_GetJobOutputClient, error)
+	// Lists every execution attempt of a job, oldest first
+	GetJobHistory(ctx context.Context, in *GetJobHistoryRequest, opts ...grpc.CallOption) (*GetJobHistoryResponse, error)
+	// Streams a record for every job owned by the caller, oldest first
+	ExportJobs(ctx context.Context, in *ExportJobsRequest, opts ...grpc.CallOption) (JobManager_ExportJobsClient, error)
+	// Jobs owned by the caller that match every given search
+	// parameter, oldest first
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
+	// Describes the node the server runs on
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error)
+	// Resources consumed by each owner's jobs over the server's accounting windows
+	GetUsageSummary(ctx context.Context, in *GetUsageSummaryRequest, opts ...grpc.CallOption) (*GetUsageSummaryResponse, error)
+	// Everything that happened to a job, oldest first. Available for a
+	// while after the job is garbage collected
+	GetJobEvents(ctx context.Context, in *GetJobEventsRequest, opts ...grpc.CallOption) (*GetJobEventsResponse, error)
+	// Segments of a job's output still on disk, oldest first. Only for
+	// jobs started with output_segments or output_window_bytes
+	ListOutputSegments(ctx context.Context, in *ListOutputSegmentsRequest, opts ...grpc.CallOption) (*ListOutputSegmentsResponse, error)
+	// Streams a single output segment (see ListOutputSegments). The segment
+	// being written is followed until the job moves on to the next one
+	GetOutputSegment(ctx context.Context, in *GetOutputSegmentRequest, opts ...grpc.CallOption) (JobManager_GetOutputSegmentClient, error)
+	// Streams the job's progress reports (see Progress): the latest one
+	// right away, then each new one. Ends once the job is finished
+	GetJobProgress(ctx context.Context, in *GetJobProgressRequest, opts ...grpc.CallOption) (JobManager_GetJobProgressClient, error)
+	// Ends one of the caller's sessions (see StartJobRequest.session_id),
+	// stopping the jobs started in it if asked to
+	EndSession(ctx context.Context, in *EndSessionRequest, opts ...grpc.CallOption) (*EndSessionResponse, error)
+	// Streams the server's own log from now on. Only for the users the
+	// server names as admins
+	StreamServerLogs(ctx context.Context, in *StreamServerLogsRequest, opts ...grpc.CallOption) (JobManager_StreamServerLogsClient, error)
+	// Deletes one of the caller's finished jobs. Unless forced, the job is
+	// only hidden (ex: from ListJobs) and its output kept until the server's
+	// grace period is up, so it can be brought back with RestoreJob
+	DeleteJob(ctx context.Context, in *DeleteJobRequest, opts ...grpc.CallOption) (*DeleteJobResponse, error)
+	// Brings back a job deleted without force, within the grace period
+	RestoreJob(ctx context.Context, in *RestoreJobRequest, opts ...grpc.CallOption) (*RestoreJobResponse, error)
+	// Registers a running process the server didn't start (ex: a daemon
+	// being migrated) as a job, so it can be followed and stopped like one.
+	// Only for admins, on servers with the process_adoption feature on
+	AdoptProcess(ctx context.Context, in *AdoptProcessRequest, opts ...grpc.CallOption) (*AdoptProcessResponse, error)
+	// How long the caller's earlier runs of a command took, how they exited
+	// and how much output they wrote. Only the most recent runs are kept
+	GetJobStats(ctx context.Context, in *GetJobStatsRequest, opts ...grpc.CallOption) (*GetJobStatsResponse, error)
+	// Everything about a job in one call: its record, status, attempts,
+	// events, output and resource usage
+	DescribeJob(ctx context.Context, in *DescribeJobRequest, opts ...grpc.CallOption) (*DescribeJobResponse, error)
+	// Sends data to the stdin of a job started with spec.stdin, as fast as
+	// the job reads it. The first message names the job. Ending the stream
+	// closes the job's stdin, giving it EOF. Only one stream may write a
+	// job's stdin, once
+	WriteJobStdin(ctx context.Context, opts ...grpc.CallOption) (JobManager_WriteJobStdinClient, error)
+	// Extends the lease of a job started with spec.lease by another lease
+	// period from now. Jobs whose lease runs out are stopped
+	RenewJobLease(ctx context.Context, in *RenewJobLeaseRequest, opts ...grpc.CallOption) (*RenewJobLeaseResponse, error)
+	// Records how far along a job started with track_progress is, as a
+	// progress report line in its output would. Mostly for the job itself,
+	// with a token that may report progress (see JobSpec.token_scopes)
+	ReportJobProgress(ctx context.Context, in *ReportJobProgressRequest, opts ...grpc.CallOption) (*ReportJobProgressResponse, error)
+	// Attaches notes to a job (ex: the URL of a report it produced), kept
+	// in its record. Mostly for the job itself, with a token that may
+	// annotate (see JobSpec.token_scopes)
+	AnnotateJob(ctx context.Context, in *AnnotateJobRequest, opts ...grpc.CallOption) (*AnnotateJobResponse, error)
+	// Adds a schedule, which starts a job as the caller each time its cron
+	// expression matches, or replaces the caller's schedule of the same name.
+	// Only on servers with a metadata store, where schedules are kept
+	PutSchedule(ctx context.Context, in *PutScheduleRequest, opts ...grpc.CallOption) (*PutScheduleResponse, error)
+	// The caller's schedules, by name
+	ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error)
+	// Stops a schedule from starting jobs. Jobs it already started are left alone
+	DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*DeleteScheduleResponse, error)
+	// Recent and upcoming runs of one of the caller's schedules
+	ListScheduleRuns(ctx context.Context, in *ListScheduleRunsRequest, opts ...grpc.CallOption) (*ListScheduleRunsResponse, error)
+	// What a template would run with the given parameters, for the caller
+	// to confirm before starting it. Fails the way starting it would if a
+	// parameter is missing or invalid, naming every parameter that is
+	PreviewTemplate(ctx context.Context, in *PreviewTemplateRequest, opts ...grpc.CallOption) (*PreviewTemplateResponse, error)
+	// Compares the output directory with the jobs the server knows of:
+	// files no job owns, output jobs are missing, and disk used by each
+	// user. Optionally removes the files no job owns. Only for admins
+	CheckOutputDirectory(ctx context.Context, in *CheckOutputDirectoryRequest, opts ...grpc.CallOption) (*CheckOutputDirectoryResponse, error)
+}
+
+type jobManagerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJobManagerClient(cc grpc.ClientConnInterface) JobManagerClient {
+	return &jobManagerClient{cc}
+}
+
+func (c *jobManagerClient) StartJob(ctx context.Context, in *StartJobRequest, opts ...grpc.CallOption) (*StartJobResponse, error) {
+	out := new(StartJobResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/StartJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) StopJob(ctx context.Context, in *StopJobRequest, opts ...grpc.CallOption) (*StopJobResponse, error) {
+	out := new(StopJobResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/StopJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) WaitJob(ctx context.Context, in *WaitJobRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/WaitJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) GetJobOutput(ctx context.Context, in *GetJobOutputRequest, opts ...grpc.CallOption) (JobManager_GetJobOutputClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JobManager_ServiceDesc.Streams[0], "/jobmanager.v2.JobManager/GetJobOutput", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobManagerGetJobOutputClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JobManager_GetJobOutputClient interface {
+	Recv() (*GetJobOutputResponse, error)
+	grpc.ClientStream
+}
+
+type jobManagerGetJobOutputClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobManagerGetJobOutputClient) Recv() (*GetJobOutputResponse, error) {
+	m := new(GetJobOutputResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobManagerClient) GetJobHistory(ctx context.Context, in *GetJobHistoryRequest, opts ...grpc.CallOption) (*GetJobHistoryResponse, error) {
+	out := new(GetJobHistoryResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/GetJobHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) ExportJobs(ctx context.Context, in *ExportJobsRequest, opts ...grpc.CallOption) (JobManager_ExportJobsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JobManager_ServiceDesc.Streams[1], "/jobmanager.v2.JobManager/ExportJobs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobManagerExportJobsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JobManager_ExportJobsClient interface {
+	Recv() (*JobRecord, error)
+	grpc.ClientStream
+}
+
+type jobManagerExportJobsClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobManagerExportJobsClient) Recv() (*JobRecord, error) {
+	m := new(JobRecord)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobManagerClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/ListJobs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error) {
+	out := new(GetServerInfoResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/GetServerInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) GetUsageSummary(ctx context.Context, in *GetUsageSummaryRequest, opts ...grpc.CallOption) (*GetUsageSummaryResponse, error) {
+	out := new(GetUsageSummaryResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/GetUsageSummary", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) GetJobEvents(ctx context.Context, in *GetJobEventsRequest, opts ...grpc.CallOption) (*GetJobEventsResponse, error) {
+	out := new(GetJobEventsResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/GetJobEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) ListOutputSegments(ctx context.Context, in *ListOutputSegmentsRequest, opts ...grpc.CallOption) (*ListOutputSegmentsResponse, error) {
+	out := new(ListOutputSegmentsResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/ListOutputSegments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) GetOutputSegment(ctx context.Context, in *GetOutputSegmentRequest, opts ...grpc.CallOption) (JobManager_GetOutputSegmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JobManager_ServiceDesc.Streams[2], "/jobmanager.v2.JobManager/GetOutputSegment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobManagerGetOutputSegmentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JobManager_GetOutputSegmentClient interface {
+	Recv() (*GetJobOutputResponse, error)
+	grpc.ClientStream
+}
+
+type jobManagerGetOutputSegmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobManagerGetOutputSegmentClient) Recv() (*GetJobOutputResponse, error) {
+	m := new(GetJobOutputResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobManagerClient) GetJobProgress(ctx context.Context, in *GetJobProgressRequest, opts ...grpc.CallOption) (JobManager_GetJobProgressClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JobManager_ServiceDesc.Streams[3], "/jobmanager.v2.JobManager/GetJobProgress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobManagerGetJobProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JobManager_GetJobProgressClient interface {
+	Recv() (*GetJobProgressResponse, error)
+	grpc.ClientStream
+}
+
+type jobManagerGetJobProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobManagerGetJobProgressClient) Recv() (*GetJobProgressResponse, error) {
+	m := new(GetJobProgressResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobManagerClient) EndSession(ctx context.Context, in *EndSessionRequest, opts ...grpc.CallOption) (*EndSessionResponse, error) {
+	out := new(EndSessionResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/EndSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) StreamServerLogs(ctx context.Context, in *StreamServerLogsRequest, opts ...grpc.CallOption) (JobManager_StreamServerLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JobManager_ServiceDesc.Streams[4], "/jobmanager.v2.JobManager/StreamServerLogs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobManagerStreamServerLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JobManager_StreamServerLogsClient interface {
+	Recv() (*ServerLogEntry, error)
+	grpc.ClientStream
+}
+
+type jobManagerStreamServerLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobManagerStreamServerLogsClient) Recv() (*ServerLogEntry, error) {
+	m := new(ServerLogEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobManagerClient) DeleteJob(ctx context.Context, in *DeleteJobRequest, opts ...grpc.CallOption) (*DeleteJobResponse, error) {
+	out := new(DeleteJobResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/DeleteJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) RestoreJob(ctx context.Context, in *RestoreJobRequest, opts ...grpc.CallOption) (*RestoreJobResponse, error) {
+	out := new(RestoreJobResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/RestoreJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) AdoptProcess(ctx context.Context, in *AdoptProcessRequest, opts ...grpc.CallOption) (*AdoptProcessResponse, error) {
+	out := new(AdoptProcessResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/AdoptProcess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) GetJobStats(ctx context.Context, in *GetJobStatsRequest, opts ...grpc.CallOption) (*GetJobStatsResponse, error) {
+	out := new(GetJobStatsResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/GetJobStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) DescribeJob(ctx context.Context, in *DescribeJobRequest, opts ...grpc.CallOption) (*DescribeJobResponse, error) {
+	out := new(DescribeJobResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/DescribeJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) WriteJobStdin(ctx context.Context, opts ...grpc.CallOption) (JobManager_WriteJobStdinClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JobManager_ServiceDesc.Streams[5], "/jobmanager.v2.JobManager/WriteJobStdin", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobManagerWriteJobStdinClient{stream}
+	return x, nil
+}
+
+type JobManager_WriteJobStdinClient interface {
+	Send(*WriteJobStdinRequest) error
+	CloseAndRecv() (*WriteJobStdinResponse, error)
+	grpc.ClientStream
+}
+
+type jobManagerWriteJobStdinClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobManagerWriteJobStdinClient) Send(m *WriteJobStdinRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *jobManagerWriteJobStdinClient) CloseAndRecv() (*WriteJobStdinResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(WriteJobStdinResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobManagerClient) RenewJobLease(ctx context.Context, in *RenewJobLeaseRequest, opts ...grpc.CallOption) (*RenewJobLeaseResponse, error) {
+	out := new(RenewJobLeaseResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/RenewJobLease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) ReportJobProgress(ctx context.Context, in *ReportJobProgressRequest, opts ...grpc.CallOption) (*ReportJobProgressResponse, error) {
+	out := new(ReportJobProgressResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/ReportJobProgress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) AnnotateJob(ctx context.Context, in *AnnotateJobRequest, opts ...grpc.CallOption) (*AnnotateJobResponse, error) {
+	out := new(AnnotateJobResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/AnnotateJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) PutSchedule(ctx context.Context, in *PutScheduleRequest, opts ...grpc.CallOption) (*PutScheduleResponse, error) {
+	out := new(PutScheduleResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/PutSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error) {
+	out := new(ListSchedulesResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/ListSchedules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*DeleteScheduleResponse, error) {
+	out := new(DeleteScheduleResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/DeleteSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) ListScheduleRuns(ctx context.Context, in *ListScheduleRunsRequest, opts ...grpc.CallOption) (*ListScheduleRunsResponse, error) {
+	out := new(ListScheduleRunsResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/ListScheduleRuns", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) PreviewTemplate(ctx context.Context, in *PreviewTemplateRequest, opts ...grpc.CallOption) (*PreviewTemplateResponse, error) {
+	out := new(PreviewTemplateResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/PreviewTemplate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobManagerClient) CheckOutputDirectory(ctx context.Context, in *CheckOutputDirectoryRequest, opts ...grpc.CallOption) (*CheckOutputDirectoryResponse, error) {
+	out := new(CheckOutputDirectoryResponse)
+	err := c.cc.Invoke(ctx, "/jobmanager.v2.JobManager/CheckOutputDirectory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JobManagerServer is the server API for JobManager service.
+// All implementations must embed UnimplementedJobManagerServer
+// for forward compatibility
+type JobManagerServer interface {
+	StartJob(context.Context, *StartJobRequest) (*StartJobResponse, error)
+	StopJob(context.Context, *StopJobRequest) (*StopJobResponse, error)
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	// Blocks until the job is finished (no attempt running or queued to run
+	// again), then returns its final status. Use a deadline to give up waiting
+	WaitJob(context.Context, *WaitJobRequest) (*GetStatusResponse, error)
+	// Server will close the send-stream once output is exhausted, after a
+	// message with the length and checksum of what it sent (see OutputEnd)
+	// Streams end early with ABORTED if the job is stopped, or NOT_FOUND
+	// if it's deleted, while they're attached
+	GetJobOutput(*GetJobOutputRequest, JobManager_GetJobOutputServer) error
+	// Lists every execution attempt of a job, oldest first
+	GetJobHistory(context.Context, *GetJobHistoryRequest) (*GetJobHistoryResponse, error)
+	// Streams a record for every job owned by the caller, oldest first
+	ExportJobs(*ExportJobsRequest, JobManager_ExportJobsServer) error
+	// Jobs owned by the caller that match every given search
+	// parameter, oldest first
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
+	// Describes the node the server runs on
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error)
+	// Resources consumed by each owner's jobs over the server's accounting windows
+	GetUsageSummary(context.Context, *GetUsageSummaryRequest) (*GetUsageSummaryResponse, error)
+	// Everything that happened to a job, oldest first. Available for a
+	// while after the job is garbage collected
+	GetJobEvents(context.Context, *GetJobEventsRequest) (*GetJobEventsResponse, error)
+	// Segments of a job's output still on disk, oldest first. Only for
+	// jobs started with output_segments or output_window_bytes
+	ListOutputSegments(context.Context, *ListOutputSegmentsRequest) (*ListOutputSegmentsResponse, error)
+	// Streams a single output segment (see ListOutputSegments). The segment
+	// being written is followed until the job moves on to the next one
+	GetOutputSegment(*GetOutputSegmentRequest, JobManager_GetOutputSegmentServer) error
+	// Streams the job's progress reports (see Progress): the latest one
+	// right away, then each new one. Ends once the job is finished
+	GetJobProgress(*GetJobProgressRequest, JobManager_GetJobProgressServer) error
+	// Ends one of the caller's sessions (see StartJobRequest.session_id),
+	// stopping the jobs started in it if asked to
+	EndSession(context.Context, *EndSessionRequest) (*EndSessionResponse, error)
+	// Streams the server's own log from now on. Only for the users the
+	// server names as admins
+	StreamServerLogs(*StreamServerLogsRequest, JobManager_StreamServerLogsServer) error
+	// Deletes one of the caller's finished jobs. Unless forced, the job is
+	// only hidden (ex: from ListJobs) and its output kept until the server's
+	// grace period is up, so it can be brought back with RestoreJob
+	DeleteJob(context.Context, *DeleteJobRequest) (*DeleteJobResponse, error)
+	// Brings back a job deleted without force, within the grace period
+	RestoreJob(context.Context, *RestoreJobRequest) (*RestoreJobResponse, error)
+	// Registers a running process the server didn't start (ex: a daemon
+	// being migrated) as a job, so it can be followed and stopped like one.
+	// Only for admins, on servers with the process_adoption feature on
+	AdoptProcess(context.Context, *AdoptProcessRequest) (*AdoptProcessResponse, error)
+	// How long the caller's earlier runs of a command took, how they exited
+	// and how much output they wrote. Only the most recent runs are kept
+	GetJobStats(context.Context, *GetJobStatsRequest) (*GetJobStatsResponse, error)
+	// Everything about a job in one call: its record, status, attempts,
+	// events, output and resource usage
+	DescribeJob(context.Context, *DescribeJobRequest) (*DescribeJobResponse, error)
+	// Sends data to the stdin of a job started with spec.stdin, as fast as
+	// the job reads it. The first message names the job. Ending the stream
+	// closes the job's stdin, giving it EOF. Only one stream may write a
+	// job's stdin, once
+	WriteJobStdin(JobManager_WriteJobStdinServer) error
+	// Extends the lease of a job started with spec.lease by another lease
+	// period from now. Jobs whose lease runs out are stopped
+	RenewJobLease(context.Context, *RenewJobLeaseRequest) (*RenewJobLeaseResponse, error)
+	// Records how far along a job started with track_progress is, as a
+	// progress report line in its output would. Mostly for the job itself,
+	// with a token that may report progress (see JobSpec.token_scopes)
+	ReportJobProgress(context.Context, *ReportJobProgressRequest) (*ReportJobProgressResponse, error)
+	// Attaches notes to a job (ex: the URL of a report it produced), kept
+	// in its record. Mostly for the job itself, with a token that may
+	// annotate (see JobSpec.token_scopes)
+	AnnotateJob(context.Context, *AnnotateJobRequest) (*AnnotateJobResponse, error)
+	// Adds a schedule, which starts a job as the caller each time its cron
+	// expression matches, or replaces the caller's schedule of the same name.
+	// Only on servers with a metadata store, where schedules are kept
+	PutSchedule(context.Context, *PutScheduleRequest) (*PutScheduleResponse, error)
+	// The caller's schedules, by name
+	ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error)
+	// Stops a schedule from starting jobs. Jobs it already started are left alone
+	DeleteSchedule(context.Context, *DeleteScheduleRequest) (*DeleteScheduleResponse, error)
+	// Recent and upcoming runs of one of the caller's schedules
+	ListScheduleRuns(context.Context, *ListScheduleRunsRequest) (*ListScheduleRunsResponse, error)
+	// What a template would run with the given parameters, for the caller
+	// to confirm before starting it. Fails the way starting it would if a
+	// parameter is missing or invalid, naming every parameter that is
+	PreviewTemplate(context.Context, *PreviewTemplateRequest) (*PreviewTemplateResponse, error)
+	// Compares the output directory with the jobs the server knows of:
+	// files no job owns, output jobs are missing, and disk used by each
+	// user. Optionally removes the files no job owns. Only for admins
+	CheckOutputDirectory(context.Context, *CheckOutputDirectoryRequest) (*CheckOutputDirectoryResponse, error)
+	mustEmbedUnimplementedJobManagerServer()
+}
+
+// UnimplementedJobManagerServer must be embedded to have forward compatible implementations.
+type UnimplementedJobManagerServer struct {
+}
+
+func (UnimplementedJobManagerServer) StartJob(context.Context, *StartJobRequest) (*StartJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartJob not implemented")
+}
+func (UnimplementedJobManagerServer) StopJob(context.Context, *StopJobRequest) (*StopJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopJob not implemented")
+}
+func (UnimplementedJobManagerServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedJobManagerServer) WaitJob(context.Context, *WaitJobRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WaitJob not implemented")
+}
+func (UnimplementedJobManagerServer) GetJobOutput(*GetJobOutputRequest, JobManager_GetJobOutputServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetJobOutput not implemented")
+}
+func (UnimplementedJobManagerServer) GetJobHistory(context.Context, *GetJobHistoryRequest) (*GetJobHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJobHistory not implemented")
+}
+func (UnimplementedJobManagerServer) ExportJobs(*ExportJobsRequest, JobManager_ExportJobsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportJobs not implemented")
+}
+func (UnimplementedJobManagerServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedJobManagerServer) GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
+}
+func (UnimplementedJobManagerServer) GetUsageSummary(context.Context, *GetUsageSummaryRequest) (*GetUsageSummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageSummary not implemented")
+}
+func (UnimplementedJobManagerServer) GetJobEvents(context.Context, *GetJobEventsRequest) (*GetJobEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJobEvents not implemented")
+}
+func (UnimplementedJobManagerServer) ListOutputSegments(context.Context, *ListOutputSegmentsRequest) (*ListOutputSegmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOutputSegments not implemented")
+}
+func (UnimplementedJobManagerServer) GetOutputSegment(*GetOutputSegmentRequest, JobManager_GetOutputSegmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetOutputSegment not implemented")
+}
+func (UnimplementedJobManagerServer) GetJobProgress(*GetJobProgressRequest, JobManager_GetJobProgressServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetJobProgress not implemented")
+}
+func (UnimplementedJobManagerServer) EndSession(context.Context, *EndSessionRequest) (*EndSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EndSession not implemented")
+}
+func (UnimplementedJobManagerServer) StreamServerLogs(*StreamServerLogsRequest, JobManager_StreamServerLogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamServerLogs not implemented")
+}
+func (UnimplementedJobManagerServer) DeleteJob(context.Context, *DeleteJobRequest) (*DeleteJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteJob not implemented")
+}
+func (UnimplementedJobManagerServer) RestoreJob(context.Context, *RestoreJobRequest) (*RestoreJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreJob not implemented")
+}
+func (UnimplementedJobManagerServer) AdoptProcess(context.Context, *AdoptProcessRequest) (*AdoptProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdoptProcess not implemented")
+}
+func (UnimplementedJobManagerServer) GetJobStats(context.Context, *GetJobStatsRequest) (*GetJobStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJobStats not implemented")
+}
+func (UnimplementedJobManagerServer) DescribeJob(context.Context, *DescribeJobRequest) (*DescribeJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DescribeJob not implemented")
+}
+func (UnimplementedJobManagerServer) WriteJobStdin(JobManager_WriteJobStdinServer) error {
+	return status.Errorf(codes.Unimplemented, "method WriteJobStdin not implemented")
+}
+func (UnimplementedJobManagerServer) RenewJobLease(context.Context, *RenewJobLeaseRequest) (*RenewJobLeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewJobLease not implemented")
+}
+func (UnimplementedJobManagerServer) ReportJobProgress(context.Context, *ReportJobProgressRequest) (*ReportJobProgressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportJobProgress not implemented")
+}
+func (UnimplementedJobManagerServer) AnnotateJob(context.Context, *AnnotateJobRequest) (*AnnotateJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnnotateJob not implemented")
+}
+func (UnimplementedJobManagerServer) PutSchedule(context.Context, *PutScheduleRequest) (*PutScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutSchedule not implemented")
+}
+func (UnimplementedJobManagerServer) ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSchedules not implemented")
+}
+func (UnimplementedJobManagerServer) DeleteSchedule(context.Context, *DeleteScheduleRequest) (*DeleteScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSchedule not implemented")
+}
+func (UnimplementedJobManagerServer) ListScheduleRuns(context.Context, *ListScheduleRunsRequest) (*ListScheduleRunsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScheduleRuns not implemented")
+}
+func (UnimplementedJobManagerServer) PreviewTemplate(context.Context, *PreviewTemplateRequest) (*PreviewTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewTemplate not implemented")
+}
+func (UnimplementedJobManagerServer) CheckOutputDirectory(context.Context, *CheckOutputDirectoryRequest) (*CheckOutputDirectoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckOutputDirectory not implemented")
+}
+func (UnimplementedJobManagerServer) mustEmbedUnimplementedJobManagerServer() {}
+
+// UnsafeJobManagerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JobManagerServer will
+// result in compilation errors.
+type UnsafeJobManagerServer interface {
+	mustEmbedUnimplementedJobManagerServer()
+}
+
+func RegisterJobManagerServer(s grpc.ServiceRegistrar, srv JobManagerServer) {
+	s.RegisterService(&JobManager_ServiceDesc, srv)
+}
+
+func _JobManager_StartJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).StartJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/StartJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).StartJob(ctx, req.(*StartJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_StopJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).StopJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/StopJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).StopJob(ctx, req.(*StopJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_WaitJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).WaitJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/WaitJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).WaitJob(ctx, req.(*WaitJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_GetJobOutput_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetJobOutputRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobManagerServer).GetJobOutput(m, &jobManagerGetJobOutputServer{stream})
+}
+
+type JobManager_GetJobOutputServer interface {
+	Send(*GetJobOutputResponse) error
+	grpc.ServerStream
+}
+
+type jobManagerGetJobOutputServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobManagerGetJobOutputServer) Send(m *GetJobOutputResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _JobManager_GetJobHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).GetJobHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/GetJobHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).GetJobHistory(ctx, req.(*GetJobHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_ExportJobs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportJobsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobManagerServer).ExportJobs(m, &jobManagerExportJobsServer{stream})
+}
+
+type JobManager_ExportJobsServer interface {
+	Send(*JobRecord) error
+	grpc.ServerStream
+}
+
+type jobManagerExportJobsServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobManagerExportJobsServer) Send(m *JobRecord) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _JobManager_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/ListJobs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/GetServerInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_GetUsageSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).GetUsageSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/GetUsageSummary",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).GetUsageSummary(ctx, req.(*GetUsageSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_GetJobEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).GetJobEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/GetJobEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).GetJobEvents(ctx, req.(*GetJobEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_ListOutputSegments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOutputSegmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).ListOutputSegments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/ListOutputSegments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).ListOutputSegments(ctx, req.(*ListOutputSegmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_GetOutputSegment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetOutputSegmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobManagerServer).GetOutputSegment(m, &jobManagerGetOutputSegmentServer{stream})
+}
+
+type JobManager_GetOutputSegmentServer interface {
+	Send(*GetJobOutputResponse) error
+	grpc.ServerStream
+}
+
+type jobManagerGetOutputSegmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobManagerGetOutputSegmentServer) Send(m *GetJobOutputResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _JobManager_GetJobProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetJobProgressRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobManagerServer).GetJobProgress(m, &jobManagerGetJobProgressServer{stream})
+}
+
+type JobManager_GetJobProgressServer interface {
+	Send(*GetJobProgressResponse) error
+	grpc.ServerStream
+}
+
+type jobManagerGetJobProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobManagerGetJobProgressServer) Send(m *GetJobProgressResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _JobManager_EndSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EndSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).EndSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/EndSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).EndSession(ctx, req.(*EndSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_StreamServerLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamServerLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobManagerServer).StreamServerLogs(m, &jobManagerStreamServerLogsServer{stream})
+}
+
+type JobManager_StreamServerLogsServer interface {
+	Send(*ServerLogEntry) error
+	grpc.ServerStream
+}
+
+type jobManagerStreamServerLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobManagerStreamServerLogsServer) Send(m *ServerLogEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _JobManager_DeleteJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).DeleteJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/DeleteJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).DeleteJob(ctx, req.(*DeleteJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_RestoreJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).RestoreJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/RestoreJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).RestoreJob(ctx, req.(*RestoreJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_AdoptProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdoptProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).AdoptProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/AdoptProcess",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).AdoptProcess(ctx, req.(*AdoptProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_GetJobStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).GetJobStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/GetJobStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).GetJobStats(ctx, req.(*GetJobStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_DescribeJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).DescribeJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/DescribeJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).DescribeJob(ctx, req.(*DescribeJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_WriteJobStdin_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(JobManagerServer).WriteJobStdin(&jobManagerWriteJobStdinServer{stream})
+}
+
+type JobManager_WriteJobStdinServer interface {
+	SendAndClose(*WriteJobStdinResponse) error
+	Recv() (*WriteJobStdinRequest, error)
+	grpc.ServerStream
+}
+
+type jobManagerWriteJobStdinServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobManagerWriteJobStdinServer) SendAndClose(m *WriteJobStdinResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *jobManagerWriteJobStdinServer) Recv() (*WriteJobStdinRequest, error) {
+	m := new(WriteJobStdinRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _JobManager_RenewJobLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewJobLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).RenewJobLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/RenewJobLease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).RenewJobLease(ctx, req.(*RenewJobLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_ReportJobProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportJobProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).ReportJobProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/ReportJobProgress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).ReportJobProgress(ctx, req.(*ReportJobProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_AnnotateJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnnotateJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).AnnotateJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/AnnotateJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).AnnotateJob(ctx, req.(*AnnotateJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_PutSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).PutSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/PutSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).PutSchedule(ctx, req.(*PutScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_ListSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).ListSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/ListSchedules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).ListSchedules(ctx, req.(*ListSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_DeleteSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).DeleteSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/DeleteSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).DeleteSchedule(ctx, req.(*DeleteScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_ListScheduleRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduleRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).ListScheduleRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/ListScheduleRuns",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).ListScheduleRuns(ctx, req.(*ListScheduleRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_PreviewTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).PreviewTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/PreviewTemplate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).PreviewTemplate(ctx, req.(*PreviewTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobManager_CheckOutputDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckOutputDirectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobManagerServer).CheckOutputDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jobmanager.v2.JobManager/CheckOutputDirectory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobManagerServer).CheckOutputDirectory(ctx, req.(*CheckOutputDirectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// JobManager_ServiceDesc is the grpc.ServiceDesc for JobManager service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JobManager_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jobmanager.v2.JobManager",
+	HandlerType: (*JobManagerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartJob",
+			Handler:    _JobManager_StartJob_Handler,
+		},
+		{
+			MethodName: "StopJob",
+			Handler:    _JobManager_StopJob_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _JobManager_GetStatus_Handler,
+		},
+		{
+			MethodName: "WaitJob",
+			Handler:    _JobManager_WaitJob_Handler,
+		},
+		{
+			MethodName: "GetJobHistory",
+			Handler:    _JobManager_GetJobHistory_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _JobManager_ListJobs_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _JobManager_GetServerInfo_Handler,
+		},
+		{
+			MethodName: "GetUsageSummary",
+			Handler:    _JobManager_GetUsageSummary_Handler,
+		},
+		{
+			MethodName: "GetJobEvents",
+			Handler:    _JobManager_GetJobEvents_Handler,
+		},
+		{
+			MethodName: "ListOutputSegments",
+			Handler:    _JobManager_ListOutputSegments_Handler,
+		},
+		{
+			MethodName: "EndSession",
+			Handler:    _JobManager_EndSession_Handler,
+		},
+		{
+			MethodName: "DeleteJob",
+			Handler:    _JobManager_DeleteJob_Handler,
+		},
+		{
+			MethodName: "RestoreJob",
+			Handler:    _JobManager_RestoreJob_Handler,
+		},
+		{
+			MethodName: "AdoptProcess",
+			Handler:    _JobManager_AdoptProcess_Handler,
+		},
+		{
+			MethodName: "GetJobStats",
+			Handler:    _JobManager_GetJobStats_Handler,
+		},
+		{
+			MethodName: "DescribeJob",
+			Handler:    _JobManager_DescribeJob_Handler,
+		},
+		{
+			MethodName: "RenewJobLease",
+			Handler:    _JobManager_RenewJobLease_Handler,
+		},
+		{
+			MethodName: "ReportJobProgress",
+			Handler:    _JobManager_ReportJobProgress_Handler,
+		},
+		{
+			MethodName: "AnnotateJob",
+			Handler:    _JobManager_AnnotateJob_Handler,
+		},
+		{
+			MethodName: "PutSchedule",
+			Handler:    _JobManager_PutSchedule_Handler,
+		},
+		{
+			MethodName: "ListSchedules",
+			Handler:    _JobManager_ListSchedules_Handler,
+		},
+		{
+			MethodName: "DeleteSchedule",
+			Handler:    _JobManager_DeleteSchedule_Handler,
+		},
+		{
+			MethodName: "ListScheduleRuns",
+			Handler:    _JobManager_ListScheduleRuns_Handler,
+		},
+		{
+			MethodName: "PreviewTemplate",
+			Handler:    _JobManager_PreviewTemplate_Handler,
+		},
+		{
+			MethodName: "CheckOutputDirectory",
+			Handler:    _JobManager_CheckOutputDirectory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetJobOutput",
+			Handler:       _JobManager_GetJobOutput_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportJobs",
+			Handler:       _JobManager_ExportJobs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetOutputSegment",
+			Handler:       _JobManager_GetOutputSegment_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetJobProgress",
+			Handler:       _JobManager_GetJobProgress_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamServerLogs",
+			Handler:       _JobManager_StreamServerLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WriteJobStdin",
+			Handler:       _JobManager_WriteJobStdin_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "jobmanager/v2/jobmanager.proto",
+}